@@ -0,0 +1,62 @@
+// Command migrate applies pending data migrations.
+//
+//	go run ./cmd/migrate            apply every pending migration
+//	go run ./cmd/migrate -to 3      apply pending migrations up to version 3
+//	go run ./cmd/migrate -status    list migrations and when they were applied
+//	go run ./cmd/migrate -unlock    release the lock left by a killed run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/config"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/migrations"
+)
+
+func main() {
+	status := flag.Bool("status", false, "list migrations and exit")
+	unlock := flag.Bool("unlock", false, "release the migration lock and exit")
+	target := flag.Int("to", 0, "apply migrations up to this version, 0 for all")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	db, err := config.InitMongo(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	runner, err := migrations.NewRunner(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	switch {
+	case *status:
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-40s  %s\n", s.Version, s.Name, appliedAt)
+		}
+	case *unlock:
+		if err := runner.Unlock(ctx); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Migration lock released")
+	default:
+		applied, err := runner.Up(ctx, *target)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Applied %d migration(s) %v", len(applied), applied)
+	}
+}
@@ -1,20 +1,29 @@
 package config
 
 import (
+	"encoding/base64"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 )
 
 type Config struct {
 	// Server
 	ServerAddress string
+	AppEnv        string
+	// How long a shutdown waits for requests, sockets and background jobs to finish
+	ShutdownTimeoutSeconds int
 
 	// MongoDB
 	MongoURI string
 	MongoDB  string
+	// Whether the server applies pending migrations before it starts serving
+	MigrateOnStart bool
 
 	// Redis
 	RedisURI      string
@@ -29,6 +38,87 @@ type Config struct {
 	JWTExpiryHours     int
 	RefreshTokenSecret string
 	RefreshTokenExpiry int // in days
+
+	// JWT key rotation
+	JWTKeysFile          string // JSON file with the key set, e.g. mounted from a secret store
+	JWTKeysReloadMinutes int
+
+	// Token claims. Tokens are checked for the issuer and audience when they're set.
+	JWTIssuer     string
+	JWTAudience   string
+	JWTTenant     string
+	JWTUserScopes []string
+
+	// Service accounts
+	ServiceAccounts           []domain.ServiceAccount
+	ServiceTokenExpiryMinutes int
+
+	// Soft-deleted content stays restorable for this many days
+	SoftDeleteRetentionDays int
+
+	// Uploads per user are limited to this many megabytes, 0 means unlimited
+	StorageQuotaMB int
+
+	// Image processing per upload context
+	ImageProcessing map[string]domain.ImageProcessing
+
+	// Google Cloud Translation API key, translation is disabled when empty
+	TranslateAPIKey string
+
+	// Analytics events go to "log" or "redis" (a Redis stream)
+	AnalyticsSink         string
+	AnalyticsStream       string
+	AnalyticsStreamMaxLen int
+
+	// Domain event export to an external stream, disabled when the sink is empty.
+	// Only "redis" (a Redis stream) is supported.
+	EventExportSink            string
+	EventExportStream          string
+	EventExportStreamMaxLen    int
+	EventExportIntervalSeconds int
+
+	// How often reaction, comment and view counts buffered in Redis are written to posts
+	PostCounterFlushSeconds int
+
+	// How often profile visits buffered in Redis are written to the daily stats
+	ProfileVisitFlushSeconds int
+
+	// Posts and users above these thresholds get aggregated notifications, 0 disables
+	HotPostEngagementThreshold        int
+	HotUserFollowerThreshold          int
+	HotNotificationAggregationMinutes int
+
+	// Hosts and app URL scheme of share links, resolved by GET /api/resolve
+	PermalinkHosts  []string
+	PermalinkScheme string
+
+	// Short share links, served as <ShortLinkBaseURL>/<code>. New links expire
+	// after ShortLinkTTLDays unless the client asks otherwise, 0 means never.
+	ShortLinkBaseURL string
+	ShortLinkTTLDays int
+
+	// Intervals of the scheduled maintenance jobs, 0 disables a job
+	StoryArchiveIntervalSeconds      int
+	SuspensionProcessIntervalSeconds int
+
+	// Transactional emails go to "log" or "smtp". Links in them point at EmailLinkBaseURL.
+	EmailSender      string
+	EmailFrom        string
+	EmailLinkBaseURL string
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+
+	// CDN hosts serving the storage buckets, as bucket=baseURL entries. CDN URLs
+	// are signed when the key (base64url, like Cloud CDN keys) is set.
+	CDNHosts               []string
+	CDNSigningKeyName      string
+	CDNSigningKey          string
+	CDNSignedURLTTLMinutes int
+
+	// Request limits per rate limit bucket, as bucket=requests/seconds entries
+	RateLimits []string
 }
 
 func LoadConfig() *Config {
@@ -39,11 +129,14 @@ func LoadConfig() *Config {
 
 	return &Config{
 		// Server
-		ServerAddress: getEnv("SERVER_ADDRESS", ":8080"),
+		ServerAddress:          getEnv("SERVER_ADDRESS", ":8080"),
+		AppEnv:                 getEnv("APP_ENV", "development"),
+		ShutdownTimeoutSeconds: getEnvInt("SHUTDOWN_TIMEOUT_SECONDS", 10),
 
 		// MongoDB
-		MongoURI: getEnv("MONGO_URI", ""),
-		MongoDB:  getEnv("MONGO_DB", ""),
+		MongoURI:       getEnv("MONGO_URI", ""),
+		MongoDB:        getEnv("MONGO_DB", ""),
+		MigrateOnStart: getEnv("MIGRATE_ON_START", "true") == "true",
 
 		// Redis
 		RedisURI:      getEnv("REDIS_URI", ""),
@@ -58,6 +151,68 @@ func LoadConfig() *Config {
 		JWTExpiryHours:     1,
 		RefreshTokenSecret: getEnv("REFRESH_TOKEN_SECRET", ""),
 		RefreshTokenExpiry: 30,
+
+		// JWT key rotation
+		JWTKeysFile:          getEnv("JWT_KEYS_FILE", ""),
+		JWTKeysReloadMinutes: getEnvInt("JWT_KEYS_RELOAD_MINUTES", 5),
+
+		// Token claims
+		JWTIssuer:     getEnv("JWT_ISSUER", ""),
+		JWTAudience:   getEnv("JWT_AUDIENCE", ""),
+		JWTTenant:     getEnv("JWT_TENANT", ""),
+		JWTUserScopes: parseList(getEnv("JWT_USER_SCOPES", "")),
+
+		// Service accounts
+		ServiceAccounts:           parseServiceAccounts(getEnv("SERVICE_ACCOUNTS", "")),
+		ServiceTokenExpiryMinutes: getEnvInt("SERVICE_TOKEN_EXPIRY_MINUTES", 15),
+
+		SoftDeleteRetentionDays: getEnvInt("SOFT_DELETE_RETENTION_DAYS", 30),
+
+		StorageQuotaMB:  getEnvInt("STORAGE_QUOTA_MB", 1024),
+		ImageProcessing: parseImageProcessing(getEnv("IMAGE_PROCESSING", "default:strip|orient")),
+
+		TranslateAPIKey: getEnv("TRANSLATE_API_KEY", ""),
+
+		AnalyticsSink:         getEnv("ANALYTICS_SINK", "log"),
+		AnalyticsStream:       getEnv("ANALYTICS_STREAM", "analytics_events"),
+		AnalyticsStreamMaxLen: getEnvInt("ANALYTICS_STREAM_MAX_LEN", 1000000),
+
+		EventExportSink:            getEnv("EVENT_EXPORT_SINK", ""),
+		EventExportStream:          getEnv("EVENT_EXPORT_STREAM", "domain_events"),
+		EventExportStreamMaxLen:    getEnvInt("EVENT_EXPORT_STREAM_MAX_LEN", 1000000),
+		EventExportIntervalSeconds: getEnvInt("EVENT_EXPORT_INTERVAL_SECONDS", 5),
+
+		PostCounterFlushSeconds: getEnvInt("POST_COUNTER_FLUSH_SECONDS", 10),
+
+		ProfileVisitFlushSeconds: getEnvInt("PROFILE_VISIT_FLUSH_SECONDS", 30),
+
+		HotPostEngagementThreshold:        getEnvInt("HOT_POST_ENGAGEMENT_THRESHOLD", 500),
+		HotUserFollowerThreshold:          getEnvInt("HOT_USER_FOLLOWER_THRESHOLD", 10000),
+		HotNotificationAggregationMinutes: getEnvInt("HOT_NOTIFICATION_AGGREGATION_MINUTES", 60),
+
+		PermalinkHosts:  parseList(getEnv("PERMALINK_HOSTS", "vongga.com,www.vongga.com")),
+		PermalinkScheme: getEnv("PERMALINK_SCHEME", "vongga"),
+
+		ShortLinkBaseURL: getEnv("SHORT_LINK_BASE_URL", "https://vongga.com/s"),
+		ShortLinkTTLDays: getEnvInt("SHORT_LINK_TTL_DAYS", 90),
+
+		StoryArchiveIntervalSeconds:      getEnvInt("STORY_ARCHIVE_INTERVAL_SECONDS", 60),
+		SuspensionProcessIntervalSeconds: getEnvInt("SUSPENSION_PROCESS_INTERVAL_SECONDS", 60),
+
+		EmailSender:      getEnv("EMAIL_SENDER", "log"),
+		EmailFrom:        getEnv("EMAIL_FROM", "Vongga <no-reply@vongga.com>"),
+		EmailLinkBaseURL: getEnv("EMAIL_LINK_BASE_URL", "https://vongga.com"),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+
+		CDNHosts:               parseList(getEnv("CDN_HOSTS", "")),
+		CDNSigningKeyName:      getEnv("CDN_SIGNING_KEY_NAME", ""),
+		CDNSigningKey:          getEnv("CDN_SIGNING_KEY", ""),
+		CDNSignedURLTTLMinutes: getEnvInt("CDN_SIGNED_URL_TTL_MINUTES", 360),
+
+		RateLimits: parseList(getEnv("RATE_LIMITS", "default=300/60,auth=20/60,search=30/60,upload=20/60")),
 	}
 }
 
@@ -71,6 +226,146 @@ func (c *Config) GetRefreshTokenExpiry() time.Duration {
 	return time.Duration(c.RefreshTokenExpiry) * 24 * time.Hour
 }
 
+// IsProduction reports whether the server runs in production
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == "production"
+}
+
+// GetTokenPolicy returns what issued tokens carry and are checked for
+func (c *Config) GetTokenPolicy() domain.TokenPolicy {
+	return domain.TokenPolicy{
+		Issuer:     c.JWTIssuer,
+		Audience:   c.JWTAudience,
+		Tenant:     c.JWTTenant,
+		UserScopes: c.JWTUserScopes,
+	}
+}
+
+// GetShutdownTimeout returns how long a shutdown waits before closing what's left
+func (c *Config) GetShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutSeconds) * time.Second
+}
+
+// GetServiceTokenExpiry returns service account token expiry duration
+func (c *Config) GetServiceTokenExpiry() time.Duration {
+	return time.Duration(c.ServiceTokenExpiryMinutes) * time.Minute
+}
+
+// GetSoftDeleteRetention returns how long soft-deleted content can be restored
+func (c *Config) GetSoftDeleteRetention() time.Duration {
+	return time.Duration(c.SoftDeleteRetentionDays) * 24 * time.Hour
+}
+
+// GetStorageQuota returns the per-user storage quota in bytes, 0 means unlimited
+func (c *Config) GetStorageQuota() int64 {
+	return int64(c.StorageQuotaMB) * 1024 * 1024
+}
+
+// GetJWTKeysReloadInterval returns how often the JWT key set is reloaded
+func (c *Config) GetJWTKeysReloadInterval() time.Duration {
+	return time.Duration(c.JWTKeysReloadMinutes) * time.Minute
+}
+
+// GetEventExportInterval returns how often pending domain events are exported
+func (c *Config) GetEventExportInterval() time.Duration {
+	return time.Duration(c.EventExportIntervalSeconds) * time.Second
+}
+
+// GetPostCounterFlushInterval returns how often buffered post counters are flushed
+func (c *Config) GetPostCounterFlushInterval() time.Duration {
+	return time.Duration(c.PostCounterFlushSeconds) * time.Second
+}
+
+// GetProfileVisitFlushInterval returns how often buffered profile visits are flushed
+func (c *Config) GetProfileVisitFlushInterval() time.Duration {
+	return time.Duration(c.ProfileVisitFlushSeconds) * time.Second
+}
+
+// GetPermalinkBaseURL returns the canonical https origin of share links
+func (c *Config) GetPermalinkBaseURL() string {
+	if len(c.PermalinkHosts) == 0 {
+		return ""
+	}
+	return "https://" + c.PermalinkHosts[0]
+}
+
+// GetShortLinkTTL returns how long new short links last by default, 0 means forever
+func (c *Config) GetShortLinkTTL() time.Duration {
+	return time.Duration(c.ShortLinkTTLDays) * 24 * time.Hour
+}
+
+// GetStoryArchiveInterval returns how often expired stories are archived
+func (c *Config) GetStoryArchiveInterval() time.Duration {
+	return time.Duration(c.StoryArchiveIntervalSeconds) * time.Second
+}
+
+// GetSuspensionProcessInterval returns how often ending suspensions are announced and lifted
+func (c *Config) GetSuspensionProcessInterval() time.Duration {
+	return time.Duration(c.SuspensionProcessIntervalSeconds) * time.Second
+}
+
+// GetHotContentPolicy returns the thresholds for switching hot posts and users to aggregated notifications
+func (c *Config) GetHotContentPolicy() domain.HotContentPolicy {
+	return domain.HotContentPolicy{
+		PostEngagementThreshold: int64(c.HotPostEngagementThreshold),
+		UserFollowerThreshold:   int64(c.HotUserFollowerThreshold),
+		AggregationWindow:       time.Duration(c.HotNotificationAggregationMinutes) * time.Minute,
+	}
+}
+
+// GetMediaCDN returns the CDN hosts stored files are served through, none when unset
+func (c *Config) GetMediaCDN() domain.MediaCDN {
+	cdn := domain.MediaCDN{
+		Hosts:          make(map[string]string),
+		SigningKeyName: c.CDNSigningKeyName,
+		SignedURLTTL:   time.Duration(c.CDNSignedURLTTLMinutes) * time.Minute,
+	}
+	for _, entry := range c.CDNHosts {
+		bucket, baseURL, ok := strings.Cut(entry, "=")
+		if !ok || bucket == "" || !strings.HasPrefix(baseURL, "https://") {
+			log.Printf("Invalid CDN host entry: %s", entry)
+			continue
+		}
+		cdn.Hosts[bucket] = baseURL
+	}
+
+	if c.CDNSigningKey != "" {
+		key, err := base64.URLEncoding.DecodeString(c.CDNSigningKey)
+		if err != nil || cdn.SignedURLTTL <= 0 {
+			log.Printf("Invalid CDN signing key or TTL, CDN URLs are not used")
+			cdn.Hosts = map[string]string{}
+			return cdn
+		}
+		cdn.SigningKey = key
+	}
+	return cdn
+}
+
+// GetRateLimits returns the limit of each rate limit bucket. Buckets left out
+// or set to 0 requests aren't limited.
+func (c *Config) GetRateLimits() map[string]domain.RateLimit {
+	limits := make(map[string]domain.RateLimit)
+	for _, entry := range c.RateLimits {
+		bucket, value, ok := strings.Cut(entry, "=")
+		requests, seconds, ok2 := strings.Cut(value, "/")
+		if !ok || !ok2 || bucket == "" {
+			log.Printf("Invalid rate limit entry: %s", entry)
+			continue
+		}
+		requestCount, err := strconv.Atoi(requests)
+		windowSeconds, err2 := strconv.Atoi(seconds)
+		if err != nil || err2 != nil || requestCount < 0 || windowSeconds <= 0 {
+			log.Printf("Invalid rate limit entry: %s", entry)
+			continue
+		}
+		limits[bucket] = domain.RateLimit{
+			Requests: requestCount,
+			Window:   time.Duration(windowSeconds) * time.Second,
+		}
+	}
+	return limits
+}
+
 // getEnv gets environment variable with fallback
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -78,3 +373,86 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt gets integer environment variable with fallback
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value for %s: %v", key, err)
+		return defaultValue
+	}
+	return intValue
+}
+
+// parseList parses a comma-separated list, skipping empty entries
+func parseList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// parseServiceAccounts parses "clientId:sha256(secret):scope|scope" entries separated by ";"
+func parseServiceAccounts(value string) []domain.ServiceAccount {
+	accounts := make([]domain.ServiceAccount, 0)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			log.Printf("Invalid service account entry: %s", parts[0])
+			continue
+		}
+
+		accounts = append(accounts, domain.ServiceAccount{
+			ClientID:   parts[0],
+			SecretHash: strings.ToLower(parts[1]),
+			Scopes:     strings.Split(parts[2], "|"),
+		})
+	}
+	return accounts
+}
+
+// parseImageProcessing parses "context:option|option" entries separated by ";".
+// Options are strip (remove metadata) and orient (normalize orientation), "none" turns both off.
+func parseImageProcessing(value string) map[string]domain.ImageProcessing {
+	settings := make(map[string]domain.ImageProcessing)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.Printf("Invalid image processing entry: %s", entry)
+			continue
+		}
+
+		var processing domain.ImageProcessing
+		for _, option := range strings.Split(parts[1], "|") {
+			switch strings.TrimSpace(option) {
+			case "strip":
+				processing.StripMetadata = true
+			case "orient":
+				processing.NormalizeOrientation = true
+			case "none", "":
+			default:
+				log.Printf("Unknown image processing option %q for %s", option, parts[0])
+			}
+		}
+		settings[parts[0]] = processing
+	}
+	return settings
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// LoadJWTKeys reads the JWT key set from JWT_KEYS_FILE and appends the legacy JWT_SECRET
+// as the default key so tokens issued before rotation keep verifying.
+func LoadJWTKeys(config *Config) ([]domain.JWTKey, error) {
+	var keys []domain.JWTKey
+
+	if config.JWTKeysFile != "" {
+		data, err := os.ReadFile(config.JWTKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading jwt keys file: %v", err)
+		}
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("error parsing jwt keys file: %v", err)
+		}
+	}
+
+	hasDefault := false
+	for _, key := range keys {
+		if key.ID == "" || key.Secret == "" {
+			return nil, fmt.Errorf("jwt key must have kid and secret")
+		}
+		if key.ID == domain.DefaultJWTKeyID {
+			hasDefault = true
+		}
+	}
+
+	if !hasDefault && config.JWTSecret != "" {
+		keys = append(keys, domain.JWTKey{
+			ID:     domain.DefaultJWTKeyID,
+			Secret: config.JWTSecret,
+		})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no jwt keys configured")
+	}
+
+	return keys, nil
+}
+
+// InitJWTKeySet loads the key set once at startup
+func InitJWTKeySet(config *Config) (*domain.JWTKeySet, error) {
+	keys, err := LoadJWTKeys(config)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewJWTKeySet(keys), nil
+}
+
+// WatchJWTKeySet periodically reloads the key set from the secret store so rotated keys
+// are picked up without a restart. A failed reload keeps the current keys.
+func WatchJWTKeySet(config *Config, keySet *domain.JWTKeySet) {
+	if config.JWTKeysFile == "" || config.JWTKeysReloadMinutes <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(config.GetJWTKeysReloadInterval())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		keys, err := LoadJWTKeys(config)
+		if err != nil {
+			log.Printf("Failed to reload JWT keys: %v", err)
+			continue
+		}
+		keySet.Replace(keys)
+	}
+}
@@ -8,21 +8,21 @@ import (
 )
 
 type SystemAuthAdapter struct {
-	jwtSecret string
+	jwtKeys     *domain.JWTKeySet
+	tokenPolicy domain.TokenPolicy
 }
 
-func NewSystemAuthAdapter(jwtSecret string) domain.AuthClient {
+func NewSystemAuthAdapter(jwtKeys *domain.JWTKeySet, tokenPolicy domain.TokenPolicy) domain.AuthClient {
 	return &SystemAuthAdapter{
-		jwtSecret: jwtSecret,
+		jwtKeys:     jwtKeys,
+		tokenPolicy: tokenPolicy,
 	}
 }
 
 func (a *SystemAuthAdapter) VerifyToken(token string) (*domain.Claims, error) {
 	// Parse token
 	claims := &domain.Claims{}
-	parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(a.jwtSecret), nil
-	})
+	parsedToken, err := jwt.ParseWithClaims(token, claims, a.jwtKeys.Keyfunc, a.tokenPolicy.ParserOptions()...)
 	if err != nil {
 		return nil, err
 	}
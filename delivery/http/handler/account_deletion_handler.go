@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// AccountDeletionHandler deletes the current user's account after a grace period
+type AccountDeletionHandler struct {
+	accountDeletionUseCase domain.AccountDeletionUseCase
+}
+
+func NewAccountDeletionHandler(router fiber.Router, accountDeletionUseCase domain.AccountDeletionUseCase) *AccountDeletionHandler {
+	handler := &AccountDeletionHandler{
+		accountDeletionUseCase: accountDeletionUseCase,
+	}
+
+	router.Delete("/", handler.DeleteAccount)
+	router.Get("/me/deletion", handler.GetDeletion)
+	router.Post("/me/deletion/cancel", handler.CancelDeletion)
+
+	return handler
+}
+
+// DeleteAccount godoc
+// @Summary Delete my account
+// @Description Deactivate the current user's account and purge its posts, comments, reactions and
+// @Description chat memberships after 30 days. Signing in and cancelling before then restores it.
+// @Tags users
+// @Produce json
+// @Success 202 {object} domain.AccountDeletion
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users [delete]
+// @Security BearerAuth
+func (h *AccountDeletionHandler) DeleteAccount(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AccountDeletionHandler.DeleteAccount")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	deletion, err := h.accountDeletionUseCase.RequestDeletion(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(deletion, nil)
+	return c.Status(fiber.StatusAccepted).JSON(deletion)
+}
+
+// GetDeletion godoc
+// @Summary Get my account deletion
+// @Description Get when the current user asked to delete their account and when it will be purged
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.AccountDeletion
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse "The account isn't being deleted"
+// @Router /users/me/deletion [get]
+// @Security BearerAuth
+func (h *AccountDeletionHandler) GetDeletion(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AccountDeletionHandler.GetDeletion")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	deletion, err := h.accountDeletionUseCase.GetDeletion(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(deletion, nil)
+	return c.JSON(deletion)
+}
+
+// CancelDeletion godoc
+// @Summary Cancel my account deletion
+// @Description Reactivate the current user's account during the grace period
+// @Tags users
+// @Success 204
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse "The account isn't being deleted"
+// @Router /users/me/deletion/cancel [post]
+// @Security BearerAuth
+func (h *AccountDeletionHandler) CancelDeletion(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AccountDeletionHandler.CancelDeletion")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	if err := h.accountDeletionUseCase.CancelDeletion(userID); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
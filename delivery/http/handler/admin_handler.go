@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type AdminHandler struct {
+	adminUseCase domain.AdminUseCase
+}
+
+func NewAdminHandler(router fiber.Router, adminUseCase domain.AdminUseCase) *AdminHandler {
+	handler := &AdminHandler{
+		adminUseCase: adminUseCase,
+	}
+
+	router.Get("/deleted/:type", handler.ListDeletedContent)
+	router.Get("/deleted/:type/:id", handler.GetDeletedContent)
+	router.Post("/deleted/:type/:id/restore", handler.RestoreContent)
+	router.Get("/audit-logs", handler.ListAuditLogs)
+	router.Get("/banned-media", handler.ListBannedMedia)
+	router.Post("/banned-media", handler.BanMedia)
+	router.Delete("/banned-media/:id", handler.UnbanMedia)
+
+	return handler
+}
+
+// ListDeletedContent godoc
+// @Summary List soft-deleted content
+// @Description List soft-deleted users, posts or stories still inside the retention window
+// @Tags admin
+// @Produce json
+// @Param type path string true "Content type (user, post, story)"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.DeletedContent
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/deleted/{type} [get]
+// @Security BearerAuth
+func (h *AdminHandler) ListDeletedContent(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.ListDeletedContent")
+
+	contentType := domain.DeletedContentType(c.Params("type"))
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"type":   contentType,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	contents, err := h.adminUseCase.ListDeletedContent(contentType, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(contents)}, nil)
+	return c.JSON(contents)
+}
+
+// GetDeletedContent godoc
+// @Summary Preview soft-deleted content
+// @Description Get the deleted payload of a user, post or story
+// @Tags admin
+// @Produce json
+// @Param type path string true "Content type (user, post, story)"
+// @Param id path string true "Content ID"
+// @Success 200 {object} domain.DeletedContent
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/deleted/{type}/{id} [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetDeletedContent(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.GetDeletedContent")
+
+	contentType := domain.DeletedContentType(c.Params("type"))
+	id := c.Params("id")
+	logger.LogInput(map[string]interface{}{
+		"type": contentType,
+		"id":   id,
+	})
+
+	content, err := h.adminUseCase.GetDeletedContent(contentType, id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(content, nil)
+	return c.JSON(content)
+}
+
+// RestoreContent godoc
+// @Summary Restore soft-deleted content
+// @Description Restore a user, post or story and record who restored it
+// @Tags admin
+// @Produce json
+// @Param type path string true "Content type (user, post, story)"
+// @Param id path string true "Content ID"
+// @Success 200 {object} domain.AuditLog
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /admin/deleted/{type}/{id}/restore [post]
+// @Security BearerAuth
+func (h *AdminHandler) RestoreContent(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.RestoreContent")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	contentType := domain.DeletedContentType(c.Params("type"))
+	id := c.Params("id")
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"type":    contentType,
+		"id":      id,
+	})
+
+	auditLog, err := h.adminUseCase.RestoreContent(adminID, contentType, id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(auditLog, nil)
+	return c.JSON(auditLog)
+}
+
+// ListAuditLogs godoc
+// @Summary List admin audit records
+// @Description List audit records, optionally filtered by target
+// @Tags admin
+// @Produce json
+// @Param targetType query string false "Target type (user, post, story)"
+// @Param targetId query string false "Target ID"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.AuditLog
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/audit-logs [get]
+// @Security BearerAuth
+func (h *AdminHandler) ListAuditLogs(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.ListAuditLogs")
+
+	targetType := c.Query("targetType")
+	targetID := c.Query("targetId")
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"targetType": targetType,
+		"targetId":   targetID,
+		"limit":      limit,
+		"offset":     offset,
+	})
+
+	logs, err := h.adminUseCase.ListAuditLogs(targetType, targetID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(logs)}, nil)
+	return c.JSON(logs)
+}
+
+type BanMediaRequest struct {
+	PerceptualHash string `json:"perceptualHash,omitempty"` // 16 hex digits
+	FileURL        string `json:"fileUrl,omitempty"`        // an uploaded image, used when no hash is given
+	Reason         string `json:"reason,omitempty"`
+}
+
+// BanMedia godoc
+// @Summary Ban imagery
+// @Description Reject future uploads that look like the given image, by perceptual hash or the URL of an uploaded image
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body BanMediaRequest true "Hash or file URL"
+// @Success 201 {object} domain.BannedMedia
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /admin/banned-media [post]
+// @Security BearerAuth
+func (h *AdminHandler) BanMedia(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.BanMedia")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req BanMediaRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"request": req,
+	})
+
+	media, err := h.adminUseCase.BanMedia(adminID, req.PerceptualHash, req.FileURL, req.Reason)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(media, nil)
+	return c.Status(fiber.StatusCreated).JSON(media)
+}
+
+// ListBannedMedia godoc
+// @Summary List banned imagery
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.BannedMedia
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/banned-media [get]
+// @Security BearerAuth
+func (h *AdminHandler) ListBannedMedia(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.ListBannedMedia")
+
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	media, err := h.adminUseCase.ListBannedMedia(limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(media)}, nil)
+	return c.JSON(media)
+}
+
+// UnbanMedia godoc
+// @Summary Lift a media ban
+// @Tags admin
+// @Produce json
+// @Param id path string true "Banned media ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/banned-media/{id} [delete]
+// @Security BearerAuth
+func (h *AdminHandler) UnbanMedia(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AdminHandler.UnbanMedia")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	id := c.Params("id")
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"id":      id,
+	})
+
+	if err := h.adminUseCase.UnbanMedia(adminID, id); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Media ban lifted", nil)
+	return utils.SendSuccess(c, "Media ban lifted")
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type AnalyticsHandler struct {
+	analyticsUseCase domain.AnalyticsUseCase
+}
+
+func NewAnalyticsHandler(router fiber.Router, analyticsUseCase domain.AnalyticsUseCase) *AnalyticsHandler {
+	handler := &AnalyticsHandler{
+		analyticsUseCase: analyticsUseCase,
+	}
+
+	router.Post("/", handler.TrackEvents)
+
+	return handler
+}
+
+type TrackEventsRequest struct {
+	Events []domain.AnalyticsEvent `json:"events"`
+}
+
+// TrackEvents godoc
+// @Summary Send analytics events
+// @Description Accept a batch of up to 100 client analytics events, such as screen_view and tap. Every event is validated against the event schema registry, and an invalid event rejects the whole batch.
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param request body TrackEventsRequest true "Event batch"
+// @Success 202 {object} map[string]int
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /events [post]
+// @Security BearerAuth
+func (h *AnalyticsHandler) TrackEvents(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AnalyticsHandler.TrackEvents")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req TrackEventsRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"count":  len(req.Events),
+	})
+
+	if err := h.analyticsUseCase.TrackEvents(userID.Hex(), req.Events); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(len(req.Events), nil)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"accepted": len(req.Events),
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIUsageHandler serves the API usage statistics of users to themselves and to admins
+type APIUsageHandler struct {
+	usageUseCase domain.APIUsageUseCase
+}
+
+func NewAPIUsageHandler(me fiber.Router, admin fiber.Router, usageUseCase domain.APIUsageUseCase) *APIUsageHandler {
+	handler := &APIUsageHandler{
+		usageUseCase: usageUseCase,
+	}
+
+	me.Get("/", handler.GetMyUsage)
+	admin.Get("/users/:id/usage", handler.GetUserUsage)
+
+	return handler
+}
+
+// GetMyUsage godoc
+// @Summary Get my API usage
+// @Description Daily request counts per route and the latest requests of the current user
+// @Tags users
+// @Produce json
+// @Param days query int false "Number of days to cover, up to 30 (default 7)"
+// @Success 200 {object} domain.APIUsage
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/me/usage [get]
+// @Security BearerAuth
+func (h *APIUsageHandler) GetMyUsage(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		utils.NewLogger("APIUsageHandler.GetMyUsage").LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	return h.getUsage(c, "APIUsageHandler.GetMyUsage", userID)
+}
+
+// GetUserUsage godoc
+// @Summary Get a user's API usage
+// @Description Daily request counts per route and the latest requests of a user, for abuse investigations
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param days query int false "Number of days to cover, up to 30 (default 7)"
+// @Success 200 {object} domain.APIUsage
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/users/{id}/usage [get]
+// @Security BearerAuth
+func (h *APIUsageHandler) GetUserUsage(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		utils.NewLogger("APIUsageHandler.GetUserUsage").LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	return h.getUsage(c, "APIUsageHandler.GetUserUsage", userID)
+}
+
+func (h *APIUsageHandler) getUsage(c *fiber.Ctx, name string, userID primitive.ObjectID) error {
+	logger := utils.NewLogger(name)
+
+	days := utils.GetQueryInt(c, "days", domain.DefaultAPIUsageDays)
+	logger.LogInput(userID, days)
+
+	usage, err := h.usageUseCase.GetUsage(userID.Hex(), days)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"total": usage.Total}, nil)
+	return c.JSON(usage)
+}
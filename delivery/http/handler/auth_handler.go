@@ -30,12 +30,10 @@ func (h *AuthHandler) CreateTestToken(c *fiber.Ctx) error {
 	logger := utils.NewLogger("AuthHandler.CreateTestToken")
 
 	var req CreateTestTokenRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(req)
@@ -61,24 +59,20 @@ func (h *AuthHandler) VerifyTokenFirebase(c *fiber.Ctx) error {
 	logger := utils.NewLogger("AuthHandler.VerifyTokenFirebase")
 
 	var req struct {
-		FirebaseToken string `json:"firebaseToken"`
+		FirebaseToken string `json:"firebaseToken" validate:"required"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(req)
 	user, tokenPair, err := h.authUseCase.VerifyTokenFirebase(c.Context(), req.FirebaseToken)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.SendError(c, fiber.StatusUnauthorized, err.Error())
 	}
 
 	response := LoginResponse{
@@ -96,21 +90,17 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	logger := utils.NewLogger("AuthHandler.RefreshToken")
 
 	var req RefreshTokenRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(req)
 	tokenPair, err := h.authUseCase.RefreshToken(c.Context(), req.RefreshToken)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.SendError(c, fiber.StatusUnauthorized, err.Error())
 	}
 
 	response := TokenResponse{
@@ -127,42 +117,318 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	logger := utils.NewLogger("AuthHandler.Logout")
 
 	var req LogoutRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(req)
 	err := h.authUseCase.RevokeRefreshToken(c.Context(), req.RefreshToken)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput("Successfully logged out", nil)
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// Register creates an email/password account
+// @Summary Register with email and password
+// @Description Creates an account and emails a link to verify the address
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RegisterRequest true "Email and password"
+// @Success 201 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.Register")
+
+	var req RegisterRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(req.Email)
+	user, tokenPair, err := h.authUseCase.Register(c.Context(), req.Email, req.Password)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	response := LoginResponse{
+		User:         user,
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+	}
+
+	logger.LogOutput(user.ID, nil)
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// Login signs in with email and password
+// @Summary Login with email and password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RegisterRequest true "Email and password"
+// @Success 200 {object} LoginResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.Login")
+
+	var req RegisterRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(req.Email)
+	user, tokenPair, err := h.authUseCase.Login(c.Context(), req.Email, req.Password)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	response := LoginResponse{
+		User:         user,
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+	}
+
+	logger.LogOutput(user.ID, nil)
+	return c.JSON(response)
+}
+
+// VerifyEmail verifies the email address with the token from the verification link
+// @Summary Verify email address
+// @Tags auth
+// @Accept json
+// @Param request body EmailTokenRequest true "Token from the link"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.VerifyEmail")
+
+	var req EmailTokenRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	if err := h.authUseCase.VerifyEmail(c.Context(), req.Token); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendEmailVerification sends another verification link
+// @Summary Resend email verification
+// @Description Always accepted, so it doesn't tell who has an account
+// @Tags auth
+// @Accept json
+// @Param request body EmailRequest true "Email address"
+// @Success 202
+// @Router /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendEmailVerification(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.ResendEmailVerification")
+
+	var req EmailRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(req.Email)
+	if err := h.authUseCase.SendEmailVerification(c.Context(), req.Email); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ForgotPassword emails a password reset link
+// @Summary Request a password reset
+// @Description Always accepted, so it doesn't tell who has an account
+// @Tags auth
+// @Accept json
+// @Param request body EmailRequest true "Email address"
+// @Success 202
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.ForgotPassword")
+
+	var req EmailRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(req.Email)
+	if err := h.authUseCase.RequestPasswordReset(c.Context(), req.Email); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ResetPassword sets a new password with the token from the reset link
+// @Summary Reset password
+// @Description Sets the new password and revokes every refresh token of the user
+// @Tags auth
+// @Accept json
+// @Param request body ResetPasswordRequest true "Token from the link and the new password"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.ResetPassword")
+
+	var req ResetPasswordRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	if err := h.authUseCase.ResetPassword(c.Context(), req.Token, req.Password); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 // Request/Response types
 type LoginRequest struct {
-	FirebaseToken string `json:"firebaseToken" example:"firebase_id_token_here"`
+	FirebaseToken string `json:"firebaseToken" validate:"required" example:"firebase_id_token_here"`
 }
 
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refreshToken" example:"refresh_token_here"`
+	RefreshToken string `json:"refreshToken" validate:"required" example:"refresh_token_here"`
 }
 
 type LogoutRequest struct {
-	RefreshToken string `json:"refreshToken" example:"refresh_token_here"`
+	RefreshToken string `json:"refreshToken" validate:"required" example:"refresh_token_here"`
+}
+
+// Introspect describes an access or service token to internal services
+// @Summary Introspect a token
+// @Description Reports whether the token is active and, when it is, who it's for and what it grants.
+// @Description Requires a service token with the tokens:introspect scope.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body IntrospectRequest true "Token to describe"
+// @Success 200 {object} domain.TokenIntrospection
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/introspect [post]
+// @Security BearerAuth
+func (h *AuthHandler) Introspect(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.Introspect")
+
+	var req IntrospectRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	introspection, err := h.authUseCase.IntrospectToken(c.Context(), req.Token)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(introspection.Active, nil)
+	return c.JSON(introspection)
+}
+
+// IssueWSTicket issues a ticket to open a WebSocket with
+// @Summary Issue a WebSocket ticket
+// @Description Returns a single use ticket that's good for 30 seconds. Open /api/ws?ticket=... (or
+// @Description /api/ws/ops for the ops purpose) with it instead of the access token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body WSTicketRequest false "Socket the ticket is for, chat by default"
+// @Success 200 {object} domain.WSTicket
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/ws-ticket [post]
+// @Security BearerAuth
+func (h *AuthHandler) IssueWSTicket(c *fiber.Ctx) error {
+	logger := utils.NewLogger("AuthHandler.IssueWSTicket")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	req := WSTicketRequest{Purpose: domain.WSTicketChat}
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+	if req.Purpose == "" {
+		req.Purpose = domain.WSTicketChat
+	}
+	logger.LogInput(userID, req.Purpose)
+
+	ticket, err := h.authUseCase.IssueWSTicket(c.Context(), userID.Hex(), req.Purpose)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(ticket.ExpiresAt, nil)
+	return c.JSON(ticket)
+}
+
+type WSTicketRequest struct {
+	Purpose string `json:"purpose" validate:"omitempty,oneof=chat ops" example:"chat"`
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required" example:"user@example.com"`
+	Password string `json:"password" validate:"required" example:"password_here"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" validate:"required" example:"user@example.com"`
+}
+
+type IntrospectRequest struct {
+	Token string `json:"token" form:"token" validate:"required" example:"access_token_here"`
+}
+
+type EmailTokenRequest struct {
+	Token string `json:"token" validate:"required" example:"token_from_email_link"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" example:"token_from_email_link"`
+	Password string `json:"password" validate:"required" example:"new_password_here"`
 }
 
 type CreateTestTokenRequest struct {
-	UserID string `json:"userId" example:"userId_here"`
+	UserID string `json:"userId" validate:"required" example:"userId_here"`
 }
 
 type LoginResponse struct {
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// parseBatchRequest reads the IDs of a batch fetch request and enforces domain.MaxBatchSize
+func parseBatchRequest(c *fiber.Ctx) ([]string, error) {
+	var req domain.BatchRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return nil, err
+	}
+	if len(req.IDs) > domain.MaxBatchSize {
+		return nil, domain.NewValidationError("invalid request", map[string]string{
+			"ids": fmt.Sprintf("must be at most %d items", domain.MaxBatchSize),
+		})
+	}
+	return req.IDs, nil
+}
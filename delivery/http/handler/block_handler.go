@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// BlockHandler lists the accounts a user blocked. Blocking and unblocking go
+// through the follow and friendship endpoints.
+type BlockHandler struct {
+	followUseCase domain.FollowUseCase
+}
+
+func NewBlockHandler(router fiber.Router, followUseCase domain.FollowUseCase) *BlockHandler {
+	handler := &BlockHandler{
+		followUseCase: followUseCase,
+	}
+
+	router.Get("/blocked", handler.ListBlocked)
+
+	return handler
+}
+
+// ListBlocked godoc
+// @Summary List blocked users
+// @Description List the users the current user blocked, latest first
+// @Tags users
+// @Produce json
+// @Param limit query int false "Page size (default 20)"
+// @Param offset query int false "Offset"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} domain.Page
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/blocked [get]
+// @Security BearerAuth
+func (h *BlockHandler) ListBlocked(c *fiber.Ctx) error {
+	logger := utils.NewLogger("BlockHandler.ListBlocked")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
+	logger.LogInput(userID, limit, offset)
+
+	blocked, total, err := h.followUseCase.ListBlocked(userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(blocked, len(blocked), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
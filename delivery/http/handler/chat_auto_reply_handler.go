@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// GetAutoReply returns the current user's away auto-reply, null when it's off
+func (h *ChatHandler) GetAutoReply(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.GetAutoReply")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID.Hex())
+
+	reply, err := h.chatUsecase.GetAutoReply(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(reply, nil)
+	return c.JSON(fiber.Map{
+		"autoReply": reply,
+	})
+}
+
+// SetAutoReply turns the away auto-reply on, answering the first message of each
+// sender per day in private chats until it's cleared or until passes
+func (h *ChatHandler) SetAutoReply(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.SetAutoReply")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.ChatAutoReply
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID.Hex(), req)
+
+	reply, err := h.chatUsecase.SetAutoReply(userID.Hex(), req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(reply, nil)
+	return c.JSON(fiber.Map{
+		"autoReply": reply,
+	})
+}
+
+// ClearAutoReply turns the away auto-reply off
+func (h *ChatHandler) ClearAutoReply(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.ClearAutoReply")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID.Hex())
+
+	if err := h.chatUsecase.ClearAutoReply(userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
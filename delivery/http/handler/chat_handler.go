@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
@@ -21,14 +21,36 @@ func NewChatHandler(router fiber.Router, chatUsecase domain.ChatUsecase) {
 	router.Post("/rooms/private", handler.CreatePrivateChat)
 	router.Post("/rooms/group", handler.CreateGroupChat)
 	router.Get("/rooms", handler.GetUserChats)
+	router.Get("/rooms/unread-counts", handler.GetUnreadCounts)
+	router.Put("/rooms/:roomId", handler.UpdateRoomDetails)
 	router.Post("/rooms/:roomId/members", handler.AddMemberToGroup)
 	router.Delete("/rooms/:roomId/members/:userId", handler.RemoveMemberFromGroup)
+	router.Get("/rooms/:roomId/settings", handler.GetRoomSettings)
+	router.Put("/rooms/:roomId/settings", handler.UpdateRoomSettings)
+
+	// Invite link endpoints
+	router.Post("/rooms/:roomId/invites", handler.CreateInviteLink)
+	router.Get("/rooms/:roomId/invites", handler.GetRoomInvites)
+	router.Delete("/rooms/:roomId/invites/:inviteId", handler.RevokeInvite)
+	router.Post("/invites/join", handler.JoinByInvite)
 
 	// Message endpoints
 	router.Post("/messages", handler.SendMessage)
 	router.Post("/messages/file", handler.SendFileMessage)
 	router.Get("/rooms/:roomId/messages", handler.GetChatMessages)
+	router.Get("/rooms/:roomId/typing", handler.GetTypingUsers)
 	router.Put("/messages/:messageId/read", handler.MarkMessageRead)
+	router.Post("/rooms/:roomId/read", handler.MarkRoomRead)
+	router.Put("/messages/:messageId", handler.EditMessage)
+	router.Delete("/messages/:messageId", handler.DeleteMessage)
+	router.Get("/messages/:messageId/thread", handler.GetThread)
+	router.Post("/messages/:messageId/thread", handler.SendThreadReply)
+	router.Post("/messages/:messageId/thread/read", handler.MarkThreadRead)
+
+	// Away auto-reply endpoints
+	router.Get("/auto-reply", handler.GetAutoReply)
+	router.Put("/auto-reply", handler.SetAutoReply)
+	router.Delete("/auto-reply", handler.ClearAutoReply)
 
 	// User status endpoints
 	router.Put("/status", handler.UpdateUserStatus)
@@ -46,10 +68,8 @@ func (h *ChatHandler) CreatePrivateChat(c *fiber.Ctx) error {
 		UserID2 string `json:"userId2" binding:"required"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.HandleError(c, err)
 	}
 
 	logger := utils.NewLogger("ChatHandler.CreatePrivateChat")
@@ -61,9 +81,7 @@ func (h *ChatHandler) CreatePrivateChat(c *fiber.Ctx) error {
 	room, err := h.chatUsecase.CreatePrivateChat(req.UserID1, req.UserID2)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(room, nil)
@@ -76,24 +94,28 @@ func (h *ChatHandler) CreateGroupChat(c *fiber.Ctx) error {
 		MemberIDs []string `json:"memberIds" binding:"required"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.HandleError(c, err)
 	}
 
 	logger := utils.NewLogger("ChatHandler.CreateGroupChat")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogInput(map[string]interface{}{
+		"creatorID": userID.Hex(),
 		"name":      req.Name,
 		"memberIDs": req.MemberIDs,
 	})
 
-	room, err := h.chatUsecase.CreateGroupChat(req.Name, req.MemberIDs)
+	room, err := h.chatUsecase.CreateGroupChat(userID.Hex(), req.Name, req.MemberIDs)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(room, nil)
@@ -106,9 +128,7 @@ func (h *ChatHandler) GetUserChats(c *fiber.Ctx) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(userID.Hex())
@@ -116,9 +136,7 @@ func (h *ChatHandler) GetUserChats(c *fiber.Ctx) error {
 	rooms, err := h.chatUsecase.GetUserChats(userID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(rooms, nil)
@@ -129,27 +147,30 @@ func (h *ChatHandler) AddMemberToGroup(c *fiber.Ctx) error {
 	logger := utils.NewLogger("ChatHandler.AddMemberToGroup")
 	roomID := c.Params("roomId")
 
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	var req struct {
 		UserID string `json:"userId" binding:"required"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(map[string]string{
-		"roomID": roomID,
-		"userID": req.UserID,
+		"roomID":  roomID,
+		"actorID": actorID.Hex(),
+		"userID":  req.UserID,
 	})
 
-	if err := h.chatUsecase.AddMemberToGroup(roomID, req.UserID); err != nil {
+	if err := h.chatUsecase.AddMemberToGroup(roomID, actorID.Hex(), req.UserID); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
@@ -161,16 +182,21 @@ func (h *ChatHandler) RemoveMemberFromGroup(c *fiber.Ctx) error {
 	roomID := c.Params("roomId")
 	userID := c.Params("userId")
 
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogInput(map[string]string{
-		"roomID": roomID,
-		"userID": userID,
+		"roomID":  roomID,
+		"actorID": actorID.Hex(),
+		"userID":  userID,
 	})
 
-	if err := h.chatUsecase.RemoveMemberFromGroup(roomID, userID); err != nil {
+	if err := h.chatUsecase.RemoveMemberFromGroup(roomID, actorID.Hex(), userID); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
@@ -184,22 +210,19 @@ func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
 	senderID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	var req struct {
-		RoomID  string `json:"roomId" binding:"required"`
-		Content string `json:"content" binding:"required"`
-		Type    string `json:"type" binding:"required"`
+		RoomID           string `json:"roomId" binding:"required"`
+		Content          string `json:"content" binding:"required"`
+		Type             string `json:"type" binding:"required"`
+		ReplyToMessageID string `json:"replyToMessageId"` // optional, the message to quote
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(map[string]string{
@@ -207,14 +230,13 @@ func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
 		"senderID": senderID.Hex(),
 		"type":     req.Type,
 		"content":  req.Content,
+		"replyTo":  req.ReplyToMessageID,
 	})
 
-	message, err := h.chatUsecase.SendMessage(req.RoomID, senderID.Hex(), req.Type, req.Content)
+	message, err := h.chatUsecase.SendMessage(req.RoomID, senderID.Hex(), req.Type, req.Content, req.ReplyToMessageID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return sendChatError(c, err)
 	}
 
 	logger.LogOutput(message, nil)
@@ -227,23 +249,17 @@ func (h *ChatHandler) SendFileMessage(c *fiber.Ctx) error {
 	senderID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	var req struct {
-		RoomID   string `json:"roomId" binding:"required"`
-		FileType string `json:"fileType" binding:"required"`
-		FileSize int64  `json:"fileSize" binding:"required"`
-		FileURL  string `json:"fileUrl" binding:"required"`
+		RoomID string `json:"roomId" binding:"required"`
+		domain.ChatFileMessage
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(map[string]interface{}{
@@ -252,14 +268,13 @@ func (h *ChatHandler) SendFileMessage(c *fiber.Ctx) error {
 		"fileType": req.FileType,
 		"fileSize": req.FileSize,
 		"fileURL":  req.FileURL,
+		"duration": req.Duration,
 	})
 
-	message, err := h.chatUsecase.SendFileMessage(req.RoomID, senderID.Hex(), req.FileType, req.FileSize, req.FileURL)
+	message, err := h.chatUsecase.SendFileMessage(req.RoomID, senderID.Hex(), req.ChatFileMessage)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return sendChatError(c, err)
 	}
 
 	logger.LogOutput(message, nil)
@@ -269,25 +284,39 @@ func (h *ChatHandler) SendFileMessage(c *fiber.Ctx) error {
 func (h *ChatHandler) GetChatMessages(c *fiber.Ctx) error {
 	logger := utils.NewLogger("ChatHandler.GetChatMessages")
 	roomID := c.Params("roomId")
-	limit, _ := strconv.Atoi(c.Query("limit", "50"))
-	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	limit, offset := utils.GetCursorParams(c, 50)
+	// afterSeq fetches the messages a client missed, oldest first
+	afterSeq := int64(utils.GetQueryInt(c, "afterSeq", 0))
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
 
 	logger.LogInput(map[string]interface{}{
-		"roomID": roomID,
-		"limit":  limit,
-		"offset": offset,
+		"roomID":   roomID,
+		"userID":   userID.Hex(),
+		"afterSeq": afterSeq,
+		"limit":    limit,
+		"offset":   offset,
 	})
 
-	messages, err := h.chatUsecase.GetChatMessages(roomID, limit, offset)
+	messages, err := h.chatUsecase.GetChatMessages(roomID, userID.Hex(), afterSeq, limit, offset)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
-	logger.LogOutput(messages, nil)
-	return c.JSON(messages)
+	total, err := h.chatUsecase.CountChatMessages(roomID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(messages, len(messages), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
 }
 
 func (h *ChatHandler) MarkMessageRead(c *fiber.Ctx) error {
@@ -297,9 +326,7 @@ func (h *ChatHandler) MarkMessageRead(c *fiber.Ctx) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(map[string]interface{}{
@@ -309,15 +336,134 @@ func (h *ChatHandler) MarkMessageRead(c *fiber.Ctx) error {
 
 	if err := h.chatUsecase.MarkMessageRead(messageID, userID.Hex()); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// MarkRoomRead marks every message in the room up to readUpTo (default now) as read
+func (h *ChatHandler) MarkRoomRead(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.MarkRoomRead")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req struct {
+		ReadUpTo time.Time `json:"readUpTo"`
+	}
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID":   roomID,
+		"userID":   userID.Hex(),
+		"readUpTo": req.ReadUpTo,
+	})
+
+	result, err := h.chatUsecase.MarkRoomRead(roomID, userID.Hex(), req.ReadUpTo)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(result, nil)
+	return c.JSON(result)
+}
+
+// GetUnreadCounts returns roomID to unread message count for all of the user's rooms
+func (h *ChatHandler) GetUnreadCounts(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.GetUnreadCounts")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(userID.Hex())
+
+	counts, err := h.chatUsecase.GetUnreadCounts(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(counts, nil)
+	return c.JSON(counts)
+}
+
+func (h *ChatHandler) DeleteMessage(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.DeleteMessage")
+	messageID := c.Params("messageId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	// Deleting for everyone stays the default for older clients
+	scope := c.Query("scope", domain.ChatDeleteForEveryone)
+
+	logger.LogInput(map[string]interface{}{
+		"messageID": messageID,
+		"userID":    userID.Hex(),
+		"scope":     scope,
+	})
+
+	if err := h.chatUsecase.DeleteMessage(messageID, userID.Hex(), scope); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ChatHandler) EditMessage(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.EditMessage")
+	messageID := c.Params("messageId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"messageID": messageID,
+		"userID":    userID.Hex(),
+		"content":   req.Content,
+	})
+
+	message, err := h.chatUsecase.EditMessage(messageID, userID.Hex(), req.Content)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(message, nil)
+	return c.JSON(message)
+}
+
 // User status handlers
 func (h *ChatHandler) UpdateUserStatus(c *fiber.Ctx) error {
 	logger := utils.NewLogger("ChatHandler.UpdateUserStatus")
@@ -325,20 +471,16 @@ func (h *ChatHandler) UpdateUserStatus(c *fiber.Ctx) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	var req struct {
 		IsOnline bool `json:"isOnline" binding:"required"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(map[string]interface{}{
@@ -348,9 +490,7 @@ func (h *ChatHandler) UpdateUserStatus(c *fiber.Ctx) error {
 
 	if err := h.chatUsecase.UpdateUserOnlineStatus(userID.Hex(), req.IsOnline); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
@@ -360,14 +500,22 @@ func (h *ChatHandler) UpdateUserStatus(c *fiber.Ctx) error {
 func (h *ChatHandler) GetUserStatus(c *fiber.Ctx) error {
 	logger := utils.NewLogger("ChatHandler.GetUserStatus")
 	userID := c.Params("userId")
-	logger.LogInput(userID)
 
-	status, err := h.chatUsecase.GetUserOnlineStatus(userID)
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID.Hex(),
+		"userID":   userID,
+	})
+
+	status, err := h.chatUsecase.GetUserOnlineStatus(viewerID.Hex(), userID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return sendChatError(c, err)
 	}
 
 	logger.LogOutput(status, nil)
@@ -381,9 +529,7 @@ func (h *ChatHandler) GetUserNotifications(c *fiber.Ctx) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(userID.Hex())
@@ -391,9 +537,7 @@ func (h *ChatHandler) GetUserNotifications(c *fiber.Ctx) error {
 	notifications, err := h.chatUsecase.GetUserNotifications(userID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(notifications, nil)
@@ -407,9 +551,7 @@ func (h *ChatHandler) MarkNotificationRead(c *fiber.Ctx) error {
 
 	if err := h.chatUsecase.MarkNotificationRead(notificationID); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
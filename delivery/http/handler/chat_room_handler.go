@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// sendChatError maps catalog errors to their status and everything else to 500.
+// Message limit errors also carry a machine readable code and when to retry.
+func sendChatError(c *fiber.Ctx, err error) error {
+	status, ok := utils.ErrorStatus(err)
+	if !ok {
+		status = fiber.StatusInternalServerError
+	}
+
+	body := fiber.Map{
+		"error": err.Error(),
+	}
+	var limitErr *domain.ChatLimitError
+	if errors.As(err, &limitErr) {
+		body["code"] = limitErr.Code
+		if limitErr.Limit > 0 {
+			body["limit"] = limitErr.Limit
+		}
+		if limitErr.RetryAfter > 0 {
+			retryAfter := int(math.Ceil(limitErr.RetryAfter.Seconds()))
+			body["retryAfter"] = retryAfter
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		}
+	}
+	return c.Status(status).JSON(body)
+}
+
+func (h *ChatHandler) UpdateRoomDetails(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.UpdateRoomDetails")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.ChatRoomDetails
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID":  roomID,
+		"userID":  userID.Hex(),
+		"details": req,
+	})
+
+	room, err := h.chatUsecase.UpdateRoomDetails(roomID, userID.Hex(), req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(room, nil)
+	return c.JSON(room)
+}
+
+func (h *ChatHandler) CreateInviteLink(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.CreateInviteLink")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req struct {
+		ExpiresIn int `json:"expiresIn"` // seconds, defaults to 7 days
+		MaxUses   int `json:"maxUses"`   // 0 means unlimited
+	}
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID":    roomID,
+		"userID":    userID.Hex(),
+		"expiresIn": req.ExpiresIn,
+		"maxUses":   req.MaxUses,
+	})
+
+	link, err := h.chatUsecase.CreateInviteLink(roomID, userID.Hex(), time.Duration(req.ExpiresIn)*time.Second, req.MaxUses)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(link.Invite, nil)
+	return c.Status(fiber.StatusCreated).JSON(link)
+}
+
+func (h *ChatHandler) GetRoomInvites(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.GetRoomInvites")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID.Hex(),
+	})
+
+	invites, err := h.chatUsecase.GetRoomInvites(roomID, userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(invites, nil)
+	return c.JSON(invites)
+}
+
+func (h *ChatHandler) RevokeInvite(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.RevokeInvite")
+	roomID := c.Params("roomId")
+	inviteID := c.Params("inviteId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID":   roomID,
+		"inviteID": inviteID,
+		"userID":   userID.Hex(),
+	})
+
+	if err := h.chatUsecase.RevokeInvite(roomID, inviteID, userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ChatHandler) JoinByInvite(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.JoinByInvite")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req struct {
+		Token string `json:"token" validate:"required"`
+	}
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(userID.Hex())
+
+	room, err := h.chatUsecase.JoinByInvite(req.Token, userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(room, nil)
+	return c.JSON(room)
+}
+
+// GetTypingUsers lets clients that join a room late see who is already typing
+func (h *ChatHandler) GetTypingUsers(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.GetTypingUsers")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID.Hex(),
+	})
+
+	userIDs, err := h.chatUsecase.GetTypingUsers(roomID, userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(userIDs, nil)
+	return c.JSON(fiber.Map{
+		"roomId":  roomID,
+		"userIds": userIDs,
+	})
+}
+
+func (h *ChatHandler) GetRoomSettings(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.GetRoomSettings")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID.Hex(),
+	})
+
+	settings, err := h.chatUsecase.GetRoomSettings(roomID, userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(settings, nil)
+	return c.JSON(settings)
+}
+
+// UpdateRoomSettings mutes the room or changes its notification level for the current user only
+func (h *ChatHandler) UpdateRoomSettings(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.UpdateRoomSettings")
+	roomID := c.Params("roomId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.ChatRoomSettingsUpdate
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID.Hex(),
+		"update": req,
+	})
+
+	settings, err := h.chatUsecase.UpdateRoomSettings(roomID, userID.Hex(), req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(settings, nil)
+	return c.JSON(settings)
+}
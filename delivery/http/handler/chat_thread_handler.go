@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// GetThread returns the root of a thread with a page of its replies, oldest first
+func (h *ChatHandler) GetThread(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.GetThread")
+	threadID := c.Params("messageId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	limit := utils.GetQueryInt(c, "limit", 50)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"threadID": threadID,
+		"userID":   userID.Hex(),
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	thread, err := h.chatUsecase.GetThread(threadID, userID.Hex(), limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(thread, nil)
+	return c.JSON(thread)
+}
+
+// SendThreadReply replies in the thread of a group message
+func (h *ChatHandler) SendThreadReply(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.SendThreadReply")
+	threadID := c.Params("messageId")
+
+	senderID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]string{
+		"threadID": threadID,
+		"senderID": senderID.Hex(),
+		"content":  req.Content,
+	})
+
+	message, err := h.chatUsecase.SendThreadReply(threadID, senderID.Hex(), req.Content)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(message, nil)
+	return c.JSON(message)
+}
+
+// MarkThreadRead marks every reply in the thread as read
+func (h *ChatHandler) MarkThreadRead(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ChatHandler.MarkThreadRead")
+	threadID := c.Params("messageId")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]string{
+		"threadID": threadID,
+		"userID":   userID.Hex(),
+	})
+
+	result, err := h.chatUsecase.MarkThreadRead(threadID, userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(result, nil)
+	return c.JSON(result)
+}
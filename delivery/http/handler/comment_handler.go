@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type CommentHandler struct {
@@ -23,6 +26,9 @@ func NewCommentHandler(router fiber.Router, cu domain.CommentUseCase, uu domain.
 	router.Delete("/:id", handler.DeleteComment)
 	router.Get("/posts/:postId", handler.ListComments)
 	router.Get("/:id", handler.GetComment)
+	router.Get("/:id/translate", handler.TranslateComment)
+	router.Get("/:id/replies", handler.ListReplies)
+	router.Post("/:id/replies", handler.CreateReply)
 
 	return handler
 }
@@ -45,11 +51,9 @@ func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
 	}
 
 	var req CreateCommentRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	// Get userID from auth context
@@ -83,9 +87,13 @@ func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
 	comment, err := h.commentUseCase.CreateComment(userID, postID, req.Content, req.Media, replyTo)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		if suspension := domain.AsSuspensionError(err); suspension != nil {
+			return utils.SendSuspensionError(c, suspension)
+		}
+		if errors.Is(err, domain.ErrBlocked) || errors.Is(err, domain.ErrPrivacyRestricted) {
+			return utils.HandleError(c, err)
+		}
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(comment, nil)
@@ -109,25 +117,35 @@ func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
 	}
 
 	var req UpdateCommentRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
+		return utils.HandleError(c, err)
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
 
 	input := map[string]interface{}{
 		"commentID": commentID,
+		"userID":    userID,
 		"request":   req,
 	}
 	logger.LogInput(input)
 
-	comment, err := h.commentUseCase.UpdateComment(commentID, req.Content, req.Media)
+	comment, err := h.commentUseCase.UpdateComment(userID, commentID, req.Content, req.Media)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Comment not found",
+			})
+		}
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(comment, nil)
@@ -149,9 +167,7 @@ func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
 	err = h.commentUseCase.DeleteComment(commentID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput("Comment deleted successfully", nil)
@@ -173,9 +189,7 @@ func (h *CommentHandler) GetComment(c *fiber.Ctx) error {
 	comment, err := h.commentUseCase.GetComment(commentID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(comment, nil)
@@ -193,23 +207,43 @@ func (h *CommentHandler) ListComments(c *fiber.Ctx) error {
 		})
 	}
 
-	limit := c.QueryInt("limit", 0)
-	offset := c.QueryInt("offset", 0)
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
 
 	input := map[string]interface{}{
+		"userID": userID,
 		"postID": postID,
 		"limit":  limit,
 		"offset": offset,
 	}
 
-	comments, err := h.commentUseCase.ListComments(postID, limit, offset)
+	comments, err := h.commentUseCase.ListComments(userID, postID, limit, offset)
 	if err != nil {
 		logger.LogOutput(input, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
+	}
+
+	commentsWithUsers := h.withUsers(comments, logger)
+
+	total, err := h.commentUseCase.CountComments(postID)
+	if err != nil {
+		logger.LogOutput(input, err)
+		return utils.HandleError(c, err)
 	}
 
+	// Count the fetched comments so skipped ones don't end pagination early
+	page := utils.NewPage(commentsWithUsers, len(comments), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
+
+// withUsers attaches the author to each comment, skipping comments whose author can't be loaded
+func (h *CommentHandler) withUsers(comments []domain.Comment, logger *utils.Logger) []domain.CommentWithUser {
 	// Create a slice to store comments with user information
 	commentsWithUsers := make([]domain.CommentWithUser, 0, len(comments))
 
@@ -217,7 +251,7 @@ func (h *CommentHandler) ListComments(c *fiber.Ctx) error {
 	for _, comment := range comments {
 		user, err := h.userUseCase.GetUserByID(comment.UserID.Hex())
 		if err != nil {
-			logger.LogOutput(input, err)
+			logger.LogOutput(comment.ID, err)
 			continue
 		}
 
@@ -237,7 +271,140 @@ func (h *CommentHandler) ListComments(c *fiber.Ctx) error {
 		}
 		commentsWithUsers = append(commentsWithUsers, commentWithUser)
 	}
+	return commentsWithUsers
+}
+
+type CreateReplyRequest struct {
+	Content string         `json:"content"`
+	Media   []domain.Media `json:"media,omitempty"`
+}
+
+func (h *CommentHandler) CreateReply(c *fiber.Ctx) error {
+	logger := utils.NewLogger("CommentHandler.CreateReply")
+
+	commentID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	var req CreateReplyRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"commentID": commentID,
+		"userID":    userID,
+		"request":   req,
+	})
+
+	reply, err := h.commentUseCase.ReplyToComment(userID, commentID, req.Content, req.Media)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Comment not found",
+			})
+		}
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(reply, nil)
+	return c.Status(fiber.StatusCreated).JSON(reply)
+}
+
+func (h *CommentHandler) ListReplies(c *fiber.Ctx) error {
+	logger := utils.NewLogger("CommentHandler.ListReplies")
+
+	commentID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
+
+	input := map[string]interface{}{
+		"userID":    userID,
+		"commentID": commentID,
+		"limit":     limit,
+		"offset":    offset,
+	}
+	logger.LogInput(input)
+
+	replies, err := h.commentUseCase.ListReplies(userID, commentID, limit, offset)
+	if err != nil {
+		logger.LogOutput(input, err)
+		return utils.HandleError(c, err)
+	}
+
+	repliesWithUsers := h.withUsers(replies, logger)
+
+	total, err := h.commentUseCase.CountReplies(commentID)
+	if err != nil {
+		logger.LogOutput(input, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(repliesWithUsers, len(replies), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
+
+func (h *CommentHandler) TranslateComment(c *fiber.Ctx) error {
+	logger := utils.NewLogger("CommentHandler.TranslateComment")
+
+	commentID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	targetLanguage := c.Query("lang")
+	if targetLanguage == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "lang is required",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"commentID":      commentID,
+		"targetLanguage": targetLanguage,
+	})
+
+	translation, err := h.commentUseCase.TranslateComment(commentID, targetLanguage)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Comment not found",
+			})
+		}
+		return utils.HandleError(c, err)
+	}
 
-	logger.LogOutput(commentsWithUsers, nil)
-	return c.JSON(commentsWithUsers)
+	logger.LogOutput(translation, nil)
+	return c.JSON(translation)
 }
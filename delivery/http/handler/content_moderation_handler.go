@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContentModerationHandler lets admins hide, unhide and delete any post or comment
+type ContentModerationHandler struct {
+	moderationUseCase domain.ContentModerationUseCase
+}
+
+func NewContentModerationHandler(router fiber.Router, moderationUseCase domain.ContentModerationUseCase) *ContentModerationHandler {
+	handler := &ContentModerationHandler{
+		moderationUseCase: moderationUseCase,
+	}
+
+	router.Post("/:type/:id/hide", handler.HideContent)
+	router.Delete("/:type/:id/hide", handler.UnhideContent)
+	router.Delete("/:type/:id", handler.DeleteContent)
+
+	return handler
+}
+
+// HideContent godoc
+// @Summary Hide a post or comment
+// @Description Take the content out of feeds, profiles, search and comment lists. Only its
+// @Description author can still open it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param type path string true "Content type (post, comment)"
+// @Param id path string true "Content ID"
+// @Param request body domain.ContentModerationRequest false "Reason"
+// @Success 200 {object} domain.AuditLog
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/content/{type}/{id}/hide [post]
+// @Security BearerAuth
+func (h *ContentModerationHandler) HideContent(c *fiber.Ctx) error {
+	return h.moderate(c, "ContentModerationHandler.HideContent", h.moderationUseCase.HideContent)
+}
+
+// UnhideContent godoc
+// @Summary Unhide a post or comment
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param type path string true "Content type (post, comment)"
+// @Param id path string true "Content ID"
+// @Param request body domain.ContentModerationRequest false "Reason"
+// @Success 200 {object} domain.AuditLog
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/content/{type}/{id}/hide [delete]
+// @Security BearerAuth
+func (h *ContentModerationHandler) UnhideContent(c *fiber.Ctx) error {
+	return h.moderate(c, "ContentModerationHandler.UnhideContent", h.moderationUseCase.UnhideContent)
+}
+
+// DeleteContent godoc
+// @Summary Delete a post or comment
+// @Description Delete the content as if its author did. Deleted posts can be restored from /admin/deleted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param type path string true "Content type (post, comment)"
+// @Param id path string true "Content ID"
+// @Param request body domain.ContentModerationRequest false "Reason"
+// @Success 200 {object} domain.AuditLog
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/content/{type}/{id} [delete]
+// @Security BearerAuth
+func (h *ContentModerationHandler) DeleteContent(c *fiber.Ctx) error {
+	return h.moderate(c, "ContentModerationHandler.DeleteContent", h.moderationUseCase.DeleteContent)
+}
+
+func (h *ContentModerationHandler) moderate(c *fiber.Ctx, name string, action func(primitive.ObjectID, string, string, domain.ContentModerationRequest) (*domain.AuditLog, error)) error {
+	logger := utils.NewLogger(name)
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	// The reason is optional, so is the body
+	var req domain.ContentModerationRequest
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+
+	contentType := c.Params("type")
+	id := c.Params("id")
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"type":    contentType,
+		"id":      id,
+		"reason":  req.Reason,
+	})
+
+	auditLog, err := action(adminID, contentType, id, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(auditLog, nil)
+	return c.JSON(auditLog)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// DataExportHandler lets users download an archive of their own data
+type DataExportHandler struct {
+	dataExportUseCase domain.DataExportUseCase
+}
+
+func NewDataExportHandler(router fiber.Router, dataExportUseCase domain.DataExportUseCase) *DataExportHandler {
+	handler := &DataExportHandler{
+		dataExportUseCase: dataExportUseCase,
+	}
+
+	router.Post("/me/export", handler.RequestExport)
+	router.Get("/me/export", handler.GetLatestExport)
+
+	return handler
+}
+
+// RequestExport godoc
+// @Summary Export my data
+// @Description Start building a zip of the current user's profile, posts, comments, chat messages
+// @Description and reactions. The user is notified with the download link once it's ready.
+// @Tags users
+// @Produce json
+// @Success 202 {object} domain.DataExport
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse "An export is already in progress"
+// @Router /users/me/export [post]
+// @Security BearerAuth
+func (h *DataExportHandler) RequestExport(c *fiber.Ctx) error {
+	logger := utils.NewLogger("DataExportHandler.RequestExport")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	export, err := h.dataExportUseCase.RequestExport(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(export.ID, nil)
+	return c.Status(fiber.StatusAccepted).JSON(export)
+}
+
+// GetLatestExport godoc
+// @Summary Get my latest data export
+// @Description Get the status of the current user's latest export, with its download link once completed
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.DataExport
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /users/me/export [get]
+// @Security BearerAuth
+func (h *DataExportHandler) GetLatestExport(c *fiber.Ctx) error {
+	logger := utils.NewLogger("DataExportHandler.GetLatestExport")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	export, err := h.dataExportUseCase.GetLatestExport(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(export.Status, nil)
+	return c.JSON(export)
+}
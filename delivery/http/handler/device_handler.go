@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type DeviceHandler struct {
+	deviceUseCase domain.DeviceUseCase
+}
+
+func NewDeviceHandler(router fiber.Router, deviceUseCase domain.DeviceUseCase) *DeviceHandler {
+	handler := &DeviceHandler{
+		deviceUseCase: deviceUseCase,
+	}
+
+	router.Post("/", handler.RegisterDevice)
+	router.Delete("/", handler.UnregisterDevice)
+
+	return handler
+}
+
+type DeviceRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Platform string `json:"platform,omitempty"` // ios, android or web; required on register
+}
+
+// RegisterDevice godoc
+// @Summary Register a device for push notifications
+// @Description Save the FCM registration token of the current device
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body DeviceRequest true "Device token"
+// @Success 200 {object} domain.DeviceToken
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/devices [post]
+// @Security BearerAuth
+func (h *DeviceHandler) RegisterDevice(c *fiber.Ctx) error {
+	logger := utils.NewLogger("DeviceHandler.RegisterDevice")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req DeviceRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(map[string]interface{}{
+		"userID":   userID,
+		"platform": req.Platform,
+	})
+
+	device, err := h.deviceUseCase.RegisterDevice(userID, req.Token, req.Platform)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(device, nil)
+	return c.JSON(device)
+}
+
+// UnregisterDevice godoc
+// @Summary Unregister a device from push notifications
+// @Description Remove the FCM registration token, e.g. on logout
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body DeviceRequest true "Device token"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /users/devices [delete]
+// @Security BearerAuth
+func (h *DeviceHandler) UnregisterDevice(c *fiber.Ctx) error {
+	logger := utils.NewLogger("DeviceHandler.UnregisterDevice")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req DeviceRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	err = h.deviceUseCase.UnregisterDevice(userID, req.Token)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Device unregistered successfully", nil)
+	return utils.SendSuccess(c, "Device unregistered successfully")
+}
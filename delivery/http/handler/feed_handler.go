@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type FeedHandler struct {
+	feedUseCase domain.FeedUseCase
+}
+
+func NewFeedHandler(router fiber.Router, feedUseCase domain.FeedUseCase) *FeedHandler {
+	handler := &FeedHandler{
+		feedUseCase: feedUseCase,
+	}
+
+	router.Get("/", handler.GetFeed)
+
+	return handler
+}
+
+// GetFeed godoc
+// @Summary Get the home feed
+// @Description Posts from the authenticated user, their friends and followed users, newest first
+// @Tags feed
+// @Accept json
+// @Produce json
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} domain.Page
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /feed [get]
+// @Security BearerAuth
+func (h *FeedHandler) GetFeed(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FeedHandler.GetFeed")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	posts, err := h.feedUseCase.GetFeed(userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	total, err := h.feedUseCase.CountFeed(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(posts, len(posts), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type FeedLayoutHandler struct {
+	feedLayoutUseCase domain.FeedLayoutUseCase
+}
+
+// NewFeedLayoutHandler registers the home screen layout on router and its management on adminRouter
+func NewFeedLayoutHandler(router fiber.Router, adminRouter fiber.Router, feedLayoutUseCase domain.FeedLayoutUseCase) *FeedLayoutHandler {
+	handler := &FeedLayoutHandler{
+		feedLayoutUseCase: feedLayoutUseCase,
+	}
+
+	router.Get("/layout", handler.GetLayout)
+
+	adminRouter.Get("/", handler.GetConfig)
+	adminRouter.Put("/", handler.UpdateConfig)
+
+	return handler
+}
+
+// GetLayout godoc
+// @Summary Get the home screen layout
+// @Description The modules of the home screen in display order (stories_tray, suggestions_card, trending, posts),
+// @Description after the current user's feature flags and experiment variants are applied
+// @Tags feed
+// @Produce json
+// @Success 200 {object} domain.FeedLayout
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /feed/layout [get]
+// @Security BearerAuth
+func (h *FeedLayoutHandler) GetLayout(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FeedLayoutHandler.GetLayout")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	layout, err := h.feedLayoutUseCase.GetLayout(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(layout, nil)
+	return c.JSON(layout)
+}
+
+// GetConfig godoc
+// @Summary Get the home screen layout configuration
+// @Description The default modules, feature flags and layout experiments
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.FeedLayoutConfig
+// @Router /admin/feed-layout [get]
+// @Security BearerAuth
+func (h *FeedLayoutHandler) GetConfig(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FeedLayoutHandler.GetConfig")
+
+	config, err := h.feedLayoutUseCase.GetConfig()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(config, nil)
+	return c.JSON(config)
+}
+
+// UpdateConfig godoc
+// @Summary Replace the home screen layout configuration
+// @Description Replace the default modules, feature flags and layout experiments. Apps pick up the
+// @Description change within a minute.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body domain.FeedLayoutConfig true "Layout configuration"
+// @Success 200 {object} domain.FeedLayoutConfig
+// @Failure 400 {object} utils.ErrorResponse
+// @Router /admin/feed-layout [put]
+// @Security BearerAuth
+func (h *FeedLayoutHandler) UpdateConfig(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FeedLayoutHandler.UpdateConfig")
+
+	var config domain.FeedLayoutConfig
+	if err := utils.ParseBody(c, &config); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(config)
+
+	updated, err := h.feedLayoutUseCase.UpdateConfig(&config)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(updated.UpdatedAt, nil)
+	return c.JSON(updated)
+}
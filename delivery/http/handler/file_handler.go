@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"bytes"
 	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
@@ -9,19 +14,23 @@ import (
 )
 
 type FileHandler struct {
-	fileRepo domain.FileRepository
+	fileUseCase domain.FileUseCase
 }
 
-func NewFileHandler(router fiber.Router, fileRepo domain.FileRepository) *FileHandler {
+func NewFileHandler(router fiber.Router, fileUseCase domain.FileUseCase) *FileHandler {
 	logger := utils.NewLogger("FileHandler.NewFileHandler")
 	logger.LogInput(map[string]interface{}{
-		"fileRepo": fileRepo,
+		"fileUseCase": fileUseCase,
 	})
 	handler := &FileHandler{
-		fileRepo: fileRepo,
+		fileUseCase: fileUseCase,
 	}
 
 	router.Post("/upload", handler.Upload)
+	router.Put("/upload", handler.UploadPasted)
+	router.Delete("/upload", handler.DeleteFile)
+	router.Get("/files/download", handler.GetDownload)
+	router.Put("/files/acl", handler.ShareFile)
 
 	return handler
 }
@@ -29,6 +38,12 @@ func NewFileHandler(router fiber.Router, fileRepo domain.FileRepository) *FileHa
 func (h *FileHandler) Upload(c *fiber.Ctx) error {
 	logger := utils.NewLogger("FileHandler.Upload")
 
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	// Get file from request
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -64,6 +79,19 @@ func (h *FileHandler) Upload(c *fiber.Ctx) error {
 		})
 	}
 
+	// Audio uploads carry their length so stories can validate trim parameters against it
+	var duration float64
+	if isAudioFileType(contentType) {
+		duration, err = strconv.ParseFloat(c.FormValue("duration"), 64)
+		if err != nil || duration <= 0 {
+			err := fmt.Errorf("duration is required for audio files")
+			logger.LogOutput(nil, err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	// Open file
 	fileData, err := file.Open()
 	if err != nil {
@@ -78,15 +106,19 @@ func (h *FileHandler) Upload(c *fiber.Ctx) error {
 	fileModel := &domain.File{
 		FileName:    file.Filename,
 		ContentType: contentType,
+		Size:        file.Size,
+		Duration:    duration,
+		Context:     c.FormValue("context"),
+	}
+	if c.FormValue("private") == "true" {
+		fileModel.ACL = &domain.FileACL{OwnerID: userID}
 	}
 
 	// Upload file
-	uploadedFile, err := h.fileRepo.Upload(fileModel, fileData)
+	uploadedFile, err := h.fileUseCase.Upload(userID, fileModel, fileData)
 	if err != nil {
 		logger.LogOutput(nil, fmt.Errorf("error uploading file: %v", err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error uploading file",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(map[string]interface{}{
@@ -94,10 +126,225 @@ func (h *FileHandler) Upload(c *fiber.Ctx) error {
 		"fileName": uploadedFile.FileName,
 	}, nil)
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"url":      uploadedFile.FileURL,
 		"fileName": uploadedFile.FileName,
-	})
+	}
+	if uploadedFile.Duration > 0 {
+		response["duration"] = uploadedFile.Duration
+	}
+	if uploadedFile.PerceptualHash != "" {
+		response["perceptualHash"] = uploadedFile.PerceptualHash
+	}
+	if uploadedFile.ACL != nil {
+		response["private"] = true
+	}
+	return c.JSON(response)
+}
+
+// UploadPasted godoc
+// @Summary Upload a pasted image
+// @Description Upload an image sent as the raw request body, like a pasted screenshot. The type is
+// @Description detected from the content rather than taken from the request. Chat uploads are private.
+// @Tags files
+// @Accept octet-stream
+// @Produce json
+// @Param context query string false "What the upload is for, like chat"
+// @Param filename query string false "File name, pasted-image by default"
+// @Param private query bool false "Keep the file private to the user"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 413 {object} utils.ErrorResponse
+// @Router /upload [put]
+// @Security BearerAuth
+func (h *FileHandler) UploadPasted(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FileHandler.UploadPasted")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	body := c.Body()
+	if len(body) == 0 {
+		return utils.HandleError(c, fmt.Errorf("%w: file is required", domain.ErrInvalidInput))
+	}
+	if len(body) > domain.MaxPastedFileSize {
+		err := fmt.Errorf("file size too large: %d bytes", len(body))
+		logger.LogOutput(nil, err)
+		return utils.SendError(c, fiber.StatusRequestEntityTooLarge, err.Error())
+	}
+
+	contentType := http.DetectContentType(body)
+	if !isValidFileType(contentType) || isAudioFileType(contentType) {
+		err := fmt.Errorf("%w: invalid file type: %s", domain.ErrInvalidInput, contentType)
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	fileName := c.Query("filename", "pasted-image")
+	if path.Ext(fileName) == "" {
+		fileName += "." + strings.TrimPrefix(contentType, "image/")
+	}
+	fileModel := &domain.File{
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(body)),
+		Context:     c.Query("context"),
+	}
+	if c.QueryBool("private") {
+		fileModel.ACL = &domain.FileACL{OwnerID: userID}
+	}
+	logger.LogInput(userID, fileModel.FileName, fileModel.ContentType, fileModel.Size, fileModel.Context)
+
+	uploadedFile, err := h.fileUseCase.Upload(userID, fileModel, bytes.NewReader(body))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	response := fiber.Map{
+		"url":         uploadedFile.FileURL,
+		"fileName":    uploadedFile.FileName,
+		"contentType": uploadedFile.ContentType,
+		"size":        uploadedFile.Size,
+		"private":     uploadedFile.ACL != nil,
+	}
+	if uploadedFile.PerceptualHash != "" {
+		response["perceptualHash"] = uploadedFile.PerceptualHash
+	}
+
+	logger.LogOutput(response, nil)
+	return c.JSON(response)
+}
+
+// DeleteFile removes one of the user's uploads, freeing its storage
+func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FileHandler.DeleteFile")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req struct {
+		URL string `json:"url" validate:"required"`
+	}
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, req.URL)
+
+	if err := h.fileUseCase.DeleteFile(userID, req.URL); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("File deleted successfully", nil)
+	return utils.SendSuccess(c, "File deleted successfully")
+}
+
+// GetStorageUsage returns the storage used by the current user against their quota
+func (h *FileHandler) GetStorageUsage(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FileHandler.GetStorageUsage")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	usage, err := h.fileUseCase.GetStorageUsage(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(usage, nil)
+	return c.JSON(usage)
+}
+
+// GetDownload godoc
+// @Summary Get a download link of a file
+// @Description Private files, like chat uploads, are only fetched through short-lived links, handed to
+// @Description their owner, the users they're shared with and the members of the chat rooms they were
+// @Description sent to. Public files keep their URL.
+// @Tags files
+// @Produce json
+// @Param url query string true "File URL"
+// @Success 200 {object} domain.FileDownload
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /files/download [get]
+// @Security BearerAuth
+func (h *FileHandler) GetDownload(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FileHandler.GetDownload")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	url := c.Query("url")
+	if url == "" {
+		return utils.HandleError(c, fmt.Errorf("%w: url is required", domain.ErrInvalidInput))
+	}
+	logger.LogInput(userID, url)
+
+	download, err := h.fileUseCase.GetDownload(userID, url)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(download.ExpiresAt, nil)
+	return c.JSON(download)
+}
+
+// ShareFile godoc
+// @Summary Share a private file with users
+// @Description Replace the users who may download one of the current user's private files
+// @Tags files
+// @Accept json
+// @Param request body ShareFileRequest true "File URL and user IDs"
+// @Success 204
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /files/acl [put]
+// @Security BearerAuth
+func (h *FileHandler) ShareFile(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FileHandler.ShareFile")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req ShareFileRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, req)
+
+	if err := h.fileUseCase.ShareFile(userID, req.URL, req.UserIDs); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type ShareFileRequest struct {
+	URL     string   `json:"url" validate:"required"`
+	UserIDs []string `json:"userIds"`
 }
 
 func isValidFileType(contentType string) bool {
@@ -106,7 +353,15 @@ func isValidFileType(contentType string) bool {
 		"image/png":  true,
 		"image/gif":  true,
 		"image/webp": true,
+		"audio/mpeg": true,
+		"audio/mp4":  true,
+		"audio/aac":  true,
+		"audio/wav":  true,
 	}
 
 	return validTypes[contentType]
 }
+
+func isAudioFileType(contentType string) bool {
+	return strings.HasPrefix(contentType, "audio/")
+}
@@ -25,6 +25,8 @@ func NewFollowHandler(router fiber.Router, fu domain.FollowUseCase) *FollowHandl
 	router.Get("/following", handler.GetFollowing)
 	router.Post("/block/:userId", handler.Block)
 	router.Delete("/block/:userId", handler.Unblock)
+	router.Get("/suggestions", handler.GetSuggestions)
+	router.Delete("/suggestions/:userId", handler.DismissSuggestion)
 
 	return handler
 }
@@ -278,3 +280,70 @@ func (h *FollowHandler) GetFollowing(c *fiber.Ctx) error {
 		"following": following,
 	})
 }
+
+// GetSuggestions handles getting accounts the user may want to follow
+func (h *FollowHandler) GetSuggestions(c *fiber.Ctx) error {
+	logger := utils.NewLogger("followHandler.GetSuggestions")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"limit":  limit,
+	})
+
+	suggestions, err := h.followUseCase.GetSuggestions(userID, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(suggestions, nil)
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"suggestions": suggestions,
+	})
+}
+
+// DismissSuggestion handles hiding an account from the user's suggestions
+func (h *FollowHandler) DismissSuggestion(c *fiber.Ctx) error {
+	logger := utils.NewLogger("followHandler.DismissSuggestion")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	suggestedID := c.Params("userId")
+
+	logger.LogInput(map[string]interface{}{
+		"userID":      userID,
+		"suggestedID": suggestedID,
+	})
+
+	suggestedObjID, err := primitive.ObjectIDFromHex(suggestedID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	if err := h.followUseCase.DismissSuggestion(userID, suggestedObjID); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Suggestion dismissed", nil)
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -24,6 +24,7 @@ func NewFriendshipHandler(router fiber.Router, fu domain.FriendshipUseCase) *Fri
 	router.Delete("/:userId", handler.RemoveFriend)
 	router.Get("/", handler.ListFriends)
 	router.Get("/requests", handler.ListFriendRequests)
+	router.Get("/user/:userId", handler.ListUserFriends)
 
 	return handler
 }
@@ -165,6 +166,37 @@ func (h *FriendshipHandler) ListFriends(c *fiber.Ctx) error {
 	return c.JSON(friends)
 }
 
+// ListUserFriends lists another user's friends, if their privacy settings let the viewer see them
+func (h *FriendshipHandler) ListUserFriends(c *fiber.Ctx) error {
+	logger := utils.NewLogger("FriendshipHandler.ListUserFriends")
+
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Params("userId"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	limit, offset := utils.GetPaginationParams(c)
+	logger.LogInput(viewerID, userID, limit, offset)
+
+	friends, err := h.friendshipUseCase.ListUserFriends(viewerID, userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(friends, nil)
+	return c.JSON(friends)
+}
+
 func (h *FriendshipHandler) ListFriendRequests(c *fiber.Ctx) error {
 	logger := utils.NewLogger("FriendshipHandler.ListFriendRequests")
 
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+const maxTrendingLimit = 50
+
+type HashtagHandler struct {
+	hashtagUseCase domain.HashtagUseCase
+}
+
+func NewHashtagHandler(router fiber.Router, hashtagUseCase domain.HashtagUseCase) *HashtagHandler {
+	handler := &HashtagHandler{
+		hashtagUseCase: hashtagUseCase,
+	}
+
+	router.Get("/trending", handler.GetTrending)
+	router.Get("/:tag/posts", handler.GetTagPosts)
+
+	return handler
+}
+
+// GetTagPosts godoc
+// @Summary Get posts with a hashtag
+// @Description Public posts tagged with the hashtag, plus the authenticated user's own, newest first
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag path string true "Hashtag, with or without the leading #"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} domain.Page
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /tags/{tag}/posts [get]
+// @Security BearerAuth
+func (h *HashtagHandler) GetTagPosts(c *fiber.Ctx) error {
+	logger := utils.NewLogger("HashtagHandler.GetTagPosts")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	tag := c.Params("tag")
+	limit, offset := utils.GetCursorParams(c, 20)
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"tag":    tag,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	posts, err := h.hashtagUseCase.GetTagPosts(userID, tag, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	total, err := h.hashtagUseCase.CountTagPosts(userID, tag)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(posts, len(posts), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
+
+// GetTrending godoc
+// @Summary Get trending hashtags
+// @Description Hashtags most used on new and edited posts within the window
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param hours query int false "Window in hours, up to 168 (default 24)"
+// @Param limit query int false "Number of tags to return, up to 50 (default 10)"
+// @Success 200 {array} domain.TrendingTag
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /tags/trending [get]
+// @Security BearerAuth
+func (h *HashtagHandler) GetTrending(c *fiber.Ctx) error {
+	logger := utils.NewLogger("HashtagHandler.GetTrending")
+
+	hours := c.QueryInt("hours", int(domain.DefaultTrendingWindow/time.Hour))
+	limit := c.QueryInt("limit", 10)
+	if limit > maxTrendingLimit {
+		limit = maxTrendingLimit
+	}
+	logger.LogInput(hours, limit)
+
+	trending, err := h.hashtagUseCase.GetTrending(time.Duration(hours)*time.Hour, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(trending, nil)
+	return c.JSON(trending)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +13,8 @@ import (
 type HealthHandler struct {
 	mongoDB     *mongo.Database
 	redisClient *redis.Client
+	// ready is set once the server listens and cleared when it starts shutting down
+	ready atomic.Bool
 }
 
 // NewHealthHandler creates a new health handler
@@ -32,7 +35,78 @@ func NewHealthHandler(mongoDB *mongo.Database, redisClient *redis.Client) *Healt
 // @Failure 503 {object} ErrorResponse
 // @Router /health [get]
 func (h *HealthHandler) Health(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	services, up := h.checkServices(c.Context())
+
+	// Overall status is down if any dependency is down
+	status := "healthy"
+	statusCode := fiber.StatusOK
+	if !up {
+		status = "unhealthy"
+		statusCode = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(statusCode).JSON(HealthResponse{
+		Status:    status,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Services:  services,
+	})
+}
+
+// SetReady marks whether the instance should be sent traffic
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Answers as long as the process serves requests. Dependencies aren't checked,
+// @Description so an outage doesn't get every instance restarted.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Router /live [get]
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	return c.JSON(HealthResponse{
+		Status:    "alive",
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Reports whether the instance should be sent traffic: it has started, isn't
+// @Description shutting down and reaches MongoDB and Redis.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /ready [get]
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	if !h.ready.Load() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(HealthResponse{
+			Status:    "not_ready",
+			Timestamp: time.Now().Format(time.RFC3339),
+		})
+	}
+
+	services, up := h.checkServices(c.Context())
+	status := "ready"
+	statusCode := fiber.StatusOK
+	if !up {
+		status = "unhealthy"
+		statusCode = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(statusCode).JSON(HealthResponse{
+		Status:    status,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Services:  services,
+	})
+}
+
+// checkServices pings MongoDB and Redis and reports whether both are up
+func (h *HealthHandler) checkServices(ctx context.Context) (map[string]string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// Check MongoDB connection
@@ -47,27 +121,16 @@ func (h *HealthHandler) Health(c *fiber.Ctx) error {
 		redisStatus = "down"
 	}
 
-	// Overall status is down if any dependency is down
-	status := "healthy"
-	statusCode := fiber.StatusOK
-	if mongoStatus == "down" || redisStatus == "down" {
-		status = "unhealthy"
-		statusCode = fiber.StatusServiceUnavailable
+	services := map[string]string{
+		"mongodb": mongoStatus,
+		"redis":   redisStatus,
 	}
-
-	return c.Status(statusCode).JSON(fiber.Map{
-		"status": status,
-		"timestamp": time.Now().Format(time.RFC3339),
-		"services": fiber.Map{
-			"mongodb": mongoStatus,
-			"redis":   redisStatus,
-		},
-	})
+	return services, mongoStatus == "up" && redisStatus == "up"
 }
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
 	Status    string            `json:"status" example:"healthy"`
 	Timestamp string            `json:"timestamp" example:"2024-12-23T07:02:21Z"`
-	Services  map[string]string `json:"services"`
+	Services  map[string]string `json:"services,omitempty"`
 }
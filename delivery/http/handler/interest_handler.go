@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InterestHandler struct {
+	interestUseCase domain.InterestUseCase
+}
+
+// NewInterestHandler registers the typeahead on router and taxonomy management on adminRouter
+func NewInterestHandler(router fiber.Router, adminRouter fiber.Router, interestUseCase domain.InterestUseCase) *InterestHandler {
+	handler := &InterestHandler{
+		interestUseCase: interestUseCase,
+	}
+
+	router.Get("/", handler.SearchInterests)
+
+	adminRouter.Get("/", handler.ListInterests)
+	adminRouter.Post("/", handler.CreateInterest)
+	adminRouter.Put("/:id", handler.UpdateInterest)
+	adminRouter.Delete("/:id", handler.DeleteInterest)
+
+	return handler
+}
+
+type InterestRequest struct {
+	Slug     string            `json:"slug"`
+	Category string            `json:"category"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// SearchInterests godoc
+// @Summary Search interests
+// @Description Typeahead over the interest taxonomy, matching slugs and labels by prefix
+// @Tags interests
+// @Produce json
+// @Param q query string false "Prefix to match"
+// @Param category query string false "Only interests of this category"
+// @Param lang query string false "Label language, defaults to en"
+// @Param limit query int false "Maximum results, up to 50"
+// @Success 200 {array} domain.InterestOption
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /interests [get]
+// @Security BearerAuth
+func (h *InterestHandler) SearchInterests(c *fiber.Ctx) error {
+	logger := utils.NewLogger("InterestHandler.SearchInterests")
+
+	query := c.Query("q")
+	category := c.Query("category")
+	lang := c.Query("lang", domain.DefaultInterestLanguage)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	logger.LogInput(map[string]interface{}{
+		"query":    query,
+		"category": category,
+		"lang":     lang,
+		"limit":    limit,
+	})
+
+	options, err := h.interestUseCase.SearchInterests(query, category, lang, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(options)}, nil)
+	return c.JSON(options)
+}
+
+// ListInterests godoc
+// @Summary List interests
+// @Description List the whole interest taxonomy with every label, including inactive interests
+// @Tags admin
+// @Produce json
+// @Param category query string false "Only interests of this category"
+// @Success 200 {array} domain.Interest
+// @Router /admin/interests [get]
+// @Security BearerAuth
+func (h *InterestHandler) ListInterests(c *fiber.Ctx) error {
+	logger := utils.NewLogger("InterestHandler.ListInterests")
+
+	category := c.Query("category")
+	logger.LogInput(category)
+
+	interests, err := h.interestUseCase.ListInterests(category)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(interests)}, nil)
+	return c.JSON(interests)
+}
+
+// CreateInterest godoc
+// @Summary Add an interest
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body InterestRequest true "Interest"
+// @Success 201 {object} domain.Interest
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /admin/interests [post]
+// @Security BearerAuth
+func (h *InterestHandler) CreateInterest(c *fiber.Ctx) error {
+	logger := utils.NewLogger("InterestHandler.CreateInterest")
+
+	var req InterestRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(req)
+
+	interest := &domain.Interest{
+		Slug:     req.Slug,
+		Category: req.Category,
+		Labels:   req.Labels,
+	}
+	if err := h.interestUseCase.CreateInterest(interest); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(interest, nil)
+	return c.Status(fiber.StatusCreated).JSON(interest)
+}
+
+// UpdateInterest godoc
+// @Summary Update an interest
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Interest ID"
+// @Param request body domain.InterestUpdate true "Fields to change"
+// @Success 200 {object} domain.Interest
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/interests/{id} [put]
+// @Security BearerAuth
+func (h *InterestHandler) UpdateInterest(c *fiber.Ctx) error {
+	logger := utils.NewLogger("InterestHandler.UpdateInterest")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+
+	var req domain.InterestUpdate
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(id, req)
+
+	interest, err := h.interestUseCase.UpdateInterest(id, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(interest, nil)
+	return c.JSON(interest)
+}
+
+// DeleteInterest godoc
+// @Summary Remove an interest
+// @Description Users keep the slug, deactivating the interest is usually preferable
+// @Tags admin
+// @Param id path string true "Interest ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/interests/{id} [delete]
+// @Security BearerAuth
+func (h *InterestHandler) DeleteInterest(c *fiber.Ctx) error {
+	logger := utils.NewLogger("InterestHandler.DeleteInterest")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(id)
+
+	if err := h.interestUseCase.DeleteInterest(id); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// JobHandler exposes internal jobs to cron runners holding the jobs:run scope
+type JobHandler struct {
+	storyUseCase      domain.StoryUseCase
+	suspensionUseCase domain.SuspensionUseCase
+}
+
+func NewJobHandler(router fiber.Router, storyUseCase domain.StoryUseCase, suspensionUseCase domain.SuspensionUseCase) *JobHandler {
+	handler := &JobHandler{
+		storyUseCase:      storyUseCase,
+		suspensionUseCase: suspensionUseCase,
+	}
+
+	router.Post("/archive-expired-stories", handler.ArchiveExpiredStories)
+	router.Post("/process-suspensions", handler.ProcessSuspensions)
+
+	return handler
+}
+
+func (h *JobHandler) ArchiveExpiredStories(c *fiber.Ctx) error {
+	logger := utils.NewLogger("JobHandler.ArchiveExpiredStories")
+	logger.LogInput(c.Locals("serviceClientId"))
+
+	if err := h.storyUseCase.ArchiveExpiredStories(); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("archived expired stories", nil)
+	return c.JSON(fiber.Map{
+		"status": "done",
+	})
+}
+
+// ProcessSuspensions warns users whose suspension ends soon and lifts the expired ones
+func (h *JobHandler) ProcessSuspensions(c *fiber.Ctx) error {
+	logger := utils.NewLogger("JobHandler.ProcessSuspensions")
+	logger.LogInput(c.Locals("serviceClientId"))
+
+	result, err := h.suspensionUseCase.ProcessSuspensions()
+	if err != nil {
+		logger.LogOutput(result, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(result, nil)
+	return c.JSON(fiber.Map{
+		"status":   "done",
+		"notified": result.Notified,
+		"lifted":   result.Lifted,
+	})
+}
+
+// MetricsHandler exposes process metrics to collectors holding the metrics:read scope
+type MetricsHandler struct {
+	startedAt time.Time
+}
+
+func NewMetricsHandler(router fiber.Router) *MetricsHandler {
+	handler := &MetricsHandler{
+		startedAt: time.Now(),
+	}
+
+	router.Get("/", handler.GetMetrics)
+
+	return handler
+}
+
+func (h *MetricsHandler) GetMetrics(c *fiber.Ctx) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return c.JSON(fiber.Map{
+		"uptimeSeconds": int64(time.Since(h.startedAt).Seconds()),
+		"goroutines":    runtime.NumGoroutine(),
+		"memory": fiber.Map{
+			"allocBytes":  mem.Alloc,
+			"sysBytes":    mem.Sys,
+			"heapObjects": mem.HeapObjects,
+			"numGC":       mem.NumGC,
+		},
+		"counters": utils.MetricsSnapshot(),
+	})
+}
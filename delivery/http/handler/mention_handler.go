@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// MentionHandler backs @mention autocompletion
+type MentionHandler struct {
+	mentionUseCase domain.MentionUseCase
+}
+
+func NewMentionHandler(router fiber.Router, mentionUseCase domain.MentionUseCase) *MentionHandler {
+	handler := &MentionHandler{
+		mentionUseCase: mentionUseCase,
+	}
+
+	router.Get("/mention-suggestions", handler.SuggestMentions)
+
+	return handler
+}
+
+// SuggestMentions godoc
+// @Summary Suggest users to mention
+// @Description Complete a typed @mention. Members of the room or participants of the post come first
+// @Description when a context is given, then friends, then other users by username. Each group is
+// @Description ranked by how recently the user interacted with them.
+// @Tags users
+// @Produce json
+// @Param q query string false "Typed text, with or without the @"
+// @Param context query string false "post:<postId> or room:<roomId>"
+// @Param limit query int false "Number of suggestions, up to 20 (default 8)"
+// @Success 200 {array} domain.MentionSuggestion
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /users/mention-suggestions [get]
+// @Security BearerAuth
+func (h *MentionHandler) SuggestMentions(c *fiber.Ctx) error {
+	logger := utils.NewLogger("MentionHandler.SuggestMentions")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	prefix := c.Query("q")
+	mentionContext := c.Query("context")
+	limit := utils.GetQueryInt(c, "limit", domain.DefaultMentionSuggestions)
+	logger.LogInput(userID, prefix, mentionContext, limit)
+
+	suggestions, err := h.mentionUseCase.SuggestMentions(userID, prefix, mentionContext, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(suggestions)}, nil)
+	return c.JSON(suggestions)
+}
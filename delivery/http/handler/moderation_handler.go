@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModerationHandler lets admins act on many posts, comments or users at once
+type ModerationHandler struct {
+	moderationUseCase domain.ModerationUseCase
+}
+
+func NewModerationHandler(router fiber.Router, moderationUseCase domain.ModerationUseCase) *ModerationHandler {
+	handler := &ModerationHandler{
+		moderationUseCase: moderationUseCase,
+	}
+
+	router.Post("/", handler.CreateJob)
+	router.Get("/", handler.ListJobs)
+	router.Get("/:id", handler.GetJob)
+
+	return handler
+}
+
+// CreateJob godoc
+// @Summary Start a bulk moderation job
+// @Description Apply one action (delete_comment, delete_post, ban_user, mute_user, lift_suspension)
+// @Description to up to 500 targets in the background. Bans and mutes end at until or after durationHours.
+// @Description Poll the job for its progress and per-item results.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body domain.ModerationJobRequest true "Action, target IDs and reason"
+// @Success 202 {object} domain.ModerationJob
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/moderation-jobs [post]
+// @Security BearerAuth
+func (h *ModerationHandler) CreateJob(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ModerationHandler.CreateJob")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.ModerationJobRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"request": req,
+	})
+
+	job, err := h.moderationUseCase.CreateJob(adminID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(job.ID, nil)
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// ListJobs godoc
+// @Summary List bulk moderation jobs
+// @Description List jobs newest first, without their per-item results
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.ModerationJob
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/moderation-jobs [get]
+// @Security BearerAuth
+func (h *ModerationHandler) ListJobs(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ModerationHandler.ListJobs")
+
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	jobs, err := h.moderationUseCase.ListJobs(limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(jobs)}, nil)
+	return c.JSON(jobs)
+}
+
+// GetJob godoc
+// @Summary Get a bulk moderation job
+// @Description Get the status of a job and the result of each item
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.ModerationJob
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/moderation-jobs/{id} [get]
+// @Security BearerAuth
+func (h *ModerationHandler) GetJob(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ModerationHandler.GetJob")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(id)
+
+	job, err := h.moderationUseCase.GetJob(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(job.Status, nil)
+	return c.JSON(job)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+const maxNearbyLimit = 50
+
+// NearbyHandler serves the posts and users located around a point
+type NearbyHandler struct {
+	nearbyUseCase domain.NearbyUseCase
+}
+
+func NewNearbyHandler(posts fiber.Router, users fiber.Router, nearbyUseCase domain.NearbyUseCase) *NearbyHandler {
+	handler := &NearbyHandler{
+		nearbyUseCase: nearbyUseCase,
+	}
+
+	posts.Get("/nearby", handler.GetNearbyPosts)
+	users.Get("/nearby", handler.GetNearbyUsers)
+
+	return handler
+}
+
+// GetNearbyPosts godoc
+// @Summary Get posts near a point
+// @Description Public posts, plus the authenticated user's own, tagged with a location within the radius, closest first. Distances are in meters.
+// @Tags posts
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius query number false "Radius in meters, up to 50000 (default 5000)"
+// @Param limit query int false "Number of items to return, up to 50 (default 10)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.PostWithDetails
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /posts/nearby [get]
+// @Security BearerAuth
+func (h *NearbyHandler) GetNearbyPosts(c *fiber.Ctx) error {
+	logger := utils.NewLogger("NearbyHandler.GetNearbyPosts")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	lat, lng, radius, err := nearbyParams(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	limit, offset := nearbyPage(c)
+	logger.LogInput(userID, lat, lng, radius, limit, offset)
+
+	posts, err := h.nearbyUseCase.GetNearbyPosts(userID, lat, lng, radius, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(posts)}, nil)
+	return c.JSON(posts)
+}
+
+// GetNearbyUsers godoc
+// @Summary Get users near a point
+// @Description Users whose location is within the radius, closest first. Distances are rounded up to whole kilometers.
+// @Tags users
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius query number false "Radius in meters, up to 50000 (default 5000)"
+// @Param limit query int false "Number of items to return, up to 50 (default 10)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.NearbyUser
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/nearby [get]
+// @Security BearerAuth
+func (h *NearbyHandler) GetNearbyUsers(c *fiber.Ctx) error {
+	logger := utils.NewLogger("NearbyHandler.GetNearbyUsers")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	lat, lng, radius, err := nearbyParams(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	limit, offset := nearbyPage(c)
+	logger.LogInput(userID, lat, lng, radius, limit, offset)
+
+	users, err := h.nearbyUseCase.GetNearbyUsers(userID, lat, lng, radius, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return c.JSON(users)
+}
+
+// nearbyParams reads the searched point and the radius, 0 when not given
+func nearbyParams(c *fiber.Ctx) (lat, lng, radius float64, err error) {
+	lat, err = strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%w: lat is required", domain.ErrInvalidInput)
+	}
+	lng, err = strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%w: lng is required", domain.ErrInvalidInput)
+	}
+	if value := c.Query("radius"); value != "" {
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("%w: radius must be a number", domain.ErrInvalidInput)
+		}
+	}
+	return lat, lng, radius, nil
+}
+
+// nearbyPage reads limit and offset. Results of blocked users are dropped from
+// a page, so clients page by offset rather than by the number of items they got.
+func nearbyPage(c *fiber.Ctx) (limit, offset int) {
+	limit, offset = utils.GetPaginationParams(c)
+	if limit == 0 || limit > maxNearbyLimit {
+		limit = maxNearbyLimit
+	}
+	return limit, offset
+}
@@ -34,7 +34,8 @@ func NewNotificationHandler(router fiber.Router, notificationUseCase domain.Noti
 // @Produce json
 // @Param limit query int false "Number of items to return (default 10)"
 // @Param offset query int false "Number of items to skip (default 0)"
-// @Success 200 {array} domain.Notification
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} domain.Page
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Router /notifications [get]
@@ -45,15 +46,19 @@ func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
 		return utils.HandleError(c, err)
 	}
 
-	limit := utils.GetQueryInt(c, "limit", 10)
-	offset := utils.GetQueryInt(c, "offset", 0)
+	limit, offset := utils.GetCursorParams(c, 10)
 
 	notifications, err := h.notificationUseCase.ListNotifications(userID, limit, offset)
 	if err != nil {
 		return utils.HandleError(c, err)
 	}
 
-	return c.JSON(notifications)
+	total, err := h.notificationUseCase.CountNotifications(userID)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return c.JSON(utils.NewPage(notifications, len(notifications), limit, offset, total))
 }
 
 // GetNotification godoc
@@ -127,7 +132,7 @@ func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 		return utils.HandleError(c, domain.ErrUnauthorized)
 	}
 
-	err = h.notificationUseCase.MarkAsRead(notificationID)
+	err = h.notificationUseCase.MarkAsRead(userID, notificationID)
 	if err != nil {
 		return utils.HandleError(c, err)
 	}
@@ -173,6 +178,7 @@ func (h *NotificationHandler) MarkAllAsRead(c *fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Router /notifications/{id} [delete]
 // @Security BearerAuth
@@ -182,22 +188,12 @@ func (h *NotificationHandler) DeleteNotification(c *fiber.Ctx) error {
 		return utils.HandleError(c, domain.ErrInvalidID)
 	}
 
-	// Verify ownership before deletion
-	notification, err := h.notificationUseCase.GetNotification(notificationID)
-	if err != nil {
-		return utils.HandleError(c, err)
-	}
-
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		return utils.HandleError(c, err)
 	}
 
-	if notification.RecipientID != userID {
-		return utils.HandleError(c, domain.ErrUnauthorized)
-	}
-
-	err = h.notificationUseCase.DeleteNotification(notificationID)
+	err = h.notificationUseCase.DeleteNotification(userID, notificationID)
 	if err != nil {
 		return utils.HandleError(c, err)
 	}
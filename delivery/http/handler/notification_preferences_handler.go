@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// NotificationPreferencesHandler serves the current user's notification preferences
+type NotificationPreferencesHandler struct {
+	digestUseCase domain.DigestUseCase
+}
+
+func NewNotificationPreferencesHandler(router fiber.Router, digestUseCase domain.DigestUseCase) *NotificationPreferencesHandler {
+	handler := &NotificationPreferencesHandler{
+		digestUseCase: digestUseCase,
+	}
+
+	router.Get("/preferences", handler.GetPreferences)
+	router.Put("/preferences", handler.UpdatePreferences)
+
+	return handler
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Get whether the current user gets the daily trending digest, and their quiet hours
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} domain.NotificationPreferences
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /notifications/preferences [get]
+// @Security BearerAuth
+func (h *NotificationPreferencesHandler) GetPreferences(c *fiber.Ctx) error {
+	logger := utils.NewLogger("NotificationPreferencesHandler.GetPreferences")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	preferences, err := h.digestUseCase.GetPreferences(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(preferences, nil)
+	return c.JSON(preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Opt in or out of the daily trending digest and set the quiet hours it isn't sent in.
+// @Description Omitted fields are left unchanged.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body domain.NotificationPreferencesUpdate true "Preferences to change"
+// @Success 200 {object} domain.NotificationPreferences
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /notifications/preferences [put]
+// @Security BearerAuth
+func (h *NotificationPreferencesHandler) UpdatePreferences(c *fiber.Ctx) error {
+	logger := utils.NewLogger("NotificationPreferencesHandler.UpdatePreferences")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.NotificationPreferencesUpdate
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, req)
+
+	preferences, err := h.digestUseCase.UpdatePreferences(userID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(preferences, nil)
+	return c.JSON(preferences)
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OnboardingHandler struct {
+	onboardingUseCase domain.OnboardingUseCase
+}
+
+// NewOnboardingHandler registers the checklist on router and its management on adminRouter
+func NewOnboardingHandler(router fiber.Router, adminRouter fiber.Router, onboardingUseCase domain.OnboardingUseCase) *OnboardingHandler {
+	handler := &OnboardingHandler{
+		onboardingUseCase: onboardingUseCase,
+	}
+
+	router.Get("/", handler.GetChecklist)
+
+	adminRouter.Get("/", handler.ListSteps)
+	adminRouter.Post("/", handler.CreateStep)
+	adminRouter.Put("/:id", handler.UpdateStep)
+	adminRouter.Delete("/:id", handler.DeleteStep)
+
+	return handler
+}
+
+type OnboardingStepRequest struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Predicate   string `json:"predicate"`
+	Target      int    `json:"target"`
+	Order       int    `json:"order"`
+}
+
+// GetChecklist godoc
+// @Summary Get the onboarding checklist
+// @Description Get the current user's progress through the onboarding steps
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.OnboardingChecklist
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/me/onboarding [get]
+// @Security BearerAuth
+func (h *OnboardingHandler) GetChecklist(c *fiber.Ctx) error {
+	logger := utils.NewLogger("OnboardingHandler.GetChecklist")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	checklist, err := h.onboardingUseCase.GetChecklist(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(checklist, nil)
+	return c.JSON(checklist)
+}
+
+// ListSteps godoc
+// @Summary List onboarding steps
+// @Description List every configured onboarding step, including inactive ones
+// @Tags admin
+// @Produce json
+// @Success 200 {array} domain.OnboardingStep
+// @Router /admin/onboarding-steps [get]
+// @Security BearerAuth
+func (h *OnboardingHandler) ListSteps(c *fiber.Ctx) error {
+	logger := utils.NewLogger("OnboardingHandler.ListSteps")
+
+	steps, err := h.onboardingUseCase.ListSteps()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(steps)}, nil)
+	return c.JSON(steps)
+}
+
+// CreateStep godoc
+// @Summary Add an onboarding step
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body OnboardingStepRequest true "Onboarding step"
+// @Success 201 {object} domain.OnboardingStep
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /admin/onboarding-steps [post]
+// @Security BearerAuth
+func (h *OnboardingHandler) CreateStep(c *fiber.Ctx) error {
+	logger := utils.NewLogger("OnboardingHandler.CreateStep")
+
+	var req OnboardingStepRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(req)
+
+	step := &domain.OnboardingStep{
+		Key:         req.Key,
+		Title:       req.Title,
+		Description: req.Description,
+		Predicate:   req.Predicate,
+		Target:      req.Target,
+		Order:       req.Order,
+	}
+	if err := h.onboardingUseCase.CreateStep(step); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(step, nil)
+	return c.Status(fiber.StatusCreated).JSON(step)
+}
+
+// UpdateStep godoc
+// @Summary Update an onboarding step
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Onboarding step ID"
+// @Param request body domain.OnboardingStepUpdate true "Fields to change"
+// @Success 200 {object} domain.OnboardingStep
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/onboarding-steps/{id} [put]
+// @Security BearerAuth
+func (h *OnboardingHandler) UpdateStep(c *fiber.Ctx) error {
+	logger := utils.NewLogger("OnboardingHandler.UpdateStep")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+
+	var req domain.OnboardingStepUpdate
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(id, req)
+
+	step, err := h.onboardingUseCase.UpdateStep(id, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(step, nil)
+	return c.JSON(step)
+}
+
+// DeleteStep godoc
+// @Summary Remove an onboarding step
+// @Tags admin
+// @Param id path string true "Onboarding step ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/onboarding-steps/{id} [delete]
+// @Security BearerAuth
+func (h *OnboardingHandler) DeleteStep(c *fiber.Ctx) error {
+	logger := utils.NewLogger("OnboardingHandler.DeleteStep")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(id)
+
+	if err := h.onboardingUseCase.DeleteStep(id); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// PermalinkHandler resolves share links so universal links route to the right screen
+type PermalinkHandler struct {
+	permalinkUseCase domain.PermalinkUseCase
+}
+
+func NewPermalinkHandler(router fiber.Router, permalinkUseCase domain.PermalinkUseCase) *PermalinkHandler {
+	handler := &PermalinkHandler{
+		permalinkUseCase: permalinkUseCase,
+	}
+
+	router.Get("/resolve", handler.Resolve)
+
+	return handler
+}
+
+// Resolve godoc
+// @Summary Resolve a share link
+// @Description Resolve a post, comment, profile or chat link into a typed reference. Content the current user can't see isn't resolved. Invite links are checked but not joined.
+// @Tags permalinks
+// @Produce json
+// @Param url query string true "Share link, e.g. https://vongga.com/p/{postId} or vongga://u/{username}"
+// @Success 200 {object} domain.Permalink
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 410 {object} utils.ErrorResponse
+// @Router /resolve [get]
+// @Security BearerAuth
+func (h *PermalinkHandler) Resolve(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PermalinkHandler.Resolve")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	rawURL := c.Query("url")
+	logger.LogInput(map[string]interface{}{
+		"userId": userID,
+		"url":    rawURL,
+	})
+	if rawURL == "" {
+		err := fmt.Errorf("%w: url is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	link, err := h.permalinkUseCase.Resolve(userID, rawURL)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(link, nil)
+	return c.JSON(link)
+}
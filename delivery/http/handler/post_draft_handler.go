@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostDraftHandler serves the current user's unpublished posts
+type PostDraftHandler struct {
+	draftUseCase domain.PostDraftUseCase
+}
+
+func NewPostDraftHandler(router fiber.Router, draftUseCase domain.PostDraftUseCase) *PostDraftHandler {
+	handler := &PostDraftHandler{
+		draftUseCase: draftUseCase,
+	}
+
+	router.Post("/", handler.CreateDraft)
+	router.Get("/", handler.ListDrafts)
+	router.Get("/:id", handler.GetDraft)
+	router.Put("/:id", handler.UpdateDraft)
+	router.Put("/:id/publish", handler.PublishDraft)
+	router.Delete("/:id", handler.DeleteDraft)
+
+	return handler
+}
+
+// CreateDraft godoc
+// @Summary Create a post draft
+// @Description Save an unpublished post. Drafts aren't validated until published and never show in feeds or profiles.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param draft body domain.PostDraftRequest true "Draft"
+// @Success 201 {object} domain.PostDraft
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /posts/drafts [post]
+// @Security BearerAuth
+func (h *PostDraftHandler) CreateDraft(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostDraftHandler.CreateDraft")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.PostDraftRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, req)
+
+	draft, err := h.draftUseCase.CreateDraft(userID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return c.Status(fiber.StatusCreated).JSON(draft)
+}
+
+// ListDrafts godoc
+// @Summary List my post drafts
+// @Description The current user's drafts, last edited first
+// @Tags posts
+// @Produce json
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {object} domain.Page
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /posts/drafts [get]
+// @Security BearerAuth
+func (h *PostDraftHandler) ListDrafts(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostDraftHandler.ListDrafts")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
+	logger.LogInput(userID, limit, offset)
+
+	drafts, total, err := h.draftUseCase.ListDrafts(userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(drafts, len(drafts), limit, offset, total)
+	logger.LogOutput(map[string]interface{}{"count": len(drafts)}, nil)
+	return c.JSON(page)
+}
+
+// GetDraft godoc
+// @Summary Get a post draft
+// @Tags posts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} domain.PostDraft
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /posts/drafts/{id} [get]
+// @Security BearerAuth
+func (h *PostDraftHandler) GetDraft(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostDraftHandler.GetDraft")
+
+	userID, draftID, err := draftParams(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, draftID)
+
+	draft, err := h.draftUseCase.GetDraft(userID, draftID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return c.JSON(draft)
+}
+
+// UpdateDraft godoc
+// @Summary Replace a post draft
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Param draft body domain.PostDraftRequest true "Draft"
+// @Success 200 {object} domain.PostDraft
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /posts/drafts/{id} [put]
+// @Security BearerAuth
+func (h *PostDraftHandler) UpdateDraft(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostDraftHandler.UpdateDraft")
+
+	userID, draftID, err := draftParams(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.PostDraftRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, draftID, req)
+
+	draft, err := h.draftUseCase.UpdateDraft(userID, draftID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return c.JSON(draft)
+}
+
+// PublishDraft godoc
+// @Summary Publish a post draft
+// @Description Post the draft and delete it. The draft needs content or media by now.
+// @Tags posts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 201 {object} domain.Post
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /posts/drafts/{id}/publish [put]
+// @Security BearerAuth
+func (h *PostDraftHandler) PublishDraft(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostDraftHandler.PublishDraft")
+
+	userID, draftID, err := draftParams(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, draftID)
+
+	post, err := h.draftUseCase.PublishDraft(userID, draftID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(post.ID, nil)
+	return c.Status(fiber.StatusCreated).JSON(post)
+}
+
+// DeleteDraft godoc
+// @Summary Delete a post draft
+// @Tags posts
+// @Param id path string true "Draft ID"
+// @Success 204
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /posts/drafts/{id} [delete]
+// @Security BearerAuth
+func (h *PostDraftHandler) DeleteDraft(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostDraftHandler.DeleteDraft")
+
+	userID, draftID, err := draftParams(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, draftID)
+
+	if err := h.draftUseCase.DeleteDraft(userID, draftID); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// draftParams returns the current user and the draft ID of the path
+func draftParams(c *fiber.Ctx) (primitive.ObjectID, primitive.ObjectID, error) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, err
+	}
+	draftID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, domain.ErrInvalidID
+	}
+	return userID, draftID, nil
+}
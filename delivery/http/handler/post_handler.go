@@ -19,10 +19,17 @@ func NewPostHandler(router fiber.Router, pu domain.PostUseCase) *PostHandler {
 	}
 
 	router.Post("/", handler.CreatePost)
+	router.Post("/batch", handler.GetPostsBatch)
 	router.Get("/", handler.ListPosts)
 	router.Get("/:id", handler.GetPost)
 	router.Put("/:id", handler.UpdatePost)
 	router.Delete("/:id", handler.DeletePost)
+	router.Post("/:id/lock", handler.LockPost)
+	router.Delete("/:id/lock", handler.UnlockPost)
+	router.Post("/:id/view", handler.RecordView)
+	router.Post("/:id/pin", handler.PinPost)
+	router.Delete("/:id/pin", handler.UnpinPost)
+	router.Post("/:id/share", handler.SharePost)
 
 	return handler
 }
@@ -42,17 +49,25 @@ type UpdatePostRequest struct {
 	Tags       []string         `json:"tags,omitempty"`
 	Location   *domain.Location `json:"location,omitempty"`
 	Visibility string           `json:"visibility"`
+	LockToken  string           `json:"lockToken,omitempty"`
+}
+
+type SharePostRequest struct {
+	Content    string `json:"content,omitempty"` // optional quote
+	Visibility string `json:"visibility,omitempty"`
+}
+
+type LockPostRequest struct {
+	LockToken string `json:"lockToken,omitempty"`
 }
 
 func (h *PostHandler) CreatePost(c *fiber.Ctx) error {
 	logger := utils.NewLogger("PostHandler.CreatePost")
 
 	var req CreatePostRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	userID, err := utils.GetUserIDFromContext(c)
@@ -74,9 +89,10 @@ func (h *PostHandler) CreatePost(c *fiber.Ctx) error {
 	)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		if suspension := domain.AsSuspensionError(err); suspension != nil {
+			return utils.SendSuspensionError(c, suspension)
+		}
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(post, nil)
@@ -95,27 +111,218 @@ func (h *PostHandler) UpdatePost(c *fiber.Ctx) error {
 	}
 
 	var req UpdatePostRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
+		return utils.HandleError(c, err)
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
 
 	input := map[string]interface{}{
+		"userID":  userID,
 		"postID":  postID,
 		"request": req,
 	}
 	logger.LogInput(input)
 
-	post, err := h.postUseCase.UpdatePost(postID, req.Content, req.Media, req.Tags, req.Location, req.Visibility)
+	post, err := h.postUseCase.UpdatePost(userID, postID, req.LockToken, req.Content, req.Media, req.Tags, req.Location, req.Visibility)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(post, nil)
+	return c.JSON(post)
+}
+
+// LockPost starts or refreshes an editing session on a post
+func (h *PostHandler) LockPost(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.LockPost")
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	var req LockPostRequest
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	lock, err := h.postUseCase.LockPostForEdit(userID, postID, req.LockToken)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(lock, nil)
+	return c.JSON(lock)
+}
+
+// UnlockPost ends the editing session identified by the lockToken query parameter
+func (h *PostHandler) UnlockPost(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.UnlockPost")
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
 		})
 	}
 
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	lockToken := c.Query("lockToken")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	err = h.postUseCase.UnlockPost(userID, postID, lockToken)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Post unlocked successfully", nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RecordView counts a view of the post, once per user per day
+func (h *PostHandler) RecordView(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.RecordView")
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	counted, err := h.postUseCase.RecordView(userID, postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(counted, nil)
+	return c.JSON(fiber.Map{
+		"counted": counted,
+	})
+}
+
+// PinPost pins a post to the top of its author's profile, at most domain.MaxPinnedPosts at a time
+func (h *PostHandler) PinPost(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.PinPost")
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	post, err := h.postUseCase.PinPost(userID, postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(post, nil)
+	return c.JSON(post)
+}
+
+// UnpinPost returns a pinned post to its place in the profile
+func (h *PostHandler) UnpinPost(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.UnpinPost")
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	post, err := h.postUseCase.UnpinPost(userID, postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogOutput(post, nil)
 	return c.JSON(post)
 }
@@ -130,15 +337,21 @@ func (h *PostHandler) DeletePost(c *fiber.Ctx) error {
 			"error": "Invalid post ID",
 		})
 	}
-	logger.LogInput(postID)
 
-	err = h.postUseCase.DeletePost(postID)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
+	logger.LogInput(userID, postID)
+
+	err = h.postUseCase.DeletePost(userID, postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
 
 	logger.LogOutput("Post deleted successfully", nil)
 	return c.SendStatus(fiber.StatusNoContent)
@@ -155,14 +368,23 @@ func (h *PostHandler) GetPost(c *fiber.Ctx) error {
 		})
 	}
 
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
 	includeSubPosts := c.Query("includeSubPosts") == "true"
 	input := map[string]interface{}{
+		"viewerID":        viewerID,
 		"postID":          postID,
 		"includeSubPosts": includeSubPosts,
 	}
 	logger.LogInput(input)
 
-	post, err := h.postUseCase.GetPost(postID, includeSubPosts)
+	post, err := h.postUseCase.GetPost(viewerID, postID, includeSubPosts)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		if domain.IsNotFoundError(err) {
@@ -170,9 +392,7 @@ func (h *PostHandler) GetPost(c *fiber.Ctx) error {
 				"error": err.Error(),
 			})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(post, nil)
@@ -198,8 +418,15 @@ func (h *PostHandler) ListPosts(c *fiber.Ctx) error {
 		})
 	}
 
-	limit := c.QueryInt("limit", 0)
-	offset := c.QueryInt("offset", 0)
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
 	includeSubPosts := c.Query("includeSubPosts") == "true"
 	hasMedia := c.Query("hasMedia") == "true"
 	mediaType := c.Query("mediaType")
@@ -212,6 +439,7 @@ func (h *PostHandler) ListPosts(c *fiber.Ctx) error {
 	}
 
 	input := map[string]interface{}{
+		"viewerID":        viewerID,
 		"userID":         userID,
 		"limit":         limit,
 		"offset":        offset,
@@ -221,14 +449,93 @@ func (h *PostHandler) ListPosts(c *fiber.Ctx) error {
 	}
 	logger.LogInput(input)
 
-	posts, err := h.postUseCase.ListPosts(userID, limit, offset, includeSubPosts, hasMedia, mediaType)
+	posts, err := h.postUseCase.ListPosts(viewerID, userID, limit, offset, includeSubPosts, hasMedia, mediaType)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	total, err := h.postUseCase.CountPosts(viewerID, userID, hasMedia, mediaType)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(posts, len(posts), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
+
+// GetPostsBatch returns hydrated posts for up to domain.MaxBatchSize IDs with per-item errors
+func (h *PostHandler) GetPostsBatch(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.GetPostsBatch")
+
+	ids, err := parseBatchRequest(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(viewerID, ids)
+	items, err := h.postUseCase.GetPostsByIDs(viewerID, ids)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(items, nil)
+	return c.JSON(fiber.Map{
+		"items": items,
+	})
+}
+
+func (h *PostHandler) SharePost(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PostHandler.SharePost")
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
 		})
 	}
 
-	logger.LogOutput(posts, nil)
-	return c.JSON(posts)
+	var req SharePostRequest
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"userID":  userID,
+		"postID":  postID,
+		"request": req,
+	})
+
+	post, err := h.postUseCase.SharePost(userID, postID, req.Content, req.Visibility)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(post, nil)
+	return c.Status(fiber.StatusCreated).JSON(post)
 }
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// PrivacyHandler serves the current user's privacy settings
+type PrivacyHandler struct {
+	privacyUseCase domain.PrivacyUseCase
+}
+
+func NewPrivacyHandler(router fiber.Router, privacyUseCase domain.PrivacyUseCase) *PrivacyHandler {
+	handler := &PrivacyHandler{
+		privacyUseCase: privacyUseCase,
+	}
+
+	router.Get("/me/privacy", handler.GetPrivacy)
+	router.Put("/me/privacy", handler.UpdatePrivacy)
+
+	return handler
+}
+
+// GetPrivacy godoc
+// @Summary Get privacy settings
+// @Description Get who can message the current user, see their friends and comment on their posts,
+// @Description and whether they can be found by email or phone number
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.PrivacySettings
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/me/privacy [get]
+// @Security BearerAuth
+func (h *PrivacyHandler) GetPrivacy(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PrivacyHandler.GetPrivacy")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	privacy, err := h.privacyUseCase.GetPrivacy(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(privacy, nil)
+	return c.JSON(privacy)
+}
+
+// UpdatePrivacy godoc
+// @Summary Update privacy settings
+// @Description Replace the current user's privacy settings. Audiences are everyone, friends or nobody,
+// @Description omitted audiences are everyone.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body domain.PrivacySettings true "Privacy settings"
+// @Success 200 {object} domain.PrivacySettings
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/me/privacy [put]
+// @Security BearerAuth
+func (h *PrivacyHandler) UpdatePrivacy(c *fiber.Ctx) error {
+	logger := utils.NewLogger("PrivacyHandler.UpdatePrivacy")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.PrivacySettings
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, req)
+
+	privacy, err := h.privacyUseCase.UpdatePrivacy(userID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(privacy, nil)
+	return c.JSON(privacy)
+}
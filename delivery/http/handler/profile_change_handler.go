@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProfileChangeHandler serves the change history of profiles to their users and to admins
+type ProfileChangeHandler struct {
+	changeUseCase domain.ProfileChangeUseCase
+}
+
+func NewProfileChangeHandler(me fiber.Router, admin fiber.Router, changeUseCase domain.ProfileChangeUseCase) *ProfileChangeHandler {
+	handler := &ProfileChangeHandler{
+		changeUseCase: changeUseCase,
+	}
+
+	me.Get("/", handler.ListMyChanges)
+	me.Post("/:changeId/rollback", handler.RollbackMyChange)
+	admin.Get("/users/:id/profile-changes", handler.ListUserChanges)
+	admin.Post("/users/:id/profile-changes/:changeId/rollback", handler.RollbackUserChange)
+
+	return handler
+}
+
+// ListMyChanges godoc
+// @Summary List changes of my profile
+// @Description Field-level history of the current user's profile, newest first
+// @Tags users
+// @Produce json
+// @Param field query string false "Only changes of this field, e.g. dateOfBirth"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.ProfileChange
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/me/profile-changes [get]
+// @Security BearerAuth
+func (h *ProfileChangeHandler) ListMyChanges(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		utils.NewLogger("ProfileChangeHandler.ListMyChanges").LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	return h.listChanges(c, "ProfileChangeHandler.ListMyChanges", userID)
+}
+
+// RollbackMyChange godoc
+// @Summary Roll back a change of my profile
+// @Description Set the field of the change back to its old value. The rollback is recorded as a change too.
+// @Tags users
+// @Produce json
+// @Param changeId path string true "Profile change ID"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /users/me/profile-changes/{changeId}/rollback [post]
+// @Security BearerAuth
+func (h *ProfileChangeHandler) RollbackMyChange(c *fiber.Ctx) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		utils.NewLogger("ProfileChangeHandler.RollbackMyChange").LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	return h.rollbackChange(c, "ProfileChangeHandler.RollbackMyChange", userID)
+}
+
+// ListUserChanges godoc
+// @Summary List changes of a user's profile
+// @Description Field-level history of a user's profile, newest first
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param field query string false "Only changes of this field, e.g. dateOfBirth"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.ProfileChange
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/users/{id}/profile-changes [get]
+// @Security BearerAuth
+func (h *ProfileChangeHandler) ListUserChanges(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		utils.NewLogger("ProfileChangeHandler.ListUserChanges").LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	return h.listChanges(c, "ProfileChangeHandler.ListUserChanges", userID)
+}
+
+// RollbackUserChange godoc
+// @Summary Roll back a change of a user's profile
+// @Description Set the field of the change back to its old value. The rollback is recorded as a change by the admin.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param changeId path string true "Profile change ID"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /admin/users/{id}/profile-changes/{changeId}/rollback [post]
+// @Security BearerAuth
+func (h *ProfileChangeHandler) RollbackUserChange(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		utils.NewLogger("ProfileChangeHandler.RollbackUserChange").LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	return h.rollbackChange(c, "ProfileChangeHandler.RollbackUserChange", userID)
+}
+
+func (h *ProfileChangeHandler) listChanges(c *fiber.Ctx, name string, userID primitive.ObjectID) error {
+	logger := utils.NewLogger(name)
+
+	field := c.Query("field")
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"userId": userID,
+		"field":  field,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	changes, err := h.changeUseCase.ListChanges(userID, field, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(changes)}, nil)
+	return c.JSON(changes)
+}
+
+func (h *ProfileChangeHandler) rollbackChange(c *fiber.Ctx, name string, userID primitive.ObjectID) error {
+	logger := utils.NewLogger(name)
+
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	changeID, err := primitive.ObjectIDFromHex(c.Params("changeId"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(map[string]interface{}{
+		"actorId":  actorID,
+		"userId":   userID,
+		"changeId": changeID,
+	})
+
+	user, err := h.changeUseCase.RollbackChange(actorID, userID, changeID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(user, nil)
+	return c.JSON(user)
+}
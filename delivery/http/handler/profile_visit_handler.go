@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type ProfileVisitHandler struct {
+	visitUseCase domain.ProfileVisitUseCase
+}
+
+func NewProfileVisitHandler(router fiber.Router, visitUseCase domain.ProfileVisitUseCase) *ProfileVisitHandler {
+	handler := &ProfileVisitHandler{
+		visitUseCase: visitUseCase,
+	}
+
+	router.Get("/profile-visits", handler.GetInsights)
+
+	return handler
+}
+
+// GetInsights godoc
+// @Summary Get profile visit insights
+// @Description Daily visits of the current user's profile and where they came from (search, post, suggestion, qr, direct, other)
+// @Tags users
+// @Produce json
+// @Param days query int false "Number of days up to today, 30 by default and at most 90"
+// @Success 200 {object} domain.ProfileVisitInsights
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /users/me/insights/profile-visits [get]
+// @Security BearerAuth
+func (h *ProfileVisitHandler) GetInsights(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ProfileVisitHandler.GetInsights")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	days := c.QueryInt("days", domain.DefaultProfileVisitDays)
+	logger.LogInput(userID, days)
+
+	insights, err := h.visitUseCase.GetInsights(userID, days)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(insights, nil)
+	return c.JSON(insights)
+}
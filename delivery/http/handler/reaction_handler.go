@@ -11,7 +11,7 @@ type ReactionHandler struct {
 	reactionUseCase domain.ReactionUseCase
 }
 
-func NewReactionHandler(router fiber.Router, ru domain.ReactionUseCase) *ReactionHandler {
+func NewReactionHandler(router, posts fiber.Router, ru domain.ReactionUseCase) *ReactionHandler {
 	handler := &ReactionHandler{
 		reactionUseCase: ru,
 	}
@@ -20,6 +20,8 @@ func NewReactionHandler(router fiber.Router, ru domain.ReactionUseCase) *Reactio
 	router.Delete("/:id", handler.DeleteReaction)
 	router.Get("/post/:postId", handler.ListPostReactions)
 	router.Get("/comment/:commentId", handler.ListCommentReactions)
+	router.Get("/summary", handler.GetReactionSummary)
+	posts.Get("/:id/reactions/summary", handler.GetPostAnalytics)
 
 	return handler
 }
@@ -48,10 +50,10 @@ func (h *ReactionHandler) CreateReaction(c *fiber.Ctx) error {
 	}
 
 	var req domain.CreateReactionRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body")
+		return utils.HandleError(c, err)
 	}
 	logger.LogInput(userID, req)
 
@@ -127,8 +129,9 @@ func (h *ReactionHandler) DeleteReaction(c *fiber.Ctx) error {
 // @Param postId path string true "Post ID"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
+// @Param cursor query string false "Cursor from a previous page"
 // @Security BearerAuth
-// @Success 200 {array} domain.Reaction
+// @Success 200 {object} domain.Page
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Router /reactions/post/{postId} [get]
@@ -142,13 +145,7 @@ func (h *ReactionHandler) ListPostReactions(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusBadRequest, "Invalid post ID")
 	}
 
-	limit, offset := utils.GetPaginationParams(c)
-	if limit <= 0 {
-		limit = 10
-	}
-	if offset < 0 {
-		offset = 0
-	}
+	limit, offset := utils.GetCursorParams(c, 10)
 	logger.LogInput(postID, limit, offset)
 
 	reactions, err := h.reactionUseCase.ListReactions(postID, false, limit, offset)
@@ -157,8 +154,15 @@ func (h *ReactionHandler) ListPostReactions(c *fiber.Ctx) error {
 		return utils.HandleError(c, err)
 	}
 
-	logger.LogOutput(reactions, nil)
-	return c.JSON(reactions)
+	total, err := h.reactionUseCase.CountReactions(postID, false)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(reactions, len(reactions), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
 }
 
 // ListCommentReactions lists reactions for a comment
@@ -170,8 +174,9 @@ func (h *ReactionHandler) ListPostReactions(c *fiber.Ctx) error {
 // @Param commentId path string true "Comment ID"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
+// @Param cursor query string false "Cursor from a previous page"
 // @Security BearerAuth
-// @Success 200 {array} domain.Reaction
+// @Success 200 {object} domain.Page
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Router /reactions/comment/{commentId} [get]
@@ -185,13 +190,7 @@ func (h *ReactionHandler) ListCommentReactions(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusBadRequest, "Invalid comment ID")
 	}
 
-	limit, offset := utils.GetPaginationParams(c)
-	if limit <= 0 {
-		limit = 10
-	}
-	if offset < 0 {
-		offset = 0
-	}
+	limit, offset := utils.GetCursorParams(c, 10)
 	logger.LogInput(commentID, limit, offset)
 
 	reactions, err := h.reactionUseCase.ListReactions(commentID, true, limit, offset)
@@ -200,6 +199,93 @@ func (h *ReactionHandler) ListCommentReactions(c *fiber.Ctx) error {
 		return utils.HandleError(c, err)
 	}
 
-	logger.LogOutput(reactions, nil)
-	return c.JSON(reactions)
+	total, err := h.reactionUseCase.CountReactions(commentID, true)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	page := utils.NewPage(reactions, len(reactions), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
+
+// GetReactionSummary summarizes the reactions of a post or comment
+// @Summary Get a reaction summary
+// @Description Count the reactions of a post or comment per type, with the latest reacting users of each type
+// @Tags reactions
+// @Produce json
+// @Param postId query string false "Post ID, required without commentId"
+// @Param commentId query string false "Comment ID, required without postId"
+// @Param users query int false "Users per type, 3 by default and at most 20"
+// @Security BearerAuth
+// @Success 200 {object} domain.ReactionSummary
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /reactions/summary [get]
+func (h *ReactionHandler) GetReactionSummary(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReactionHandler.GetReactionSummary")
+
+	rawID, isComment := c.Query("postId"), false
+	if commentID := c.Query("commentId"); commentID != "" {
+		rawID, isComment = commentID, true
+	}
+	targetID, err := primitive.ObjectIDFromHex(rawID)
+	if err != nil {
+		logger.LogInput(rawID)
+		logger.LogOutput(nil, err)
+		return utils.SendError(c, fiber.StatusBadRequest, "A valid postId or commentId is required")
+	}
+
+	usersPerType := c.QueryInt("users", domain.DefaultReactionSummaryUsers)
+	logger.LogInput(targetID, isComment, usersPerType)
+
+	summary, err := h.reactionUseCase.GetReactionSummary(targetID, isComment, usersPerType)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(summary, nil)
+	return c.JSON(summary)
+}
+
+// GetPostAnalytics shows the author of a post how it's being reacted to
+// @Summary Get reaction analytics of my post
+// @Description Count the reactions of the current user's post per type, list the latest of their friends
+// @Description who reacted, and count the reactions per hour over the last 48 hours
+// @Tags reactions
+// @Produce json
+// @Param id path string true "Post ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.PostReactionAnalytics
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse "Not the post's author"
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /posts/{id}/reactions/summary [get]
+func (h *ReactionHandler) GetPostAnalytics(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReactionHandler.GetPostAnalytics")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(userID, postID)
+
+	analytics, err := h.reactionUseCase.GetPostAnalytics(userID, postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(analytics.Total, nil)
+	return c.JSON(analytics)
 }
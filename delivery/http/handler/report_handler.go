@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportHandler takes content reports from users and serves them to admins
+type ReportHandler struct {
+	reportUseCase domain.ReportUseCase
+}
+
+func NewReportHandler(router fiber.Router, admin fiber.Router, reportUseCase domain.ReportUseCase) *ReportHandler {
+	handler := &ReportHandler{
+		reportUseCase: reportUseCase,
+	}
+
+	router.Post("/", handler.CreateReport)
+	admin.Get("/", handler.ListReports)
+	admin.Get("/targets", handler.ListTargetCounts)
+	admin.Get("/:id", handler.GetReport)
+	admin.Put("/:id/resolve", handler.ResolveReport)
+
+	return handler
+}
+
+// CreateReport godoc
+// @Summary Report content
+// @Description Flag a post, comment, user or chat message for moderators. A user can have
+// @Description one open report per target.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param request body domain.ReportRequest true "Target type (post, comment, user, message), target ID, reason and details"
+// @Success 201 {object} domain.Report
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /reports [post]
+// @Security BearerAuth
+func (h *ReportHandler) CreateReport(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReportHandler.CreateReport")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req domain.ReportRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, req)
+
+	report, err := h.reportUseCase.CreateReport(userID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return c.Status(fiber.StatusCreated).JSON(report)
+}
+
+// ListReports godoc
+// @Summary List content reports
+// @Description Reports newest first
+// @Tags admin
+// @Produce json
+// @Param status query string false "open, resolved or dismissed"
+// @Param targetType query string false "post, comment, user or message"
+// @Param targetId query string false "Only reports of this target"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.Report
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/reports [get]
+// @Security BearerAuth
+func (h *ReportHandler) ListReports(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReportHandler.ListReports")
+
+	filter := reportFilterParams(c)
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(filter, limit, offset)
+
+	reports, err := h.reportUseCase.ListReports(filter, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(reports)}, nil)
+	return c.JSON(reports)
+}
+
+// ListTargetCounts godoc
+// @Summary List reported targets
+// @Description Report counts per target, targets with the most open reports first. A status
+// @Description lists the targets having reports of that status.
+// @Tags admin
+// @Produce json
+// @Param status query string false "open, resolved or dismissed"
+// @Param targetType query string false "post, comment, user or message"
+// @Param targetId query string false "Only this target"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.ReportCount
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /admin/reports/targets [get]
+// @Security BearerAuth
+func (h *ReportHandler) ListTargetCounts(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReportHandler.ListTargetCounts")
+
+	filter := reportFilterParams(c)
+	limit := utils.GetQueryInt(c, "limit", 20)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(filter, limit, offset)
+
+	counts, err := h.reportUseCase.ListTargetCounts(filter, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(counts)}, nil)
+	return c.JSON(counts)
+}
+
+// GetReport godoc
+// @Summary Get a content report
+// @Tags admin
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success 200 {object} domain.Report
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/reports/{id} [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetReport(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReportHandler.GetReport")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(id)
+
+	report, err := h.reportUseCase.GetReport(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return c.JSON(report)
+}
+
+// ResolveReport godoc
+// @Summary Resolve a content report
+// @Description Close the report and every other open report of its target as resolved or
+// @Description dismissed. The decision is recorded in the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param request body domain.ResolveReportRequest true "Status (resolved, dismissed) and note"
+// @Success 200 {object} domain.Report
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/reports/{id}/resolve [put]
+// @Security BearerAuth
+func (h *ReportHandler) ResolveReport(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReportHandler.ResolveReport")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+
+	var req domain.ResolveReportRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(adminID, id, req)
+
+	report, err := h.reportUseCase.ResolveReport(adminID, id, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return c.JSON(report)
+}
+
+func reportFilterParams(c *fiber.Ctx) domain.ReportFilter {
+	return domain.ReportFilter{
+		Status:     c.Query("status"),
+		TargetType: c.Query("targetType"),
+		TargetID:   c.Query("targetId"),
+	}
+}
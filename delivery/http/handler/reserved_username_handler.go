@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReservedUsernameHandler struct {
+	usernameUseCase domain.UsernameUseCase
+}
+
+func NewReservedUsernameHandler(router fiber.Router, usernameUseCase domain.UsernameUseCase) *ReservedUsernameHandler {
+	handler := &ReservedUsernameHandler{
+		usernameUseCase: usernameUseCase,
+	}
+
+	router.Get("/", handler.ListReservedUsernames)
+	router.Post("/", handler.CreateReservedUsername)
+	router.Put("/:id", handler.UpdateReservedUsername)
+	router.Delete("/:id", handler.DeleteReservedUsername)
+
+	return handler
+}
+
+type ReservedUsernameRequest struct {
+	Username  string                          `json:"username"`
+	Category  domain.ReservedUsernameCategory `json:"category"`
+	Reason    string                          `json:"reason"`
+	IsPremium bool                            `json:"isPremium"`
+}
+
+// ListReservedUsernames godoc
+// @Summary List reserved usernames
+// @Tags admin
+// @Produce json
+// @Param category query string false "Filter by category (brand, offensive, system)"
+// @Param limit query int false "Number of items to return (default 50)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} domain.ReservedUsername
+// @Router /admin/reserved-usernames [get]
+// @Security BearerAuth
+func (h *ReservedUsernameHandler) ListReservedUsernames(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReservedUsernameHandler.ListReservedUsernames")
+
+	category := c.Query("category")
+	limit := utils.GetQueryInt(c, "limit", 50)
+	offset := utils.GetQueryInt(c, "offset", 0)
+	logger.LogInput(map[string]interface{}{
+		"category": category,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	reserved, err := h.usernameUseCase.ListReservedUsernames(category, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(reserved)}, nil)
+	return c.JSON(reserved)
+}
+
+// CreateReservedUsername godoc
+// @Summary Reserve a username
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body ReservedUsernameRequest true "Reserved username"
+// @Success 201 {object} domain.ReservedUsername
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /admin/reserved-usernames [post]
+// @Security BearerAuth
+func (h *ReservedUsernameHandler) CreateReservedUsername(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReservedUsernameHandler.CreateReservedUsername")
+
+	var req ReservedUsernameRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(req)
+
+	reserved := &domain.ReservedUsername{
+		Username:  req.Username,
+		Category:  req.Category,
+		Reason:    req.Reason,
+		IsPremium: req.IsPremium,
+	}
+	if err := h.usernameUseCase.CreateReservedUsername(reserved); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(reserved, nil)
+	return c.Status(fiber.StatusCreated).JSON(reserved)
+}
+
+// UpdateReservedUsername godoc
+// @Summary Update a reserved username
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Reserved username ID"
+// @Param request body ReservedUsernameRequest true "Reserved username"
+// @Success 200 {object} domain.ReservedUsername
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/reserved-usernames/{id} [put]
+// @Security BearerAuth
+func (h *ReservedUsernameHandler) UpdateReservedUsername(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReservedUsernameHandler.UpdateReservedUsername")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+
+	var req ReservedUsernameRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(id, req)
+
+	reserved, err := h.usernameUseCase.UpdateReservedUsername(id, req.Category, req.Reason, req.IsPremium)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(reserved, nil)
+	return c.JSON(reserved)
+}
+
+// DeleteReservedUsername godoc
+// @Summary Release a reserved username
+// @Tags admin
+// @Param id path string true "Reserved username ID"
+// @Success 204
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/reserved-usernames/{id} [delete]
+// @Security BearerAuth
+func (h *ReservedUsernameHandler) DeleteReservedUsername(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ReservedUsernameHandler.DeleteReservedUsername")
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(id)
+
+	if err := h.usernameUseCase.DeleteReservedUsername(id); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type SearchHandler struct {
+	searchUseCase domain.SearchUseCase
+}
+
+func NewSearchHandler(router fiber.Router, searchUseCase domain.SearchUseCase) *SearchHandler {
+	handler := &SearchHandler{
+		searchUseCase: searchUseCase,
+	}
+
+	router.Get("/", handler.Search)
+	router.Get("/suggestions", handler.Suggest)
+	router.Get("/history", handler.GetHistory)
+	router.Delete("/history", handler.ClearHistory)
+	router.Delete("/history/:query", handler.DeleteHistoryEntry)
+
+	return handler
+}
+
+// Search godoc
+// @Summary Search posts and users
+// @Description Full-text search over post content, tags, usernames and display names, ranked by relevance.
+// @Description The query is added to the user's search history when the first page is fetched.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search text"
+// @Param type query string false "all, post or user (default all)"
+// @Param limit query int false "Number of items to return (default 20)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} domain.Page
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /search [get]
+// @Security BearerAuth
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SearchHandler.Search")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	query := c.Query("q")
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "q is required",
+		})
+	}
+
+	searchType := domain.SearchResultType(c.Query("type", string(domain.SearchTypeAll)))
+	if searchType != domain.SearchTypeAll && searchType != domain.SearchTypePost && searchType != domain.SearchTypeUser {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid type. Must be 'all', 'post' or 'user'",
+		})
+	}
+
+	limit, offset := utils.GetCursorParams(c, 20)
+	logger.LogInput(map[string]interface{}{
+		"query":      query,
+		"searchType": searchType,
+		"limit":      limit,
+		"offset":     offset,
+	})
+
+	results, err := h.searchUseCase.Search(query, searchType, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	total, err := h.searchUseCase.CountResults(query, searchType)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	// The search already ran, failing to remember it doesn't fail the request
+	if offset == 0 {
+		if err := h.searchUseCase.RecordSearch(userID.Hex(), query); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
+	page := utils.NewPage(results, len(results), limit, offset, total)
+	logger.LogOutput(page, nil)
+	return c.JSON(page)
+}
+
+// Suggest godoc
+// @Summary Suggest search queries
+// @Description Complete a partly typed query from the user's recent searches first, then from
+// @Description queries many users searched recently
+// @Tags search
+// @Produce json
+// @Param q query string true "Typed text"
+// @Param limit query int false "Number of suggestions, up to 20 (default 10)"
+// @Success 200 {array} domain.SearchSuggestion
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /search/suggestions [get]
+// @Security BearerAuth
+func (h *SearchHandler) Suggest(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SearchHandler.Suggest")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	prefix := c.Query("q")
+	limit := utils.GetQueryInt(c, "limit", domain.DefaultSearchSuggestions)
+	logger.LogInput(userID, prefix, limit)
+
+	suggestions, err := h.searchUseCase.Suggest(userID.Hex(), prefix, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(suggestions)}, nil)
+	return c.JSON(suggestions)
+}
+
+// GetHistory godoc
+// @Summary List my recent searches
+// @Description The current user's last 20 searches, latest first
+// @Tags search
+// @Produce json
+// @Success 200 {array} domain.SearchHistoryEntry
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /search/history [get]
+// @Security BearerAuth
+func (h *SearchHandler) GetHistory(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SearchHandler.GetHistory")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	entries, err := h.searchUseCase.GetHistory(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(entries)}, nil)
+	return c.JSON(entries)
+}
+
+// DeleteHistoryEntry godoc
+// @Summary Remove a recent search
+// @Tags search
+// @Param query path string true "The search query, URL encoded"
+// @Success 204
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /search/history/{query} [delete]
+// @Security BearerAuth
+func (h *SearchHandler) DeleteHistoryEntry(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SearchHandler.DeleteHistoryEntry")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	query, err := url.PathUnescape(c.Params("query"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidInput)
+	}
+	logger.LogInput(userID, query)
+
+	if err := h.searchUseCase.DeleteHistoryEntry(userID.Hex(), query); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ClearHistory godoc
+// @Summary Clear my recent searches
+// @Tags search
+// @Success 204
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /search/history [delete]
+// @Security BearerAuth
+func (h *SearchHandler) ClearHistory(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SearchHandler.ClearHistory")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID)
+
+	if err := h.searchUseCase.ClearHistory(userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(nil, nil)
+	return c.SendStatus(fiber.StatusNoContent)
+}
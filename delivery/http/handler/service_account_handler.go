@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type ServiceAccountHandler struct {
+	serviceAccountUseCase domain.ServiceAccountUseCase
+}
+
+func NewServiceAccountHandler(serviceAccountUseCase domain.ServiceAccountUseCase) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		serviceAccountUseCase: serviceAccountUseCase,
+	}
+}
+
+// IssueToken exchanges service account credentials for a scoped machine token
+// @Summary Issue service account token
+// @Description Client credentials exchange for background workers and cron runners
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ServiceTokenRequest true "Client credentials and requested scopes"
+// @Success 200 {object} domain.ServiceToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/service-token [post]
+func (h *ServiceAccountHandler) IssueToken(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ServiceAccountHandler.IssueToken")
+
+	var req ServiceTokenRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"clientId": req.ClientID,
+		"scopes":   req.Scopes,
+	})
+	token, err := h.serviceAccountUseCase.IssueToken(c.Context(), req.ClientID, req.ClientSecret, req.Scopes)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.SendError(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	logger.LogOutput(map[string]interface{}{
+		"clientId": req.ClientID,
+		"scopes":   token.Scopes,
+	}, nil)
+	return c.JSON(token)
+}
+
+type ServiceTokenRequest struct {
+	ClientID     string   `json:"clientId" validate:"required" example:"story-archiver"`
+	ClientSecret string   `json:"clientSecret" validate:"required" example:"client_secret_here"`
+	Scopes       []string `json:"scopes,omitempty" example:"jobs:run"`
+}
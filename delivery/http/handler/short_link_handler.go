@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShortLinkHandler creates short share links and redirects them to their targets
+type ShortLinkHandler struct {
+	shortLinkUseCase domain.ShortLinkUseCase
+}
+
+// NewShortLinkHandler registers the public redirect, the link creation routes are
+// registered on the protected post and user groups
+func NewShortLinkHandler(router fiber.Router, shortLinkUseCase domain.ShortLinkUseCase) *ShortLinkHandler {
+	handler := &ShortLinkHandler{
+		shortLinkUseCase: shortLinkUseCase,
+	}
+
+	router.Get("/:code", handler.Redirect)
+
+	return handler
+}
+
+// CreatePostLink godoc
+// @Summary Create a short link to a post
+// @Description Create a short share link to a post the current user can see. Without expiresInHours the user's existing link to the post is returned.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param request body domain.ShortLinkRequest false "Link expiry"
+// @Success 200 {object} domain.ShortLink
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /posts/{id}/share-link [post]
+// @Security BearerAuth
+func (h *ShortLinkHandler) CreatePostLink(c *fiber.Ctx) error {
+	return h.createLink(c, "ShortLinkHandler.CreatePostLink", h.shortLinkUseCase.CreatePostLink)
+}
+
+// CreateProfileLink godoc
+// @Summary Create a short link to a profile
+// @Description Create a short share link to a user's profile. Without expiresInHours the current user's existing link to the profile is returned.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body domain.ShortLinkRequest false "Link expiry"
+// @Success 200 {object} domain.ShortLink
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /users/{id}/share-link [post]
+// @Security BearerAuth
+func (h *ShortLinkHandler) CreateProfileLink(c *fiber.Ctx) error {
+	return h.createLink(c, "ShortLinkHandler.CreateProfileLink", h.shortLinkUseCase.CreateProfileLink)
+}
+
+func (h *ShortLinkHandler) createLink(c *fiber.Ctx, name string, create func(userID, targetID primitive.ObjectID, req domain.ShortLinkRequest) (*domain.ShortLink, error)) error {
+	logger := utils.NewLogger(name)
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+
+	var req domain.ShortLinkRequest
+	if len(c.Body()) > 0 {
+		if err := utils.ParseBody(c, &req); err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+	}
+	logger.LogInput(map[string]interface{}{
+		"userId":   userID,
+		"targetId": targetID,
+		"request":  req,
+	})
+
+	link, err := create(userID, targetID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(link, nil)
+	return c.JSON(link)
+}
+
+// Redirect godoc
+// @Summary Open a short link
+// @Description Count a click and redirect to the canonical link of the post or profile
+// @Tags posts
+// @Param code path string true "Short link code"
+// @Success 302
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 410 {object} utils.ErrorResponse
+// @Router /s/{code} [get]
+func (h *ShortLinkHandler) Redirect(c *fiber.Ctx) error {
+	logger := utils.NewLogger("ShortLinkHandler.Redirect")
+
+	code := c.Params("code")
+	logger.LogInput(code)
+
+	link, err := h.shortLinkUseCase.Open(code)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(link.TargetURL, nil)
+	return c.Redirect(link.TargetURL, fiber.StatusFound)
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
@@ -20,6 +22,9 @@ func NewStoryHandler(router fiber.Router, storyUseCase domain.StoryUseCase) *Sto
 	router.Get("/user/:userId", handler.GetUserStories)
 	router.Get("/:storyId", handler.GetStoryByID)
 	router.Post("/:storyId/view", handler.ViewStory)
+	router.Post("/:storyId/reply", handler.ReplyToStory)
+	router.Post("/:storyId/reactions", handler.ReactToStory)
+	router.Delete("/:storyId/reactions", handler.RemoveStoryReaction)
 	router.Delete("/:storyId", handler.DeleteStory)
 
 	return handler
@@ -37,32 +42,18 @@ func (h *StoryHandler) CreateStory(c *fiber.Ctx) error {
 	}
 
 	var req struct {
-		MediaURL      string           `json:"mediaUrl"`
-		MediaType     domain.StoryType `json:"mediaType"`
-		MediaDuration int              `json:"mediaDuration,omitempty"`
-		Thumbnail     string           `json:"thumbnail,omitempty"`
-		Caption       string           `json:"caption,omitempty"`
-		Location      string           `json:"location,omitempty"`
+		MediaURL      string             `json:"mediaUrl" validate:"required"`
+		MediaType     domain.StoryType   `json:"mediaType" validate:"oneof=image video"`
+		MediaDuration int                `json:"mediaDuration,omitempty"`
+		Thumbnail     string             `json:"thumbnail,omitempty"`
+		Caption       string             `json:"caption,omitempty"`
+		Location      string             `json:"location,omitempty"`
+		Audio         *domain.StoryAudio `json:"audio,omitempty"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-
-	// Validate required fields
-	if req.MediaURL == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "media URL is required",
-		})
-	}
-
-	if req.MediaType != domain.Image && req.MediaType != domain.Video {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid media type",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	story := &domain.Story{
@@ -75,15 +66,22 @@ func (h *StoryHandler) CreateStory(c *fiber.Ctx) error {
 		},
 		Caption:  req.Caption,
 		Location: req.Location,
+		Audio:    req.Audio,
 	}
 
 	logger.LogInput(story)
 	err = h.storyUseCase.CreateStory(story)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		if suspension := domain.AsSuspensionError(err); suspension != nil {
+			return utils.SendSuspensionError(c, suspension)
+		}
+		if errors.Is(err, domain.ErrInvalidStoryAudio) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(story, nil)
@@ -104,14 +102,23 @@ func (h *StoryHandler) GetStoryByID(c *fiber.Ctx) error {
 		})
 	}
 
-	story, err := h.storyUseCase.GetStoryByID(storyID)
+	viewerID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
 
+	story, err := h.storyUseCase.GetStoryByID(storyID, viewerID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		if errors.Is(err, domain.ErrNotFound) {
+			return utils.HandleError(c, err)
+		}
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogOutput(story, nil)
 	return c.JSON(fiber.Map{
 		"story": story,
@@ -124,14 +131,20 @@ func (h *StoryHandler) GetUserStories(c *fiber.Ctx) error {
 	userID := c.Params("userId")
 	logger.LogInput(userID)
 
-	stories, err := h.storyUseCase.GetUserStories(userID)
+	viewerID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
 
+	stories, err := h.storyUseCase.GetUserStories(userID, viewerID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogOutput(stories, nil)
 	return c.JSON(fiber.Map{
 		"stories": stories,
@@ -141,14 +154,20 @@ func (h *StoryHandler) GetUserStories(c *fiber.Ctx) error {
 func (h *StoryHandler) GetActiveStories(c *fiber.Ctx) error {
 	logger := utils.NewLogger("StoryHandler.GetActiveStories")
 
-	stories, err := h.storyUseCase.GetActiveStories()
+	viewerID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
 
+	stories, err := h.storyUseCase.GetActiveStories(viewerID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogOutput(stories, nil)
 	return c.JSON(fiber.Map{
 		"stories": stories,
@@ -176,15 +195,153 @@ func (h *StoryHandler) ViewStory(c *fiber.Ctx) error {
 	err = h.storyUseCase.ViewStory(storyID, viewerID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
 	return c.SendStatus(fiber.StatusOK)
 }
 
+type StoryReplyRequest struct {
+	Content string `json:"content"`
+}
+
+// ReplyToStory godoc
+// @Summary Reply to a story
+// @Description Send a reply to the story owner as a chat message in your private room, created if needed.
+// @Description The message keeps a snapshot of the story.
+// @Tags stories
+// @Accept json
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Param request body StoryReplyRequest true "Reply"
+// @Success 201 {object} domain.ChatMessage
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 429 {object} utils.ErrorResponse
+// @Router /stories/{storyId}/reply [post]
+// @Security BearerAuth
+func (h *StoryHandler) ReplyToStory(c *fiber.Ctx) error {
+	logger := utils.NewLogger("StoryHandler.ReplyToStory")
+
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req StoryReplyRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	storyID := c.Params("storyId")
+	logger.LogInput(map[string]interface{}{
+		"storyId":  storyID,
+		"viewerId": viewerID.Hex(),
+		"content":  req.Content,
+	})
+
+	message, err := h.storyUseCase.ReplyToStory(storyID, viewerID.Hex(), req.Content)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		if suspension := domain.AsSuspensionError(err); suspension != nil {
+			return utils.SendSuspensionError(c, suspension)
+		}
+		return sendChatError(c, err)
+	}
+
+	logger.LogOutput(message, nil)
+	return c.Status(fiber.StatusCreated).JSON(message)
+}
+
+type StoryReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// ReactToStory godoc
+// @Summary React to a story
+// @Description Send a quick emoji reaction (❤️ 😂 😮 😢 😡 👏 🔥 🎉) on a story. Reacting again changes the emoji.
+// @Description The story owner is notified of new reactions.
+// @Tags stories
+// @Accept json
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Param request body StoryReactionRequest true "Reaction"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /stories/{storyId}/reactions [post]
+// @Security BearerAuth
+func (h *StoryHandler) ReactToStory(c *fiber.Ctx) error {
+	logger := utils.NewLogger("StoryHandler.ReactToStory")
+
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req StoryReactionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	storyID := c.Params("storyId")
+	logger.LogInput(map[string]interface{}{
+		"storyId":  storyID,
+		"viewerId": viewerID.Hex(),
+		"emoji":    req.Emoji,
+	})
+
+	if err := h.storyUseCase.ReactToStory(storyID, viewerID.Hex(), req.Emoji); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Reaction saved", nil)
+	return utils.SendSuccess(c, "Reaction saved")
+}
+
+// RemoveStoryReaction godoc
+// @Summary Remove my reaction from a story
+// @Tags stories
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /stories/{storyId}/reactions [delete]
+// @Security BearerAuth
+func (h *StoryHandler) RemoveStoryReaction(c *fiber.Ctx) error {
+	logger := utils.NewLogger("StoryHandler.RemoveStoryReaction")
+
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	storyID := c.Params("storyId")
+	logger.LogInput(map[string]interface{}{
+		"storyId":  storyID,
+		"viewerId": viewerID.Hex(),
+	})
+
+	if err := h.storyUseCase.RemoveStoryReaction(storyID, viewerID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Reaction removed", nil)
+	return utils.SendSuccess(c, "Reaction removed")
+}
+
 func (h *StoryHandler) DeleteStory(c *fiber.Ctx) error {
 	logger := utils.NewLogger("StoryHandler.DeleteStory")
 
@@ -206,9 +363,12 @@ func (h *StoryHandler) DeleteStory(c *fiber.Ctx) error {
 	err = h.storyUseCase.DeleteStory(storyID, userID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		if errors.Is(err, domain.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(nil, nil)
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type StoryInsightsHandler struct {
+	insightsUseCase domain.StoryInsightsUseCase
+}
+
+func NewStoryInsightsHandler(router fiber.Router, insightsUseCase domain.StoryInsightsUseCase) *StoryInsightsHandler {
+	handler := &StoryInsightsHandler{
+		insightsUseCase: insightsUseCase,
+	}
+
+	router.Post("/interactions", handler.RecordInteractions)
+	router.Get("/:storyId/insights", handler.GetInsights)
+
+	return handler
+}
+
+type RecordStoryInteractionsRequest struct {
+	Events []domain.StoryInteractionEvent `json:"events"`
+}
+
+// RecordInteractions godoc
+// @Summary Send story interactions
+// @Description Accept a batch of up to 100 story interactions: tap_forward, tap_back, exit and swipe_up.
+// @Description Position is the second of the story the viewer was at. An invalid event rejects the whole batch,
+// @Description events on expired stories and on the viewer's own stories are skipped.
+// @Tags stories
+// @Accept json
+// @Produce json
+// @Param request body RecordStoryInteractionsRequest true "Interaction batch"
+// @Success 202 {object} map[string]int
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /stories/interactions [post]
+// @Security BearerAuth
+func (h *StoryInsightsHandler) RecordInteractions(c *fiber.Ctx) error {
+	logger := utils.NewLogger("StoryInsightsHandler.RecordInteractions")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	var req RecordStoryInteractionsRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(userID, len(req.Events))
+
+	counted, err := h.insightsUseCase.RecordInteractions(userID.Hex(), req.Events)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(counted, nil)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"accepted": counted,
+	})
+}
+
+// GetInsights godoc
+// @Summary Get the insights of my story
+// @Description Count the taps forward and back, exits and swipe ups on the current user's story, the share
+// @Description of viewers who skipped, exited or watched it to the end, and how many viewers were still
+// @Description watching at each second. Available after the story expired too.
+// @Tags stories
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Success 200 {object} domain.StoryInsights
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /stories/{storyId}/insights [get]
+// @Security BearerAuth
+func (h *StoryInsightsHandler) GetInsights(c *fiber.Ctx) error {
+	logger := utils.NewLogger("StoryInsightsHandler.GetInsights")
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	storyID := c.Params("storyId")
+	logger.LogInput(storyID, userID)
+
+	insights, err := h.insightsUseCase.GetInsights(storyID, userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(insights, nil)
+	return c.JSON(insights)
+}
@@ -44,15 +44,18 @@ func (h *SubPostHandler) CreateSubPost(c *fiber.Ctx) error {
 	}
 
 	var req CreateSubPostRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
-	// TODO: Get userID from auth context
-	userID := primitive.NewObjectID()
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
 
 	input := map[string]interface{}{
 		"parentID": parentID,
@@ -64,9 +67,7 @@ func (h *SubPostHandler) CreateSubPost(c *fiber.Ctx) error {
 	subPost, err := h.subPostUseCase.CreateSubPost(parentID, userID, req.Content, req.Media, req.Order)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(subPost, nil)
@@ -90,11 +91,9 @@ func (h *SubPostHandler) UpdateSubPost(c *fiber.Ctx) error {
 	}
 
 	var req UpdateSubPostRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	input := map[string]interface{}{
@@ -106,9 +105,7 @@ func (h *SubPostHandler) UpdateSubPost(c *fiber.Ctx) error {
 	subPost, err := h.subPostUseCase.UpdateSubPost(subPostID, req.Content, req.Media)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(subPost, nil)
@@ -130,9 +127,7 @@ func (h *SubPostHandler) DeleteSubPost(c *fiber.Ctx) error {
 	err = h.subPostUseCase.DeleteSubPost(subPostID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput("SubPost deleted successfully", nil)
@@ -149,16 +144,25 @@ func (h *SubPostHandler) GetSubPost(c *fiber.Ctx) error {
 			"error": "Invalid subpost ID",
 		})
 	}
-	logger.LogInput(subPostID)
-
-	subPost, err := h.subPostUseCase.GetSubPost(subPostID)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
 		})
 	}
 
+	logger.LogInput(map[string]interface{}{
+		"userID":    userID,
+		"subPostID": subPostID,
+	})
+
+	subPost, err := h.subPostUseCase.GetSubPost(userID, subPostID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	logger.LogOutput(subPost, nil)
 	return c.JSON(subPost)
 }
@@ -174,22 +178,29 @@ func (h *SubPostHandler) ListSubPosts(c *fiber.Ctx) error {
 		})
 	}
 
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
 	limit, _ := strconv.Atoi(c.Query("limit"))
 	offset, _ := strconv.Atoi(c.Query("offset"))
 
 	input := map[string]interface{}{
+		"userID":   userID,
 		"parentID": parentID,
 		"limit":    limit,
 		"offset":   offset,
 	}
 	logger.LogInput(input)
 
-	subPosts, err := h.subPostUseCase.ListSubPosts(parentID, limit, offset)
+	subPosts, err := h.subPostUseCase.ListSubPosts(userID, parentID, limit, offset)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(subPosts, nil)
@@ -212,11 +223,9 @@ func (h *SubPostHandler) ReorderSubPosts(c *fiber.Ctx) error {
 	}
 
 	var req ReorderSubPostsRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	// Convert string IDs to ObjectIDs
@@ -241,9 +250,7 @@ func (h *SubPostHandler) ReorderSubPosts(c *fiber.Ctx) error {
 	err = h.subPostUseCase.ReorderSubPosts(parentID, orders)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput("SubPosts reordered successfully", nil)
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SuspensionHandler lets admins ban and mute users for a limited time
+type SuspensionHandler struct {
+	suspensionUseCase domain.SuspensionUseCase
+}
+
+func NewSuspensionHandler(router fiber.Router, suspensionUseCase domain.SuspensionUseCase) *SuspensionHandler {
+	handler := &SuspensionHandler{
+		suspensionUseCase: suspensionUseCase,
+	}
+
+	router.Post("/users/:id/suspension", handler.SuspendUser)
+	router.Delete("/users/:id/suspension", handler.LiftSuspension)
+
+	return handler
+}
+
+// SuspendUser godoc
+// @Summary Suspend a user
+// @Description Ban or mute a user until a time or for a number of hours. The user is notified
+// @Description and the suspension is lifted automatically once it ends. Suspending a suspended
+// @Description user replaces the current suspension.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body domain.SuspendRequest true "Type (ban, mute), reason and until or durationHours"
+// @Success 201 {object} domain.Suspension
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/users/{id}/suspension [post]
+// @Security BearerAuth
+func (h *SuspensionHandler) SuspendUser(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SuspensionHandler.SuspendUser")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+
+	var req domain.SuspendRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"userId":  userID,
+		"request": req,
+	})
+
+	suspension, err := h.suspensionUseCase.Suspend(adminID, userID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(suspension, nil)
+	return c.Status(fiber.StatusCreated).JSON(suspension)
+}
+
+// LiftSuspension godoc
+// @Summary Lift a user suspension
+// @Description End a ban or mute early. The user is notified.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /admin/users/{id}/suspension [delete]
+// @Security BearerAuth
+func (h *SuspensionHandler) LiftSuspension(c *fiber.Ctx) error {
+	logger := utils.NewLogger("SuspensionHandler.LiftSuspension")
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, domain.ErrInvalidID)
+	}
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"userId":  userID,
+	})
+
+	if err := h.suspensionUseCase.Lift(adminID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput("Suspension lifted", nil)
+	return utils.SendSuccess(c, "Suspension lifted")
+}
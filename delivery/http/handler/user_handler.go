@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"regexp"
 	"time"
 
@@ -10,19 +11,27 @@ import (
 )
 
 type UserHandler struct {
-	userUseCase domain.UserUseCase
+	userUseCase     domain.UserUseCase
+	usernameUseCase domain.UsernameUseCase
+	interestUseCase domain.InterestUseCase
+	visitUseCase    domain.ProfileVisitUseCase
 }
 
-func NewUserHandler(router fiber.Router, userUseCase domain.UserUseCase) *UserHandler {
+func NewUserHandler(router fiber.Router, userUseCase domain.UserUseCase, usernameUseCase domain.UsernameUseCase, interestUseCase domain.InterestUseCase, visitUseCase domain.ProfileVisitUseCase) *UserHandler {
 	handler := &UserHandler{
-		userUseCase: userUseCase,
+		userUseCase:     userUseCase,
+		usernameUseCase: usernameUseCase,
+		interestUseCase: interestUseCase,
+		visitUseCase:    visitUseCase,
 	}
 
 	router.Patch("/", handler.UpdateUser)
-	router.Delete("/", handler.DeleteAccount)
 	router.Post("/", handler.CreateOrUpdateUser)
+	router.Post("/batch", handler.GetUsersBatch)
 	router.Get("/me", handler.GetProfile)
 	router.Get("/check-username", handler.CheckUsername)
+	router.Get("/username-suggestions", handler.GetUsernameSuggestions)
+	router.Post("/username/claim", handler.ClaimPremiumUsername)
 	router.Get("/list", handler.GetUserList)
 	router.Get("/:username", handler.GetUserByUsername)
 
@@ -47,12 +56,10 @@ func (h *UserHandler) CreateOrUpdateUser(c *fiber.Ctx) error {
 		PhotoURL  string `json:"photoUrl"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	// Email validation
@@ -81,9 +88,7 @@ func (h *UserHandler) CreateOrUpdateUser(c *fiber.Ctx) error {
 	)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(user, nil)
@@ -107,9 +112,7 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	user, err := h.userUseCase.GetUserByID(userID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(user, nil)
@@ -152,23 +155,22 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		IsVerified     *bool                `json:"isVerified"`
 		IsActive       *bool                `json:"isActive"`
 		Live           *domain.Live         `json:"live"`
+		HideReadReceipts *bool              `json:"hideReadReceipts"`
+		LastSeenVisibility *string          `json:"lastSeenVisibility"`
+		CoarseLastSeen *bool                `json:"coarseLastSeen"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	if err := utils.ParseBody(c, &req); err != nil {
 		logger.LogInput(req)
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogInput(userID, req)
 	user, err := h.userUseCase.GetUserByID(userID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	// Username validation if provided
@@ -194,13 +196,25 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 				"error": err.Error(),
 			})
 		}
+		// Reserved and profane usernames can't be claimed
+		if *req.Username != user.Username {
+			err := h.usernameUseCase.ValidateUsername(*req.Username)
+			if errors.Is(err, domain.ErrUsernameNotAllowed) || errors.Is(err, domain.ErrUsernameTaken) {
+				logger.LogOutput(nil, err)
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			if err != nil {
+				logger.LogOutput(nil, err)
+				return utils.HandleError(c, err)
+			}
+		}
 		// Check if username is already taken by another user
 		existingUser, err := h.userUseCase.GetUserByUsername(*req.Username)
 		if err != nil {
 			logger.LogOutput(nil, err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return utils.HandleError(c, err)
 		}
 		if existingUser != nil && existingUser.ID != user.ID {
 			err := fiber.NewError(fiber.StatusBadRequest, "username is already taken")
@@ -253,7 +267,14 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		user.Height = *req.Height
 	}
 	if req.Interests != nil {
-		user.Interests = req.Interests
+		// Interests outside the taxonomy are kept as free text until they are migrated
+		interests, legacy, err := h.interestUseCase.ResolveInterests(req.Interests)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return utils.HandleError(c, err)
+		}
+		user.Interests = interests
+		user.LegacyInterests = legacy
 	}
 	if req.Occupation != nil {
 		user.Occupation = *req.Occupation
@@ -276,17 +297,33 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	if req.Live != nil {
 		user.Live = *req.Live
 	}
+	if req.HideReadReceipts != nil {
+		user.HideReadReceipts = *req.HideReadReceipts
+	}
+	if req.LastSeenVisibility != nil {
+		switch *req.LastSeenVisibility {
+		case domain.LastSeenVisibilityEveryone, domain.LastSeenVisibilityFriends, domain.LastSeenVisibilityNobody:
+			user.LastSeenVisibility = *req.LastSeenVisibility
+		default:
+			err := fiber.NewError(fiber.StatusBadRequest, "lastSeenVisibility must be everyone, friends or nobody")
+			logger.LogOutput(nil, err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	if req.CoarseLastSeen != nil {
+		user.CoarseLastSeen = *req.CoarseLastSeen
+	}
 
 	// Update timestamp and version
 	user.UpdatedAt = time.Now()
 	user.Version++
 
-	err = h.userUseCase.UpdateUser(user)
+	err = h.userUseCase.UpdateUser(userID, user)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(user, nil)
@@ -295,9 +332,17 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	})
 }
 
+// GetUserByUsername returns the profile as far as the viewer's relationship
+// with its owner allows, see domain.ProfileView
 func (h *UserHandler) GetUserByUsername(c *fiber.Ctx) error {
 	logger := utils.NewLogger("UserHandler.GetUserByUsername")
 
+	viewerID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
 	username := c.Params("username")
 	if username == "" {
 		err := fiber.NewError(fiber.StatusBadRequest, "username is required")
@@ -308,26 +353,24 @@ func (h *UserHandler) GetUserByUsername(c *fiber.Ctx) error {
 		})
 	}
 
-	logger.LogInput(username)
-	user, err := h.userUseCase.GetUserByUsername(username)
+	logger.LogInput(viewerID, username)
+	profile, err := h.userUseCase.GetProfileByUsername(viewerID, username)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
-	if user == nil {
-		err := fiber.NewError(fiber.StatusNotFound, "user not found")
-		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	// Count the visit for the owner's insights, ref tells where it came from.
+	// Blocked viewers aren't shown to the owner.
+	if profile.Relationship != domain.ProfileRelationBlocked {
+		if err := h.visitUseCase.RecordVisit(profile.ID, viewerID, c.Query("ref")); err != nil {
+			logger.LogOutput(nil, err)
+		}
 	}
 
-	logger.LogOutput(user, nil)
+	logger.LogOutput(profile.Relationship, nil)
 	return c.JSON(fiber.Map{
-		"user": user,
+		"user": profile,
 	})
 }
 
@@ -350,9 +393,7 @@ func (h *UserHandler) GetUserList(c *fiber.Ctx) error {
 	response, err := h.userUseCase.GetUserList(req)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
 	}
 
 	logger.LogOutput(response, nil)
@@ -393,22 +434,52 @@ func (h *UserHandler) CheckUsername(c *fiber.Ctx) error {
 	}
 
 	logger.LogInput(username)
-	user, err := h.userUseCase.GetUserByUsername(username)
+	err := h.usernameUseCase.ValidateUsername(username)
+	if errors.Is(err, domain.ErrUsernameNotAllowed) || errors.Is(err, domain.ErrUsernameTaken) {
+		logger.LogOutput(map[string]bool{"available": false}, nil)
+		return c.JSON(fiber.Map{
+			"available": false,
+			"reason":    err.Error(),
+		})
+	}
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(map[string]bool{"available": true}, nil)
+	return c.JSON(fiber.Map{
+		"available": true,
+	})
+}
+
+func (h *UserHandler) GetUsernameSuggestions(c *fiber.Ctx) error {
+	logger := utils.NewLogger("UserHandler.GetUsernameSuggestions")
+
+	base := c.Query("base")
+	limit := utils.GetQueryInt(c, "limit", 5)
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+
+	logger.LogInput(map[string]interface{}{
+		"base":  base,
+		"limit": limit,
+	})
+	suggestions, err := h.usernameUseCase.SuggestUsernames(base, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
 	}
 
-	logger.LogOutput(map[string]bool{"available": user == nil}, nil)
+	logger.LogOutput(suggestions, nil)
 	return c.JSON(fiber.Map{
-		"available": user == nil,
+		"suggestions": suggestions,
 	})
 }
 
-func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
-	logger := utils.NewLogger("UserHandler.DeleteAccount")
+func (h *UserHandler) ClaimPremiumUsername(c *fiber.Ctx) error {
+	logger := utils.NewLogger("UserHandler.ClaimPremiumUsername")
 
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
@@ -417,19 +488,49 @@ func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
 			"error": "Unauthorized",
 		})
 	}
-	authClient := c.Locals("firebase_auth")
-	logger.LogInput(userID)
 
-	err = h.userUseCase.DeleteAccount(userID.Hex(), authClient)
+	var req struct {
+		Username string `json:"username" validate:"required"`
+	}
+	if err := utils.ParseBody(c, &req); err != nil {
+		logger.LogInput(req)
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(userID, req)
+	user, err := h.usernameUseCase.ClaimPremiumUsername(userID, req.Username)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		status, _ := utils.ErrorStatus(err)
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	logger.LogOutput("success", nil)
+	logger.LogOutput(user, nil)
+	return c.JSON(user)
+}
+
+// GetUsersBatch returns users for up to domain.MaxBatchSize IDs with per-item errors
+func (h *UserHandler) GetUsersBatch(c *fiber.Ctx) error {
+	logger := utils.NewLogger("UserHandler.GetUsersBatch")
+
+	ids, err := parseBatchRequest(c)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogInput(ids)
+	items, err := h.userUseCase.GetUsersByIDs(ids)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return utils.HandleError(c, err)
+	}
+
+	logger.LogOutput(items, nil)
 	return c.JSON(fiber.Map{
-		"message": "Account deleted successfully",
+		"items": items,
 	})
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// AdminMiddleware must run after AuthMiddleware and only lets admins through
+func AdminMiddleware(userRepo domain.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		logger := utils.NewLogger("AdminMiddleware")
+
+		userID, ok := c.Locals("userId").(string)
+		if !ok || userID == "" {
+			logger.LogOutput(nil, fmt.Errorf("missing user in context"))
+			return utils.SendError(c, fiber.StatusUnauthorized, "unauthorized")
+		}
+		logger.LogInput(userID)
+
+		user, err := userRepo.FindByID(userID)
+		if err != nil || user == nil || !user.IsAdmin() {
+			logger.LogOutput(nil, fmt.Errorf("user %s is not an admin", userID))
+			return utils.SendError(c, fiber.StatusForbidden, "forbidden")
+		}
+
+		logger.LogOutput(userID, nil)
+		return c.Next()
+	}
+}
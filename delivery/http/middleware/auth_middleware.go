@@ -6,10 +6,15 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
 )
 
-func AuthMiddleware(jwtSecret string) fiber.Handler {
+// AuthMiddleware authenticates the bearer access token, checking its issuer and
+// audience as the policy sets, and turns banned users away. The token's claims
+// are kept in the "claims" local for handlers that need the roles or scopes.
+func AuthMiddleware(jwtKeys *domain.JWTKeySet, tokenPolicy domain.TokenPolicy, suspensions domain.SuspensionUseCase) fiber.Handler {
+	parserOptions := tokenPolicy.ParserOptions()
 	return func(c *fiber.Ctx) error {
 		logger := utils.NewLogger("AuthMiddleware")
 		logger.LogInput(c)
@@ -17,42 +22,45 @@ func AuthMiddleware(jwtSecret string) fiber.Handler {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			logger.LogOutput(nil, fmt.Errorf("missing authorization header"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "unauthorized",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "unauthorized")
 		}
 
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		logger.LogInfo(tokenString)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			logger.LogOutput(nil, fmt.Errorf("parsing token"))
-			return []byte(jwtSecret), nil
-		})
+		claims := &domain.Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, jwtKeys.Keyfunc, parserOptions...)
 
 		if err != nil || !token.Valid {
 			logger.LogOutput(nil, fmt.Errorf("invalid token"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid token",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
 		logger.LogInput(map[string]interface{}{
-			"claims":      claims,
-			"userIdValue": claims["userId"],
-			"userIdType":  fmt.Sprintf("%T", claims["userId"]),
+			"claims": claims,
 		}, nil)
-		// Convert userId to string before setting in context
-		userID, ok := claims["userId"].(string)
-		if !ok {
-			logger.LogOutput(nil, fmt.Errorf("userId is not a string"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token format",
+		// Service tokens and other tokens signed with the same keys carry no user.
+		// Access tokens issued before the type claim was added carry none.
+		userID := claims.UserID
+		if userID == "" || (claims.Type != domain.TokenTypeAccess && claims.Type != "") {
+			logger.LogOutput(nil, fmt.Errorf("not a user access token"))
+			return utils.SendError(c, fiber.StatusUnauthorized, "Invalid token format")
+		}
+
+		// A failed lookup lets the request through rather than locking everyone out
+		suspension, err := suspensions.GetActiveSuspension(userID)
+		if err != nil {
+			logger.LogInfo(map[string]interface{}{
+				"message": "suspension check failed",
+				"error":   err.Error(),
 			})
 		}
+		if suspension != nil && suspension.Type == domain.SuspensionBan {
+			logger.LogOutput(nil, domain.ErrAccountBanned)
+			return utils.SendSuspensionError(c, suspension)
+		}
 
 		// Set userId as string in context
 		c.Locals("userId", userID)
+		c.Locals("claims", claims)
 		logger.LogOutput(userID, nil)
 		return c.Next()
 	}
@@ -17,27 +17,21 @@ func FirebaseAuthMiddleware(auth *auth.Client) fiber.Handler {
 		if authHeader == "" {
 			logger.LogInput(authHeader)
 			logger.LogOutput(nil, fmt.Errorf("missing authorization header"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "missing authorization header",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "missing authorization header")
 		}
 
 		idToken := strings.TrimPrefix(authHeader, "Bearer ")
 		if idToken == authHeader {
 			logger.LogInput(idToken)
 			logger.LogOutput(nil, fmt.Errorf("invalid token format"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid token format",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token format")
 		}
 
 		token, err := auth.VerifyIDToken(context.Background(), idToken)
 		if err != nil {
 			logger.LogInput(idToken)
 			logger.LogOutput(nil, err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid token",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
 		}
 
 		// Store Firebase UID and email in context
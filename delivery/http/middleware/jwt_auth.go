@@ -7,56 +7,41 @@ import (
 	"firebase.google.com/go/v4/auth"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func JWTAuthMiddleware(jwtSecret string, authClient *auth.Client) fiber.Handler {
+func JWTAuthMiddleware(jwtKeys *domain.JWTKeySet, authClient *auth.Client) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		logger := utils.NewLogger("JWTAuthMiddleware")
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			logger.LogInput(authHeader)
 			logger.LogOutput(nil, fmt.Errorf("missing authorization header"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "missing authorization header",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "missing authorization header")
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
 			logger.LogInput(tokenString)
 			logger.LogOutput(nil, fmt.Errorf("invalid token format"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid token format",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token format")
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				logger.LogInput(tokenString)
-				logger.LogOutput(nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"]))
-				return nil, fiber.ErrUnauthorized
-			}
-			logger.LogInput(tokenString)
-			return []byte(jwtSecret), nil
-		})
+		token, err := jwt.Parse(tokenString, jwtKeys.Keyfunc)
 
 		if err != nil {
 			logger.LogInput(tokenString)
 			logger.LogOutput(nil, err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid token",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok || !token.Valid {
 			logger.LogInput(tokenString)
 			logger.LogOutput(nil, fmt.Errorf("invalid token claims"))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid token claims",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token claims")
 		}
 
 		// Store user ID in context
@@ -73,23 +58,17 @@ func JWTAuthMiddleware(jwtSecret string, authClient *auth.Client) fiber.Handler
 				userIDStr = str
 			} else {
 				logger.LogOutput(nil, fmt.Errorf("userId is not a valid string: %T", v))
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "invalid user ID format in token",
-				})
+				return utils.SendError(c, fiber.StatusUnauthorized, "invalid user ID format in token")
 			}
 		default:
 			logger.LogOutput(nil, fmt.Errorf("userId is not a valid format: %T", v))
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid user ID format in token",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid user ID format in token")
 		}
 
 		userID, err := primitive.ObjectIDFromHex(userIDStr)
 		if err != nil {
 			logger.LogOutput(nil, err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid user ID format",
-			})
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid user ID format")
 		}
 
 		c.Locals("userId", userID)
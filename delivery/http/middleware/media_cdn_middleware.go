@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// MediaCDNMiddleware hands clients CDN URLs for stored files in JSON responses
+// and turns the CDN URLs clients send back in JSON bodies into the storage URLs
+// the server keeps, so nothing but the responses knows about the CDN
+func MediaCDNMiddleware(rewriter *utils.MediaURLRewriter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+			c.Request().SetBody(rewriter.RestoreJSON(c.Body()))
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		contentType := c.Response().Header.ContentType()
+		body := c.Response().Body()
+		if bytes.HasPrefix(contentType, []byte(fiber.MIMEApplicationJSON)) && bytes.Contains(body, []byte("firebasestorage.googleapis.com")) {
+			c.Response().SetBody(rewriter.RewriteJSON(body))
+		}
+		return nil
+	}
+}
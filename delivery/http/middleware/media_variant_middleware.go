@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// Request headers clients hint the image width they need with
+const (
+	saveDataHeader      = "Save-Data"
+	ectHeader           = "ECT"
+	networkTypeHeader   = "X-Network-Type"
+	mediaMaxWidthHeader = "X-Media-Max-Width"
+)
+
+// networkMaxWidths is the widest image worth fetching over each network, by the
+// ECT client hint or the network type the app reports
+var networkMaxWidths = map[string]int{
+	"slow-2g":  320,
+	"2g":       320,
+	"3g":       640,
+	"cellular": 1080,
+}
+
+// MediaVariantMiddleware hands clients that hint at a slow or metered network,
+// or at the widest image they need, the narrowest variant at least that wide of
+// each stored image in JSON responses. Variant URLs clients send back in JSON
+// bodies become the URLs of the original images again, so posts and stories
+// keep pointing at the originals.
+func MediaVariantMiddleware(mediaBlobRepo domain.MediaBlobRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+			c.Request().SetBody(utils.RestoreMediaVariantsJSON(c.Body()))
+		}
+
+		maxWidth := hintedMediaWidth(c)
+		if err := c.Next(); err != nil {
+			return err
+		}
+		c.Vary(saveDataHeader, ectHeader, networkTypeHeader, mediaMaxWidthHeader)
+		if maxWidth == 0 || c.Method() != fiber.MethodGet {
+			return nil
+		}
+
+		contentType := c.Response().Header.ContentType()
+		body := c.Response().Body()
+		if !bytes.HasPrefix(contentType, []byte(fiber.MIMEApplicationJSON)) || !bytes.Contains(body, []byte("firebasestorage.googleapis.com")) {
+			return nil
+		}
+
+		// The images are served at full size when the variants can't be looked up
+		urls := utils.StorageURLs(body)
+		if len(urls) == 0 {
+			return nil
+		}
+		blobs, err := mediaBlobRepo.FindByURLs(urls)
+		if err != nil {
+			return nil
+		}
+
+		replacements := make(map[string]string)
+		for _, blob := range blobs {
+			for _, width := range blob.VariantWidths {
+				if width >= maxWidth {
+					replacements[blob.FileURL] = utils.MediaVariantURL(blob.FileURL, blob.FileName, width)
+					break
+				}
+			}
+		}
+		if len(replacements) > 0 {
+			c.Response().SetBody(utils.ReplaceStorageURLsJSON(body, replacements))
+		}
+		return nil
+	}
+}
+
+// hintedMediaWidth returns the widest image the request hints it needs, 0 when
+// it doesn't hint
+func hintedMediaWidth(c *fiber.Ctx) int {
+	maxWidth := 0
+	narrow := func(width int) {
+		if width > 0 && (maxWidth == 0 || width < maxWidth) {
+			maxWidth = width
+		}
+	}
+
+	if width, err := strconv.Atoi(c.Get(mediaMaxWidthHeader)); err == nil {
+		narrow(width)
+	}
+	if strings.EqualFold(c.Get(saveDataHeader), "on") {
+		narrow(domain.MediaVariantWidths[0])
+	}
+	narrow(networkMaxWidths[strings.ToLower(c.Get(ectHeader))])
+	narrow(networkMaxWidths[strings.ToLower(c.Get(networkTypeHeader))])
+	return maxWidth
+}
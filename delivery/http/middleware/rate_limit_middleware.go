@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// RateLimitMiddleware counts requests in the bucket per user when it runs after
+// AuthMiddleware, and per IP otherwise. Requests over the limit get 429 with a
+// Retry-After. When Redis is unavailable requests are let through rather than
+// turning everyone away.
+func RateLimitMiddleware(rateLimitRepo domain.RateLimitRepository, bucket string, limit domain.RateLimit) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if limit.Requests <= 0 || limit.Window <= 0 {
+			return c.Next()
+		}
+
+		key := fmt.Sprintf("%s:ip:%s", bucket, c.IP())
+		if userID, ok := c.Locals("userId").(string); ok && userID != "" {
+			key = fmt.Sprintf("%s:user:%s", bucket, userID)
+		}
+
+		result, err := rateLimitRepo.Allow(key, limit)
+		if err != nil {
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return utils.HandleError(c, domain.ErrRateLimited)
+		}
+		return c.Next()
+	}
+}
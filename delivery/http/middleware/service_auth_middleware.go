@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// ServiceAuthMiddleware only accepts service account tokens that carry the required scope
+func ServiceAuthMiddleware(serviceAccountUseCase domain.ServiceAccountUseCase, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		logger := utils.NewLogger("ServiceAuthMiddleware")
+		logger.LogInput(map[string]interface{}{
+			"path":  c.Path(),
+			"scope": scope,
+		})
+
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			logger.LogOutput(nil, fmt.Errorf("missing authorization header"))
+			return utils.SendError(c, fiber.StatusUnauthorized, "unauthorized")
+		}
+
+		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
+		claims, err := serviceAccountUseCase.VerifyToken(tokenString)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
+		}
+
+		if !claims.HasScope(scope) {
+			logger.LogOutput(nil, fmt.Errorf("missing scope %s", scope))
+			return utils.SendError(c, fiber.StatusForbidden, "insufficient scope")
+		}
+
+		c.Locals("serviceClientId", claims.ClientID)
+		logger.LogOutput(claims.ClientID, nil)
+		return c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// UsageMiddleware must run after AuthMiddleware and records every request of the
+// user for the usage statistics. Recording happens in the background.
+func UsageMiddleware(usageUseCase domain.APIUsageUseCase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startedAt := time.Now()
+		err := c.Next()
+
+		userID, ok := c.Locals("userId").(string)
+		if !ok || userID == "" {
+			return err
+		}
+
+		// Fiber reuses the context once the handler returns, so copy what's kept
+		request := domain.APIRequest{
+			Method:     strings.Clone(c.Method()),
+			Route:      strings.Clone(c.Route().Path),
+			Status:     utils.ResponseStatus(c, err),
+			DurationMs: time.Since(startedAt).Milliseconds(),
+			IP:         strings.Clone(c.IP()),
+			At:         startedAt,
+		}
+		go usageUseCase.RecordRequest(userID, request)
+
+		return err
+	}
+}
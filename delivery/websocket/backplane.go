@@ -0,0 +1,93 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// backplaneChannel is the Redis pub/sub channel shared by every API instance
+const backplaneChannel = "ws:backplane"
+
+// Backplane delivery targets
+const (
+	backplaneTargetRoom = "room"
+	backplaneTargetUser = "user"
+	backplaneTargetAll  = "all"
+	backplaneTargetOps  = "ops" // admin ops feed connections
+)
+
+// backplaneMessage carries an already encoded WebSocket message to the other instances
+type backplaneMessage struct {
+	Origin   string          `json:"origin"` // instance that published the message, skipped on receive
+	Target   string          `json:"target"` // room, user, all or ops
+	ID       string          `json:"id,omitempty"`
+	Category string          `json:"category,omitempty"` // event category connections filter by
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// publish forwards a message delivered locally to the other instances.
+// Without a Redis client the hub runs single-instance and this is a no-op.
+func (h *Hub) publish(target, id, category string, payload []byte) {
+	if h.rdb == nil {
+		return
+	}
+
+	logger := utils.NewLogger("Hub.publish")
+
+	msgBytes, err := json.Marshal(backplaneMessage{
+		Origin:   h.instanceID,
+		Target:   target,
+		ID:       id,
+		Category: category,
+		Payload:  payload,
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	if err := h.rdb.Publish(context.Background(), backplaneChannel, msgBytes).Err(); err != nil {
+		logger.LogOutput(nil, fmt.Errorf("error publishing to backplane: %v", err))
+	}
+}
+
+// runBackplane delivers messages published by other instances to local clients
+func (h *Hub) runBackplane() {
+	logger := utils.NewLogger("Hub.runBackplane")
+
+	pubsub := h.rdb.Subscribe(context.Background(), backplaneChannel)
+	defer pubsub.Close()
+
+	logger.LogInfo(map[string]interface{}{
+		"instanceID": h.instanceID,
+		"channel":    backplaneChannel,
+	})
+
+	for redisMsg := range pubsub.Channel() {
+		var msg backplaneMessage
+		if err := json.Unmarshal([]byte(redisMsg.Payload), &msg); err != nil {
+			logger.LogOutput(nil, fmt.Errorf("error unmarshaling backplane message: %v", err))
+			continue
+		}
+
+		if msg.Origin == h.instanceID {
+			continue
+		}
+
+		switch msg.Target {
+		case backplaneTargetRoom:
+			h.deliverToRoom(msg.ID, msg.Category, msg.Payload)
+		case backplaneTargetUser:
+			h.deliverToUser(msg.ID, msg.Category, msg.Payload)
+		case backplaneTargetAll:
+			h.Broadcast <- outboundMessage{category: msg.Category, payload: msg.Payload}
+		case backplaneTargetOps:
+			h.deliverToOps(msg.Payload)
+		default:
+			logger.LogOutput(nil, fmt.Errorf("unknown backplane target: %s", msg.Target))
+		}
+	}
+}
@@ -2,13 +2,16 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/gofiber/websocket/v2"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
 )
 
 // Message types
@@ -18,6 +21,16 @@ const (
 	MessageTypePing       = "ping"
 	MessageTypePong       = "pong"
 	MessageTypeUserStatus = "userStatus"
+	MessageTypeStoryWatch = "storyWatch" // content is the story ID, sent as a heartbeat while viewing
+	MessageTypeStoryLeave = "storyLeave" // content is the story ID
+	MessageTypeError      = "error"      // sent to the sender when a message is rejected, content is the error code
+	// Subscription control, content is a comma-separated list of event categories.
+	// The server answers both with the connection's subscriptions.
+	MessageTypeSubscribe     = "subscribe"
+	MessageTypeUnsubscribe   = "unsubscribe"
+	MessageTypeSubscriptions = "subscriptions"
+	// Sent by clients with the room and the last seq they handled, see replay.go
+	MessageTypeAck = "ack"
 )
 
 // WebSocketMessage represents the message structure for WebSocket communication
@@ -28,6 +41,12 @@ type WebSocketMessage struct {
 	Content   string      `json:"content"`             // message content or typing status (true/false)
 	Data      interface{} `json:"data,omitempty"`      // additional data if needed
 	CreatedAt string      `json:"createdAt,omitempty"` // set by server in RFC3339 format
+	// ReplyToMessageID quotes an earlier message of the room, the broadcast carries its preview in Data
+	ReplyToMessageID string `json:"replyToMessageId,omitempty"`
+	// Seq numbers the chat events of a room for replay, set by server
+	Seq int64 `json:"seq,omitempty"`
+	// MessageSeq is the room seq of a sent message, see domain.ChatMessage.Seq
+	MessageSeq int64 `json:"messageSeq,omitempty"`
 }
 
 // Client represents a WebSocket client connection
@@ -38,39 +57,84 @@ type Client struct {
 	Send    chan []byte
 	Hub     *Hub
 	RoomIDs map[string]bool
-	mu      sync.Mutex
+	// stories this client is currently watching, cleared on disconnect
+	WatchingStories map[string]bool
+	mu              sync.Mutex
+	// when the presence of the connection was last refreshed, to throttle heartbeats
+	presenceAt time.Time
+	// event categories the connection unsubscribed from
+	unsubscribed map[string]bool
+	// Encoding is how payloads are written and binary frames read, EncodingJSON when empty
+	Encoding string
+	// Compress deflates large frames when the connection negotiated permessage-deflate
+	Compress bool
+}
+
+// outboundMessage is an encoded message with the event category connections filter it by
+type outboundMessage struct {
+	category string
+	payload  []byte
 }
 
 type Hub struct {
-	Clients     map[*Client]bool
-	UserMap     map[string]*Client // maps userID to client
-	Broadcast   chan []byte
-	Register    chan *Client
-	Unregister  chan *Client
-	Mutex       sync.Mutex
-	ChatUsecase domain.ChatUsecase
+	Clients      map[*Client]bool
+	UserMap      map[string]map[*Client]bool // maps userID to the user's connections, one per device
+	Broadcast    chan outboundMessage
+	Register     chan *Client
+	Unregister   chan *Client
+	Mutex        sync.Mutex
+	ChatUsecase  domain.ChatUsecase
+	StoryUsecase domain.StoryUseCase
+
+	// admin connections to the ops feed, see ops_socket.go
+	opsClients map[*Client]bool
+
+	// Redis backplane so broadcasts reach clients connected to other instances
+	rdb        *redis.Client
+	instanceID string
+
+	// set by Shutdown, new connections are turned away
+	closing bool
 }
 
-func NewHub(chatUsecase domain.ChatUsecase) *Hub {
+// NewHub creates the hub. Use cases are attached by NewWebSocketHandler so the hub
+// can be handed to use cases as a domain.RealtimePublisher before they exist.
+// A nil rdb keeps every broadcast in this process.
+func NewHub(rdb *redis.Client) *Hub {
 	return &Hub{
-		Clients:     make(map[*Client]bool),
-		UserMap:     make(map[string]*Client),
-		Broadcast:   make(chan []byte),
-		Register:    make(chan *Client),
-		Unregister:  make(chan *Client),
-		ChatUsecase: chatUsecase,
+		Clients:    make(map[*Client]bool),
+		UserMap:    make(map[string]map[*Client]bool),
+		Broadcast:  make(chan outboundMessage),
+		Register:   make(chan *Client),
+		Unregister: make(chan *Client),
+		opsClients: make(map[*Client]bool),
+		rdb:        rdb,
+		instanceID: utils.GenerateID(),
 	}
 }
 
 func (h *Hub) Run() {
 	logger := utils.NewLogger("Hub.Run")
 
+	if h.rdb != nil {
+		go h.runBackplane()
+	}
+
 	for {
 		select {
 		case client := <-h.Register:
 			h.Mutex.Lock()
+			if h.closing {
+				h.Mutex.Unlock()
+				goAway(client)
+				close(client.Send)
+				continue
+			}
 			h.Clients[client] = true
-			h.UserMap[client.UserID] = client
+			if h.UserMap[client.UserID] == nil {
+				h.UserMap[client.UserID] = make(map[*Client]bool)
+			}
+			h.UserMap[client.UserID][client] = true
 			h.Mutex.Unlock()
 
 			logger.LogOutput(map[string]interface{}{
@@ -81,9 +145,7 @@ func (h *Hub) Run() {
 		case client := <-h.Unregister:
 			if _, ok := h.Clients[client]; ok {
 				h.Mutex.Lock()
-				delete(h.Clients, client)
-				delete(h.UserMap, client.UserID)
-				close(client.Send)
+				h.removeClient(client)
 				h.Mutex.Unlock()
 			}
 
@@ -94,22 +156,24 @@ func (h *Hub) Run() {
 
 		case message := <-h.Broadcast:
 			logger.LogInput(map[string]interface{}{
-				"messageSize": len(message),
+				"messageSize": len(message.payload),
+				"category":    message.category,
 				"action":      "broadcast",
 			})
 
 			for client := range h.Clients {
+				if !client.Subscribed(message.category) {
+					continue
+				}
 				select {
-				case client.Send <- message:
+				case client.Send <- message.payload:
 					logger.LogOutput(map[string]interface{}{
 						"clientID": client.ID,
 						"status":   "messageSent",
 					}, nil)
 				default:
 					h.Mutex.Lock()
-					delete(h.Clients, client)
-					delete(h.UserMap, client.UserID)
-					close(client.Send)
+					h.removeClient(client)
 					h.Mutex.Unlock()
 
 					logger.LogOutput(map[string]interface{}{
@@ -123,6 +187,18 @@ func (h *Hub) Run() {
 	}
 }
 
+// removeClient drops a connection and closes its send channel. The caller must hold h.Mutex.
+func (h *Hub) removeClient(client *Client) {
+	delete(h.Clients, client)
+	if connections, ok := h.UserMap[client.UserID]; ok {
+		delete(connections, client)
+		if len(connections) == 0 {
+			delete(h.UserMap, client.UserID)
+		}
+	}
+	close(client.Send)
+}
+
 func (h *Hub) BroadcastToRoom(roomID string, message interface{}) {
 	logger := utils.NewLogger("Hub.BroadcastToRoom")
 	logger.LogInput(map[string]interface{}{
@@ -130,6 +206,18 @@ func (h *Hub) BroadcastToRoom(roomID string, message interface{}) {
 		"message": message,
 	})
 
+	// Chat events are numbered so reconnecting clients can replay them, typing isn't worth it
+	category := ""
+	var seq int64
+	if msg, ok := message.(WebSocketMessage); ok {
+		category = eventCategory(msg.Type)
+		if category == CategoryChat {
+			seq = h.nextRoomSeq(roomID)
+			msg.Seq = seq
+			message = msg
+		}
+	}
+
 	// แปลง message เป็น JSON
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
@@ -137,12 +225,36 @@ func (h *Hub) BroadcastToRoom(roomID string, message interface{}) {
 		return
 	}
 
+	if seq > 0 {
+		h.retainRoomEvent(roomID, seq, messageBytes)
+	}
+
+	h.deliverToRoom(roomID, category, messageBytes)
+	h.publish(backplaneTargetRoom, roomID, category, messageBytes)
+}
+
+// PublishToRoom sends an event to every member of the room connected to any instance
+func (h *Hub) PublishToRoom(roomID string, eventType string, data interface{}) error {
+	h.BroadcastToRoom(roomID, WebSocketMessage{
+		Type:      eventType,
+		RoomID:    roomID,
+		Data:      data,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+	return nil
+}
+
+// deliverToRoom sends an encoded message to the room members connected to this
+// instance that subscribed to its category
+func (h *Hub) deliverToRoom(roomID, category string, messageBytes []byte) {
+	logger := utils.NewLogger("Hub.deliverToRoom")
+
 	h.Mutex.Lock()
 	defer h.Mutex.Unlock()
 
 	// ส่งข้อความไปยังทุก client ที่อยู่ในห้อง
 	for client := range h.Clients {
-		if client.RoomIDs[roomID] {
+		if client.RoomIDs[roomID] && client.Subscribed(category) {
 			select {
 			case client.Send <- messageBytes:
 				logger.LogOutput(map[string]interface{}{
@@ -151,9 +263,7 @@ func (h *Hub) BroadcastToRoom(roomID string, message interface{}) {
 				}, nil)
 			default:
 				// ถ้าส่งไม่ได้ ให้ลบ client ออก
-				delete(h.Clients, client)
-				delete(h.UserMap, client.UserID)
-				close(client.Send)
+				h.removeClient(client)
 			}
 		}
 	}
@@ -179,7 +289,62 @@ func (h *Hub) BroadcastUserStatus(userID string, status string) {
 		return
 	}
 
-	h.Broadcast <- msgBytes
+	h.BroadcastAll(CategoryPresence, msgBytes)
+}
+
+// BroadcastAll sends an encoded message to every client on every instance
+// subscribed to the category
+func (h *Hub) BroadcastAll(category string, msgBytes []byte) {
+	h.Broadcast <- outboundMessage{category: category, payload: msgBytes}
+	h.publish(backplaneTargetAll, "", category, msgBytes)
+}
+
+// SendToUser delivers an event to every connection of the user
+func (h *Hub) SendToUser(userID string, eventType string, data interface{}) error {
+	logger := utils.NewLogger("Hub.SendToUser")
+	logger.LogInput(map[string]interface{}{
+		"userID":    userID,
+		"eventType": eventType,
+	})
+
+	msg := WebSocketMessage{
+		Type:      eventType,
+		Data:      data,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	category := eventCategory(eventType)
+	h.deliverToUser(userID, category, msgBytes)
+	h.publish(backplaneTargetUser, userID, category, msgBytes)
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// deliverToUser sends an encoded message to the user's connections on this
+// instance that subscribed to its category
+func (h *Hub) deliverToUser(userID, category string, msgBytes []byte) {
+	logger := utils.NewLogger("Hub.deliverToUser")
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	for client := range h.UserMap[userID] {
+		if !client.Subscribed(category) {
+			continue
+		}
+		select {
+		case client.Send <- msgBytes:
+		default:
+			logger.LogOutput(nil, fmt.Errorf("send channel full for client %s", client.ID))
+		}
+	}
 }
 
 func (c *Client) JoinRoom(roomID string) {
@@ -200,6 +365,8 @@ func (c *Client) ReadPump() {
 			logger.LogOutput(nil, fmt.Errorf("panic recovered in ReadPump: %v", r))
 		}
 		logger.LogInfo("closing connection and unregistering client")
+		c.leaveWatchedStories()
+		c.disconnectPresence()
 		if c.Hub != nil {
 			c.Hub.Unregister <- c
 		}
@@ -220,14 +387,18 @@ func (c *Client) ReadPump() {
 		logger.LogOutput(nil, fmt.Errorf("error getting user rooms: %v", err))
 	} else {
 		for _, room := range rooms {
-			c.JoinRoom(room.ID.String())
+			c.JoinRoom(room.ID.Hex())
 		}
 	}
+	c.replay()
 
-	// Set read deadline and pong handler
+	c.touchPresence()
+
+	// Set read deadline and pong handler, pongs to the server's pings keep the user online
 	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.touchPresence()
 		return nil
 	})
 
@@ -251,9 +422,10 @@ func (c *Client) ReadPump() {
 			continue
 		}
 
-		// Handle text message
-		if messageType != websocket.TextMessage {
-			logger.LogOutput(nil, fmt.Errorf("unexpected message type: %v", messageType))
+		// Text frames are JSON, binary frames use the connection's encoding
+		message, err = decodeFrame(c.Encoding, messageType, message)
+		if err != nil {
+			logger.LogOutput(nil, err)
 			continue
 		}
 
@@ -271,6 +443,7 @@ func (c *Client) ReadPump() {
 
 		// Handle ping type message from client
 		if msg.Type == MessageTypePing {
+			c.touchPresence()
 			pongMsg := WebSocketMessage{
 				Type:      MessageTypePong,
 				CreatedAt: time.Now().Format(time.RFC3339),
@@ -305,9 +478,29 @@ func (c *Client) ReadPump() {
 				msg.SenderID,
 				"text",
 				msg.Content,
+				msg.ReplyToMessageID,
 			)
 			if err != nil {
 				logger.LogOutput(nil, fmt.Errorf("error sending message: %v", err))
+				var limitErr *domain.ChatLimitError
+				if errors.As(err, &limitErr) {
+					errMsg := WebSocketMessage{
+						Type:    MessageTypeError,
+						RoomID:  msg.RoomID,
+						Content: limitErr.Code,
+						Data: map[string]interface{}{
+							"limit":      limitErr.Limit,
+							"retryAfter": int(math.Ceil(limitErr.RetryAfter.Seconds())),
+						},
+						CreatedAt: time.Now().Format(time.RFC3339),
+					}
+					if errBytes, err := json.Marshal(errMsg); err == nil {
+						select {
+						case c.Send <- errBytes:
+						default:
+						}
+					}
+				}
 				continue
 			}
 
@@ -316,11 +509,16 @@ func (c *Client) ReadPump() {
 
 			// แปลง chatMsg เป็น Message สำหรับ broadcast
 			broadcastMsg := WebSocketMessage{
-				Type:      MessageTypeMessage,
-				RoomID:    chatMsg.RoomID,
-				SenderID:  chatMsg.SenderID,
-				Content:   chatMsg.Content,
-				CreatedAt: chatMsg.CreatedAt.Format(time.RFC3339),
+				Type:       MessageTypeMessage,
+				RoomID:     chatMsg.RoomID,
+				SenderID:   chatMsg.SenderID,
+				Content:    chatMsg.Content,
+				CreatedAt:  chatMsg.CreatedAt.Format(time.RFC3339),
+				MessageSeq: chatMsg.Seq,
+			}
+			if chatMsg.ReplyTo != nil {
+				broadcastMsg.ReplyToMessageID = chatMsg.ReplyToMessageID
+				broadcastMsg.Data = chatMsg.ReplyTo
 			}
 
 			// Safely broadcast message
@@ -341,6 +539,12 @@ func (c *Client) ReadPump() {
 				continue
 			}
 
+			// Stored so clients joining the room later can fetch who is typing
+			if err := c.Hub.ChatUsecase.SetTyping(msg.RoomID, c.UserID, msg.Content == "true"); err != nil {
+				logger.LogOutput(nil, fmt.Errorf("error setting typing status: %v", err))
+				continue
+			}
+
 			typingMsg := WebSocketMessage{
 				Type:      MessageTypeTyping,
 				RoomID:    msg.RoomID,
@@ -381,16 +585,143 @@ func (c *Client) ReadPump() {
 						logger.LogOutput(nil, fmt.Errorf("error marshaling status message: %v", err))
 						return
 					}
-					c.Hub.Broadcast <- statusBytes
+					c.Hub.BroadcastAll(CategoryPresence, statusBytes)
 				}
 			}()
 
+		case MessageTypeStoryWatch:
+			if msg.Content == "" || c.Hub.StoryUsecase == nil {
+				logger.LogOutput(nil, fmt.Errorf("story ID is required for storyWatch"))
+				continue
+			}
+
+			if err := c.Hub.StoryUsecase.WatchStory(msg.Content, c.UserID); err != nil {
+				logger.LogOutput(nil, fmt.Errorf("error watching story: %v", err))
+				continue
+			}
+			c.mu.Lock()
+			if c.WatchingStories == nil {
+				c.WatchingStories = make(map[string]bool)
+			}
+			c.WatchingStories[msg.Content] = true
+			c.mu.Unlock()
+
+		case MessageTypeStoryLeave:
+			if msg.Content == "" || c.Hub.StoryUsecase == nil {
+				logger.LogOutput(nil, fmt.Errorf("story ID is required for storyLeave"))
+				continue
+			}
+
+			c.mu.Lock()
+			delete(c.WatchingStories, msg.Content)
+			c.mu.Unlock()
+			if err := c.Hub.StoryUsecase.LeaveStory(msg.Content, c.UserID); err != nil {
+				logger.LogOutput(nil, fmt.Errorf("error leaving story: %v", err))
+			}
+
+		case MessageTypeAck:
+			if msg.RoomID == "" || msg.Seq <= 0 {
+				logger.LogOutput(nil, fmt.Errorf("roomID and seq are required for ack"))
+				continue
+			}
+			if err := c.Hub.ack(c.UserID, msg.RoomID, msg.Seq); err != nil {
+				logger.LogOutput(nil, fmt.Errorf("error saving ack: %v", err))
+			}
+
+		case MessageTypeSubscribe, MessageTypeUnsubscribe:
+			categories, err := parseCategories(msg.Content)
+			if err != nil {
+				logger.LogOutput(nil, err)
+				c.sendError("invalid_subscription", err.Error())
+				continue
+			}
+			c.SetSubscribed(categories, msg.Type == MessageTypeSubscribe)
+			c.sendSubscriptions()
+
 		default:
 			logger.LogOutput(nil, fmt.Errorf("unknown message type: %s", msg.Type))
 		}
 	}
 }
 
+// sendSubscriptions tells the connection which event categories it gets
+func (c *Client) sendSubscriptions() {
+	c.sendDirect(WebSocketMessage{
+		Type:      MessageTypeSubscriptions,
+		Data:      c.Subscriptions(),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+}
+
+// sendError tells the connection a request was rejected
+func (c *Client) sendError(code, detail string) {
+	c.sendDirect(WebSocketMessage{
+		Type:      MessageTypeError,
+		Content:   code,
+		Data:      map[string]interface{}{"error": detail},
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+}
+
+// sendDirect queues a message for this connection only, dropping it when the queue is full
+func (c *Client) sendDirect(msg WebSocketMessage) {
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		utils.NewLogger("Client.sendDirect").LogOutput(nil, err)
+		return
+	}
+	select {
+	case c.Send <- msgBytes:
+	default:
+	}
+}
+
+// touchPresence refreshes the user's presence, at most a few times per presence window
+func (c *Client) touchPresence() {
+	if c.Hub == nil || c.Hub.ChatUsecase == nil {
+		return
+	}
+	if time.Since(c.presenceAt) < domain.ChatPresenceTTL/4 {
+		return
+	}
+	c.presenceAt = time.Now()
+
+	if err := c.Hub.ChatUsecase.TouchPresence(c.UserID, c.ID); err != nil {
+		// Retry on the next heartbeat
+		c.presenceAt = time.Time{}
+		utils.NewLogger("Client.touchPresence").LogOutput(nil, err)
+	}
+}
+
+// disconnectPresence takes the connection out of the user's presence
+func (c *Client) disconnectPresence() {
+	if c.Hub == nil || c.Hub.ChatUsecase == nil {
+		return
+	}
+	if err := c.Hub.ChatUsecase.DisconnectPresence(c.UserID, c.ID); err != nil {
+		utils.NewLogger("Client.disconnectPresence").LogOutput(nil, err)
+	}
+}
+
+// leaveWatchedStories removes the client from every story it was watching
+func (c *Client) leaveWatchedStories() {
+	if c.Hub == nil || c.Hub.StoryUsecase == nil {
+		return
+	}
+
+	c.mu.Lock()
+	storyIDs := make([]string, 0, len(c.WatchingStories))
+	for storyID := range c.WatchingStories {
+		storyIDs = append(storyIDs, storyID)
+	}
+	c.WatchingStories = nil
+	c.mu.Unlock()
+
+	for _, storyID := range storyIDs {
+		c.Hub.StoryUsecase.LeaveStory(storyID, c.UserID)
+	}
+}
+
 func (c *Client) WritePump() {
 	logger := utils.NewLogger("Client.WritePump")
 	ticker := time.NewTicker(30 * time.Second)
@@ -425,8 +756,14 @@ func (c *Client) WritePump() {
 			}
 
 			if c.Conn != nil {
+				frameType, frame, err := encodeFrame(c.Encoding, message)
+				if err != nil {
+					logger.LogOutput(nil, fmt.Errorf("error encoding message: %v", err))
+					continue
+				}
+				c.Conn.EnableWriteCompression(c.Compress && len(frame) >= compressionThreshold)
 				c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-				if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				if err := c.Conn.WriteMessage(frameType, frame); err != nil {
 					logger.LogOutput(nil, fmt.Errorf("error writing message: %v", err))
 					return
 				}
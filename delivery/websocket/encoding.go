@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/websocket/v2"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Payload encodings a connection can pick with the encoding query parameter.
+// Messages are built as JSON and converted per connection, so both carry the same fields.
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack" // MessagePack in binary frames
+)
+
+// Frames smaller than this aren't worth deflating, the compressor state costs more than it saves
+const compressionThreshold = 512
+
+// parseEncoding validates the encoding a connection asked for, JSON when empty
+func parseEncoding(value string) (string, error) {
+	switch encoding := strings.ToLower(strings.TrimSpace(value)); encoding {
+	case "", EncodingJSON:
+		return EncodingJSON, nil
+	case EncodingMsgpack:
+		return EncodingMsgpack, nil
+	default:
+		return "", fmt.Errorf("unknown encoding %q", value)
+	}
+}
+
+// encodeFrame converts a JSON message to the connection's encoding and returns
+// the frame type to send it in
+func encodeFrame(encoding string, message []byte) (int, []byte, error) {
+	if encoding != EncodingMsgpack {
+		return websocket.TextMessage, message, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return 0, nil, err
+	}
+
+	frame, err := msgp.AppendIntf(make([]byte, 0, len(message)), msgpackValue(value))
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, frame, nil
+}
+
+// decodeFrame returns a received frame as JSON. Text frames are always JSON,
+// binary frames are accepted from MessagePack connections only.
+func decodeFrame(encoding string, messageType int, data []byte) ([]byte, error) {
+	switch messageType {
+	case websocket.TextMessage:
+		return data, nil
+	case websocket.BinaryMessage:
+		if encoding != EncodingMsgpack {
+			return nil, fmt.Errorf("binary frames need the %s encoding", EncodingMsgpack)
+		}
+		var out bytes.Buffer
+		if _, err := msgp.UnmarshalAsJSON(&out, data); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unexpected message type: %v", messageType)
+	}
+}
+
+// msgpackValue turns JSON numbers into integers where they are whole, so counts
+// and timestamps don't become floats
+func msgpackValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = msgpackValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = msgpackValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,182 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// MessageTypeOps frames carry a domain.OpsEvent in Data, only ops connections get them
+const MessageTypeOps = "ops"
+
+// OpsSocketHandler serves the admin-only ops feed. Ops connections are kept apart
+// from chat clients: they get ops events and nothing else.
+type OpsSocketHandler struct {
+	hub         *Hub
+	authUseCase domain.AuthUseCase
+	userRepo    domain.UserRepository
+}
+
+func NewOpsSocketHandler(router fiber.Router, hub *Hub, authUseCase domain.AuthUseCase, userRepo domain.UserRepository) {
+	handler := &OpsSocketHandler{
+		hub:         hub,
+		authUseCase: authUseCase,
+		userRepo:    userRepo,
+	}
+
+	router.Get("/ws/ops", websocket.New(handler.handleOpsSocket, websocket.Config{
+		HandshakeTimeout: 10 * time.Second,
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+	}))
+}
+
+func (h *OpsSocketHandler) handleOpsSocket(ws *websocket.Conn) {
+	logger := utils.NewLogger("OpsSocketHandler.handleOpsSocket")
+
+	userID, code, err := h.authorize(ws.Query("ticket"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		ws.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(code, err.Error()),
+			time.Now().Add(time.Second),
+		)
+		ws.Close()
+		return
+	}
+
+	client := &Client{
+		ID:     utils.GenerateID(),
+		UserID: userID,
+		Conn:   ws,
+		Send:   make(chan []byte, 256),
+		Hub:    h.hub,
+	}
+	h.hub.registerOps(client)
+
+	logger.LogInfo(map[string]interface{}{
+		"userID": userID,
+		"status": "ops_client_registered",
+	})
+
+	go client.WritePump()
+	client.readOps() // This blocks until connection is closed
+}
+
+// authorize redeems the ops ticket and returns the admin it was issued to, or
+// the close code to reject the connection with
+func (h *OpsSocketHandler) authorize(ticket string) (string, int, error) {
+	if ticket == "" {
+		return "", websocket.CloseInvalidFramePayloadData, fmt.Errorf("Missing ticket")
+	}
+
+	userID, err := h.authUseCase.RedeemWSTicket(context.Background(), ticket, domain.WSTicketOps)
+	if err != nil {
+		return "", websocket.ClosePolicyViolation, fmt.Errorf("Invalid or expired ticket")
+	}
+
+	user, err := h.userRepo.FindByID(userID)
+	if err != nil {
+		return "", websocket.CloseInternalServerErr, fmt.Errorf("Internal server error")
+	}
+	if user == nil || !user.IsAdmin() {
+		return "", websocket.ClosePolicyViolation, fmt.Errorf("Admin access required")
+	}
+
+	return userID, 0, nil
+}
+
+// readOps keeps an ops connection alive until it closes. Ops clients only listen,
+// anything they send is dropped.
+func (c *Client) readOps() {
+	defer func() {
+		c.Hub.unregisterOps(c)
+		c.Conn.Close()
+	}()
+
+	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	c.Conn.SetPongHandler(func(string) error {
+		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		return nil
+	})
+
+	for {
+		if _, _, err := c.Conn.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				utils.NewLogger("Client.readOps").LogOutput(nil, fmt.Errorf("unexpected close error: %v", err))
+			}
+			return
+		}
+	}
+}
+
+func (h *Hub) registerOps(client *Client) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	if h.closing {
+		goAway(client)
+		close(client.Send)
+		return
+	}
+	h.opsClients[client] = true
+}
+
+func (h *Hub) unregisterOps(client *Client) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	if h.opsClients[client] {
+		delete(h.opsClients, client)
+		close(client.Send)
+	}
+}
+
+// PublishOps sends an ops event to the ops connections of every instance
+func (h *Hub) PublishOps(event domain.OpsEvent) error {
+	event.Instance = h.instanceID
+
+	msgBytes, err := json.Marshal(WebSocketMessage{
+		Type:      MessageTypeOps,
+		Data:      event,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	h.deliverToOps(msgBytes)
+	h.publish(backplaneTargetOps, "", "", msgBytes)
+	return nil
+}
+
+// deliverToOps sends an encoded message to the ops connections on this instance.
+// A dashboard that can't keep up misses events rather than holding the feed back.
+func (h *Hub) deliverToOps(msgBytes []byte) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	for client := range h.opsClients {
+		select {
+		case client.Send <- msgBytes:
+		default:
+		}
+	}
+}
+
+// ConnectionStats counts the connections of this instance
+func (h *Hub) ConnectionStats() domain.OpsConnections {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	return domain.OpsConnections{
+		Connections: len(h.Clients),
+		Users:       len(h.UserMap),
+		OpsClients:  len(h.opsClients),
+	}
+}
@@ -0,0 +1,168 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Chat events of a room are numbered and the latest kept in Redis, so a client
+// reconnecting after a brief disconnect gets what it missed instead of refetching
+// history. Clients ack the last seq they handled per room and drop events with a
+// seq they already have, replays can overlap live delivery.
+const (
+	replayRetention = 200 // events kept per room
+	replayTTL       = 24 * time.Hour
+)
+
+// MessageTypeReplayGap tells a client events between its ack and the oldest kept
+// one are gone, so it has to refetch the room history. Seq is the first missing one.
+const MessageTypeReplayGap = "replayGap"
+
+func roomSeqKey(roomID string) string {
+	return "ws:room_seq:" + roomID
+}
+
+func roomEventsKey(roomID string) string {
+	return "ws:room_events:" + roomID
+}
+
+// acksKey holds the user's acked seq per room, shared by all their connections
+func acksKey(userID string) string {
+	return "ws:acks:" + userID
+}
+
+// nextRoomSeq numbers the next chat event of the room. The counter never expires,
+// a restart from 1 would fall behind acks and skip events. It returns 0 when events
+// aren't kept, without Redis or when it fails.
+func (h *Hub) nextRoomSeq(roomID string) int64 {
+	if h.rdb == nil {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	seq, err := h.rdb.Incr(ctx, roomSeqKey(roomID)).Result()
+	if err != nil {
+		utils.NewLogger("Hub.nextRoomSeq").LogOutput(nil, err)
+		return 0
+	}
+	return seq
+}
+
+// retainRoomEvent keeps an encoded event for replay, dropping the oldest past replayRetention
+func (h *Hub) retainRoomEvent(roomID string, seq int64, payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	key := roomEventsKey(roomID)
+	pipe := h.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(seq), Member: string(payload)})
+	pipe.ZRemRangeByRank(ctx, key, 0, -replayRetention-1)
+	pipe.Expire(ctx, key, replayTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		utils.NewLogger("Hub.retainRoomEvent").LogOutput(nil, err)
+	}
+}
+
+// ack records the last seq the user handled in the room, never moving it back
+// when connections ack out of order
+func (h *Hub) ack(userID, roomID string, seq int64) error {
+	if h.rdb == nil || seq <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	key := acksKey(userID)
+	pipe := h.rdb.TxPipeline()
+	pipe.ZAddGT(ctx, key, redis.Z{Score: float64(seq), Member: roomID})
+	pipe.Expire(ctx, key, replayTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// replay queues the chat events of the client's rooms it missed since the user's
+// last ack. Rooms without an ack are skipped, the client loads their history anyway.
+func (c *Client) replay() {
+	if c.Hub == nil || c.Hub.rdb == nil || !c.Subscribed(CategoryChat) {
+		return
+	}
+
+	logger := utils.NewLogger("Client.replay")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	acks, err := c.Hub.rdb.ZRangeWithScores(ctx, acksKey(c.UserID), 0, -1).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	c.mu.Lock()
+	rooms := make(map[string]bool, len(c.RoomIDs))
+	for roomID := range c.RoomIDs {
+		rooms[roomID] = true
+	}
+	c.mu.Unlock()
+
+	replayed := 0
+	for _, acked := range acks {
+		roomID := acked.Member
+		if !rooms[roomID] {
+			continue
+		}
+		since := int64(acked.Score)
+
+		events, err := c.Hub.rdb.ZRangeByScoreWithScores(ctx, roomEventsKey(roomID), &redis.ZRangeBy{
+			Min: "(" + strconv.FormatInt(since, 10),
+			Max: "+inf",
+		}).Result()
+		if err != nil {
+			logger.LogOutput(nil, fmt.Errorf("error loading events of room %s: %v", roomID, err))
+			continue
+		}
+
+		// The oldest kept event follows the ack unless some were trimmed or expired
+		if len(events) == 0 {
+			latest, err := c.Hub.rdb.Get(ctx, roomSeqKey(roomID)).Int64()
+			if err != nil || latest <= since {
+				continue
+			}
+			c.sendReplayGap(roomID, since+1)
+			continue
+		}
+		if first := int64(events[0].Score); first > since+1 {
+			c.sendReplayGap(roomID, since+1)
+		}
+
+		for _, event := range events {
+			select {
+			case c.Send <- []byte(event.Member):
+				replayed++
+			default:
+				// The queue is full, the client refetches from the first event it didn't get
+				c.sendReplayGap(roomID, int64(event.Score))
+				logger.LogOutput(map[string]interface{}{"replayed": replayed}, fmt.Errorf("send channel full"))
+				return
+			}
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"replayed": replayed}, nil)
+}
+
+func (c *Client) sendReplayGap(roomID string, from int64) {
+	c.sendDirect(WebSocketMessage{
+		Type:      MessageTypeReplayGap,
+		RoomID:    roomID,
+		Seq:       from,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// Shutdown sends every connection a going away close frame, so clients reconnect
+// to another instance, and turns new connections away. Connections close as their
+// clients answer, running their usual cleanup. Whatever is still open when ctx
+// is done is closed outright.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	logger := utils.NewLogger("Hub.Shutdown")
+
+	h.Mutex.Lock()
+	h.closing = true
+	clients := h.connections()
+	h.Mutex.Unlock()
+	logger.LogInput(len(clients))
+
+	for _, client := range clients {
+		goAway(client)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		h.Mutex.Lock()
+		clients = h.connections()
+		h.Mutex.Unlock()
+		if len(clients) == 0 {
+			logger.LogOutput("all connections closed", nil)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, client := range clients {
+				client.Conn.Close()
+			}
+			logger.LogOutput(len(clients), ctx.Err())
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// connections returns the chat and ops connections. The caller must hold h.Mutex.
+func (h *Hub) connections() []*Client {
+	clients := make([]*Client, 0, len(h.Clients)+len(h.opsClients))
+	for client := range h.Clients {
+		clients = append(clients, client)
+	}
+	for client := range h.opsClients {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// goAway asks the client to close the connection because the server is going down
+func goAway(client *Client) {
+	client.Conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "Server is shutting down"),
+		time.Now().Add(time.Second),
+	)
+}
@@ -0,0 +1,114 @@
+package websocket
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// Event categories a connection can subscribe to. Connections start subscribed
+// to every category, events outside them like pongs and errors are always sent.
+const (
+	CategoryChat          = "chat"
+	CategoryNotifications = "notifications"
+	CategoryPresence      = "presence"
+	CategoryTyping        = "typing"
+)
+
+var eventCategoryNames = []string{CategoryChat, CategoryNotifications, CategoryPresence, CategoryTyping}
+
+// eventCategories maps event types to the category they are filtered by
+var eventCategories = map[string]string{
+	MessageTypeMessage:                   CategoryChat,
+	domain.RealtimeEventMessageEdited:    CategoryChat,
+	domain.RealtimeEventMessageDeleted:   CategoryChat,
+	domain.RealtimeEventReadReceipt:      CategoryChat,
+	domain.RealtimeEventRoomRead:         CategoryChat,
+	domain.RealtimeEventThreadActivity:   CategoryChat,
+	domain.RealtimeEventSystemMessage:    CategoryChat,
+	domain.RealtimeEventAutoReply:        CategoryChat,
+	domain.RealtimeEventNotification:     CategoryNotifications,
+	domain.RealtimeEventNotificationRead: CategoryNotifications,
+	MessageTypeUserStatus:                CategoryPresence,
+	MessageTypeTyping:                    CategoryTyping,
+}
+
+// eventCategory returns the category of an event type, empty for events sent regardless
+func eventCategory(eventType string) string {
+	return eventCategories[eventType]
+}
+
+// parseCategories parses a comma-separated category list, "all" meaning every category
+func parseCategories(value string) ([]string, error) {
+	categories := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case name == "all":
+			categories = append(categories, eventCategoryNames...)
+		case isEventCategory(name):
+			categories = append(categories, name)
+		default:
+			return nil, fmt.Errorf("unknown event category %q", name)
+		}
+	}
+	return categories, nil
+}
+
+func isEventCategory(name string) bool {
+	for _, category := range eventCategoryNames {
+		if category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribed reports whether the connection wants events of the category
+func (c *Client) Subscribed(category string) bool {
+	if category == "" {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.unsubscribed[category]
+}
+
+// SetSubscribed turns the categories on or off for the connection
+func (c *Client) SetSubscribed(categories []string, subscribed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsubscribed == nil {
+		c.unsubscribed = make(map[string]bool)
+	}
+	for _, category := range categories {
+		if subscribed {
+			delete(c.unsubscribed, category)
+		} else {
+			c.unsubscribed[category] = true
+		}
+	}
+}
+
+// SubscribeOnly subscribes the connection to the categories and nothing else
+func (c *Client) SubscribeOnly(categories []string) {
+	c.SetSubscribed(eventCategoryNames, false)
+	c.SetSubscribed(categories, true)
+}
+
+// Subscriptions lists the categories the connection is subscribed to
+func (c *Client) Subscriptions() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	categories := make([]string, 0, len(eventCategoryNames))
+	for _, category := range eventCategoryNames {
+		if !c.unsubscribed[category] {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"context"
 	"time"
 
 	"fmt"
@@ -12,11 +13,11 @@ import (
 )
 
 const (
-	// Token missing
+	// Ticket missing
 	CloseInvalidFramePayloadData = 1007 // ข้อมูลไม่ถูกต้อง
 
-	// Token invalid/expired
-	ClosePolicyViolation = 1008 // ผิด policy (token)
+	// Ticket invalid/expired
+	ClosePolicyViolation = 1008 // ผิด policy (ticket)
 
 	// Normal closure
 	CloseNormalClosure = 1000 // ปิดปกติ
@@ -28,14 +29,17 @@ const (
 type WebSocketHandler struct {
 	chatUsecase domain.ChatUsecase
 	hub         *Hub
-	authClient  domain.AuthClient
+	authUseCase domain.AuthUseCase
 }
 
-func NewWebSocketHandler(router fiber.Router, chatUsecase domain.ChatUsecase, authClient domain.AuthClient) {
+func NewWebSocketHandler(router fiber.Router, hub *Hub, chatUsecase domain.ChatUsecase, storyUsecase domain.StoryUseCase, authUseCase domain.AuthUseCase) {
+	hub.ChatUsecase = chatUsecase
+	hub.StoryUsecase = storyUsecase
+
 	handler := &WebSocketHandler{
 		chatUsecase: chatUsecase,
-		hub:         NewHub(chatUsecase),
-		authClient:  authClient,
+		hub:         hub,
+		authUseCase: authUseCase,
 	}
 
 	// Start WebSocket hub
@@ -68,31 +72,31 @@ func (h *WebSocketHandler) handleWebSocket(ws *websocket.Conn) {
 		}
 	}()
 
-	// Get token from query parameter
-	token := ws.Query("token")
-	logger.LogInput(token)
-	if token == "" {
-		logger.LogOutput(nil, fmt.Errorf("missing token"))
+	// Clients exchange a single use ticket from POST /api/auth/ws-ticket, never
+	// their access token, which would end up in URLs and logs
+	ticket := ws.Query("ticket")
+	if ticket == "" {
+		logger.LogOutput(nil, fmt.Errorf("missing ticket"))
 		ws.WriteControl(
 			websocket.CloseMessage,
 			websocket.FormatCloseMessage(
 				websocket.CloseInvalidFramePayloadData,
-				"Missing token",
+				"Missing ticket",
 			), time.Now().Add(time.Second),
 		)
 		ws.Close()
 		return
 	}
 
-	// Verify token
-	claims, err := h.authClient.VerifyToken(token)
+	// Redeem ticket
+	userID, err := h.authUseCase.RedeemWSTicket(context.Background(), ticket, domain.WSTicketChat)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		ws.WriteControl(
 			websocket.CloseMessage,
 			websocket.FormatCloseMessage(
 				websocket.ClosePolicyViolation,
-				"Invalid or expired token",
+				"Invalid or expired ticket",
 			),
 			time.Now().Add(time.Second),
 		)
@@ -100,8 +104,42 @@ func (h *WebSocketHandler) handleWebSocket(ws *websocket.Conn) {
 		return
 	}
 
-	logger.LogOutput(claims, nil)
-	userID := claims.UserID
+	logger.LogOutput(userID, nil)
+
+	// Low-power clients can ask for only some event categories, e.g. events=chat,notifications
+	var categories []string
+	if events := ws.Query("events"); events != "" {
+		categories, err = parseCategories(events)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(
+					websocket.CloseInvalidFramePayloadData,
+					err.Error(),
+				),
+				time.Now().Add(time.Second),
+			)
+			ws.Close()
+			return
+		}
+	}
+
+	// Mobile clients can ask for MessagePack in binary frames with encoding=msgpack
+	encoding, err := parseEncoding(ws.Query("encoding"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		ws.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(
+				websocket.CloseInvalidFramePayloadData,
+				err.Error(),
+			),
+			time.Now().Add(time.Second),
+		)
+		ws.Close()
+		return
+	}
 
 	// Create new client with mutex
 	client := &Client{
@@ -111,6 +149,13 @@ func (h *WebSocketHandler) handleWebSocket(ws *websocket.Conn) {
 		Send:    make(chan []byte, 256),
 		Hub:     h.hub,
 		RoomIDs: make(map[string]bool),
+		// Compression only applies when the client negotiated permessage-deflate,
+		// compress=false turns it off for clients that would rather save CPU
+		Encoding: encoding,
+		Compress: ws.Query("compress") != "false",
+	}
+	if categories != nil {
+		client.SubscribeOnly(categories)
 	}
 
 	// Register client before starting pumps
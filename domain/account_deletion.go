@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Account deletion tuning
+const (
+	// AccountDeletionGracePeriod is how long a deleted account can still be
+	// restored by its owner before everything in it is purged
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	// AccountPurgeBatchSize is how many due accounts a purge run takes on
+	AccountPurgeBatchSize = 20
+)
+
+// AccountDeletion is where the deletion of an account stands
+type AccountDeletion struct {
+	UserID      primitive.ObjectID `json:"userId"`
+	RequestedAt time.Time          `json:"requestedAt"`
+	PurgeAt     time.Time          `json:"purgeAt"`
+}
+
+// AccountPurgeResult counts what a purge run removed
+type AccountPurgeResult struct {
+	Accounts  int   `json:"accounts"`
+	Posts     int64 `json:"posts"`
+	Comments  int64 `json:"comments"`
+	Reactions int64 `json:"reactions"`
+	ChatRooms int64 `json:"chatRooms"` // rooms the accounts were removed from
+	Failed    int   `json:"failed"`    // accounts left for the next run
+}
+
+type AccountDeletionUseCase interface {
+	// RequestDeletion deactivates the account right away and schedules its purge
+	// after the grace period
+	RequestDeletion(userID primitive.ObjectID) (*AccountDeletion, error)
+	// GetDeletion returns ErrNotFound when the account isn't being deleted
+	GetDeletion(userID primitive.ObjectID) (*AccountDeletion, error)
+	// CancelDeletion reactivates an account during its grace period
+	CancelDeletion(userID primitive.ObjectID) error
+	// PurgeDueAccounts removes the posts, comments, reactions, chat memberships
+	// and Firebase user of the accounts whose grace period ended, then the accounts
+	PurgeDueAccounts() (*AccountPurgeResult, error)
+}
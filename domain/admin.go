@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DeletedContentType string
+
+const (
+	DeletedContentUser  DeletedContentType = "user"
+	DeletedContentPost  DeletedContentType = "post"
+	DeletedContentStory DeletedContentType = "story"
+)
+
+// DeletedContent is a soft-deleted resource still inside the retention window
+type DeletedContent struct {
+	Type      DeletedContentType `json:"type"`
+	ID        string             `json:"id"`
+	DeletedAt time.Time          `json:"deletedAt"`
+	Payload   interface{}        `json:"payload"` // the deleted document for preview
+}
+
+type AdminUseCase interface {
+	ListDeletedContent(contentType DeletedContentType, limit, offset int) ([]DeletedContent, error)
+	GetDeletedContent(contentType DeletedContentType, id string) (*DeletedContent, error)
+	RestoreContent(adminID primitive.ObjectID, contentType DeletedContentType, id string) (*AuditLog, error)
+	ListAuditLogs(targetType, targetID string, limit, offset int) ([]AuditLog, error)
+
+	// Banned media, matched against uploads by perceptual hash.
+	// BanMedia takes the hash directly or the URL of an uploaded image.
+	BanMedia(adminID primitive.ObjectID, hash, fileURL, reason string) (*BannedMedia, error)
+	ListBannedMedia(limit, offset int) ([]BannedMedia, error)
+	UnbanMedia(adminID primitive.ObjectID, id string) error
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// Batch limits for client analytics
+const (
+	MaxAnalyticsBatchSize      = 100
+	MaxAnalyticsProperties     = 32
+	MaxAnalyticsPropertyLength = 1024
+)
+
+// AnalyticsEvent is a client analytics event. UserID and ReceivedAt are set by the server.
+type AnalyticsEvent struct {
+	Name       string                 `json:"name"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	SessionID  string                 `json:"sessionId,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	UserID     string                 `json:"userId"`
+	ReceivedAt time.Time              `json:"receivedAt"`
+}
+
+// Property types allowed in analytics event schemas
+type AnalyticsPropertyType string
+
+const (
+	AnalyticsPropertyString AnalyticsPropertyType = "string"
+	AnalyticsPropertyNumber AnalyticsPropertyType = "number"
+	AnalyticsPropertyBool   AnalyticsPropertyType = "bool"
+)
+
+// AnalyticsEventSchema lists the properties an event may carry and which of them are required
+type AnalyticsEventSchema struct {
+	Properties map[string]AnalyticsPropertyType
+	Required   []string
+}
+
+// AnalyticsEventSchemas is the registry of events clients may send. Unknown events are rejected.
+var AnalyticsEventSchemas = map[string]AnalyticsEventSchema{
+	"screen_view": {
+		Properties: map[string]AnalyticsPropertyType{
+			"screen":     AnalyticsPropertyString,
+			"previous":   AnalyticsPropertyString,
+			"durationMs": AnalyticsPropertyNumber,
+		},
+		Required: []string{"screen"},
+	},
+	"tap": {
+		Properties: map[string]AnalyticsPropertyType{
+			"screen": AnalyticsPropertyString,
+			"target": AnalyticsPropertyString,
+			"refId":  AnalyticsPropertyString,
+		},
+		Required: []string{"screen", "target"},
+	},
+	"app_open": {
+		Properties: map[string]AnalyticsPropertyType{
+			"platform":   AnalyticsPropertyString,
+			"appVersion": AnalyticsPropertyString,
+			"coldStart":  AnalyticsPropertyBool,
+		},
+	},
+}
+
+// AnalyticsSink forwards accepted events to the analytics pipeline
+type AnalyticsSink interface {
+	Publish(events []AnalyticsEvent) error
+}
+
+type AnalyticsUseCase interface {
+	// TrackEvents validates the whole batch against the schema registry before publishing any of it
+	TrackEvents(userID string, events []AnalyticsEvent) error
+}
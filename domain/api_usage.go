@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// API usage tracking
+const (
+	APIUsageDayFormat = "2006-01-02" // days are UTC
+	// How long daily counts are kept, and how many days a usage query can cover
+	APIUsageRetentionDays = 30
+	DefaultAPIUsageDays   = 7
+	// How many of a user's latest requests are kept
+	APIUsageRecentRequests = 50
+)
+
+// APIRequest is one authenticated request. Route is the matched route pattern, so
+// requests for different posts count towards the same route.
+type APIRequest struct {
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Status     int       `json:"status"`
+	DurationMs int64     `json:"durationMs"`
+	IP         string    `json:"ip"`
+	At         time.Time `json:"at"`
+}
+
+// APIUsageDay counts a user's requests on one day
+type APIUsageDay struct {
+	Date   string           `json:"date"`
+	Total  int64            `json:"total"`
+	Errors int64            `json:"errors"` // responses with a 4xx or 5xx status
+	Routes map[string]int64 `json:"routes"` // keyed by "METHOD route"
+}
+
+// APIUsage sums up a user's recent API usage, latest day first
+type APIUsage struct {
+	UserID string        `json:"userId"`
+	Total  int64         `json:"total"`
+	Errors int64         `json:"errors"`
+	Days   []APIUsageDay `json:"days"`
+	Recent []APIRequest  `json:"recent"` // latest first
+}
+
+type APIUsageRepository interface {
+	Record(userID string, request APIRequest) error
+	// GetDays returns the counts of the days, days without requests included
+	GetDays(userID string, days []string) ([]APIUsageDay, error)
+	GetRecent(userID string, limit int) ([]APIRequest, error)
+}
+
+type APIUsageUseCase interface {
+	// RecordRequest counts a request, failures are only logged
+	RecordRequest(userID string, request APIRequest)
+	GetUsage(userID string, days int) (*APIUsage, error)
+}
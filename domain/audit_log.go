@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit actions
+const (
+	AuditActionRestore        = "restore"
+	AuditActionBanMedia       = "ban_media"
+	AuditActionUnbanMedia     = "unban_media"
+	AuditActionSuspend        = "suspend"
+	AuditActionLift           = "lift_suspension"
+	AuditActionBulkModeration = "bulk_moderation"
+	AuditActionResolveReport  = "resolve_report"
+	AuditActionHideContent    = "hide_content"
+	AuditActionUnhideContent  = "unhide_content"
+	AuditActionDeleteContent  = "delete_content"
+)
+
+// AuditLog records an administrative action on a resource
+type AuditLog struct {
+	BaseModel  `bson:",inline"`
+	ActorID    primitive.ObjectID     `bson:"actorId" json:"actorId"`
+	Action     string                 `bson:"action" json:"action"`
+	TargetType string                 `bson:"targetType" json:"targetType"`
+	TargetID   string                 `bson:"targetId" json:"targetId"`
+	Metadata   map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+type AuditLogRepository interface {
+	Create(log *AuditLog) error
+	FindByTarget(targetType, targetID string, limit, offset int) ([]AuditLog, error)
+	FindAll(limit, offset int) ([]AuditLog, error)
+}
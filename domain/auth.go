@@ -2,19 +2,116 @@ package domain
 
 import (
 	"context"
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Email/password account rules
+const (
+	MinPasswordLength = 8
+	// MaxPasswordLength is bcrypt's limit, it ignores anything longer
+	MaxPasswordLength = 72
+	// EmailVerificationTTL is how long an email verification link works
+	EmailVerificationTTL = 24 * time.Hour
+	// PasswordResetTTL is how long a password reset link works
+	PasswordResetTTL = time.Hour
+	// AuthEmailCooldown is how long a user waits between two emails of the same kind
+	AuthEmailCooldown = time.Minute
+)
+
 type TokenPair struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
 }
 
+// TokenTypeAccess marks user access tokens
+const TokenTypeAccess = "access"
+
+// Claims are the claims carried by user access tokens. Tokens issued before
+// roles, scopes and the tenant were added only carry the user ID.
 type Claims struct {
-	UserID string `json:"userId"`
+	UserID string   `json:"userId"`
+	Type   string   `json:"type,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	Tenant string   `json:"tenant,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenPolicy is what the access and service tokens the server issues carry.
+// Tokens are only checked for the issuer and audience that are set.
+type TokenPolicy struct {
+	Issuer   string
+	Audience string
+	Tenant   string
+	// UserScopes are granted to every user access token
+	UserScopes []string
+}
+
+// ParserOptions returns the options tokens are parsed with to check the issuer and audience
+func (p TokenPolicy) ParserOptions() []jwt.ParserOption {
+	var opts []jwt.ParserOption
+	if p.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(p.Issuer))
+	}
+	if p.Audience != "" {
+		opts = append(opts, jwt.WithAudience(p.Audience))
+	}
+	return opts
+}
+
+// RegisteredClaims returns the standard claims of a token for the subject that
+// lasts for ttl from now
+func (p TokenPolicy) RegisteredClaims(subject string, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    p.Issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	if p.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{p.Audience}
+	}
+	return claims
+}
+
+// TokenIntrospection describes a token to internal services, in the shape of
+// OAuth 2.0 token introspection. Only Active is set for tokens that aren't.
+type TokenIntrospection struct {
+	Active    bool     `json:"active"`
+	TokenType string   `json:"tokenType,omitempty"` // access or service
+	Subject   string   `json:"sub,omitempty"`
+	UserID    string   `json:"userId,omitempty"`
+	ClientID  string   `json:"clientId,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Tenant    string   `json:"tenant,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+}
+
+// WebSocket ticket purposes, one per socket endpoint
+const (
+	WSTicketChat = "chat" // /api/ws
+	WSTicketOps  = "ops"  // /api/ws/ops
+)
+
+// WSTicketTTL is how long a WebSocket ticket can be exchanged for a connection
+const WSTicketTTL = 30 * time.Second
+
+// WSTicket is exchanged once, during the handshake of a socket of its purpose,
+// for a connection as the user it was issued to. Clients send it instead of
+// their access token, so access tokens stay out of socket URLs and logs.
+type WSTicket struct {
+	Ticket    string    `json:"ticket"`
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 type AuthClient interface {
 	VerifyToken(token string) (*Claims, error)
 }
@@ -24,4 +121,27 @@ type AuthUseCase interface {
 	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
 	RevokeRefreshToken(ctx context.Context, refreshToken string) error
 	CreateTestToken(ctx context.Context, userID string) (*TokenPair, error)
+	// IntrospectToken describes an access or service token for internal services
+	IntrospectToken(ctx context.Context, token string) (*TokenIntrospection, error)
+	// IssueWSTicket issues the user a single use ticket for a socket of the purpose
+	IssueWSTicket(ctx context.Context, userID, purpose string) (*WSTicket, error)
+	// RedeemWSTicket returns the user a ticket of the purpose was issued to and invalidates it
+	RedeemWSTicket(ctx context.Context, ticket, purpose string) (string, error)
+
+	// Email/password accounts
+	Register(ctx context.Context, email, password string) (*User, *TokenPair, error)
+	Login(ctx context.Context, email, password string) (*User, *TokenPair, error)
+	// SendEmailVerification emails a verification link unless the address is verified already
+	SendEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	// RequestPasswordReset emails a reset link. Unknown emails succeed too, so
+	// the endpoint doesn't tell who has an account.
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ResetPassword sets the new password and signs the user out everywhere
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+// EmailSender delivers transactional emails like verification and password reset links
+type EmailSender interface {
+	Send(to, subject, body string) error
 }
@@ -0,0 +1,17 @@
+package domain
+
+// MaxBatchSize is the maximum number of IDs accepted by batch fetch endpoints
+const MaxBatchSize = 50
+
+// BatchItem is one entry of a batch fetch response, in request order.
+// Either Data or Error is set.
+type BatchItem struct {
+	ID    string      `json:"id"`
+	Data  interface{} `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// BatchRequest is the request body of batch fetch endpoints
+type BatchRequest struct {
+	IDs []string `json:"ids" validate:"min=1"`
+}
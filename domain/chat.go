@@ -1,34 +1,307 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
 type ChatRoom struct {
-	BaseModel `bson:",inline"`
-	Name      string   `bson:"name" json:"name"`
-	Type      string   `bson:"type" json:"type"` // "private" or "group"
-	Members   []string `bson:"members" json:"members"`
-	Users     []User   `bson:"users,omitempty" json:"users,omitempty"`
+	BaseModel   `bson:",inline"`
+	Name        string   `bson:"name" json:"name"`
+	Type        string   `bson:"type" json:"type"` // "private" or "group"
+	Description string   `bson:"description,omitempty" json:"description,omitempty"`
+	AvatarURL   string   `bson:"avatarUrl,omitempty" json:"avatarUrl,omitempty"` // uploaded through /upload
+	Members     []string `bson:"members" json:"members"`
+	Admins      []string `bson:"admins,omitempty" json:"admins,omitempty"` // group admins, the creator by default
+	Users       []User   `bson:"users,omitempty" json:"users,omitempty"`
+	// LastMessageSeq is the seq of the latest message of the room
+	LastMessageSeq int64 `bson:"lastMessageSeq,omitempty" json:"lastMessageSeq"`
 }
 
 type ChatMessage struct {
 	BaseModel `bson:",inline"`
 	RoomID    string   `bson:"roomId" json:"roomId"`
 	SenderID  string   `bson:"senderId" json:"senderId"`
-	Type      string   `bson:"type" json:"type"` // "text" or "file"
+	Type      string   `bson:"type" json:"type"` // "text", "file" or "system"
 	Content   string   `bson:"content" json:"content"`
 	FileURL   string   `bson:"fileUrl,omitempty" json:"fileUrl,omitempty"`
 	FileType  string   `bson:"fileType,omitempty" json:"fileType,omitempty"`
 	FileSize  int64    `bson:"fileSize,omitempty" json:"fileSize,omitempty"`
 	ReadBy    []string `bson:"readBy" json:"readBy"`
+	IsEdited  bool     `bson:"isEdited" json:"isEdited"`
+	// EditHistory keeps the previous contents of an edited message, oldest first
+	EditHistory []ChatMessageEdit `bson:"editHistory,omitempty" json:"editHistory,omitempty"`
+	// ReplyToMessageID is the message in the same room this one quotes
+	ReplyToMessageID string `bson:"replyToMessageId,omitempty" json:"replyToMessageId,omitempty"`
+	// ReplyTo previews the quoted message, filled in when messages are sent or listed
+	ReplyTo *ChatMessagePreview `bson:"-" json:"replyTo,omitempty"`
+	// Duration in seconds of voice and video messages
+	Duration     float64 `bson:"duration,omitempty" json:"duration,omitempty"`
+	ThumbnailURL string  `bson:"thumbnailUrl,omitempty" json:"thumbnailUrl,omitempty"` // video messages
+	// DeletedFor lists members who deleted the message for themselves only
+	DeletedFor []string `bson:"deletedFor,omitempty" json:"-"`
+	// DeletedForEveryone leaves a placeholder in the room, the content is removed
+	DeletedForEveryone bool `bson:"deletedForEveryone,omitempty" json:"deletedForEveryone,omitempty"`
+	// Story is the story a story reply answers, as it was when the reply was sent
+	Story *ChatStoryRef `bson:"story,omitempty" json:"story,omitempty"`
+	// ThreadID is the root message of the thread a reply belongs to. Thread
+	// replies are left out of the room history and unread counts.
+	ThreadID string `bson:"threadId,omitempty" json:"threadId,omitempty"`
+	// Thread summarizes the replies to a thread root
+	Thread *ChatThreadSummary `bson:"thread,omitempty" json:"thread,omitempty"`
+	// Seq numbers the messages of a room from 1 in the order they were saved, so
+	// clients can order them and notice gaps. Thread replies have none.
+	Seq int64 `bson:"seq,omitempty" json:"seq,omitempty"`
+	// System describes the room change a system message records
+	System *ChatSystemEvent `bson:"system,omitempty" json:"system,omitempty"`
+	// IsAutoReply marks a message sent on the sender's behalf by their auto-reply
+	IsAutoReply bool `bson:"isAutoReply,omitempty" json:"isAutoReply,omitempty"`
+}
+
+// ChatMessageTypeSystem marks the messages the server adds to the room history
+// when members or room details change
+const ChatMessageTypeSystem = "system"
+
+// Events of system messages
+const (
+	ChatSystemMemberAdded        = "member_added"   // ActorID added UserID
+	ChatSystemMemberRemoved      = "member_removed" // ActorID removed UserID
+	ChatSystemMemberJoined       = "member_joined"  // UserID joined through an invite link
+	ChatSystemMemberLeft         = "member_left"
+	ChatSystemRoomRenamed        = "room_renamed"
+	ChatSystemDescriptionChanged = "description_changed"
+	ChatSystemAvatarChanged      = "avatar_changed"
+)
+
+// ChatSystemEvent is the payload of a system message. OldValue and NewValue are
+// set on room detail changes.
+type ChatSystemEvent struct {
+	Event    string `bson:"event" json:"event"`
+	ActorID  string `bson:"actorId" json:"actorId"`
+	UserID   string `bson:"userId,omitempty" json:"userId,omitempty"`
+	OldValue string `bson:"oldValue,omitempty" json:"oldValue,omitempty"`
+	NewValue string `bson:"newValue,omitempty" json:"newValue,omitempty"`
+}
+
+// ChatThreadSummary is kept on the root message of a thread
+type ChatThreadSummary struct {
+	ReplyCount  int64     `bson:"replyCount" json:"replyCount"`
+	LastReplyAt time.Time `bson:"lastReplyAt" json:"lastReplyAt"`
+	// Participants are the root's sender and everyone who replied, they get the thread's activity
+	Participants []string `bson:"participants" json:"participants"`
+}
+
+// ChatThread is a thread root with a page of its replies, oldest first
+type ChatThread struct {
+	Root        *ChatMessage   `json:"root"`
+	Replies     []*ChatMessage `json:"replies"`
+	UnreadCount int64          `json:"unreadCount"` // replies of others the viewer hasn't read
+}
+
+// ChatThreadActivity is sent to the participants of a thread when someone replies
+type ChatThreadActivity struct {
+	RoomID      string       `json:"roomId"`
+	ThreadID    string       `json:"threadId"`
+	Message     *ChatMessage `json:"message"`
+	ReplyCount  int64        `json:"replyCount"`
+	LastReplyAt time.Time    `json:"lastReplyAt"`
+}
+
+// ChatThreadReadResult is returned after marking a thread as read
+type ChatThreadReadResult struct {
+	ThreadID    string `json:"threadId"`
+	MarkedCount int64  `json:"markedCount"`
+}
+
+// ChatStoryRef is a snapshot of a replied story, kept after the story expires
+type ChatStoryRef struct {
+	StoryID   string    `bson:"storyId" json:"storyId"`
+	OwnerID   string    `bson:"ownerId" json:"ownerId"`
+	MediaURL  string    `bson:"mediaUrl" json:"mediaUrl"`
+	MediaType StoryType `bson:"mediaType" json:"mediaType"`
+	Thumbnail string    `bson:"thumbnail,omitempty" json:"thumbnail,omitempty"`
+	Caption   string    `bson:"caption,omitempty" json:"caption,omitempty"`
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"`
+}
+
+// Scopes of a chat message delete
+const (
+	ChatDeleteForMe       = "me"
+	ChatDeleteForEveryone = "all"
+)
+
+// Kinds of files that can be sent to a room
+const (
+	ChatFileImage = "image"
+	ChatFileAudio = "audio"
+	ChatFileVideo = "video"
+)
+
+// ChatFileRule limits the files of one type sent to a room
+type ChatFileRule struct {
+	Kind        string
+	MaxSize     int64         // bytes
+	MaxDuration time.Duration // audio and video only
+}
+
+// ChatFileTypes are the file types accepted by SendFileMessage
+var ChatFileTypes = map[string]ChatFileRule{
+	"jpg": {Kind: ChatFileImage, MaxSize: 10 * 1024 * 1024},
+	"png": {Kind: ChatFileImage, MaxSize: 10 * 1024 * 1024},
+	"gif": {Kind: ChatFileImage, MaxSize: 10 * 1024 * 1024},
+	"m4a": {Kind: ChatFileAudio, MaxSize: 20 * 1024 * 1024, MaxDuration: 15 * time.Minute},
+	"ogg": {Kind: ChatFileAudio, MaxSize: 20 * 1024 * 1024, MaxDuration: 15 * time.Minute},
+	"mp4": {Kind: ChatFileVideo, MaxSize: 100 * 1024 * 1024, MaxDuration: 10 * time.Minute},
+}
+
+// ChatFileMessage is an uploaded file sent to a room
+type ChatFileMessage struct {
+	FileType     string  `json:"fileType"`
+	FileSize     int64   `json:"fileSize"`
+	FileURL      string  `json:"fileUrl"`
+	Duration     float64 `json:"duration,omitempty"`     // seconds, required for audio and video
+	ThumbnailURL string  `json:"thumbnailUrl,omitempty"` // video only
+}
+
+// Reply previews keep at most this many characters of the quoted message
+const ChatReplyPreviewLength = 200
+
+// ChatMessagePreview is the quoted message shown above a reply
+type ChatMessagePreview struct {
+	ID        string `json:"id"`
+	SenderID  string `json:"senderId,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Content   string `json:"content,omitempty"`
+	FileType  string `json:"fileType,omitempty"`
+	IsDeleted bool   `json:"isDeleted"` // the quoted message was deleted since
+}
+
+// ChatMessageEdit is the content a message had before an edit
+type ChatMessageEdit struct {
+	Content  string    `bson:"content" json:"content"`
+	EditedAt time.Time `bson:"editedAt" json:"editedAt"`
+}
+
+// ChatReadReceipt is broadcast to the room when a member reads a message
+type ChatReadReceipt struct {
+	RoomID    string    `json:"roomId"`
+	MessageID string    `json:"messageId"`
+	UserID    string    `json:"userId"`
+	ReadAt    time.Time `json:"readAt"`
+}
+
+// ChatRoomRead is broadcast to the room when a member reads every message up to a time
+type ChatRoomRead struct {
+	RoomID   string    `json:"roomId"`
+	UserID   string    `json:"userId"`
+	ReadUpTo time.Time `json:"readUpTo"`
 }
 
+// ChatRoomReadResult is returned after marking a room as read
+type ChatRoomReadResult struct {
+	RoomID       string           `json:"roomId"`
+	MarkedCount  int64            `json:"markedCount"`
+	UnreadCounts map[string]int64 `json:"unreadCounts"` // unread messages per room, for every room of the user
+}
+
+// Chat flood control limits
+const (
+	MaxChatMessageLength  = 4000 // characters
+	MaxChatMessageLinks   = 5
+	ChatRateLimitWindow   = 10 * time.Second
+	ChatRateLimitMessages = 10 // per sender per room within the window
+	// Senders hitting the rate limit this many times within the strike window
+	// are muted in the room, for twice as long on every further strike
+	ChatFloodStrikesBeforeMute = 3
+	ChatFloodStrikeWindow      = time.Hour
+	ChatFloodMuteBase          = time.Minute
+	ChatFloodMuteMax           = time.Hour
+)
+
+// Chat message fan-out. Messages with more recipients than ChatSyncFanoutRecipients
+// are notified and pushed in the background, so sending to big groups stays fast.
+const (
+	ChatSyncFanoutRecipients = 50
+	ChatFanoutBatchSize      = 500 // notifications per insert
+)
+
+// Codes of the chat limits a message can break
+const (
+	ChatLimitMessageTooLong = "message_too_long"
+	ChatLimitTooManyLinks   = "too_many_links"
+	ChatLimitRateLimited    = "rate_limited"
+	ChatLimitMuted          = "muted"
+)
+
+// ChatLimitError is returned when a message breaks a chat limit
+type ChatLimitError struct {
+	Code       string
+	Err        error
+	Limit      int           // the limit that was broken, when it has one
+	RetryAfter time.Duration // when the sender may try again, for rate limits and mutes
+}
+
+func (e *ChatLimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ChatLimitError) Unwrap() error {
+	return e.Err
+}
+
+// ChatAutoReply is sent on the user's behalf while they're away, in private chats,
+// to the first message of each sender within ChatAutoReplyWindow
+type ChatAutoReply struct {
+	Message string     `bson:"message" json:"message"`
+	Until   *time.Time `bson:"until,omitempty" json:"until,omitempty"` // the auto-reply turns itself off then
+}
+
+// Active reports whether the auto-reply is still on at the time
+func (a *ChatAutoReply) Active(now time.Time) bool {
+	return a != nil && a.Message != "" && (a.Until == nil || now.Before(*a.Until))
+}
+
+// Auto-reply limits
+const (
+	MaxChatAutoReplyLength = 500 // characters
+	ChatAutoReplyWindow    = 24 * time.Hour
+)
+
+// A typing indicator expires unless the client refreshes it within this window
+const ChatTypingTTL = 6 * time.Second
+
+// A user is online while any of their connections sent a heartbeat within this window
+const ChatPresenceTTL = 90 * time.Second
+
+// Presence values broadcast when a user comes online or goes offline
+const (
+	ChatStatusOnline  = "online"
+	ChatStatusOffline = "offline"
+)
+
+// Who can see a user's exact last seen time
+const (
+	LastSeenVisibilityEveryone = "everyone"
+	LastSeenVisibilityFriends  = "friends"
+	LastSeenVisibilityNobody   = "nobody"
+)
+
+// Coarse last seen labels served instead of the exact time
+const (
+	LastSeenRecently = "recently"
+	LastSeenToday    = "today"
+	LastSeenThisWeek = "this_week"
+	LastSeenLongAgo  = "long_ago"
+)
+
+// ChatUserStatus is the presence of a user. When the viewer may not see the exact
+// time, LastSeen is cleared and LastSeenLabel holds a coarse label instead.
 type ChatUserStatus struct {
-	BaseModel `bson:",inline"`
-	UserID    string    `bson:"userId" json:"userId"`
-	IsOnline  bool      `bson:"isOnline" json:"isOnline"`
-	LastSeen  time.Time `bson:"lastSeen" json:"lastSeen"`
+	BaseModel     `bson:",inline"`
+	UserID        string     `bson:"userId" json:"userId"`
+	IsOnline      bool       `bson:"isOnline" json:"isOnline"`
+	LastSeen      *time.Time `bson:"lastSeen" json:"lastSeen,omitempty"`
+	LastSeenLabel string     `bson:"-" json:"lastSeenLabel,omitempty"`
 }
 
 type ChatNotification struct {
@@ -42,23 +315,55 @@ type ChatNotification struct {
 }
 
 type ChatRepository interface {
+	EnsureIndexes(ctx context.Context) error
+
 	// Room operations
 	SaveRoom(room *ChatRoom) error
 	GetRoom(roomID string) (*ChatRoom, error)
 	GetRoomsByUser(userID string) ([]*ChatRoom, error)
 	UpdateRoom(room *ChatRoom) error
 	DeleteRoom(roomID string) error
+	// RemoveMemberFromRooms takes the user out of the members and admins of every
+	// room and returns how many rooms changed
+	RemoveMemberFromRooms(userID string) (int64, error)
 
 	// Message operations
+	// SaveMessage numbers messages outside threads with the room's next seq
 	SaveMessage(message *ChatMessage) error
 	GetMessage(messageID string) (*ChatMessage, error)
-	GetRoomMessages(roomID string, limit int64, offset int64) ([]*ChatMessage, error)
-	DeleteMessage(messageID string) error
+	// GetMessagesByIDs returns the messages that exist, in no particular order
+	GetMessagesByIDs(messageIDs []string) ([]*ChatMessage, error)
+	// GetMessagesBySender lists the messages the user sent in any room, oldest first
+	GetMessagesBySender(senderID string, limit, offset int64) ([]*ChatMessage, error)
+	// GetRoomMessages leaves out messages the viewer deleted for themselves. It lists
+	// the newest first, or with afterSeq above 0 the messages after it oldest first.
+	GetRoomMessages(roomID, viewerID string, afterSeq int64, limit int64, offset int64) ([]*ChatMessage, error)
+	CountRoomMessages(roomID string) (int64, error)
+	DeleteMessageForUser(messageID, userID string) error
+	// DeleteMessageForEveryone removes the content and keeps a placeholder
+	DeleteMessageForEveryone(messageID string) (*ChatMessage, error)
+	// EditMessage replaces the content and appends the previous content to the edit history
+	EditMessage(messageID string, content string, previous ChatMessageEdit) (*ChatMessage, error)
 	MarkMessageAsRead(messageID string, userID string) error
 	GetUnreadMessages(userID string, roomID string) ([]*ChatMessage, error)
+	// MarkRoomAsRead marks the messages of others sent up to the time as read and returns how many changed
+	MarkRoomAsRead(roomID, userID string, upTo time.Time) (int64, error)
+	// CountUnreadByRoom counts the messages of others the user hasn't read, rooms without any are left out
+	CountUnreadByRoom(userID string, roomIDs []string) (map[string]int64, error)
+
+	// Thread operations
+	// GetThreadReplies lists the replies of a thread oldest first, leaving out the ones the viewer deleted
+	GetThreadReplies(threadID, viewerID string, limit, offset int64) ([]*ChatMessage, error)
+	// AddThreadReply counts a reply on the thread root and adds the participants,
+	// returning the updated root
+	AddThreadReply(threadID string, participants []string, at time.Time) (*ChatMessage, error)
+	CountThreadUnread(threadID, userID string) (int64, error)
+	MarkThreadAsRead(threadID, userID string) (int64, error)
 
 	// Notification operations
 	CreateNotification(notification *ChatNotification) error
+	// CreateNotifications inserts many notifications in one write
+	CreateNotifications(notifications []*ChatNotification) error
 	SaveNotification(notification *ChatNotification) error
 	GetUserNotifications(userID string) ([]*ChatNotification, error)
 	GetNotification(notificationID string) (*ChatNotification, error)
@@ -66,40 +371,120 @@ type ChatRepository interface {
 	DeleteRoomNotifications(roomID string) error
 	MarkNotificationAsRead(notificationID string) error
 
+	// Invite operations
+	SaveInvite(invite *ChatInvite) error
+	GetInvite(inviteID string) (*ChatInvite, error)
+	GetRoomInvites(roomID string) ([]*ChatInvite, error)
+	// UseInvite counts a join against the invite. It returns false when the invite is revoked, expired or used up.
+	UseInvite(inviteID string) (bool, error)
+	RevokeInvite(inviteID string) error
+
+	// Room settings operations
+	// GetRoomSettings returns nil when the member has no settings for the room
+	GetRoomSettings(roomID, userID string) (*ChatRoomSettings, error)
+	GetRoomSettingsForUsers(roomID string, userIDs []string) ([]*ChatRoomSettings, error)
+	UpsertRoomSettings(settings *ChatRoomSettings) error
+
+	// Flood control state, kept in Redis
+	// IncrementMessageCount counts a message in the current rate window and returns the count
+	IncrementMessageCount(roomID, userID string, window time.Duration) (int64, error)
+	AddFloodStrike(roomID, userID string, window time.Duration) (int64, error)
+	FloodMute(roomID, userID string, duration time.Duration) error
+	// GetFloodMute returns how long the sender stays muted in the room, 0 when not muted
+	GetFloodMute(roomID, userID string) (time.Duration, error)
+
+	// ClaimAutoReply reports whether the user's auto-reply may answer the sender,
+	// which it may once per window. Kept in Redis.
+	ClaimAutoReply(userID, senderID string, window time.Duration) (bool, error)
+
+	// Typing state, kept in Redis so clients joining late can fetch it
+	SetTyping(roomID, userID string, typing bool, ttl time.Duration) error
+	GetTypingUsers(roomID string, ttl time.Duration) ([]string, error)
+
 	// User status operations
 	UpdateUserStatus(status *ChatUserStatus) error
 	GetUserStatus(userID string) (*ChatUserStatus, error)
 	GetOnlineUsers(userIDs []string) ([]*ChatUserStatus, error)
+
+	// Presence, kept in Redis per connection so users stay online until their last device leaves.
+	// TouchPresence reports whether the user came online and RemovePresence whether they went offline.
+	TouchPresence(userID, connectionID string, ttl time.Duration) (bool, error)
+	RemovePresence(userID, connectionID string, ttl time.Duration) (bool, error)
+	// ExpirePresence takes the users without a heartbeat within the ttl offline,
+	// returning when each of them was last seen
+	ExpirePresence(ttl time.Duration) (map[string]time.Time, error)
+	GetPresence(userIDs []string, ttl time.Duration) (map[string]bool, error)
 }
 
 type ChatUsecase interface {
 	// Room operations
 	CreatePrivateChat(userID1, userID2 string) (*ChatRoom, error)
-	CreateGroupChat(name string, memberIDs []string) (*ChatRoom, error)
+	CreateGroupChat(creatorID, name string, memberIDs []string) (*ChatRoom, error)
 	GetUserChats(userID string) ([]*ChatRoom, error)
 	GetRoom(roomID string) (*ChatRoom, error)
 	GetRoomsByUserID(userID string) ([]*ChatRoom, error)
-	AddMemberToGroup(roomID, userID string) error
-	RemoveMemberFromGroup(roomID, userID string) error
+	// AddMemberToGroup and RemoveMemberFromGroup record the change in the room history.
+	// A member removing themselves is recorded as leaving.
+	AddMemberToGroup(roomID, actorID, userID string) error
+	RemoveMemberFromGroup(roomID, actorID, userID string) error
 	UpdateRoom(room *ChatRoom) error
+	UpdateRoomDetails(roomID, userID string, details ChatRoomDetails) (*ChatRoom, error)
 	DeleteRoom(roomID string) error
 
+	// Invite links
+	CreateInviteLink(roomID, userID string, expiresIn time.Duration, maxUses int) (*ChatInviteLink, error)
+	GetRoomInvites(roomID, userID string) ([]*ChatInvite, error)
+	RevokeInvite(roomID, inviteID, userID string) error
+	JoinByInvite(token, userID string) (*ChatRoom, error)
+
+	// Per-member room settings
+	GetRoomSettings(roomID, userID string) (*ChatRoomSettings, error)
+	UpdateRoomSettings(roomID, userID string, update ChatRoomSettingsUpdate) (*ChatRoomSettings, error)
+
 	// Message operations
-	SendMessage(roomID, senderID, messageType, content string) (*ChatMessage, error)
-	SendFileMessage(roomID, senderID string, fileType string, fileSize int64, fileURL string) (*ChatMessage, error)
-	GetChatMessages(roomID string, limit, offset int) ([]*ChatMessage, error)
+	// SendMessage sends a message, quoting replyToMessageID when it isn't empty
+	SendMessage(roomID, senderID, messageType, content, replyToMessageID string) (*ChatMessage, error)
+	SendFileMessage(roomID, senderID string, file ChatFileMessage) (*ChatMessage, error)
+	// SendStoryReply sends a reply to the story owner in their private room, creating the room if needed
+	SendStoryReply(senderID string, story ChatStoryRef, content string) (*ChatMessage, error)
+	GetChatMessages(roomID, viewerID string, afterSeq int64, limit, offset int) ([]*ChatMessage, error)
+	CountChatMessages(roomID string) (int64, error)
 	MarkMessageRead(messageID, userID string) error
+	MarkRoomRead(roomID, userID string, upTo time.Time) (*ChatRoomReadResult, error)
+	// GetUnreadCounts maps each of the user's room IDs to its unread message count
+	GetUnreadCounts(userID string) (map[string]int64, error)
 	GetUnreadMessages(userID string, roomID string) ([]*ChatMessage, error)
-	DeleteMessage(messageID string) error
+	// DeleteMessage deletes the message for the user only, or for every member with ChatDeleteForEveryone
+	DeleteMessage(messageID, userID, scope string) error
+	EditMessage(messageID, userID, content string) (*ChatMessage, error)
+
+	// Threads, only in group rooms. Replies go to the thread's participants only.
+	SendThreadReply(threadID, senderID, content string) (*ChatMessage, error)
+	GetThread(threadID, viewerID string, limit, offset int) (*ChatThread, error)
+	MarkThreadRead(threadID, userID string) (*ChatThreadReadResult, error)
+
+	// Away auto-reply. GetAutoReply returns nil when it's off.
+	GetAutoReply(userID string) (*ChatAutoReply, error)
+	SetAutoReply(userID string, reply ChatAutoReply) (*ChatAutoReply, error)
+	ClearAutoReply(userID string) error
+
+	// Typing indicators
+	SetTyping(roomID, userID string, typing bool) error
+	GetTypingUsers(roomID, userID string) ([]string, error)
 
 	// User status operations
 	UpdateUserOnlineStatus(userID string, isOnline bool) error
-	GetUserOnlineStatus(userID string) (*ChatUserStatus, error)
-	GetOnlineUsers(userIDs []string) ([]*ChatUserStatus, error)
+	// GetUserOnlineStatus applies the user's last seen settings for the viewer
+	GetUserOnlineStatus(viewerID, userID string) (*ChatUserStatus, error)
+	GetOnlineUsers(viewerID string, userIDs []string) ([]*ChatUserStatus, error)
+	// Presence tracked from WebSocket connections and their heartbeats
+	TouchPresence(userID, connectionID string) error
+	DisconnectPresence(userID, connectionID string) error
+	ExpirePresence() (int, error)
 
 	// Notification operations
 	SendNotification(notification *ChatNotification) error
 	GetUserNotifications(userID string) ([]*ChatNotification, error)
 	MarkNotificationRead(notificationID string) error
-	DeleteNotification(notificationID string) error
+	DeleteNotification(notificationID, userID string) error
 }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenTypeChatInvite marks invite link tokens so they can't be used as user or service tokens
+const TokenTypeChatInvite = "chat_invite"
+
+// Invite link limits
+const (
+	DefaultChatInviteExpiry = 7 * 24 * time.Hour
+	MaxChatInviteExpiry     = 30 * 24 * time.Hour
+)
+
+// ChatInvite is an invite link to a group room. The link carries a signed token
+// referencing the invite, so revoking the invite disables the link.
+type ChatInvite struct {
+	BaseModel `bson:",inline"`
+	RoomID    string     `bson:"roomId" json:"roomId"`
+	CreatedBy string     `bson:"createdBy" json:"createdBy"`
+	ExpiresAt time.Time  `bson:"expiresAt" json:"expiresAt"`
+	MaxUses   int        `bson:"maxUses" json:"maxUses"` // 0 means unlimited
+	Uses      int        `bson:"uses" json:"uses"`
+	RevokedAt *time.Time `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+}
+
+// ChatInviteLink is returned once when an invite is created
+type ChatInviteLink struct {
+	Invite *ChatInvite `json:"invite"`
+	Token  string      `json:"token"`
+}
+
+// ChatInviteClaims are the claims carried by invite link tokens
+type ChatInviteClaims struct {
+	InviteID string `json:"inviteId"`
+	RoomID   string `json:"roomId"`
+	Type     string `json:"type"`
+	jwt.RegisteredClaims
+}
+
+// ChatRoomDetails holds the editable metadata of a group room. Nil fields are left unchanged.
+type ChatRoomDetails struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	AvatarURL   *string `json:"avatarUrl,omitempty"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Per-room notification levels
+const (
+	ChatNotifyAll      = "all"
+	ChatNotifyMentions = "mentions" // only messages mentioning the member
+	ChatNotifyNone     = "none"
+)
+
+// ChatRoomSettings are a member's own settings for a room. Members without a
+// settings document get every notification.
+type ChatRoomSettings struct {
+	BaseModel         `bson:",inline"`
+	RoomID            string     `bson:"roomId" json:"roomId"`
+	UserID            string     `bson:"userId" json:"userId"`
+	Muted             bool       `bson:"muted" json:"muted"`
+	MuteUntil         *time.Time `bson:"muteUntil,omitempty" json:"muteUntil,omitempty"` // nil mutes until unmuted
+	NotificationLevel string     `bson:"notificationLevel" json:"notificationLevel"`
+}
+
+// IsMuted reports whether the mute is in effect at the time
+func (s *ChatRoomSettings) IsMuted(at time.Time) bool {
+	return s.Muted && (s.MuteUntil == nil || s.MuteUntil.After(at))
+}
+
+// ChatRoomSettingsUpdate changes a member's room settings. Nil fields are left unchanged.
+type ChatRoomSettingsUpdate struct {
+	Muted             *bool      `json:"muted,omitempty"`
+	MuteUntil         *time.Time `json:"muteUntil,omitempty"` // mutes the room until the time
+	NotificationLevel *string    `json:"notificationLevel,omitempty"`
+}
+
+// IsValidChatNotifyLevel reports whether the level is a known notification level
+func IsValidChatNotifyLevel(level string) bool {
+	switch level {
+	case ChatNotifyAll, ChatNotifyMentions, ChatNotifyNone:
+		return true
+	}
+	return false
+}
@@ -1,17 +1,34 @@
 package domain
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Comment struct {
 	BaseModel      `bson:",inline"`
-	PostID         primitive.ObjectID  `bson:"postId" json:"postId"`
-	UserID         primitive.ObjectID  `bson:"userId" json:"userId"`
-	Content        string              `bson:"content" json:"content"`
-	Media          []Media             `bson:"media,omitempty" json:"media,omitempty"`
-	ReactionCounts map[string]int      `bson:"reactionCounts" json:"reactionCounts"`
-	ReplyTo        *primitive.ObjectID `bson:"replyTo,omitempty" json:"replyTo,omitempty"`
+	PostID         primitive.ObjectID   `bson:"postId" json:"postId"`
+	UserID         primitive.ObjectID   `bson:"userId" json:"userId"`
+	Content        string               `bson:"content" json:"content"`
+	Language       string               `bson:"language,omitempty" json:"language,omitempty"` // Detected from content on write
+	Media          []Media              `bson:"media,omitempty" json:"media,omitempty"`
+	Mentions       []primitive.ObjectID `bson:"mentions" json:"mentions,omitempty"`
+	ReactionCounts map[string]int       `bson:"reactionCounts" json:"reactionCounts"`
+	// ReplyToCommentID is the parent of a reply, stored as replyTo so existing replies keep their thread
+	ReplyToCommentID *primitive.ObjectID `bson:"replyTo,omitempty" json:"replyTo,omitempty"`
+	ReplyCount       int                 `bson:"replyCount" json:"replyCount"` // direct replies only
+	IsEdited         bool                `bson:"isEdited" json:"isEdited"`
+	LastEditedAt     *time.Time          `bson:"lastEditedAt,omitempty" json:"lastEditedAt,omitempty"`
+	// EditHistory keeps the previous contents of an edited comment, oldest first
+	EditHistory []CommentEditLog `bson:"editHistory,omitempty" json:"editHistory,omitempty"`
+}
+
+// CommentEditLog is a previous version of an edited comment
+type CommentEditLog struct {
+	Content  string    `bson:"content" json:"content"`
+	Media    []Media   `bson:"media,omitempty" json:"media,omitempty"`
+	EditedAt time.Time `bson:"editedAt" json:"editedAt"`
 }
 
 // Repository interface
@@ -20,16 +37,40 @@ type CommentRepository interface {
 	Update(comment *Comment) error
 	Delete(id primitive.ObjectID) error
 	FindByID(id primitive.ObjectID) (*Comment, error)
-	FindByPostID(postID primitive.ObjectID, limit, offset int) ([]Comment, error)
+	// FindByIDs returns the comments that exist, in no particular order
+	FindByIDs(ids []primitive.ObjectID) ([]Comment, error)
+	// FindByPostID and CountByPostID cover the top-level comments, replies are listed under their parent.
+	// FindByPostID and FindReplies leave out comments of the excluded authors.
+	FindByPostID(postID primitive.ObjectID, exclude []primitive.ObjectID, limit, offset int) ([]Comment, error)
+	CountByPostID(postID primitive.ObjectID) (int64, error)
+	// DeleteByPostID removes all the comments and replies of a post for good
+	DeleteByPostID(postID primitive.ObjectID) error
+	// FindByUserID lists the user's comments and replies, oldest first
+	FindByUserID(userID primitive.ObjectID, limit, offset int) ([]Comment, error)
+	// DeleteByUserID removes all the user's comments and replies and returns how many were removed
+	DeleteByUserID(userID primitive.ObjectID) (int64, error)
+	// FindReplies returns the direct replies to the comment, oldest first
+	FindReplies(commentID primitive.ObjectID, exclude []primitive.ObjectID, limit, offset int) ([]Comment, error)
+	CountReplies(commentID primitive.ObjectID) (int64, error)
+	IncrementReplyCount(commentID primitive.ObjectID, delta int) error
+	// GetTranslation returns nil, nil when the translation is not cached
+	GetTranslation(commentID primitive.ObjectID, targetLanguage string) (*Translation, error)
+	SetTranslation(commentID primitive.ObjectID, translation *Translation, ttl time.Duration) error
 }
 
 // UseCase interface
 type CommentUseCase interface {
 	CreateComment(userID, postID primitive.ObjectID, content string, media []Media, replyTo *primitive.ObjectID) (*Comment, error)
-	UpdateComment(commentID primitive.ObjectID, content string, media []Media) (*Comment, error)
+	UpdateComment(userID, commentID primitive.ObjectID, content string, media []Media) (*Comment, error)
 	DeleteComment(commentID primitive.ObjectID) error
 	GetComment(commentID primitive.ObjectID) (*Comment, error)
-	ListComments(postID primitive.ObjectID, limit, offset int) ([]Comment, error)
+	// ListComments and ListReplies leave out comments of users blocked either way with the viewer
+	ListComments(viewerID, postID primitive.ObjectID, limit, offset int) ([]Comment, error)
+	CountComments(postID primitive.ObjectID) (int64, error)
+	ReplyToComment(userID, commentID primitive.ObjectID, content string, media []Media) (*Comment, error)
+	ListReplies(viewerID, commentID primitive.ObjectID, limit, offset int) ([]Comment, error)
+	CountReplies(commentID primitive.ObjectID) (int64, error)
+	TranslateComment(commentID primitive.ObjectID, targetLanguage string) (*Translation, error)
 }
 
 // CommentUser represents limited user data for comment owner
@@ -0,0 +1,24 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Kinds of content admins moderate one by one
+const (
+	ModeratedContentPost    = "post"
+	ModeratedContentComment = "comment"
+)
+
+// ContentModerationRequest carries the reason an admin acted, kept in the audit log
+type ContentModerationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ContentModerationUseCase lets admins act on a single post or comment. Hidden
+// content has IsActive unset: it drops out of feeds, profiles, search and comment
+// lists, and only its author can still open it. Deleting works like the author
+// deleting it. Every action is recorded in the audit log.
+type ContentModerationUseCase interface {
+	HideContent(adminID primitive.ObjectID, contentType, id string, req ContentModerationRequest) (*AuditLog, error)
+	UnhideContent(adminID primitive.ObjectID, contentType, id string, req ContentModerationRequest) (*AuditLog, error)
+	DeleteContent(adminID primitive.ObjectID, contentType, id string, req ContentModerationRequest) (*AuditLog, error)
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Data export statuses
+const (
+	DataExportPending   = "pending"
+	DataExportRunning   = "running"
+	DataExportCompleted = "completed"
+	DataExportFailed    = "failed"
+)
+
+// Data export tuning
+const (
+	// A running export not updated for this long is taken over by another worker
+	DataExportStaleAfter = 15 * time.Minute
+	// DataExportPageSize is how many records are read at a time into the archive
+	DataExportPageSize = 500
+)
+
+// DataExport is a user's request for an archive of their profile, posts,
+// comments, chat messages and reactions. The archive is built in the background
+// and the user is notified with its link.
+type DataExport struct {
+	BaseModel  `bson:",inline"`
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	Status     string             `bson:"status" json:"status"`
+	FileURL    string             `bson:"fileUrl,omitempty" json:"fileUrl,omitempty"`
+	Size       int64              `bson:"size,omitempty" json:"size,omitempty"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt  *time.Time         `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	FinishedAt *time.Time         `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+}
+
+type DataExportRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(export *DataExport) error
+	// FindLatestByUser returns the user's newest export
+	FindLatestByUser(userID primitive.ObjectID) (*DataExport, error)
+	// ClaimNext marks the oldest pending export, or a running export not updated
+	// since staleBefore, as running and returns it. It returns nil when there's none.
+	ClaimNext(staleBefore time.Time) (*DataExport, error)
+	Complete(id primitive.ObjectID, fileURL string, size int64) error
+	Fail(id primitive.ObjectID, reason string) error
+}
+
+type DataExportUseCase interface {
+	// RequestExport queues an export of the user's data, unless one is already queued or running
+	RequestExport(userID primitive.ObjectID) (*DataExport, error)
+	GetLatestExport(userID primitive.ObjectID) (*DataExport, error)
+	// RunNextExport builds one claimable export and reports false when there was none
+	RunNextExport() (bool, error)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Device platforms accepted for push registration
+const (
+	DevicePlatformIOS     = "ios"
+	DevicePlatformAndroid = "android"
+	DevicePlatformWeb     = "web"
+)
+
+// DeviceToken is an FCM registration token of one of the user's devices
+type DeviceToken struct {
+	BaseModel `bson:",inline"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Token     string             `bson:"token" json:"token"`
+	Platform  string             `bson:"platform" json:"platform"`
+}
+
+// PushMessage is a mobile push notification
+type PushMessage struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"` // lets the app open the referenced content
+}
+
+// DeviceTokenRepository stores the push tokens of user devices
+type DeviceTokenRepository interface {
+	// Upsert saves the token for the user. A token registered by another user moves to this one.
+	Upsert(device *DeviceToken) error
+	Delete(userID primitive.ObjectID, token string) error
+	DeleteByTokens(tokens []string) error
+	FindByUserID(userID primitive.ObjectID) ([]DeviceToken, error)
+}
+
+// PushSender delivers push notifications through the push provider
+type PushSender interface {
+	// Send returns the tokens the provider reported as no longer registered
+	Send(tokens []string, message *PushMessage) ([]string, error)
+}
+
+// DeviceUseCase manages device registrations and pushes to them
+type DeviceUseCase interface {
+	RegisterDevice(userID primitive.ObjectID, token, platform string) (*DeviceToken, error)
+	UnregisterDevice(userID primitive.ObjectID, token string) error
+	PushToUser(userID primitive.ObjectID, message *PushMessage) error
+}
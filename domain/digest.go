@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Trending digest tuning
+const (
+	// DigestWindow is how far back the digest looks for trending tags and posts
+	DigestWindow = 24 * time.Hour
+	// DigestMinGap is the least time between two digests of a user. It's a bit under
+	// a day so the hourly job doesn't push the send time later every day.
+	DigestMinGap = 23 * time.Hour
+	// DigestTrendingTags is how many trending tags are scanned for the user's interests
+	DigestTrendingTags = 50
+	DigestMaxTags      = 3
+	DigestMaxPosts     = 3
+	// DigestMaxFollowing caps the followed accounts whose posts the digest considers
+	DigestMaxFollowing = 500
+	// DigestBatchSize is how many users a run loads at a time
+	DigestBatchSize = 100
+)
+
+// QuietHours is a daily range of local hours, StartHour included and EndHour
+// excluded, in which the user gets no digest. The range may wrap past midnight.
+type QuietHours struct {
+	StartHour int `bson:"startHour" json:"startHour"`
+	EndHour   int `bson:"endHour" json:"endHour"`
+}
+
+// Contains reports whether the local hour is within the quiet hours
+func (q *QuietHours) Contains(hour int) bool {
+	if q.StartHour <= q.EndHour {
+		return hour >= q.StartHour && hour < q.EndHour
+	}
+	return hour >= q.StartHour || hour < q.EndHour
+}
+
+// NotificationPreferences are a user's own notification settings. Users without
+// a preferences document get no digest.
+type NotificationPreferences struct {
+	BaseModel      `bson:",inline"`
+	UserID         primitive.ObjectID `bson:"userId" json:"userId"`
+	TrendingDigest bool               `bson:"trendingDigest" json:"trendingDigest"`
+	QuietHours     *QuietHours        `bson:"quietHours,omitempty" json:"quietHours,omitempty"`
+	// TimeZone is the IANA time zone quiet hours are in, UTC when unset
+	TimeZone string `bson:"timeZone,omitempty" json:"timeZone,omitempty"`
+	// LastDigestAt is when the digest last ran for the user, whether or not there was anything to send
+	LastDigestAt *time.Time `bson:"lastDigestAt,omitempty" json:"lastDigestAt,omitempty"`
+}
+
+// InQuietHours reports whether the time falls in the user's quiet hours
+func (p *NotificationPreferences) InQuietHours(at time.Time) bool {
+	if p.QuietHours == nil {
+		return false
+	}
+	location, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	return p.QuietHours.Contains(at.In(location).Hour())
+}
+
+// NotificationPreferencesUpdate changes a user's notification preferences. Nil
+// fields are left unchanged, ClearQuietHours removes the quiet hours.
+type NotificationPreferencesUpdate struct {
+	TrendingDigest  *bool       `json:"trendingDigest,omitempty"`
+	QuietHours      *QuietHours `json:"quietHours,omitempty"`
+	ClearQuietHours bool        `json:"clearQuietHours,omitempty"`
+	TimeZone        *string     `json:"timeZone,omitempty"`
+}
+
+type NotificationPreferencesRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Find returns nil, nil when the user never saved preferences
+	Find(userID primitive.ObjectID) (*NotificationPreferences, error)
+	// Upsert saves the preferences, creating them on first use
+	Upsert(preferences *NotificationPreferences) error
+	// FindDigestDue returns the preferences of users who opted in to the digest and
+	// didn't get one since the time, in user order after afterUserID
+	FindDigestDue(since time.Time, afterUserID primitive.ObjectID, limit int) ([]NotificationPreferences, error)
+	MarkDigestSent(userID primitive.ObjectID, at time.Time) error
+}
+
+// DigestUseCase manages notification preferences and sends the trending digest
+type DigestUseCase interface {
+	GetPreferences(userID primitive.ObjectID) (*NotificationPreferences, error)
+	UpdatePreferences(userID primitive.ObjectID, update NotificationPreferencesUpdate) (*NotificationPreferences, error)
+	// SendTrendingDigests notifies the opted-in users who are due a digest and not
+	// in their quiet hours about what trended in their interests and among the
+	// accounts they follow. It returns how many digests were sent.
+	SendTrendingDigests() (int, error)
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Domain event types exported to external streams
+const (
+	DomainEventPostCreated    = "post.created"
+	DomainEventMessageSent    = "message.sent"
+	DomainEventUserRegistered = "user.registered"
+)
+
+// DomainEventSchemaVersions is the payload version of each event type.
+// Bump the version whenever a payload changes in a way consumers must handle.
+var DomainEventSchemaVersions = map[string]int{
+	DomainEventPostCreated:    1,
+	DomainEventMessageSent:    1,
+	DomainEventUserRegistered: 1,
+}
+
+// DomainEvent is an event waiting in the outbox or already exported.
+// Delivery is at-least-once, consumers deduplicate on ID.
+type DomainEvent struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	Type          string             `bson:"type" json:"type"`
+	SchemaVersion int                `bson:"schemaVersion" json:"schemaVersion"`
+	OccurredAt    time.Time          `bson:"occurredAt" json:"occurredAt"`
+	Payload       string             `bson:"payload" json:"payload"` // JSON encoded
+	ExportedAt    *time.Time         `bson:"exportedAt,omitempty" json:"exportedAt,omitempty"`
+}
+
+// PostCreatedPayload is the payload of post.created, version 1
+type PostCreatedPayload struct {
+	PostID     primitive.ObjectID `json:"postId"`
+	UserID     primitive.ObjectID `json:"userId"`
+	PostType   string             `json:"postType,omitempty"`
+	Visibility string             `json:"visibility"`
+	Tags       []string           `json:"tags"`
+	MediaCount int                `json:"mediaCount"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+// MessageSentPayload is the payload of message.sent, version 1. Message content is not exported.
+type MessageSentPayload struct {
+	MessageID string    `json:"messageId"`
+	RoomID    string    `json:"roomId"`
+	SenderID  string    `json:"senderId"`
+	Type      string    `json:"type"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// UserRegisteredPayload is the payload of user.registered, version 1
+type UserRegisteredPayload struct {
+	UserID       primitive.ObjectID `json:"userId"`
+	Provider     AuthProvider       `json:"provider,omitempty"`
+	RegisteredAt time.Time          `json:"registeredAt"`
+}
+
+// DomainEventRepository is the outbox events are written to before they are exported
+type DomainEventRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Save(event *DomainEvent) error
+	// FindPending returns events not exported yet, oldest first
+	FindPending(limit int) ([]DomainEvent, error)
+	MarkExported(ids []primitive.ObjectID) error
+}
+
+// DomainEventSink writes events to the external stream
+type DomainEventSink interface {
+	Export(events []DomainEvent) error
+}
+
+// DomainEventPublisher records domain events for export. Failures are logged and never fail the caller.
+type DomainEventPublisher interface {
+	Publish(eventType string, payload interface{})
+}
@@ -25,12 +25,54 @@ var (
 	// ErrDuplicate represents a duplicate resource error
 	ErrDuplicate = errors.New("duplicate resource")
 
+	// ErrForbidden represents an authenticated user acting on a resource they don't own
+	ErrForbidden = errors.New("forbidden")
+
 	// Friendship errors
 	ErrFriendRequestAlreadySent = errors.New("friend request already sent")
 	ErrAlreadyFriends          = errors.New("already friends")
 	ErrFriendRequestNotFound   = errors.New("friend request not found")
 	ErrFriendshipNotFound      = errors.New("friendship not found")
 	ErrNotFriends             = errors.New("not friends")
+
+	// Block errors
+	ErrBlocked = errors.New("you blocked this user or they blocked you")
+
+	// Privacy errors
+	ErrPrivacyRestricted = errors.New("not allowed by the user's privacy settings")
+
+	// Username errors
+	ErrUsernameNotAllowed = errors.New("username is not allowed")
+	ErrUsernameTaken      = errors.New("username is already taken")
+	ErrUsernameNotClaimable = errors.New("username can not be claimed")
+
+	// Post errors
+	ErrPostEditLocked  = errors.New("post is being edited in another session")
+	ErrPinnedPostLimit = errors.New("too many pinned posts")
+
+	// Story errors
+	ErrInvalidStoryAudio = errors.New("invalid story audio")
+
+	// File errors
+	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	ErrBannedMedia          = errors.New("media matches banned content")
+
+	// Chat errors
+	ErrInvalidChatInvite  = errors.New("invite link is invalid or has expired")
+	ErrChatMessageTooLong = errors.New("message is too long")
+	ErrChatTooManyLinks   = errors.New("message contains too many links")
+	ErrChatRateLimited    = errors.New("sending messages too fast")
+	ErrChatMuted          = errors.New("muted in this room for sending too many messages")
+
+	// Rate limit errors
+	ErrRateLimited = errors.New("too many requests")
+
+	// Short link errors
+	ErrShortLinkExpired = errors.New("short link has expired")
+
+	// Suspension errors, usually wrapped in a *SuspensionError
+	ErrAccountBanned = errors.New("account is suspended")
+	ErrAccountMuted  = errors.New("account is muted")
 )
 
 // NotFoundError represents a not found error with context
@@ -52,8 +94,76 @@ func NewNotFoundError(resource, id string) error {
 	}
 }
 
-// IsNotFoundError checks if the error is a NotFoundError
+// IsNotFoundError checks if the error is, or wraps, a NotFoundError
 func IsNotFoundError(err error) bool {
-	_, ok := err.(*NotFoundError)
-	return ok
+	var notFoundErr *NotFoundError
+	return errors.As(err, &notFoundErr)
+}
+
+// ErrorCode classifies an error for clients, whatever the transport
+type ErrorCode string
+
+// Error codes
+const (
+	CodeValidation    ErrorCode = "validation"
+	CodeUnauthorized  ErrorCode = "unauthorized"
+	CodeForbidden     ErrorCode = "forbidden"
+	CodeNotFound      ErrorCode = "not_found"
+	CodeConflict      ErrorCode = "conflict"
+	CodeGone          ErrorCode = "gone"
+	CodeTooLarge      ErrorCode = "too_large"
+	CodeUnprocessable ErrorCode = "unprocessable"
+	CodeRateLimited   ErrorCode = "rate_limited"
+	CodeInternal      ErrorCode = "internal"
+	CodeUnavailable   ErrorCode = "unavailable"
+)
+
+// Error is an error with a code and a message meant for clients. Fields holds
+// the problem with each invalid input field. The cause, if any, is matched by
+// errors.Is and errors.As.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Fields  map[string]string
+	Err     error
+}
+
+// Error returns the message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError creates an error with the code and message
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// NewValidationError reports invalid input, with the problem of each field when given.
+// It matches ErrInvalidInput.
+func NewValidationError(message string, fields map[string]string) error {
+	return &Error{Code: CodeValidation, Message: message, Fields: fields, Err: ErrInvalidInput}
+}
+
+// NewForbiddenError reports an action the user isn't allowed. It matches ErrForbidden.
+func NewForbiddenError(message string) error {
+	return &Error{Code: CodeForbidden, Message: message, Err: ErrForbidden}
+}
+
+// NewConflictError reports a clash with the current state. It matches ErrDuplicate.
+func NewConflictError(message string) error {
+	return &Error{Code: CodeConflict, Message: message, Err: ErrDuplicate}
+}
+
+// AsError returns the *Error in err's chain, or nil when there's none
+func AsError(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+	return nil
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeedAudience lists the authors whose posts a viewer reads in their feed
+type FeedAudience struct {
+	FollowingIDs []primitive.ObjectID `json:"followingIds"`
+	FriendIDs    []primitive.ObjectID `json:"friendIds"`
+}
+
+// FeedRepository caches feed audiences so the feed query does not reload the social graph on every page
+type FeedRepository interface {
+	// GetAudience returns nil, nil when the audience is not cached
+	GetAudience(userID primitive.ObjectID) (*FeedAudience, error)
+	SetAudience(userID primitive.ObjectID, audience *FeedAudience, ttl time.Duration) error
+}
+
+type FeedUseCase interface {
+	GetFeed(userID primitive.ObjectID, limit, offset int) ([]PostWithDetails, error)
+	CountFeed(userID primitive.ObjectID) (int64, error)
+}
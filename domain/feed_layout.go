@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Modules the home screen knows how to render
+const (
+	FeedModuleStoriesTray     = "stories_tray"
+	FeedModuleSuggestionsCard = "suggestions_card"
+	FeedModuleTrending        = "trending"
+	FeedModulePosts           = "posts" // the feed itself
+)
+
+// IsFeedModule reports whether the home screen can render the module type
+func IsFeedModule(moduleType string) bool {
+	switch moduleType {
+	case FeedModuleStoriesTray, FeedModuleSuggestionsCard, FeedModuleTrending, FeedModulePosts:
+		return true
+	}
+	return false
+}
+
+// FeedLayoutCacheTTL is how long the layout configuration is cached
+const FeedLayoutCacheTTL = time.Minute
+
+// FeedModule is one section of the home screen. A module with a Flag is only
+// shown to the users the flag is on for. Params are scalar settings passed to
+// the app as is, like {"afterPosts": 5} for a card placed between posts.
+type FeedModule struct {
+	Type   string                 `bson:"type" json:"type"`
+	Flag   string                 `bson:"flag,omitempty" json:"flag,omitempty"`
+	Params map[string]interface{} `bson:"params,omitempty" json:"params,omitempty"`
+}
+
+// FeatureFlag is on for RolloutPercent of the users, picked by a stable hash of
+// the user ID, so a user keeps seeing the same thing while the rollout holds
+type FeatureFlag struct {
+	Key            string `bson:"key" json:"key"`
+	Enabled        bool   `bson:"enabled" json:"enabled"`
+	RolloutPercent int    `bson:"rolloutPercent" json:"rolloutPercent"`
+}
+
+// ExperimentVariant is one arm of a layout experiment. Users in a variant with
+// modules get them instead of the default modules; a variant without modules
+// is a control group.
+type ExperimentVariant struct {
+	Name    string       `bson:"name" json:"name"`
+	Weight  int          `bson:"weight" json:"weight"`
+	Modules []FeedModule `bson:"modules,omitempty" json:"modules,omitempty"`
+}
+
+// LayoutExperiment splits users between its variants by weight, by a stable
+// hash of the user ID and the experiment key
+type LayoutExperiment struct {
+	Key      string              `bson:"key" json:"key"`
+	Enabled  bool                `bson:"enabled" json:"enabled"`
+	Variants []ExperimentVariant `bson:"variants" json:"variants"`
+}
+
+// FeedLayoutConfig is what admins edit to change the home screen composition
+type FeedLayoutConfig struct {
+	Modules     []FeedModule       `bson:"modules" json:"modules"`
+	Flags       []FeatureFlag      `bson:"flags" json:"flags"`
+	Experiments []LayoutExperiment `bson:"experiments" json:"experiments"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// DefaultFeedLayout is served until an admin configures the layout
+var DefaultFeedLayout = FeedLayoutConfig{
+	Modules: []FeedModule{
+		{Type: FeedModuleStoriesTray},
+		{Type: FeedModuleSuggestionsCard, Params: map[string]interface{}{"afterPosts": 3}},
+		{Type: FeedModulePosts},
+	},
+	Flags:       []FeatureFlag{},
+	Experiments: []LayoutExperiment{},
+}
+
+// FeedLayout is the home screen composition for one user, modules in display order
+type FeedLayout struct {
+	Modules []FeedModule `json:"modules"`
+	// Experiments maps each running experiment to the user's variant, for the app's analytics
+	Experiments map[string]string `json:"experiments"`
+	// Version changes whenever the configuration does, so apps can cache the layout
+	Version string `json:"version"`
+}
+
+type FeedLayoutRepository interface {
+	// Find returns nil, nil while the layout isn't configured
+	Find() (*FeedLayoutConfig, error)
+	Save(config *FeedLayoutConfig) error
+}
+
+type FeedLayoutUseCase interface {
+	GetLayout(userID primitive.ObjectID) (*FeedLayout, error)
+
+	// Layout management
+	GetConfig() (*FeedLayoutConfig, error)
+	UpdateConfig(config *FeedLayoutConfig) (*FeedLayoutConfig, error)
+}
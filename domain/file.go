@@ -1,13 +1,137 @@
 package domain
 
-import "mime/multipart"
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type File struct {
 	FileName    string 
 	FileURL     string 
 	ContentType string 
+	Size        int64
+	Duration    float64 // Duration in seconds for audio and video uploads
+	Context     string  // what the upload is for, one of the UploadContext values
+	// PerceptualHash of decodable images, for matching against banned media
+	PerceptualHash string
+	// ACL makes the file private to the users it lists, nil for public files
+	ACL *FileACL
+}
+
+// PrivateFilePrefix is where files with an ACL are stored. Storage rules must deny
+// reading it, so private files are only fetched through download links.
+const PrivateFilePrefix = "private/"
+
+// MaxPastedFileSize caps files uploaded as a raw request body, like pasted screenshots
+const MaxPastedFileSize = 4 * 1024 * 1024
+
+// FileDownloadTTL is how long a download link of a private file works
+const FileDownloadTTL = 15 * time.Minute
+
+// FileACL lets the owner, the listed users and the members of the listed chat
+// rooms download a private file
+type FileACL struct {
+	OwnerID primitive.ObjectID   `bson:"ownerId" json:"ownerId"`
+	UserIDs []primitive.ObjectID `bson:"userIds,omitempty" json:"userIds,omitempty"`
+	RoomIDs []string             `bson:"roomIds,omitempty" json:"roomIds,omitempty"`
+}
+
+// FileDownload is where to fetch a file from. Links of private files expire.
+type FileDownload struct {
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Upload contexts select how an uploaded image is processed
+const (
+	UploadContextDefault = "default" // uploads that don't name a context
+	UploadContextPost    = "post"
+	UploadContextComment = "comment"
+	UploadContextStory   = "story"
+	UploadContextChat    = "chat"
+	UploadContextAvatar  = "avatar"
+)
+
+// IsValidUploadContext reports whether clients may name the context on upload
+func IsValidUploadContext(context string) bool {
+	switch context {
+	case UploadContextPost, UploadContextComment, UploadContextStory, UploadContextChat, UploadContextAvatar:
+		return true
+	}
+	return false
+}
+
+// ImageProcessing controls what happens to an uploaded image before it is stored
+type ImageProcessing struct {
+	StripMetadata        bool // drop EXIF (including GPS), XMP and text metadata
+	NormalizeOrientation bool // rotate JPEG pixels to match the EXIF orientation
 }
 
 type FileRepository interface {
-	Upload(file *File, fileData multipart.File) (*File, error)
+	Upload(file *File, fileData io.Reader) (*File, error)
+	// UploadVariant stores a resized copy of the stored file, named by MediaVariantName
+	UploadVariant(original *File, width int, fileData io.Reader) (*File, error)
+	// FindByURL returns the stored file behind a download URL issued by Upload
+	FindByURL(url string) (*File, error)
+	Delete(fileName string) error
+	// SignedURL returns a link to the stored file that works until expires
+	SignedURL(fileName string, expires time.Time) (string, error)
+}
+
+// StoredFile records who uploaded a file, so storage can be accounted per user
+type StoredFile struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID `bson:"userId" json:"userId"`
+	FileName    string             `bson:"fileName" json:"fileName"`
+	FileURL     string             `bson:"fileUrl" json:"fileUrl"`
+	ContentType string             `bson:"contentType" json:"contentType"`
+	Size        int64              `bson:"size" json:"size"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	// BlobID is the shared object holding the content, unset for uploads from before deduplication
+	BlobID primitive.ObjectID `bson:"blobId,omitempty" json:"blobId,omitempty"`
+	// ReleasedAt is set while the content using the file is deleted. Released files don't count towards the quota.
+	ReleasedAt *time.Time `bson:"releasedAt,omitempty" json:"releasedAt,omitempty"`
+	// ACL is set for private files
+	ACL *FileACL `bson:"acl,omitempty" json:"acl,omitempty"`
+}
+
+// StorageUsage is the storage a user has used against their quota
+type StorageUsage struct {
+	UsedBytes  int64 `json:"usedBytes"`
+	QuotaBytes int64 `json:"quotaBytes"` // 0 means unlimited
+	FileCount  int64 `json:"fileCount"`
+}
+
+type StoredFileRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(file *StoredFile) error
+	FindByURL(userID primitive.ObjectID, url string) (*StoredFile, error)
+	// FindAllByURL returns every upload behind the URL, of any user
+	FindAllByURL(url string) ([]StoredFile, error)
+	// ShareWithRoom lets the room's members download the user's private file behind the URL
+	ShareWithRoom(userID primitive.ObjectID, url, roomID string) error
+	// SetSharedUsers replaces the users the user's private file behind the URL is shared with
+	SetSharedUsers(userID primitive.ObjectID, url string, userIDs []primitive.ObjectID) error
+	Delete(id primitive.ObjectID) error
+	// SetReleased marks the user's files behind the URLs as released, or counts them again
+	SetReleased(userID primitive.ObjectID, urls []string, released bool) error
+	// GetUsage sums the size of the user's files that aren't released
+	GetUsage(userID primitive.ObjectID) (usedBytes int64, fileCount int64, err error)
+}
+
+type FileUseCase interface {
+	// Upload stores the file for the user, failing with ErrStorageQuotaExceeded when it doesn't fit the quota
+	// Images are processed according to the upload context first. Files with an ACL
+	// and chat uploads are private to the user until shared.
+	Upload(userID primitive.ObjectID, file *File, fileData io.Reader) (*File, error)
+	DeleteFile(userID primitive.ObjectID, url string) error
+	GetStorageUsage(userID primitive.ObjectID) (*StorageUsage, error)
+	// GetDownload returns a short-lived link to a private file the user may
+	// download, and public files' URLs as they are
+	GetDownload(userID primitive.ObjectID, url string) (*FileDownload, error)
+	// ShareFile replaces the users the user's private file is shared with
+	ShareFile(userID primitive.ObjectID, url string, userIDs []string) error
 }
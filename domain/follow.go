@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -12,6 +14,12 @@ type Follow struct {
 	Status      string             `bson:"status" json:"status"` // active, blocked
 }
 
+// BlockedUser is an account the user blocked
+type BlockedUser struct {
+	User      SearchUser `json:"user"`
+	BlockedAt time.Time  `json:"blockedAt"`
+}
+
 // FollowRepository interface defines methods for follow persistence
 type FollowRepository interface {
 	Create(follow *Follow) error
@@ -22,6 +30,16 @@ type FollowRepository interface {
 	CountFollowers(userID primitive.ObjectID) (int64, error)
 	CountFollowing(userID primitive.ObjectID) (int64, error)
 	UpdateStatus(followerID, followingID primitive.ObjectID, status string) error
+	// FindRelatedIDs returns the accounts the user follows or has a block with either way
+	FindRelatedIDs(userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	// FindSecondDegree returns accounts followed by the accounts the user follows,
+	// most shared first, leaving out the excluded accounts
+	FindSecondDegree(userID primitive.ObjectID, exclude []primitive.ObjectID, limit int) ([]SecondDegreeFollow, error)
+	// FindBlockedIDs returns the accounts the user blocked or that blocked the user
+	FindBlockedIDs(userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	// FindBlocks lists the blocks the user made, latest first
+	FindBlocks(userID primitive.ObjectID, limit, offset int) ([]Follow, error)
+	CountBlocks(userID primitive.ObjectID) (int64, error)
 }
 
 // FollowUseCase interface defines business logic for follows
@@ -34,4 +52,11 @@ type FollowUseCase interface {
 	GetFollowing(userID primitive.ObjectID, limit, offset int) ([]Follow, error)
 	IsFollowing(followerID, followingID primitive.ObjectID) (bool, error)
 	IsBlocked(userID, blockedID primitive.ObjectID) (bool, error)
+	// GetBlockedIDs returns the accounts the user blocked or that blocked the user,
+	// whose content is hidden from the user
+	GetBlockedIDs(userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	ListBlocked(userID primitive.ObjectID, limit, offset int) ([]BlockedUser, int64, error)
+	// Who-to-follow
+	GetSuggestions(userID primitive.ObjectID, limit int) ([]FollowSuggestion, error)
+	DismissSuggestion(userID, suggestedID primitive.ObjectID) error
 }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Why an account is suggested, in order of preference
+const (
+	SuggestionReasonFollowedBy      = "followed_by"      // followed by accounts the user follows
+	SuggestionReasonPopularNearby   = "popular_nearby"   // popular around the user's location
+	SuggestionReasonSharedInterests = "shared_interests" // shares interests with the user
+)
+
+// Who-to-follow tuning
+const (
+	FollowSuggestionCacheTTL     = 30 * time.Minute
+	FollowSuggestionDismissTTL   = 90 * 24 * time.Hour
+	FollowSuggestionNearbyMeters = 50000
+	MaxFollowSuggestions         = 50
+)
+
+// FollowSuggestion is an account the user may want to follow, with why it was suggested
+type FollowSuggestion struct {
+	User           SearchUser `json:"user"`
+	FollowersCount int        `json:"followersCount"`
+	Reason         string     `json:"reason"`
+	Explanation    string     `json:"explanation"` // e.g. "Followed by alice and bob"
+	// MutualIDs are the followed accounts that follow the suggestion, for followed_by
+	MutualIDs []string `json:"mutualIds,omitempty"`
+}
+
+// SecondDegreeFollow is an account followed by FollowedBy, accounts the user follows
+type SecondDegreeFollow struct {
+	UserID     primitive.ObjectID   `bson:"_id"`
+	FollowedBy []primitive.ObjectID `bson:"followedBy"`
+	Count      int                  `bson:"count"`
+}
+
+// FollowSuggestionRepository keeps dismissed suggestions and caches computed ones
+type FollowSuggestionRepository interface {
+	// GetCached returns nil, nil when nothing is cached
+	GetCached(userID primitive.ObjectID) ([]FollowSuggestion, error)
+	SetCached(userID primitive.ObjectID, suggestions []FollowSuggestion, ttl time.Duration) error
+	ClearCached(userID primitive.ObjectID) error
+	Dismiss(userID, suggestedID primitive.ObjectID, ttl time.Duration) error
+	GetDismissed(userID primitive.ObjectID) ([]primitive.ObjectID, error)
+}
@@ -41,6 +41,9 @@ type FriendshipUseCase interface {
 	IsFriend(userID1, userID2 primitive.ObjectID) (bool, error)
 	GetFriendshipStatus(userID1, userID2 primitive.ObjectID) (string, error)
 	ListFriends(userID primitive.ObjectID, limit, offset int) ([]Friendship, error)
+	// ListUserFriends lists the user's friends to the viewer, as far as the user's
+	// privacy settings allow and neither blocked the other
+	ListUserFriends(viewerID, userID primitive.ObjectID, limit, offset int) ([]Friendship, error)
 	ListFriendRequests(userID primitive.ObjectID, limit, offset int) ([]Friendship, error)
 	RemoveFriend(userID, targetID primitive.ObjectID) error
 }
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Trending tags are counted in hourly buckets, so windows are rounded to whole hours
+const (
+	DefaultTrendingWindow = 24 * time.Hour
+	MaxTrendingWindow     = 7 * 24 * time.Hour
+)
+
+// Hashtag keeps the number of live posts using a tag
+type Hashtag struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Tag        string             `bson:"tag" json:"tag"`
+	PostCount  int64              `bson:"postCount" json:"postCount"`
+	LastUsedAt time.Time          `bson:"lastUsedAt" json:"lastUsedAt"`
+}
+
+// TrendingTag is a tag with the number of times it was used within the trending window
+type TrendingTag struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+type HashtagRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// IncrementPostCounts adds delta to the post count of each tag, creating missing tags
+	IncrementPostCounts(tags []string, delta int) error
+	// RecordUsage counts the tags in the trending bucket of the given time
+	RecordUsage(tags []string, at time.Time) error
+	FindTrending(window time.Duration, limit int) ([]TrendingTag, error)
+}
+
+type HashtagUseCase interface {
+	GetTagPosts(viewerID primitive.ObjectID, tag string, limit, offset int) ([]PostWithDetails, error)
+	CountTagPosts(viewerID primitive.ObjectID, tag string) (int64, error)
+	GetTrending(window time.Duration, limit int) ([]TrendingTag, error)
+}
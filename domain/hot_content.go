@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// HotContentPolicy decides when posts and users get so much engagement that they switch
+// to cheaper strategies, like aggregating notifications instead of sending one per event.
+// A zero threshold disables that switch.
+type HotContentPolicy struct {
+	PostEngagementThreshold int64         // reactions plus comments
+	UserFollowerThreshold   int64         // followers
+	AggregationWindow       time.Duration // how long events fold into the same notification
+}
+
+// PostEngagement is the number of reactions and comments on the post
+func PostEngagement(post *Post) int64 {
+	engagement := int64(post.CommentCount)
+	for _, count := range post.ReactionCounts {
+		engagement += int64(count)
+	}
+	return engagement
+}
+
+// IsHotPost reports whether the post is above the engagement threshold
+func (p HotContentPolicy) IsHotPost(post *Post) bool {
+	return p.PostEngagementThreshold > 0 && PostEngagement(post) >= p.PostEngagementThreshold
+}
+
+// IsHotUser reports whether a user with this many followers is above the follower threshold
+func (p HotContentPolicy) IsHotUser(followers int64) bool {
+	return p.UserFollowerThreshold > 0 && followers >= p.UserFollowerThreshold
+}
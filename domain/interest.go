@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultInterestLanguage is the label language used when the requested one is missing
+const DefaultInterestLanguage = "en"
+
+// Typeahead limits
+const (
+	DefaultInterestSearchLimit = 20
+	MaxInterestSearchLimit     = 50
+)
+
+// Interest is an entry of the managed interest taxonomy. Users store the slug.
+// Inactive interests are kept for existing users but no longer offered.
+type Interest struct {
+	BaseModel `bson:",inline"`
+	Slug      string            `bson:"slug" json:"slug"`
+	Category  string            `bson:"category" json:"category"`
+	Labels    map[string]string `bson:"labels" json:"labels"` // language code to label
+	// SearchTerms are the lowercased slug and labels, matched by typeahead and when resolving free text
+	SearchTerms []string `bson:"searchTerms" json:"-"`
+}
+
+// Label returns the label in lang, falling back to the default language and then the slug
+func (i *Interest) Label(lang string) string {
+	if label, ok := i.Labels[strings.ToLower(lang)]; ok && label != "" {
+		return label
+	}
+	if label, ok := i.Labels[DefaultInterestLanguage]; ok && label != "" {
+		return label
+	}
+	return i.Slug
+}
+
+// InterestOption is an interest localized for display
+type InterestOption struct {
+	Slug     string `json:"slug"`
+	Category string `json:"category"`
+	Label    string `json:"label"`
+}
+
+// InterestUpdate holds the editable fields of an interest. Nil fields are left unchanged,
+// Labels replaces every label when set.
+type InterestUpdate struct {
+	Category *string           `json:"category,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	IsActive *bool             `json:"isActive,omitempty"`
+}
+
+type InterestRepository interface {
+	Create(interest *Interest) error
+	Update(interest *Interest) error
+	Delete(id primitive.ObjectID) error
+	FindByID(id primitive.ObjectID) (*Interest, error)
+	// List returns the interests sorted by category and slug, an empty category lists all
+	List(category string, activeOnly bool) ([]Interest, error)
+	// Search returns active interests with a search term starting with prefix
+	Search(prefix, category string, limit int) ([]Interest, error)
+	// FindByTerms returns the active interests matching any of the lowercased terms
+	FindByTerms(terms []string) ([]Interest, error)
+}
+
+type InterestUseCase interface {
+	SearchInterests(query, category, lang string, limit int) ([]InterestOption, error)
+	// ResolveInterests maps slugs and labels to taxonomy slugs. Values outside the
+	// taxonomy are returned as legacy free text so they can be migrated later.
+	ResolveInterests(values []string) (slugs []string, legacy []string, err error)
+
+	// Taxonomy management
+	ListInterests(category string) ([]Interest, error)
+	CreateInterest(interest *Interest) error
+	UpdateInterest(id primitive.ObjectID, update InterestUpdate) (*Interest, error)
+	DeleteInterest(id primitive.ObjectID) error
+}
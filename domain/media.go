@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Uploads within this many bits of a banned perceptual hash are rejected
+const BannedMediaMaxDistance = 8
+
+// MediaBlob is a stored object shared by every upload with the same content.
+// The object is deleted when the last upload referencing it is.
+type MediaBlob struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Checksum    string             `bson:"checksum" json:"checksum"` // SHA-256 of the stored bytes
+	FileName    string             `bson:"fileName" json:"fileName"`
+	FileURL     string             `bson:"fileUrl" json:"fileUrl"`
+	ContentType string             `bson:"contentType" json:"contentType"`
+	Size        int64              `bson:"size" json:"size"`
+	// PerceptualHash is set for images the server can decode
+	PerceptualHash string `bson:"perceptualHash,omitempty" json:"perceptualHash,omitempty"`
+	// Width of images the server can decode, and the widths of the smaller
+	// variants stored next to them, narrowest first
+	Width         int       `bson:"width,omitempty" json:"width,omitempty"`
+	VariantWidths []int     `bson:"variantWidths,omitempty" json:"variantWidths,omitempty"`
+	RefCount      int64     `bson:"refCount" json:"refCount"`
+	CreatedAt     time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// MediaVariantWidths are the widths public JPEG and PNG images are scaled down
+// to on upload, so clients on slow or metered networks can fetch less
+var MediaVariantWidths = []int{320, 640, 1080}
+
+// MediaVariantName is where the variant of the stored object at the width is stored
+func MediaVariantName(fileName string, width int) string {
+	return fmt.Sprintf("variants/w%d/%s", width, fileName)
+}
+
+type MediaBlobRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create fails with ErrDuplicate when a blob with the same checksum exists
+	Create(blob *MediaBlob) error
+	// FindByChecksum and FindByURL return nil when there is no such blob
+	FindByChecksum(checksum string) (*MediaBlob, error)
+	FindByURL(url string) (*MediaBlob, error)
+	// FindByURLs returns the blobs behind the URLs, leaving out the URLs without one
+	FindByURLs(urls []string) ([]MediaBlob, error)
+	FindByID(id primitive.ObjectID) (*MediaBlob, error)
+	// AddReference adjusts the reference count and returns the updated blob
+	AddReference(id primitive.ObjectID, delta int64) (*MediaBlob, error)
+	Delete(id primitive.ObjectID) error
+}
+
+// BannedMedia is a perceptual hash of imagery that may not be uploaded
+type BannedMedia struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PerceptualHash string             `bson:"perceptualHash" json:"perceptualHash"`
+	Reason         string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy      primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+type BannedMediaRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create fails with ErrDuplicate when the hash is already banned
+	Create(media *BannedMedia) error
+	FindByID(id primitive.ObjectID) (*BannedMedia, error)
+	FindAll(limit, offset int) ([]BannedMedia, error)
+	// ListHashes returns every banned hash, for matching uploads
+	ListHashes() ([]string, error)
+	Delete(id primitive.ObjectID) error
+}
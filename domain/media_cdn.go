@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// MediaCDN serves stored files through CDN hosts. The storage URLs stay what's
+// stored; responses get CDN URLs and requests get the storage URLs back, so
+// clients use the URLs they're given as they are.
+type MediaCDN struct {
+	// Hosts maps storage bucket names to the CDN base URL serving the bucket
+	Hosts map[string]string
+	// CDN URLs are signed with the key when it's set, for CDNs that only serve
+	// signed URLs. Signatures last between SignedURLTTL and twice as long.
+	SigningKeyName string
+	SigningKey     []byte
+	SignedURLTTL   time.Duration
+}
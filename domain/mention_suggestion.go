@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mention autocomplete tuning
+const (
+	DefaultMentionSuggestions = 8
+	MaxMentionSuggestions     = 20
+	// Friends, room members and post participants are cached per scope. Rooms and
+	// posts change faster than friend lists, so their cache is shorter.
+	MentionFriendsCacheTTL = 5 * time.Minute
+	MentionContextCacheTTL = time.Minute
+	// Candidates loaded per scope, beyond that the cache would get slow to rank
+	MaxMentionCandidates = 500
+	// Latest interaction partners kept per user
+	MaxMentionInteractions = 200
+	MentionInteractionTTL  = 90 * 24 * time.Hour
+)
+
+// Where a mention suggestion comes from, in order of preference
+const (
+	MentionSourceContext = "context" // member of the room or participant of the post
+	MentionSourceFriend  = "friend"
+	MentionSourceOther   = "other" // any user whose username matches
+)
+
+// Contexts a mention is typed in, passed as "post:<id>" or "room:<id>"
+const (
+	MentionContextPost = "post"
+	MentionContextRoom = "room"
+)
+
+// MentionSuggestion is a user the current user may want to @mention
+type MentionSuggestion struct {
+	User   SearchUser `json:"user"`
+	Source string     `json:"source"`
+	// LastInteractionAt is when the two users last interacted, unset if never recently
+	LastInteractionAt *time.Time `json:"lastInteractionAt,omitempty"`
+}
+
+// MentionRepository caches mention candidates per scope and keeps who each user
+// interacted with lately, so suggestions are served from Redis alone
+type MentionRepository interface {
+	// GetCandidates returns nil, nil when the scope isn't cached
+	GetCandidates(scope string) ([]SearchUser, error)
+	SetCandidates(scope string, users []SearchUser, ttl time.Duration) error
+	// TouchInteraction records that the two users interacted at the time, for both of them
+	TouchInteraction(userID, otherID primitive.ObjectID, at time.Time) error
+	// GetInteractions returns when the user last interacted with each of the others,
+	// leaving out those they didn't interact with
+	GetInteractions(userID primitive.ObjectID, otherIDs []string) (map[string]time.Time, error)
+}
+
+type MentionUseCase interface {
+	// SuggestMentions completes the prefix with room members or post participants
+	// first when a context is given, then friends, then other users. Each group is
+	// ranked by how recently the user interacted with them.
+	SuggestMentions(userID primitive.ObjectID, prefix, context string, limit int) ([]MentionSuggestion, error)
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bulk moderation actions, each applied to every target of a job
+const (
+	ModerationActionDeleteComment  = "delete_comment"
+	ModerationActionDeletePost     = "delete_post" // soft delete, restorable from the admin API
+	ModerationActionBanUser        = "ban_user"
+	ModerationActionMuteUser       = "mute_user"
+	ModerationActionLiftSuspension = "lift_suspension"
+)
+
+// Moderation job statuses. A job completes once every item ran, even when some failed.
+const (
+	ModerationJobPending   = "pending"
+	ModerationJobRunning   = "running"
+	ModerationJobCompleted = "completed"
+)
+
+// Moderation item statuses
+const (
+	ModerationItemPending   = "pending"
+	ModerationItemSucceeded = "succeeded"
+	ModerationItemFailed    = "failed"
+)
+
+// Bulk moderation limits
+const (
+	MaxModerationJobItems = 500
+	// A running job not updated for this long is taken over by another worker
+	ModerationJobStaleAfter = 5 * time.Minute
+)
+
+// ModerationJobItem is the result of the job's action on one target
+type ModerationJobItem struct {
+	TargetID    string     `bson:"targetId" json:"targetId"`
+	Status      string     `bson:"status" json:"status"`
+	Error       string     `bson:"error,omitempty" json:"error,omitempty"`
+	ProcessedAt *time.Time `bson:"processedAt,omitempty" json:"processedAt,omitempty"`
+}
+
+// ModerationJob applies one moderation action to many targets in the background
+type ModerationJob struct {
+	BaseModel  `bson:",inline"`
+	CreatedBy  primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	Action     string              `bson:"action" json:"action"`
+	Reason     string              `bson:"reason,omitempty" json:"reason,omitempty"`
+	Until      *time.Time          `bson:"until,omitempty" json:"until,omitempty"` // ban and mute end
+	Status     string              `bson:"status" json:"status"`
+	Total      int                 `bson:"total" json:"total"`
+	Succeeded  int                 `bson:"succeeded" json:"succeeded"`
+	Failed     int                 `bson:"failed" json:"failed"`
+	Items      []ModerationJobItem `bson:"items" json:"items"`
+	StartedAt  *time.Time          `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	FinishedAt *time.Time          `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+}
+
+// ModerationJobRequest starts a bulk moderation job. Until or DurationHours
+// set the end of bans and mutes.
+type ModerationJobRequest struct {
+	Action        string     `json:"action"`
+	TargetIDs     []string   `json:"targetIds"`
+	Reason        string     `json:"reason"`
+	Until         *time.Time `json:"until,omitempty"`
+	DurationHours int        `json:"durationHours,omitempty"`
+}
+
+type ModerationJobRepository interface {
+	Create(job *ModerationJob) error
+	FindByID(id primitive.ObjectID) (*ModerationJob, error)
+	// FindAll lists jobs without their items, newest first
+	FindAll(limit, offset int) ([]ModerationJob, error)
+	// ClaimNext marks the oldest pending job, or a running job not updated since
+	// staleBefore, as running and returns it. It returns nil when there's none.
+	ClaimNext(staleBefore time.Time) (*ModerationJob, error)
+	// SetItemResult records the result of the item at index and bumps the job's counters
+	SetItemResult(id primitive.ObjectID, index int, item ModerationJobItem) error
+	Complete(id primitive.ObjectID) error
+	// CountPending counts the jobs waiting for a worker
+	CountPending() (int64, error)
+}
+
+type ModerationUseCase interface {
+	CreateJob(adminID primitive.ObjectID, req ModerationJobRequest) (*ModerationJob, error)
+	GetJob(id primitive.ObjectID) (*ModerationJob, error)
+	ListJobs(limit, offset int) ([]ModerationJob, error)
+	// RunNextJob runs one claimable job and reports false when there was none
+	RunNextJob() (bool, error)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Radius of a nearby search in meters
+const (
+	DefaultNearbyRadiusMeters = 5000
+	MaxNearbyRadiusMeters     = 50000
+)
+
+// GeoPointType is the GeoJSON type of the locations nearby searches match
+const GeoPointType = "Point"
+
+// PostWithDistance is a post found by a nearby search, Distance in meters
+type PostWithDistance struct {
+	Post     `bson:",inline"`
+	Distance float64 `bson:"distance"`
+}
+
+// UserWithDistance is a user found by a nearby search, Distance in meters
+type UserWithDistance struct {
+	User     `bson:",inline"`
+	Distance float64 `bson:"distance"`
+}
+
+// NearbyUser is a user near the viewer. The distance is rounded up to whole
+// kilometers so users can't be located by searching from a few points.
+type NearbyUser struct {
+	User       SearchUser `json:"user"`
+	DistanceKm float64    `json:"distanceKm"`
+}
+
+type NearbyUseCase interface {
+	// GetNearbyPosts lists the posts tagged within radius meters of the point the viewer may see, closest first
+	GetNearbyPosts(viewerID primitive.ObjectID, lat, lng, radius float64, limit, offset int) ([]PostWithDetails, error)
+	// GetNearbyUsers lists the users whose location is within radius meters of the point, closest first
+	GetNearbyUsers(viewerID primitive.ObjectID, lat, lng, radius float64, limit, offset int) ([]NearbyUser, error)
+}
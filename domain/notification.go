@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -13,6 +15,9 @@ const (
 	NotificationTypeFollow     NotificationType = "follow"
 	NotificationTypeFriendReq  NotificationType = "friend_request"
 	NotificationTypeMention    NotificationType = "mention"
+	NotificationTypeShare      NotificationType = "share"
+	NotificationTypeAccount    NotificationType = "account" // account status changes such as suspensions
+	NotificationTypeDigest     NotificationType = "digest"  // the opt-in trending digest
 )
 
 // Notification represents a notification entity
@@ -25,6 +30,11 @@ type Notification struct {
 	RefType      string             `bson:"refType" json:"refType"`        // Reference type (e.g., "post", "comment")
 	Message      string             `bson:"message" json:"message"`
 	IsRead       bool               `bson:"isRead" json:"isRead"`
+	// AggregateCount is how many events were folded into the notification, for hot posts and users
+	AggregateCount int `bson:"aggregateCount,omitempty" json:"aggregateCount,omitempty"`
+	// Stale is set when the referenced post or comment no longer exists, so clients
+	// can show the notification without deep-linking to a broken screen
+	Stale bool `bson:"stale,omitempty" json:"stale,omitempty"`
 }
 
 // NotificationResponse represents a notification with sender information
@@ -40,6 +50,13 @@ type NotificationResponse struct {
 	} `json:"sender"`
 }
 
+// NotificationReadEvent is pushed to all of the recipient's devices when notifications are marked read
+type NotificationReadEvent struct {
+	NotificationIDs []string `json:"notificationIds,omitempty"`
+	All             bool     `json:"all"` // every notification was marked read
+	UnreadCount     int64    `json:"unreadCount"`
+}
+
 // NotificationRepository interface
 type NotificationRepository interface {
 	Create(notification *Notification) error
@@ -50,6 +67,14 @@ type NotificationRepository interface {
 	MarkAsRead(notificationID primitive.ObjectID) error
 	MarkAllAsRead(recipientID primitive.ObjectID) error
 	CountUnread(recipientID primitive.ObjectID) (int64, error)
+	CountByRecipient(recipientID primitive.ObjectID) (int64, error)
+	// FindAggregate returns the latest unread notification of the type about the ref created since the time,
+	// or nil, nil when there is none
+	FindAggregate(recipientID, refID primitive.ObjectID, nType NotificationType, since time.Time) (*Notification, error)
+	// AddToAggregate folds one more event by the sender into the notification
+	AddToAggregate(id, senderID primitive.ObjectID, message string) (*Notification, error)
+	// SetStale flags or unflags the notifications as referencing deleted content
+	SetStale(ids []primitive.ObjectID, stale bool) error
 }
 
 // NotificationUseCase interface
@@ -57,8 +82,12 @@ type NotificationUseCase interface {
 	CreateNotification(recipientID, senderID, refID primitive.ObjectID, nType NotificationType, refType, message string) (*Notification, error)
 	GetNotification(notificationID primitive.ObjectID) (*NotificationResponse, error)
 	ListNotifications(recipientID primitive.ObjectID, limit, offset int) ([]NotificationResponse, error)
-	MarkAsRead(notificationID primitive.ObjectID) error
+	MarkAsRead(recipientID, notificationID primitive.ObjectID) error
 	MarkAllAsRead(recipientID primitive.ObjectID) error
-	DeleteNotification(notificationID primitive.ObjectID) error
+	DeleteNotification(userID, notificationID primitive.ObjectID) error
 	GetUnreadCount(recipientID primitive.ObjectID) (int64, error)
+	CountNotifications(recipientID primitive.ObjectID) (int64, error)
+	// CreateAggregatedNotification folds the event into a recent unread notification of the same
+	// type about the same ref, so hot posts and users don't notify once per event
+	CreateAggregatedNotification(recipientID, senderID, refID primitive.ObjectID, nType NotificationType, refType, message string, window time.Duration) (*Notification, error)
 }
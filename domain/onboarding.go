@@ -0,0 +1,101 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Onboarding predicates, evaluated server-side against the user's profile and activity.
+// Count predicates are done once the count reaches the step's target.
+const (
+	OnboardingHasAvatar      = "has_avatar"
+	OnboardingHasBio         = "has_bio"
+	OnboardingHasCover       = "has_cover"
+	OnboardingHasInterests   = "has_interests"
+	OnboardingEmailVerified  = "email_verified"
+	OnboardingFollowingCount = "following_count"
+	OnboardingFriendsCount   = "friends_count"
+	OnboardingPostCount      = "post_count"
+)
+
+// IsOnboardingCountPredicate reports whether the predicate compares a count against a target
+func IsOnboardingCountPredicate(predicate string) bool {
+	switch predicate {
+	case OnboardingFollowingCount, OnboardingFriendsCount, OnboardingPostCount:
+		return true
+	}
+	return false
+}
+
+// IsValidOnboardingPredicate reports whether the server knows how to evaluate the predicate
+func IsValidOnboardingPredicate(predicate string) bool {
+	switch predicate {
+	case OnboardingHasAvatar, OnboardingHasBio, OnboardingHasCover, OnboardingHasInterests, OnboardingEmailVerified:
+		return true
+	}
+	return IsOnboardingCountPredicate(predicate)
+}
+
+// OnboardingStep is an admin-configured step of the onboarding checklist.
+// Inactive steps are kept but not shown to users.
+type OnboardingStep struct {
+	BaseModel   `bson:",inline"`
+	Key         string `bson:"key" json:"key"`
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+	Predicate   string `bson:"predicate" json:"predicate"`
+	Target      int    `bson:"target,omitempty" json:"target,omitempty"` // for count predicates
+	Order       int    `bson:"order" json:"order"`
+}
+
+// DefaultOnboardingSteps are shown until an admin configures the checklist
+var DefaultOnboardingSteps = []OnboardingStep{
+	{Key: "avatar", Title: "Add a profile photo", Predicate: OnboardingHasAvatar, Order: 1},
+	{Key: "bio", Title: "Write a short bio", Predicate: OnboardingHasBio, Order: 2},
+	{Key: "follow", Title: "Follow 3 people", Predicate: OnboardingFollowingCount, Target: 3, Order: 3},
+	{Key: "first_post", Title: "Share your first post", Predicate: OnboardingPostCount, Target: 1, Order: 4},
+}
+
+// OnboardingStepUpdate holds the editable fields of a step. Nil fields are left unchanged.
+type OnboardingStepUpdate struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Predicate   *string `json:"predicate,omitempty"`
+	Target      *int    `json:"target,omitempty"`
+	Order       *int    `json:"order,omitempty"`
+	IsActive    *bool   `json:"isActive,omitempty"`
+}
+
+// OnboardingStepProgress is a checklist step evaluated for a user
+type OnboardingStepProgress struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Target      int    `json:"target,omitempty"`
+	Progress    int    `json:"progress,omitempty"` // current count for count predicates
+	Done        bool   `json:"done"`
+}
+
+// OnboardingChecklist is the user's progress through the onboarding steps, in order
+type OnboardingChecklist struct {
+	Steps     []OnboardingStepProgress `json:"steps"`
+	Completed int                      `json:"completed"`
+	Total     int                      `json:"total"`
+	Done      bool                     `json:"done"`
+}
+
+type OnboardingStepRepository interface {
+	Create(step *OnboardingStep) error
+	Update(step *OnboardingStep) error
+	Delete(id primitive.ObjectID) error
+	FindByID(id primitive.ObjectID) (*OnboardingStep, error)
+	// List returns the steps sorted by order, only the active ones when activeOnly is set
+	List(activeOnly bool) ([]OnboardingStep, error)
+}
+
+type OnboardingUseCase interface {
+	GetChecklist(userID primitive.ObjectID) (*OnboardingChecklist, error)
+
+	// Checklist management
+	ListSteps() ([]OnboardingStep, error)
+	CreateStep(step *OnboardingStep) error
+	UpdateStep(id primitive.ObjectID, update OnboardingStepUpdate) (*OnboardingStep, error)
+	DeleteStep(id primitive.ObjectID) error
+}
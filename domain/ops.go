@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Operational event types streamed to admins on the ops WebSocket feed
+const (
+	OpsEventSnapshot          = "snapshot"          // counters and connections of one instance, every monitor tick
+	OpsEventErrorSpike        = "errorSpike"        // an instance answered far more 5xx than usual
+	OpsEventModerationBacklog = "moderationBacklog" // pending moderation jobs reached OpsModerationBacklogThreshold
+)
+
+// Ops monitor thresholds
+const (
+	// An interval needs at least this many server errors to count as a spike
+	OpsErrorSpikeMin = 20
+	// and this many times the average of the last OpsErrorSpikeWindow intervals
+	OpsErrorSpikeFactor = 3
+	OpsErrorSpikeWindow = 6
+	// Pending moderation jobs that raise a backlog alert, re-armed once the queue drops below
+	OpsModerationBacklogThreshold = 50
+)
+
+// OpsEvent is one frame of the ops feed
+type OpsEvent struct {
+	Type     string      `json:"type"`
+	Instance string      `json:"instance"` // API instance the event comes from, set by the feed
+	Data     interface{} `json:"data"`
+	At       time.Time   `json:"at"`
+}
+
+// OpsConnections counts the WebSocket connections of an instance
+type OpsConnections struct {
+	Connections int `json:"connections"`
+	Users       int `json:"users"`
+	OpsClients  int `json:"opsClients"`
+}
+
+// OpsSnapshot is the data of a snapshot event
+type OpsSnapshot struct {
+	Connections       OpsConnections   `json:"connections"`
+	Goroutines        int              `json:"goroutines"`
+	Counters          map[string]int64 `json:"counters"` // how much each process counter grew since the last snapshot
+	ModerationPending int64            `json:"moderationPending"`
+}
+
+// OpsErrorSpike is the data of an errorSpike event
+type OpsErrorSpike struct {
+	Errors          int64   `json:"errors"`
+	Average         float64 `json:"average"`
+	IntervalSeconds float64 `json:"intervalSeconds"`
+}
+
+// OpsModerationBacklog is the data of a moderationBacklog event
+type OpsModerationBacklog struct {
+	Pending   int64 `json:"pending"`
+	Threshold int64 `json:"threshold"`
+}
+
+// OpsFeed streams operational events to the admins watching the ops feed on any instance
+type OpsFeed interface {
+	PublishOps(event OpsEvent) error
+	// ConnectionStats counts the connections of this instance
+	ConnectionStats() OpsConnections
+}
@@ -0,0 +1,13 @@
+package domain
+
+// MaxApproxCount caps how many documents are counted for TotalApprox.
+// Past this value the total is reported as the cap, which keeps counts cheap on large collections.
+const MaxApproxCount = 10000
+
+// Page is the standard envelope for paginated list endpoints
+type Page struct {
+	Items       interface{} `json:"items"`
+	NextCursor  string      `json:"nextCursor,omitempty"`
+	HasMore     bool        `json:"hasMore"`
+	TotalApprox int64       `json:"totalApprox"`
+}
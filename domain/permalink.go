@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What a share link points at
+const (
+	PermalinkPost    = "post"
+	PermalinkComment = "comment"
+	PermalinkUser    = "user"
+	PermalinkRoom    = "room"
+)
+
+// Permalink is a share link resolved into a typed reference the app can route to
+type Permalink struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// PostID is the post of a comment
+	PostID   string `json:"postId,omitempty"`
+	Username string `json:"username,omitempty"`
+	// InviteToken is set for room invite links, the viewer joins with it
+	InviteToken string `json:"inviteToken,omitempty"`
+	IsMember    bool   `json:"isMember,omitempty"` // rooms only
+}
+
+type PermalinkUseCase interface {
+	// Resolve parses a share link and checks the viewer may open what it points at.
+	// Links to missing content return a not found error, content the viewer can't
+	// see returns ErrForbidden.
+	Resolve(viewerID primitive.ObjectID, rawURL string) (*Permalink, error)
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,35 +9,42 @@ import (
 
 type Post struct {
 	BaseModel      `bson:",inline"`
-	UserID         primitive.ObjectID `bson:"userId" json:"userId"`
-	Content        string             `bson:"content" json:"content"`
-	Media          []Media            `bson:"media" json:"media"`
-	ReactionCounts map[string]int     `bson:"reactionCounts" json:"reactionCounts"`
-	CommentCount   int                `bson:"commentCount" json:"commentCount"`
-	SubPostCount   int                `bson:"subPostCount" json:"subPostCount"`
-	Tags           []string           `bson:"tags" json:"tags"`
-	Location       *Location          `bson:"location,omitempty" json:"location,omitempty"`
-	Visibility     string             `bson:"visibility" json:"visibility"`
-	ShareCount     int                `bson:"shareCount" json:"shareCount"`
-	ViewCount      int                `bson:"viewCount" json:"viewCount"`
-	IsEdited       bool               `bson:"isEdited" json:"isEdited"`
-	EditHistory    []EditLog          `bson:"editHistory" json:"editHistory"`
-	AllowComments  bool               `bson:"allowComments" json:"allowComments"`
-	AllowReactions bool               `bson:"allowReactions" json:"allowReactions"`
-	PostType       string             `bson:"postType" json:"postType"`
+	UserID         primitive.ObjectID   `bson:"userId" json:"userId"`
+	Content        string               `bson:"content" json:"content"`
+	Media          []Media              `bson:"media" json:"media"`
+	ReactionCounts map[string]int       `bson:"reactionCounts" json:"reactionCounts"`
+	CommentCount   int                  `bson:"commentCount" json:"commentCount"`
+	SubPostCount   int                  `bson:"subPostCount" json:"subPostCount"`
+	Tags           []string             `bson:"tags" json:"tags"`
+	Mentions       []primitive.ObjectID `bson:"mentions" json:"mentions,omitempty"` // users @mentioned in the content
+	Location       *Location            `bson:"location,omitempty" json:"location,omitempty"`
+	Visibility     string               `bson:"visibility" json:"visibility"`
+	ShareCount     int                  `bson:"shareCount" json:"shareCount"`
+	ViewCount      int                  `bson:"viewCount" json:"viewCount"`
+	IsEdited       bool                 `bson:"isEdited" json:"isEdited"`
+	EditHistory    []EditLog            `bson:"editHistory" json:"editHistory"`
+	AllowComments  bool                 `bson:"allowComments" json:"allowComments"`
+	AllowReactions bool                 `bson:"allowReactions" json:"allowReactions"`
+	PostType       string               `bson:"postType" json:"postType"`
+	SharedPostID   *primitive.ObjectID  `bson:"sharedPostId,omitempty" json:"sharedPostId,omitempty"` // set on shares
+	// Pinned posts are listed first on the author's profile, the latest pinned first
+	IsPinned bool       `bson:"isPinned" json:"isPinned"`
+	PinnedAt *time.Time `bson:"pinnedAt" json:"pinnedAt,omitempty"`
 }
 
 type SubPost struct {
 	BaseModel      `bson:",inline"`
-	ParentID       primitive.ObjectID `bson:"parentId" json:"parentId"`
-	UserID         primitive.ObjectID `bson:"userId" json:"userId"`
-	Content        string             `bson:"content" json:"content"`
-	Media          []Media            `bson:"media" json:"media"`
-	ReactionCounts map[string]int     `bson:"reactionCounts" json:"reactionCounts"`
-	CommentCount   int                `bson:"commentCount" json:"commentCount"`
-	Order          int                `bson:"order" json:"order"`
-	AllowComments  bool               `bson:"allowComments" json:"allowComments"`
-	AllowReactions bool               `bson:"allowReactions" json:"allowReactions"`
+	ParentID       primitive.ObjectID   `bson:"parentId" json:"parentId"`
+	UserID         primitive.ObjectID   `bson:"userId" json:"userId"`
+	Content        string               `bson:"content" json:"content"`
+	Media          []Media              `bson:"media" json:"media"`
+	ReactionCounts map[string]int       `bson:"reactionCounts" json:"reactionCounts"`
+	CommentCount   int                  `bson:"commentCount" json:"commentCount"`
+	Mentions       []primitive.ObjectID `bson:"mentions" json:"mentions,omitempty"`
+	Order          int                  `bson:"order" json:"order"`
+	Visibility     string               `bson:"visibility" json:"visibility"` // copied from the parent post
+	AllowComments  bool                 `bson:"allowComments" json:"allowComments"`
+	AllowReactions bool                 `bson:"allowReactions" json:"allowReactions"`
 }
 
 type Media struct {
@@ -63,6 +71,14 @@ type EditLog struct {
 	EditedAt time.Time `bson:"editedAt" json:"editedAt"`
 }
 
+// PostEditLock marks a post as being edited by one session so concurrent edits don't overwrite each other
+type PostEditLock struct {
+	PostID    primitive.ObjectID `json:"postId"`
+	UserID    primitive.ObjectID `json:"userId"`
+	Token     string             `json:"lockToken"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+}
+
 type SubPostInput struct {
 	Content string  `json:"content"`
 	Media   []Media `json:"media,omitempty"`
@@ -74,13 +90,77 @@ const (
 	MediaTypeVideo = "video"
 )
 
+// Hot post counters. Increments are buffered in Redis, merged into posts when read
+// and flushed to the post documents in the background.
+const (
+	PostCounterComments = "commentCount"
+	PostCounterViews    = "viewCount"
+)
+
+// PostReactionCounter is the counter of the reaction type
+func PostReactionCounter(reactionType string) string {
+	return "reactionCounts." + reactionType
+}
+
+// PostTypeShare marks a post that shares another post, with Content as the optional quote
+const PostTypeShare = "share"
+
+// MaxPinnedPosts is how many posts a user can pin to their profile
+const MaxPinnedPosts = 3
+
+// Post visibility. Posts stored without a visibility are treated as public.
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+	VisibilityPrivate = "private"
+)
+
 // Repository interface
 type PostRepository interface {
+	// EnsureIndexes creates the geo index nearby searches need
+	EnsureIndexes(ctx context.Context) error
 	Create(post *Post) error
 	Update(post *Post) error
 	Delete(id primitive.ObjectID) error
 	FindByID(id primitive.ObjectID) (*Post, error)
-	FindByUserID(userID primitive.ObjectID, limit, offset int, hasMedia bool, mediaType string) ([]Post, error)
+	// FindByUserID and CountByUserID only match posts with one of visibilities, or any post when it's nil.
+	// FindByUserID lists pinned posts first.
+	FindByUserID(userID primitive.ObjectID, visibilities []string, limit, offset int, hasMedia bool, mediaType string) ([]Post, error)
+	FindDeleted(since time.Time, limit, offset int) ([]Post, error)
+	FindDeletedByID(id primitive.ObjectID) (*Post, error)
+	Restore(id primitive.ObjectID) error
+	CountByUserID(userID primitive.ObjectID, visibilities []string, hasMedia bool, mediaType string) (int64, error)
+	CountPinnedByUserID(userID primitive.ObjectID) (int64, error)
+	// DeleteByUserID removes all the user's posts for good, soft deleted ones included,
+	// and returns their IDs
+	DeleteByUserID(userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	FindByIDs(ids []primitive.ObjectID) ([]Post, error)
+	FindFeed(viewerID primitive.ObjectID, audience *FeedAudience, limit, offset int) ([]Post, error)
+	CountFeed(viewerID primitive.ObjectID, audience *FeedAudience) (int64, error)
+	// FindByTag returns public posts with the tag, plus the viewer's own
+	FindByTag(viewerID primitive.ObjectID, tag string, limit, offset int) ([]Post, error)
+	CountByTag(viewerID primitive.ObjectID, tag string) (int64, error)
+	// FindPopularSince returns the public posts created since the time that have one
+	// of the tags or are by one of the authors, most reactions and comments first,
+	// leaving out the excluded authors
+	FindPopularSince(tags []string, authorIDs, excludeAuthors []primitive.ObjectID, since time.Time, limit int) ([]Post, error)
+	// FindNearby returns public posts, plus the viewer's own, located within radius
+	// meters of the coordinates, closest first
+	FindNearby(viewerID primitive.ObjectID, coordinates []float64, radiusMeters float64, limit, offset int) ([]PostWithDistance, error)
+	IncrementShareCount(id primitive.ObjectID) error
+	// IncrementCounter buffers a change to a hot counter, see PostCounterComments
+	IncrementCounter(id primitive.ObjectID, counter string, delta int64) error
+	// FlushCounters writes buffered counter changes of up to limit posts to the post documents
+	// and returns how many posts were flushed
+	FlushCounters(limit int) (int, error)
+	// AddViewer records the viewer of the post on the day and reports whether it's
+	// their first view that day
+	AddViewer(id, viewerID primitive.ObjectID, day time.Time) (bool, error)
+	// AcquireEditLock takes the lock, or extends it when lock.Token already holds it. It returns false if another token holds it.
+	AcquireEditLock(lock *PostEditLock, ttl time.Duration) (bool, error)
+	// GetEditLock returns nil, nil when the post is not locked
+	GetEditLock(postID primitive.ObjectID) (*PostEditLock, error)
+	ReleaseEditLock(postID primitive.ObjectID, token string) error
 }
 
 type SubPostRepository interface {
@@ -90,23 +170,43 @@ type SubPostRepository interface {
 	FindByID(id primitive.ObjectID) (*SubPost, error)
 	FindByParentID(parentID primitive.ObjectID, limit, offset int) ([]SubPost, error)
 	UpdateOrder(parentID primitive.ObjectID, orders map[primitive.ObjectID]int) error
+	UpdateVisibilityByParentID(parentID primitive.ObjectID, visibility string) error
+	// SoftDeleteByParentID soft deletes the subposts of a deleted parent post
+	SoftDeleteByParentID(parentID primitive.ObjectID) error
+	// RestoreByParentID restores only the subposts that were deleted together with the parent
+	RestoreByParentID(parentID primitive.ObjectID) error
+	// DeleteByParentID removes all the subposts of a post for good
+	DeleteByParentID(parentID primitive.ObjectID) error
 }
 
 // UseCase interface
 type PostUseCase interface {
 	CreatePost(userID primitive.ObjectID, content string, media []Media, tags []string, location *Location, visibility string, subPosts []SubPostInput) (*Post, error)
-	UpdatePost(postID primitive.ObjectID, content string, media []Media, tags []string, location *Location, visibility string) (*Post, error)
-	DeletePost(postID primitive.ObjectID) error
-	GetPost(postID primitive.ObjectID, includeSubPosts bool) (*PostWithDetails, error)
-	ListPosts(userID primitive.ObjectID, limit, offset int, includeSubPosts bool, hasMedia bool, mediaType string) ([]PostWithDetails, error)
+	UpdatePost(userID, postID primitive.ObjectID, lockToken string, content string, media []Media, tags []string, location *Location, visibility string) (*Post, error)
+	LockPostForEdit(userID, postID primitive.ObjectID, lockToken string) (*PostEditLock, error)
+	UnlockPost(userID, postID primitive.ObjectID, lockToken string) error
+	DeletePost(userID, postID primitive.ObjectID) error
+	SharePost(userID, postID primitive.ObjectID, quote, visibility string) (*Post, error)
+	// RecordView counts a view of the post, once per viewer per day. The author's own
+	// views aren't counted. It reports whether the view was counted.
+	RecordView(viewerID, postID primitive.ObjectID) (bool, error)
+	// PinPost pins a post to its author's profile, at most MaxPinnedPosts of them
+	PinPost(userID, postID primitive.ObjectID) (*Post, error)
+	UnpinPost(userID, postID primitive.ObjectID) (*Post, error)
+	// GetPost, ListPosts, CountPosts and GetPostsByIDs only return what the viewer may
+	// see. Posts hidden from the viewer are reported as not found.
+	GetPost(viewerID, postID primitive.ObjectID, includeSubPosts bool) (*PostWithDetails, error)
+	ListPosts(viewerID, userID primitive.ObjectID, limit, offset int, includeSubPosts bool, hasMedia bool, mediaType string) ([]PostWithDetails, error)
+	CountPosts(viewerID, userID primitive.ObjectID, hasMedia bool, mediaType string) (int64, error)
+	GetPostsByIDs(viewerID primitive.ObjectID, ids []string) ([]BatchItem, error)
 }
 
 type SubPostUseCase interface {
 	CreateSubPost(parentID, userID primitive.ObjectID, content string, media []Media, order int) (*SubPost, error)
 	UpdateSubPost(subPostID primitive.ObjectID, content string, media []Media) (*SubPost, error)
 	DeleteSubPost(subPostID primitive.ObjectID) error
-	GetSubPost(subPostID primitive.ObjectID) (*SubPost, error)
-	ListSubPosts(parentID primitive.ObjectID, limit, offset int) ([]SubPost, error)
+	GetSubPost(viewerID, subPostID primitive.ObjectID) (*SubPost, error)
+	ListSubPosts(viewerID, parentID primitive.ObjectID, limit, offset int) ([]SubPost, error)
 	ReorderSubPosts(parentID primitive.ObjectID, orders map[primitive.ObjectID]int) error
 }
 
@@ -125,4 +225,5 @@ type PostWithDetails struct {
 	*Post
 	User     *PostUser `json:"user"`
 	SubPosts []SubPost `json:"subPosts,omitempty"`
+	Distance *float64  `json:"distance,omitempty"` // meters, on nearby results
 }
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxPostDrafts is how many drafts a user can keep
+const MaxPostDrafts = 100
+
+// PostDraft is a post being written. Drafts live apart from posts, so feeds and
+// profiles never see them, and are only validated when published.
+type PostDraft struct {
+	BaseModel  `bson:",inline"`
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	Content    string             `bson:"content" json:"content"`
+	Media      []Media            `bson:"media" json:"media"`
+	Tags       []string           `bson:"tags" json:"tags"`
+	Location   *Location          `bson:"location,omitempty" json:"location,omitempty"`
+	Visibility string             `bson:"visibility" json:"visibility"`
+	SubPosts   []SubPostInput     `bson:"subPosts" json:"subPosts"`
+}
+
+// PostDraftRequest creates or replaces a draft, every field may still be empty
+type PostDraftRequest struct {
+	Content    string         `json:"content"`
+	Media      []Media        `json:"media,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Location   *Location      `json:"location,omitempty"`
+	Visibility string         `json:"visibility"`
+	SubPosts   []SubPostInput `json:"subPosts,omitempty"`
+}
+
+type PostDraftRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(draft *PostDraft) error
+	Update(draft *PostDraft) error
+	FindByID(id primitive.ObjectID) (*PostDraft, error)
+	// FindByUserID lists the user's drafts, last edited first
+	FindByUserID(userID primitive.ObjectID, limit, offset int) ([]PostDraft, error)
+	CountByUserID(userID primitive.ObjectID) (int64, error)
+	Delete(id primitive.ObjectID) error
+}
+
+type PostDraftUseCase interface {
+	CreateDraft(userID primitive.ObjectID, req PostDraftRequest) (*PostDraft, error)
+	ListDrafts(userID primitive.ObjectID, limit, offset int) ([]PostDraft, int64, error)
+	GetDraft(userID, draftID primitive.ObjectID) (*PostDraft, error)
+	UpdateDraft(userID, draftID primitive.ObjectID, req PostDraftRequest) (*PostDraft, error)
+	// PublishDraft creates the post the draft holds and deletes the draft
+	PublishDraft(userID, draftID primitive.ObjectID) (*Post, error)
+	DeleteDraft(userID, draftID primitive.ObjectID) error
+}
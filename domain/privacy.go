@@ -0,0 +1,57 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Who a privacy setting lets through
+const (
+	PrivacyEveryone = "everyone"
+	PrivacyFriends  = "friends"
+	PrivacyNobody   = "nobody"
+)
+
+// PrivacySettings controls who can reach the user and what they can see. Unset
+// audiences mean everyone, so users who never changed them behave as before.
+type PrivacySettings struct {
+	// WhoCanMessage is who can open a private chat with the user or message them in one
+	WhoCanMessage string `bson:"whoCanMessage,omitempty" json:"whoCanMessage"`
+	// WhoCanSeeFriends is who can list the user's friends
+	WhoCanSeeFriends string `bson:"whoCanSeeFriends,omitempty" json:"whoCanSeeFriends"`
+	// WhoCanComment is who can comment on the user's posts
+	WhoCanComment string `bson:"whoCanComment,omitempty" json:"whoCanComment"`
+	// SearchableByEmail and SearchableByPhone let others find the user by typing
+	// their exact email or phone number in search
+	SearchableByEmail bool `bson:"searchableByEmail" json:"searchableByEmail"`
+	SearchableByPhone bool `bson:"searchableByPhone" json:"searchableByPhone"`
+}
+
+// WithDefaults fills the unset audiences with everyone
+func (p PrivacySettings) WithDefaults() PrivacySettings {
+	for _, audience := range []*string{&p.WhoCanMessage, &p.WhoCanSeeFriends, &p.WhoCanComment} {
+		if *audience == "" {
+			*audience = PrivacyEveryone
+		}
+	}
+	return p
+}
+
+// IsPrivacyAudience reports whether the value is a known audience
+func IsPrivacyAudience(audience string) bool {
+	switch audience {
+	case PrivacyEveryone, PrivacyFriends, PrivacyNobody:
+		return true
+	}
+	return false
+}
+
+type PrivacyUseCase interface {
+	GetPrivacy(userID primitive.ObjectID) (*PrivacySettings, error)
+	// UpdatePrivacy replaces the user's privacy settings
+	UpdatePrivacy(userID primitive.ObjectID, settings PrivacySettings) (*PrivacySettings, error)
+
+	// CheckCanMessage, CheckCanComment and CheckCanSeeFriends reject with
+	// ErrPrivacyRestricted when the owner's settings leave the viewer out. Owners
+	// are always let through.
+	CheckCanMessage(senderID, recipientID primitive.ObjectID) error
+	CheckCanComment(userID, authorID primitive.ObjectID) error
+	CheckCanSeeFriends(viewerID, userID primitive.ObjectID) error
+}
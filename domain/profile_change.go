@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What made a profile change
+const (
+	ProfileChangeSourceProfile       = "profile"        // a profile update
+	ProfileChangeSourceUsernameClaim = "username_claim" // a claimed premium username
+	ProfileChangeSourceRollback      = "rollback"
+)
+
+// ProfileChange records the old and new value of one profile field
+type ProfileChange struct {
+	BaseModel `bson:",inline"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	// ActorID is who made the change, the user or an admin
+	ActorID  primitive.ObjectID `bson:"actorId" json:"actorId"`
+	Field    string             `bson:"field" json:"field"`
+	OldValue interface{}        `bson:"oldValue" json:"oldValue"`
+	NewValue interface{}        `bson:"newValue" json:"newValue"`
+	Source   string             `bson:"source" json:"source"`
+	// RollbackOf is the change a rollback undid
+	RollbackOf *primitive.ObjectID `bson:"rollbackOf,omitempty" json:"rollbackOf,omitempty"`
+}
+
+type ProfileChangeRepository interface {
+	CreateMany(changes []ProfileChange) error
+	FindByID(id primitive.ObjectID) (*ProfileChange, error)
+	// FindByUser lists the user's changes newest first, of one field when field isn't empty
+	FindByUser(userID primitive.ObjectID, field string, limit, offset int) ([]ProfileChange, error)
+}
+
+type ProfileChangeUseCase interface {
+	ListChanges(userID primitive.ObjectID, field string, limit, offset int) ([]ProfileChange, error)
+	// RollbackChange sets the field of the change back to its old value. The actor
+	// must be the user or an admin.
+	RollbackChange(actorID, userID, changeID primitive.ObjectID) (*User, error)
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// How a profile's viewer relates to its owner, from the closest to the farthest
+const (
+	ProfileRelationSelf     = "self"
+	ProfileRelationFriend   = "friend"
+	ProfileRelationFollower = "follower" // the viewer follows the owner
+	ProfileRelationStranger = "stranger"
+	ProfileRelationBlocked  = "blocked" // either blocked the other
+)
+
+// ProfileView is another user's profile as the viewer may see it. The closer
+// the relationship, the more of it is filled in: blocked viewers get the name
+// only, strangers the public profile, followers what the user is about and
+// friends their personal and contact details. Fields keep the User JSON names.
+type ProfileView struct {
+	ID           primitive.ObjectID `json:"id"`
+	Username     string             `json:"username"`
+	DisplayName  string             `json:"displayName"`
+	Relationship string             `json:"relationship"`
+
+	// Public
+	FirstName      string   `json:"firstName,omitempty"`
+	LastName       string   `json:"lastName,omitempty"`
+	Avatar         string   `json:"avatar,omitempty"`
+	Bio            string   `json:"bio,omitempty"`
+	PhotoProfile   string   `json:"photoProfile,omitempty"`
+	PhotoCover     string   `json:"photoCover,omitempty"`
+	FollowersCount int      `json:"followersCount"`
+	FollowingCount int      `json:"followingCount"`
+	FriendsCount   int      `json:"friendsCount"`
+	IsVerified     bool     `json:"isVerified"`
+	Interests      []string `json:"interests,omitempty"`
+
+	// About, for followers and friends
+	Gender         string        `json:"gender,omitempty"`
+	RelationStatus string        `json:"relationStatus,omitempty"`
+	Occupation     string        `json:"occupation,omitempty"`
+	Education      string        `json:"education,omitempty"`
+	Live           *Live         `json:"live,omitempty"`
+	DatingPhotos   []DatingPhoto `json:"datingPhotos,omitempty"` // approved ones only
+
+	// Personal and contact details, for friends
+	DateOfBirth  *time.Time `json:"dateOfBirth,omitempty"`
+	Height       float64    `json:"height,omitempty"`
+	InterestedIn []string   `json:"interestedIn,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	PhoneNumber  string     `json:"phoneNumber,omitempty"`
+
+	// Location is the owner's exact position, only shown to them
+	Location *GeoLocation `json:"location,omitempty"`
+}
+
+// NewProfileView shows the user as a viewer with the relationship may see them
+func NewProfileView(user *User, relationship string) *ProfileView {
+	view := &ProfileView{
+		ID:           user.ID,
+		Username:     user.Username,
+		DisplayName:  user.DisplayName,
+		Relationship: relationship,
+	}
+	if relationship == ProfileRelationBlocked {
+		return view
+	}
+
+	view.FirstName = user.FirstName
+	view.LastName = user.LastName
+	view.Avatar = user.Avatar
+	view.Bio = user.Bio
+	view.PhotoProfile = user.PhotoProfile
+	view.PhotoCover = user.PhotoCover
+	view.FollowersCount = user.FollowersCount
+	view.FollowingCount = user.FollowingCount
+	view.FriendsCount = user.FriendsCount
+	view.IsVerified = user.IsVerified
+	view.Interests = user.Interests
+	if relationship == ProfileRelationStranger {
+		return view
+	}
+
+	view.Gender = user.Gender
+	view.RelationStatus = user.RelationStatus
+	view.Occupation = user.Occupation
+	view.Education = user.Education
+	if user.Live != (Live{}) {
+		live := user.Live
+		view.Live = &live
+	}
+	for _, photo := range user.DatingPhotos {
+		if photo.IsApproved || relationship == ProfileRelationSelf {
+			view.DatingPhotos = append(view.DatingPhotos, photo)
+		}
+	}
+	if relationship == ProfileRelationFollower {
+		return view
+	}
+
+	if !user.DateOfBirth.IsZero() {
+		dateOfBirth := user.DateOfBirth
+		view.DateOfBirth = &dateOfBirth
+	}
+	view.Height = user.Height
+	view.InterestedIn = user.InterestedIn
+	view.Email = user.Email
+	view.PhoneNumber = user.PhoneNumber
+	if relationship == ProfileRelationSelf && len(user.Location.Coordinates) > 0 {
+		location := user.Location
+		view.Location = &location
+	}
+	return view
+}
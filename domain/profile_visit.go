@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Where a profile visit came from, sent by clients as the ref query parameter
+const (
+	ProfileVisitSourceSearch     = "search"
+	ProfileVisitSourcePost       = "post"
+	ProfileVisitSourceSuggestion = "suggestion"
+	ProfileVisitSourceQR         = "qr"
+	ProfileVisitSourceDirect     = "direct" // no ref, e.g. a typed or shared link
+	ProfileVisitSourceOther      = "other"  // an unknown ref
+)
+
+// NormalizeProfileVisitSource maps a client ref to a known source
+func NormalizeProfileVisitSource(ref string) string {
+	switch ref {
+	case ProfileVisitSourceSearch, ProfileVisitSourcePost, ProfileVisitSourceSuggestion, ProfileVisitSourceQR:
+		return ref
+	case "", ProfileVisitSourceDirect:
+		return ProfileVisitSourceDirect
+	}
+	return ProfileVisitSourceOther
+}
+
+// Profile visit insight limits
+const (
+	ProfileVisitDayFormat    = "2006-01-02" // days are UTC
+	DefaultProfileVisitDays  = 30
+	MaxProfileVisitDays      = 90
+	ProfileVisitDedupeWindow = 24 * time.Hour // a visitor counts once per profile and day
+)
+
+// ProfileVisitDay is the number of visits a profile got on one day, per source
+type ProfileVisitDay struct {
+	UserID  primitive.ObjectID `bson:"userId" json:"-"`
+	Day     string             `bson:"day" json:"day"`
+	Total   int64              `bson:"total" json:"total"`
+	Sources map[string]int64   `bson:"sources" json:"sources"`
+}
+
+// ProfileVisitInsights summarizes the visits of a profile over the last days, oldest day first
+type ProfileVisitInsights struct {
+	Days    int               `json:"days"`
+	Total   int64             `json:"total"`
+	Sources map[string]int64  `json:"sources"`
+	Daily   []ProfileVisitDay `json:"daily"`
+}
+
+// ProfileVisitRepository buffers visits in Redis and keeps the daily stats in MongoDB
+type ProfileVisitRepository interface {
+	// Record buffers the visit and reports false when the visitor was already counted today
+	Record(ownerID, visitorID primitive.ObjectID, source string, at time.Time) (bool, error)
+	// Flush writes up to limit buffered profile days to the daily stats and returns how many it wrote
+	Flush(limit int) (int, error)
+	// FindDaily returns the stats of the days from fromDay on, oldest first
+	FindDaily(ownerID primitive.ObjectID, fromDay string) ([]ProfileVisitDay, error)
+}
+
+type ProfileVisitUseCase interface {
+	RecordVisit(ownerID, visitorID primitive.ObjectID, ref string) error
+	GetInsights(ownerID primitive.ObjectID, days int) (*ProfileVisitInsights, error)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Rate limit buckets. Each route group counts its requests in its own bucket,
+// so a busy search doesn't use up the user's allowance for the rest of the API.
+const (
+	RateLimitDefault = "default"
+	RateLimitAuth    = "auth"
+	RateLimitSearch  = "search"
+	RateLimitUpload  = "upload"
+)
+
+// RateLimit allows Requests per sliding Window. A limit without requests is off.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitResult is the outcome of counting one request against a limit
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the oldest counted request leaves the window
+	RetryAfter time.Duration
+}
+
+type RateLimitRepository interface {
+	// Allow counts the request against the key when it fits in the limit's
+	// sliding window. Rejected requests aren't counted.
+	Allow(key string, limit RateLimit) (*RateLimitResult, error)
+}
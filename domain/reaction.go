@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -19,6 +21,82 @@ type Reaction struct {
 	Type      string              `bson:"type" json:"type"`
 }
 
+// How many reacting users a reaction summary includes per type
+const (
+	DefaultReactionSummaryUsers = 3
+	MaxReactionSummaryUsers     = 20
+)
+
+// ReactionUser is the public profile of a reacting user
+type ReactionUser struct {
+	ID           primitive.ObjectID `bson:"_id" json:"userId"`
+	Username     string             `bson:"username" json:"username"`
+	DisplayName  string             `bson:"displayName" json:"displayName"`
+	PhotoProfile string             `bson:"photoProfile" json:"photoProfile"`
+	FirstName    string             `bson:"firstName" json:"firstName"`
+	LastName     string             `bson:"lastName" json:"lastName"`
+}
+
+// ReactionTypeSummary counts the reactions of one type and shows who reacted most recently
+type ReactionTypeSummary struct {
+	Type    string               `bson:"_id" json:"type"`
+	Count   int64                `bson:"count" json:"count"`
+	UserIDs []primitive.ObjectID `bson:"userIds" json:"-"` // most recent first
+	Users   []ReactionUser       `bson:"users" json:"users"`
+}
+
+// ReactionSummary groups the reactions of a post or comment by type, most used type first
+type ReactionSummary struct {
+	TargetID primitive.ObjectID    `json:"targetId"`
+	Total    int64                 `json:"total"`
+	Types    []ReactionTypeSummary `json:"types"`
+}
+
+// Post reaction analytics tuning
+const (
+	// ReactionTimelineHours is how many of the latest hours the reaction timeline covers
+	ReactionTimelineHours = 48
+	// ReactionAnalyticsFriends is how many of the friends who reacted are listed
+	ReactionAnalyticsFriends = 10
+	// ReactionAnalyticsMaxFriends caps the author's friends matched against the reactions
+	ReactionAnalyticsMaxFriends = 1000
+	// Analytics of hot posts are cached for this long
+	ReactionAnalyticsCacheTTL = time.Minute
+)
+
+// ReactionTypeCount counts the reactions of one type
+type ReactionTypeCount struct {
+	Type  string `bson:"_id" json:"type"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+// FriendReaction is how one of the author's friends reacted
+type FriendReaction struct {
+	User      ReactionUser `bson:"user" json:"user"`
+	Type      string       `bson:"type" json:"type"`
+	ReactedAt time.Time    `bson:"createdAt" json:"reactedAt"`
+}
+
+// ReactionTimelineBucket counts the reactions made within the hour starting at Hour
+type ReactionTimelineBucket struct {
+	Hour  time.Time `bson:"_id" json:"hour"`
+	Count int64     `bson:"count" json:"count"`
+}
+
+// PostReactionAnalytics shows a post's author how the post is being reacted to
+type PostReactionAnalytics struct {
+	PostID primitive.ObjectID  `json:"postId"`
+	Total  int64               `json:"total"`
+	Types  []ReactionTypeCount `json:"types"` // most used type first
+	// FriendCount is how many of the author's friends reacted, Friends the latest of them
+	FriendCount int64            `json:"friendCount"`
+	Friends     []FriendReaction `json:"friends"`
+	// Timeline covers the latest hours since the post was created, oldest first,
+	// including the hours without reactions
+	Timeline    []ReactionTimelineBucket `json:"timeline"`
+	GeneratedAt time.Time                `json:"generatedAt"`
+}
+
 // Repository interface
 type ReactionRepository interface {
 	Create(reaction *Reaction) error
@@ -28,6 +106,19 @@ type ReactionRepository interface {
 	FindByPostID(postID primitive.ObjectID, limit, offset int) ([]Reaction, error)
 	FindByCommentID(commentID primitive.ObjectID, limit, offset int) ([]Reaction, error)
 	FindByUserAndTarget(userID, postID primitive.ObjectID, commentID *primitive.ObjectID) (*Reaction, error)
+	// FindByUserID lists the user's reactions, oldest first
+	FindByUserID(userID primitive.ObjectID, limit, offset int) ([]Reaction, error)
+	// DeleteByUserID removes all the user's reactions and returns how many were removed
+	DeleteByUserID(userID primitive.ObjectID) (int64, error)
+	CountByTarget(targetID primitive.ObjectID, isComment bool) (int64, error)
+	// SummarizeByTarget counts reactions per type with the profiles of the latest usersPerType users
+	SummarizeByTarget(targetID primitive.ObjectID, isComment bool, usersPerType int) ([]ReactionTypeSummary, error)
+	// AnalyzePost aggregates the post's reactions by type, the latest friendLimit of
+	// them by the friends and their counts per hour since the time
+	AnalyzePost(postID primitive.ObjectID, friendIDs []primitive.ObjectID, friendLimit int, since time.Time) (*PostReactionAnalytics, error)
+	// GetPostAnalytics returns nil, nil when the post's analytics aren't cached
+	GetPostAnalytics(postID primitive.ObjectID) (*PostReactionAnalytics, error)
+	SetPostAnalytics(analytics *PostReactionAnalytics, ttl time.Duration) error
 }
 
 // UseCase interface
@@ -36,4 +127,9 @@ type ReactionUseCase interface {
 	DeleteReaction(reactionID primitive.ObjectID) error
 	GetReaction(reactionID primitive.ObjectID) (*Reaction, error)
 	ListReactions(targetID primitive.ObjectID, isComment bool, limit, offset int) ([]Reaction, error)
+	CountReactions(targetID primitive.ObjectID, isComment bool) (int64, error)
+	GetReactionSummary(targetID primitive.ObjectID, isComment bool, usersPerType int) (*ReactionSummary, error)
+	// GetPostAnalytics lets the post's author see its reactions by type, by their
+	// friends and by hour. Everyone else gets ErrForbidden.
+	GetPostAnalytics(userID, postID primitive.ObjectID) (*PostReactionAnalytics, error)
 }
@@ -0,0 +1,22 @@
+package domain
+
+// Realtime event types pushed to connected clients
+const (
+	RealtimeEventStoryViewers     = "storyViewers"
+	RealtimeEventNotificationRead = "notification_read"
+	RealtimeEventReadReceipt      = "readReceipt"
+	RealtimeEventNotification     = "notification" // a new notification, with its sender
+	RealtimeEventMessageEdited    = "messageEdited"
+	RealtimeEventRoomRead         = "read"           // a member read every message up to a time
+	RealtimeEventMessageDeleted   = "messageDeleted" // a message was deleted for everyone
+	RealtimeEventThreadActivity   = "threadActivity" // a reply in a thread, sent to its participants only
+	RealtimeEventSystemMessage    = "systemMessage"  // a system message added to the room history
+	RealtimeEventAutoReply        = "autoReply"      // a message sent by a member's away auto-reply
+)
+
+// RealtimePublisher pushes events to connected users, implemented by the WebSocket hub
+type RealtimePublisher interface {
+	SendToUser(userID string, eventType string, data interface{}) error
+	PublishToRoom(roomID string, eventType string, data interface{}) error
+	BroadcastUserStatus(userID string, status string)
+}
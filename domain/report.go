@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What a report can be about
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+	ReportTargetMessage = "message" // a chat message, only members of its room can report it
+)
+
+// Why content was reported
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHateSpeech     = "hate_speech"
+	ReportReasonViolence       = "violence"
+	ReportReasonNudity         = "nudity"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonImpersonation  = "impersonation"
+	ReportReasonOther          = "other"
+)
+
+// Report statuses. Resolving a report closes every open report of its target.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"  // action was taken on the target
+	ReportStatusDismissed = "dismissed" // the target broke no rules
+)
+
+// MaxReportDetailsLength is how long the reporter's explanation can be
+const MaxReportDetailsLength = 1000
+
+// Report flags a post, comment, user or chat message for moderators
+type Report struct {
+	BaseModel  `bson:",inline"`
+	ReporterID primitive.ObjectID `bson:"reporterId" json:"reporterId"`
+	TargetType string             `bson:"targetType" json:"targetType"`
+	TargetID   string             `bson:"targetId" json:"targetId"`
+	// TargetOwnerID is who wrote the reported content, the user itself for user reports
+	TargetOwnerID primitive.ObjectID  `bson:"targetOwnerId" json:"targetOwnerId"`
+	Reason        string              `bson:"reason" json:"reason"`
+	Details       string              `bson:"details,omitempty" json:"details,omitempty"`
+	Status        string              `bson:"status" json:"status"`
+	ResolvedBy    *primitive.ObjectID `bson:"resolvedBy,omitempty" json:"resolvedBy,omitempty"`
+	ResolvedAt    *time.Time          `bson:"resolvedAt,omitempty" json:"resolvedAt,omitempty"`
+	Resolution    string              `bson:"resolution,omitempty" json:"resolution,omitempty"` // the moderator's note
+}
+
+// ReportRequest files a report
+type ReportRequest struct {
+	TargetType string `json:"targetType"`
+	TargetID   string `json:"targetId"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+}
+
+// ResolveReportRequest closes the open reports of a target as resolved or dismissed
+type ResolveReportRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// ReportFilter narrows a report listing, empty fields match everything
+type ReportFilter struct {
+	Status     string
+	TargetType string
+	TargetID   string
+}
+
+// ReportCount sums up the reports of one target
+type ReportCount struct {
+	TargetType     string    `bson:"targetType" json:"targetType"`
+	TargetID       string    `bson:"targetId" json:"targetId"`
+	Open           int64     `bson:"open" json:"open"`
+	Total          int64     `bson:"total" json:"total"`
+	Reasons        []string  `bson:"reasons" json:"reasons"`
+	LastReportedAt time.Time `bson:"lastReportedAt" json:"lastReportedAt"`
+}
+
+type ReportRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create fails with ErrDuplicate when the reporter has an open report of the target
+	Create(report *Report) error
+	FindByID(id primitive.ObjectID) (*Report, error)
+	// FindAll lists reports newest first
+	FindAll(filter ReportFilter, limit, offset int) ([]Report, error)
+	// ResolveOpen closes the open reports of a target and returns how many it closed
+	ResolveOpen(targetType, targetID string, status string, resolverID primitive.ObjectID, note string) (int64, error)
+	// CountByTarget sums up reports per target, targets with the most open reports first.
+	// Only targets with reports of the filter's status are listed.
+	CountByTarget(filter ReportFilter, limit, offset int) ([]ReportCount, error)
+}
+
+type ReportUseCase interface {
+	CreateReport(reporterID primitive.ObjectID, req ReportRequest) (*Report, error)
+	ListReports(filter ReportFilter, limit, offset int) ([]Report, error)
+	GetReport(id primitive.ObjectID) (*Report, error)
+	// ResolveReport closes the report and every other open report of its target
+	ResolveReport(adminID, reportID primitive.ObjectID, req ResolveReportRequest) (*Report, error)
+	ListTargetCounts(filter ReportFilter, limit, offset int) ([]ReportCount, error)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReservedUsernameCategory string
+
+const (
+	ReservedCategoryBrand     ReservedUsernameCategory = "brand"
+	ReservedCategoryOffensive ReservedUsernameCategory = "offensive"
+	ReservedCategorySystem    ReservedUsernameCategory = "system"
+)
+
+// ReservedUsername blocks a username from registration and username changes.
+// Premium entries can be claimed once by a verified account.
+type ReservedUsername struct {
+	BaseModel `bson:",inline"`
+	Username  string                   `bson:"username" json:"username"`
+	Category  ReservedUsernameCategory `bson:"category" json:"category"`
+	Reason    string                   `bson:"reason,omitempty" json:"reason,omitempty"`
+	IsPremium bool                     `bson:"isPremium" json:"isPremium"`
+	ClaimedBy *primitive.ObjectID      `bson:"claimedBy,omitempty" json:"claimedBy,omitempty"`
+	ClaimedAt *time.Time               `bson:"claimedAt,omitempty" json:"claimedAt,omitempty"`
+}
+
+// IsClaimable reports whether a verified account may still claim the username
+func (r *ReservedUsername) IsClaimable() bool {
+	return r.IsPremium && r.ClaimedBy == nil && r.Category != ReservedCategoryOffensive
+}
+
+type ReservedUsernameRepository interface {
+	Create(reserved *ReservedUsername) error
+	Update(reserved *ReservedUsername) error
+	Delete(id primitive.ObjectID) error
+	FindByID(id primitive.ObjectID) (*ReservedUsername, error)
+	FindByUsername(username string) (*ReservedUsername, error)
+	List(category string, limit, offset int) ([]ReservedUsername, error)
+}
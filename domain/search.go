@@ -0,0 +1,72 @@
+package domain
+
+import "context"
+
+// MaxSearchWindow caps how deep search results can be paged
+const MaxSearchWindow = 500
+
+// MaxContactMatches caps the users an exact email or phone number search returns
+const MaxContactMatches = 5
+
+type SearchResultType string
+
+const (
+	SearchTypeAll  SearchResultType = "all"
+	SearchTypePost SearchResultType = "post"
+	SearchTypeUser SearchResultType = "user"
+)
+
+// SearchUser represents limited user data returned by search
+type SearchUser struct {
+	ID           string `json:"userId"`
+	Username     string `json:"username"`
+	DisplayName  string `json:"displayName"`
+	PhotoProfile string `json:"photoProfile"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+}
+
+// SearchResult is one item of a mixed search result list. Data holds a *PostWithDetails or a *SearchUser.
+type SearchResult struct {
+	Type  SearchResultType `json:"type"`
+	ID    string           `json:"id"`
+	Score float64          `json:"score"`
+	Data  interface{}      `json:"data"`
+}
+
+type PostSearchHit struct {
+	Post  Post
+	Score float64
+}
+
+type UserSearchHit struct {
+	User  User
+	Score float64
+}
+
+// SearchRepository runs full-text queries, ranked by relevance
+type SearchRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	SearchPosts(query string, limit int) ([]PostSearchHit, error)
+	SearchUsers(query string, limit int) ([]UserSearchHit, error)
+	// FindUsersByContact returns the users whose email or phone number is exactly
+	// the one given and who let others find them by it. Empty values are skipped.
+	FindUsersByContact(email, phone string, limit int) ([]User, error)
+	CountPosts(query string) (int64, error)
+	CountUsers(query string) (int64, error)
+}
+
+type SearchUseCase interface {
+	Search(query string, searchType SearchResultType, limit, offset int) ([]SearchResult, error)
+	CountResults(query string, searchType SearchResultType) (int64, error)
+
+	// RecordSearch adds the query to the user's history and the popular searches
+	RecordSearch(userID, query string) error
+	GetHistory(userID string) ([]SearchHistoryEntry, error)
+	DeleteHistoryEntry(userID, query string) error
+	ClearHistory(userID string) error
+	// Suggest completes the prefix from the user's history first, then from popular searches
+	Suggest(userID, prefix string, limit int) ([]SearchSuggestion, error)
+	// TrimSuggestions keeps the popular searches within MaxPopularSearches
+	TrimSuggestions() error
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Search history and suggestion limits
+const (
+	MaxSearchHistory     = 20  // recent searches kept per user
+	MaxSearchQueryLength = 100 // longer queries aren't remembered
+	// Suggestions per request
+	DefaultSearchSuggestions = 10
+	MaxSearchSuggestions     = 20
+	// A query is suggested to others once this many users searched it recently,
+	// so one user's searches never show up for someone else
+	MinSuggestedSearchUsers = 3
+	// Popular queries kept for suggestions, the least searched are trimmed beyond that
+	MaxPopularSearches = 10000
+)
+
+// Where a suggestion comes from
+const (
+	SearchSuggestionHistory = "history"
+	SearchSuggestionPopular = "popular"
+)
+
+// SearchHistoryEntry is one of a user's recent searches. Queries are stored
+// normalized, lowercase with single spaces.
+type SearchHistoryEntry struct {
+	Query      string    `json:"query"`
+	SearchedAt time.Time `json:"searchedAt"`
+}
+
+// SearchSuggestion completes a partly typed query
+type SearchSuggestion struct {
+	Query  string `json:"query"`
+	Source string `json:"source"`
+}
+
+// SearchHistoryRepository keeps recent searches per user and popular searches overall
+type SearchHistoryRepository interface {
+	// AddHistory records a search of the user, keeping the latest MaxSearchHistory.
+	// It reports whether the query wasn't in the user's history yet.
+	AddHistory(userID, query string, at time.Time) (bool, error)
+	// GetHistory lists the user's searches, latest first
+	GetHistory(userID string, limit int) ([]SearchHistoryEntry, error)
+	// DeleteHistory reports false when the query wasn't in the user's history
+	DeleteHistory(userID, query string) (bool, error)
+	ClearHistory(userID string) error
+	IncrementPopular(query string) error
+	// SuggestPopular lists popular queries starting with prefix that at least
+	// minCount users searched, most searched first
+	SuggestPopular(prefix string, minCount int64, limit int) ([]string, error)
+	// TrimPopular drops the least searched queries beyond max and returns how many it dropped
+	TrimPopular(max int64) (int64, error)
+}
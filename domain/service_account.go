@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Scopes that can be granted to service accounts
+const (
+	ScopeJobsRun     = "jobs:run"
+	ScopeMetricsRead = "metrics:read"
+	// ScopeTokensIntrospect lets internal services look up what a token grants
+	ScopeTokensIntrospect = "tokens:introspect"
+)
+
+// TokenTypeService marks machine tokens so they can't be used as user tokens
+const TokenTypeService = "service"
+
+// ServiceAccount is a machine identity used by background workers and cron runners
+type ServiceAccount struct {
+	ClientID   string   `json:"clientId"`
+	SecretHash string   `json:"-"` // hex encoded SHA-256 of the client secret
+	Scopes     []string `json:"scopes"`
+}
+
+// HasScope checks if the account is allowed to request the scope
+func (a *ServiceAccount) HasScope(scope string) bool {
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceClaims are the claims carried by machine tokens
+type ServiceClaims struct {
+	ClientID string   `json:"clientId"`
+	Scopes   []string `json:"scopes"`
+	Type     string   `json:"type"`
+	jwt.RegisteredClaims
+}
+
+// HasScope checks if the token was granted the scope
+func (c *ServiceClaims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type ServiceToken struct {
+	AccessToken string   `json:"accessToken"`
+	TokenType   string   `json:"tokenType"`
+	ExpiresIn   int64    `json:"expiresIn"` // seconds
+	Scopes      []string `json:"scopes"`
+}
+
+type ServiceAccountUseCase interface {
+	IssueToken(ctx context.Context, clientID, clientSecret string, scopes []string) (*ServiceToken, error)
+	VerifyToken(token string) (*ServiceClaims, error)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What a short link points at
+const (
+	ShortLinkPost    = "post"
+	ShortLinkProfile = "profile"
+)
+
+// Short link limits
+const (
+	ShortLinkCodeLength = 7
+	MaxShortLinkExpiry  = 365 * 24 * time.Hour
+)
+
+// ShortLink is a compact share code for a post or a profile
+type ShortLink struct {
+	BaseModel  `bson:",inline"`
+	Code       string             `bson:"code" json:"code"`
+	TargetType string             `bson:"targetType" json:"targetType"`
+	TargetID   primitive.ObjectID `bson:"targetId" json:"targetId"`
+	CreatedBy  primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	Clicks     int64              `bson:"clicks" json:"clicks"`
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"` // nil never expires
+	URL        string             `bson:"-" json:"url"`
+	// TargetURL is the canonical link of the target, set when a link is opened
+	TargetURL string `bson:"-" json:"targetUrl,omitempty"`
+}
+
+// IsExpired reports whether the link stopped resolving
+func (l *ShortLink) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// ShortLinkRequest sets how long a new link lasts, the configured default when 0
+type ShortLinkRequest struct {
+	ExpiresInHours int `json:"expiresInHours,omitempty"`
+}
+
+type ShortLinkRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create returns ErrDuplicate when the code is taken
+	Create(link *ShortLink) error
+	FindByCode(code string) (*ShortLink, error)
+	// FindReusable returns the creator's newest unexpired link to the target, nil when there's none
+	FindReusable(createdBy primitive.ObjectID, targetType string, targetID primitive.ObjectID) (*ShortLink, error)
+	IncrementClicks(code string) error
+}
+
+type ShortLinkUseCase interface {
+	// CreatePostLink returns a link to a post the user can see. Without an expiry
+	// in req the user's existing link to the post is reused.
+	CreatePostLink(userID, postID primitive.ObjectID, req ShortLinkRequest) (*ShortLink, error)
+	CreateProfileLink(userID, profileID primitive.ObjectID, req ShortLinkRequest) (*ShortLink, error)
+	// Open counts a click and returns the link. Expired links return ErrShortLinkExpired.
+	Open(code string) (*ShortLink, error)
+}
@@ -18,20 +18,56 @@ type StoryMedia struct {
 	Thumbnail string    `bson:"thumbnail" json:"thumbnail"`
 }
 
+// MaxStoryAudioDuration is the longest audio clip in seconds that can play over a story
+const MaxStoryAudioDuration = 60
+
+// StoryAudio references an uploaded audio file and the trimmed part that plays over the story
+type StoryAudio struct {
+	URL         string  `bson:"url" json:"url"`
+	Title       string  `bson:"title,omitempty" json:"title,omitempty"`
+	Artist      string  `bson:"artist,omitempty" json:"artist,omitempty"`
+	LicenseID   string  `bson:"licenseId,omitempty" json:"licenseId,omitempty"`
+	StartOffset float64 `bson:"startOffset" json:"startOffset"` // Seconds from the start of the audio file
+	Duration    float64 `bson:"duration" json:"duration"`       // Seconds played from StartOffset
+}
+
 type StoryViewer struct {
 	UserID    string    `bson:"userId" json:"userId"`
 	ViewedAt  time.Time `bson:"viewedAt" json:"viewedAt"`
 	IsArchive bool      `bson:"isArchive" json:"isArchive"`
 }
 
+// StoryReactionEmojis are the quick reactions viewers can send on a story
+var StoryReactionEmojis = map[string]bool{
+	"❤️": true,
+	"😂": true,
+	"😮": true,
+	"😢": true,
+	"😡": true,
+	"👏": true,
+	"🔥": true,
+	"🎉": true,
+}
+
+// StoryReaction is a viewer's quick reaction, one per viewer
+type StoryReaction struct {
+	UserID    string    `bson:"userId" json:"userId"`
+	Emoji     string    `bson:"emoji" json:"emoji"`
+	ReactedAt time.Time `bson:"reactedAt" json:"reactedAt"`
+}
+
 type Story struct {
 	BaseModel    `bson:",inline"`
 	UserID       string        `bson:"userId" json:"userId"`
 	Media        StoryMedia    `bson:"media" json:"media"`
+	Audio        *StoryAudio   `bson:"audio,omitempty" json:"audio,omitempty"`
 	Caption      string        `bson:"caption" json:"caption"`
 	Location     string        `bson:"location" json:"location"`
 	ViewersCount int          `bson:"viewersCount" json:"viewersCount"`
 	Viewers      []StoryViewer `bson:"viewers" json:"viewers"`
+	// ReactionsCount counts reacting viewers, separately from ViewersCount
+	ReactionsCount int             `bson:"reactionsCount" json:"reactionsCount"`
+	Reactions      []StoryReaction `bson:"reactions,omitempty" json:"reactions,omitempty"`
 	ExpiresAt    time.Time     `bson:"expiresAt" json:"expiresAt"`
 	IsArchive    bool          `bson:"isArchive" json:"isArchive"`
 	IsActive     bool          `bson:"isActive" json:"isActive"`
@@ -49,23 +85,51 @@ type StoryResponse struct {
 	} `json:"user"`
 }
 
+// StoryLiveViewers is pushed to the story owner while viewers are watching
+type StoryLiveViewers struct {
+	StoryID  string `json:"storyId"`
+	Watching int64  `json:"watching"`
+}
+
 type StoryRepository interface {
 	Create(story *Story) error
 	FindByID(id string) (*Story, error)
+	// FindAnyByID also returns expired and archived stories, not deleted ones
+	FindAnyByID(id string) (*Story, error)
 	FindByUserID(userID string) ([]*Story, error)
 	FindActiveStories() ([]*Story, error)
 	Update(story *Story) error
 	AddViewer(storyID string, viewer StoryViewer) error
+	// SetReaction adds the viewer's reaction or changes its emoji, reporting whether it was added
+	SetReaction(storyID string, reaction StoryReaction) (bool, error)
+	// RemoveReaction removes the viewer's reaction, reporting whether there was one
+	RemoveReaction(storyID, userID string) (bool, error)
 	DeleteStory(id string) error
 	ArchiveExpiredStories() error
+	FindDeleted(since time.Time, limit, offset int) ([]*Story, error)
+	FindDeletedByID(id string) (*Story, error)
+	Restore(id string) error
+
+	// Live watchers are kept in Redis only
+	AddWatcher(storyID, viewerID string, window time.Duration) (int64, error)
+	RemoveWatcher(storyID, viewerID string, window time.Duration) (int64, error)
+	TryLockWatcherBroadcast(storyID string, interval time.Duration) (bool, error)
 }
 
 type StoryUseCase interface {
 	CreateStory(story *Story) error
-	GetStoryByID(id string) (*StoryResponse, error)
-	GetUserStories(userID string) ([]*StoryResponse, error)
-	GetActiveStories() ([]*StoryResponse, error)
+	// GetStoryByID, GetUserStories and GetActiveStories hide the stories of users
+	// the viewer blocked or who blocked the viewer
+	GetStoryByID(id string, viewerID string) (*StoryResponse, error)
+	GetUserStories(userID string, viewerID string) ([]*StoryResponse, error)
+	GetActiveStories(viewerID string) ([]*StoryResponse, error)
 	ViewStory(storyID string, viewerID string) error
+	// ReplyToStory sends a chat message to the story owner
+	ReplyToStory(storyID string, viewerID string, content string) (*ChatMessage, error)
+	ReactToStory(storyID string, viewerID string, emoji string) error
+	RemoveStoryReaction(storyID string, viewerID string) error
 	DeleteStory(storyID string, userID string) error
 	ArchiveExpiredStories() error
+	WatchStory(storyID string, viewerID string) error
+	LeaveStory(storyID string, viewerID string) error
 }
@@ -0,0 +1,103 @@
+package domain
+
+import "time"
+
+// Story interactions viewers' apps report
+const (
+	StoryInteractionTapForward = "tap_forward" // skipped to the next story
+	StoryInteractionTapBack    = "tap_back"    // went back to the previous story
+	StoryInteractionExit       = "exit"        // closed the stories viewer
+	StoryInteractionSwipeUp    = "swipe_up"    // opened the story's link or reply
+)
+
+// IsStoryInteraction reports whether apps may report the interaction type
+func IsStoryInteraction(interactionType string) bool {
+	switch interactionType {
+	case StoryInteractionTapForward, StoryInteractionTapBack, StoryInteractionExit, StoryInteractionSwipeUp:
+		return true
+	}
+	return false
+}
+
+// Story interaction limits
+const (
+	MaxStoryInteractionBatchSize = 100
+	// A viewer counts once per story and interaction type within this window,
+	// longer than a story is shown
+	StoryInteractionDedupeWindow = 48 * time.Hour
+	// MaxStoryRetentionSecond caps the retention curve, the longest a story plays
+	MaxStoryRetentionSecond = 60
+	// StoryImageSeconds is how long apps show an image story
+	StoryImageSeconds = 5
+)
+
+// StoryInteractionEvent is one interaction with a story. Position is the
+// second of the story the viewer was at when leaving it.
+type StoryInteractionEvent struct {
+	StoryID  string  `json:"storyId"`
+	Type     string  `json:"type"`
+	Position float64 `json:"position,omitempty"`
+}
+
+// StoryInteractionRecord is a validated interaction of a viewer to count
+type StoryInteractionRecord struct {
+	StoryID string
+	OwnerID string
+	Type    string
+	Second  int
+	// Unique is set for the viewer's first interaction of the type with the story
+	Unique bool
+}
+
+// StoryInteractionStats are the counts kept for a story
+type StoryInteractionStats struct {
+	StoryID string `bson:"_id" json:"storyId"`
+	OwnerID string `bson:"ownerId" json:"-"`
+	// Counts has every interaction, Viewers the viewers who interacted, per type
+	Counts  map[string]int64 `bson:"counts" json:"counts"`
+	Viewers map[string]int64 `bson:"viewers" json:"viewers"`
+	// DropOffs counts the viewers leaving the story early, by the second they left at
+	DropOffs  map[string]int64 `bson:"dropOffs" json:"-"`
+	UpdatedAt time.Time        `bson:"updatedAt" json:"updatedAt"`
+}
+
+// StoryRetentionPoint is how many viewers were still watching at a second of the story
+type StoryRetentionPoint struct {
+	Second  int     `json:"second"`
+	Viewers int64   `json:"viewers"`
+	Rate    float64 `json:"rate"`
+}
+
+// StoryInsights is the author's view of how viewers watched a story. Rates are
+// fractions of the story's viewers.
+type StoryInsights struct {
+	StoryID        string                `json:"storyId"`
+	Viewers        int64                 `json:"viewers"`
+	Reactions      int64                 `json:"reactions"`
+	TapsForward    int64                 `json:"tapsForward"`
+	TapsBack       int64                 `json:"tapsBack"`
+	Exits          int64                 `json:"exits"`
+	SwipeUps       int64                 `json:"swipeUps"`
+	ForwardRate    float64               `json:"forwardRate"`
+	ExitRate       float64               `json:"exitRate"`
+	SwipeUpRate    float64               `json:"swipeUpRate"`
+	CompletionRate float64               `json:"completionRate"`
+	Retention      []StoryRetentionPoint `json:"retention"`
+}
+
+type StoryInteractionRepository interface {
+	// MarkInteracted reports whether it's the viewer's first interaction of the type with the story
+	MarkInteracted(storyID, viewerID, interactionType string) (bool, error)
+	// Record adds the interactions to the stats of their stories
+	Record(records []StoryInteractionRecord) error
+	// FindStats returns nil when no interaction with the story was recorded
+	FindStats(storyID string) (*StoryInteractionStats, error)
+}
+
+type StoryInsightsUseCase interface {
+	// RecordInteractions validates the whole batch before counting any of it and
+	// returns how many events were counted. The author's own interactions aren't.
+	RecordInteractions(viewerID string, events []StoryInteractionEvent) (int, error)
+	// GetInsights is only allowed for the story's author, also after it expired
+	GetInsights(storyID, userID string) (*StoryInsights, error)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Suspension types. Banned users can't use the API at all, muted users can
+// read but not post, comment, share stories or send messages.
+const (
+	SuspensionBan  = "ban"
+	SuspensionMute = "mute"
+)
+
+// Suspension limits
+const (
+	MaxSuspensionDuration = 365 * 24 * time.Hour
+	// Users are told this long before their suspension ends
+	SuspensionEndingNotice = 24 * time.Hour
+)
+
+// Suspension is a time-boxed ban or mute of a user, lifted automatically once Until passes
+type Suspension struct {
+	Type      string             `bson:"type" json:"type"`
+	Reason    string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	Until     time.Time          `bson:"until" json:"until"`
+	CreatedBy primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	// EndingNotifiedAt is set once the user was told the suspension ends soon
+	EndingNotifiedAt *time.Time `bson:"endingNotifiedAt,omitempty" json:"endingNotifiedAt,omitempty"`
+}
+
+// IsActive reports whether the suspension is still in force. Expired suspensions
+// stop applying right away, even before the lifting job clears them.
+func (s *Suspension) IsActive(now time.Time) bool {
+	return s != nil && now.Before(s.Until)
+}
+
+// SuspensionError is returned to suspended users, carrying the suspension so clients can show its details
+type SuspensionError struct {
+	Suspension *Suspension
+}
+
+func (e *SuspensionError) Error() string {
+	if e.Suspension.Type == SuspensionMute {
+		return ErrAccountMuted.Error()
+	}
+	return ErrAccountBanned.Error()
+}
+
+func (e *SuspensionError) Unwrap() error {
+	if e.Suspension.Type == SuspensionMute {
+		return ErrAccountMuted
+	}
+	return ErrAccountBanned
+}
+
+// AsSuspensionError returns the suspension of a suspension error, or nil for other errors
+func AsSuspensionError(err error) *Suspension {
+	var suspensionErr *SuspensionError
+	if errors.As(err, &suspensionErr) {
+		return suspensionErr.Suspension
+	}
+	return nil
+}
+
+// SuspendRequest suspends a user until a time or for a number of hours
+type SuspendRequest struct {
+	Type          string     `json:"type"`
+	Reason        string     `json:"reason"`
+	Until         *time.Time `json:"until,omitempty"`
+	DurationHours int        `json:"durationHours,omitempty"` // used when until is not set
+}
+
+// SuspensionRunResult is the outcome of a suspension processing run
+type SuspensionRunResult struct {
+	Notified int `json:"notified"`
+	Lifted   int `json:"lifted"`
+}
+
+type SuspensionUseCase interface {
+	Suspend(adminID, userID primitive.ObjectID, req SuspendRequest) (*Suspension, error)
+	Lift(adminID, userID primitive.ObjectID) error
+	// GetActiveSuspension returns nil when the user isn't suspended
+	GetActiveSuspension(userID string) (*Suspension, error)
+	// ProcessSuspensions tells users whose suspension ends soon and lifts the expired ones
+	ProcessSuspensions() (*SuspensionRunResult, error)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// ErrTranslationUnavailable is returned when no translation provider is configured or it is down
+var ErrTranslationUnavailable = errors.New("translation service unavailable")
+
+// Translation is a piece of user content rendered in another language
+type Translation struct {
+	SourceLanguage string `json:"sourceLanguage"`
+	TargetLanguage string `json:"targetLanguage"`
+	Text           string `json:"text"`
+}
+
+// TranslationRepository wraps the external machine translation provider
+type TranslationRepository interface {
+	Translate(text, sourceLanguage, targetLanguage string) (*Translation, error)
+}
@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"context"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AuthProvider string
@@ -12,6 +15,13 @@ const (
 	Email  AuthProvider = "email"
 )
 
+type UserRole string
+
+const (
+	RoleUser  UserRole = "user"
+	RoleAdmin UserRole = "admin"
+)
+
 type GeoLocation struct {
 	Type        string    `bson:"type" json:"type"`
 	Coordinates []float64 `bson:"coordinates" json:"coordinates"`
@@ -40,6 +50,7 @@ type User struct {
 	FollowingCount int           `bson:"followingCount" json:"followingCount"`
 	FriendsCount   int           `bson:"friendsCount" json:"friendsCount"`
 	Provider       AuthProvider  `bson:"provider" json:"provider"`
+	Role           UserRole      `bson:"role,omitempty" json:"role,omitempty"`
 	EmailVerified  bool          `bson:"emailVerified" json:"emailVerified"`
 	DateOfBirth    time.Time     `bson:"dateOfBirth" json:"dateOfBirth"`
 	Gender         string        `bson:"gender" json:"gender"`
@@ -47,7 +58,9 @@ type User struct {
 	Location       GeoLocation   `bson:"location" json:"location"`
 	RelationStatus string        `bson:"relationStatus" json:"relationStatus"`
 	Height         float64       `bson:"height" json:"height"`
-	Interests      []string      `bson:"interests" json:"interests"`
+	Interests      []string      `bson:"interests" json:"interests"` // interest taxonomy slugs
+	// LegacyInterests are free text interests that don't match the taxonomy yet
+	LegacyInterests []string `bson:"legacyInterests,omitempty" json:"legacyInterests,omitempty"`
 	Occupation     string        `bson:"occupation" json:"occupation"`
 	Education      string        `bson:"education" json:"education"`
 	DatingPhotos   []DatingPhoto `bson:"datingPhotos" json:"datingPhotos"`
@@ -55,6 +68,22 @@ type User struct {
 	IsActive       bool          `bson:"isActive" json:"isActive"`
 	PhoneNumber    string        `bson:"phoneNumber,omitempty" json:"phoneNumber,omitempty"`
 	Live           Live          `bson:"live" json:"live"`
+	// HideReadReceipts stops other members from seeing when this user read their messages
+	HideReadReceipts bool `bson:"hideReadReceipts" json:"hideReadReceipts"`
+	// LastSeenVisibility is who sees the exact last seen time: everyone (the default), friends or nobody
+	LastSeenVisibility string `bson:"lastSeenVisibility,omitempty" json:"lastSeenVisibility,omitempty"`
+	// CoarseLastSeen shows everyone only "recently", "today", ... instead of the exact time
+	CoarseLastSeen bool `bson:"coarseLastSeen" json:"coarseLastSeen"`
+	// AutoReply is the away message, served through the chat auto-reply endpoints only
+	AutoReply *ChatAutoReply `bson:"autoReply,omitempty" json:"-"`
+	// Suspension is the current temporary ban or mute, if any
+	Suspension *Suspension `bson:"suspension,omitempty" json:"suspension,omitempty"`
+	// Privacy is served through the privacy endpoints only, and isn't kept in the
+	// user cache either
+	Privacy PrivacySettings `bson:"privacy" json:"-"`
+	// DeletionScheduledAt is when the account gets purged after its owner deleted it,
+	// unless they cancel before then
+	DeletionScheduledAt *time.Time `bson:"deletionScheduledAt,omitempty" json:"deletionScheduledAt,omitempty"`
 }
 
 type Live struct {
@@ -62,6 +91,11 @@ type Live struct {
 	Country string `bson:"country" json:"country"`
 }
 
+// IsAdmin checks if the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
 type UserListItem struct {
 	ID             string `json:"id"`
 	Username       string `json:"username"`
@@ -94,6 +128,9 @@ type UserListResponse struct {
 }
 
 type UserRepository interface {
+	// EnsureIndexes creates the geo index nearby searches need and the username
+	// index prefix lookups use
+	EnsureIndexes(ctx context.Context) error
 	Create(user *User) error
 	FindByFirebaseUID(firebaseUID string) (*User, error)
 	FindByEmail(email string) (*User, error)
@@ -103,6 +140,43 @@ type UserRepository interface {
 	SoftDelete(id string) error
 	GetUserList(req *UserListRequest) ([]User, int64, error)
 	GetUserByID(userID string) (*User, error)
+	FindDeleted(since time.Time, limit, offset int) ([]User, error)
+	FindDeletedByID(id string) (*User, error)
+	Restore(id string) error
+	FindByIDs(ids []primitive.ObjectID) ([]User, error)
+	// FindPopularNearby returns the most followed users within radius meters of the coordinates
+	FindPopularNearby(coordinates []float64, radiusMeters float64, exclude []primitive.ObjectID, limit int) ([]User, error)
+	// FindNearby returns users located within radius meters of the coordinates, closest first
+	FindNearby(coordinates []float64, radiusMeters float64, exclude []primitive.ObjectID, limit, offset int) ([]UserWithDistance, error)
+	// FindByInterests returns the most followed users sharing any of the interests
+	FindByInterests(interests []string, exclude []primitive.ObjectID, limit int) ([]User, error)
+	// FindByUsernamePrefix returns the most followed users whose username starts with the prefix
+	FindByUsernamePrefix(prefix string, limit int) ([]User, error)
+	// SetSuspension replaces the user's suspension, nil lifts it
+	SetSuspension(id primitive.ObjectID, suspension *Suspension) error
+	// FindSuspendedUntil returns users whose suspension ends before the time,
+	// only those not told about the ending yet when unnotified is set
+	FindSuspendedUntil(before time.Time, unnotified bool, limit int) ([]User, error)
+	MarkSuspensionEndingNotified(id primitive.ObjectID, until time.Time) error
+	// LiftExpiredSuspension lifts the suspension only if it still ends at until, so a
+	// suspension an admin extended in the meantime is kept
+	LiftExpiredSuspension(id primitive.ObjectID, until time.Time) (bool, error)
+	// SetPrivacy replaces the user's privacy settings
+	SetPrivacy(id primitive.ObjectID, privacy PrivacySettings) error
+	// ScheduleDeletion soft deletes the user and records when to purge them
+	ScheduleDeletion(id primitive.ObjectID, purgeAt time.Time) error
+	// CancelDeletion restores a user whose deletion is scheduled
+	CancelDeletion(id primitive.ObjectID) error
+	// FindDeletionDue returns the users scheduled to be purged before the time, earliest first
+	FindDeletionDue(before time.Time, limit int) ([]User, error)
+	// Purge removes the user document for good
+	Purge(id primitive.ObjectID) error
+	// FindCredentialsByEmail reads the user with their password hash from the
+	// database, skipping the cache. It returns nil when there's no such user.
+	FindCredentialsByEmail(email string) (*User, error)
+	// SetPassword replaces the user's password hash
+	SetPassword(id primitive.ObjectID, passwordHash string) error
+	MarkEmailVerified(id primitive.ObjectID) error
 }
 
 type UserUseCase interface {
@@ -110,7 +184,10 @@ type UserUseCase interface {
 	GetUserByID(id string) (*User, error)
 	GetUserByFirebaseUID(firebaseUID string) (*User, error)
 	GetUserByUsername(username string) (*User, error)
-	UpdateUser(user *User) error
-	DeleteAccount(userID string, authClient interface{}) error
+	// GetProfileByUsername returns the user's profile redacted to what the viewer may see
+	GetProfileByUsername(viewerID primitive.ObjectID, username string) (*ProfileView, error)
+	// UpdateUser saves the profile and records what actorID changed on it
+	UpdateUser(actorID primitive.ObjectID, user *User) error
 	GetUserList(req *UserListRequest) (*UserListResponse, error)
+	GetUsersByIDs(ids []string) ([]BatchItem, error)
 }
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UsernameUseCase interface {
+	SuggestUsernames(base string, limit int) ([]string, error)
+	ValidateUsername(username string) error
+
+	// Reserved username management
+	CreateReservedUsername(reserved *ReservedUsername) error
+	UpdateReservedUsername(id primitive.ObjectID, category ReservedUsernameCategory, reason string, isPremium bool) (*ReservedUsername, error)
+	DeleteReservedUsername(id primitive.ObjectID) error
+	ListReservedUsernames(category string, limit, offset int) ([]ReservedUsername, error)
+	ClaimPremiumUsername(userID primitive.ObjectID, username string) (*User, error)
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,11 +16,12 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/config"
-	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/delivery/auth"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/delivery/http/handler"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/delivery/http/middleware"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/delivery/websocket"
 	_ "github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/docs" // swagger docs
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/migrations"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/repository"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/usecase"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
@@ -45,14 +51,33 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Cancelled on SIGINT or SIGTERM to shut the server and background jobs down
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers stop with ctx. Shutdown waits for them before closing the databases.
+	var workers sync.WaitGroup
+	runWorker := func(run func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+
 	// Initialize Firebase Admin
 	firebaseApp, err := config.InitFirebase(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Create auth adapter
-	systemAuthAdapter := auth.NewSystemAuthAdapter(cfg.JWTSecret)
+	// Load JWT signing keys and keep them in sync with the secret store
+	jwtKeys, err := config.InitJWTKeySet(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go config.WatchJWTKeySet(cfg, jwtKeys)
+	tokenPolicy := cfg.GetTokenPolicy()
 
 	authClient, err := firebaseApp.Auth(context.Background())
 	if err != nil {
@@ -65,6 +90,24 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Apply pending migrations before serving. When another instance is applying
+	// them already, this one starts without waiting.
+	if cfg.MigrateOnStart {
+		runner, err := migrations.NewRunner(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		applied, err := runner.Up(context.Background(), 0)
+		switch {
+		case errors.Is(err, migrations.ErrLocked):
+			log.Printf("Skipping migrations: %v", err)
+		case err != nil:
+			log.Fatalf("Failed to apply migrations: %v", err)
+		default:
+			log.Printf("Applied %d migration(s) %v", len(applied), applied)
+		}
+	}
+
 	// Initialize Redis client
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.RedisURI,
@@ -81,40 +124,230 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db, redisClient)
+	if err := userRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create user indexes: %v", err)
+	}
 	postRepo := repository.NewPostRepository(db, redisClient)
+	if err := postRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create post indexes: %v", err)
+	}
+	runWorker(usecase.NewPostCounterFlusher(postRepo, cfg.GetPostCounterFlushInterval(), 500).Run)
 	followRepo := repository.NewFollowRepository(db)
 	friendshipRepo := repository.NewFriendshipRepository(db)
 	notificationRepo := repository.NewNotificationRepository(db, redisClient)
 	commentRepo := repository.NewCommentRepository(db, redisClient)
-	reactionRepo := repository.NewReactionRepository(db)
+	reactionRepo := repository.NewReactionRepository(db, redisClient)
 	subPostRepo := repository.NewSubPostRepository(db, redisClient)
 	storyRepo := repository.NewStoryRepository(db, redisClient)
-	chatRepo := repository.NewChatRepository(db)
+	storyInteractionRepo := repository.NewStoryInteractionRepository(db, redisClient)
+	chatRepo := repository.NewChatRepository(db, redisClient)
+	if err := chatRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create chat indexes: %v", err)
+	}
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	moderationJobRepo := repository.NewModerationJobRepository(db)
+	dataExportRepo := repository.NewDataExportRepository(db)
+	if err := dataExportRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create data export indexes: %v", err)
+	}
+	deviceTokenRepo := repository.NewDeviceTokenRepository(db)
+	reservedUsernameRepo := repository.NewReservedUsernameRepository(db)
+	onboardingStepRepo := repository.NewOnboardingStepRepository(db)
+	interestRepo := repository.NewInterestRepository(db)
+	profileVisitRepo := repository.NewProfileVisitRepository(db, redisClient)
+	profileChangeRepo := repository.NewProfileChangeRepository(db)
+	runWorker(usecase.NewProfileVisitFlusher(profileVisitRepo, cfg.GetProfileVisitFlushInterval(), 500).Run)
+	translationRepo := repository.NewTranslationRepository(cfg.TranslateAPIKey)
+	feedRepo := repository.NewFeedRepository(redisClient)
+	feedLayoutRepo := repository.NewFeedLayoutRepository(db, redisClient)
+	apiUsageRepo := repository.NewAPIUsageRepository(redisClient)
+	rateLimitRepo := repository.NewRateLimitRepository(redisClient)
+	searchHistoryRepo := repository.NewSearchHistoryRepository(redisClient)
+	mentionRepo := repository.NewMentionRepository(redisClient)
+	followSuggestionRepo := repository.NewFollowSuggestionRepository(redisClient)
+	hashtagRepo := repository.NewHashtagRepository(db, redisClient)
+	if err := hashtagRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create tag indexes: %v", err)
+	}
+	searchRepo := repository.NewSearchRepository(db)
+	if err := searchRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create search indexes: %v", err)
+	}
 	fileRepo, err := repository.NewFileStorage(cfg.FirebaseCredentialsPath, cfg.FirebaseStorageBucket)
 	if err != nil {
 		log.Fatal(err)
 	}
+	storedFileRepo := repository.NewStoredFileRepository(db)
+	if err := storedFileRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create file indexes: %v", err)
+	}
+	mediaBlobRepo := repository.NewMediaBlobRepository(db)
+	if err := mediaBlobRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create media blob indexes: %v", err)
+	}
+	bannedMediaRepo := repository.NewBannedMediaRepository(db)
+	if err := bannedMediaRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create banned media indexes: %v", err)
+	}
+	postDraftRepo := repository.NewPostDraftRepository(db)
+	if err := postDraftRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create post draft indexes: %v", err)
+	}
+	reportRepo := repository.NewReportRepository(db)
+	if err := reportRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report indexes: %v", err)
+	}
+	notificationPreferencesRepo := repository.NewNotificationPreferencesRepository(db)
+	if err := notificationPreferencesRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create notification preferences indexes: %v", err)
+	}
+	shortLinkRepo := repository.NewShortLinkRepository(db, redisClient)
+	if err := shortLinkRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create short link indexes: %v", err)
+	}
+
+	messagingClient, err := firebaseApp.Messaging(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	pushSender := repository.NewFCMPushSender(messagingClient)
+
+	var analyticsSink domain.AnalyticsSink
+	switch cfg.AnalyticsSink {
+	case "redis":
+		analyticsSink = repository.NewRedisStreamAnalyticsSink(redisClient, cfg.AnalyticsStream, int64(cfg.AnalyticsStreamMaxLen))
+	default:
+		analyticsSink = repository.NewLogAnalyticsSink()
+	}
+
+	var emailSender domain.EmailSender
+	switch cfg.EmailSender {
+	case "smtp":
+		emailSender = repository.NewSMTPEmailSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom)
+	default:
+		emailSender = repository.NewLogEmailSender()
+	}
+
+	// Domain events are written to an outbox and exported in the background when a sink is configured
+	var domainEventRepo domain.DomainEventRepository
+	switch cfg.EventExportSink {
+	case "redis":
+		domainEventRepo = repository.NewDomainEventRepository(db)
+		if err := domainEventRepo.EnsureIndexes(context.Background()); err != nil {
+			log.Printf("Failed to create domain event indexes: %v", err)
+		}
+		sink := repository.NewRedisStreamDomainEventSink(redisClient, cfg.EventExportStream, int64(cfg.EventExportStreamMaxLen))
+		runWorker(usecase.NewDomainEventExporter(domainEventRepo, sink, cfg.GetEventExportInterval(), 500).Run)
+	case "":
+	default:
+		log.Printf("Unknown EVENT_EXPORT_SINK %q, domain event export is disabled", cfg.EventExportSink)
+	}
+	domainEvents := usecase.NewDomainEventPublisher(domainEventRepo)
+
+	// WebSocket hub, shared with use cases that push realtime events.
+	// Broadcasts go through Redis pub/sub so they reach clients on every instance.
+	hub := websocket.NewHub(redisClient)
 
 	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo)
-	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, userRepo)
-	postUseCase := usecase.NewPostUseCase(postRepo, subPostRepo, userRepo, notificationUseCase)
-	storyUseCase := usecase.NewStoryUseCase(storyRepo, userRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, profileChangeRepo, followRepo, friendshipRepo, domainEvents)
+	usernameUseCase := usecase.NewUsernameUseCase(userRepo, reservedUsernameRepo, profileChangeRepo)
+	deviceUseCase := usecase.NewDeviceUseCase(deviceTokenRepo, pushSender)
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, userRepo, postRepo, commentRepo, subPostRepo, hub, deviceUseCase, mentionRepo)
+	followUseCase := usecase.NewFollowUseCase(followRepo, notificationUseCase, cfg.GetHotContentPolicy(), userRepo, followSuggestionRepo, friendshipRepo)
+	privacyUseCase := usecase.NewPrivacyUseCase(userRepo, friendshipRepo)
+	postUseCase := usecase.NewPostUseCase(postRepo, subPostRepo, hashtagRepo, storedFileRepo, userRepo, friendshipRepo, followUseCase, notificationUseCase, domainEvents)
+	postDraftUseCase := usecase.NewPostDraftUseCase(postDraftRepo, postUseCase)
+	nearbyUseCase := usecase.NewNearbyUseCase(postRepo, userRepo, followUseCase)
 	authUseCase := usecase.NewAuthUseCase(
 		userRepo,
 		authClient,
 		redisClient,
-		cfg.JWTSecret,
+		jwtKeys,
+		tokenPolicy,
 		cfg.RefreshTokenSecret,
 		cfg.GetJWTExpiry(),
 		cfg.GetRefreshTokenExpiry(),
+		domainEvents,
+		emailSender,
+		cfg.EmailLinkBaseURL,
+	)
+	friendshipUseCase := usecase.NewFriendshipUseCase(friendshipRepo, notificationUseCase, followUseCase, privacyUseCase)
+	commentUseCase := usecase.NewCommentUseCase(commentRepo, postRepo, notificationUseCase, userRepo, translationRepo, cfg.GetHotContentPolicy(), followUseCase, privacyUseCase)
+	reactionUseCase := usecase.NewReactionUseCase(reactionRepo, postRepo, commentRepo, notificationUseCase, cfg.GetHotContentPolicy(), friendshipRepo)
+	subPostUseCase := usecase.NewSubPostUseCase(subPostRepo, postRepo, friendshipRepo, userRepo, notificationUseCase)
+	feedUseCase := usecase.NewFeedUseCase(postRepo, userRepo, followRepo, friendshipRepo, feedRepo)
+	feedLayoutUseCase := usecase.NewFeedLayoutUseCase(feedLayoutRepo)
+	hashtagUseCase := usecase.NewHashtagUseCase(hashtagRepo, postRepo, userRepo)
+	searchUseCase := usecase.NewSearchUseCase(searchRepo, userRepo, searchHistoryRepo)
+	mentionUseCase := usecase.NewMentionUseCase(mentionRepo, userRepo, friendshipRepo, postRepo, commentRepo, chatRepo)
+	analyticsUseCase := usecase.NewAnalyticsUseCase(analyticsSink)
+	apiUsageUseCase := usecase.NewAPIUsageUseCase(apiUsageRepo)
+	fileUseCase := usecase.NewFileUseCase(fileRepo, storedFileRepo, mediaBlobRepo, bannedMediaRepo, chatRepo, cfg.GetStorageQuota(), cfg.ImageProcessing)
+	chatUseCase := usecase.NewChatUsecase(chatRepo, userRepo, friendshipRepo, notificationUseCase, deviceUseCase, fileRepo, jwtKeys, hub, domainEvents, followUseCase, privacyUseCase, storedFileRepo)
+	runWorker(usecase.NewPresenceSweeper(chatUseCase, 15*time.Second).Run)
+	storyUseCase := usecase.NewStoryUseCase(storyRepo, userRepo, fileRepo, hub, chatUseCase, notificationUseCase, followUseCase)
+	storyInsightsUseCase := usecase.NewStoryInsightsUseCase(storyInteractionRepo, storyRepo)
+	dataExportUseCase := usecase.NewDataExportUseCase(dataExportRepo, userRepo, postRepo, commentRepo, reactionRepo, chatRepo, fileRepo, notificationUseCase)
+	runWorker(usecase.NewDataExportWorker(dataExportUseCase, 10*time.Second).Run)
+	digestUseCase := usecase.NewDigestUseCase(notificationPreferencesRepo, userRepo, followRepo, hashtagRepo, postRepo, notificationUseCase)
+	accountDeletionUseCase := usecase.NewAccountDeletionUseCase(userRepo, postRepo, subPostRepo, commentRepo, reactionRepo, chatRepo, authClient)
+	suspensionUseCase := usecase.NewSuspensionUseCase(userRepo, auditLogRepo, notificationUseCase)
+	moderationUseCase := usecase.NewModerationUseCase(moderationJobRepo, auditLogRepo, postUseCase, commentUseCase, suspensionUseCase)
+	runWorker(usecase.NewModerationWorker(moderationUseCase, 5*time.Second).Run)
+	runWorker(usecase.NewOpsMonitor(hub, moderationJobRepo, 10*time.Second).Run)
+	reportUseCase := usecase.NewReportUseCase(reportRepo, postRepo, commentRepo, userRepo, chatRepo, friendshipRepo, auditLogRepo)
+	contentModerationUseCase := usecase.NewContentModerationUseCase(postRepo, commentRepo, postUseCase, commentUseCase, auditLogRepo)
+	adminUseCase := usecase.NewAdminUseCase(userRepo, postRepo, subPostRepo, hashtagRepo, storedFileRepo, mediaBlobRepo, bannedMediaRepo, storyRepo, auditLogRepo, cfg.GetSoftDeleteRetention())
+	onboardingUseCase := usecase.NewOnboardingUseCase(onboardingStepRepo, userRepo, postRepo)
+	interestUseCase := usecase.NewInterestUseCase(interestRepo)
+	profileVisitUseCase := usecase.NewProfileVisitUseCase(profileVisitRepo)
+	profileChangeUseCase := usecase.NewProfileChangeUseCase(profileChangeRepo, userRepo)
+	shortLinkUseCase := usecase.NewShortLinkUseCase(shortLinkRepo, postRepo, userRepo, friendshipRepo, followUseCase, cfg.ShortLinkBaseURL, cfg.GetPermalinkBaseURL(), cfg.GetShortLinkTTL())
+	permalinkUseCase := usecase.NewPermalinkUseCase(postRepo, commentRepo, userRepo, friendshipRepo, followUseCase, chatRepo, shortLinkUseCase, jwtKeys, cfg.PermalinkHosts, cfg.PermalinkScheme)
+	serviceAccountUseCase := usecase.NewServiceAccountUseCase(cfg.ServiceAccounts, jwtKeys, tokenPolicy, cfg.GetServiceTokenExpiry())
+
+	// Periodic maintenance, also runnable on demand through the internal job routes
+	scheduler := usecase.NewScheduler(
+		usecase.ScheduledJob{
+			Name:     "archiveExpiredStories",
+			Interval: cfg.GetStoryArchiveInterval(),
+			Run: func(ctx context.Context) error {
+				return storyUseCase.ArchiveExpiredStories()
+			},
+		},
+		usecase.ScheduledJob{
+			Name:     "processSuspensions",
+			Interval: cfg.GetSuspensionProcessInterval(),
+			Run: func(ctx context.Context) error {
+				_, err := suspensionUseCase.ProcessSuspensions()
+				return err
+			},
+		},
+		usecase.ScheduledJob{
+			Name:     "purgeDeletedAccounts",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) error {
+				_, err := accountDeletionUseCase.PurgeDueAccounts()
+				return err
+			},
+		},
+		usecase.ScheduledJob{
+			Name:     "sendTrendingDigests",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) error {
+				_, err := digestUseCase.SendTrendingDigests()
+				return err
+			},
+		},
+		usecase.ScheduledJob{
+			Name:     "trimSearchSuggestions",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) error {
+				return searchUseCase.TrimSuggestions()
+			},
+		},
 	)
-	followUseCase := usecase.NewFollowUseCase(followRepo, notificationUseCase)
-	friendshipUseCase := usecase.NewFriendshipUseCase(friendshipRepo, notificationUseCase)
-	commentUseCase := usecase.NewCommentUseCase(commentRepo, postRepo, notificationUseCase, userRepo)
-	reactionUseCase := usecase.NewReactionUseCase(reactionRepo, postRepo, commentRepo, notificationUseCase)
-	subPostUseCase := usecase.NewSubPostUseCase(subPostRepo, postRepo)
-	chatUseCase := usecase.NewChatUsecase(chatRepo, userRepo, notificationUseCase)
+	scheduler.Start(ctx)
 
 	// Initialize Fiber app with performance configurations
 	app := fiber.New(fiber.Config{
@@ -124,6 +357,17 @@ func main() {
 		CaseSensitive: true,
 		BodyLimit:     4 * 1024 * 1024, // 4MB
 		Concurrency:   256,
+		ErrorHandler:  utils.ErrorHandler,
+	})
+
+	// Probes come before every middleware, so they're never cached, logged or limited.
+	// The instance is ready once it listens, until it starts shutting down.
+	health := handler.NewHealthHandler(db, redisClient)
+	app.Get("/api/live", health.Live)
+	app.Get("/api/ready", health.Ready)
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		health.SetReady(true)
+		return nil
 	})
 
 	// CORS
@@ -149,30 +393,66 @@ func main() {
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
 	// Health check - public endpoint
-	app.Get("/api", handler.NewHealthHandler(db, redisClient).Health)
+	app.Get("/api", health.Health)
 
 	// Middleware
 	app.Use(utils.RequestLogger())
+	app.Use(utils.ResponseMetrics())
+	if rewriter := utils.NewMediaURLRewriter(cfg.GetMediaCDN()); rewriter != nil {
+		app.Use(middleware.MediaCDNMiddleware(rewriter))
+	}
 
 	// Routes
 	api := app.Group("/api")
+	rateLimits := cfg.GetRateLimits()
+	rateLimit := func(bucket string) fiber.Handler {
+		return middleware.RateLimitMiddleware(rateLimitRepo, bucket, rateLimits[bucket])
+	}
 
 	// WebSocket endpoint (outside protected routes)
-	websocket.NewWebSocketHandler(api, chatUseCase, systemAuthAdapter)
+	websocket.NewWebSocketHandler(api, hub, chatUseCase, storyUseCase, authUseCase)
+	websocket.NewOpsSocketHandler(api, hub, authUseCase, userRepo)
 
 	// Public auth routes
-	auth := api.Group("/auth")
+	auth := api.Group("/auth", rateLimit(domain.RateLimitAuth))
 	auth.Post("/verifyTokenFirebase", handler.NewAuthHandler(authUseCase).VerifyTokenFirebase)
 	auth.Post("/refresh", handler.NewAuthHandler(authUseCase).RefreshToken)
 	auth.Post("/logout", handler.NewAuthHandler(authUseCase).Logout)
-	auth.Post("/createTestToken", handler.NewAuthHandler(authUseCase).CreateTestToken)
+	auth.Post("/register", handler.NewAuthHandler(authUseCase).Register)
+	auth.Post("/login", handler.NewAuthHandler(authUseCase).Login)
+	auth.Post("/verify-email", handler.NewAuthHandler(authUseCase).VerifyEmail)
+	auth.Post("/verify-email/resend", handler.NewAuthHandler(authUseCase).ResendEmailVerification)
+	auth.Post("/password/forgot", handler.NewAuthHandler(authUseCase).ForgotPassword)
+	auth.Post("/password/reset", handler.NewAuthHandler(authUseCase).ResetPassword)
+	auth.Post("/service-token", handler.NewServiceAccountHandler(serviceAccountUseCase).IssueToken)
+	auth.Post("/ws-ticket", middleware.AuthMiddleware(jwtKeys, tokenPolicy, suspensionUseCase), handler.NewAuthHandler(authUseCase).IssueWSTicket)
+	auth.Post("/introspect", middleware.ServiceAuthMiddleware(serviceAccountUseCase, domain.ScopeTokensIntrospect), handler.NewAuthHandler(authUseCase).Introspect)
+	if !cfg.IsProduction() {
+		auth.Post("/createTestToken", handler.NewAuthHandler(authUseCase).CreateTestToken)
+	}
+
+	// Public short link redirects
+	shortLinkHandler := handler.NewShortLinkHandler(api.Group("/s"), shortLinkUseCase)
+
+	// Internal routes for background workers and cron runners (service account tokens only)
+	internal := api.Group("/internal")
+	jobs := internal.Group("/jobs", middleware.ServiceAuthMiddleware(serviceAccountUseCase, domain.ScopeJobsRun))
+	metrics := internal.Group("/metrics", middleware.ServiceAuthMiddleware(serviceAccountUseCase, domain.ScopeMetricsRead))
+	handler.NewJobHandler(jobs, storyUseCase, suspensionUseCase)
+	handler.NewMetricsHandler(metrics)
 
 	// Protected routes
-	protectedApi := api.Group("", middleware.AuthMiddleware(cfg.JWTSecret))
+	protectedApi := api.Group("", middleware.AuthMiddleware(jwtKeys, tokenPolicy, suspensionUseCase), middleware.UsageMiddleware(apiUsageUseCase), rateLimit(domain.RateLimitDefault), middleware.MediaVariantMiddleware(mediaBlobRepo))
+	protectedApi.Use("/upload", rateLimit(domain.RateLimitUpload))
 
 	// Create route groups
 	users := protectedApi.Group("/users")
 	posts := protectedApi.Group("/posts")
+	feed := protectedApi.Group("/feed")
+	tags := protectedApi.Group("/tags")
+	events := protectedApi.Group("/events")
+	search := protectedApi.Group("/search", rateLimit(domain.RateLimitSearch))
+	interests := protectedApi.Group("/interests")
 	comments := protectedApi.Group("/comments")
 	reactions := protectedApi.Group("/reactions")
 	follows := protectedApi.Group("/follows")
@@ -180,20 +460,98 @@ func main() {
 	notifications := protectedApi.Group("/notifications")
 	stories := protectedApi.Group("/stories")
 	chats := protectedApi.Group("/chat")
+	reports := protectedApi.Group("/reports")
+	admin := protectedApi.Group("/admin", middleware.AdminMiddleware(userRepo))
 
 	// Initialize handlers with their respective route groups
-	handler.NewUserHandler(users, userUseCase)
+	// Before the user and post routes, whose /:username and /:id would take "nearby"
+	handler.NewNearbyHandler(posts, users, nearbyUseCase)
+	handler.NewDeviceHandler(users.Group("/devices"), deviceUseCase)
+	handler.NewOnboardingHandler(users.Group("/me/onboarding"), admin.Group("/onboarding-steps"), onboardingUseCase)
+	handler.NewProfileVisitHandler(users.Group("/me/insights"), profileVisitUseCase)
+	handler.NewProfileChangeHandler(users.Group("/me/profile-changes"), admin, profileChangeUseCase)
+	handler.NewAPIUsageHandler(users.Group("/me/usage"), admin, apiUsageUseCase)
+	handler.NewMentionHandler(users, mentionUseCase)
+	handler.NewBlockHandler(users, followUseCase)
+	handler.NewPrivacyHandler(users, privacyUseCase)
+	handler.NewAccountDeletionHandler(users, accountDeletionUseCase)
+	handler.NewDataExportHandler(users, dataExportUseCase)
+	handler.NewUserHandler(users, userUseCase, usernameUseCase, interestUseCase, profileVisitUseCase)
+	handler.NewInterestHandler(interests, admin.Group("/interests"), interestUseCase)
 	handler.NewFollowHandler(follows, followUseCase)
 	handler.NewFriendshipHandler(friendships, friendshipUseCase)
+	// Before the post routes, whose /:id would take "drafts"
+	handler.NewPostDraftHandler(posts.Group("/drafts"), postDraftUseCase)
 	handler.NewPostHandler(posts, postUseCase)
 	handler.NewSubPostHandler(posts, subPostUseCase)
+	handler.NewFeedLayoutHandler(feed, admin.Group("/feed-layout"), feedLayoutUseCase)
+	handler.NewFeedHandler(feed, feedUseCase)
+	handler.NewHashtagHandler(tags, hashtagUseCase)
+	handler.NewAnalyticsHandler(events, analyticsUseCase)
+	handler.NewSearchHandler(search, searchUseCase)
 	handler.NewCommentHandler(comments, commentUseCase, userUseCase)
-	handler.NewReactionHandler(reactions, reactionUseCase)
+	handler.NewReactionHandler(reactions, posts, reactionUseCase)
+	handler.NewNotificationPreferencesHandler(notifications, digestUseCase)
 	handler.NewNotificationHandler(notifications, notificationUseCase)
+	handler.NewStoryInsightsHandler(stories, storyInsightsUseCase)
 	handler.NewStoryHandler(stories, storyUseCase)
-	handler.NewFileHandler(protectedApi, fileRepo)
+	fileHandler := handler.NewFileHandler(protectedApi, fileUseCase)
+	users.Get("/me/storage", fileHandler.GetStorageUsage)
 	handler.NewChatHandler(chats, chatUseCase)
+	handler.NewPermalinkHandler(protectedApi, permalinkUseCase)
+	posts.Post("/:id/share-link", shortLinkHandler.CreatePostLink)
+	users.Post("/:id/share-link", shortLinkHandler.CreateProfileLink)
+	handler.NewAdminHandler(admin, adminUseCase)
+	handler.NewSuspensionHandler(admin, suspensionUseCase)
+	handler.NewModerationHandler(admin.Group("/moderation-jobs"), moderationUseCase)
+	handler.NewReportHandler(reports, admin.Group("/reports"), reportUseCase)
+	handler.NewContentModerationHandler(admin.Group("/content"), contentModerationUseCase)
+	handler.NewReservedUsernameHandler(admin.Group("/reserved-usernames"), usernameUseCase)
 
 	// Start server
-	log.Fatal(app.Listen(cfg.ServerAddress))
+	go func() {
+		if err := app.Listen(cfg.ServerAddress); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+	health.SetReady(false)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.GetShutdownTimeout())
+	defer cancel()
+
+	// Sockets are hijacked from the server, so the hub closes them while the
+	// server stops listening and finishes the requests in flight
+	hubClosed := make(chan struct{})
+	go func() {
+		defer close(hubClosed)
+		if err := hub.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to close every WebSocket in time: %v", err)
+		}
+	}()
+	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down the server: %v", err)
+	}
+	<-hubClosed
+
+	jobsDone := make(chan struct{})
+	go func() {
+		scheduler.Wait()
+		workers.Wait()
+		close(jobsDone)
+	}()
+	select {
+	case <-jobsDone:
+	case <-shutdownCtx.Done():
+		log.Println("Background jobs did not stop in time")
+	}
+
+	if err := redisClient.Close(); err != nil {
+		log.Printf("Failed to close Redis: %v", err)
+	}
+	if err := db.Client().Disconnect(context.Background()); err != nil {
+		log.Printf("Failed to disconnect from MongoDB: %v", err)
+	}
+	log.Println("Shut down")
 }
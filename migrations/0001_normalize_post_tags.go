@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// normalizePostTags rewrites tags saved before tags were normalized on write
+var normalizePostTags = Migration{
+	Version: 1,
+	Name:    "normalize_post_tags",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		posts := db.Collection("posts")
+
+		cursor, err := posts.Find(ctx,
+			bson.M{"tags.0": bson.M{"$exists": true}},
+			options.Find().SetProjection(bson.M{"tags": 1}),
+		)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		writer := &bulkWriter{collection: posts}
+		for cursor.Next(ctx) {
+			var post struct {
+				ID   primitive.ObjectID `bson:"_id"`
+				Tags []string           `bson:"tags"`
+			}
+			if err := cursor.Decode(&post); err != nil {
+				return err
+			}
+
+			tags := utils.NormalizeHashtags(post.Tags)
+			if reflect.DeepEqual(tags, post.Tags) {
+				continue
+			}
+			err := writer.add(ctx, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": post.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"tags": tags}}))
+			if err != nil {
+				return err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+		return writer.flush(ctx)
+	},
+}
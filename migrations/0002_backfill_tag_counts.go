@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// backfillTagCounts rebuilds the post counts of the tags collection from live posts
+var backfillTagCounts = Migration{
+	Version: 2,
+	Name:    "backfill_tag_counts",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{
+				"deletedAt": bson.M{"$exists": false},
+				"tags.0":    bson.M{"$exists": true},
+			}}},
+			{{Key: "$unwind", Value: "$tags"}},
+			{{Key: "$group", Value: bson.M{
+				"_id":        "$tags",
+				"postCount":  bson.M{"$sum": 1},
+				"lastUsedAt": bson.M{"$max": "$createdAt"},
+			}}},
+		}
+
+		cursor, err := db.Collection("posts").Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		writer := &bulkWriter{collection: db.Collection("tags")}
+		for cursor.Next(ctx) {
+			var tag struct {
+				Tag        string    `bson:"_id"`
+				PostCount  int64     `bson:"postCount"`
+				LastUsedAt time.Time `bson:"lastUsedAt"`
+			}
+			if err := cursor.Decode(&tag); err != nil {
+				return err
+			}
+
+			// Counts are set rather than incremented so the backfill can be rerun
+			err := writer.add(ctx, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"tag": tag.Tag}).
+				SetUpdate(bson.M{
+					"$set": bson.M{
+						"postCount":  tag.PostCount,
+						"lastUsedAt": tag.LastUsedAt,
+					},
+					"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+				}).
+				SetUpsert(true))
+			if err != nil {
+				return err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+		return writer.flush(ctx)
+	},
+}
@@ -0,0 +1,119 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// splitUserInterests maps the free text interests of users onto the interest
+// taxonomy and moves values it doesn't know to legacyInterests. Seed the
+// taxonomy before running it.
+var splitUserInterests = Migration{
+	Version: 3,
+	Name:    "split_user_interests",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		slugByTerm, err := interestTerms(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		users := db.Collection("users")
+		cursor, err := users.Find(ctx,
+			bson.M{"$or": bson.A{
+				bson.M{"interests.0": bson.M{"$exists": true}},
+				bson.M{"legacyInterests.0": bson.M{"$exists": true}},
+			}},
+			options.Find().SetProjection(bson.M{"interests": 1, "legacyInterests": 1}),
+		)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		writer := &bulkWriter{collection: users}
+		for cursor.Next(ctx) {
+			var user struct {
+				ID              primitive.ObjectID `bson:"_id"`
+				Interests       []string           `bson:"interests"`
+				LegacyInterests []string           `bson:"legacyInterests"`
+			}
+			if err := cursor.Decode(&user); err != nil {
+				return err
+			}
+
+			// Slugs resolve to themselves, so rerunning only picks up new taxonomy entries
+			slugs := make([]string, 0, len(user.Interests))
+			legacy := make([]string, 0)
+			seen := make(map[string]bool)
+			for _, value := range append(user.Interests, user.LegacyInterests...) {
+				term := strings.ToLower(strings.TrimSpace(value))
+				if term == "" || seen[term] {
+					continue
+				}
+				seen[term] = true
+				if slug, ok := slugByTerm[term]; ok {
+					if !seen["slug:"+slug] {
+						seen["slug:"+slug] = true
+						slugs = append(slugs, slug)
+					}
+					continue
+				}
+				legacy = append(legacy, strings.TrimSpace(value))
+			}
+			if reflect.DeepEqual(slugs, user.Interests) && len(legacy) == len(user.LegacyInterests) {
+				continue
+			}
+
+			update := bson.M{"$set": bson.M{"interests": slugs, "legacyInterests": legacy}}
+			if len(legacy) == 0 {
+				update = bson.M{
+					"$set":   bson.M{"interests": slugs},
+					"$unset": bson.M{"legacyInterests": ""},
+				}
+			}
+			err := writer.add(ctx, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": user.ID}).
+				SetUpdate(update))
+			if err != nil {
+				return err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+		return writer.flush(ctx)
+	},
+}
+
+// interestTerms maps every search term of the active interests to its slug
+func interestTerms(ctx context.Context, db *mongo.Database) (map[string]string, error) {
+	cursor, err := db.Collection("interests").Find(ctx,
+		bson.M{"isActive": true},
+		options.Find().SetProjection(bson.M{"slug": 1, "searchTerms": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	slugByTerm := make(map[string]string)
+	for cursor.Next(ctx) {
+		var interest struct {
+			Slug        string   `bson:"slug"`
+			SearchTerms []string `bson:"searchTerms"`
+		}
+		if err := cursor.Decode(&interest); err != nil {
+			return nil, err
+		}
+		for _, term := range interest.SearchTerms {
+			slugByTerm[term] = interest.Slug
+		}
+	}
+	return slugByTerm, cursor.Err()
+}
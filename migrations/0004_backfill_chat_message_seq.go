@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// backfillChatMessageSeq numbers the existing messages of every room in the order
+// they were sent and sets the room counters to match. Messages are renumbered from
+// 1 on every run, so run it before deploying the servers that assign seqs.
+var backfillChatMessageSeq = Migration{
+	Version: 4,
+	Name:    "backfill_chat_message_seq",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		rooms := db.Collection("chatRooms")
+		messages := db.Collection("chatMessages")
+
+		roomCursor, err := rooms.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return err
+		}
+		defer roomCursor.Close(ctx)
+
+		roomWriter := &bulkWriter{collection: rooms}
+		for roomCursor.Next(ctx) {
+			var room struct {
+				ID primitive.ObjectID `bson:"_id"`
+			}
+			if err := roomCursor.Decode(&room); err != nil {
+				return err
+			}
+
+			seq, err := numberRoomMessages(ctx, messages, room.ID.Hex())
+			if err != nil {
+				return err
+			}
+
+			err = roomWriter.add(ctx, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": room.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"lastMessageSeq": seq}}))
+			if err != nil {
+				return err
+			}
+		}
+		if err := roomCursor.Err(); err != nil {
+			return err
+		}
+		return roomWriter.flush(ctx)
+	},
+}
+
+// numberRoomMessages sets the seq of the room's messages outside threads and returns the last one
+func numberRoomMessages(ctx context.Context, messages *mongo.Collection, roomID string) (int64, error) {
+	// The seqs are cleared first so renumbering never collides on the unique room_message_seq index
+	_, err := messages.UpdateMany(ctx,
+		bson.M{"roomId": roomID, "seq": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"seq": ""}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	cursor, err := messages.Find(ctx,
+		bson.M{"roomId": roomID, "threadId": bson.M{"$exists": false}},
+		options.Find().
+			SetProjection(bson.M{"_id": 1}).
+			SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var seq int64
+	writer := &bulkWriter{collection: messages}
+	for cursor.Next(ctx) {
+		var message struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&message); err != nil {
+			return 0, err
+		}
+		seq++
+		err := writer.add(ctx, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": message.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"seq": seq}}))
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, err
+	}
+	return seq, writer.flush(ctx)
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// deletedStoryRetention is how long deleted stories are kept before MongoDB removes them
+const deletedStoryRetention = 30 * 24 * time.Hour
+
+// createCoreIndexes creates the indexes of the hottest queries that no repository
+// creates itself: a user's posts, a user's notifications and the removal of deleted
+// stories. Expired stories are archived rather than deleted, so only deleted ones
+// expire. Usernames, locations and chat history are indexed by their repositories.
+var createCoreIndexes = Migration{
+	Version: 5,
+	Name:    "create_core_indexes",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		indexes := map[string][]mongo.IndexModel{
+			"posts": {{
+				Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+				Options: options.Index().SetName("user_posts"),
+			}},
+			"notifications": {{
+				Keys:    bson.D{{Key: "recipientId", Value: 1}, {Key: "createdAt", Value: -1}},
+				Options: options.Index().SetName("recipient_notifications"),
+			}},
+			"stories": {{
+				Keys: bson.D{{Key: "deletedAt", Value: 1}},
+				Options: options.Index().SetName("deleted_stories_ttl").
+					SetExpireAfterSeconds(int32(deletedStoryRetention.Seconds())).
+					SetPartialFilterExpression(bson.M{"isActive": false}),
+			}},
+		}
+
+		// Creating an index that already exists as is does nothing, so a retry is safe
+		for collection, models := range indexes {
+			if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
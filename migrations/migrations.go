@@ -0,0 +1,206 @@
+// Package migrations runs ordered, one-off data migrations, backfills and index
+// changes against MongoDB. Applied versions are tracked in the migrations collection
+// so each migration runs once.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrLocked is returned when another run holds the migration lock
+var ErrLocked = errors.New("migrations are locked by another run")
+
+// Migration is a data migration. Up should be idempotent, so a run interrupted
+// before the version is recorded can be retried safely.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+}
+
+// registry lists every migration in version order. Append new migrations at the end.
+var registry = []Migration{
+	normalizePostTags,
+	backfillTagCounts,
+	splitUserInterests,
+	backfillChatMessageSeq,
+	createCoreIndexes,
+}
+
+// AppliedMigration is the record of a migration in the migrations collection
+type AppliedMigration struct {
+	Version    int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	AppliedAt  time.Time `bson:"appliedAt"`
+	DurationMs int64     `bson:"durationMs"`
+}
+
+// Status is a registered migration and when it was applied, if it was
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Runner struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	locks      *mongo.Collection
+	migrations []Migration
+}
+
+func NewRunner(db *mongo.Database) (*Runner, error) {
+	for i := 1; i < len(registry); i++ {
+		if registry[i].Version <= registry[i-1].Version {
+			return nil, fmt.Errorf("migration %d (%s) is out of order", registry[i].Version, registry[i].Name)
+		}
+	}
+
+	return &Runner{
+		db:         db,
+		collection: db.Collection("migrations"),
+		locks:      db.Collection("migrations_lock"),
+		migrations: registry,
+	}, nil
+}
+
+func (r *Runner) applied(ctx context.Context) (map[int]AppliedMigration, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []AppliedMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]AppliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Status lists every registered migration in order
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		status := Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies pending migrations in order, up to and including target. A target of 0 applies all of them.
+// It stops at the first failure and returns the versions applied so far.
+func (r *Runner) Up(ctx context.Context, target int) ([]int, error) {
+	if err := r.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.unlock()
+
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]int, 0)
+	for _, migration := range r.migrations {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		log.Printf("Applying migration %d: %s", migration.Version, migration.Name)
+		start := time.Now()
+		if err := migration.Up(ctx, r.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+
+		_, err := r.collection.InsertOne(ctx, AppliedMigration{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			AppliedAt:  time.Now(),
+			DurationMs: time.Since(start).Milliseconds(),
+		})
+		if err != nil {
+			return done, fmt.Errorf("recording migration %d: %w", migration.Version, err)
+		}
+		done = append(done, migration.Version)
+	}
+	return done, nil
+}
+
+// lock makes sure only one run applies migrations at a time
+func (r *Runner) lock(ctx context.Context) error {
+	_, err := r.locks.InsertOne(ctx, bson.M{"_id": "lock", "lockedAt": time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	}
+	return err
+}
+
+func (r *Runner) unlock() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := r.Unlock(ctx); err != nil {
+		log.Printf("Failed to release the migration lock: %v", err)
+	}
+}
+
+// Unlock releases the lock, e.g. after a run was killed while holding it
+func (r *Runner) Unlock(ctx context.Context) error {
+	_, err := r.locks.DeleteOne(ctx, bson.M{"_id": "lock"})
+	return err
+}
+
+// bulkWriteBatchSize bounds the size of bulk writes issued by migrations
+const bulkWriteBatchSize = 500
+
+// bulkWriter collects write models and flushes them in batches
+type bulkWriter struct {
+	collection *mongo.Collection
+	models     []mongo.WriteModel
+}
+
+func (w *bulkWriter) add(ctx context.Context, model mongo.WriteModel) error {
+	w.models = append(w.models, model)
+	if len(w.models) >= bulkWriteBatchSize {
+		return w.flush(ctx)
+	}
+	return nil
+}
+
+func (w *bulkWriter) flush(ctx context.Context) error {
+	if len(w.models) == 0 {
+		return nil
+	}
+	_, err := w.collection.BulkWrite(ctx, w.models, options.BulkWrite().SetOrdered(false))
+	w.models = w.models[:0]
+	return err
+}
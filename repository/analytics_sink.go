@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+type logAnalyticsSink struct{}
+
+// NewLogAnalyticsSink writes events to the application log, for development and small deployments
+func NewLogAnalyticsSink() domain.AnalyticsSink {
+	return &logAnalyticsSink{}
+}
+
+func (s *logAnalyticsSink) Publish(events []domain.AnalyticsEvent) error {
+	logger := utils.NewLogger("LogAnalyticsSink.Publish")
+	logger.LogInput(events)
+	logger.LogOutput(map[string]interface{}{"count": len(events)}, nil)
+	return nil
+}
+
+type redisStreamAnalyticsSink struct {
+	rdb    *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamAnalyticsSink appends events to a Redis stream for a consumer group to ship elsewhere.
+// The stream is trimmed to about maxLen entries.
+func NewRedisStreamAnalyticsSink(rdb *redis.Client, stream string, maxLen int64) domain.AnalyticsSink {
+	return &redisStreamAnalyticsSink{
+		rdb:    rdb,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (s *redisStreamAnalyticsSink) Publish(events []domain.AnalyticsEvent) error {
+	logger := utils.NewLogger("RedisStreamAnalyticsSink.Publish")
+	logger.LogInput(map[string]interface{}{
+		"stream": s.stream,
+		"count":  len(events),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipe := s.rdb.Pipeline()
+	for _, event := range events {
+		eventJSON, err := json.Marshal(event)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.stream,
+			MaxLen: s.maxLen,
+			Approx: true,
+			Values: map[string]interface{}{
+				"name":  event.Name,
+				"event": eventJSON,
+			},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(events)}, nil)
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Fields of the daily usage hashes besides the per route counts
+const (
+	apiUsageTotalField  = "total"
+	apiUsageErrorsField = "errors"
+	apiUsageRoutePrefix = "route:"
+)
+
+// Daily counts outlive the retention by a day so the oldest day stays complete
+const apiUsageTTL = (domain.APIUsageRetentionDays + 1) * 24 * time.Hour
+
+type apiUsageRepository struct {
+	rdb *redis.Client
+}
+
+func NewAPIUsageRepository(rdb *redis.Client) domain.APIUsageRepository {
+	return &apiUsageRepository{
+		rdb: rdb,
+	}
+}
+
+func apiUsageDayKey(userID, day string) string {
+	return fmt.Sprintf("api_usage:%s:%s", userID, day)
+}
+
+func apiActivityKey(userID string) string {
+	return fmt.Sprintf("api_activity:%s", userID)
+}
+
+// Record runs on every authenticated request, so only failures are logged
+func (r *apiUsageRepository) Record(userID string, request domain.APIRequest) error {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		utils.NewLogger("APIUsageRepository.Record").LogOutput(nil, err)
+		return err
+	}
+
+	ctx := context.Background()
+	dayKey := apiUsageDayKey(userID, request.At.UTC().Format(domain.APIUsageDayFormat))
+	activityKey := apiActivityKey(userID)
+	_, err = r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, dayKey, apiUsageTotalField, 1)
+		pipe.HIncrBy(ctx, dayKey, apiUsageRoutePrefix+request.Method+" "+request.Route, 1)
+		if request.Status >= 400 {
+			pipe.HIncrBy(ctx, dayKey, apiUsageErrorsField, 1)
+		}
+		pipe.Expire(ctx, dayKey, apiUsageTTL)
+		pipe.LPush(ctx, activityKey, requestBytes)
+		pipe.LTrim(ctx, activityKey, 0, domain.APIUsageRecentRequests-1)
+		pipe.Expire(ctx, activityKey, apiUsageTTL)
+		return nil
+	})
+	if err != nil {
+		utils.NewLogger("APIUsageRepository.Record").LogOutput(nil, err)
+		return err
+	}
+	return nil
+}
+
+func (r *apiUsageRepository) GetDays(userID string, days []string) ([]domain.APIUsageDay, error) {
+	logger := utils.NewLogger("APIUsageRepository.GetDays")
+	logger.LogInput(userID, days)
+
+	ctx := context.Background()
+	cmds := make([]*redis.MapStringStringCmd, len(days))
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, day := range days {
+			cmds[i] = pipe.HGetAll(ctx, apiUsageDayKey(userID, day))
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	usage := make([]domain.APIUsageDay, len(days))
+	for i, day := range days {
+		usage[i] = domain.APIUsageDay{Date: day, Routes: make(map[string]int64)}
+		for field, value := range cmds[i].Val() {
+			count, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case field == apiUsageTotalField:
+				usage[i].Total = count
+			case field == apiUsageErrorsField:
+				usage[i].Errors = count
+			case strings.HasPrefix(field, apiUsageRoutePrefix):
+				usage[i].Routes[strings.TrimPrefix(field, apiUsageRoutePrefix)] = count
+			}
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"days": len(usage)}, nil)
+	return usage, nil
+}
+
+func (r *apiUsageRepository) GetRecent(userID string, limit int) ([]domain.APIRequest, error) {
+	logger := utils.NewLogger("APIUsageRepository.GetRecent")
+	logger.LogInput(userID, limit)
+
+	values, err := r.rdb.LRange(context.Background(), apiActivityKey(userID), 0, int64(limit)-1).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	requests := make([]domain.APIRequest, 0, len(values))
+	for _, value := range values {
+		var request domain.APIRequest
+		if err := json.Unmarshal([]byte(value), &request); err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+		requests = append(requests, request)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(requests)}, nil)
+	return requests, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogRepository(db *mongo.Database) domain.AuditLogRepository {
+	return &auditLogRepository{
+		collection: db.Collection("audit_logs"),
+	}
+}
+
+func (r *auditLogRepository) Create(log *domain.AuditLog) error {
+	logger := utils.NewLogger("AuditLogRepository.Create")
+	logger.LogInput(log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	log.CreatedAt = now
+	log.UpdatedAt = now
+	log.IsActive = true
+	log.Version = 1
+
+	result, err := r.collection.InsertOne(ctx, log)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	log.ID = result.InsertedID.(primitive.ObjectID)
+
+	logger.LogOutput(log, nil)
+	return nil
+}
+
+func (r *auditLogRepository) FindByTarget(targetType, targetID string, limit, offset int) ([]domain.AuditLog, error) {
+	logger := utils.NewLogger("AuditLogRepository.FindByTarget")
+	logger.LogInput(map[string]interface{}{
+		"targetType": targetType,
+		"targetId":   targetID,
+		"limit":      limit,
+		"offset":     offset,
+	})
+
+	filter := bson.M{"targetType": targetType}
+	if targetID != "" {
+		filter["targetId"] = targetID
+	}
+
+	logs, err := r.find(filter, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(logs)}, nil)
+	return logs, nil
+}
+
+func (r *auditLogRepository) FindAll(limit, offset int) ([]domain.AuditLog, error) {
+	logger := utils.NewLogger("AuditLogRepository.FindAll")
+	logger.LogInput(map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	logs, err := r.find(bson.M{}, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(logs)}, nil)
+	return logs, nil
+}
+
+func (r *auditLogRepository) find(filter bson.M, limit, offset int) ([]domain.AuditLog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	logs := make([]domain.AuditLog, 0)
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
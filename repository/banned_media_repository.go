@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type bannedMediaRepository struct {
+	collection *mongo.Collection
+}
+
+func NewBannedMediaRepository(db *mongo.Database) domain.BannedMediaRepository {
+	return &bannedMediaRepository{
+		collection: db.Collection("banned_media"),
+	}
+}
+
+func (r *bannedMediaRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("BannedMediaRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "perceptualHash", Value: 1}},
+		Options: options.Index().SetName("perceptual_hash_unique").SetUnique(true),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Banned media indexes ready", nil)
+	return nil
+}
+
+func (r *bannedMediaRepository) Create(media *domain.BannedMedia) error {
+	logger := utils.NewLogger("BannedMediaRepository.Create")
+	logger.LogInput(media)
+
+	if media.ID.IsZero() {
+		media.ID = primitive.NewObjectID()
+	}
+	if media.CreatedAt.IsZero() {
+		media.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(context.Background(), media)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = domain.ErrDuplicate
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(media.ID, nil)
+	return nil
+}
+
+func (r *bannedMediaRepository) FindByID(id primitive.ObjectID) (*domain.BannedMedia, error) {
+	logger := utils.NewLogger("BannedMediaRepository.FindByID")
+	logger.LogInput(id)
+
+	var media domain.BannedMedia
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&media)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("banned media", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&media, nil)
+	return &media, nil
+}
+
+func (r *bannedMediaRepository) FindAll(limit, offset int) ([]domain.BannedMedia, error) {
+	logger := utils.NewLogger("BannedMediaRepository.FindAll")
+	logger.LogInput(map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	media := make([]domain.BannedMedia, 0)
+	if err := cursor.All(ctx, &media); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(media)}, nil)
+	return media, nil
+}
+
+func (r *bannedMediaRepository) ListHashes() ([]string, error) {
+	logger := utils.NewLogger("BannedMediaRepository.ListHashes")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetProjection(bson.M{"perceptualHash": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var media []domain.BannedMedia
+	if err := cursor.All(ctx, &media); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(media))
+	for _, m := range media {
+		hashes = append(hashes, m.PerceptualHash)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(hashes)}, nil)
+	return hashes, nil
+}
+
+func (r *bannedMediaRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("BannedMediaRepository.Delete")
+	logger.LogInput(id)
+
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		err := domain.NewNotFoundError("banned media", id.Hex())
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -14,22 +16,84 @@ import (
 
 type chatRepository struct {
 	db                *mongo.Database
+	rdb               *redis.Client
 	roomsColl         *mongo.Collection
 	messagesColl      *mongo.Collection
 	notificationsColl *mongo.Collection
 	userStatusColl    *mongo.Collection
+	invitesColl       *mongo.Collection
+	settingsColl      *mongo.Collection
 }
 
-func NewChatRepository(db *mongo.Database) domain.ChatRepository {
+func NewChatRepository(db *mongo.Database, rdb *redis.Client) domain.ChatRepository {
 	return &chatRepository{
 		db:                db,
+		rdb:               rdb,
 		roomsColl:         db.Collection("chatRooms"),
 		messagesColl:      db.Collection("chatMessages"),
 		notificationsColl: db.Collection("chatNotifications"),
 		userStatusColl:    db.Collection("chatUserStatus"),
+		invitesColl:       db.Collection("chatInvites"),
+		settingsColl:      db.Collection("chatRoomSettings"),
 	}
 }
 
+// EnsureIndexes creates the indexes serving room history, thread replies and
+// unread counts, and keeps message seqs and settings documents unique per room
+func (r *chatRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("ChatRepository.EnsureIndexes")
+
+	_, err := r.messagesColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "roomId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("room_messages"),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	_, err = r.messagesColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "threadId", Value: 1}, {Key: "createdAt", Value: 1}},
+		Options: options.Index().SetName("thread_replies").
+			SetPartialFilterExpression(bson.M{"threadId": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	_, err = r.messagesColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "roomId", Value: 1}, {Key: "seq", Value: 1}},
+		Options: options.Index().SetName("room_message_seq").SetUnique(true).
+			SetPartialFilterExpression(bson.M{"seq": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	_, err = r.messagesColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "senderId", Value: 1}, {Key: "createdAt", Value: 1}},
+		Options: options.Index().SetName("sender_messages"),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	_, err = r.settingsColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "roomId", Value: 1}, {Key: "userId", Value: 1}},
+		Options: options.Index().SetName("room_member_settings").SetUnique(true),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Chat indexes ready", nil)
+	return nil
+}
+
 // Room operations
 func (r *chatRepository) SaveRoom(room *domain.ChatRoom) error {
 	logger := utils.NewLogger("ChatRepository.SaveRoom")
@@ -131,120 +195,795 @@ func (r *chatRepository) RemoveMemberFromRoom(roomID string, userID string) erro
 	return nil
 }
 
-func (r *chatRepository) DeleteRoom(roomID string) error {
-	logger := utils.NewLogger("ChatRepository.DeleteRoom")
-	logger.LogInput(roomID)
+func (r *chatRepository) DeleteRoom(roomID string) error {
+	logger := utils.NewLogger("ChatRepository.DeleteRoom")
+	logger.LogInput(roomID)
+
+	filter := bson.M{"_id": roomID}
+	_, err := r.roomsColl.DeleteOne(context.Background(), filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Delete all messages in the room
+	messageFilter := bson.M{"roomId": roomID}
+	_, err = r.messagesColl.DeleteMany(context.Background(), messageFilter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Delete all notifications related to the room
+	notificationFilter := bson.M{"roomId": roomID}
+	_, err = r.notificationsColl.DeleteMany(context.Background(), notificationFilter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *chatRepository) UpdateRoom(room *domain.ChatRoom) error {
+	logger := utils.NewLogger("ChatRepository.UpdateRoom")
+	logger.LogInput(room)
+
+	filter := bson.M{"_id": room.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        room.Name,
+			"type":        room.Type,
+			"description": room.Description,
+			"avatarUrl":   room.AvatarURL,
+			"members":     room.Members,
+			"admins":      room.Admins,
+			"updatedAt":   time.Now(),
+		},
+	}
+
+	_, err := r.roomsColl.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *chatRepository) RemoveMemberFromRooms(userID string) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.RemoveMemberFromRooms")
+	logger.LogInput(userID)
+
+	update := bson.M{
+		"$pull": bson.M{"members": userID, "admins": userID},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	result, err := r.roomsColl.UpdateMany(context.Background(), bson.M{"members": userID}, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(result.ModifiedCount, nil)
+	return result.ModifiedCount, nil
+}
+
+// Invite operations
+func (r *chatRepository) SaveInvite(invite *domain.ChatInvite) error {
+	logger := utils.NewLogger("ChatRepository.SaveInvite")
+	logger.LogInput(invite)
+
+	_, err := r.invitesColl.InsertOne(context.Background(), invite)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(invite, nil)
+	return nil
+}
+
+func (r *chatRepository) GetInvite(inviteID string) (*domain.ChatInvite, error) {
+	logger := utils.NewLogger("ChatRepository.GetInvite")
+	logger.LogInput(inviteID)
+
+	objectID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, domain.ErrInvalidID
+	}
+
+	var invite domain.ChatInvite
+	err = r.invitesColl.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&invite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("invite", inviteID)
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&invite, nil)
+	return &invite, nil
+}
+
+func (r *chatRepository) GetRoomInvites(roomID string) ([]*domain.ChatInvite, error) {
+	logger := utils.NewLogger("ChatRepository.GetRoomInvites")
+	logger.LogInput(roomID)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := r.invitesColl.Find(context.Background(), bson.M{"roomId": roomID}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	invites := make([]*domain.ChatInvite, 0)
+	if err = cursor.All(context.Background(), &invites); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(invites, nil)
+	return invites, nil
+}
+
+func (r *chatRepository) UseInvite(inviteID string) (bool, error) {
+	logger := utils.NewLogger("ChatRepository.UseInvite")
+	logger.LogInput(inviteID)
+
+	objectID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, domain.ErrInvalidID
+	}
+
+	// Check and count in one update so concurrent joins can't exceed maxUses
+	filter := bson.M{
+		"_id":       objectID,
+		"revokedAt": bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": time.Now()},
+		"$or": []bson.M{
+			{"maxUses": 0},
+			{"$expr": bson.M{"$lt": []string{"$uses", "$maxUses"}}},
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{"uses": 1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := r.invitesColl.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(result.MatchedCount > 0, nil)
+	return result.MatchedCount > 0, nil
+}
+
+func (r *chatRepository) RevokeInvite(inviteID string) error {
+	logger := utils.NewLogger("ChatRepository.RevokeInvite")
+	logger.LogInput(inviteID)
+
+	objectID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return domain.ErrInvalidID
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": objectID, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": now, "updatedAt": now}}
+	_, err = r.invitesColl.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// Room settings operations
+func (r *chatRepository) GetRoomSettings(roomID, userID string) (*domain.ChatRoomSettings, error) {
+	logger := utils.NewLogger("ChatRepository.GetRoomSettings")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+	})
+
+	var settings domain.ChatRoomSettings
+	err := r.settingsColl.FindOne(context.Background(), bson.M{"roomId": roomID, "userId": userID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.LogOutput(nil, nil)
+			return nil, nil
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&settings, nil)
+	return &settings, nil
+}
+
+func (r *chatRepository) GetRoomSettingsForUsers(roomID string, userIDs []string) ([]*domain.ChatRoomSettings, error) {
+	logger := utils.NewLogger("ChatRepository.GetRoomSettingsForUsers")
+	logger.LogInput(map[string]interface{}{
+		"roomID":  roomID,
+		"userIDs": userIDs,
+	})
+
+	filter := bson.M{"roomId": roomID, "userId": bson.M{"$in": userIDs}}
+	cursor, err := r.settingsColl.Find(context.Background(), filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var settings []*domain.ChatRoomSettings
+	if err := cursor.All(context.Background(), &settings); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(settings), nil)
+	return settings, nil
+}
+
+// UpsertRoomSettings saves the settings of the member, creating them on first use
+func (r *chatRepository) UpsertRoomSettings(settings *domain.ChatRoomSettings) error {
+	logger := utils.NewLogger("ChatRepository.UpsertRoomSettings")
+	logger.LogInput(settings)
+
+	now := time.Now()
+	filter := bson.M{"roomId": settings.RoomID, "userId": settings.UserID}
+	set := bson.M{
+		"muted":             settings.Muted,
+		"notificationLevel": settings.NotificationLevel,
+		"updatedAt":         now,
+	}
+	update := bson.M{
+		"$set": set,
+		"$setOnInsert": bson.M{
+			"createdAt": now,
+			"isActive":  true,
+			"version":   1,
+		},
+	}
+	if settings.MuteUntil != nil {
+		set["muteUntil"] = settings.MuteUntil
+	} else {
+		update["$unset"] = bson.M{"muteUntil": ""}
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var saved domain.ChatRoomSettings
+	err := r.settingsColl.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&saved)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	*settings = saved
+
+	logger.LogOutput(settings, nil)
+	return nil
+}
+
+func (r *chatRepository) IncrementMessageCount(roomID, userID string, window time.Duration) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.IncrementMessageCount")
+	logger.LogInput(roomID, userID)
+
+	count, err := r.incrementWithExpiry(fmt.Sprintf("chat_rate:%s:%s", roomID, userID), window)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *chatRepository) AddFloodStrike(roomID, userID string, window time.Duration) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.AddFloodStrike")
+	logger.LogInput(roomID, userID)
+
+	count, err := r.incrementWithExpiry(fmt.Sprintf("chat_flood_strikes:%s:%s", roomID, userID), window)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+// incrementWithExpiry counts in a fixed window that starts with the first increment
+func (r *chatRepository) incrementWithExpiry(key string, window time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	pipe := r.rdb.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	// The first increment starts the window, or a key left without expiry gets one
+	if ttl.Val() < 0 {
+		if err := r.rdb.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count.Val(), nil
+}
+
+func chatFloodMuteKey(roomID, userID string) string {
+	return fmt.Sprintf("chat_flood_mute:%s:%s", roomID, userID)
+}
+
+func (r *chatRepository) FloodMute(roomID, userID string, duration time.Duration) error {
+	logger := utils.NewLogger("ChatRepository.FloodMute")
+	logger.LogInput(roomID, userID, duration.String())
+
+	if err := r.rdb.Set(context.Background(), chatFloodMuteKey(roomID, userID), 1, duration).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *chatRepository) GetFloodMute(roomID, userID string) (time.Duration, error) {
+	ttl, err := r.rdb.PTTL(context.Background(), chatFloodMuteKey(roomID, userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Negative values mean there is no mute
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func chatAutoReplyKey(userID, senderID string) string {
+	return fmt.Sprintf("chat_auto_reply:%s:%s", userID, senderID)
+}
+
+func (r *chatRepository) ClaimAutoReply(userID, senderID string, window time.Duration) (bool, error) {
+	logger := utils.NewLogger("ChatRepository.ClaimAutoReply")
+	logger.LogInput(userID, senderID, window.String())
+
+	claimed, err := r.rdb.SetNX(context.Background(), chatAutoReplyKey(userID, senderID), 1, window).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(claimed, nil)
+	return claimed, nil
+}
+
+func chatTypingKey(roomID string) string {
+	return fmt.Sprintf("chat_typing:%s", roomID)
+}
+
+// SetTyping records the user as typing in the room, or clears them when typing is false
+func (r *chatRepository) SetTyping(roomID, userID string, typing bool, ttl time.Duration) error {
+	logger := utils.NewLogger("ChatRepository.SetTyping")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+		"typing": typing,
+	})
+
+	ctx := context.Background()
+	key := chatTypingKey(roomID)
+	now := time.Now()
+
+	pipe := r.rdb.TxPipeline()
+	if typing {
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: userID})
+		pipe.Expire(ctx, key, ttl)
+	} else {
+		pipe.ZRem(ctx, key, userID)
+	}
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("%d", now.Add(-ttl).UnixMilli()))
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// GetTypingUsers returns the users who reported typing within the ttl, oldest first
+func (r *chatRepository) GetTypingUsers(roomID string, ttl time.Duration) ([]string, error) {
+	logger := utils.NewLogger("ChatRepository.GetTypingUsers")
+	logger.LogInput(roomID)
+
+	min := fmt.Sprintf("%d", time.Now().Add(-ttl).UnixMilli())
+	userIDs, err := r.rdb.ZRangeByScore(context.Background(), chatTypingKey(roomID), &redis.ZRangeBy{
+		Min: min,
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(userIDs, nil)
+	return userIDs, nil
+}
+
+// Message operations
+func (r *chatRepository) SaveMessage(message *domain.ChatMessage) error {
+	logger := utils.NewLogger("ChatRepository.SaveMessage")
+	logger.LogInput(message)
+
+	if message.ThreadID == "" {
+		seq, err := r.nextMessageSeq(message.RoomID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+		message.Seq = seq
+	}
+
+	message.CreatedAt = time.Now()
+	message.UpdatedAt = time.Now()
+	_, err := r.messagesColl.InsertOne(context.Background(), message)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(message, nil)
+	return nil
+}
+
+// nextMessageSeq takes the next seq of the room from the counter on the room document
+func (r *chatRepository) nextMessageSeq(roomID string) (int64, error) {
+	objectID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"lastMessageSeq": 1}).
+		SetReturnDocument(options.After)
+
+	var room struct {
+		LastMessageSeq int64 `bson:"lastMessageSeq"`
+	}
+	err = r.roomsColl.FindOneAndUpdate(context.Background(),
+		bson.M{"_id": objectID},
+		bson.M{"$inc": bson.M{"lastMessageSeq": 1}},
+		opts,
+	).Decode(&room)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, domain.NewNotFoundError("chat room", roomID)
+		}
+		return 0, err
+	}
+	return room.LastMessageSeq, nil
+}
+
+func (r *chatRepository) GetRoomMessages(roomID, viewerID string, afterSeq int64, limit, offset int64) ([]*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.GetRoomMessages")
+	logger.LogInput(map[string]interface{}{
+		"roomID":   roomID,
+		"viewerID": viewerID,
+		"afterSeq": afterSeq,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+
+	filter := bson.M{
+		"roomId":     roomID,
+		"threadId":   bson.M{"$exists": false},
+		"deletedFor": bson.M{"$ne": viewerID},
+	}
+	// Filling a gap reads forward from the last seq the client has
+	if afterSeq > 0 {
+		filter["seq"] = bson.M{"$gt": afterSeq}
+		opts.SetSort(bson.D{{Key: "seq", Value: 1}})
+	}
+	cursor, err := r.messagesColl.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var messages []*domain.ChatMessage
+	if err = cursor.All(context.Background(), &messages); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(messages, nil)
+	return messages, nil
+}
+
+func (r *chatRepository) MarkMessageAsRead(messageID string, userID string) error {
+	logger := utils.NewLogger("ChatRepository.MarkMessageAsRead")
+	logger.LogInput(map[string]string{
+		"messageID": messageID,
+		"userID":    userID,
+	})
+
+	_, err := r.messagesColl.UpdateOne(
+		context.Background(),
+		bson.M{"_id": messageID},
+		bson.M{"$addToSet": bson.M{"read_by": userID}},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *chatRepository) GetUnreadMessages(userID string, roomID string) ([]*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.GetUnreadMessages")
+	logger.LogInput(map[string]string{
+		"userID": userID,
+		"roomID": roomID,
+	})
+
+	objectID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	filter := bson.M{
+		"roomId": objectID,
+		"readBy": bson.M{
+			"$nin": []string{userID},
+		},
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.messagesColl.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var messages []*domain.ChatMessage
+	if err = cursor.All(context.Background(), &messages); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(messages, nil)
+	return messages, nil
+}
+
+func (r *chatRepository) MarkRoomAsRead(roomID, userID string, upTo time.Time) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.MarkRoomAsRead")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+		"upTo":   upTo,
+	})
+
+	filter := bson.M{
+		"roomId":    roomID,
+		"threadId":  bson.M{"$exists": false},
+		"senderId":  bson.M{"$ne": userID},
+		"readBy":    bson.M{"$ne": userID},
+		"createdAt": bson.M{"$lte": upTo},
+	}
+	result, err := r.messagesColl.UpdateMany(context.Background(), filter, bson.M{
+		"$addToSet": bson.M{"readBy": userID},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(result.ModifiedCount, nil)
+	return result.ModifiedCount, nil
+}
+
+func (r *chatRepository) CountUnreadByRoom(userID string, roomIDs []string) (map[string]int64, error) {
+	logger := utils.NewLogger("ChatRepository.CountUnreadByRoom")
+	logger.LogInput(map[string]interface{}{
+		"userID":  userID,
+		"roomIDs": roomIDs,
+	})
+
+	counts := make(map[string]int64)
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"roomId":             bson.M{"$in": roomIDs},
+			"threadId":           bson.M{"$exists": false},
+			"senderId":           bson.M{"$ne": userID},
+			"readBy":             bson.M{"$ne": userID},
+			"deletedFor":         bson.M{"$ne": userID},
+			"deletedForEveryone": bson.M{"$ne": true},
+			"type":               bson.M{"$ne": domain.ChatMessageTypeSystem},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$roomId",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.messagesColl.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var results []struct {
+		RoomID string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+	if err := cursor.All(context.Background(), &results); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	for _, result := range results {
+		counts[result.RoomID] = result.Count
+	}
+
+	logger.LogOutput(counts, nil)
+	return counts, nil
+}
+
+// Thread operations
+func (r *chatRepository) GetThreadReplies(threadID, viewerID string, limit, offset int64) ([]*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.GetThreadReplies")
+	logger.LogInput(map[string]interface{}{
+		"threadID": threadID,
+		"viewerID": viewerID,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+
+	filter := bson.M{"threadId": threadID, "deletedFor": bson.M{"$ne": viewerID}}
+	cursor, err := r.messagesColl.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	messages := make([]*domain.ChatMessage, 0)
+	if err = cursor.All(context.Background(), &messages); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(messages), nil)
+	return messages, nil
+}
+
+func (r *chatRepository) AddThreadReply(threadID string, participants []string, at time.Time) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.AddThreadReply")
+	logger.LogInput(map[string]interface{}{
+		"threadID":     threadID,
+		"participants": participants,
+		"at":           at,
+	})
 
-	filter := bson.M{"_id": roomID}
-	_, err := r.roomsColl.DeleteOne(context.Background(), filter)
+	objectID, err := primitive.ObjectIDFromHex(threadID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return err
+		return nil, err
 	}
 
-	// Delete all messages in the room
-	messageFilter := bson.M{"roomId": roomID}
-	_, err = r.messagesColl.DeleteMany(context.Background(), messageFilter)
-	if err != nil {
-		logger.LogOutput(nil, err)
-		return err
+	update := bson.M{
+		"$inc":      bson.M{"thread.replyCount": 1},
+		"$max":      bson.M{"thread.lastReplyAt": at},
+		"$addToSet": bson.M{"thread.participants": bson.M{"$each": participants}},
 	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	// Delete all notifications related to the room
-	notificationFilter := bson.M{"roomId": roomID}
-	_, err = r.notificationsColl.DeleteMany(context.Background(), notificationFilter)
+	var root domain.ChatMessage
+	err = r.messagesColl.FindOneAndUpdate(context.Background(), bson.M{"_id": objectID}, update, opts).Decode(&root)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("message", threadID)
+		}
 		logger.LogOutput(nil, err)
-		return err
+		return nil, err
 	}
 
-	logger.LogOutput(nil, nil)
-	return nil
+	logger.LogOutput(root.Thread, nil)
+	return &root, nil
 }
 
-func (r *chatRepository) UpdateRoom(room *domain.ChatRoom) error {
-	logger := utils.NewLogger("ChatRepository.UpdateRoom")
-	logger.LogInput(room)
+func (r *chatRepository) CountThreadUnread(threadID, userID string) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.CountThreadUnread")
+	logger.LogInput(threadID, userID)
 
-	filter := bson.M{"_id": room.ID}
-	update := bson.M{
-		"$set": bson.M{
-			"name":      room.Name,
-			"type":      room.Type,
-			"members":   room.Members,
-			"updatedAt": time.Now(),
-		},
+	filter := bson.M{
+		"threadId":           threadID,
+		"senderId":           bson.M{"$ne": userID},
+		"readBy":             bson.M{"$ne": userID},
+		"deletedFor":         bson.M{"$ne": userID},
+		"deletedForEveryone": bson.M{"$ne": true},
 	}
-
-	_, err := r.roomsColl.UpdateOne(context.Background(), filter, update)
+	count, err := r.messagesColl.CountDocuments(context.Background(), filter)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return err
+		return 0, err
 	}
 
-	logger.LogOutput(nil, nil)
-	return nil
+	logger.LogOutput(count, nil)
+	return count, nil
 }
 
-// Message operations
-func (r *chatRepository) SaveMessage(message *domain.ChatMessage) error {
-	logger := utils.NewLogger("ChatRepository.SaveMessage")
-	logger.LogInput(message)
+func (r *chatRepository) MarkThreadAsRead(threadID, userID string) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.MarkThreadAsRead")
+	logger.LogInput(threadID, userID)
 
-	message.CreatedAt = time.Now()
-	message.UpdatedAt = time.Now()
-	_, err := r.messagesColl.InsertOne(context.Background(), message)
+	filter := bson.M{
+		"threadId": threadID,
+		"senderId": bson.M{"$ne": userID},
+		"readBy":   bson.M{"$ne": userID},
+	}
+	result, err := r.messagesColl.UpdateMany(context.Background(), filter, bson.M{
+		"$addToSet": bson.M{"readBy": userID},
+	})
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return err
+		return 0, err
 	}
 
-	logger.LogOutput(message, nil)
-	return nil
+	logger.LogOutput(result.ModifiedCount, nil)
+	return result.ModifiedCount, nil
 }
 
-func (r *chatRepository) GetRoomMessages(roomID string, limit, offset int64) ([]*domain.ChatMessage, error) {
-	logger := utils.NewLogger("ChatRepository.GetRoomMessages")
+func (r *chatRepository) DeleteMessageForUser(messageID, userID string) error {
+	logger := utils.NewLogger("ChatRepository.DeleteMessageForUser")
 	logger.LogInput(map[string]interface{}{
-		"roomID": roomID,
-		"limit":  limit,
-		"offset": offset,
+		"messageID": messageID,
+		"userID":    userID,
 	})
 
-	opts := options.Find().
-		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
-		SetSkip(offset).
-		SetLimit(limit)
-
-	cursor, err := r.messagesColl.Find(context.Background(), bson.M{"roomId": roomID}, opts)
+	objectID, err := primitive.ObjectIDFromHex(messageID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return nil, err
-	}
-	defer cursor.Close(context.Background())
-
-	var messages []*domain.ChatMessage
-	if err = cursor.All(context.Background(), &messages); err != nil {
-		logger.LogOutput(nil, err)
-		return nil, err
+		return domain.ErrInvalidID
 	}
 
-	logger.LogOutput(messages, nil)
-	return messages, nil
-}
-
-func (r *chatRepository) MarkMessageAsRead(messageID string, userID string) error {
-	logger := utils.NewLogger("ChatRepository.MarkMessageAsRead")
-	logger.LogInput(map[string]string{
-		"messageID": messageID,
-		"userID":    userID,
+	_, err = r.messagesColl.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
+		"$addToSet": bson.M{"deletedFor": userID},
 	})
-
-	_, err := r.messagesColl.UpdateOne(
-		context.Background(),
-		bson.M{"_id": messageID},
-		bson.M{"$addToSet": bson.M{"read_by": userID}},
-	)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
@@ -254,58 +993,79 @@ func (r *chatRepository) MarkMessageAsRead(messageID string, userID string) erro
 	return nil
 }
 
-func (r *chatRepository) GetUnreadMessages(userID string, roomID string) ([]*domain.ChatMessage, error) {
-	logger := utils.NewLogger("ChatRepository.GetUnreadMessages")
-	logger.LogInput(map[string]string{
-		"userID": userID,
-		"roomID": roomID,
-	})
+func (r *chatRepository) DeleteMessageForEveryone(messageID string) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.DeleteMessageForEveryone")
+	logger.LogInput(messageID)
 
-	objectID, err := primitive.ObjectIDFromHex(roomID)
+	objectID, err := primitive.ObjectIDFromHex(messageID)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return nil, err
+		return nil, domain.ErrInvalidID
 	}
 
-	filter := bson.M{
-		"roomId": objectID,
-		"readBy": bson.M{
-			"$nin": []string{userID},
+	update := bson.M{
+		"$set": bson.M{
+			"deletedForEveryone": true,
+			"content":            "",
+			"updatedAt":          time.Now(),
+		},
+		"$unset": bson.M{
+			"fileUrl":      "",
+			"thumbnailUrl": "",
+			"editHistory":  "",
 		},
 	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
-
-	cursor, err := r.messagesColl.Find(context.Background(), filter, opts)
+	var message domain.ChatMessage
+	err = r.messagesColl.FindOneAndUpdate(context.Background(), bson.M{"_id": objectID}, update, opts).Decode(&message)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("message", messageID)
+		}
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
 
-	var messages []*domain.ChatMessage
-	if err = cursor.All(context.Background(), &messages); err != nil {
+	logger.LogOutput(&message, nil)
+	return &message, nil
+}
+
+func (r *chatRepository) EditMessage(messageID string, content string, previous domain.ChatMessageEdit) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.EditMessage")
+	logger.LogInput(map[string]interface{}{
+		"messageID": messageID,
+		"content":   content,
+	})
+
+	objectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
-	logger.LogOutput(messages, nil)
-	return messages, nil
-}
-
-func (r *chatRepository) DeleteMessage(messageID string) error {
-	logger := utils.NewLogger("ChatRepository.DeleteMessage")
-	logger.LogInput(messageID)
+	update := bson.M{
+		"$set": bson.M{
+			"content":   content,
+			"isEdited":  true,
+			"updatedAt": previous.EditedAt,
+		},
+		"$push": bson.M{"editHistory": previous},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	filter := bson.M{"_id": messageID}
-	_, err := r.messagesColl.DeleteOne(context.Background(), filter)
+	var message domain.ChatMessage
+	err = r.messagesColl.FindOneAndUpdate(context.Background(), bson.M{"_id": objectID}, update, opts).Decode(&message)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("message", messageID)
+		}
 		logger.LogOutput(nil, err)
-		return err
+		return nil, err
 	}
 
-	logger.LogOutput(nil, nil)
-	return nil
+	logger.LogOutput(&message, nil)
+	return &message, nil
 }
 
 func (r *chatRepository) GetMessage(messageID string) (*domain.ChatMessage, error) {
@@ -335,13 +1095,89 @@ func (r *chatRepository) GetMessage(messageID string) (*domain.ChatMessage, erro
 	return &message, nil
 }
 
+func (r *chatRepository) GetMessagesByIDs(messageIDs []string) ([]*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.GetMessagesByIDs")
+	logger.LogInput(messageIDs)
+
+	objectIDs := make([]primitive.ObjectID, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	if len(objectIDs) == 0 {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+
+	cursor, err := r.messagesColl.Find(context.Background(), bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var messages []*domain.ChatMessage
+	if err := cursor.All(context.Background(), &messages); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(messages), nil)
+	return messages, nil
+}
+
+func (r *chatRepository) GetMessagesBySender(senderID string, limit, offset int64) ([]*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatRepository.GetMessagesBySender")
+	logger.LogInput(senderID, limit, offset)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+
+	// System messages carry the actor as sender but aren't written by them
+	filter := bson.M{
+		"senderId": senderID,
+		"type":     bson.M{"$ne": domain.ChatMessageTypeSystem},
+	}
+	cursor, err := r.messagesColl.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var messages []*domain.ChatMessage
+	if err := cursor.All(context.Background(), &messages); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(messages), nil)
+	return messages, nil
+}
+
 // User status operations
 func (r *chatRepository) UpdateUserStatus(status *domain.ChatUserStatus) error {
 	logger := utils.NewLogger("ChatRepository.UpdateUserStatus")
 	logger.LogInput(status)
 
+	// Only the presence fields change once the status document exists
 	filter := bson.M{"userId": status.UserID}
-	update := bson.M{"$set": status}
+	update := bson.M{
+		"$set": bson.M{
+			"isOnline":  status.IsOnline,
+			"lastSeen":  status.LastSeen,
+			"updatedAt": status.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":       status.ID,
+			"createdAt": status.CreatedAt,
+			"isActive":  status.IsActive,
+			"version":   status.Version,
+		},
+	}
 	opts := options.Update().SetUpsert(true)
 
 	_, err := r.userStatusColl.UpdateOne(context.Background(), filter, update, opts)
@@ -401,6 +1237,133 @@ func (r *chatRepository) GetOnlineUsers(userIDs []string) ([]*domain.ChatUserSta
 	return statuses, nil
 }
 
+// Presence keys: a sorted set of the user's connections scored by their last heartbeat,
+// and a sorted set of online users scored by their latest heartbeat for the sweeper
+const chatPresenceOnlineKey = "chat_presence_online"
+
+func chatPresenceKey(userID string) string {
+	return fmt.Sprintf("chat_presence:%s", userID)
+}
+
+func (r *chatRepository) TouchPresence(userID, connectionID string, ttl time.Duration) (bool, error) {
+	logger := utils.NewLogger("ChatRepository.TouchPresence")
+	logger.LogInput(map[string]interface{}{
+		"userID":       userID,
+		"connectionID": connectionID,
+	})
+
+	ctx := context.Background()
+	key := chatPresenceKey(userID)
+	now := time.Now()
+	score := float64(now.UnixMilli())
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: connectionID})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("%d", now.Add(-ttl).UnixMilli()))
+	pipe.Expire(ctx, key, ttl)
+	added := pipe.ZAdd(ctx, chatPresenceOnlineKey, redis.Z{Score: score, Member: userID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	cameOnline := added.Val() == 1
+	logger.LogOutput(cameOnline, nil)
+	return cameOnline, nil
+}
+
+func (r *chatRepository) RemovePresence(userID, connectionID string, ttl time.Duration) (bool, error) {
+	logger := utils.NewLogger("ChatRepository.RemovePresence")
+	logger.LogInput(map[string]interface{}{
+		"userID":       userID,
+		"connectionID": connectionID,
+	})
+
+	ctx := context.Background()
+	key := chatPresenceKey(userID)
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZRem(ctx, key, connectionID)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("%d", time.Now().Add(-ttl).UnixMilli()))
+	remaining := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if remaining.Val() > 0 {
+		logger.LogOutput(false, nil)
+		return false, nil
+	}
+
+	// Only the caller that removes the user announces them offline
+	removed, err := r.rdb.ZRem(ctx, chatPresenceOnlineKey, userID).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	wentOffline := removed == 1
+	logger.LogOutput(wentOffline, nil)
+	return wentOffline, nil
+}
+
+func (r *chatRepository) ExpirePresence(ttl time.Duration) (map[string]time.Time, error) {
+	logger := utils.NewLogger("ChatRepository.ExpirePresence")
+
+	ctx := context.Background()
+	stale, err := r.rdb.ZRangeByScoreWithScores(ctx, chatPresenceOnlineKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", time.Now().Add(-ttl).UnixMilli()),
+	}).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	expired := make(map[string]time.Time, len(stale))
+	for _, z := range stale {
+		userID := z.Member
+		// Several instances sweep, only the one that removes the user reports them
+		removed, err := r.rdb.ZRem(ctx, chatPresenceOnlineKey, userID).Result()
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return expired, err
+		}
+		if removed == 1 {
+			expired[userID] = time.UnixMilli(int64(z.Score))
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(expired)}, nil)
+	return expired, nil
+}
+
+// GetPresence reports which of the users had a heartbeat within the ttl
+func (r *chatRepository) GetPresence(userIDs []string, ttl time.Duration) (map[string]bool, error) {
+	logger := utils.NewLogger("ChatRepository.GetPresence")
+	logger.LogInput(userIDs)
+
+	online := make(map[string]bool, len(userIDs))
+	if len(userIDs) == 0 {
+		return online, nil
+	}
+
+	scores, err := r.rdb.ZMScore(context.Background(), chatPresenceOnlineKey, userIDs...).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Missing members score 0
+	since := float64(time.Now().Add(-ttl).UnixMilli())
+	for i, userID := range userIDs {
+		online[userID] = scores[i] >= since
+	}
+
+	logger.LogOutput(online, nil)
+	return online, nil
+}
+
 // Notification operations
 func (r *chatRepository) CreateNotification(notification *domain.ChatNotification) error {
 	logger := utils.NewLogger("ChatRepository.CreateNotification")
@@ -417,6 +1380,30 @@ func (r *chatRepository) CreateNotification(notification *domain.ChatNotificatio
 	return nil
 }
 
+func (r *chatRepository) CreateNotifications(notifications []*domain.ChatNotification) error {
+	logger := utils.NewLogger("ChatRepository.CreateNotifications")
+	logger.LogInput(map[string]interface{}{"count": len(notifications)})
+
+	if len(notifications) == 0 {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	docs := make([]interface{}, len(notifications))
+	for i, notification := range notifications {
+		docs[i] = notification
+	}
+
+	_, err := r.notificationsColl.InsertMany(context.Background(), docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
 func (r *chatRepository) GetUserNotifications(userID string) ([]*domain.ChatNotification, error) {
 	logger := utils.NewLogger("ChatRepository.GetUserNotifications")
 	logger.LogInput(userID)
@@ -542,3 +1529,17 @@ func (r *chatRepository) DeleteRoomNotifications(roomID string) error {
 	logger.LogOutput(nil, nil)
 	return nil
 }
+
+func (r *chatRepository) CountRoomMessages(roomID string) (int64, error) {
+	logger := utils.NewLogger("ChatRepository.CountRoomMessages")
+	logger.LogInput(roomID)
+
+	count, err := countApprox(context.Background(), r.messagesColl, bson.M{"roomId": roomID})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
@@ -97,6 +97,20 @@ func (r *commentRepository) Update(comment *domain.Comment) error {
 		}
 	}
 
+	// Content may have changed, so cached translations are stale
+	keys, err = r.rdb.Keys(ctx, fmt.Sprintf("comment_translation:%s:*", comment.ID.Hex())).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(keys) > 0 {
+		err = r.rdb.Del(ctx, keys...).Err()
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
 	logger.LogOutput("Comment updated successfully", nil)
 	return nil
 }
@@ -201,39 +215,69 @@ func (r *commentRepository) FindByID(id primitive.ObjectID) (*domain.Comment, er
 	return &comment, nil
 }
 
-func (r *commentRepository) FindByPostID(postID primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
+func (r *commentRepository) FindByIDs(ids []primitive.ObjectID) ([]domain.Comment, error) {
+	logger := utils.NewLogger("CommentRepository.FindByIDs")
+	logger.LogInput(ids)
+
+	ctx := context.Background()
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]domain.Comment, 0, len(ids))
+	if err := cursor.All(ctx, &comments); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(comments)}, nil)
+	return comments, nil
+}
+
+// FindByPostID caches the pages every viewer shares, pages leaving out authors aren't cached
+func (r *commentRepository) FindByPostID(postID primitive.ObjectID, exclude []primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
 	logger := utils.NewLogger("CommentRepository.FindByPostID")
 	input := map[string]interface{}{
-		"postID": postID,
-		"limit":  limit,
-		"offset": offset,
+		"postID":  postID,
+		"exclude": exclude,
+		"limit":   limit,
+		"offset":  offset,
 	}
 	logger.LogInput(input)
 
 	ctx := context.Background()
 	key := fmt.Sprintf("post_comments:%s:%d:%d", postID.Hex(), limit, offset)
+	cacheable := len(exclude) == 0
 
 	// Try to get from Redis first
-	commentsJSON, err := r.rdb.Get(ctx, key).Result()
-	if err == nil {
-		// Found in Redis
-		var comments []domain.Comment
-		err = json.Unmarshal([]byte(commentsJSON), &comments)
-		if err != nil {
+	if cacheable {
+		commentsJSON, err := r.rdb.Get(ctx, key).Result()
+		if err == nil {
+			// Found in Redis
+			var comments []domain.Comment
+			err = json.Unmarshal([]byte(commentsJSON), &comments)
+			if err != nil {
+				logger.LogOutput(nil, err)
+				return nil, err
+			}
+			logger.LogOutput(comments, nil)
+			return comments, nil
+		} else if err != redis.Nil {
+			// Redis error
 			logger.LogOutput(nil, err)
 			return nil, err
 		}
-		logger.LogOutput(comments, nil)
-		return comments, nil
-	} else if err != redis.Nil {
-		// Redis error
-		logger.LogOutput(nil, err)
-		return nil, err
 	}
 
 	// Not found in Redis, get from MongoDB
 	var comments []domain.Comment
-	filter := bson.M{"postId": postID}
+	filter := bson.M{"postId": postID, "replyTo": bson.M{"$exists": false}, "isActive": bson.M{"$ne": false}}
+	if !cacheable {
+		filter["userId"] = bson.M{"$nin": exclude}
+	}
 
 	findOptions := options.Find()
 	if limit > 0 {
@@ -256,6 +300,10 @@ func (r *commentRepository) FindByPostID(postID primitive.ObjectID, limit, offse
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
+	if !cacheable {
+		logger.LogOutput(comments, nil)
+		return comments, nil
+	}
 
 	// Cache in Redis for 10 minutes
 	commentsBytes, err := json.Marshal(comments)
@@ -307,3 +355,254 @@ func (r *commentRepository) DeleteByPostID(postID primitive.ObjectID) error {
 	}, nil)
 	return nil
 }
+
+func (r *commentRepository) CountByPostID(postID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("CommentRepository.CountByPostID")
+	logger.LogInput(postID)
+
+	count, err := countApprox(context.Background(), r.collection, bson.M{"postId": postID, "replyTo": bson.M{"$exists": false}, "isActive": bson.M{"$ne": false}})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *commentRepository) FindByUserID(userID primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
+	logger := utils.NewLogger("CommentRepository.FindByUserID")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID, "isActive": bson.M{"$ne": false}}, findOptions)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]domain.Comment, 0)
+	if err := cursor.All(ctx, &comments); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(comments)}, nil)
+	return comments, nil
+}
+
+func (r *commentRepository) DeleteByUserID(userID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("CommentRepository.DeleteByUserID")
+	logger.LogInput(userID)
+
+	ctx := context.Background()
+	filter := bson.M{"userId": userID}
+
+	// The posts commented on, for cache invalidation
+	postIDs, err := r.collection.Distinct(ctx, "postId", filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+	var comments []domain.Comment
+	err = cursor.All(ctx, &comments)
+	cursor.Close(ctx)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		keys = append(keys, fmt.Sprintf("comment:%s", comment.ID.Hex()))
+	}
+	for _, postID := range postIDs {
+		id, ok := postID.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		postKeys, err := r.rdb.Keys(ctx, fmt.Sprintf("post_comments:%s:*", id.Hex())).Result()
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return result.DeletedCount, err
+		}
+		keys = append(keys, postKeys...)
+	}
+	if len(keys) > 0 {
+		if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+			logger.LogOutput(nil, err)
+			return result.DeletedCount, err
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"deletedCount": result.DeletedCount}, nil)
+	return result.DeletedCount, nil
+}
+
+func (r *commentRepository) FindReplies(commentID primitive.ObjectID, exclude []primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
+	logger := utils.NewLogger("CommentRepository.FindReplies")
+	logger.LogInput(map[string]interface{}{
+		"commentID": commentID,
+		"exclude":   exclude,
+		"limit":     limit,
+		"offset":    offset,
+	})
+
+	ctx := context.Background()
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		findOptions.SetSkip(int64(offset))
+	}
+
+	filter := bson.M{"replyTo": commentID, "isActive": bson.M{"$ne": false}}
+	if len(exclude) > 0 {
+		filter["userId"] = bson.M{"$nin": exclude}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]domain.Comment, 0)
+	if err := cursor.All(ctx, &comments); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(comments)}, nil)
+	return comments, nil
+}
+
+func (r *commentRepository) CountReplies(commentID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("CommentRepository.CountReplies")
+	logger.LogInput(commentID)
+
+	count, err := countApprox(context.Background(), r.collection, bson.M{"replyTo": commentID, "isActive": bson.M{"$ne": false}})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *commentRepository) IncrementReplyCount(commentID primitive.ObjectID, delta int) error {
+	logger := utils.NewLogger("CommentRepository.IncrementReplyCount")
+	logger.LogInput(map[string]interface{}{
+		"commentID": commentID,
+		"delta":     delta,
+	})
+
+	ctx := context.Background()
+	var comment domain.Comment
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": commentID}, bson.M{"$inc": bson.M{"replyCount": delta}}).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("comment", commentID.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Invalidate comment cache and post comments cache
+	if err := r.rdb.Del(ctx, fmt.Sprintf("comment:%s", commentID.Hex())).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	keys, err := r.rdb.Keys(ctx, fmt.Sprintf("post_comments:%s:*", comment.PostID.Hex())).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(keys) > 0 {
+		if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *commentRepository) GetTranslation(commentID primitive.ObjectID, targetLanguage string) (*domain.Translation, error) {
+	logger := utils.NewLogger("CommentRepository.GetTranslation")
+	logger.LogInput(map[string]interface{}{
+		"commentID":      commentID,
+		"targetLanguage": targetLanguage,
+	})
+
+	key := fmt.Sprintf("comment_translation:%s:%s", commentID.Hex(), targetLanguage)
+	translationJSON, err := r.rdb.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var translation domain.Translation
+	if err := json.Unmarshal([]byte(translationJSON), &translation); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&translation, nil)
+	return &translation, nil
+}
+
+func (r *commentRepository) SetTranslation(commentID primitive.ObjectID, translation *domain.Translation, ttl time.Duration) error {
+	logger := utils.NewLogger("CommentRepository.SetTranslation")
+	logger.LogInput(map[string]interface{}{
+		"commentID":   commentID,
+		"translation": translation,
+	})
+
+	translationBytes, err := json.Marshal(translation)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	key := fmt.Sprintf("comment_translation:%s:%s", commentID.Hex(), translation.TargetLanguage)
+	err = r.rdb.Set(context.Background(), key, string(translationBytes), ttl).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Translation cached successfully", nil)
+	return nil
+}
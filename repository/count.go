@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// countApprox counts matching documents up to domain.MaxApproxCount
+func countApprox(ctx context.Context, collection *mongo.Collection, filter bson.M) (int64, error) {
+	opts := options.Count().SetLimit(domain.MaxApproxCount)
+	return collection.CountDocuments(ctx, filter, opts)
+}
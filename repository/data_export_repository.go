@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type dataExportRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDataExportRepository(db *mongo.Database) domain.DataExportRepository {
+	return &dataExportRepository{
+		collection: db.Collection("data_exports"),
+	}
+}
+
+// EnsureIndexes indexes each user's exports newest first and the queue workers claim from
+func (r *dataExportRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("DataExportRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("user_exports"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("export_queue"),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Data export indexes ready", nil)
+	return nil
+}
+
+func (r *dataExportRepository) Create(export *domain.DataExport) error {
+	logger := utils.NewLogger("DataExportRepository.Create")
+	logger.LogInput(export.UserID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	export.ID = primitive.NewObjectID()
+	export.CreatedAt = now
+	export.UpdatedAt = now
+	export.IsActive = true
+	export.Version = 1
+
+	_, err := r.collection.InsertOne(ctx, export)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(export.ID, nil)
+	return nil
+}
+
+func (r *dataExportRepository) FindLatestByUser(userID primitive.ObjectID) (*domain.DataExport, error) {
+	logger := utils.NewLogger("DataExportRepository.FindLatestByUser")
+	logger.LogInput(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	var export domain.DataExport
+	err := r.collection.FindOne(ctx, bson.M{"userId": userID}, opts).Decode(&export)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("data export", userID.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(export.Status, nil)
+	return &export, nil
+}
+
+func (r *dataExportRepository) ClaimNext(staleBefore time.Time) (*domain.DataExport, error) {
+	logger := utils.NewLogger("DataExportRepository.ClaimNext")
+	logger.LogInput(staleBefore)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": []bson.M{
+		{"status": domain.DataExportPending},
+		{"status": domain.DataExportRunning, "updatedAt": bson.M{"$lt": staleBefore}},
+	}}
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{"status": domain.DataExportRunning, "updatedAt": now},
+		// Keeps the first start when a stale export is taken over
+		"$min": bson.M{"startedAt": now},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var export domain.DataExport
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&export)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.LogOutput(nil, nil)
+			return nil, nil
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(export.ID, nil)
+	return &export, nil
+}
+
+func (r *dataExportRepository) Complete(id primitive.ObjectID, fileURL string, size int64) error {
+	logger := utils.NewLogger("DataExportRepository.Complete")
+	logger.LogInput(id, fileURL, size)
+
+	return r.finish(logger, id, bson.M{
+		"status":  domain.DataExportCompleted,
+		"fileUrl": fileURL,
+		"size":    size,
+	})
+}
+
+func (r *dataExportRepository) Fail(id primitive.ObjectID, reason string) error {
+	logger := utils.NewLogger("DataExportRepository.Fail")
+	logger.LogInput(id, reason)
+
+	return r.finish(logger, id, bson.M{
+		"status": domain.DataExportFailed,
+		"error":  reason,
+	})
+}
+
+// finish sets the final fields of an export along with when it finished
+func (r *dataExportRepository) finish(logger *utils.Logger, id primitive.ObjectID, fields bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	fields["finishedAt"] = now
+	fields["updatedAt"] = now
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
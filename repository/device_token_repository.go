@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type deviceTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDeviceTokenRepository(db *mongo.Database) domain.DeviceTokenRepository {
+	return &deviceTokenRepository{
+		collection: db.Collection("device_tokens"),
+	}
+}
+
+func (r *deviceTokenRepository) Upsert(device *domain.DeviceToken) error {
+	logger := utils.NewLogger("DeviceTokenRepository.Upsert")
+	logger.LogInput(map[string]interface{}{
+		"userID":   device.UserID,
+		"platform": device.Platform,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"token": device.Token}
+	update := bson.M{
+		"$set": bson.M{
+			"userId":    device.UserID,
+			"platform":  device.Platform,
+			"updatedAt": now,
+			"isActive":  true,
+		},
+		"$setOnInsert": bson.M{
+			"_id":       primitive.NewObjectID(),
+			"createdAt": now,
+			"version":   1,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(device)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(device.ID, nil)
+	return nil
+}
+
+func (r *deviceTokenRepository) Delete(userID primitive.ObjectID, token string) error {
+	logger := utils.NewLogger("DeviceTokenRepository.Delete")
+	logger.LogInput(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"userId": userID, "token": token})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		logger.LogOutput(nil, domain.ErrNotFound)
+		return domain.ErrNotFound
+	}
+
+	logger.LogOutput("Device token deleted successfully", nil)
+	return nil
+}
+
+func (r *deviceTokenRepository) DeleteByTokens(tokens []string) error {
+	logger := utils.NewLogger("DeviceTokenRepository.DeleteByTokens")
+	logger.LogInput(len(tokens))
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"token": bson.M{"$in": tokens}})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{
+		"deletedCount": result.DeletedCount,
+	}, nil)
+	return nil
+}
+
+func (r *deviceTokenRepository) FindByUserID(userID primitive.ObjectID) ([]domain.DeviceToken, error) {
+	logger := utils.NewLogger("DeviceTokenRepository.FindByUserID")
+	logger.LogInput(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	devices := make([]domain.DeviceToken, 0)
+	if err := cursor.All(ctx, &devices); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(devices), nil)
+	return devices, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Exported events are kept this long for replays, then removed by a TTL index
+const exportedDomainEventRetention = 7 * 24 * time.Hour
+
+type domainEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDomainEventRepository(db *mongo.Database) domain.DomainEventRepository {
+	return &domainEventRepository{
+		collection: db.Collection("domain_events"),
+	}
+}
+
+// EnsureIndexes creates the TTL index that removes exported events
+func (r *domainEventRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("DomainEventRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "exportedAt", Value: 1}},
+		Options: options.Index().
+			SetName("exported_ttl").
+			SetExpireAfterSeconds(int32(exportedDomainEventRetention.Seconds())),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Domain event indexes ready", nil)
+	return nil
+}
+
+func (r *domainEventRepository) Save(event *domain.DomainEvent) error {
+	logger := utils.NewLogger("DomainEventRepository.Save")
+	logger.LogInput(event.Type, event.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(event.ID, nil)
+	return nil
+}
+
+func (r *domainEventRepository) FindPending(limit int) ([]domain.DomainEvent, error) {
+	logger := utils.NewLogger("DomainEventRepository.FindPending")
+	logger.LogInput(limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"exportedAt": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := make([]domain.DomainEvent, 0)
+	if err := cursor.All(ctx, &events); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(events)}, nil)
+	return events, nil
+}
+
+func (r *domainEventRepository) MarkExported(ids []primitive.ObjectID) error {
+	logger := utils.NewLogger("DomainEventRepository.MarkExported")
+	logger.LogInput(map[string]interface{}{"count": len(ids)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"exportedAt": time.Now()}},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Domain events marked exported", nil)
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStreamDomainEventSink struct {
+	rdb    *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamDomainEventSink appends domain events to a Redis stream, trimmed to about maxLen entries
+func NewRedisStreamDomainEventSink(rdb *redis.Client, stream string, maxLen int64) domain.DomainEventSink {
+	return &redisStreamDomainEventSink{
+		rdb:    rdb,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (s *redisStreamDomainEventSink) Export(events []domain.DomainEvent) error {
+	logger := utils.NewLogger("RedisStreamDomainEventSink.Export")
+	logger.LogInput(map[string]interface{}{
+		"stream": s.stream,
+		"count":  len(events),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipe := s.rdb.Pipeline()
+	for _, event := range events {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.stream,
+			MaxLen: s.maxLen,
+			Approx: true,
+			Values: map[string]interface{}{
+				"id":            event.ID.Hex(),
+				"type":          event.Type,
+				"schemaVersion": event.SchemaVersion,
+				"occurredAt":    event.OccurredAt.UTC().Format(time.RFC3339Nano),
+				"payload":       event.Payload,
+			},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(events)}, nil)
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type logEmailSender struct{}
+
+// NewLogEmailSender writes emails to the application log instead of sending them, for development
+func NewLogEmailSender() domain.EmailSender {
+	return &logEmailSender{}
+}
+
+func (s *logEmailSender) Send(to, subject, body string) error {
+	logger := utils.NewLogger("LogEmailSender.Send")
+	logger.LogInput(to, subject, body)
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+type smtpEmailSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPEmailSender sends plain text emails through an SMTP server. Without a
+// username the server is used without authentication.
+func NewSMTPEmailSender(host string, port int, username, password, from string) domain.EmailSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpEmailSender{
+		addr: fmt.Sprintf("%s:%d", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (s *smtpEmailSender) Send(to, subject, body string) error {
+	logger := utils.NewLogger("SMTPEmailSender.Send")
+	logger.LogInput(to, subject)
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", s.from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(body)
+
+	err := smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(message.String()))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
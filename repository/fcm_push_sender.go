@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"firebase.google.com/go/v4/messaging"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// fcmMulticastLimit is the maximum number of tokens FCM accepts in one multicast
+const fcmMulticastLimit = 500
+
+type fcmPushSender struct {
+	client *messaging.Client
+}
+
+// NewFCMPushSender creates a push sender backed by Firebase Cloud Messaging
+func NewFCMPushSender(client *messaging.Client) domain.PushSender {
+	return &fcmPushSender{
+		client: client,
+	}
+}
+
+func (s *fcmPushSender) Send(tokens []string, message *domain.PushMessage) ([]string, error) {
+	logger := utils.NewLogger("FCMPushSender.Send")
+	logger.LogInput(map[string]interface{}{
+		"tokens": len(tokens),
+		"title":  message.Title,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var invalidTokens []string
+	for start := 0; start < len(tokens); start += fcmMulticastLimit {
+		end := start + fcmMulticastLimit
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		batch := tokens[start:end]
+
+		response, err := s.client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+			Tokens: batch,
+			Notification: &messaging.Notification{
+				Title: message.Title,
+				Body:  message.Body,
+			},
+			Data: message.Data,
+		})
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return invalidTokens, err
+		}
+
+		for i, result := range response.Responses {
+			if result.Success {
+				continue
+			}
+			if messaging.IsRegistrationTokenNotRegistered(result.Error) || messaging.IsInvalidArgument(result.Error) {
+				invalidTokens = append(invalidTokens, batch[i])
+			}
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{
+		"invalidTokens": len(invalidTokens),
+	}, nil)
+	return invalidTokens, nil
+}
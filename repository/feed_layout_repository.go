@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// feedLayoutID is the _id of the one layout document, the home screen
+const feedLayoutID = "home"
+
+const feedLayoutCacheKey = "feed_layout:" + feedLayoutID
+
+type feedLayoutRepository struct {
+	collection *mongo.Collection
+	rdb        *redis.Client
+}
+
+func NewFeedLayoutRepository(db *mongo.Database, rdb *redis.Client) domain.FeedLayoutRepository {
+	return &feedLayoutRepository{
+		collection: db.Collection("feed_layouts"),
+		rdb:        rdb,
+	}
+}
+
+// Find serves the layout from Redis, every home screen load asks for it
+func (r *feedLayoutRepository) Find() (*domain.FeedLayoutConfig, error) {
+	logger := utils.NewLogger("FeedLayoutRepository.Find")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configJSON, err := r.rdb.Get(ctx, feedLayoutCacheKey).Result()
+	if err == nil {
+		var config domain.FeedLayoutConfig
+		if err := json.Unmarshal([]byte(configJSON), &config); err == nil {
+			logger.LogOutput(config.UpdatedAt, nil)
+			return &config, nil
+		}
+	} else if err != redis.Nil {
+		// Mongo still answers
+		logger.LogOutput(nil, err)
+	}
+
+	var config domain.FeedLayoutConfig
+	err = r.collection.FindOne(ctx, bson.M{"_id": feedLayoutID}).Decode(&config)
+	if err == mongo.ErrNoDocuments {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if configBytes, err := json.Marshal(config); err == nil {
+		if err := r.rdb.Set(ctx, feedLayoutCacheKey, string(configBytes), domain.FeedLayoutCacheTTL).Err(); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
+	logger.LogOutput(config.UpdatedAt, nil)
+	return &config, nil
+}
+
+func (r *feedLayoutRepository) Save(config *domain.FeedLayoutConfig) error {
+	logger := utils.NewLogger("FeedLayoutRepository.Save")
+	logger.LogInput(config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config.UpdatedAt = time.Now()
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": feedLayoutID}, config, options.Replace().SetUpsert(true))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := r.rdb.Del(ctx, feedLayoutCacheKey).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type feedRepository struct {
+	rdb *redis.Client
+}
+
+func NewFeedRepository(rdb *redis.Client) domain.FeedRepository {
+	return &feedRepository{
+		rdb: rdb,
+	}
+}
+
+func (r *feedRepository) GetAudience(userID primitive.ObjectID) (*domain.FeedAudience, error) {
+	logger := utils.NewLogger("FeedRepository.GetAudience")
+	logger.LogInput(userID)
+
+	key := fmt.Sprintf("feed_audience:%s", userID.Hex())
+	audienceJSON, err := r.rdb.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var audience domain.FeedAudience
+	if err := json.Unmarshal([]byte(audienceJSON), &audience); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&audience, nil)
+	return &audience, nil
+}
+
+func (r *feedRepository) SetAudience(userID primitive.ObjectID, audience *domain.FeedAudience, ttl time.Duration) error {
+	logger := utils.NewLogger("FeedRepository.SetAudience")
+	logger.LogInput(userID, audience)
+
+	audienceBytes, err := json.Marshal(audience)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	key := fmt.Sprintf("feed_audience:%s", userID.Hex())
+	err = r.rdb.Set(context.Background(), key, string(audienceBytes), ttl).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Feed audience cached successfully", nil)
+	return nil
+}
@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
@@ -66,25 +70,63 @@ func NewFileStorage(credentialsFile string, bucketName string) (domain.FileRepos
 	return storage, nil
 }
 
-func (fs *fileStorage) Upload(file *domain.File, fileData multipart.File) (*domain.File, error) {
+func (fs *fileStorage) Upload(file *domain.File, fileData io.Reader) (*domain.File, error) {
 	logger := utils.NewLogger("FileRepository.Upload")
 	logger.LogInput(map[string]interface{}{
 		"fileName":    file.FileName,
 		"contentType": file.ContentType,
 	})
 
-	ctx := context.Background()
-
 	// Generate unique filename using timestamp
 	timestamp := time.Now().UnixNano()
 	ext := filepath.Ext(file.FileName)
 	uniqueFileName := fmt.Sprintf("%d%s", timestamp, ext)
+	if file.ACL != nil {
+		uniqueFileName = domain.PrivateFilePrefix + uniqueFileName
+	}
+
+	fileModel, err := fs.write(uniqueFileName, file, fileData)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]string{
+		"fileURL":  fileModel.FileURL,
+		"fileName": fileModel.FileName,
+	}, nil)
+	return fileModel, nil
+}
+
+func (fs *fileStorage) UploadVariant(original *domain.File, width int, fileData io.Reader) (*domain.File, error) {
+	logger := utils.NewLogger("FileRepository.UploadVariant")
+	logger.LogInput(original.FileName, width)
 
-	obj := fs.bucket.Object(uniqueFileName)
+	fileModel, err := fs.write(domain.MediaVariantName(original.FileName, width), original, fileData)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(fileModel.FileURL, nil)
+	return fileModel, nil
+}
+
+// write stores the data as the named object with the file's content type and duration
+func (fs *fileStorage) write(name string, file *domain.File, fileData io.Reader) (*domain.File, error) {
+	logger := utils.NewLogger("FileRepository.write")
+	ctx := context.Background()
+
+	obj := fs.bucket.Object(name)
 	writer := obj.NewWriter(ctx)
 
 	// Set content type
 	writer.ContentType = file.ContentType
+	if file.Duration > 0 {
+		writer.Metadata = map[string]string{
+			"duration": strconv.FormatFloat(file.Duration, 'f', -1, 64),
+		}
+	}
 
 	if _, err := io.Copy(writer, fileData); err != nil {
 		logger.LogOutput(nil, fmt.Errorf("error copying file to storage: %v", err))
@@ -107,15 +149,91 @@ func (fs *fileStorage) Upload(file *domain.File, fileData multipart.File) (*doma
 	}, nil)
 
 	// Create file model with URL from upload
-	fileModel := &domain.File{
-		FileURL:     fmt.Sprintf("https://firebasestorage.googleapis.com/v0/b/%s/o/%s?alt=media", fs.bucketName, uniqueFileName),
-		FileName:    uniqueFileName,
+	return &domain.File{
+		FileURL:     fs.fileURL(name),
+		FileName:    name,
 		ContentType: file.ContentType,
+		Size:        attrs.Size,
+		Duration:    file.Duration,
+	}, nil
+}
+
+func (fs *fileStorage) FindByURL(fileURL string) (*domain.File, error) {
+	logger := utils.NewLogger("FileRepository.FindByURL")
+	logger.LogInput(fileURL)
+
+	// Only files uploaded to our own bucket can be referenced
+	prefix := fmt.Sprintf("https://firebasestorage.googleapis.com/v0/b/%s/o/", fs.bucketName)
+	if !strings.HasPrefix(fileURL, prefix) {
+		logger.LogOutput(nil, domain.ErrNotFound)
+		return nil, domain.ErrNotFound
 	}
 
-	logger.LogOutput(map[string]string{
-		"fileURL":  fileModel.FileURL,
-		"fileName": fileModel.FileName,
-	}, nil)
-	return fileModel, nil
+	name := strings.TrimPrefix(fileURL, prefix)
+	if i := strings.Index(name, "?"); i >= 0 {
+		name = name[:i]
+	}
+	name, err := url.PathUnescape(name)
+	if err != nil || name == "" {
+		logger.LogOutput(nil, domain.ErrNotFound)
+		return nil, domain.ErrNotFound
+	}
+
+	attrs, err := fs.bucket.Object(name).Attrs(context.Background())
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			err = domain.ErrNotFound
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	file := &domain.File{
+		FileName:    name,
+		FileURL:     fs.fileURL(name),
+		ContentType: attrs.ContentType,
+		Size:        attrs.Size,
+	}
+	if d, ok := attrs.Metadata["duration"]; ok {
+		file.Duration, _ = strconv.ParseFloat(d, 64)
+	}
+
+	logger.LogOutput(file, nil)
+	return file, nil
+}
+
+func (fs *fileStorage) Delete(fileName string) error {
+	logger := utils.NewLogger("FileRepository.Delete")
+	logger.LogInput(fileName)
+
+	err := fs.bucket.Object(fileName).Delete(context.Background())
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (fs *fileStorage) SignedURL(fileName string, expires time.Time) (string, error) {
+	logger := utils.NewLogger("FileRepository.SignedURL")
+	logger.LogInput(fileName, expires)
+
+	signedURL, err := fs.bucket.SignedURL(fileName, &storage.SignedURLOptions{
+		Method:  http.MethodGet,
+		Expires: expires,
+		Scheme:  storage.SigningSchemeV4,
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return "", err
+	}
+
+	logger.LogOutput(nil, nil)
+	return signedURL, nil
+}
+
+func (fs *fileStorage) fileURL(name string) string {
+	return fmt.Sprintf("https://firebasestorage.googleapis.com/v0/b/%s/o/%s?alt=media", fs.bucketName, name)
 }
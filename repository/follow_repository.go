@@ -252,7 +252,8 @@ func (r *followRepository) UpdateStatus(followerID, followingID primitive.Object
 
 	update := bson.M{
 		"$set": bson.M{
-			"status": status,
+			"status":    status,
+			"updatedAt": time.Now(),
 		},
 	}
 
@@ -271,3 +272,211 @@ func (r *followRepository) UpdateStatus(followerID, followingID primitive.Object
 	logger.LogOutput(result, nil)
 	return nil
 }
+
+// Only this many followed accounts are walked for friends-of-friends suggestions
+const maxSecondDegreeSources = 1000
+
+func (r *followRepository) FindRelatedIDs(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	logger := utils.NewLogger("FollowRepository.FindRelatedIDs")
+	logger.LogInput(userID.Hex())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// A block is stored as the blocked user following the blocker with status blocked
+	filter := bson.M{"$or": bson.A{
+		bson.M{"followerId": userID},
+		bson.M{"followingId": userID, "status": "blocked"},
+	}}
+	opts := options.Find().SetProjection(bson.M{"followerId": 1, "followingId": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var follows []domain.Follow
+	if err = cursor.All(ctx, &follows); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		if follow.FollowerID == userID {
+			ids = append(ids, follow.FollowingID)
+		} else {
+			ids = append(ids, follow.FollowerID)
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(ids)}, nil)
+	return ids, nil
+}
+
+func (r *followRepository) FindSecondDegree(userID primitive.ObjectID, exclude []primitive.ObjectID, limit int) ([]domain.SecondDegreeFollow, error) {
+	logger := utils.NewLogger("FollowRepository.FindSecondDegree")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID.Hex(),
+		"limit":  limit,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetProjection(bson.M{"followingId": 1}).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(maxSecondDegreeSources)
+	cursor, err := r.collection.Find(ctx, bson.M{"followerId": userID, "status": "active"}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	var following []domain.Follow
+	if err = cursor.All(ctx, &following); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len(following) == 0 {
+		logger.LogOutput(nil, nil)
+		return []domain.SecondDegreeFollow{}, nil
+	}
+
+	sources := make([]primitive.ObjectID, 0, len(following))
+	for _, follow := range following {
+		sources = append(sources, follow.FollowingID)
+	}
+	excluded := append([]primitive.ObjectID{userID}, exclude...)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"followerId":  bson.M{"$in": sources},
+			"followingId": bson.M{"$nin": excluded},
+			"status":      "active",
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$followingId",
+			"followedBy": bson.M{"$push": "$followerId"},
+			"count":      bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		// Explanations name at most a few of the accounts
+		{{Key: "$project", Value: bson.M{
+			"followedBy": bson.M{"$slice": bson.A{"$followedBy", 3}},
+			"count":      1,
+		}}},
+	}
+
+	cursor, err = r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := make([]domain.SecondDegreeFollow, 0)
+	if err = cursor.All(ctx, &results); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(results)}, nil)
+	return results, nil
+}
+
+func (r *followRepository) FindBlockedIDs(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	logger := utils.NewLogger("FollowRepository.FindBlockedIDs")
+	logger.LogInput(userID.Hex())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status": "blocked",
+		"$or": bson.A{
+			bson.M{"followerId": userID},
+			bson.M{"followingId": userID},
+		},
+	}
+	opts := options.Find().SetProjection(bson.M{"followerId": 1, "followingId": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []domain.Follow
+	if err = cursor.All(ctx, &blocks); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(blocks))
+	for _, block := range blocks {
+		if block.FollowerID == userID {
+			ids = append(ids, block.FollowingID)
+		} else {
+			ids = append(ids, block.FollowerID)
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(ids)}, nil)
+	return ids, nil
+}
+
+// FindBlocks matches the blocked users following the blocker with status blocked
+func (r *followRepository) FindBlocks(userID primitive.ObjectID, limit, offset int) ([]domain.Follow, error) {
+	logger := utils.NewLogger("FollowRepository.FindBlocks")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID.Hex(),
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"followingId": userID, "status": "blocked"}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blocks := make([]domain.Follow, 0)
+	if err = cursor.All(ctx, &blocks); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(blocks)}, nil)
+	return blocks, nil
+}
+
+func (r *followRepository) CountBlocks(userID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("FollowRepository.CountBlocks")
+	logger.LogInput(userID.Hex())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"followingId": userID, "status": "blocked"})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
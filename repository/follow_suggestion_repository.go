@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type followSuggestionRepository struct {
+	rdb *redis.Client
+}
+
+func NewFollowSuggestionRepository(rdb *redis.Client) domain.FollowSuggestionRepository {
+	return &followSuggestionRepository{
+		rdb: rdb,
+	}
+}
+
+func followSuggestionsKey(userID primitive.ObjectID) string {
+	return fmt.Sprintf("follow_suggestions:%s", userID.Hex())
+}
+
+func dismissedSuggestionsKey(userID primitive.ObjectID) string {
+	return fmt.Sprintf("follow_suggestions_dismissed:%s", userID.Hex())
+}
+
+func (r *followSuggestionRepository) GetCached(userID primitive.ObjectID) ([]domain.FollowSuggestion, error) {
+	logger := utils.NewLogger("FollowSuggestionRepository.GetCached")
+	logger.LogInput(userID)
+
+	suggestionsJSON, err := r.rdb.Get(context.Background(), followSuggestionsKey(userID)).Result()
+	if err == redis.Nil {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	suggestions := make([]domain.FollowSuggestion, 0)
+	if err := json.Unmarshal([]byte(suggestionsJSON), &suggestions); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(suggestions)}, nil)
+	return suggestions, nil
+}
+
+func (r *followSuggestionRepository) SetCached(userID primitive.ObjectID, suggestions []domain.FollowSuggestion, ttl time.Duration) error {
+	logger := utils.NewLogger("FollowSuggestionRepository.SetCached")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID.Hex(),
+		"count":  len(suggestions),
+	})
+
+	suggestionsBytes, err := json.Marshal(suggestions)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	err = r.rdb.Set(context.Background(), followSuggestionsKey(userID), string(suggestionsBytes), ttl).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Follow suggestions cached successfully", nil)
+	return nil
+}
+
+func (r *followSuggestionRepository) ClearCached(userID primitive.ObjectID) error {
+	logger := utils.NewLogger("FollowSuggestionRepository.ClearCached")
+	logger.LogInput(userID)
+
+	if err := r.rdb.Del(context.Background(), followSuggestionsKey(userID)).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// Dismiss adds the account to the dismissed set. The whole set expires ttl
+// after the latest dismissal.
+func (r *followSuggestionRepository) Dismiss(userID, suggestedID primitive.ObjectID, ttl time.Duration) error {
+	logger := utils.NewLogger("FollowSuggestionRepository.Dismiss")
+	logger.LogInput(map[string]interface{}{
+		"userID":      userID.Hex(),
+		"suggestedID": suggestedID.Hex(),
+	})
+
+	ctx := context.Background()
+	key := dismissedSuggestionsKey(userID)
+	pipe := r.rdb.TxPipeline()
+	pipe.SAdd(ctx, key, suggestedID.Hex())
+	pipe.Expire(ctx, key, ttl)
+	pipe.Del(ctx, followSuggestionsKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Follow suggestion dismissed", nil)
+	return nil
+}
+
+func (r *followSuggestionRepository) GetDismissed(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	logger := utils.NewLogger("FollowSuggestionRepository.GetDismissed")
+	logger.LogInput(userID)
+
+	members, err := r.rdb.SMembers(context.Background(), dismissedSuggestionsKey(userID)).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(members))
+	for _, member := range members {
+		id, err := primitive.ObjectIDFromHex(member)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(ids)}, nil)
+	return ids, nil
+}
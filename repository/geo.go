@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// geoPointIndex is a 2dsphere index on location, limited to GeoJSON points. Queries
+// have to match location.type as well for the index to be used.
+func geoPointIndex(name string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+		Options: options.Index().
+			SetName(name).
+			SetPartialFilterExpression(bson.M{"location.type": domain.GeoPointType}),
+	}
+}
+
+// geoNearPipeline pages the documents matching the query within radius meters of
+// the coordinates, closest first, with their distance in the distance field
+func geoNearPipeline(coordinates []float64, radiusMeters float64, query bson.M, limit, offset int) mongo.Pipeline {
+	query["location.type"] = domain.GeoPointType
+	return mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          bson.M{"type": domain.GeoPointType, "coordinates": coordinates},
+			"distanceField": "distance",
+			"maxDistance":   radiusMeters,
+			"spherical":     true,
+			"key":           "location",
+			"query":         query,
+		}}},
+		{{Key: "$skip", Value: int64(offset)}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The trending union is cached briefly so busy trending pages don't re-merge every bucket
+const trendingCacheTTL = time.Minute
+
+type hashtagRepository struct {
+	rdb        *redis.Client
+	collection *mongo.Collection
+	posts      *mongo.Collection
+}
+
+func NewHashtagRepository(db *mongo.Database, rdb *redis.Client) domain.HashtagRepository {
+	return &hashtagRepository{
+		rdb:        rdb,
+		collection: db.Collection("tags"),
+		posts:      db.Collection("posts"),
+	}
+}
+
+// EnsureIndexes creates the unique tag index and the index serving tag pages
+func (r *hashtagRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("HashtagRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tag", Value: 1}},
+		Options: options.Index().SetName("tag_unique").SetUnique(true),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	_, err = r.posts.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tags", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("post_tags"),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Tag indexes ready", nil)
+	return nil
+}
+
+func trendingBucketKey(at time.Time) string {
+	return fmt.Sprintf("trending_tags:%s", at.UTC().Format("2006010215"))
+}
+
+func (r *hashtagRepository) IncrementPostCounts(tags []string, delta int) error {
+	logger := utils.NewLogger("HashtagRepository.IncrementPostCounts")
+	logger.LogInput(tags, delta)
+
+	if len(tags) == 0 || delta == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(tags))
+	for _, tag := range tags {
+		update := bson.M{"$inc": bson.M{"postCount": delta}}
+		model := mongo.NewUpdateOneModel().SetFilter(bson.M{"tag": tag})
+		if delta > 0 {
+			// Only new usages create tags and move lastUsedAt
+			update["$set"] = bson.M{"lastUsedAt": now}
+			update["$setOnInsert"] = bson.M{"_id": primitive.NewObjectID()}
+			model.SetUpsert(true)
+		}
+		models = append(models, model.SetUpdate(update))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Tag counts updated", nil)
+	return nil
+}
+
+func (r *hashtagRepository) RecordUsage(tags []string, at time.Time) error {
+	logger := utils.NewLogger("HashtagRepository.RecordUsage")
+	logger.LogInput(tags, at)
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	key := trendingBucketKey(at)
+
+	pipe := r.rdb.TxPipeline()
+	for _, tag := range tags {
+		pipe.ZIncrBy(ctx, key, 1, tag)
+	}
+	// Keep buckets for the longest window that can be requested
+	pipe.Expire(ctx, key, domain.MaxTrendingWindow+time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Tag usage recorded", nil)
+	return nil
+}
+
+func (r *hashtagRepository) FindTrending(window time.Duration, limit int) ([]domain.TrendingTag, error) {
+	logger := utils.NewLogger("HashtagRepository.FindTrending")
+	logger.LogInput(window, limit)
+
+	ctx := context.Background()
+	hours := int(window / time.Hour)
+	now := time.Now()
+
+	keys := make([]string, 0, hours)
+	for i := 0; i < hours; i++ {
+		keys = append(keys, trendingBucketKey(now.Add(-time.Duration(i)*time.Hour)))
+	}
+
+	// The cache key includes the current bucket so it rolls over with the hour
+	unionKey := fmt.Sprintf("trending_tags:window:%d:%s", hours, now.UTC().Format("2006010215"))
+	exists, err := r.rdb.Exists(ctx, unionKey).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if exists == 0 {
+		pipe := r.rdb.TxPipeline()
+		pipe.ZUnionStore(ctx, unionKey, &redis.ZStore{Keys: keys, Aggregate: "SUM"})
+		pipe.Expire(ctx, unionKey, trendingCacheTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	scores, err := r.rdb.ZRevRangeWithScores(ctx, unionKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	trending := make([]domain.TrendingTag, 0, len(scores))
+	for _, score := range scores {
+		trending = append(trending, domain.TrendingTag{
+			Tag:   score.Member,
+			Count: int64(score.Score),
+		})
+	}
+
+	logger.LogOutput(trending, nil)
+	return trending, nil
+}
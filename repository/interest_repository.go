@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type interestRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInterestRepository(db *mongo.Database) domain.InterestRepository {
+	return &interestRepository{
+		collection: db.Collection("interests"),
+	}
+}
+
+func (r *interestRepository) Create(interest *domain.Interest) error {
+	logger := utils.NewLogger("InterestRepository.Create")
+	logger.LogInput(interest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Users store the slug, so slugs must be unique
+	count, err := r.collection.CountDocuments(ctx, bson.M{"slug": interest.Slug})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if count > 0 {
+		logger.LogOutput(nil, domain.ErrDuplicate)
+		return domain.ErrDuplicate
+	}
+
+	now := time.Now()
+	interest.ID = primitive.NewObjectID()
+	interest.CreatedAt = now
+	interest.UpdatedAt = now
+	interest.IsActive = true
+	interest.Version = 1
+
+	_, err = r.collection.InsertOne(ctx, interest)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(interest, nil)
+	return nil
+}
+
+func (r *interestRepository) Update(interest *domain.Interest) error {
+	logger := utils.NewLogger("InterestRepository.Update")
+	logger.LogInput(interest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	interest.UpdatedAt = time.Now()
+	interest.Version++
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": interest.ID}, bson.M{"$set": interest})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		err = domain.ErrNotFound
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(interest, nil)
+	return nil
+}
+
+func (r *interestRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("InterestRepository.Delete")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		err = domain.ErrNotFound
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"deleted": true}, nil)
+	return nil
+}
+
+func (r *interestRepository) FindByID(id primitive.ObjectID) (*domain.Interest, error) {
+	logger := utils.NewLogger("InterestRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var interest domain.Interest
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&interest)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.ErrNotFound
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&interest, nil)
+	return &interest, nil
+}
+
+func (r *interestRepository) List(category string, activeOnly bool) ([]domain.Interest, error) {
+	logger := utils.NewLogger("InterestRepository.List")
+	logger.LogInput(map[string]interface{}{
+		"category":   category,
+		"activeOnly": activeOnly,
+	})
+
+	filter := bson.M{}
+	if category != "" {
+		filter["category"] = category
+	}
+	if activeOnly {
+		filter["isActive"] = true
+	}
+
+	interests, err := r.find(filter, 0)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(interests)}, nil)
+	return interests, nil
+}
+
+func (r *interestRepository) Search(prefix, category string, limit int) ([]domain.Interest, error) {
+	logger := utils.NewLogger("InterestRepository.Search")
+	logger.LogInput(map[string]interface{}{
+		"prefix":   prefix,
+		"category": category,
+		"limit":    limit,
+	})
+
+	filter := bson.M{"isActive": true}
+	if prefix != "" {
+		// Anchored prefix regexes can use an index on searchTerms
+		filter["searchTerms"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	if category != "" {
+		filter["category"] = category
+	}
+
+	interests, err := r.find(filter, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(interests)}, nil)
+	return interests, nil
+}
+
+func (r *interestRepository) FindByTerms(terms []string) ([]domain.Interest, error) {
+	logger := utils.NewLogger("InterestRepository.FindByTerms")
+	logger.LogInput(terms)
+
+	interests, err := r.find(bson.M{"isActive": true, "searchTerms": bson.M{"$in": terms}}, 0)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(interests)}, nil)
+	return interests, nil
+}
+
+func (r *interestRepository) find(filter bson.M, limit int) ([]domain.Interest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "category", Value: 1}, {Key: "slug", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	interests := make([]domain.Interest, 0)
+	if err = cursor.All(ctx, &interests); err != nil {
+		return nil, err
+	}
+	return interests, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mediaBlobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMediaBlobRepository(db *mongo.Database) domain.MediaBlobRepository {
+	return &mediaBlobRepository{
+		collection: db.Collection("media_blobs"),
+	}
+}
+
+// EnsureIndexes creates the unique checksum index deduplication relies on
+func (r *mediaBlobRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("MediaBlobRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "checksum", Value: 1}},
+			Options: options.Index().SetName("checksum_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "fileUrl", Value: 1}},
+			Options: options.Index().SetName("file_url"),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Media blob indexes ready", nil)
+	return nil
+}
+
+func (r *mediaBlobRepository) Create(blob *domain.MediaBlob) error {
+	logger := utils.NewLogger("MediaBlobRepository.Create")
+	logger.LogInput(blob)
+
+	if blob.ID.IsZero() {
+		blob.ID = primitive.NewObjectID()
+	}
+	if blob.CreatedAt.IsZero() {
+		blob.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(context.Background(), blob)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = domain.ErrDuplicate
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(blob.ID, nil)
+	return nil
+}
+
+func (r *mediaBlobRepository) FindByChecksum(checksum string) (*domain.MediaBlob, error) {
+	logger := utils.NewLogger("MediaBlobRepository.FindByChecksum")
+	logger.LogInput(checksum)
+
+	blob, err := r.findOne(bson.M{"checksum": checksum})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(blob, nil)
+	return blob, nil
+}
+
+func (r *mediaBlobRepository) FindByURL(url string) (*domain.MediaBlob, error) {
+	logger := utils.NewLogger("MediaBlobRepository.FindByURL")
+	logger.LogInput(url)
+
+	blob, err := r.findOne(bson.M{"fileUrl": url})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(blob, nil)
+	return blob, nil
+}
+
+func (r *mediaBlobRepository) FindByURLs(urls []string) ([]domain.MediaBlob, error) {
+	logger := utils.NewLogger("MediaBlobRepository.FindByURLs")
+	logger.LogInput(len(urls))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"fileUrl": bson.M{"$in": urls}})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blobs := make([]domain.MediaBlob, 0, len(urls))
+	if err := cursor.All(ctx, &blobs); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(blobs), nil)
+	return blobs, nil
+}
+
+func (r *mediaBlobRepository) FindByID(id primitive.ObjectID) (*domain.MediaBlob, error) {
+	logger := utils.NewLogger("MediaBlobRepository.FindByID")
+	logger.LogInput(id)
+
+	blob, err := r.findOne(bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(blob, nil)
+	return blob, nil
+}
+
+func (r *mediaBlobRepository) findOne(filter bson.M) (*domain.MediaBlob, error) {
+	var blob domain.MediaBlob
+	err := r.collection.FindOne(context.Background(), filter).Decode(&blob)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+func (r *mediaBlobRepository) AddReference(id primitive.ObjectID, delta int64) (*domain.MediaBlob, error) {
+	logger := utils.NewLogger("MediaBlobRepository.AddReference")
+	logger.LogInput(id, delta)
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var blob domain.MediaBlob
+	err := r.collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"refCount": delta}},
+		opts,
+	).Decode(&blob)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("media blob", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&blob, nil)
+	return &blob, nil
+}
+
+func (r *mediaBlobRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("MediaBlobRepository.Delete")
+	logger.LogInput(id)
+
+	_, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mentionRepository struct {
+	rdb *redis.Client
+}
+
+func NewMentionRepository(rdb *redis.Client) domain.MentionRepository {
+	return &mentionRepository{
+		rdb: rdb,
+	}
+}
+
+func mentionCandidatesKey(scope string) string {
+	return fmt.Sprintf("mention_candidates:%s", scope)
+}
+
+// interactionsKey holds the user's interaction partners scored by the last interaction in ms
+func interactionsKey(userID string) string {
+	return fmt.Sprintf("interactions:%s", userID)
+}
+
+func (r *mentionRepository) GetCandidates(scope string) ([]domain.SearchUser, error) {
+	logger := utils.NewLogger("MentionRepository.GetCandidates")
+	logger.LogInput(scope)
+
+	candidatesJSON, err := r.rdb.Get(context.Background(), mentionCandidatesKey(scope)).Result()
+	if err == redis.Nil {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	candidates := make([]domain.SearchUser, 0)
+	if err := json.Unmarshal([]byte(candidatesJSON), &candidates); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(candidates)}, nil)
+	return candidates, nil
+}
+
+func (r *mentionRepository) SetCandidates(scope string, users []domain.SearchUser, ttl time.Duration) error {
+	logger := utils.NewLogger("MentionRepository.SetCandidates")
+	logger.LogInput(map[string]interface{}{
+		"scope": scope,
+		"count": len(users),
+	})
+
+	candidatesBytes, err := json.Marshal(users)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := r.rdb.Set(context.Background(), mentionCandidatesKey(scope), string(candidatesBytes), ttl).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *mentionRepository) TouchInteraction(userID, otherID primitive.ObjectID, at time.Time) error {
+	logger := utils.NewLogger("MentionRepository.TouchInteraction")
+	logger.LogInput(userID, otherID)
+
+	ctx := context.Background()
+	score := float64(at.UnixMilli())
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, pair := range [][2]string{{userID.Hex(), otherID.Hex()}, {otherID.Hex(), userID.Hex()}} {
+			key := interactionsKey(pair[0])
+			pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: pair[1]})
+			pipe.ZRemRangeByRank(ctx, key, 0, -domain.MaxMentionInteractions-1)
+			pipe.Expire(ctx, key, domain.MentionInteractionTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *mentionRepository) GetInteractions(userID primitive.ObjectID, otherIDs []string) (map[string]time.Time, error) {
+	logger := utils.NewLogger("MentionRepository.GetInteractions")
+	logger.LogInput(userID, len(otherIDs))
+
+	interactions := make(map[string]time.Time)
+	if len(otherIDs) == 0 {
+		logger.LogOutput(interactions, nil)
+		return interactions, nil
+	}
+
+	// Members without an interaction come back as 0
+	scores, err := r.rdb.ZMScore(context.Background(), interactionsKey(userID.Hex()), otherIDs...).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	for i, score := range scores {
+		if score > 0 {
+			interactions[otherIDs[i]] = time.UnixMilli(int64(score))
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(interactions)}, nil)
+	return interactions, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type moderationJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewModerationJobRepository(db *mongo.Database) domain.ModerationJobRepository {
+	return &moderationJobRepository{
+		collection: db.Collection("moderation_jobs"),
+	}
+}
+
+func (r *moderationJobRepository) Create(job *domain.ModerationJob) error {
+	logger := utils.NewLogger("ModerationJobRepository.Create")
+	logger.LogInput(map[string]interface{}{
+		"action": job.Action,
+		"total":  job.Total,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.IsActive = true
+	job.Version = 1
+
+	_, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(job.ID, nil)
+	return nil
+}
+
+func (r *moderationJobRepository) FindByID(id primitive.ObjectID) (*domain.ModerationJob, error) {
+	logger := utils.NewLogger("ModerationJobRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job domain.ModerationJob
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("moderation job", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(job.Status, nil)
+	return &job, nil
+}
+
+func (r *moderationJobRepository) FindAll(limit, offset int) ([]domain.ModerationJob, error) {
+	logger := utils.NewLogger("ModerationJobRepository.FindAll")
+	logger.LogInput(map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetProjection(bson.M{"items": 0}).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := make([]domain.ModerationJob, 0)
+	if err = cursor.All(ctx, &jobs); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(jobs)}, nil)
+	return jobs, nil
+}
+
+func (r *moderationJobRepository) ClaimNext(staleBefore time.Time) (*domain.ModerationJob, error) {
+	logger := utils.NewLogger("ModerationJobRepository.ClaimNext")
+	logger.LogInput(staleBefore)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": []bson.M{
+		{"status": domain.ModerationJobPending},
+		{"status": domain.ModerationJobRunning, "updatedAt": bson.M{"$lt": staleBefore}},
+	}}
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{"status": domain.ModerationJobRunning, "updatedAt": now},
+		// Keeps the first start when a stale job is taken over
+		"$min": bson.M{"startedAt": now},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job domain.ModerationJob
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.LogOutput(nil, nil)
+			return nil, nil
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(job.ID, nil)
+	return &job, nil
+}
+
+func (r *moderationJobRepository) SetItemResult(id primitive.ObjectID, index int, item domain.ModerationJobItem) error {
+	logger := utils.NewLogger("ModerationJobRepository.SetItemResult")
+	logger.LogInput(map[string]interface{}{
+		"id":    id,
+		"index": index,
+		"item":  item,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	counter := "succeeded"
+	if item.Status == domain.ModerationItemFailed {
+		counter = "failed"
+	}
+	field := fmt.Sprintf("items.%d", index)
+
+	// Only pending items are set, so a job taken over never counts an item twice
+	filter := bson.M{"_id": id, field + ".status": domain.ModerationItemPending}
+	update := bson.M{
+		"$set": bson.M{field: item, "updatedAt": time.Now()},
+		"$inc": bson.M{counter: 1},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *moderationJobRepository) Complete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("ModerationJobRepository.Complete")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"status":     domain.ModerationJobCompleted,
+		"finishedAt": now,
+		"updatedAt":  now,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *moderationJobRepository) CountPending() (int64, error) {
+	logger := utils.NewLogger("ModerationJobRepository.CountPending")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": domain.ModerationJobPending})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notificationPreferencesRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNotificationPreferencesRepository(db *mongo.Database) domain.NotificationPreferencesRepository {
+	return &notificationPreferencesRepository{
+		collection: db.Collection("notification_preferences"),
+	}
+}
+
+// EnsureIndexes keeps one preferences document per user and indexes the digest
+// opt-ins the daily job pages through
+func (r *notificationPreferencesRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("NotificationPreferencesRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}},
+			Options: options.Index().SetName("user_unique").SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "lastDigestAt", Value: 1}},
+			Options: options.Index().
+				SetName("digest_due").
+				SetPartialFilterExpression(bson.M{"trendingDigest": true}),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Notification preferences indexes ready", nil)
+	return nil
+}
+
+func (r *notificationPreferencesRepository) Find(userID primitive.ObjectID) (*domain.NotificationPreferences, error) {
+	logger := utils.NewLogger("NotificationPreferencesRepository.Find")
+	logger.LogInput(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var preferences domain.NotificationPreferences
+	err := r.collection.FindOne(ctx, bson.M{"userId": userID}).Decode(&preferences)
+	if err == mongo.ErrNoDocuments {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&preferences, nil)
+	return &preferences, nil
+}
+
+func (r *notificationPreferencesRepository) Upsert(preferences *domain.NotificationPreferences) error {
+	logger := utils.NewLogger("NotificationPreferencesRepository.Upsert")
+	logger.LogInput(preferences)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{
+		"trendingDigest": preferences.TrendingDigest,
+		"updatedAt":      now,
+	}
+	unset := bson.M{}
+	if preferences.QuietHours != nil {
+		set["quietHours"] = preferences.QuietHours
+	} else {
+		unset["quietHours"] = ""
+	}
+	if preferences.TimeZone != "" {
+		set["timeZone"] = preferences.TimeZone
+	} else {
+		unset["timeZone"] = ""
+	}
+	update := bson.M{
+		"$set": set,
+		"$setOnInsert": bson.M{
+			"createdAt": now,
+			"isActive":  true,
+			"version":   1,
+		},
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var saved domain.NotificationPreferences
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"userId": preferences.UserID}, update, opts).Decode(&saved)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	*preferences = saved
+
+	logger.LogOutput(preferences, nil)
+	return nil
+}
+
+func (r *notificationPreferencesRepository) FindDigestDue(since time.Time, afterUserID primitive.ObjectID, limit int) ([]domain.NotificationPreferences, error) {
+	logger := utils.NewLogger("NotificationPreferencesRepository.FindDigestDue")
+	logger.LogInput(since, afterUserID, limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"trendingDigest": true,
+		"userId":         bson.M{"$gt": afterUserID},
+		"$or": bson.A{
+			bson.M{"lastDigestAt": bson.M{"$exists": false}},
+			bson.M{"lastDigestAt": bson.M{"$lt": since}},
+		},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "userId", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	due := make([]domain.NotificationPreferences, 0)
+	if err := cursor.All(ctx, &due); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(due)}, nil)
+	return due, nil
+}
+
+func (r *notificationPreferencesRepository) MarkDigestSent(userID primitive.ObjectID, at time.Time) error {
+	logger := utils.NewLogger("NotificationPreferencesRepository.MarkDigestSent")
+	logger.LogInput(userID, at)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"userId": userID},
+		bson.M{"$set": bson.M{"lastDigestAt": at}},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
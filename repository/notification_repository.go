@@ -397,3 +397,134 @@ func (r *notificationRepository) CountUnread(recipientID primitive.ObjectID) (in
 	logger.LogOutput(map[string]interface{}{"count": unreadCount}, nil)
 	return unreadCount, nil
 }
+
+func (r *notificationRepository) CountByRecipient(recipientID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("NotificationRepository.CountByRecipient")
+	logger.LogInput(map[string]interface{}{"recipientId": recipientID.Hex()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := countApprox(ctx, r.collection, bson.M{"recipientId": recipientID})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *notificationRepository) FindAggregate(recipientID, refID primitive.ObjectID, nType domain.NotificationType, since time.Time) (*domain.Notification, error) {
+	logger := utils.NewLogger("NotificationRepository.FindAggregate")
+	logger.LogInput(map[string]interface{}{
+		"recipientID": recipientID.Hex(),
+		"refID":       refID.Hex(),
+		"type":        nType,
+		"since":       since,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"recipientId": recipientID,
+		"refId":       refID,
+		"type":        nType,
+		"isRead":      false,
+		"createdAt":   bson.M{"$gte": since},
+	}
+	opts := options.FindOne().SetSort(bson.M{"createdAt": -1})
+
+	var notification domain.Notification
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&notification)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.LogOutput(nil, nil)
+			return nil, nil
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&notification, nil)
+	return &notification, nil
+}
+
+// AddToAggregate counts the event and rewrites the message in one update, so concurrent
+// events on a hot post are all counted
+func (r *notificationRepository) AddToAggregate(id, senderID primitive.ObjectID, message string) (*domain.Notification, error) {
+	logger := utils.NewLogger("NotificationRepository.AddToAggregate")
+	logger.LogInput(map[string]interface{}{
+		"id":       id.Hex(),
+		"senderID": senderID.Hex(),
+		"message":  message,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Notifications created directly count as one event
+	previous := bson.M{"$ifNull": bson.A{"$aggregateCount", 1}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"aggregateCount": bson.M{"$add": bson.A{previous, 1}},
+		"message":        bson.M{"$concat": bson.A{"and ", bson.M{"$toString": previous}, " others ", message}},
+		"senderId":       senderID,
+		"updatedAt":      time.Now(),
+	}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var notification domain.Notification
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&notification)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.ErrNotFound
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Invalidate recipient's notifications cache
+	pattern := fmt.Sprintf("user_notifications:%s:*", notification.RecipientID.Hex())
+	keys, err := r.rdb.Keys(ctx, pattern).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len(keys) > 0 {
+		err = r.rdb.Del(ctx, keys...).Err()
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	logger.LogOutput(&notification, nil)
+	return &notification, nil
+}
+
+// SetStale records the result of reference validation, unsetting the flag when
+// deleted content has been restored
+func (r *notificationRepository) SetStale(ids []primitive.ObjectID, stale bool) error {
+	logger := utils.NewLogger("NotificationRepository.SetStale")
+	logger.LogInput(map[string]interface{}{
+		"count": len(ids),
+		"stale": stale,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"stale": true}}
+	if !stale {
+		update = bson.M{"$unset": bson.M{"stale": ""}}
+	}
+	_, err := r.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type onboardingStepRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOnboardingStepRepository(db *mongo.Database) domain.OnboardingStepRepository {
+	return &onboardingStepRepository{
+		collection: db.Collection("onboarding_steps"),
+	}
+}
+
+func (r *onboardingStepRepository) Create(step *domain.OnboardingStep) error {
+	logger := utils.NewLogger("OnboardingStepRepository.Create")
+	logger.LogInput(step)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Clients track steps by key, so keys must be unique
+	count, err := r.collection.CountDocuments(ctx, bson.M{"key": step.Key})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if count > 0 {
+		logger.LogOutput(nil, domain.ErrDuplicate)
+		return domain.ErrDuplicate
+	}
+
+	now := time.Now()
+	step.ID = primitive.NewObjectID()
+	step.CreatedAt = now
+	step.UpdatedAt = now
+	step.IsActive = true
+	step.Version = 1
+
+	_, err = r.collection.InsertOne(ctx, step)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(step, nil)
+	return nil
+}
+
+func (r *onboardingStepRepository) Update(step *domain.OnboardingStep) error {
+	logger := utils.NewLogger("OnboardingStepRepository.Update")
+	logger.LogInput(step)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	step.UpdatedAt = time.Now()
+	step.Version++
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": step.ID}, bson.M{"$set": step})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		err = domain.ErrNotFound
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(step, nil)
+	return nil
+}
+
+func (r *onboardingStepRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("OnboardingStepRepository.Delete")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		err = domain.ErrNotFound
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"deleted": true}, nil)
+	return nil
+}
+
+func (r *onboardingStepRepository) FindByID(id primitive.ObjectID) (*domain.OnboardingStep, error) {
+	logger := utils.NewLogger("OnboardingStepRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var step domain.OnboardingStep
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&step)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.ErrNotFound
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&step, nil)
+	return &step, nil
+}
+
+func (r *onboardingStepRepository) List(activeOnly bool) ([]domain.OnboardingStep, error) {
+	logger := utils.NewLogger("OnboardingStepRepository.List")
+	logger.LogInput(activeOnly)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if activeOnly {
+		filter["isActive"] = true
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	steps := make([]domain.OnboardingStep, 0)
+	if err = cursor.All(ctx, &steps); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(steps)}, nil)
+	return steps, nil
+}
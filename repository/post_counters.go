@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Buffered counter changes are kept in one hash per post, keyed by the counter's
+// field path in the post document. The dirty set lists posts waiting for a flush.
+const (
+	postCountersKeyPrefix = "post_counters:"
+	postCountersDirtyKey  = "post_counters_dirty"
+)
+
+func postCountersKey(id primitive.ObjectID) string {
+	return postCountersKeyPrefix + id.Hex()
+}
+
+// Viewers of a post are kept per UTC day, a bit past the end of the day
+const postViewersTTL = 48 * time.Hour
+
+func postViewersKey(id primitive.ObjectID, day time.Time) string {
+	return fmt.Sprintf("post_viewers:%s:%s", id.Hex(), day.UTC().Format("2006-01-02"))
+}
+
+// isPostCounter keeps arbitrary strings out of the $inc paths
+func isPostCounter(counter string) bool {
+	if counter == domain.PostCounterComments || counter == domain.PostCounterViews {
+		return true
+	}
+	reactionType := strings.TrimPrefix(counter, domain.PostReactionCounter(""))
+	return reactionType != counter && reactionType != "" && !strings.ContainsAny(reactionType, ".$")
+}
+
+func (r *postRepository) IncrementCounter(id primitive.ObjectID, counter string, delta int64) error {
+	logger := utils.NewLogger("PostRepository.IncrementCounter")
+	logger.LogInput(map[string]interface{}{
+		"id":      id,
+		"counter": counter,
+		"delta":   delta,
+	})
+
+	if !isPostCounter(counter) {
+		err := fmt.Errorf("%w: unknown post counter %q", domain.ErrInvalidInput, counter)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	ctx := context.Background()
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, postCountersKey(id), counter, delta)
+		pipe.SAdd(ctx, postCountersDirtyKey, id.Hex())
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *postRepository) FlushCounters(limit int) (int, error) {
+	logger := utils.NewLogger("PostRepository.FlushCounters")
+	logger.LogInput(limit)
+
+	ctx := context.Background()
+	ids, err := r.rdb.SPopN(ctx, postCountersDirtyKey, int64(limit)).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	flushed := 0
+	for _, hexID := range ids {
+		id, err := primitive.ObjectIDFromHex(hexID)
+		if err != nil {
+			continue
+		}
+		if err := r.flushPostCounters(ctx, id); err != nil {
+			logger.LogOutput(nil, err)
+			return flushed, err
+		}
+		flushed++
+	}
+
+	logger.LogOutput(flushed, nil)
+	return flushed, nil
+}
+
+func (r *postRepository) AddViewer(id, viewerID primitive.ObjectID, day time.Time) (bool, error) {
+	logger := utils.NewLogger("PostRepository.AddViewer")
+	logger.LogInput(id, viewerID, day)
+
+	ctx := context.Background()
+	key := postViewersKey(id, day)
+	var added *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		added = pipe.SAdd(ctx, key, viewerID.Hex())
+		pipe.Expire(ctx, key, postViewersTTL)
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	first := added.Val() == 1
+	logger.LogOutput(first, nil)
+	return first, nil
+}
+
+// flushPostCounters takes the buffered changes of the post and applies them with $inc.
+// Changes that fail to apply are put back so the next flush retries them.
+func (r *postRepository) flushPostCounters(ctx context.Context, id primitive.ObjectID) error {
+	key := postCountersKey(id)
+	var pending *redis.MapStringStringCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pending = pipe.HGetAll(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		r.rdb.SAdd(ctx, postCountersDirtyKey, id.Hex())
+		return err
+	}
+
+	inc := bson.M{}
+	hasReactions := false
+	for counter, value := range pending.Val() {
+		delta, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || delta == 0 {
+			continue
+		}
+		inc[counter] = delta
+		if counter != domain.PostCounterComments && counter != domain.PostCounterViews {
+			hasReactions = true
+		}
+	}
+	if len(inc) == 0 {
+		return nil
+	}
+
+	if err := r.applyCounters(ctx, id, inc, hasReactions); err != nil {
+		_, restoreErr := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for counter, delta := range inc {
+				pipe.HIncrBy(ctx, key, counter, delta.(int64))
+			}
+			pipe.SAdd(ctx, postCountersDirtyKey, id.Hex())
+			return nil
+		})
+		if restoreErr != nil {
+			return fmt.Errorf("%v, and restoring the buffered counters failed: %v", err, restoreErr)
+		}
+		return err
+	}
+
+	// The cached post has the old counts
+	return r.rdb.Del(ctx, fmt.Sprintf("post:%s", id.Hex())).Err()
+}
+
+func (r *postRepository) applyCounters(ctx context.Context, id primitive.ObjectID, inc bson.M, hasReactions bool) error {
+	// $inc can't create fields under a null reactionCounts
+	if hasReactions {
+		_, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": id, "reactionCounts": bson.M{"$type": "null"}},
+			bson.M{"$set": bson.M{"reactionCounts": bson.M{}}})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": inc})
+	return err
+}
+
+// mergePendingCounters adds the buffered counter changes to posts read from Mongo or the cache.
+// Posts are still returned with their stored counts if Redis fails.
+func (r *postRepository) mergePendingCounters(posts []domain.Post) {
+	logger := utils.NewLogger("PostRepository.mergePendingCounters")
+
+	if len(posts) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	cmds := make([]*redis.MapStringStringCmd, len(posts))
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i := range posts {
+			cmds[i] = pipe.HGetAll(ctx, postCountersKey(posts[i].ID))
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	for i := range posts {
+		for counter, value := range cmds[i].Val() {
+			delta, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			applyCounterDelta(&posts[i], counter, int(delta))
+		}
+	}
+}
+
+func applyCounterDelta(post *domain.Post, counter string, delta int) {
+	switch counter {
+	case domain.PostCounterComments:
+		post.CommentCount = nonNegative(post.CommentCount + delta)
+	case domain.PostCounterViews:
+		post.ViewCount = nonNegative(post.ViewCount + delta)
+	default:
+		reactionType := strings.TrimPrefix(counter, domain.PostReactionCounter(""))
+		if post.ReactionCounts == nil {
+			post.ReactionCounts = make(map[string]int)
+		}
+		post.ReactionCounts[reactionType] = nonNegative(post.ReactionCounts[reactionType] + delta)
+	}
+}
+
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func (r *postRepository) mergePostCounters(post *domain.Post) {
+	posts := []domain.Post{*post}
+	r.mergePendingCounters(posts)
+	*post = posts[0]
+}
+
+// toBsonMap converts a document to a map so single fields can be left out of an update
+func toBsonMap(doc interface{}) (bson.M, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var m bson.M
+	if err := bson.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
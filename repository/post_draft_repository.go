@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type postDraftRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPostDraftRepository(db *mongo.Database) domain.PostDraftRepository {
+	return &postDraftRepository{
+		collection: db.Collection("post_drafts"),
+	}
+}
+
+// EnsureIndexes creates the index serving a user's draft list
+func (r *postDraftRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("PostDraftRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "updatedAt", Value: -1}},
+		Options: options.Index().SetName("user_drafts"),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Post draft indexes ready", nil)
+	return nil
+}
+
+func (r *postDraftRepository) Create(draft *domain.PostDraft) error {
+	logger := utils.NewLogger("PostDraftRepository.Create")
+	logger.LogInput(draft)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	draft.ID = primitive.NewObjectID()
+	draft.CreatedAt = now
+	draft.UpdatedAt = now
+	draft.IsActive = true
+	draft.Version = 1
+
+	_, err := r.collection.InsertOne(ctx, draft)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return nil
+}
+
+func (r *postDraftRepository) Update(draft *domain.PostDraft) error {
+	logger := utils.NewLogger("PostDraftRepository.Update")
+	logger.LogInput(draft)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	draft.UpdatedAt = time.Now()
+	draft.Version++
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": draft.ID}, draft)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		err = domain.NewNotFoundError("post draft", draft.ID.Hex())
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *postDraftRepository) FindByID(id primitive.ObjectID) (*domain.PostDraft, error) {
+	logger := utils.NewLogger("PostDraftRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var draft domain.PostDraft
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&draft)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("post draft", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return &draft, nil
+}
+
+func (r *postDraftRepository) FindByUserID(userID primitive.ObjectID, limit, offset int) ([]domain.PostDraft, error) {
+	logger := utils.NewLogger("PostDraftRepository.FindByUserID")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	drafts := make([]domain.PostDraft, 0)
+	if err = cursor.All(ctx, &drafts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(drafts)}, nil)
+	return drafts, nil
+}
+
+func (r *postDraftRepository) CountByUserID(userID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("PostDraftRepository.CountByUserID")
+	logger.LogInput(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"userId": userID})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *postDraftRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("PostDraftRepository.Delete")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		err = domain.NewNotFoundError("post draft", id.Hex())
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
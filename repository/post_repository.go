@@ -29,6 +29,21 @@ func NewPostRepository(db *mongo.Database, rdb *redis.Client) domain.PostReposit
 	}
 }
 
+// EnsureIndexes creates the 2dsphere index of post locations. Only GeoJSON points
+// are indexed, so posts tagged with a place name alone can still be saved.
+func (r *postRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("PostRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateOne(ctx, geoPointIndex("post_location"))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Post indexes ready", nil)
+	return nil
+}
+
 func (r *postRepository) Create(post *domain.Post) error {
 	logger := utils.NewLogger("PostRepository.Create")
 	logger.LogInput(post)
@@ -63,9 +78,20 @@ func (r *postRepository) Update(post *domain.Post) error {
 	logger := utils.NewLogger("PostRepository.Update")
 	logger.LogInput(post)
 
+	// Counters change through IncrementCounter only. The post may carry merged
+	// counts that are still buffered, writing them here would count them twice.
+	set, err := toBsonMap(post)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	delete(set, "reactionCounts")
+	delete(set, domain.PostCounterComments)
+	delete(set, domain.PostCounterViews)
+
 	filter := bson.M{"_id": post.ID}
-	update := bson.M{"$set": post}
-	_, err := r.collection.UpdateOne(context.Background(), filter, update)
+	update := bson.M{"$set": set}
+	_, err = r.collection.UpdateOne(context.Background(), filter, update)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
@@ -170,6 +196,7 @@ func (r *postRepository) FindByID(id primitive.ObjectID) (*domain.Post, error) {
 			logger.LogOutput(nil, err)
 			return nil, err
 		}
+		r.mergePostCounters(&post)
 		logger.LogOutput(&post, nil)
 		return &post, nil
 	} else if err != redis.Nil {
@@ -209,22 +236,258 @@ func (r *postRepository) FindByID(id primitive.ObjectID) (*domain.Post, error) {
 		logger.LogOutput(nil, err)
 	}
 
+	r.mergePostCounters(&post)
 	logger.LogOutput(&post, nil)
 	return &post, nil
 }
 
-func (r *postRepository) FindByUserID(userID primitive.ObjectID, limit, offset int, hasMedia bool, mediaType string) ([]domain.Post, error) {
+func (r *postRepository) FindByUserID(userID primitive.ObjectID, visibilities []string, limit, offset int, hasMedia bool, mediaType string) ([]domain.Post, error) {
 	logger := utils.NewLogger("PostRepository.FindByUserID")
 
 	input := map[string]interface{}{
-		"userID":    userID,
-		"limit":     limit,
-		"offset":    offset,
-		"hasMedia":  hasMedia,
-		"mediaType": mediaType,
+		"userID":       userID,
+		"visibilities": visibilities,
+		"limit":        limit,
+		"offset":       offset,
+		"hasMedia":     hasMedia,
+		"mediaType":    mediaType,
 	}
 	logger.LogInput(input)
 
+	filter := userPostsFilter(userID, visibilities, hasMedia, mediaType)
+
+	opts := options.Find()
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+	opts.SetSort(bson.D{
+		{Key: "isPinned", Value: -1},
+		{Key: "pinnedAt", Value: -1},
+		{Key: "createdAt", Value: -1},
+	})
+
+	cursor, err := r.collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var posts []domain.Post
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	r.mergePendingCounters(posts)
+	logger.LogOutput(posts, nil)
+	return posts, nil
+}
+
+func (r *postRepository) FindDeleted(since time.Time, limit, offset int) ([]domain.Post, error) {
+	logger := utils.NewLogger("PostRepository.FindDeleted")
+	logger.LogInput(map[string]interface{}{
+		"since":  since,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	filter := bson.M{"deletedAt": bson.M{"$gte": since}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deletedAt", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var posts []domain.Post
+	if err = cursor.All(context.Background(), &posts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(posts)}, nil)
+	return posts, nil
+}
+
+func (r *postRepository) FindDeletedByID(id primitive.ObjectID) (*domain.Post, error) {
+	logger := utils.NewLogger("PostRepository.FindDeletedByID")
+	logger.LogInput(id)
+
+	var post domain.Post
+	err := r.collection.FindOne(context.Background(), bson.M{
+		"_id":       id,
+		"deletedAt": bson.M{"$exists": true},
+	}).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("post", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&post, nil)
+	return &post, nil
+}
+
+func (r *postRepository) IncrementShareCount(id primitive.ObjectID) error {
+	logger := utils.NewLogger("PostRepository.IncrementShareCount")
+	logger.LogInput(id)
+
+	ctx := context.Background()
+	filter := bson.M{
+		"_id":       id,
+		"deletedAt": bson.M{"$exists": false},
+	}
+	update := bson.M{"$inc": bson.M{"shareCount": 1}}
+
+	var post domain.Post
+	err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("post", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Invalidate post cache and user's posts cache
+	err = r.rdb.Del(ctx, fmt.Sprintf("post:%s", id.Hex())).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	keys, err := r.rdb.Keys(ctx, fmt.Sprintf("user_posts:%s:*", post.UserID.Hex())).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(keys) > 0 {
+		err = r.rdb.Del(ctx, keys...).Err()
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
+	logger.LogOutput("Share count incremented successfully", nil)
+	return nil
+}
+
+func (r *postRepository) Restore(id primitive.ObjectID) error {
+	logger := utils.NewLogger("PostRepository.Restore")
+	logger.LogInput(id)
+
+	ctx := context.Background()
+	filter := bson.M{
+		"_id":       id,
+		"deletedAt": bson.M{"$exists": true},
+	}
+
+	var post domain.Post
+	err := r.collection.FindOne(ctx, filter).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("post", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	update := bson.M{
+		"$set":   bson.M{"updatedAt": time.Now()},
+		"$unset": bson.M{"deletedAt": ""},
+	}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Invalidate user's posts cache
+	pattern := fmt.Sprintf("user_posts:%s:*", post.UserID.Hex())
+	keys, err := r.rdb.Keys(ctx, pattern).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(keys) > 0 {
+		err = r.rdb.Del(ctx, keys...).Err()
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
+	logger.LogOutput("Post restored successfully", nil)
+	return nil
+}
+
+func (r *postRepository) DeleteByUserID(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	logger := utils.NewLogger("PostRepository.DeleteByUserID")
+	logger.LogInput(userID)
+
+	ctx := context.Background()
+	filter := bson.M{"userId": userID}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	var posts []domain.Post
+	err = cursor.All(ctx, &posts)
+	cursor.Close(ctx)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	if len(ids) == 0 {
+		logger.LogOutput(ids, nil)
+		return ids, nil
+	}
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Invalidate the posts' caches and the user's posts cache
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf("post:%s", id.Hex()))
+	}
+	userKeys, err := r.rdb.Keys(ctx, fmt.Sprintf("user_posts:%s:*", userID.Hex())).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	keys = append(keys, userKeys...)
+	if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"deleted": len(ids)}, nil)
+	return ids, nil
+}
+
+// userPostsFilter builds the filter shared by FindByUserID and CountByUserID
+func userPostsFilter(userID primitive.ObjectID, visibilities []string, hasMedia bool, mediaType string) bson.M {
 	filter := bson.M{
 		"userId":   userID,
 		"isActive": true,
@@ -260,28 +523,426 @@ func (r *postRepository) FindByUserID(userID primitive.ObjectID, limit, offset i
 		}
 	}
 
-	opts := options.Find()
-	if limit > 0 {
-		opts.SetLimit(int64(limit))
+	if visibilities != nil {
+		filter = bson.M{"$and": []bson.M{
+			filter,
+			{"visibility": bson.M{"$in": visibilityValues(visibilities)}},
+		}}
 	}
-	if offset > 0 {
-		opts.SetSkip(int64(offset))
+
+	return filter
+}
+
+// visibilityValues adds null for "" so posts stored without a visibility match it
+func visibilityValues(visibilities []string) []interface{} {
+	values := make([]interface{}, 0, len(visibilities)+1)
+	for _, visibility := range visibilities {
+		values = append(values, visibility)
+		if visibility == "" {
+			values = append(values, nil)
+		}
 	}
-	opts.SetSort(bson.M{"createdAt": -1})
+	return values
+}
 
-	cursor, err := r.collection.Find(context.Background(), filter, opts)
+func (r *postRepository) CountByUserID(userID primitive.ObjectID, visibilities []string, hasMedia bool, mediaType string) (int64, error) {
+	logger := utils.NewLogger("PostRepository.CountByUserID")
+	logger.LogInput(map[string]interface{}{
+		"userID":       userID,
+		"visibilities": visibilities,
+		"hasMedia":     hasMedia,
+		"mediaType":    mediaType,
+	})
+
+	count, err := countApprox(context.Background(), r.collection, userPostsFilter(userID, visibilities, hasMedia, mediaType))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *postRepository) CountPinnedByUserID(userID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("PostRepository.CountPinnedByUserID")
+	logger.LogInput(userID)
+
+	filter := bson.M{
+		"userId":    userID,
+		"isPinned":  true,
+		"deletedAt": bson.M{"$exists": false},
+	}
+	count, err := r.collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *postRepository) FindByIDs(ids []primitive.ObjectID) ([]domain.Post, error) {
+	logger := utils.NewLogger("PostRepository.FindByIDs")
+	logger.LogInput(ids)
+
+	filter := bson.M{
+		"_id":       bson.M{"$in": ids},
+		"deletedAt": bson.M{"$exists": false},
+	}
+
+	cursor, err := r.collection.Find(context.Background(), filter)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 	defer cursor.Close(context.Background())
 
-	var posts []domain.Post
+	posts := make([]domain.Post, 0, len(ids))
 	if err := cursor.All(context.Background(), &posts); err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
-	logger.LogOutput(posts, nil)
+	r.mergePendingCounters(posts)
+	logger.LogOutput(map[string]interface{}{"count": len(posts)}, nil)
+	return posts, nil
+}
+
+// feedFilter matches the viewer's own posts, friends' posts shared with friends and followed users' public posts
+func feedFilter(viewerID primitive.ObjectID, audience *domain.FeedAudience) bson.M {
+	public := []interface{}{domain.VisibilityPublic, "", nil}
+	friendsOnly := append([]interface{}{domain.VisibilityFriends}, public...)
+
+	return bson.M{
+		"isActive": true,
+		"deletedAt": bson.M{
+			"$exists": false,
+		},
+		"$or": []bson.M{
+			{"userId": viewerID},
+			{"userId": bson.M{"$in": audience.FriendIDs}, "visibility": bson.M{"$in": friendsOnly}},
+			{"userId": bson.M{"$in": audience.FollowingIDs}, "visibility": bson.M{"$in": public}},
+		},
+	}
+}
+
+func (r *postRepository) FindFeed(viewerID primitive.ObjectID, audience *domain.FeedAudience, limit, offset int) ([]domain.Post, error) {
+	logger := utils.NewLogger("PostRepository.FindFeed")
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID,
+		"audience": audience,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(context.Background(), feedFilter(viewerID, audience), opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	posts := make([]domain.Post, 0)
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	r.mergePendingCounters(posts)
+	logger.LogOutput(map[string]interface{}{"count": len(posts)}, nil)
+	return posts, nil
+}
+
+func (r *postRepository) CountFeed(viewerID primitive.ObjectID, audience *domain.FeedAudience) (int64, error) {
+	logger := utils.NewLogger("PostRepository.CountFeed")
+	logger.LogInput(viewerID, audience)
+
+	count, err := countApprox(context.Background(), r.collection, feedFilter(viewerID, audience))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func tagFilter(viewerID primitive.ObjectID, tag string) bson.M {
+	return bson.M{
+		"isActive": true,
+		"deletedAt": bson.M{
+			"$exists": false,
+		},
+		"tags": tag,
+		"$or": []bson.M{
+			{"userId": viewerID},
+			{"visibility": bson.M{"$in": []interface{}{domain.VisibilityPublic, "", nil}}},
+		},
+	}
+}
+
+func (r *postRepository) FindByTag(viewerID primitive.ObjectID, tag string, limit, offset int) ([]domain.Post, error) {
+	logger := utils.NewLogger("PostRepository.FindByTag")
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID,
+		"tag":      tag,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(context.Background(), tagFilter(viewerID, tag), opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	posts := make([]domain.Post, 0)
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	r.mergePendingCounters(posts)
+	logger.LogOutput(map[string]interface{}{"count": len(posts)}, nil)
+	return posts, nil
+}
+
+func (r *postRepository) CountByTag(viewerID primitive.ObjectID, tag string) (int64, error) {
+	logger := utils.NewLogger("PostRepository.CountByTag")
+	logger.LogInput(viewerID, tag)
+
+	count, err := countApprox(context.Background(), r.collection, tagFilter(viewerID, tag))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *postRepository) FindPopularSince(tags []string, authorIDs, excludeAuthors []primitive.ObjectID, since time.Time, limit int) ([]domain.Post, error) {
+	logger := utils.NewLogger("PostRepository.FindPopularSince")
+	logger.LogInput(map[string]interface{}{
+		"tags":           tags,
+		"authors":        len(authorIDs),
+		"excludeAuthors": len(excludeAuthors),
+		"since":          since,
+		"limit":          limit,
+	})
+
+	posts := make([]domain.Post, 0)
+	if len(tags) == 0 && len(authorIDs) == 0 {
+		logger.LogOutput(posts, nil)
+		return posts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := bson.M{
+		"isActive":   true,
+		"deletedAt":  bson.M{"$exists": false},
+		"visibility": bson.M{"$in": []interface{}{domain.VisibilityPublic, "", nil}},
+		"createdAt":  bson.M{"$gte": since},
+	}
+	sources := bson.A{}
+	if len(tags) > 0 {
+		sources = append(sources, bson.M{"tags": bson.M{"$in": tags}})
+	}
+	if len(authorIDs) > 0 {
+		sources = append(sources, bson.M{"userId": bson.M{"$in": authorIDs}})
+	}
+	match["$or"] = sources
+	if len(excludeAuthors) > 0 {
+		match["userId"] = bson.M{"$nin": excludeAuthors}
+	}
+
+	// Engagement is the comment count plus every reaction count, like domain.PostEngagement
+	engagement := bson.M{"$add": bson.A{
+		bson.M{"$ifNull": bson.A{"$commentCount", 0}},
+		bson.M{"$sum": bson.M{"$map": bson.M{
+			"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$reactionCounts", bson.M{}}}},
+			"as":    "reaction",
+			"in":    "$$reaction.v",
+		}}},
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"engagement": engagement}}},
+		{{Key: "$sort", Value: bson.D{{Key: "engagement", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &posts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	r.mergePendingCounters(posts)
+	logger.LogOutput(map[string]interface{}{"count": len(posts)}, nil)
 	return posts, nil
 }
+
+func (r *postRepository) FindNearby(viewerID primitive.ObjectID, coordinates []float64, radiusMeters float64, limit, offset int) ([]domain.PostWithDistance, error) {
+	logger := utils.NewLogger("PostRepository.FindNearby")
+	logger.LogInput(map[string]interface{}{
+		"viewerID":     viewerID,
+		"coordinates":  coordinates,
+		"radiusMeters": radiusMeters,
+		"limit":        limit,
+		"offset":       offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"isActive": true,
+		"deletedAt": bson.M{
+			"$exists": false,
+		},
+		"$or": []bson.M{
+			{"userId": viewerID},
+			{"visibility": bson.M{"$in": []interface{}{domain.VisibilityPublic, "", nil}}},
+		},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, geoNearPipeline(coordinates, radiusMeters, query, limit, offset))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := make([]domain.PostWithDistance, 0)
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	posts := make([]domain.Post, len(results))
+	for i := range results {
+		posts[i] = results[i].Post
+	}
+	r.mergePendingCounters(posts)
+	for i := range results {
+		results[i].Post = posts[i]
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(results)}, nil)
+	return results, nil
+}
+
+func (r *postRepository) AcquireEditLock(lock *domain.PostEditLock, ttl time.Duration) (bool, error) {
+	logger := utils.NewLogger("PostRepository.AcquireEditLock")
+	logger.LogInput(lock, ttl)
+
+	ctx := context.Background()
+	key := fmt.Sprintf("post_edit_lock:%s", lock.PostID.Hex())
+
+	lockBytes, err := json.Marshal(lock)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	acquired, err := r.rdb.SetNX(ctx, key, string(lockBytes), ttl).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if acquired {
+		logger.LogOutput(true, nil)
+		return true, nil
+	}
+
+	// Already locked, extend it only for the session holding the token
+	current, err := r.GetEditLock(lock.PostID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if current != nil && current.Token != lock.Token {
+		logger.LogOutput(false, nil)
+		return false, nil
+	}
+
+	err = r.rdb.Set(ctx, key, string(lockBytes), ttl).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(true, nil)
+	return true, nil
+}
+
+func (r *postRepository) GetEditLock(postID primitive.ObjectID) (*domain.PostEditLock, error) {
+	logger := utils.NewLogger("PostRepository.GetEditLock")
+	logger.LogInput(postID)
+
+	key := fmt.Sprintf("post_edit_lock:%s", postID.Hex())
+	lockJSON, err := r.rdb.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var lock domain.PostEditLock
+	if err := json.Unmarshal([]byte(lockJSON), &lock); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&lock, nil)
+	return &lock, nil
+}
+
+func (r *postRepository) ReleaseEditLock(postID primitive.ObjectID, token string) error {
+	logger := utils.NewLogger("PostRepository.ReleaseEditLock")
+	logger.LogInput(postID)
+
+	lock, err := r.GetEditLock(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if lock == nil || lock.Token != token {
+		logger.LogOutput("Lock not held by token", nil)
+		return nil
+	}
+
+	err = r.rdb.Del(context.Background(), fmt.Sprintf("post_edit_lock:%s", postID.Hex())).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Edit lock released successfully", nil)
+	return nil
+}
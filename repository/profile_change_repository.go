@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type profileChangeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewProfileChangeRepository(db *mongo.Database) domain.ProfileChangeRepository {
+	// Old and new values decode embedded documents as maps so they render as JSON objects
+	opts := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	return &profileChangeRepository{
+		collection: db.Collection("profile_changes", opts),
+	}
+}
+
+func (r *profileChangeRepository) CreateMany(changes []domain.ProfileChange) error {
+	logger := utils.NewLogger("ProfileChangeRepository.CreateMany")
+	logger.LogInput(map[string]interface{}{"count": len(changes)})
+
+	if len(changes) == 0 {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	docs := make([]interface{}, len(changes))
+	for i := range changes {
+		changes[i].ID = primitive.NewObjectID()
+		changes[i].CreatedAt = now
+		changes[i].UpdatedAt = now
+		changes[i].IsActive = true
+		changes[i].Version = 1
+		docs[i] = changes[i]
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *profileChangeRepository) FindByID(id primitive.ObjectID) (*domain.ProfileChange, error) {
+	logger := utils.NewLogger("ProfileChangeRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var change domain.ProfileChange
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&change)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("profile change", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(change, nil)
+	return &change, nil
+}
+
+func (r *profileChangeRepository) FindByUser(userID primitive.ObjectID, field string, limit, offset int) ([]domain.ProfileChange, error) {
+	logger := utils.NewLogger("ProfileChangeRepository.FindByUser")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"field":  field,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": userID}
+	if field != "" {
+		filter["field"] = field
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	changes := make([]domain.ProfileChange, 0)
+	if err = cursor.All(ctx, &changes); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(changes)}, nil)
+	return changes, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Buffered visits are kept in one hash per profile and day, keyed by source.
+// The dirty set lists the "<userId>:<day>" pairs waiting for a flush.
+const (
+	profileVisitsKeyPrefix = "profile_visits:"
+	profileVisitsDirtyKey  = "profile_visits_dirty"
+)
+
+type profileVisitRepository struct {
+	collection *mongo.Collection
+	rdb        *redis.Client
+}
+
+func NewProfileVisitRepository(db *mongo.Database, rdb *redis.Client) domain.ProfileVisitRepository {
+	return &profileVisitRepository{
+		collection: db.Collection("profile_visit_stats"),
+		rdb:        rdb,
+	}
+}
+
+func profileVisitsKey(profileDay string) string {
+	return profileVisitsKeyPrefix + profileDay
+}
+
+func (r *profileVisitRepository) Record(ownerID, visitorID primitive.ObjectID, source string, at time.Time) (bool, error) {
+	logger := utils.NewLogger("ProfileVisitRepository.Record")
+	logger.LogInput(map[string]interface{}{
+		"ownerID":   ownerID.Hex(),
+		"visitorID": visitorID.Hex(),
+		"source":    source,
+	})
+
+	ctx := context.Background()
+	day := at.UTC().Format(domain.ProfileVisitDayFormat)
+	profileDay := ownerID.Hex() + ":" + day
+
+	seenKey := fmt.Sprintf("profile_visit_seen:%s:%s", profileDay, visitorID.Hex())
+	first, err := r.rdb.SetNX(ctx, seenKey, 1, domain.ProfileVisitDedupeWindow).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if !first {
+		logger.LogOutput(false, nil)
+		return false, nil
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, profileVisitsKey(profileDay), source, 1)
+		pipe.SAdd(ctx, profileVisitsDirtyKey, profileDay)
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(true, nil)
+	return true, nil
+}
+
+func (r *profileVisitRepository) Flush(limit int) (int, error) {
+	logger := utils.NewLogger("ProfileVisitRepository.Flush")
+	logger.LogInput(limit)
+
+	ctx := context.Background()
+	profileDays, err := r.rdb.SPopN(ctx, profileVisitsDirtyKey, int64(limit)).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	flushed := 0
+	for _, profileDay := range profileDays {
+		if err := r.flushProfileDay(ctx, profileDay); err != nil {
+			logger.LogOutput(nil, err)
+			return flushed, err
+		}
+		flushed++
+	}
+
+	logger.LogOutput(flushed, nil)
+	return flushed, nil
+}
+
+// flushProfileDay takes the buffered visits of the profile day and adds them to its stats.
+// Visits that fail to apply are put back so the next flush retries them.
+func (r *profileVisitRepository) flushProfileDay(ctx context.Context, profileDay string) error {
+	hexID, day, ok := strings.Cut(profileDay, ":")
+	userID, err := primitive.ObjectIDFromHex(hexID)
+	if !ok || err != nil {
+		return nil
+	}
+
+	key := profileVisitsKey(profileDay)
+	var pending *redis.MapStringStringCmd
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pending = pipe.HGetAll(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		r.rdb.SAdd(ctx, profileVisitsDirtyKey, profileDay)
+		return err
+	}
+
+	counts := make(map[string]int64)
+	inc := bson.M{}
+	var total int64
+	for source, value := range pending.Val() {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || count == 0 {
+			continue
+		}
+		counts[source] = count
+		inc["sources."+source] = count
+		total += count
+	}
+	if total == 0 {
+		return nil
+	}
+	inc["total"] = total
+
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"userId": userID, "day": day},
+		bson.M{"$inc": inc},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		_, restoreErr := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for source, count := range counts {
+				pipe.HIncrBy(ctx, key, source, count)
+			}
+			pipe.SAdd(ctx, profileVisitsDirtyKey, profileDay)
+			return nil
+		})
+		if restoreErr != nil {
+			return fmt.Errorf("%v, and restoring the buffered visits failed: %v", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *profileVisitRepository) FindDaily(ownerID primitive.ObjectID, fromDay string) ([]domain.ProfileVisitDay, error) {
+	logger := utils.NewLogger("ProfileVisitRepository.FindDaily")
+	logger.LogInput(map[string]interface{}{
+		"ownerID": ownerID.Hex(),
+		"fromDay": fromDay,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Days are formatted so they sort as strings
+	filter := bson.M{"userId": ownerID, "day": bson.M{"$gte": fromDay}}
+	opts := options.Find().SetSort(bson.D{{Key: "day", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	days := make([]domain.ProfileVisitDay, 0)
+	if err = cursor.All(ctx, &days); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(days)}, nil)
+	return days, nil
+}
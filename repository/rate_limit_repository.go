@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+type rateLimitRepository struct {
+	rdb *redis.Client
+}
+
+func NewRateLimitRepository(rdb *redis.Client) domain.RateLimitRepository {
+	return &rateLimitRepository{
+		rdb: rdb,
+	}
+}
+
+func rateLimitKey(key string) string {
+	return fmt.Sprintf("rate_limit:%s", key)
+}
+
+// Allow keeps the request times of the key in a sorted set. The request is
+// added before it's counted so concurrent requests can't all slip in, and taken
+// out again when it doesn't fit.
+func (r *rateLimitRepository) Allow(key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	logger := utils.NewLogger("RateLimitRepository.Allow")
+	logger.LogInput(key, limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	redisKey := rateLimitKey(key)
+	now := time.Now()
+	member := fmt.Sprintf("%d:%d", now.UnixNano(), rand.Int63())
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, redisKey, "-inf", fmt.Sprintf("%d", now.Add(-limit.Window).UnixMilli()))
+	pipe.ZAdd(ctx, redisKey, redis.Z{Score: float64(now.UnixMilli()), Member: member})
+	count := pipe.ZCard(ctx, redisKey)
+	oldest := pipe.ZRangeWithScores(ctx, redisKey, 0, 0)
+	pipe.PExpire(ctx, redisKey, limit.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if count.Val() <= int64(limit.Requests) {
+		result := &domain.RateLimitResult{
+			Allowed:   true,
+			Remaining: limit.Requests - int(count.Val()),
+		}
+		logger.LogOutput(result, nil)
+		return result, nil
+	}
+
+	if err := r.rdb.ZRem(ctx, redisKey, member).Err(); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	result := &domain.RateLimitResult{RetryAfter: limit.Window}
+	if entries := oldest.Val(); len(entries) > 0 {
+		oldestAt := time.UnixMilli(int64(entries[0].Score))
+		if wait := oldestAt.Add(limit.Window).Sub(now); wait > 0 {
+			result.RetryAfter = wait
+		}
+	}
+
+	logger.LogOutput(result, nil)
+	return result, nil
+}
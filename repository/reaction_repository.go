@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,15 +16,21 @@ import (
 )
 
 type reactionRepository struct {
-	db *mongo.Database
+	db  *mongo.Database
+	rdb *redis.Client
 }
 
-func NewReactionRepository(db *mongo.Database) domain.ReactionRepository {
+func NewReactionRepository(db *mongo.Database, rdb *redis.Client) domain.ReactionRepository {
 	return &reactionRepository{
-		db: db,
+		db:  db,
+		rdb: rdb,
 	}
 }
 
+func postReactionAnalyticsKey(postID primitive.ObjectID) string {
+	return fmt.Sprintf("post_reaction_analytics:%s", postID.Hex())
+}
+
 func (r *reactionRepository) Create(reaction *domain.Reaction) error {
 	logger := utils.NewLogger("ReactionRepository.Create")
 	logger.LogInput(reaction)
@@ -142,6 +151,46 @@ func (r *reactionRepository) FindByCommentID(commentID primitive.ObjectID, limit
 	return reactions, nil
 }
 
+func (r *reactionRepository) FindByUserID(userID primitive.ObjectID, limit, offset int) ([]domain.Reaction, error) {
+	logger := utils.NewLogger("ReactionRepository.FindByUserID")
+	logger.LogInput(userID, limit, offset)
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.db.Collection("reactions").Find(context.Background(), bson.M{"userId": userID, "deletedAt": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	reactions := make([]domain.Reaction, 0)
+	if err = cursor.All(context.Background(), &reactions); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(len(reactions), nil)
+	return reactions, nil
+}
+
+func (r *reactionRepository) DeleteByUserID(userID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("ReactionRepository.DeleteByUserID")
+	logger.LogInput(userID)
+
+	result, err := r.db.Collection("reactions").DeleteMany(context.Background(), bson.M{"userId": userID})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(result.DeletedCount, nil)
+	return result.DeletedCount, nil
+}
+
 func (r *reactionRepository) FindByUserAndTarget(userID, postID primitive.ObjectID, commentID *primitive.ObjectID) (*domain.Reaction, error) {
 	logger := utils.NewLogger("ReactionRepository.FindByUserAndTarget")
 	logger.LogInput(userID, postID, commentID)
@@ -168,3 +217,221 @@ func (r *reactionRepository) FindByUserAndTarget(userID, postID primitive.Object
 	logger.LogOutput(&reaction, nil)
 	return &reaction, nil
 }
+
+func (r *reactionRepository) CountByTarget(targetID primitive.ObjectID, isComment bool) (int64, error) {
+	logger := utils.NewLogger("ReactionRepository.CountByTarget")
+	logger.LogInput(targetID, isComment)
+
+	filter := bson.M{"postId": targetID, "deletedAt": bson.M{"$exists": false}}
+	if isComment {
+		filter = bson.M{"commentId": targetID, "deletedAt": bson.M{"$exists": false}}
+	}
+
+	count, err := countApprox(context.Background(), r.db.Collection("reactions"), filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *reactionRepository) SummarizeByTarget(targetID primitive.ObjectID, isComment bool, usersPerType int) ([]domain.ReactionTypeSummary, error) {
+	logger := utils.NewLogger("ReactionRepository.SummarizeByTarget")
+	logger.LogInput(targetID, isComment, usersPerType)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Reactions on a post's comments also carry the postId
+	match := bson.M{"postId": targetID, "commentId": bson.M{"$exists": false}, "deletedAt": bson.M{"$exists": false}}
+	if isComment {
+		match = bson.M{"commentId": targetID, "deletedAt": bson.M{"$exists": false}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$type",
+			"count":   bson.M{"$sum": 1},
+			"userIds": bson.M{"$firstN": bson.M{"input": "$userId", "n": usersPerType}},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "users",
+			"let":  bson.M{"ids": "$userIds"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$in": bson.A{"$_id", "$$ids"}}}}},
+				{{Key: "$project", Value: bson.M{
+					"username":     1,
+					"displayName":  1,
+					"photoProfile": 1,
+					"firstName":    1,
+					"lastName":     1,
+				}}},
+			},
+			"as": "users",
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.db.Collection("reactions").Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	summaries := make([]domain.ReactionTypeSummary, 0)
+	if err = cursor.All(ctx, &summaries); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(summaries, nil)
+	return summaries, nil
+}
+
+func (r *reactionRepository) AnalyzePost(postID primitive.ObjectID, friendIDs []primitive.ObjectID, friendLimit int, since time.Time) (*domain.PostReactionAnalytics, error) {
+	logger := utils.NewLogger("ReactionRepository.AnalyzePost")
+	logger.LogInput(postID, len(friendIDs), friendLimit, since)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if friendIDs == nil {
+		friendIDs = []primitive.ObjectID{}
+	}
+	byFriends := bson.M{"userId": bson.M{"$in": friendIDs}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"postId": postID, "commentId": bson.M{"$exists": false}, "deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$facet", Value: bson.M{
+			"types": mongo.Pipeline{
+				{{Key: "$group", Value: bson.M{"_id": "$type", "count": bson.M{"$sum": 1}}}},
+				{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			},
+			"friends": mongo.Pipeline{
+				{{Key: "$match", Value: byFriends}},
+				{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: -1}}}},
+				{{Key: "$limit", Value: friendLimit}},
+				{{Key: "$lookup", Value: bson.M{
+					"from":         "users",
+					"localField":   "userId",
+					"foreignField": "_id",
+					"as":           "user",
+				}}},
+				{{Key: "$unwind", Value: "$user"}},
+				{{Key: "$project", Value: bson.M{
+					"type":              1,
+					"createdAt":         1,
+					"user._id":          1,
+					"user.username":     1,
+					"user.displayName":  1,
+					"user.photoProfile": 1,
+					"user.firstName":    1,
+					"user.lastName":     1,
+				}}},
+			},
+			"friendCount": mongo.Pipeline{
+				{{Key: "$match", Value: byFriends}},
+				{{Key: "$count", Value: "count"}},
+			},
+			"timeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"createdAt": bson.M{"$gte": since}}}},
+				{{Key: "$group", Value: bson.M{
+					"_id":   bson.M{"$dateTrunc": bson.M{"date": "$createdAt", "unit": "hour"}},
+					"count": bson.M{"$sum": 1},
+				}}},
+				{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			},
+		}}},
+	}
+
+	cursor, err := r.db.Collection("reactions").Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Types       []domain.ReactionTypeCount      `bson:"types"`
+		Friends     []domain.FriendReaction         `bson:"friends"`
+		FriendCount []struct{ Count int64 }         `bson:"friendCount"`
+		Timeline    []domain.ReactionTimelineBucket `bson:"timeline"`
+	}
+	if err = cursor.All(ctx, &facets); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	analytics := &domain.PostReactionAnalytics{
+		PostID:   postID,
+		Types:    make([]domain.ReactionTypeCount, 0),
+		Friends:  make([]domain.FriendReaction, 0),
+		Timeline: make([]domain.ReactionTimelineBucket, 0),
+	}
+	if len(facets) > 0 {
+		facet := facets[0]
+		if facet.Types != nil {
+			analytics.Types = facet.Types
+		}
+		if facet.Friends != nil {
+			analytics.Friends = facet.Friends
+		}
+		if len(facet.FriendCount) > 0 {
+			analytics.FriendCount = facet.FriendCount[0].Count
+		}
+		if facet.Timeline != nil {
+			analytics.Timeline = facet.Timeline
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"types": len(analytics.Types), "friendCount": analytics.FriendCount}, nil)
+	return analytics, nil
+}
+
+func (r *reactionRepository) GetPostAnalytics(postID primitive.ObjectID) (*domain.PostReactionAnalytics, error) {
+	logger := utils.NewLogger("ReactionRepository.GetPostAnalytics")
+	logger.LogInput(postID)
+
+	analyticsJSON, err := r.rdb.Get(context.Background(), postReactionAnalyticsKey(postID)).Result()
+	if err == redis.Nil {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var analytics domain.PostReactionAnalytics
+	if err := json.Unmarshal([]byte(analyticsJSON), &analytics); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(analytics.GeneratedAt, nil)
+	return &analytics, nil
+}
+
+func (r *reactionRepository) SetPostAnalytics(analytics *domain.PostReactionAnalytics, ttl time.Duration) error {
+	logger := utils.NewLogger("ReactionRepository.SetPostAnalytics")
+	logger.LogInput(analytics.PostID, ttl)
+
+	analyticsBytes, err := json.Marshal(analytics)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := r.rdb.Set(context.Background(), postReactionAnalyticsKey(analytics.PostID), string(analyticsBytes), ttl).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reportRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportRepository(db *mongo.Database) domain.ReportRepository {
+	return &reportRepository{
+		collection: db.Collection("reports"),
+	}
+}
+
+// EnsureIndexes creates the index keeping a reporter to one open report per target,
+// plus the indexes of the moderation listings
+func (r *reportRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("ReportRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "reporterId", Value: 1}, {Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}},
+			Options: options.Index().
+				SetName("open_report_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": domain.ReportStatusOpen}),
+		},
+		{
+			Keys:    bson.D{{Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("target_status"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("status_created"),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Report indexes ready", nil)
+	return nil
+}
+
+func (r *reportRepository) Create(report *domain.Report) error {
+	logger := utils.NewLogger("ReportRepository.Create")
+	logger.LogInput(report)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	report.ID = primitive.NewObjectID()
+	report.CreatedAt = now
+	report.UpdatedAt = now
+	report.IsActive = true
+	report.Version = 1
+
+	_, err := r.collection.InsertOne(ctx, report)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = domain.ErrDuplicate
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return nil
+}
+
+func (r *reportRepository) FindByID(id primitive.ObjectID) (*domain.Report, error) {
+	logger := utils.NewLogger("ReportRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var report domain.Report
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&report)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("report", id.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return &report, nil
+}
+
+func (r *reportRepository) FindAll(filter domain.ReportFilter, limit, offset int) ([]domain.Report, error) {
+	logger := utils.NewLogger("ReportRepository.FindAll")
+	logger.LogInput(map[string]interface{}{
+		"filter": filter,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, reportFilter(filter, true), opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports := make([]domain.Report, 0)
+	if err = cursor.All(ctx, &reports); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(reports)}, nil)
+	return reports, nil
+}
+
+func (r *reportRepository) ResolveOpen(targetType, targetID string, status string, resolverID primitive.ObjectID, note string) (int64, error) {
+	logger := utils.NewLogger("ReportRepository.ResolveOpen")
+	logger.LogInput(map[string]interface{}{
+		"targetType": targetType,
+		"targetID":   targetID,
+		"status":     status,
+		"resolverID": resolverID,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"targetType": targetType,
+		"targetId":   targetID,
+		"status":     domain.ReportStatusOpen,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"resolvedBy": resolverID,
+			"resolvedAt": now,
+			"resolution": note,
+			"updatedAt":  now,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(result.ModifiedCount, nil)
+	return result.ModifiedCount, nil
+}
+
+func (r *reportRepository) CountByTarget(filter domain.ReportFilter, limit, offset int) ([]domain.ReportCount, error) {
+	logger := utils.NewLogger("ReportRepository.CountByTarget")
+	logger.LogInput(map[string]interface{}{
+		"filter": filter,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: reportFilter(filter, false)}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"targetType": "$targetType", "targetId": "$targetId"},
+			"open": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$status", domain.ReportStatusOpen}}, 1, 0},
+			}},
+			"total":          bson.M{"$sum": 1},
+			"reasons":        bson.M{"$addToSet": "$reason"},
+			"statuses":       bson.M{"$addToSet": "$status"},
+			"lastReportedAt": bson.M{"$max": "$createdAt"},
+		}}},
+	}
+	if filter.Status != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"statuses": filter.Status}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "open", Value: -1}, {Key: "lastReportedAt", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			"targetType":     "$_id.targetType",
+			"targetId":       "$_id.targetId",
+			"open":           1,
+			"total":          1,
+			"reasons":        1,
+			"lastReportedAt": 1,
+		}}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	counts := make([]domain.ReportCount, 0)
+	if err = cursor.All(ctx, &counts); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(counts)}, nil)
+	return counts, nil
+}
+
+// reportFilter builds the query of a report filter, leaving the status out when
+// it's applied per target instead
+func reportFilter(filter domain.ReportFilter, withStatus bool) bson.M {
+	query := bson.M{}
+	if withStatus && filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.TargetType != "" {
+		query["targetType"] = filter.TargetType
+	}
+	if filter.TargetID != "" {
+		query["targetId"] = filter.TargetID
+	}
+	return query
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reservedUsernameRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReservedUsernameRepository(db *mongo.Database) domain.ReservedUsernameRepository {
+	return &reservedUsernameRepository{
+		collection: db.Collection("reserved_usernames"),
+	}
+}
+
+func (r *reservedUsernameRepository) Create(reserved *domain.ReservedUsername) error {
+	logger := utils.NewLogger("ReservedUsernameRepository.Create")
+	logger.LogInput(reserved)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Usernames are matched case-insensitively
+	reserved.Username = strings.ToLower(reserved.Username)
+
+	existing, err := r.FindByUsername(reserved.Username)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if existing != nil {
+		logger.LogOutput(nil, domain.ErrDuplicate)
+		return domain.ErrDuplicate
+	}
+
+	now := time.Now()
+	reserved.ID = primitive.NewObjectID()
+	reserved.CreatedAt = now
+	reserved.UpdatedAt = now
+	reserved.IsActive = true
+	reserved.Version = 1
+
+	_, err = r.collection.InsertOne(ctx, reserved)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(reserved, nil)
+	return nil
+}
+
+func (r *reservedUsernameRepository) Update(reserved *domain.ReservedUsername) error {
+	logger := utils.NewLogger("ReservedUsernameRepository.Update")
+	logger.LogInput(reserved)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reserved.UpdatedAt = time.Now()
+	reserved.Version++
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": reserved.ID}, bson.M{"$set": reserved})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		err = domain.ErrNotFound
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(reserved, nil)
+	return nil
+}
+
+func (r *reservedUsernameRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("ReservedUsernameRepository.Delete")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		err = domain.ErrNotFound
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"deleted": true}, nil)
+	return nil
+}
+
+func (r *reservedUsernameRepository) FindByID(id primitive.ObjectID) (*domain.ReservedUsername, error) {
+	logger := utils.NewLogger("ReservedUsernameRepository.FindByID")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reserved domain.ReservedUsername
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&reserved)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.ErrNotFound
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&reserved, nil)
+	return &reserved, nil
+}
+
+// FindByUsername returns nil without error when the username is not reserved
+func (r *reservedUsernameRepository) FindByUsername(username string) (*domain.ReservedUsername, error) {
+	logger := utils.NewLogger("ReservedUsernameRepository.FindByUsername")
+	logger.LogInput(username)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reserved domain.ReservedUsername
+	err := r.collection.FindOne(ctx, bson.M{"username": strings.ToLower(username)}).Decode(&reserved)
+	if err == mongo.ErrNoDocuments {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&reserved, nil)
+	return &reserved, nil
+}
+
+func (r *reservedUsernameRepository) List(category string, limit, offset int) ([]domain.ReservedUsername, error) {
+	logger := utils.NewLogger("ReservedUsernameRepository.List")
+	logger.LogInput(map[string]interface{}{
+		"category": category,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if category != "" {
+		filter["category"] = category
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "username", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reserved := make([]domain.ReservedUsername, 0)
+	if err = cursor.All(ctx, &reserved); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(reserved)}, nil)
+	return reserved, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Popular searches are kept twice: scored by how many users searched them, and
+// all at score 0 so prefixes can be matched with ZRANGEBYLEX
+const (
+	searchPopularKey = "search_popular"
+	searchTermsKey   = "search_terms"
+	// how many prefix matches are ranked per suggestion request
+	searchSuggestionCandidates = 200
+)
+
+type searchHistoryRepository struct {
+	rdb *redis.Client
+}
+
+func NewSearchHistoryRepository(rdb *redis.Client) domain.SearchHistoryRepository {
+	return &searchHistoryRepository{
+		rdb: rdb,
+	}
+}
+
+func searchHistoryKey(userID string) string {
+	return fmt.Sprintf("search_history:%s", userID)
+}
+
+func (r *searchHistoryRepository) AddHistory(userID, query string, at time.Time) (bool, error) {
+	logger := utils.NewLogger("SearchHistoryRepository.AddHistory")
+	logger.LogInput(userID, query)
+
+	ctx := context.Background()
+	key := searchHistoryKey(userID)
+	var added *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		added = pipe.ZAdd(ctx, key, redis.Z{Score: float64(at.UnixMilli()), Member: query})
+		pipe.ZRemRangeByRank(ctx, key, 0, -domain.MaxSearchHistory-1)
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	isNew := added.Val() == 1
+	logger.LogOutput(isNew, nil)
+	return isNew, nil
+}
+
+func (r *searchHistoryRepository) GetHistory(userID string, limit int) ([]domain.SearchHistoryEntry, error) {
+	logger := utils.NewLogger("SearchHistoryRepository.GetHistory")
+	logger.LogInput(userID, limit)
+
+	members, err := r.rdb.ZRevRangeWithScores(context.Background(), searchHistoryKey(userID), 0, int64(limit)-1).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	entries := make([]domain.SearchHistoryEntry, 0, len(members))
+	for _, member := range members {
+		entries = append(entries, domain.SearchHistoryEntry{
+			Query:      member.Member,
+			SearchedAt: time.UnixMilli(int64(member.Score)),
+		})
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(entries)}, nil)
+	return entries, nil
+}
+
+func (r *searchHistoryRepository) DeleteHistory(userID, query string) (bool, error) {
+	logger := utils.NewLogger("SearchHistoryRepository.DeleteHistory")
+	logger.LogInput(userID, query)
+
+	removed, err := r.rdb.ZRem(context.Background(), searchHistoryKey(userID), query).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(removed, nil)
+	return removed > 0, nil
+}
+
+func (r *searchHistoryRepository) ClearHistory(userID string) error {
+	logger := utils.NewLogger("SearchHistoryRepository.ClearHistory")
+	logger.LogInput(userID)
+
+	if err := r.rdb.Del(context.Background(), searchHistoryKey(userID)).Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *searchHistoryRepository) IncrementPopular(query string) error {
+	logger := utils.NewLogger("SearchHistoryRepository.IncrementPopular")
+	logger.LogInput(query)
+
+	ctx := context.Background()
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZIncrBy(ctx, searchPopularKey, 1, query)
+		pipe.ZAdd(ctx, searchTermsKey, redis.Z{Score: 0, Member: query})
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// SuggestPopular ranks the first searchSuggestionCandidates queries matching the
+// prefix in byte order, so very short prefixes may miss popular queries further on
+func (r *searchHistoryRepository) SuggestPopular(prefix string, minCount int64, limit int) ([]string, error) {
+	logger := utils.NewLogger("SearchHistoryRepository.SuggestPopular")
+	logger.LogInput(prefix, minCount, limit)
+
+	ctx := context.Background()
+	candidates, err := r.rdb.ZRangeByLex(ctx, searchTermsKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: searchSuggestionCandidates,
+	}).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		logger.LogOutput(candidates, nil)
+		return candidates, nil
+	}
+
+	counts, err := r.rdb.ZMScore(ctx, searchPopularKey, candidates...).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	type scored struct {
+		query string
+		count float64
+	}
+	matches := make([]scored, 0, len(candidates))
+	for i, query := range candidates {
+		if int64(counts[i]) >= minCount {
+			matches = append(matches, scored{query: query, count: counts[i]})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].count > matches[j].count
+	})
+
+	queries := make([]string, 0, limit)
+	for _, match := range matches {
+		if len(queries) == limit {
+			break
+		}
+		queries = append(queries, match.query)
+	}
+
+	logger.LogOutput(queries, nil)
+	return queries, nil
+}
+
+func (r *searchHistoryRepository) TrimPopular(max int64) (int64, error) {
+	logger := utils.NewLogger("SearchHistoryRepository.TrimPopular")
+	logger.LogInput(max)
+
+	ctx := context.Background()
+	total, err := r.rdb.ZCard(ctx, searchPopularKey).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+	if total <= max {
+		logger.LogOutput(0, nil)
+		return 0, nil
+	}
+
+	// Least searched first
+	queries, err := r.rdb.ZRange(ctx, searchPopularKey, 0, total-max-1).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+	members := make([]interface{}, len(queries))
+	for i, query := range queries {
+		members[i] = query
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, searchPopularKey, members...)
+		pipe.ZRem(ctx, searchTermsKey, members...)
+		return nil
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(len(members), nil)
+	return int64(len(members)), nil
+}
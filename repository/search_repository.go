@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type searchRepository struct {
+	posts *mongo.Collection
+	users *mongo.Collection
+}
+
+func NewSearchRepository(db *mongo.Database) domain.SearchRepository {
+	return &searchRepository{
+		posts: db.Collection("posts"),
+		users: db.Collection("users"),
+	}
+}
+
+// EnsureIndexes creates the text indexes search depends on. MongoDB allows one text index per collection.
+// The language is "none" so Thai and English content is tokenized the same way, without stemming.
+func (r *searchRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("SearchRepository.EnsureIndexes")
+
+	_, err := r.posts.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "content", Value: "text"}, {Key: "tags", Value: "text"}},
+		Options: options.Index().
+			SetName("post_search").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "tags", Value: 5}, {Key: "content", Value: 1}}),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	_, err = r.users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "username", Value: "text"},
+			{Key: "displayName", Value: "text"},
+			{Key: "firstName", Value: "text"},
+			{Key: "lastName", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("user_search").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "username", Value: 10}, {Key: "displayName", Value: 5}}),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Search indexes ready", nil)
+	return nil
+}
+
+// postSearchFilter only matches public posts so search never exposes friends-only or private content
+func postSearchFilter(query string) bson.M {
+	return bson.M{
+		"$text":      bson.M{"$search": query},
+		"isActive":   true,
+		"deletedAt":  bson.M{"$exists": false},
+		"visibility": bson.M{"$in": []interface{}{domain.VisibilityPublic, "", nil}},
+	}
+}
+
+func userSearchFilter(query string) bson.M {
+	return bson.M{
+		"$text":     bson.M{"$search": query},
+		"deletedAt": bson.M{"$exists": false},
+	}
+}
+
+func searchFindOptions(limit int) *options.FindOptions {
+	score := bson.M{"$meta": "textScore"}
+	return options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+}
+
+func (r *searchRepository) SearchPosts(query string, limit int) ([]domain.PostSearchHit, error) {
+	logger := utils.NewLogger("SearchRepository.SearchPosts")
+	logger.LogInput(query, limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.posts.Find(ctx, postSearchFilter(query), searchFindOptions(limit))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		domain.Post `bson:",inline"`
+		Score       float64 `bson:"score"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	hits := make([]domain.PostSearchHit, 0, len(docs))
+	for _, doc := range docs {
+		hits = append(hits, domain.PostSearchHit{Post: doc.Post, Score: doc.Score})
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(hits)}, nil)
+	return hits, nil
+}
+
+func (r *searchRepository) SearchUsers(query string, limit int) ([]domain.UserSearchHit, error) {
+	logger := utils.NewLogger("SearchRepository.SearchUsers")
+	logger.LogInput(query, limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.users.Find(ctx, userSearchFilter(query), searchFindOptions(limit))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		domain.User `bson:",inline"`
+		Score       float64 `bson:"score"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	hits := make([]domain.UserSearchHit, 0, len(docs))
+	for _, doc := range docs {
+		hits = append(hits, domain.UserSearchHit{User: doc.User, Score: doc.Score})
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(hits)}, nil)
+	return hits, nil
+}
+
+func (r *searchRepository) FindUsersByContact(email, phone string, limit int) ([]domain.User, error) {
+	logger := utils.NewLogger("SearchRepository.FindUsersByContact")
+	logger.LogInput(email, phone, limit)
+
+	users := make([]domain.User, 0)
+	matches := make([]bson.M, 0, 2)
+	if email != "" {
+		matches = append(matches, bson.M{"email": email, "privacy.searchableByEmail": true})
+	}
+	if phone != "" {
+		matches = append(matches, bson.M{"phoneNumber": phone, "privacy.searchableByPhone": true})
+	}
+	if len(matches) == 0 {
+		logger.LogOutput(users, nil)
+		return users, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"$or":       matches,
+		"deletedAt": bson.M{"$exists": false},
+	}
+	cursor, err := r.users.Find(ctx, filter, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *searchRepository) CountPosts(query string) (int64, error) {
+	logger := utils.NewLogger("SearchRepository.CountPosts")
+	logger.LogInput(query)
+
+	count, err := countApprox(context.Background(), r.posts, postSearchFilter(query))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *searchRepository) CountUsers(query string) (int64, error) {
+	logger := utils.NewLogger("SearchRepository.CountUsers")
+	logger.LogInput(query)
+
+	count, err := countApprox(context.Background(), r.users, userSearchFilter(query))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
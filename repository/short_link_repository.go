@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// shortLinkCacheTTL bounds how long a link is served from Redis, links expiring
+// sooner are cached until they expire
+const shortLinkCacheTTL = time.Hour
+
+type shortLinkRepository struct {
+	collection *mongo.Collection
+	rdb        *redis.Client
+}
+
+func NewShortLinkRepository(db *mongo.Database, rdb *redis.Client) domain.ShortLinkRepository {
+	return &shortLinkRepository{
+		collection: db.Collection("short_links"),
+		rdb:        rdb,
+	}
+}
+
+func shortLinkKey(code string) string {
+	return fmt.Sprintf("short_link:%s", code)
+}
+
+func (r *shortLinkRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("ShortLinkRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetName("code_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "createdBy", Value: 1}, {Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("creator_target"),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Short link indexes ready", nil)
+	return nil
+}
+
+func (r *shortLinkRepository) Create(link *domain.ShortLink) error {
+	logger := utils.NewLogger("ShortLinkRepository.Create")
+	logger.LogInput(link)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	link.ID = primitive.NewObjectID()
+	link.CreatedAt = now
+	link.UpdatedAt = now
+	link.IsActive = true
+	link.Version = 1
+
+	_, err := r.collection.InsertOne(ctx, link)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = domain.ErrDuplicate
+		}
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(link.ID, nil)
+	return nil
+}
+
+func (r *shortLinkRepository) FindByCode(code string) (*domain.ShortLink, error) {
+	logger := utils.NewLogger("ShortLinkRepository.FindByCode")
+	logger.LogInput(code)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := shortLinkKey(code)
+	cached, err := r.rdb.Get(ctx, key).Result()
+	if err == nil {
+		var link domain.ShortLink
+		if err := json.Unmarshal([]byte(cached), &link); err == nil {
+			logger.LogOutput(link.ID, nil)
+			return &link, nil
+		}
+	} else if err != redis.Nil {
+		// Redis errors fall back to Mongo
+		logger.LogOutput(nil, err)
+	}
+
+	var link domain.ShortLink
+	err = r.collection.FindOne(ctx, bson.M{"code": code}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("short link", code)
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ttl := shortLinkCacheTTL
+	if link.ExpiresAt != nil {
+		if untilExpiry := time.Until(*link.ExpiresAt); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	if ttl > 0 {
+		if data, err := json.Marshal(&link); err == nil {
+			if err := r.rdb.Set(ctx, key, data, ttl).Err(); err != nil {
+				logger.LogOutput(nil, err)
+			}
+		}
+	}
+
+	logger.LogOutput(link.ID, nil)
+	return &link, nil
+}
+
+func (r *shortLinkRepository) FindReusable(createdBy primitive.ObjectID, targetType string, targetID primitive.ObjectID) (*domain.ShortLink, error) {
+	logger := utils.NewLogger("ShortLinkRepository.FindReusable")
+	logger.LogInput(createdBy, targetType, targetID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"createdBy":  createdBy,
+		"targetType": targetType,
+		"targetId":   targetID,
+		"$or": []bson.M{
+			{"expiresAt": bson.M{"$exists": false}},
+			{"expiresAt": bson.M{"$gt": time.Now()}},
+		},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	var link domain.ShortLink
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.LogOutput(nil, nil)
+			return nil, nil
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(link.ID, nil)
+	return &link, nil
+}
+
+func (r *shortLinkRepository) IncrementClicks(code string) error {
+	logger := utils.NewLogger("ShortLinkRepository.IncrementClicks")
+	logger.LogInput(code)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$inc": bson.M{"clicks": 1}}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"code": code}, update); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
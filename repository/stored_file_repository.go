@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type storedFileRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStoredFileRepository(db *mongo.Database) domain.StoredFileRepository {
+	return &storedFileRepository{
+		collection: db.Collection("files"),
+	}
+}
+
+// EnsureIndexes creates the indexes serving URL lookups and usage sums
+func (r *storedFileRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("StoredFileRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "fileUrl", Value: 1}},
+			Options: options.Index().SetName("user_file_url"),
+		},
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "releasedAt", Value: 1}},
+			Options: options.Index().SetName("user_usage"),
+		},
+		{
+			Keys:    bson.D{{Key: "fileUrl", Value: 1}},
+			Options: options.Index().SetName("file_url"),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("File indexes ready", nil)
+	return nil
+}
+
+func (r *storedFileRepository) Create(file *domain.StoredFile) error {
+	logger := utils.NewLogger("StoredFileRepository.Create")
+	logger.LogInput(file)
+
+	if file.ID.IsZero() {
+		file.ID = primitive.NewObjectID()
+	}
+	if file.CreatedAt.IsZero() {
+		file.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(context.Background(), file)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(file.ID, nil)
+	return nil
+}
+
+func (r *storedFileRepository) FindByURL(userID primitive.ObjectID, url string) (*domain.StoredFile, error) {
+	logger := utils.NewLogger("StoredFileRepository.FindByURL")
+	logger.LogInput(userID, url)
+
+	var file domain.StoredFile
+	err := r.collection.FindOne(context.Background(), bson.M{"userId": userID, "fileUrl": url}).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("file", url)
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&file, nil)
+	return &file, nil
+}
+
+func (r *storedFileRepository) Delete(id primitive.ObjectID) error {
+	logger := utils.NewLogger("StoredFileRepository.Delete")
+	logger.LogInput(id)
+
+	_, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *storedFileRepository) SetReleased(userID primitive.ObjectID, urls []string, released bool) error {
+	logger := utils.NewLogger("StoredFileRepository.SetReleased")
+	logger.LogInput(map[string]interface{}{
+		"userID":   userID,
+		"urls":     urls,
+		"released": released,
+	})
+
+	if len(urls) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"userId": userID, "fileUrl": bson.M{"$in": urls}}
+	update := bson.M{"$unset": bson.M{"releasedAt": ""}}
+	if released {
+		filter["releasedAt"] = bson.M{"$exists": false}
+		update = bson.M{"$set": bson.M{"releasedAt": time.Now()}}
+	}
+
+	result, err := r.collection.UpdateMany(context.Background(), filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(result.ModifiedCount, nil)
+	return nil
+}
+
+func (r *storedFileRepository) GetUsage(userID primitive.ObjectID) (int64, int64, error) {
+	logger := utils.NewLogger("StoredFileRepository.GetUsage")
+	logger.LogInput(userID)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": userID, "releasedAt": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"bytes": bson.M{"$sum": "$size"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	var result struct {
+		Bytes int64 `bson:"bytes"`
+		Count int64 `bson:"count"`
+	}
+	if cursor.Next(context.Background()) {
+		if err := cursor.Decode(&result); err != nil {
+			logger.LogOutput(nil, err)
+			return 0, 0, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		logger.LogOutput(nil, err)
+		return 0, 0, err
+	}
+
+	logger.LogOutput(result, nil)
+	return result.Bytes, result.Count, nil
+}
+
+func (r *storedFileRepository) FindAllByURL(url string) ([]domain.StoredFile, error) {
+	logger := utils.NewLogger("StoredFileRepository.FindAllByURL")
+	logger.LogInput(url)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"fileUrl": url})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	files := make([]domain.StoredFile, 0)
+	if err := cursor.All(ctx, &files); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(files)}, nil)
+	return files, nil
+}
+
+func (r *storedFileRepository) ShareWithRoom(userID primitive.ObjectID, url, roomID string) error {
+	logger := utils.NewLogger("StoredFileRepository.ShareWithRoom")
+	logger.LogInput(userID, url, roomID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Public files have no ACL to add the room to
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"userId": userID, "fileUrl": url, "acl": bson.M{"$exists": true}},
+		bson.M{"$addToSet": bson.M{"acl.roomIds": roomID}},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *storedFileRepository) SetSharedUsers(userID primitive.ObjectID, url string, userIDs []primitive.ObjectID) error {
+	logger := utils.NewLogger("StoredFileRepository.SetSharedUsers")
+	logger.LogInput(userID, url, userIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"userId": userID, "fileUrl": url, "acl": bson.M{"$exists": true}},
+		bson.M{"$set": bson.M{"acl.userIds": userIDs}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		err = domain.NewNotFoundError("private file", url)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
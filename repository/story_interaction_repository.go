@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type storyInteractionRepository struct {
+	collection *mongo.Collection
+	rdb        *redis.Client
+}
+
+func NewStoryInteractionRepository(db *mongo.Database, rdb *redis.Client) domain.StoryInteractionRepository {
+	return &storyInteractionRepository{
+		collection: db.Collection("story_interaction_stats"),
+		rdb:        rdb,
+	}
+}
+
+func (r *storyInteractionRepository) MarkInteracted(storyID, viewerID, interactionType string) (bool, error) {
+	logger := utils.NewLogger("StoryInteractionRepository.MarkInteracted")
+	logger.LogInput(storyID, viewerID, interactionType)
+
+	key := fmt.Sprintf("story_interaction_seen:%s:%s:%s", storyID, interactionType, viewerID)
+	first, err := r.rdb.SetNX(context.Background(), key, 1, domain.StoryInteractionDedupeWindow).Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(first, nil)
+	return first, nil
+}
+
+// Record adds up the records per story first, so each story is written once
+func (r *storyInteractionRepository) Record(records []domain.StoryInteractionRecord) error {
+	logger := utils.NewLogger("StoryInteractionRepository.Record")
+	logger.LogInput(map[string]interface{}{"count": len(records)})
+
+	if len(records) == 0 {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	incs := make(map[string]bson.M)
+	owners := make(map[string]string)
+	order := make([]string, 0)
+	for _, record := range records {
+		inc, ok := incs[record.StoryID]
+		if !ok {
+			inc = bson.M{}
+			incs[record.StoryID] = inc
+			owners[record.StoryID] = record.OwnerID
+			order = append(order, record.StoryID)
+		}
+		incField(inc, "counts."+record.Type)
+		if !record.Unique {
+			continue
+		}
+		incField(inc, "viewers."+record.Type)
+		if record.Type == domain.StoryInteractionTapForward || record.Type == domain.StoryInteractionExit {
+			incField(inc, "dropOffs."+strconv.Itoa(record.Second))
+		}
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(order))
+	for _, storyID := range order {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": storyID}).
+			SetUpdate(bson.M{
+				"$inc":         incs[storyID],
+				"$set":         bson.M{"updatedAt": now},
+				"$setOnInsert": bson.M{"ownerId": owners[storyID]},
+			}).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"stories": len(order)}, nil)
+	return nil
+}
+
+func (r *storyInteractionRepository) FindStats(storyID string) (*domain.StoryInteractionStats, error) {
+	logger := utils.NewLogger("StoryInteractionRepository.FindStats")
+	logger.LogInput(storyID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var stats domain.StoryInteractionStats
+	err := r.collection.FindOne(ctx, bson.M{"_id": storyID}).Decode(&stats)
+	if err == mongo.ErrNoDocuments {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(stats.Counts, nil)
+	return &stats, nil
+}
+
+func incField(inc bson.M, field string) {
+	count, _ := inc[field].(int64)
+	inc[field] = count + 1
+}
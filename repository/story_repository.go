@@ -12,6 +12,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type storyRepository struct {
@@ -142,6 +143,36 @@ func (r *storyRepository) FindByID(id string) (*domain.Story, error) {
 	return &story, nil
 }
 
+func (r *storyRepository) FindAnyByID(id string) (*domain.Story, error) {
+	logger := utils.NewLogger("StoryRepository.FindAnyByID")
+	logger.LogInput(id)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.LogOutput(nil, domain.ErrInvalidID)
+		return nil, domain.ErrInvalidID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var story domain.Story
+	err = r.collection.FindOne(ctx, bson.M{
+		"_id":       objectID,
+		"deletedAt": bson.M{"$exists": false},
+	}).Decode(&story)
+	if err == mongo.ErrNoDocuments {
+		err = domain.NewNotFoundError("story", id)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(story.ID, nil)
+	return &story, nil
+}
+
 func (r *storyRepository) FindByUserID(userID string) ([]*domain.Story, error) {
 	logger := utils.NewLogger("StoryRepository.FindByUserID")
 	logger.LogInput(userID)
@@ -391,6 +422,98 @@ func (r *storyRepository) AddViewer(storyID string, viewer domain.StoryViewer) e
 	return nil
 }
 
+func (r *storyRepository) SetReaction(storyID string, reaction domain.StoryReaction) (bool, error) {
+	logger := utils.NewLogger("StoryRepository.SetReaction")
+	logger.LogInput(map[string]interface{}{"storyID": storyID, "reaction": reaction})
+
+	objectID, err := primitive.ObjectIDFromHex(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Change the emoji of an existing reaction first, then add a new one.
+	// The filters keep a viewer to one reaction even when requests race.
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "isActive": true, "reactions.userId": reaction.UserID},
+		bson.M{"$set": bson.M{
+			"reactions.$.emoji":     reaction.Emoji,
+			"reactions.$.reactedAt": reaction.ReactedAt,
+			"updatedAt":             time.Now(),
+		}},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	added := false
+	if result.MatchedCount == 0 {
+		result, err = r.collection.UpdateOne(ctx,
+			bson.M{"_id": objectID, "isActive": true, "reactions.userId": bson.M{"$ne": reaction.UserID}},
+			bson.M{
+				"$push": bson.M{"reactions": reaction},
+				"$inc":  bson.M{"reactionsCount": 1},
+				"$set":  bson.M{"updatedAt": time.Now()},
+			},
+		)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return false, err
+		}
+		if result.MatchedCount == 0 {
+			err = mongo.ErrNoDocuments
+			logger.LogOutput(nil, err)
+			return false, err
+		}
+		added = true
+	}
+
+	if err := r.rdb.Del(ctx, fmt.Sprintf("story:%s", storyID)).Err(); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	logger.LogOutput(added, nil)
+	return added, nil
+}
+
+func (r *storyRepository) RemoveReaction(storyID, userID string) (bool, error) {
+	logger := utils.NewLogger("StoryRepository.RemoveReaction")
+	logger.LogInput(map[string]interface{}{"storyID": storyID, "userID": userID})
+
+	objectID, err := primitive.ObjectIDFromHex(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "isActive": true, "reactions.userId": userID},
+		bson.M{
+			"$pull": bson.M{"reactions": bson.M{"userId": userID}},
+			"$inc":  bson.M{"reactionsCount": -1},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	if err := r.rdb.Del(ctx, fmt.Sprintf("story:%s", storyID)).Err(); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	removed := result.ModifiedCount > 0
+	logger.LogOutput(removed, nil)
+	return removed, nil
+}
+
 func (r *storyRepository) DeleteStory(id string) error {
 	logger := utils.NewLogger("StoryRepository.DeleteStory")
 	logger.LogInput(id)
@@ -460,6 +583,7 @@ func (r *storyRepository) DeleteStory(id string) error {
 func (r *storyRepository) ArchiveExpiredStories() error {
 	logger := utils.NewLogger("StoryRepository.ArchiveExpiredStories")
 
+	ctx := context.Background()
 	now := time.Now()
 	filter := bson.M{
 		"isActive":  true,
@@ -467,6 +591,32 @@ func (r *storyRepository) ArchiveExpiredStories() error {
 		"expiresAt": bson.M{"$lte": now},
 	}
 
+	// Looked up first so the caches of the archived stories can be dropped too
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "userId": 1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	var expired []struct {
+		ID     primitive.ObjectID `bson:"_id"`
+		UserID string             `bson:"userId"`
+	}
+	if err := cursor.All(ctx, &expired); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(expired) == 0 {
+		logger.LogOutput(map[string]interface{}{"archivedCount": 0}, nil)
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(expired))
+	for _, story := range expired {
+		ids = append(ids, story.ID)
+	}
+	filter["_id"] = bson.M{"$in": ids}
+
 	update := bson.M{
 		"$set": bson.M{
 			"isArchive": true,
@@ -474,19 +624,22 @@ func (r *storyRepository) ArchiveExpiredStories() error {
 		},
 	}
 
-	result, err := r.collection.UpdateMany(context.Background(), filter, update)
+	result, err := r.collection.UpdateMany(ctx, filter, update)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
 
-	// If any stories were archived, invalidate active stories cache
-	if result.ModifiedCount > 0 {
-		err = r.rdb.Del(context.Background(), "active_stories").Err()
-		if err != nil {
-			logger.LogOutput(nil, err)
-			return err
-		}
+	pipe := r.rdb.Pipeline()
+	pipe.Del(ctx, "active_stories")
+	for _, story := range expired {
+		pipe.Del(ctx, fmt.Sprintf("story:%s", story.ID.Hex()))
+		pipe.Del(ctx, fmt.Sprintf("user_stories:%s", story.UserID))
+		pipe.Del(ctx, storyWatchersKey(story.ID.Hex()))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return err
 	}
 
 	logger.LogOutput(map[string]interface{}{
@@ -494,3 +647,161 @@ func (r *storyRepository) ArchiveExpiredStories() error {
 	}, nil)
 	return nil
 }
+
+func (r *storyRepository) FindDeleted(since time.Time, limit, offset int) ([]*domain.Story, error) {
+	logger := utils.NewLogger("StoryRepository.FindDeleted")
+	logger.LogInput(map[string]interface{}{
+		"since":  since,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	filter := bson.M{"deletedAt": bson.M{"$gte": since}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deletedAt", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var stories []*domain.Story
+	if err = cursor.All(context.Background(), &stories); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(stories)}, nil)
+	return stories, nil
+}
+
+func (r *storyRepository) FindDeletedByID(id string) (*domain.Story, error) {
+	logger := utils.NewLogger("StoryRepository.FindDeletedByID")
+	logger.LogInput(id)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, domain.ErrInvalidID
+	}
+
+	var story domain.Story
+	err = r.collection.FindOne(context.Background(), bson.M{
+		"_id":       objectID,
+		"deletedAt": bson.M{"$exists": true},
+	}).Decode(&story)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("story", id)
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&story, nil)
+	return &story, nil
+}
+
+func (r *storyRepository) Restore(id string) error {
+	logger := utils.NewLogger("StoryRepository.Restore")
+	logger.LogInput(id)
+
+	story, err := r.FindDeletedByID(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isActive":  true,
+			"updatedAt": time.Now(),
+		},
+		"$unset": bson.M{"deletedAt": ""},
+	}
+
+	_, err = r.collection.UpdateOne(context.Background(), bson.M{"_id": story.ID}, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Invalidate all related caches
+	pipe := r.rdb.Pipeline()
+	pipe.Del(context.Background(), fmt.Sprintf("story:%s", id))
+	pipe.Del(context.Background(), fmt.Sprintf("user_stories:%s", story.UserID))
+	pipe.Del(context.Background(), "active_stories")
+
+	_, err = pipe.Exec(context.Background())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func storyWatchersKey(storyID string) string {
+	return fmt.Sprintf("story_watchers:%s", storyID)
+}
+
+// AddWatcher records a viewer heartbeat and returns how many viewers were seen within the window
+func (r *storyRepository) AddWatcher(storyID, viewerID string, window time.Duration) (int64, error) {
+	logger := utils.NewLogger("StoryRepository.AddWatcher")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+	})
+
+	ctx := context.Background()
+	key := storyWatchersKey(storyID)
+	now := time.Now()
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: viewerID})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("%d", now.Add(-window).UnixMilli()))
+	count := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count.Val(), nil)
+	return count.Val(), nil
+}
+
+// RemoveWatcher drops the viewer and returns the remaining watcher count
+func (r *storyRepository) RemoveWatcher(storyID, viewerID string, window time.Duration) (int64, error) {
+	logger := utils.NewLogger("StoryRepository.RemoveWatcher")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+	})
+
+	ctx := context.Background()
+	key := storyWatchersKey(storyID)
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZRem(ctx, key, viewerID)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("%d", time.Now().Add(-window).UnixMilli()))
+	count := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count.Val(), nil)
+	return count.Val(), nil
+}
+
+// TryLockWatcherBroadcast returns true at most once per interval for a story
+func (r *storyRepository) TryLockWatcherBroadcast(storyID string, interval time.Duration) (bool, error) {
+	key := fmt.Sprintf("story_watchers_broadcast:%s", storyID)
+	return r.rdb.SetNX(context.Background(), key, 1, interval).Result()
+}
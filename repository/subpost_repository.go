@@ -168,9 +168,12 @@ func (r *subPostRepository) FindByID(id primitive.ObjectID) (*domain.SubPost, er
 
 	// Not found in Redis, get from MongoDB
 	var subPost domain.SubPost
-	filter := bson.M{"_id": id}
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}}
 	err = r.collection.FindOne(context.Background(), filter).Decode(&subPost)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("subpost", id.Hex())
+		}
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
@@ -222,7 +225,7 @@ func (r *subPostRepository) FindByParentID(parentID primitive.ObjectID, limit, o
 
 	// Not found in Redis, get from MongoDB
 	var subPosts []domain.SubPost
-	filter := bson.M{"parentId": parentID}
+	filter := bson.M{"parentId": parentID, "deletedAt": bson.M{"$exists": false}}
 
 	findOptions := options.Find()
 	if limit > 0 {
@@ -381,3 +384,96 @@ func (r *subPostRepository) DeleteByParentID(parentID primitive.ObjectID) error
 	}, nil)
 	return nil
 }
+
+func (r *subPostRepository) UpdateVisibilityByParentID(parentID primitive.ObjectID, visibility string) error {
+	logger := utils.NewLogger("SubPostRepository.UpdateVisibilityByParentID")
+	logger.LogInput(map[string]interface{}{
+		"parentID":   parentID,
+		"visibility": visibility,
+	})
+
+	filter := bson.M{"parentId": parentID}
+	update := bson.M{"$set": bson.M{"visibility": visibility, "updatedAt": time.Now()}}
+	err := r.updateManyByParentID(parentID, filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("SubPosts visibility updated successfully", nil)
+	return nil
+}
+
+func (r *subPostRepository) SoftDeleteByParentID(parentID primitive.ObjectID) error {
+	logger := utils.NewLogger("SubPostRepository.SoftDeleteByParentID")
+	logger.LogInput(parentID)
+
+	filter := bson.M{"parentId": parentID, "deletedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"deletedAt": time.Now(), "deletedWithParent": true}}
+	err := r.updateManyByParentID(parentID, filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("SubPosts deleted successfully", nil)
+	return nil
+}
+
+func (r *subPostRepository) RestoreByParentID(parentID primitive.ObjectID) error {
+	logger := utils.NewLogger("SubPostRepository.RestoreByParentID")
+	logger.LogInput(parentID)
+
+	filter := bson.M{"parentId": parentID, "deletedWithParent": true}
+	update := bson.M{
+		"$set":   bson.M{"updatedAt": time.Now()},
+		"$unset": bson.M{"deletedAt": "", "deletedWithParent": ""},
+	}
+	err := r.updateManyByParentID(parentID, filter, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("SubPosts restored successfully", nil)
+	return nil
+}
+
+// updateManyByParentID applies update to the parent's subposts matching filter
+// and invalidates the affected caches
+func (r *subPostRepository) updateManyByParentID(parentID primitive.ObjectID, filter, update bson.M) error {
+	ctx := context.Background()
+
+	var subPosts []domain.SubPost
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &subPosts)
+	if err != nil {
+		return err
+	}
+	if len(subPosts) == 0 {
+		return nil
+	}
+
+	_, err = r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(subPosts))
+	for _, subPost := range subPosts {
+		keys = append(keys, fmt.Sprintf("subpost:%s", subPost.ID.Hex()))
+	}
+	pattern := fmt.Sprintf("parent_subposts:%s:*", parentID.Hex())
+	parentKeys, err := r.rdb.Keys(ctx, pattern).Result()
+	if err != nil {
+		return err
+	}
+	keys = append(keys, parentKeys...)
+
+	return r.rdb.Del(ctx, keys...).Err()
+}
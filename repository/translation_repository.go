@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+const googleTranslateURL = "https://translation.googleapis.com/language/translate/v2"
+
+type translationRepository struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewTranslationRepository creates a Google Cloud Translation client.
+// Without an API key every call returns domain.ErrTranslationUnavailable.
+func NewTranslationRepository(apiKey string) domain.TranslationRepository {
+	return &translationRepository{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type googleTranslateResponse struct {
+	Data struct {
+		Translations []struct {
+			TranslatedText         string `json:"translatedText"`
+			DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+		} `json:"translations"`
+	} `json:"data"`
+}
+
+func (r *translationRepository) Translate(text, sourceLanguage, targetLanguage string) (*domain.Translation, error) {
+	logger := utils.NewLogger("TranslationRepository.Translate")
+	logger.LogInput(map[string]interface{}{
+		"sourceLanguage": sourceLanguage,
+		"targetLanguage": targetLanguage,
+	})
+
+	if r.apiKey == "" {
+		logger.LogOutput(nil, domain.ErrTranslationUnavailable)
+		return nil, domain.ErrTranslationUnavailable
+	}
+
+	form := url.Values{
+		"q":      {text},
+		"target": {targetLanguage},
+		"format": {"text"},
+		"key":    {r.apiKey},
+	}
+	// Let the provider detect the language when we could not
+	if sourceLanguage != "" && sourceLanguage != utils.LanguageUnknown {
+		form.Set("source", sourceLanguage)
+	}
+
+	resp, err := r.client.PostForm(googleTranslateURL, form)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", domain.ErrTranslationUnavailable, err)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("%w: provider returned status %d", domain.ErrTranslationUnavailable, resp.StatusCode)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var body googleTranslateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len(body.Data.Translations) == 0 {
+		err = fmt.Errorf("%w: empty response", domain.ErrTranslationUnavailable)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	result := body.Data.Translations[0]
+	if result.DetectedSourceLanguage != "" {
+		sourceLanguage = result.DetectedSourceLanguage
+	}
+
+	translation := &domain.Translation{
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: targetLanguage,
+		Text:           result.TranslatedText,
+	}
+
+	logger.LogOutput(translation, nil)
+	return translation, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
@@ -27,6 +28,27 @@ func NewUserRepository(db *mongo.Database, rdb *redis.Client) domain.UserReposit
 	}
 }
 
+// EnsureIndexes creates the 2dsphere index of user locations. Users who never
+// shared a location keep an empty one, which isn't indexed.
+func (r *userRepository) EnsureIndexes(ctx context.Context) error {
+	logger := utils.NewLogger("UserRepository.EnsureIndexes")
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		geoPointIndex("user_location"),
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetName("user_username"),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("User indexes ready", nil)
+	return nil
+}
+
 func (r *userRepository) Create(user *domain.User) error {
 	logger := utils.NewLogger("UserRepository.Create")
 	logger.LogInput(user)
@@ -338,6 +360,7 @@ func (r *userRepository) Update(user *domain.User) error {
 			"relationStatus": user.RelationStatus,
 			"height":         user.Height,
 			"interests":      user.Interests,
+			"legacyInterests": user.LegacyInterests,
 			"occupation":     user.Occupation,
 			"education":      user.Education,
 			"phoneNumber":    user.PhoneNumber,
@@ -345,6 +368,9 @@ func (r *userRepository) Update(user *domain.User) error {
 			"isVerified":     user.IsVerified,
 			"isActive":       user.IsActive,
 			"live":           user.Live,
+			"hideReadReceipts": user.HideReadReceipts,
+			"lastSeenVisibility": user.LastSeenVisibility,
+			"coarseLastSeen": user.CoarseLastSeen,
 			"updatedAt":      user.UpdatedAt,
 			"version":        user.Version,
 		},
@@ -584,3 +610,610 @@ func (r *userRepository) GetUserList(req *domain.UserListRequest) ([]domain.User
 
 	return users, totalCount, nil
 }
+
+func (r *userRepository) FindDeleted(since time.Time, limit, offset int) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindDeleted")
+	logger.LogInput(map[string]interface{}{
+		"since":  since,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	filter := bson.M{"deletedAt": bson.M{"$gte": since}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deletedAt", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var users []domain.User
+	if err = cursor.All(context.Background(), &users); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *userRepository) FindDeletedByID(id string) (*domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindDeletedByID")
+	logger.LogInput(id)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, domain.ErrInvalidID
+	}
+
+	var user domain.User
+	err = r.collection.FindOne(context.Background(), bson.M{
+		"_id":       objectID,
+		"deletedAt": bson.M{"$exists": true},
+	}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = domain.NewNotFoundError("user", id)
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(&user, nil)
+	return &user, nil
+}
+
+func (r *userRepository) Restore(id string) error {
+	logger := utils.NewLogger("UserRepository.Restore")
+	logger.LogInput(id)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return domain.ErrInvalidID
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isActive":  true,
+			"updatedAt": time.Now(),
+		},
+		// An admin restore also calls off a scheduled deletion
+		"$unset": bson.M{"deletedAt": "", "deletionScheduledAt": ""},
+	}
+
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{
+		"_id":       objectID,
+		"deletedAt": bson.M{"$exists": true},
+	}, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		err = domain.NewNotFoundError("user", id)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Drop cached user lists so the restored user shows up again
+	keys, err := r.rdb.Keys(context.Background(), "user_list:*").Result()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(keys) > 0 {
+		if err = r.rdb.Del(context.Background(), keys...).Err(); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"restored": true}, nil)
+	return nil
+}
+
+func (r *userRepository) FindByIDs(ids []primitive.ObjectID) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindByIDs")
+	logger.LogInput(ids)
+
+	filter := bson.M{
+		"_id":       bson.M{"$in": ids},
+		"deletedAt": bson.M{"$exists": false},
+	}
+
+	cursor, err := r.collection.Find(context.Background(), filter)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	users := make([]domain.User, 0, len(ids))
+	if err = cursor.All(context.Background(), &users); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+// suggestionCandidates returns the most followed active users matching the filter
+func (r *userRepository) suggestionCandidates(filter bson.M, exclude []primitive.ObjectID, limit int) ([]domain.User, error) {
+	filter["_id"] = bson.M{"$nin": exclude}
+	filter["deletedAt"] = bson.M{"$exists": false}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "followersCount", Value: -1}}).
+		SetLimit(int64(limit))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]domain.User, 0, limit)
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) FindPopularNearby(coordinates []float64, radiusMeters float64, exclude []primitive.ObjectID, limit int) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindPopularNearby")
+	logger.LogInput(map[string]interface{}{
+		"coordinates":  coordinates,
+		"radiusMeters": radiusMeters,
+		"limit":        limit,
+	})
+
+	// $centerSphere takes the radius in radians and doesn't need a geo index
+	const earthRadiusMeters = 6378100.0
+	filter := bson.M{
+		"location.coordinates": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{coordinates, radiusMeters / earthRadiusMeters},
+		}},
+	}
+
+	users, err := r.suggestionCandidates(filter, exclude, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *userRepository) FindNearby(coordinates []float64, radiusMeters float64, exclude []primitive.ObjectID, limit, offset int) ([]domain.UserWithDistance, error) {
+	logger := utils.NewLogger("UserRepository.FindNearby")
+	logger.LogInput(map[string]interface{}{
+		"coordinates":  coordinates,
+		"radiusMeters": radiusMeters,
+		"limit":        limit,
+		"offset":       offset,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"_id":       bson.M{"$nin": exclude},
+		"deletedAt": bson.M{"$exists": false},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, geoNearPipeline(coordinates, radiusMeters, query, limit, offset))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]domain.UserWithDistance, 0)
+	if err := cursor.All(ctx, &users); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *userRepository) FindByInterests(interests []string, exclude []primitive.ObjectID, limit int) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindByInterests")
+	logger.LogInput(map[string]interface{}{
+		"interests": interests,
+		"limit":     limit,
+	})
+
+	users, err := r.suggestionCandidates(bson.M{"interests": bson.M{"$in": interests}}, exclude, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *userRepository) FindByUsernamePrefix(prefix string, limit int) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindByUsernamePrefix")
+	logger.LogInput(prefix, limit)
+
+	// An anchored, case sensitive regex can use the username index
+	filter := bson.M{"username": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	users, err := r.suggestionCandidates(filter, []primitive.ObjectID{}, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+// clearUserCache drops the cached copies of the user under every lookup key
+func (r *userRepository) clearUserCache(ctx context.Context, user *domain.User) error {
+	pipe := r.rdb.Pipeline()
+	pipe.Del(ctx, fmt.Sprintf("user:id:%s", user.ID.Hex()))
+	pipe.Del(ctx, fmt.Sprintf("user:username:%s", user.Username))
+	pipe.Del(ctx, fmt.Sprintf("user:email:%s", user.Email))
+	if user.FirebaseUID != "" {
+		pipe.Del(ctx, fmt.Sprintf("user:firebase:%s", user.FirebaseUID))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// updateSuspension applies the update to the user matching the filter and clears
+// the user's cache. It reports whether a user matched.
+func (r *userRepository) updateSuspension(filter, update bson.M) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user domain.User
+	err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, r.clearUserCache(ctx, &user)
+}
+
+func (r *userRepository) SetSuspension(id primitive.ObjectID, suspension *domain.Suspension) error {
+	logger := utils.NewLogger("UserRepository.SetSuspension")
+	logger.LogInput(id, suspension)
+
+	update := bson.M{"$set": bson.M{"suspension": suspension, "updatedAt": time.Now()}}
+	if suspension == nil {
+		update = bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"suspension": ""},
+		}
+	}
+
+	found, err := r.updateSuspension(bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}}, update)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if !found {
+		err = domain.NewNotFoundError("user", id.Hex())
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *userRepository) FindSuspendedUntil(before time.Time, unnotified bool, limit int) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindSuspendedUntil")
+	logger.LogInput(before, unnotified, limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"suspension.until": bson.M{"$lte": before}}
+	if unnotified {
+		filter["suspension.endingNotifiedAt"] = bson.M{"$exists": false}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "suspension.until", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]domain.User, 0)
+	if err = cursor.All(ctx, &users); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *userRepository) MarkSuspensionEndingNotified(id primitive.ObjectID, until time.Time) error {
+	logger := utils.NewLogger("UserRepository.MarkSuspensionEndingNotified")
+	logger.LogInput(id, until)
+
+	_, err := r.updateSuspension(
+		bson.M{"_id": id, "suspension.until": until},
+		bson.M{"$set": bson.M{"suspension.endingNotifiedAt": time.Now()}},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *userRepository) LiftExpiredSuspension(id primitive.ObjectID, until time.Time) (bool, error) {
+	logger := utils.NewLogger("UserRepository.LiftExpiredSuspension")
+	logger.LogInput(id, until)
+
+	lifted, err := r.updateSuspension(
+		bson.M{"_id": id, "suspension.until": until},
+		bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"suspension": ""},
+		},
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(lifted, nil)
+	return lifted, nil
+}
+
+func (r *userRepository) SetPrivacy(id primitive.ObjectID, privacy domain.PrivacySettings) error {
+	logger := utils.NewLogger("UserRepository.SetPrivacy")
+	logger.LogInput(id, privacy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user domain.User
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"privacy": privacy, "updatedAt": time.Now()}},
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		err = domain.NewNotFoundError("user", id.Hex())
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := r.clearUserCache(ctx, &user); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *userRepository) ScheduleDeletion(id primitive.ObjectID, purgeAt time.Time) error {
+	logger := utils.NewLogger("UserRepository.ScheduleDeletion")
+	logger.LogInput(id, purgeAt)
+
+	now := time.Now()
+	found, err := r.updateDeletion(
+		bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"deletedAt":           now,
+			"deletionScheduledAt": purgeAt,
+			"isActive":            false,
+			"updatedAt":           now,
+		}},
+	)
+	if err == nil && !found {
+		err = domain.NewNotFoundError("user", id.Hex())
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *userRepository) CancelDeletion(id primitive.ObjectID) error {
+	logger := utils.NewLogger("UserRepository.CancelDeletion")
+	logger.LogInput(id)
+
+	found, err := r.updateDeletion(
+		bson.M{"_id": id, "deletionScheduledAt": bson.M{"$exists": true}},
+		bson.M{
+			"$set":   bson.M{"isActive": true, "updatedAt": time.Now()},
+			"$unset": bson.M{"deletedAt": "", "deletionScheduledAt": ""},
+		},
+	)
+	if err == nil && !found {
+		err = domain.NewNotFoundError("account deletion", id.Hex())
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// updateDeletion applies the update to the user matching the filter and clears the
+// user's cache and the cached user lists. It reports whether a user matched.
+func (r *userRepository) updateDeletion(filter, update bson.M) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user domain.User
+	err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := r.clearUserCache(ctx, &user); err != nil {
+		return true, err
+	}
+
+	keys, err := r.rdb.Keys(ctx, "user_list:*").Result()
+	if err != nil {
+		return true, err
+	}
+	if len(keys) > 0 {
+		return true, r.rdb.Del(ctx, keys...).Err()
+	}
+	return true, nil
+}
+
+func (r *userRepository) FindDeletionDue(before time.Time, limit int) ([]domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindDeletionDue")
+	logger.LogInput(before, limit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"deletionScheduledAt": bson.M{"$lte": before},
+		"deletedAt":           bson.M{"$exists": true},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deletionScheduledAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]domain.User, 0)
+	if err = cursor.All(ctx, &users); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+func (r *userRepository) Purge(id primitive.ObjectID) error {
+	logger := utils.NewLogger("UserRepository.Purge")
+	logger.LogInput(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user domain.User
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		err = domain.NewNotFoundError("user", id.Hex())
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := r.clearUserCache(ctx, &user); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (r *userRepository) FindCredentialsByEmail(email string) (*domain.User, error) {
+	logger := utils.NewLogger("UserRepository.FindCredentialsByEmail")
+	logger.LogInput(email)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user domain.User
+	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		logger.LogOutput(nil, nil)
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(user.ID, nil)
+	return &user, nil
+}
+
+func (r *userRepository) SetPassword(id primitive.ObjectID, passwordHash string) error {
+	logger := utils.NewLogger("UserRepository.SetPassword")
+	logger.LogInput(id)
+
+	return r.setAccountFields(logger, id, bson.M{"password": passwordHash})
+}
+
+func (r *userRepository) MarkEmailVerified(id primitive.ObjectID) error {
+	logger := utils.NewLogger("UserRepository.MarkEmailVerified")
+	logger.LogInput(id)
+
+	return r.setAccountFields(logger, id, bson.M{"emailVerified": true})
+}
+
+// setAccountFields sets the fields on the user and clears the user's cache
+func (r *userRepository) setAccountFields(logger *utils.Logger, id primitive.ObjectID, fields bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fields["updatedAt"] = time.Now()
+
+	var user domain.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": fields}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		err = domain.NewNotFoundError("user", id.Hex())
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := r.clearUserCache(ctx, &user); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
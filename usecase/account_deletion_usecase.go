@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type accountDeletionUseCase struct {
+	userRepo     domain.UserRepository
+	postRepo     domain.PostRepository
+	subPostRepo  domain.SubPostRepository
+	commentRepo  domain.CommentRepository
+	reactionRepo domain.ReactionRepository
+	chatRepo     domain.ChatRepository
+	authClient   *auth.Client
+}
+
+func NewAccountDeletionUseCase(
+	userRepo domain.UserRepository,
+	postRepo domain.PostRepository,
+	subPostRepo domain.SubPostRepository,
+	commentRepo domain.CommentRepository,
+	reactionRepo domain.ReactionRepository,
+	chatRepo domain.ChatRepository,
+	authClient *auth.Client,
+) domain.AccountDeletionUseCase {
+	return &accountDeletionUseCase{
+		userRepo:     userRepo,
+		postRepo:     postRepo,
+		subPostRepo:  subPostRepo,
+		commentRepo:  commentRepo,
+		reactionRepo: reactionRepo,
+		chatRepo:     chatRepo,
+		authClient:   authClient,
+	}
+}
+
+// RequestDeletion keeps the Firebase user until the purge, so the owner can still
+// sign in to cancel. Asking again keeps the deletion already scheduled.
+func (u *accountDeletionUseCase) RequestDeletion(userID primitive.ObjectID) (*domain.AccountDeletion, error) {
+	logger := utils.NewLogger("AccountDeletionUseCase.RequestDeletion")
+	logger.LogInput(userID)
+
+	existing, err := u.GetDeletion(userID)
+	if err == nil {
+		logger.LogOutput(existing, nil)
+		return existing, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	purgeAt := time.Now().Add(domain.AccountDeletionGracePeriod)
+	if err := u.userRepo.ScheduleDeletion(userID, purgeAt); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	deletion, err := u.GetDeletion(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(deletion, nil)
+	return deletion, nil
+}
+
+func (u *accountDeletionUseCase) GetDeletion(userID primitive.ObjectID) (*domain.AccountDeletion, error) {
+	logger := utils.NewLogger("AccountDeletionUseCase.GetDeletion")
+	logger.LogInput(userID)
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil || user.DeletionScheduledAt == nil || user.DeletedAt == nil {
+		err := domain.NewNotFoundError("account deletion", userID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	deletion := &domain.AccountDeletion{
+		UserID:      userID,
+		RequestedAt: *user.DeletedAt,
+		PurgeAt:     *user.DeletionScheduledAt,
+	}
+
+	logger.LogOutput(deletion, nil)
+	return deletion, nil
+}
+
+func (u *accountDeletionUseCase) CancelDeletion(userID primitive.ObjectID) error {
+	logger := utils.NewLogger("AccountDeletionUseCase.CancelDeletion")
+	logger.LogInput(userID)
+
+	if err := u.userRepo.CancelDeletion(userID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// PurgeDueAccounts removes the user document last, so an account that fails
+// halfway is picked up again by the next run
+func (u *accountDeletionUseCase) PurgeDueAccounts() (*domain.AccountPurgeResult, error) {
+	logger := utils.NewLogger("AccountDeletionUseCase.PurgeDueAccounts")
+
+	users, err := u.userRepo.FindDeletionDue(time.Now(), domain.AccountPurgeBatchSize)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	result := &domain.AccountPurgeResult{}
+	for i := range users {
+		if err := u.purge(&users[i], result); err != nil {
+			logger.LogOutput(users[i].ID, err)
+			result.Failed++
+			continue
+		}
+		result.Accounts++
+	}
+
+	logger.LogOutput(result, nil)
+	return result, nil
+}
+
+// purge removes everything the user left behind, adding the counts to result
+func (u *accountDeletionUseCase) purge(user *domain.User, result *domain.AccountPurgeResult) error {
+	postIDs, err := u.postRepo.DeleteByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+	result.Posts += int64(len(postIDs))
+	for _, postID := range postIDs {
+		if err := u.subPostRepo.DeleteByParentID(postID); err != nil {
+			return err
+		}
+		// Comments of others on the user's posts go with the posts
+		if err := u.commentRepo.DeleteByPostID(postID); err != nil {
+			return err
+		}
+	}
+
+	comments, err := u.commentRepo.DeleteByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+	result.Comments += comments
+
+	reactions, err := u.reactionRepo.DeleteByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+	result.Reactions += reactions
+
+	rooms, err := u.chatRepo.RemoveMemberFromRooms(user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	result.ChatRooms += rooms
+
+	if user.FirebaseUID != "" {
+		err := u.authClient.DeleteUser(context.Background(), user.FirebaseUID)
+		if err != nil && !auth.IsUserNotFound(err) {
+			return err
+		}
+	}
+
+	return u.userRepo.Purge(user.ID)
+}
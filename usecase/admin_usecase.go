@@ -0,0 +1,360 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type adminUseCase struct {
+	userRepo        domain.UserRepository
+	postRepo        domain.PostRepository
+	subPostRepo     domain.SubPostRepository
+	hashtagRepo     domain.HashtagRepository
+	storedFileRepo  domain.StoredFileRepository
+	mediaBlobRepo   domain.MediaBlobRepository
+	bannedMediaRepo domain.BannedMediaRepository
+	storyRepo       domain.StoryRepository
+	auditLogRepo    domain.AuditLogRepository
+	retentionWindow time.Duration
+}
+
+func NewAdminUseCase(
+	userRepo domain.UserRepository,
+	postRepo domain.PostRepository,
+	subPostRepo domain.SubPostRepository,
+	hashtagRepo domain.HashtagRepository,
+	storedFileRepo domain.StoredFileRepository,
+	mediaBlobRepo domain.MediaBlobRepository,
+	bannedMediaRepo domain.BannedMediaRepository,
+	storyRepo domain.StoryRepository,
+	auditLogRepo domain.AuditLogRepository,
+	retentionWindow time.Duration,
+) domain.AdminUseCase {
+	return &adminUseCase{
+		userRepo:        userRepo,
+		postRepo:        postRepo,
+		subPostRepo:     subPostRepo,
+		hashtagRepo:     hashtagRepo,
+		storedFileRepo:  storedFileRepo,
+		mediaBlobRepo:   mediaBlobRepo,
+		bannedMediaRepo: bannedMediaRepo,
+		storyRepo:       storyRepo,
+		auditLogRepo:    auditLogRepo,
+		retentionWindow: retentionWindow,
+	}
+}
+
+func (u *adminUseCase) ListDeletedContent(contentType domain.DeletedContentType, limit, offset int) ([]domain.DeletedContent, error) {
+	logger := utils.NewLogger("AdminUseCase.ListDeletedContent")
+	logger.LogInput(map[string]interface{}{
+		"contentType": contentType,
+		"limit":       limit,
+		"offset":      offset,
+	})
+
+	since := time.Now().Add(-u.retentionWindow)
+	contents := make([]domain.DeletedContent, 0)
+
+	switch contentType {
+	case domain.DeletedContentUser:
+		users, err := u.userRepo.FindDeleted(since, limit, offset)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		for i := range users {
+			contents = append(contents, newDeletedContent(contentType, users[i].ID, users[i].DeletedAt, &users[i]))
+		}
+	case domain.DeletedContentPost:
+		posts, err := u.postRepo.FindDeleted(since, limit, offset)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		for i := range posts {
+			contents = append(contents, newDeletedContent(contentType, posts[i].ID, posts[i].DeletedAt, &posts[i]))
+		}
+	case domain.DeletedContentStory:
+		stories, err := u.storyRepo.FindDeleted(since, limit, offset)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		for _, story := range stories {
+			contents = append(contents, newDeletedContent(contentType, story.ID, story.DeletedAt, story))
+		}
+	default:
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return nil, domain.ErrInvalidInput
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(contents)}, nil)
+	return contents, nil
+}
+
+func (u *adminUseCase) GetDeletedContent(contentType domain.DeletedContentType, id string) (*domain.DeletedContent, error) {
+	logger := utils.NewLogger("AdminUseCase.GetDeletedContent")
+	logger.LogInput(map[string]interface{}{
+		"contentType": contentType,
+		"id":          id,
+	})
+
+	var content domain.DeletedContent
+	switch contentType {
+	case domain.DeletedContentUser:
+		user, err := u.userRepo.FindDeletedByID(id)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		content = newDeletedContent(contentType, user.ID, user.DeletedAt, user)
+	case domain.DeletedContentPost:
+		postID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, domain.ErrInvalidID
+		}
+		post, err := u.postRepo.FindDeletedByID(postID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		content = newDeletedContent(contentType, post.ID, post.DeletedAt, post)
+	case domain.DeletedContentStory:
+		story, err := u.storyRepo.FindDeletedByID(id)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		content = newDeletedContent(contentType, story.ID, story.DeletedAt, story)
+	default:
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return nil, domain.ErrInvalidInput
+	}
+
+	// Content past the retention window is treated as purged
+	if content.DeletedAt.Before(time.Now().Add(-u.retentionWindow)) {
+		err := domain.NewNotFoundError(string(contentType), id)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(content, nil)
+	return &content, nil
+}
+
+func (u *adminUseCase) RestoreContent(adminID primitive.ObjectID, contentType domain.DeletedContentType, id string) (*domain.AuditLog, error) {
+	logger := utils.NewLogger("AdminUseCase.RestoreContent")
+	logger.LogInput(map[string]interface{}{
+		"adminId":     adminID,
+		"contentType": contentType,
+		"id":          id,
+	})
+
+	content, err := u.GetDeletedContent(contentType, id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	switch contentType {
+	case domain.DeletedContentUser:
+		// The username may have been claimed while the account was deleted
+		user := content.Payload.(*domain.User)
+		if existing, err := u.userRepo.FindByUsername(user.Username); err == nil && existing != nil {
+			logger.LogOutput(nil, domain.ErrDuplicate)
+			return nil, domain.ErrDuplicate
+		}
+		err = u.userRepo.Restore(id)
+	case domain.DeletedContentPost:
+		post := content.Payload.(*domain.Post)
+		if err = u.postRepo.Restore(post.ID); err == nil {
+			err = u.subPostRepo.RestoreByParentID(post.ID)
+		}
+		if err == nil {
+			// The restored post counts towards its tags again
+			if tagErr := u.hashtagRepo.IncrementPostCounts(post.Tags, 1); tagErr != nil {
+				logger.LogOutput(nil, tagErr)
+			}
+			// and its media towards the author's storage
+			if fileErr := u.storedFileRepo.SetReleased(post.UserID, mediaURLs(post.Media), false); fileErr != nil {
+				logger.LogOutput(nil, fileErr)
+			}
+		}
+	case domain.DeletedContentStory:
+		err = u.storyRepo.Restore(id)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	auditLog := &domain.AuditLog{
+		ActorID:    adminID,
+		Action:     domain.AuditActionRestore,
+		TargetType: string(contentType),
+		TargetID:   id,
+		Metadata: map[string]interface{}{
+			"deletedAt": content.DeletedAt,
+		},
+	}
+	if err := u.auditLogRepo.Create(auditLog); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(auditLog, nil)
+	return auditLog, nil
+}
+
+func (u *adminUseCase) ListAuditLogs(targetType, targetID string, limit, offset int) ([]domain.AuditLog, error) {
+	logger := utils.NewLogger("AdminUseCase.ListAuditLogs")
+	logger.LogInput(map[string]interface{}{
+		"targetType": targetType,
+		"targetId":   targetID,
+		"limit":      limit,
+		"offset":     offset,
+	})
+
+	var logs []domain.AuditLog
+	var err error
+	if targetType != "" {
+		logs, err = u.auditLogRepo.FindByTarget(targetType, targetID, limit, offset)
+	} else {
+		logs, err = u.auditLogRepo.FindAll(limit, offset)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(logs)}, nil)
+	return logs, nil
+}
+
+func (u *adminUseCase) BanMedia(adminID primitive.ObjectID, hash, fileURL, reason string) (*domain.BannedMedia, error) {
+	logger := utils.NewLogger("AdminUseCase.BanMedia")
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"hash":    hash,
+		"fileUrl": fileURL,
+		"reason":  reason,
+	})
+
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" && fileURL != "" {
+		blob, err := u.mediaBlobRepo.FindByURL(fileURL)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if blob == nil {
+			err := domain.NewNotFoundError("file", fileURL)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		hash = blob.PerceptualHash
+	}
+	if !utils.IsPerceptualHash(hash) {
+		err := fmt.Errorf("%w: a perceptual hash or the URL of an uploaded image is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	media := &domain.BannedMedia{
+		PerceptualHash: hash,
+		Reason:         strings.TrimSpace(reason),
+		CreatedBy:      adminID,
+	}
+	if err := u.bannedMediaRepo.Create(media); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	u.recordAudit(adminID, domain.AuditActionBanMedia, media.ID.Hex(), map[string]interface{}{
+		"perceptualHash": media.PerceptualHash,
+		"reason":         media.Reason,
+	})
+
+	logger.LogOutput(media, nil)
+	return media, nil
+}
+
+func (u *adminUseCase) ListBannedMedia(limit, offset int) ([]domain.BannedMedia, error) {
+	logger := utils.NewLogger("AdminUseCase.ListBannedMedia")
+	logger.LogInput(map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	media, err := u.bannedMediaRepo.FindAll(limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(media)}, nil)
+	return media, nil
+}
+
+func (u *adminUseCase) UnbanMedia(adminID primitive.ObjectID, id string) error {
+	logger := utils.NewLogger("AdminUseCase.UnbanMedia")
+	logger.LogInput(map[string]interface{}{
+		"adminId": adminID,
+		"id":      id,
+	})
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		logger.LogOutput(nil, domain.ErrInvalidID)
+		return domain.ErrInvalidID
+	}
+
+	media, err := u.bannedMediaRepo.FindByID(objectID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if err := u.bannedMediaRepo.Delete(objectID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	u.recordAudit(adminID, domain.AuditActionUnbanMedia, id, map[string]interface{}{
+		"perceptualHash": media.PerceptualHash,
+	})
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// recordAudit logs an admin action that has already happened, so a failure only gets logged
+func (u *adminUseCase) recordAudit(adminID primitive.ObjectID, action, targetID string, metadata map[string]interface{}) {
+	err := u.auditLogRepo.Create(&domain.AuditLog{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: "media",
+		TargetID:   targetID,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		utils.NewLogger("AdminUseCase.recordAudit").LogOutput(nil, err)
+	}
+}
+
+func newDeletedContent(contentType domain.DeletedContentType, id primitive.ObjectID, deletedAt *time.Time, payload interface{}) domain.DeletedContent {
+	content := domain.DeletedContent{
+		Type:    contentType,
+		ID:      id.Hex(),
+		Payload: payload,
+	}
+	if deletedAt != nil {
+		content.DeletedAt = *deletedAt
+	}
+	return content
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// Client clocks drift, timestamps further off than this are replaced by the receive time
+const maxAnalyticsClockSkew = 24 * time.Hour
+
+type analyticsUseCase struct {
+	sink domain.AnalyticsSink
+}
+
+func NewAnalyticsUseCase(sink domain.AnalyticsSink) domain.AnalyticsUseCase {
+	return &analyticsUseCase{
+		sink: sink,
+	}
+}
+
+func (a *analyticsUseCase) TrackEvents(userID string, events []domain.AnalyticsEvent) error {
+	logger := utils.NewLogger("AnalyticsUseCase.TrackEvents")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"count":  len(events),
+	})
+
+	if len(events) == 0 || len(events) > domain.MaxAnalyticsBatchSize {
+		err := fmt.Errorf("%w: a batch must have between 1 and %d events", domain.ErrInvalidInput, domain.MaxAnalyticsBatchSize)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	now := time.Now()
+	for i := range events {
+		if err := validateAnalyticsEvent(&events[i]); err != nil {
+			err = fmt.Errorf("%w: event %d: %s", domain.ErrInvalidInput, i, err)
+			logger.LogOutput(nil, err)
+			return err
+		}
+
+		events[i].UserID = userID
+		events[i].ReceivedAt = now
+		if events[i].Timestamp.IsZero() || events[i].Timestamp.Sub(now).Abs() > maxAnalyticsClockSkew {
+			events[i].Timestamp = now
+		}
+	}
+
+	if err := a.sink.Publish(events); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(events)}, nil)
+	return nil
+}
+
+// validateAnalyticsEvent checks the event against its schema in the registry
+func validateAnalyticsEvent(event *domain.AnalyticsEvent) error {
+	schema, ok := domain.AnalyticsEventSchemas[event.Name]
+	if !ok {
+		return fmt.Errorf("unknown event %q", event.Name)
+	}
+	if len(event.Properties) > domain.MaxAnalyticsProperties {
+		return fmt.Errorf("at most %d properties are allowed", domain.MaxAnalyticsProperties)
+	}
+	if len(event.SessionID) > domain.MaxAnalyticsPropertyLength {
+		return fmt.Errorf("sessionId is too long")
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := event.Properties[name]; !ok {
+			return fmt.Errorf("property %q is required", name)
+		}
+	}
+
+	for name, value := range event.Properties {
+		propertyType, ok := schema.Properties[name]
+		if !ok {
+			return fmt.Errorf("unknown property %q", name)
+		}
+
+		valid := false
+		switch propertyType {
+		case domain.AnalyticsPropertyString:
+			var s string
+			s, valid = value.(string)
+			if valid && (strings.TrimSpace(s) == "" || len(s) > domain.MaxAnalyticsPropertyLength) {
+				return fmt.Errorf("property %q must be a non-empty string of at most %d bytes", name, domain.MaxAnalyticsPropertyLength)
+			}
+		case domain.AnalyticsPropertyNumber:
+			// JSON numbers decode to float64
+			_, valid = value.(float64)
+		case domain.AnalyticsPropertyBool:
+			_, valid = value.(bool)
+		}
+		if !valid {
+			return fmt.Errorf("property %q must be a %s", name, propertyType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type apiUsageUseCase struct {
+	usageRepo domain.APIUsageRepository
+}
+
+func NewAPIUsageUseCase(usageRepo domain.APIUsageRepository) domain.APIUsageUseCase {
+	return &apiUsageUseCase{
+		usageRepo: usageRepo,
+	}
+}
+
+// RecordRequest leaves logging to the repository, it runs on every request
+func (u *apiUsageUseCase) RecordRequest(userID string, request domain.APIRequest) {
+	_ = u.usageRepo.Record(userID, request)
+}
+
+func (u *apiUsageUseCase) GetUsage(userID string, days int) (*domain.APIUsage, error) {
+	logger := utils.NewLogger("APIUsageUseCase.GetUsage")
+	logger.LogInput(userID, days)
+
+	if days < 1 || days > domain.APIUsageRetentionDays {
+		err := fmt.Errorf("%w: days must be between 1 and %d", domain.ErrInvalidInput, domain.APIUsageRetentionDays)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Latest day first
+	today := time.Now().UTC()
+	dates := make([]string, days)
+	for i := range dates {
+		dates[i] = today.AddDate(0, 0, -i).Format(domain.APIUsageDayFormat)
+	}
+
+	usageDays, err := u.usageRepo.GetDays(userID, dates)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	recent, err := u.usageRepo.GetRecent(userID, domain.APIUsageRecentRequests)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	usage := &domain.APIUsage{
+		UserID: userID,
+		Days:   usageDays,
+		Recent: recent,
+	}
+	for _, day := range usageDays {
+		usage.Total += day.Total
+		usage.Errors += day.Errors
+	}
+
+	logger.LogOutput(map[string]interface{}{"total": usage.Total}, nil)
+	return usage, nil
+}
@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Kinds of emailed auth links, also their Redis key prefixes
+const (
+	emailVerificationLink = "email_verification"
+	passwordResetLink     = "password_reset"
+)
+
+// errInvalidCredentials is the one login error, so it doesn't tell whether the email has an account
+var errInvalidCredentials = fmt.Errorf("%w: invalid email or password", domain.ErrUnauthorized)
+
+// dummyPasswordHash is compared against when there's no account, so a login takes
+// as long whether the email has an account or not
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("vongga-dummy-password"), bcrypt.DefaultCost)
+
+func (u *authUseCase) Register(ctx context.Context, email, password string) (*domain.User, *domain.TokenPair, error) {
+	logger := utils.NewLogger("AuthUseCase.Register")
+	email = normalizeEmail(email)
+	logger.LogInput(email)
+
+	if !utils.IsValidEmail(email) {
+		err := fmt.Errorf("%w: invalid email format", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+	if err := validatePassword(password); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+
+	existing, err := u.userRepo.FindCredentialsByEmail(email)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+	if existing != nil {
+		err := fmt.Errorf("%w: an account with this email already exists", domain.ErrDuplicate)
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+	user := &domain.User{
+		Email:    email,
+		Password: string(hash),
+		Provider: domain.Email,
+	}
+	if err := u.userRepo.Create(user); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+
+	u.events.Publish(domain.DomainEventUserRegistered, domain.UserRegisteredPayload{
+		UserID:       user.ID,
+		Provider:     user.Provider,
+		RegisteredAt: user.CreatedAt,
+	})
+
+	// The user can ask for another link, registering already worked
+	if err := u.sendAuthLink(ctx, user, emailVerificationLink); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	tokenPair, err := u.generateTokenPair(ctx, user)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+
+	logger.LogOutput(user.ID, nil)
+	return user, tokenPair, nil
+}
+
+// Login also signs in users whose account deletion is scheduled, so they can cancel it
+func (u *authUseCase) Login(ctx context.Context, email, password string) (*domain.User, *domain.TokenPair, error) {
+	logger := utils.NewLogger("AuthUseCase.Login")
+	email = normalizeEmail(email)
+	logger.LogInput(email)
+
+	user, err := u.userRepo.FindCredentialsByEmail(email)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+
+	hash := dummyPasswordHash
+	if user != nil && user.Password != "" {
+		hash = []byte(user.Password)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || user == nil || user.Password == "" {
+		logger.LogOutput(nil, errInvalidCredentials)
+		return nil, nil, errInvalidCredentials
+	}
+	if user.DeletedAt != nil && user.DeletionScheduledAt == nil {
+		logger.LogOutput(nil, errInvalidCredentials)
+		return nil, nil, errInvalidCredentials
+	}
+
+	tokenPair, err := u.generateTokenPair(ctx, user)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+
+	logger.LogOutput(user.ID, nil)
+	return user, tokenPair, nil
+}
+
+func (u *authUseCase) SendEmailVerification(ctx context.Context, email string) error {
+	logger := utils.NewLogger("AuthUseCase.SendEmailVerification")
+	email = normalizeEmail(email)
+	logger.LogInput(email)
+
+	user, err := u.userRepo.FindCredentialsByEmail(email)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if user == nil || user.EmailVerified || user.DeletedAt != nil {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	if err := u.sendAuthLink(ctx, user, emailVerificationLink); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (u *authUseCase) VerifyEmail(ctx context.Context, token string) error {
+	logger := utils.NewLogger("AuthUseCase.VerifyEmail")
+
+	userID, err := u.consumeAuthLink(ctx, emailVerificationLink, token)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	logger.LogInput(userID)
+
+	if err := u.userRepo.MarkEmailVerified(userID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (u *authUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	logger := utils.NewLogger("AuthUseCase.RequestPasswordReset")
+	email = normalizeEmail(email)
+	logger.LogInput(email)
+
+	user, err := u.userRepo.FindCredentialsByEmail(email)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	// Firebase accounts reset their password with their provider
+	if user == nil || user.Password == "" || (user.DeletedAt != nil && user.DeletionScheduledAt == nil) {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	if err := u.sendAuthLink(ctx, user, passwordResetLink); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (u *authUseCase) ResetPassword(ctx context.Context, token, password string) error {
+	logger := utils.NewLogger("AuthUseCase.ResetPassword")
+
+	if err := validatePassword(password); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	userID, err := u.consumeAuthLink(ctx, passwordResetLink, token)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	logger.LogInput(userID)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if err := u.userRepo.SetPassword(userID, string(hash)); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	// Receiving the link proves the mailbox too
+	if err := u.userRepo.MarkEmailVerified(userID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := u.revokeAllRefreshTokens(ctx, userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// sendAuthLink emails the user a single use link of the kind. Only the token's
+// hash is stored, and at most one email of a kind goes out per cooldown.
+func (u *authUseCase) sendAuthLink(ctx context.Context, user *domain.User, kind string) error {
+	cooldownKey := fmt.Sprintf("%s_cooldown:%s", kind, user.ID.Hex())
+	sent, err := u.redisClient.SetNX(ctx, cooldownKey, 1, domain.AuthEmailCooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !sent {
+		return nil
+	}
+
+	ttl, path, subject, text := domain.EmailVerificationTTL, "verify-email", "Verify your Vongga email",
+		"Confirm this is your email address by opening the link below. It works for 24 hours."
+	if kind == passwordResetLink {
+		ttl, path, subject, text = domain.PasswordResetTTL, "reset-password", "Reset your Vongga password",
+			"Choose a new password by opening the link below. It works for 1 hour. If you didn't ask for it, you can ignore this email."
+	}
+
+	token := generateRandomString(32)
+	err = u.redisClient.Set(ctx, authLinkKey(kind, token), user.ID.Hex(), ttl).Err()
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/%s?token=%s", u.emailLinkBaseURL, path, url.QueryEscape(token))
+	return u.emailSender.Send(user.Email, subject, text+"\n\n"+link+"\n")
+}
+
+// consumeAuthLink returns the user the link token was sent to and invalidates it
+func (u *authUseCase) consumeAuthLink(ctx context.Context, kind, token string) (primitive.ObjectID, error) {
+	invalid := fmt.Errorf("%w: the link is invalid or has expired", domain.ErrInvalidInput)
+	if token == "" {
+		return primitive.NilObjectID, invalid
+	}
+
+	userID, err := u.redisClient.GetDel(ctx, authLinkKey(kind, token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return primitive.NilObjectID, invalid
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return primitive.NilObjectID, invalid
+	}
+	return id, nil
+}
+
+// revokeAllRefreshTokens signs the user out of every session
+func (u *authUseCase) revokeAllRefreshTokens(ctx context.Context, userID string) error {
+	iter := u.redisClient.Scan(ctx, 0, fmt.Sprintf("refresh_token:%s:*", userID), 100).Iterator()
+	keys := make([]string, 0)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return u.redisClient.Del(ctx, keys...).Err()
+}
+
+func authLinkKey(kind, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%s:%s", kind, hex.EncodeToString(hash[:]))
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func validatePassword(password string) error {
+	if len(password) < domain.MinPasswordLength || len(password) > domain.MaxPasswordLength {
+		return fmt.Errorf("%w: password must be %d to %d characters", domain.ErrInvalidInput, domain.MinPasswordLength, domain.MaxPasswordLength)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
 	"firebase.google.com/go/v4/auth"
@@ -18,29 +19,41 @@ type authUseCase struct {
 	userRepo           domain.UserRepository
 	authClient         *auth.Client
 	redisClient        *redis.Client
-	jwtSecret          string
+	jwtKeys            *domain.JWTKeySet
+	tokenPolicy        domain.TokenPolicy
 	refreshTokenSecret string
 	tokenExpiry        time.Duration
 	refreshTokenExpiry time.Duration
+	events             domain.DomainEventPublisher
+	emailSender        domain.EmailSender
+	emailLinkBaseURL   string
 }
 
 func NewAuthUseCase(
 	userRepo domain.UserRepository,
 	authClient *auth.Client,
 	redisClient *redis.Client,
-	jwtSecret string,
+	jwtKeys *domain.JWTKeySet,
+	tokenPolicy domain.TokenPolicy,
 	refreshTokenSecret string,
 	tokenExpiry time.Duration,
 	refreshTokenExpiry time.Duration,
+	events domain.DomainEventPublisher,
+	emailSender domain.EmailSender,
+	emailLinkBaseURL string,
 ) domain.AuthUseCase {
 	return &authUseCase{
 		userRepo:           userRepo,
 		authClient:         authClient,
 		redisClient:        redisClient,
-		jwtSecret:          jwtSecret,
+		jwtKeys:            jwtKeys,
+		tokenPolicy:        tokenPolicy,
 		refreshTokenSecret: refreshTokenSecret,
 		tokenExpiry:        tokenExpiry,
 		refreshTokenExpiry: refreshTokenExpiry,
+		events:             events,
+		emailSender:        emailSender,
+		emailLinkBaseURL:   strings.TrimRight(emailLinkBaseURL, "/"),
 	}
 }
 
@@ -92,10 +105,16 @@ func (u *authUseCase) VerifyTokenFirebase(ctx context.Context, firebaseToken str
 			logger.LogOutput(nil, fmt.Errorf("error getting created user: %v", err))
 			return nil, nil, fmt.Errorf("error getting created user: %v", err)
 		}
+
+		u.events.Publish(domain.DomainEventUserRegistered, domain.UserRegisteredPayload{
+			UserID:       user.ID,
+			Provider:     user.Provider,
+			RegisteredAt: user.CreatedAt,
+		})
 	}
 
 	// Generate token pair
-	tokenPair, err := u.generateTokenPair(ctx, user.ID.Hex())
+	tokenPair, err := u.generateTokenPair(ctx, user)
 	if err != nil {
 		logger.LogOutput(nil, fmt.Errorf("error generating tokens: %v", err))
 		return nil, nil, fmt.Errorf("error generating tokens: %v", err)
@@ -161,8 +180,19 @@ func (u *authUseCase) RefreshToken(ctx context.Context, refreshToken string) (*d
 		return nil, fmt.Errorf("refresh token has been revoked")
 	}
 
+	// The new access token carries the user's current role
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil {
+		logger.LogOutput(nil, fmt.Errorf("user %s not found", userID))
+		return nil, fmt.Errorf("invalid refresh token: user not found")
+	}
+
 	// Generate new token pair
-	tokenPair, err := u.generateTokenPair(ctx, userID)
+	tokenPair, err := u.generateTokenPair(ctx, user)
 	if err != nil {
 		logger.LogOutput(nil, fmt.Errorf("error generating new token pair: %v", err))
 		return nil, err
@@ -238,11 +268,7 @@ func (u *authUseCase) CreateTestToken(ctx context.Context, userID string) (*doma
 	}
 
 	// Create access token
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID.Hex(),
-		"exp": time.Now().Add(u.tokenExpiry).Unix(),
-	})
-	accessTokenString, err := accessToken.SignedString([]byte(u.jwtSecret))
+	accessTokenString, err := u.jwtKeys.Sign(u.accessClaims(user))
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
@@ -275,18 +301,29 @@ func (u *authUseCase) CreateTestToken(ctx context.Context, userID string) (*doma
 	return tokenPair, nil
 }
 
-func (u *authUseCase) generateTokenPair(ctx context.Context, userID string) (*domain.TokenPair, error) {
+// accessClaims returns the claims of a new access token of the user
+func (u *authUseCase) accessClaims(user *domain.User) domain.Claims {
+	role := user.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+	return domain.Claims{
+		UserID:           user.ID.Hex(),
+		Type:             domain.TokenTypeAccess,
+		Roles:            []string{string(role)},
+		Scopes:           u.tokenPolicy.UserScopes,
+		Tenant:           u.tokenPolicy.Tenant,
+		RegisteredClaims: u.tokenPolicy.RegisteredClaims(user.ID.Hex(), u.tokenExpiry),
+	}
+}
+
+func (u *authUseCase) generateTokenPair(ctx context.Context, user *domain.User) (*domain.TokenPair, error) {
 	logger := utils.NewLogger("AuthUseCase.generateTokenPair")
+	userID := user.ID.Hex()
 	logger.LogInput(userID)
 
-	// Generate access token
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userId": userID,
-		"exp":    time.Now().Add(u.tokenExpiry).Unix(),
-		"type":   "access",
-	})
-
-	accessTokenString, err := accessToken.SignedString([]byte(u.jwtSecret))
+	// Generate access token signed with the currently active key
+	accessTokenString, err := u.jwtKeys.Sign(u.accessClaims(user))
 	if err != nil {
 		logger.LogOutput(nil, fmt.Errorf("error generating access token: %v", err))
 		return nil, err
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// IssueWSTicket stores only the ticket's hash, like email links, so the ticket
+// itself never sits in Redis
+func (u *authUseCase) IssueWSTicket(ctx context.Context, userID, purpose string) (*domain.WSTicket, error) {
+	logger := utils.NewLogger("AuthUseCase.IssueWSTicket")
+	logger.LogInput(userID, purpose)
+
+	if purpose != domain.WSTicketChat && purpose != domain.WSTicketOps {
+		err := fmt.Errorf("%w: purpose must be %s or %s", domain.ErrInvalidInput, domain.WSTicketChat, domain.WSTicketOps)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ticket := generateRandomString(32)
+	err := u.redisClient.Set(ctx, wsTicketKey(purpose, ticket), userID, domain.WSTicketTTL).Err()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	wsTicket := &domain.WSTicket{
+		Ticket:    ticket,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(domain.WSTicketTTL),
+	}
+	logger.LogOutput(wsTicket.ExpiresAt, nil)
+	return wsTicket, nil
+}
+
+// RedeemWSTicket reports unknown, expired, used and other purpose tickets the same way
+func (u *authUseCase) RedeemWSTicket(ctx context.Context, ticket, purpose string) (string, error) {
+	logger := utils.NewLogger("AuthUseCase.RedeemWSTicket")
+	logger.LogInput(purpose)
+
+	invalid := fmt.Errorf("%w: the ticket is invalid or has expired", domain.ErrUnauthorized)
+	if ticket == "" {
+		logger.LogOutput(nil, invalid)
+		return "", invalid
+	}
+
+	userID, err := u.redisClient.GetDel(ctx, wsTicketKey(purpose, ticket)).Result()
+	if errors.Is(err, redis.Nil) {
+		logger.LogOutput(nil, invalid)
+		return "", invalid
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return "", err
+	}
+
+	logger.LogOutput(userID, nil)
+	return userID, nil
+}
+
+func wsTicketKey(purpose, ticket string) string {
+	return authLinkKey("ws_ticket_"+purpose, ticket)
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// authorizeOwnerOrAdmin lets the owner of a resource and admins act on it.
+// Everyone else gets domain.ErrForbidden.
+func authorizeOwnerOrAdmin(userRepo domain.UserRepository, actorID, ownerID string) error {
+	if actorID == ownerID {
+		return nil
+	}
+
+	actor, err := userRepo.FindByID(actorID)
+	if err != nil {
+		return err
+	}
+	if actor == nil || !actor.IsAdmin() {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// checkCanPublish rejects banned and muted users with a domain.SuspensionError
+func checkCanPublish(userRepo domain.UserRepository, userID string) error {
+	user, err := userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user != nil && user.Suspension.IsActive(time.Now()) {
+		return &domain.SuspensionError{Suspension: user.Suspension}
+	}
+	return nil
+}
+
+// canViewPost applies the post visibility rules to a viewer.
+// Posts without a visibility are public.
+func canViewPost(friendshipRepo domain.FriendshipRepository, viewerID primitive.ObjectID, post *domain.Post) (bool, error) {
+	if viewerID == post.UserID {
+		return true, nil
+	}
+	// Hidden by a moderator
+	if !post.IsActive {
+		return false, nil
+	}
+
+	switch post.Visibility {
+	case domain.VisibilityPublic, "":
+		return true, nil
+	case domain.VisibilityFriends:
+		friendship, err := friendshipRepo.FindByUsers(viewerID, post.UserID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return friendship.Status == "accepted", nil
+	default:
+		return false, nil
+	}
+}
+
+// visiblePostVisibilities lists the post visibilities the viewer may see of the
+// author's posts. It returns nil for the author, who sees all of them.
+func visiblePostVisibilities(friendshipRepo domain.FriendshipRepository, viewerID, authorID primitive.ObjectID) ([]string, error) {
+	if viewerID == authorID {
+		return nil, nil
+	}
+
+	visibilities := []string{domain.VisibilityPublic, ""}
+	friendship, err := friendshipRepo.FindByUsers(viewerID, authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return visibilities, nil
+		}
+		return nil, err
+	}
+	if friendship.Status == "accepted" {
+		visibilities = append(visibilities, domain.VisibilityFriends)
+	}
+	return visibilities, nil
+}
+
+// isBlockedEitherWay reports whether either user blocked the other
+func isBlockedEitherWay(followUseCase domain.FollowUseCase, userID, otherID primitive.ObjectID) (bool, error) {
+	blocked, err := followUseCase.IsBlocked(userID, otherID)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	return followUseCase.IsBlocked(otherID, userID)
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (u *chatUsecase) GetAutoReply(userID string) (*domain.ChatAutoReply, error) {
+	logger := utils.NewLogger("ChatUsecase.GetAutoReply")
+	logger.LogInput(userID)
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil {
+		err = domain.NewNotFoundError("user", userID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// An expired auto-reply is as good as off
+	reply := user.AutoReply
+	if !reply.Active(time.Now()) {
+		reply = nil
+	}
+
+	logger.LogOutput(reply, nil)
+	return reply, nil
+}
+
+func (u *chatUsecase) SetAutoReply(userID string, reply domain.ChatAutoReply) (*domain.ChatAutoReply, error) {
+	logger := utils.NewLogger("ChatUsecase.SetAutoReply")
+	logger.LogInput(userID, reply)
+
+	reply.Message = strings.TrimSpace(reply.Message)
+	if reply.Message == "" {
+		err := fmt.Errorf("%w: message is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len([]rune(reply.Message)) > domain.MaxChatAutoReplyLength {
+		err := fmt.Errorf("%w: message must be at most %d characters", domain.ErrInvalidInput, domain.MaxChatAutoReplyLength)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if reply.Until != nil && !reply.Until.After(time.Now()) {
+		err := fmt.Errorf("%w: until must be in the future", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil {
+		err = domain.NewNotFoundError("user", userID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	user.AutoReply = &reply
+	if err := u.userRepo.Update(user); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(user.AutoReply, nil)
+	return user.AutoReply, nil
+}
+
+func (u *chatUsecase) ClearAutoReply(userID string) error {
+	logger := utils.NewLogger("ChatUsecase.ClearAutoReply")
+	logger.LogInput(userID)
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if user == nil || user.AutoReply == nil {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	user.AutoReply = nil
+	if err := u.userRepo.Update(user); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// sendAutoReply answers a message in a private room with the other member's
+// auto-reply, once per sender per ChatAutoReplyWindow. The message is already
+// delivered, so failures are only logged.
+func (u *chatUsecase) sendAutoReply(room *domain.ChatRoom, message *domain.ChatMessage) {
+	if room.Type != "private" || message.IsAutoReply {
+		return
+	}
+
+	logger := utils.NewLogger("ChatUsecase.sendAutoReply")
+
+	for _, memberID := range room.Members {
+		if memberID == message.SenderID {
+			continue
+		}
+
+		member, err := u.userRepo.FindByID(memberID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+		if member == nil || !member.AutoReply.Active(time.Now()) {
+			continue
+		}
+
+		claimed, err := u.chatRepo.ClaimAutoReply(memberID, message.SenderID, domain.ChatAutoReplyWindow)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		reply := &domain.ChatMessage{
+			BaseModel: domain.BaseModel{
+				ID:        primitive.NewObjectID(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				IsActive:  true,
+				Version:   1,
+			},
+			RoomID:      message.RoomID,
+			SenderID:    memberID,
+			Type:        "text",
+			Content:     member.AutoReply.Message,
+			ReadBy:      []string{memberID},
+			IsAutoReply: true,
+		}
+		if err := u.deliverMessage(room, reply, "Auto-reply"); err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+
+		if u.realtime != nil {
+			if err := u.realtime.PublishToRoom(reply.RoomID, domain.RealtimeEventAutoReply, reply); err != nil {
+				logger.LogOutput(nil, err)
+			}
+		}
+		logger.LogOutput(reply.ID, nil)
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// checkNotBlocked rejects with ErrBlocked when either user blocked the other
+func (u *chatUsecase) checkNotBlocked(userID, otherID string) error {
+	user, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	other, err := primitive.ObjectIDFromHex(otherID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	blocked, err := isBlockedEitherWay(u.followUseCase, user, other)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return domain.ErrBlocked
+	}
+	return nil
+}
+
+// checkCanMessage rejects messages to a private room when the sender and the
+// other member blocked one another, or the other member's privacy settings
+// don't let the sender message them. Group rooms aren't affected.
+func (u *chatUsecase) checkCanMessage(room *domain.ChatRoom, senderID string) error {
+	if room.Type != "private" {
+		return nil
+	}
+	for _, memberID := range room.Members {
+		if memberID == senderID {
+			continue
+		}
+		if err := u.checkNotBlocked(senderID, memberID); err != nil {
+			return err
+		}
+		if err := u.checkPrivacy(senderID, memberID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPrivacy rejects with ErrPrivacyRestricted when the recipient's privacy
+// settings don't let the sender message them
+func (u *chatUsecase) checkPrivacy(senderID, recipientID string) error {
+	sender, err := primitive.ObjectIDFromHex(senderID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	recipient, err := primitive.ObjectIDFromHex(recipientID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	return u.privacyUseCase.CheckCanMessage(sender, recipient)
+}
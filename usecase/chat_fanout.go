@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fanOutMessage notifies the recipients of a saved message and pushes it to their
+// devices. Small rooms are done before returning. Past domain.ChatSyncFanoutRecipients
+// it runs in the background in batches, and failures are only logged.
+func (u *chatUsecase) fanOutMessage(message *domain.ChatMessage, notificationType, notice string, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	if len(recipients) <= domain.ChatSyncFanoutRecipients {
+		notifications := newChatNotifications(recipients, notificationType, message, notice)
+		if err := u.chatRepo.CreateNotifications(notifications); err != nil {
+			return err
+		}
+		u.pushMessage(recipients, message)
+		return nil
+	}
+
+	go func() {
+		logger := utils.NewLogger("ChatUsecase.fanOutMessage")
+		logger.LogInput(map[string]interface{}{
+			"messageID":  message.ID.Hex(),
+			"recipients": len(recipients),
+		})
+
+		for start := 0; start < len(recipients); start += domain.ChatFanoutBatchSize {
+			batch := recipients[start:min(start+domain.ChatFanoutBatchSize, len(recipients))]
+			notifications := newChatNotifications(batch, notificationType, message, notice)
+			if err := u.chatRepo.CreateNotifications(notifications); err != nil {
+				logger.LogOutput(nil, err)
+			}
+			u.pushMessage(batch, message)
+		}
+
+		logger.LogOutput(nil, nil)
+	}()
+	return nil
+}
+
+func newChatNotifications(recipients []string, notificationType string, message *domain.ChatMessage, notice string) []*domain.ChatNotification {
+	now := time.Now()
+	notifications := make([]*domain.ChatNotification, 0, len(recipients))
+	for _, recipientID := range recipients {
+		notifications = append(notifications, &domain.ChatNotification{
+			BaseModel: domain.BaseModel{
+				ID:        primitive.NewObjectID(),
+				CreatedAt: now,
+				UpdatedAt: now,
+				IsActive:  true,
+				Version:   1,
+			},
+			UserID:    recipientID,
+			Type:      notificationType,
+			RoomID:    message.RoomID,
+			MessageID: message.ID.Hex(),
+			Message:   notice,
+		})
+	}
+	return notifications
+}
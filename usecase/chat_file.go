@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// validateChatFile checks the file against the limits of its type.
+// Durations are only kept for audio and video, and thumbnails only for video.
+func validateChatFile(file *domain.ChatFileMessage) error {
+	file.FileType = strings.ToLower(strings.TrimSpace(file.FileType))
+	rule, ok := domain.ChatFileTypes[file.FileType]
+	if !ok {
+		return fmt.Errorf("%w: unsupported file type: %s", domain.ErrInvalidInput, file.FileType)
+	}
+	if file.FileURL == "" {
+		return fmt.Errorf("%w: fileUrl is required", domain.ErrInvalidInput)
+	}
+	if file.FileSize <= 0 || file.FileSize > rule.MaxSize {
+		return fmt.Errorf("%w: %s files must be at most %dMB", domain.ErrInvalidInput, file.FileType, rule.MaxSize/(1024*1024))
+	}
+
+	if rule.Kind == domain.ChatFileImage {
+		file.Duration = 0
+	} else {
+		duration := time.Duration(file.Duration * float64(time.Second))
+		if duration <= 0 || duration > rule.MaxDuration {
+			return fmt.Errorf("%w: %s messages need a duration of at most %s", domain.ErrInvalidInput, rule.Kind, rule.MaxDuration)
+		}
+	}
+	if rule.Kind != domain.ChatFileVideo {
+		file.ThumbnailURL = ""
+	}
+	return nil
+}
+
+// shareFileWithRoom lets the room's members download the sender's private files
+// behind the URLs. Public files and files of others are left alone.
+func (u *chatUsecase) shareFileWithRoom(senderID, roomID string, urls ...string) error {
+	sender, err := primitive.ObjectIDFromHex(senderID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		if err := u.storedFileRepo.ShareWithRoom(sender, url, roomID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
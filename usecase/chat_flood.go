@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+var chatLinkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// checkMessageLimits enforces the size, link and rate limits on a message about to be sent.
+// Flood control fails open, so chat keeps working while Redis is unavailable.
+func (u *chatUsecase) checkMessageLimits(roomID, senderID, messageType, content string) error {
+	logger := utils.NewLogger("ChatUsecase.checkMessageLimits")
+
+	muted, err := u.chatRepo.GetFloodMute(roomID, senderID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+	if muted > 0 {
+		return &domain.ChatLimitError{Code: domain.ChatLimitMuted, Err: domain.ErrChatMuted, RetryAfter: muted}
+	}
+
+	if messageType == "text" {
+		if utf8.RuneCountInString(content) > domain.MaxChatMessageLength {
+			return &domain.ChatLimitError{Code: domain.ChatLimitMessageTooLong, Err: domain.ErrChatMessageTooLong, Limit: domain.MaxChatMessageLength}
+		}
+		if len(chatLinkPattern.FindAllStringIndex(content, domain.MaxChatMessageLinks+1)) > domain.MaxChatMessageLinks {
+			return &domain.ChatLimitError{Code: domain.ChatLimitTooManyLinks, Err: domain.ErrChatTooManyLinks, Limit: domain.MaxChatMessageLinks}
+		}
+	}
+
+	count, err := u.chatRepo.IncrementMessageCount(roomID, senderID, domain.ChatRateLimitWindow)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil
+	}
+	if count <= domain.ChatRateLimitMessages {
+		return nil
+	}
+
+	limitErr := &domain.ChatLimitError{
+		Code:       domain.ChatLimitRateLimited,
+		Err:        domain.ErrChatRateLimited,
+		Limit:      domain.ChatRateLimitMessages,
+		RetryAfter: domain.ChatRateLimitWindow,
+	}
+
+	// Only the first message over the limit in a window is a strike
+	if count != domain.ChatRateLimitMessages+1 {
+		return limitErr
+	}
+	strikes, err := u.chatRepo.AddFloodStrike(roomID, senderID, domain.ChatFloodStrikeWindow)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return limitErr
+	}
+	if strikes < domain.ChatFloodStrikesBeforeMute {
+		return limitErr
+	}
+
+	mute := floodMuteDuration(strikes)
+	if err := u.chatRepo.FloodMute(roomID, senderID, mute); err != nil {
+		logger.LogOutput(nil, err)
+		return limitErr
+	}
+	logger.LogOutput(map[string]interface{}{
+		"roomID":   roomID,
+		"senderID": senderID,
+		"strikes":  strikes,
+		"mute":     mute.String(),
+	}, nil)
+	return &domain.ChatLimitError{Code: domain.ChatLimitMuted, Err: domain.ErrChatMuted, RetryAfter: mute}
+}
+
+// floodMuteDuration doubles the mute for every strike past the threshold, up to the maximum
+func floodMuteDuration(strikes int64) time.Duration {
+	mute := domain.ChatFloodMuteBase
+	for i := int64(domain.ChatFloodStrikesBeforeMute); i < strikes && mute < domain.ChatFloodMuteMax; i++ {
+		mute *= 2
+	}
+	if mute > domain.ChatFloodMuteMax {
+		mute = domain.ChatFloodMuteMax
+	}
+	return mute
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TouchPresence is called when a WebSocket connection opens and on its heartbeats.
+// The user is announced online when this is their first live connection.
+func (u *chatUsecase) TouchPresence(userID, connectionID string) error {
+	logger := utils.NewLogger("ChatUsecase.TouchPresence")
+	logger.LogInput(map[string]interface{}{
+		"userID":       userID,
+		"connectionID": connectionID,
+	})
+
+	cameOnline, err := u.chatRepo.TouchPresence(userID, connectionID, domain.ChatPresenceTTL)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if cameOnline {
+		u.recordPresence(userID, true, time.Now())
+	}
+
+	logger.LogOutput(cameOnline, nil)
+	return nil
+}
+
+// DisconnectPresence is called when a WebSocket connection closes.
+// The user is announced offline when it was their last live connection.
+func (u *chatUsecase) DisconnectPresence(userID, connectionID string) error {
+	logger := utils.NewLogger("ChatUsecase.DisconnectPresence")
+	logger.LogInput(map[string]interface{}{
+		"userID":       userID,
+		"connectionID": connectionID,
+	})
+
+	wentOffline, err := u.chatRepo.RemovePresence(userID, connectionID, domain.ChatPresenceTTL)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if wentOffline {
+		u.recordPresence(userID, false, time.Now())
+	}
+
+	logger.LogOutput(wentOffline, nil)
+	return nil
+}
+
+// ExpirePresence takes users whose connections stopped sending heartbeats offline,
+// e.g. after an instance crashed, and returns how many there were
+func (u *chatUsecase) ExpirePresence() (int, error) {
+	logger := utils.NewLogger("ChatUsecase.ExpirePresence")
+
+	expired, err := u.chatRepo.ExpirePresence(domain.ChatPresenceTTL)
+	for userID, lastSeen := range expired {
+		u.recordPresence(userID, false, lastSeen)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return len(expired), err
+	}
+
+	if len(expired) > 0 {
+		logger.LogOutput(map[string]interface{}{"count": len(expired)}, nil)
+	}
+	return len(expired), nil
+}
+
+// recordPresence stores the status change and broadcasts it. Users who hide their
+// last seen time from anyone are not broadcast, viewers fetch their status instead.
+func (u *chatUsecase) recordPresence(userID string, online bool, at time.Time) {
+	logger := utils.NewLogger("ChatUsecase.recordPresence")
+
+	status := &domain.ChatUserStatus{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: at,
+			UpdatedAt: time.Now(),
+			IsActive:  true,
+			Version:   1,
+		},
+		UserID:   userID,
+		IsOnline: online,
+		LastSeen: &at,
+	}
+	if err := u.chatRepo.UpdateUserStatus(status); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	if u.realtime == nil {
+		return
+	}
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+	if user.LastSeenVisibility != "" && user.LastSeenVisibility != domain.LastSeenVisibilityEveryone {
+		return
+	}
+
+	value := domain.ChatStatusOffline
+	if online {
+		value = domain.ChatStatusOnline
+	}
+	u.realtime.BroadcastUserStatus(userID, value)
+}
+
+// applyPresence takes whether users are online from their live connections,
+// the stored status only keeps when they were last seen
+func (u *chatUsecase) applyPresence(statuses ...*domain.ChatUserStatus) {
+	logger := utils.NewLogger("ChatUsecase.applyPresence")
+
+	userIDs := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		userIDs = append(userIDs, status.UserID)
+	}
+
+	online, err := u.chatRepo.GetPresence(userIDs, domain.ChatPresenceTTL)
+	if err != nil {
+		// Fall back to the stored status
+		logger.LogOutput(nil, err)
+		return
+	}
+	for _, status := range statuses {
+		status.IsOnline = online[status.UserID]
+	}
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// newReplyPreview quotes the message, cutting long content
+func newReplyPreview(message *domain.ChatMessage) *domain.ChatMessagePreview {
+	if message.DeletedForEveryone {
+		return &domain.ChatMessagePreview{ID: message.ID.Hex(), IsDeleted: true}
+	}
+	content := []rune(message.Content)
+	if len(content) > domain.ChatReplyPreviewLength {
+		content = content[:domain.ChatReplyPreviewLength]
+	}
+	return &domain.ChatMessagePreview{
+		ID:       message.ID.Hex(),
+		SenderID: message.SenderID,
+		Type:     message.Type,
+		Content:  string(content),
+		FileType: message.FileType,
+	}
+}
+
+// getReplyTarget loads the message a new message in the room replies to
+func (u *chatUsecase) getReplyTarget(roomID, replyToMessageID string) (*domain.ChatMessage, error) {
+	target, err := u.chatRepo.GetMessage(replyToMessageID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid reply message ID", domain.ErrInvalidInput)
+	}
+	// Thread replies aren't part of the room history, so they can't be quoted there
+	if target == nil || target.RoomID != roomID || target.ThreadID != "" || target.DeletedForEveryone {
+		return nil, fmt.Errorf("%w: the replied message is not in this room", domain.ErrInvalidInput)
+	}
+	return target, nil
+}
+
+// attachReplyPreviews fills in the quoted message of every reply with one query.
+// Quoted messages that no longer exist are previewed as deleted.
+func (u *chatUsecase) attachReplyPreviews(messages []*domain.ChatMessage) error {
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, message := range messages {
+		if id := message.ReplyToMessageID; id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	targets, err := u.chatRepo.GetMessagesByIDs(ids)
+	if err != nil {
+		return err
+	}
+	previews := make(map[string]*domain.ChatMessagePreview, len(targets))
+	for _, target := range targets {
+		previews[target.ID.Hex()] = newReplyPreview(target)
+	}
+
+	for _, message := range messages {
+		if message.ReplyToMessageID == "" {
+			continue
+		}
+		preview, ok := previews[message.ReplyToMessageID]
+		if !ok {
+			preview = &domain.ChatMessagePreview{ID: message.ReplyToMessageID, IsDeleted: true}
+		}
+		message.ReplyTo = preview
+	}
+	return nil
+}
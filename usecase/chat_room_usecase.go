@@ -0,0 +1,515 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxRoomDescriptionLength = 500
+
+// isRoomAdmin reports whether the user can manage the group.
+// Groups created before admins were tracked can be managed by any member.
+func isRoomAdmin(room *domain.ChatRoom, userID string) bool {
+	managers := room.Admins
+	if len(managers) == 0 {
+		managers = room.Members
+	}
+	for _, id := range managers {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// getManagedRoom loads a group room the user administers
+func (u *chatUsecase) getManagedRoom(roomID, userID string) (*domain.ChatRoom, error) {
+	room, err := u.chatRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, domain.NewNotFoundError("room", roomID)
+	}
+	if room.Type != "group" {
+		return nil, fmt.Errorf("%w: only group rooms can be managed", domain.ErrInvalidInput)
+	}
+	if !isRoomAdmin(room, userID) {
+		return nil, domain.ErrForbidden
+	}
+	return room, nil
+}
+
+// getMemberRoom loads a room the user is a member of
+func (u *chatUsecase) getMemberRoom(roomID, userID string) (*domain.ChatRoom, error) {
+	room, err := u.chatRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, domain.NewNotFoundError("room", roomID)
+	}
+	for _, memberID := range room.Members {
+		if memberID == userID {
+			return room, nil
+		}
+	}
+	return nil, domain.ErrForbidden
+}
+
+func (u *chatUsecase) UpdateRoomDetails(roomID, userID string, details domain.ChatRoomDetails) (*domain.ChatRoom, error) {
+	logger := utils.NewLogger("ChatUsecase.UpdateRoomDetails")
+	logger.LogInput(map[string]interface{}{
+		"roomID":  roomID,
+		"userID":  userID,
+		"details": details,
+	})
+
+	room, err := u.getManagedRoom(roomID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	before := *room
+
+	if details.Name != nil {
+		name := strings.TrimSpace(*details.Name)
+		if name == "" {
+			err := fmt.Errorf("%w: room name is required", domain.ErrInvalidInput)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		room.Name = name
+	}
+	if details.Description != nil {
+		description := strings.TrimSpace(*details.Description)
+		if len([]rune(description)) > maxRoomDescriptionLength {
+			err := fmt.Errorf("%w: description must be at most %d characters", domain.ErrInvalidInput, maxRoomDescriptionLength)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		room.Description = description
+	}
+	if details.AvatarURL != nil {
+		if err := u.validateRoomAvatar(*details.AvatarURL); err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		room.AvatarURL = *details.AvatarURL
+	}
+
+	if err := u.chatRepo.UpdateRoom(room); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	for _, event := range roomDetailEvents(userID, &before, room) {
+		u.postSystemMessage(roomID, event)
+	}
+
+	logger.LogOutput(room, nil)
+	return room, nil
+}
+
+// validateRoomAvatar checks that the avatar was uploaded through /upload and is an image.
+// An empty URL removes the avatar.
+func (u *chatUsecase) validateRoomAvatar(avatarURL string) error {
+	if avatarURL == "" {
+		return nil
+	}
+
+	file, err := u.fileRepo.FindByURL(avatarURL)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: avatar file not found", domain.ErrInvalidInput)
+		}
+		return err
+	}
+	if !strings.HasPrefix(file.ContentType, "image/") {
+		return fmt.Errorf("%w: avatar must be an image", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+func (u *chatUsecase) CreateInviteLink(roomID, userID string, expiresIn time.Duration, maxUses int) (*domain.ChatInviteLink, error) {
+	logger := utils.NewLogger("ChatUsecase.CreateInviteLink")
+	logger.LogInput(map[string]interface{}{
+		"roomID":    roomID,
+		"userID":    userID,
+		"expiresIn": expiresIn.String(),
+		"maxUses":   maxUses,
+	})
+
+	if expiresIn == 0 {
+		expiresIn = domain.DefaultChatInviteExpiry
+	}
+	if expiresIn < 0 || expiresIn > domain.MaxChatInviteExpiry || maxUses < 0 {
+		err := fmt.Errorf("%w: invite must expire within %s and have a non-negative use limit", domain.ErrInvalidInput, domain.MaxChatInviteExpiry)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	room, err := u.getManagedRoom(roomID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	invite := &domain.ChatInvite{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			IsActive:  true,
+			Version:   1,
+		},
+		RoomID:    room.ID.Hex(),
+		CreatedBy: userID,
+		ExpiresAt: now.Add(expiresIn),
+		MaxUses:   maxUses,
+	}
+
+	token, err := u.jwtKeys.Sign(domain.ChatInviteClaims{
+		InviteID: invite.ID.Hex(),
+		RoomID:   invite.RoomID,
+		Type:     domain.TokenTypeChatInvite,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(invite.ExpiresAt),
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.chatRepo.SaveInvite(invite); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	link := &domain.ChatInviteLink{
+		Invite: invite,
+		Token:  token,
+	}
+	logger.LogOutput(invite, nil)
+	return link, nil
+}
+
+func (u *chatUsecase) GetRoomInvites(roomID, userID string) ([]*domain.ChatInvite, error) {
+	logger := utils.NewLogger("ChatUsecase.GetRoomInvites")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+	})
+
+	if _, err := u.getManagedRoom(roomID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	invites, err := u.chatRepo.GetRoomInvites(roomID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(invites, nil)
+	return invites, nil
+}
+
+func (u *chatUsecase) RevokeInvite(roomID, inviteID, userID string) error {
+	logger := utils.NewLogger("ChatUsecase.RevokeInvite")
+	logger.LogInput(map[string]interface{}{
+		"roomID":   roomID,
+		"inviteID": inviteID,
+		"userID":   userID,
+	})
+
+	if _, err := u.getManagedRoom(roomID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	invite, err := u.chatRepo.GetInvite(inviteID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if invite.RoomID != roomID {
+		err := domain.NewNotFoundError("invite", inviteID)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := u.chatRepo.RevokeInvite(inviteID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// JoinByInvite adds the user to the group behind an invite link token
+func (u *chatUsecase) JoinByInvite(token, userID string) (*domain.ChatRoom, error) {
+	logger := utils.NewLogger("ChatUsecase.JoinByInvite")
+	logger.LogInput(userID)
+
+	claims := &domain.ChatInviteClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, u.jwtKeys.Keyfunc)
+	if err != nil || !parsed.Valid || claims.Type != domain.TokenTypeChatInvite {
+		logger.LogOutput(nil, domain.ErrInvalidChatInvite)
+		return nil, domain.ErrInvalidChatInvite
+	}
+
+	room, err := u.chatRepo.GetRoom(claims.RoomID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if room == nil || room.Type != "group" {
+		logger.LogOutput(nil, domain.ErrInvalidChatInvite)
+		return nil, domain.ErrInvalidChatInvite
+	}
+
+	// Opening the link again doesn't use up the invite
+	for _, memberID := range room.Members {
+		if memberID == userID {
+			logger.LogOutput(room, nil)
+			return room, nil
+		}
+	}
+
+	ok, err := u.chatRepo.UseInvite(claims.InviteID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if !ok {
+		logger.LogOutput(nil, domain.ErrInvalidChatInvite)
+		return nil, domain.ErrInvalidChatInvite
+	}
+
+	room.Members = append(room.Members, userID)
+	if err := u.chatRepo.UpdateRoom(room); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	u.postSystemMessage(room.ID.Hex(), domain.ChatSystemEvent{
+		Event:   domain.ChatSystemMemberJoined,
+		ActorID: userID,
+		UserID:  userID,
+	})
+
+	logger.LogOutput(room, nil)
+	return room, nil
+}
+
+// SetTyping records whether the user is typing in the room
+func (u *chatUsecase) SetTyping(roomID, userID string, typing bool) error {
+	logger := utils.NewLogger("ChatUsecase.SetTyping")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+		"typing": typing,
+	})
+
+	if _, err := u.getMemberRoom(roomID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := u.chatRepo.SetTyping(roomID, userID, typing, domain.ChatTypingTTL); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// GetTypingUsers returns who is currently typing in the room, without the viewer
+func (u *chatUsecase) GetTypingUsers(roomID, userID string) ([]string, error) {
+	logger := utils.NewLogger("ChatUsecase.GetTypingUsers")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+	})
+
+	if _, err := u.getMemberRoom(roomID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	typing, err := u.chatRepo.GetTypingUsers(roomID, domain.ChatTypingTTL)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(typing))
+	for _, id := range typing {
+		if id != userID {
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	logger.LogOutput(userIDs, nil)
+	return userIDs, nil
+}
+
+// defaultRoomSettings are the settings of a member who never changed them
+func defaultRoomSettings(roomID, userID string) *domain.ChatRoomSettings {
+	return &domain.ChatRoomSettings{
+		RoomID:            roomID,
+		UserID:            userID,
+		NotificationLevel: domain.ChatNotifyAll,
+	}
+}
+
+func (u *chatUsecase) GetRoomSettings(roomID, userID string) (*domain.ChatRoomSettings, error) {
+	logger := utils.NewLogger("ChatUsecase.GetRoomSettings")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+	})
+
+	if _, err := u.getMemberRoom(roomID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	settings, err := u.chatRepo.GetRoomSettings(roomID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if settings == nil {
+		settings = defaultRoomSettings(roomID, userID)
+	}
+
+	logger.LogOutput(settings, nil)
+	return settings, nil
+}
+
+// UpdateRoomSettings changes the member's mute and notification settings for the room.
+// Setting muteUntil mutes the room until then, unmuting clears it.
+func (u *chatUsecase) UpdateRoomSettings(roomID, userID string, update domain.ChatRoomSettingsUpdate) (*domain.ChatRoomSettings, error) {
+	logger := utils.NewLogger("ChatUsecase.UpdateRoomSettings")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+		"update": update,
+	})
+
+	if update.MuteUntil != nil && !update.MuteUntil.After(time.Now()) {
+		err := fmt.Errorf("%w: muteUntil must be in the future", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if update.MuteUntil != nil && update.Muted != nil && !*update.Muted {
+		err := fmt.Errorf("%w: muteUntil can't be set when unmuting", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if update.NotificationLevel != nil && !domain.IsValidChatNotifyLevel(*update.NotificationLevel) {
+		err := fmt.Errorf("%w: notificationLevel must be all, mentions or none", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	settings, err := u.GetRoomSettings(roomID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if update.Muted != nil {
+		settings.Muted = *update.Muted
+		settings.MuteUntil = nil
+	}
+	if update.MuteUntil != nil {
+		settings.Muted = true
+		settings.MuteUntil = update.MuteUntil
+	}
+	if update.NotificationLevel != nil {
+		settings.NotificationLevel = *update.NotificationLevel
+	}
+
+	if err := u.chatRepo.UpsertRoomSettings(settings); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(settings, nil)
+	return settings, nil
+}
+
+// notificationRecipients returns the members other than the sender who want to be notified
+// of the message. Members who muted the room, turned notifications off, or only want
+// mentions of messages not mentioning them are left out. Settings that fail to load
+// don't stop the message, everyone is notified instead.
+func (u *chatUsecase) notificationRecipients(room *domain.ChatRoom, message *domain.ChatMessage) []string {
+	logger := utils.NewLogger("ChatUsecase.notificationRecipients")
+
+	others := make([]string, 0, len(room.Members))
+	for _, memberID := range room.Members {
+		if memberID != message.SenderID {
+			others = append(others, memberID)
+		}
+	}
+	if len(others) == 0 {
+		return others
+	}
+
+	settingsList, err := u.chatRepo.GetRoomSettingsForUsers(message.RoomID, others)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return others
+	}
+	settingsByUser := make(map[string]*domain.ChatRoomSettings, len(settingsList))
+	for _, settings := range settingsList {
+		settingsByUser[settings.UserID] = settings
+	}
+
+	var mentioned map[string]bool
+	now := time.Now()
+	recipients := make([]string, 0, len(others))
+	for _, memberID := range others {
+		settings, ok := settingsByUser[memberID]
+		if !ok {
+			recipients = append(recipients, memberID)
+			continue
+		}
+		if settings.IsMuted(now) {
+			continue
+		}
+		switch settings.NotificationLevel {
+		case domain.ChatNotifyNone:
+			continue
+		case domain.ChatNotifyMentions:
+			if mentioned == nil {
+				mentioned = make(map[string]bool)
+				for _, username := range utils.ExtractMentions(message.Content) {
+					mentioned[strings.ToLower(username)] = true
+				}
+			}
+			member, err := u.userRepo.FindByID(memberID)
+			if err != nil || member == nil || !mentioned[strings.ToLower(member.Username)] {
+				continue
+			}
+		}
+		recipients = append(recipients, memberID)
+	}
+	return recipients
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (u *chatUsecase) SendStoryReply(senderID string, story domain.ChatStoryRef, content string) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatUsecase.SendStoryReply")
+	logger.LogInput(map[string]interface{}{
+		"senderID": senderID,
+		"storyID":  story.StoryID,
+		"content":  content,
+	})
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		err := fmt.Errorf("%w: content is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := checkCanPublish(u.userRepo, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	room, err := u.CreatePrivateChat(senderID, story.OwnerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if err := u.checkCanMessage(room, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	roomID := room.ID.Hex()
+
+	if err := u.checkMessageLimits(roomID, senderID, "text", content); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	message := &domain.ChatMessage{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			IsActive:  true,
+			Version:   1,
+		},
+		RoomID:   roomID,
+		SenderID: senderID,
+		Type:     "text",
+		Content:  content,
+		ReadBy:   []string{senderID},
+		Story:    &story,
+	}
+
+	if err := u.deliverMessage(room, message, "Replied to your story"); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	u.sendAutoReply(room, message)
+
+	logger.LogOutput(message, nil)
+	return message, nil
+}
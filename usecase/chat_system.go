@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// postSystemMessage records a room change in the room history and publishes it to
+// the room. System messages aren't notified or pushed and don't count as unread.
+// The change is already saved, so failures are only logged.
+func (u *chatUsecase) postSystemMessage(roomID string, event domain.ChatSystemEvent) {
+	logger := utils.NewLogger("ChatUsecase.postSystemMessage")
+	logger.LogInput(roomID, event)
+
+	message := &domain.ChatMessage{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			IsActive:  true,
+			Version:   1,
+		},
+		RoomID:   roomID,
+		SenderID: event.ActorID,
+		Type:     domain.ChatMessageTypeSystem,
+		ReadBy:   []string{event.ActorID},
+		System:   &event,
+	}
+	if err := u.chatRepo.SaveMessage(message); err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	if u.realtime != nil {
+		if err := u.realtime.PublishToRoom(roomID, domain.RealtimeEventSystemMessage, message); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
+	logger.LogOutput(message.ID, nil)
+}
+
+// roomDetailEvents lists the system events of a room details update, one per changed detail
+func roomDetailEvents(actorID string, before, after *domain.ChatRoom) []domain.ChatSystemEvent {
+	events := make([]domain.ChatSystemEvent, 0)
+	if before.Name != after.Name {
+		events = append(events, domain.ChatSystemEvent{
+			Event:    domain.ChatSystemRoomRenamed,
+			ActorID:  actorID,
+			OldValue: before.Name,
+			NewValue: after.Name,
+		})
+	}
+	if before.Description != after.Description {
+		events = append(events, domain.ChatSystemEvent{
+			Event:    domain.ChatSystemDescriptionChanged,
+			ActorID:  actorID,
+			OldValue: before.Description,
+			NewValue: after.Description,
+		})
+	}
+	if before.AvatarURL != after.AvatarURL {
+		events = append(events, domain.ChatSystemEvent{
+			Event:    domain.ChatSystemAvatarChanged,
+			ActorID:  actorID,
+			OldValue: before.AvatarURL,
+			NewValue: after.AvatarURL,
+		})
+	}
+	return events
+}
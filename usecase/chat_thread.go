@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// getThreadRoot loads the root of a thread along with its room, checking the
+// user is a member of the group
+func (u *chatUsecase) getThreadRoot(threadID, userID string) (*domain.ChatMessage, *domain.ChatRoom, error) {
+	if !primitive.IsValidObjectID(threadID) {
+		return nil, nil, domain.ErrInvalidID
+	}
+
+	root, err := u.chatRepo.GetMessage(threadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root == nil {
+		return nil, nil, domain.NewNotFoundError("message", threadID)
+	}
+	if root.ThreadID != "" {
+		return nil, nil, fmt.Errorf("%w: thread replies can't start a thread", domain.ErrInvalidInput)
+	}
+
+	room, err := u.getMemberRoom(root.RoomID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if room.Type != "group" {
+		return nil, nil, fmt.Errorf("%w: threads are only available in group rooms", domain.ErrInvalidInput)
+	}
+	return root, room, nil
+}
+
+// SendThreadReply adds a reply to the thread of a group message. The reply stays
+// out of the room history, only the thread's participants are notified.
+func (u *chatUsecase) SendThreadReply(threadID, senderID, content string) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatUsecase.SendThreadReply")
+	logger.LogInput(map[string]interface{}{
+		"threadID": threadID,
+		"senderID": senderID,
+		"content":  content,
+	})
+
+	root, room, err := u.getThreadRoot(threadID, senderID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if root.DeletedForEveryone {
+		err = fmt.Errorf("%w: the message was deleted", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := checkCanPublish(u.userRepo, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.checkMessageLimits(root.RoomID, senderID, "text", content); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	message := &domain.ChatMessage{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			IsActive:  true,
+			Version:   1,
+		},
+		RoomID:   root.RoomID,
+		SenderID: senderID,
+		Type:     "text",
+		Content:  content,
+		ReadBy:   []string{senderID},
+		ThreadID: threadID,
+	}
+	if err := u.chatRepo.SaveMessage(message); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	root, err = u.chatRepo.AddThreadReply(threadID, []string{root.SenderID, senderID}, message.CreatedAt)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.notifyThread(room, root, message); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	u.events.Publish(domain.DomainEventMessageSent, domain.MessageSentPayload{
+		MessageID: message.ID.Hex(),
+		RoomID:    message.RoomID,
+		SenderID:  message.SenderID,
+		Type:      message.Type,
+		SentAt:    message.CreatedAt,
+	})
+
+	logger.LogOutput(message, nil)
+	return message, nil
+}
+
+// notifyThread sends the reply to the participants still in the room, and
+// notifies the ones whose room settings let the message through
+func (u *chatUsecase) notifyThread(room *domain.ChatRoom, root, message *domain.ChatMessage) error {
+	logger := utils.NewLogger("ChatUsecase.notifyThread")
+
+	members := make(map[string]bool, len(room.Members))
+	for _, memberID := range room.Members {
+		members[memberID] = true
+	}
+	participants := make(map[string]bool, len(root.Thread.Participants))
+	for _, participantID := range root.Thread.Participants {
+		participants[participantID] = members[participantID]
+	}
+
+	recipients := make([]string, 0, len(participants))
+	for _, memberID := range u.notificationRecipients(room, message) {
+		if participants[memberID] {
+			recipients = append(recipients, memberID)
+		}
+	}
+	if err := u.fanOutMessage(message, "thread_reply", "New reply in a thread", recipients); err != nil {
+		return err
+	}
+
+	if u.realtime == nil {
+		return nil
+	}
+	activity := domain.ChatThreadActivity{
+		RoomID:      message.RoomID,
+		ThreadID:    message.ThreadID,
+		Message:     message,
+		ReplyCount:  root.Thread.ReplyCount,
+		LastReplyAt: root.Thread.LastReplyAt,
+	}
+	// The sender gets it too, to sync their other devices
+	for participantID, isMember := range participants {
+		if !isMember {
+			continue
+		}
+		if err := u.realtime.SendToUser(participantID, domain.RealtimeEventThreadActivity, activity); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+	return nil
+}
+
+func (u *chatUsecase) GetThread(threadID, viewerID string, limit, offset int) (*domain.ChatThread, error) {
+	logger := utils.NewLogger("ChatUsecase.GetThread")
+	logger.LogInput(map[string]interface{}{
+		"threadID": threadID,
+		"viewerID": viewerID,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	root, _, err := u.getThreadRoot(threadID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	replies, err := u.chatRepo.GetThreadReplies(threadID, viewerID, int64(limit), int64(offset))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.hideReadReceipts(append([]*domain.ChatMessage{root}, replies...), viewerID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	unread, err := u.chatRepo.CountThreadUnread(threadID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	thread := &domain.ChatThread{
+		Root:        root,
+		Replies:     replies,
+		UnreadCount: unread,
+	}
+	logger.LogOutput(map[string]interface{}{"replies": len(replies), "unread": unread}, nil)
+	return thread, nil
+}
+
+func (u *chatUsecase) MarkThreadRead(threadID, userID string) (*domain.ChatThreadReadResult, error) {
+	logger := utils.NewLogger("ChatUsecase.MarkThreadRead")
+	logger.LogInput(threadID, userID)
+
+	if _, _, err := u.getThreadRoot(threadID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	marked, err := u.chatRepo.MarkThreadAsRead(threadID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	result := &domain.ChatThreadReadResult{
+		ThreadID:    threadID,
+		MarkedCount: marked,
+	}
+	logger.LogOutput(result, nil)
+	return result, nil
+}
@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
@@ -12,14 +14,45 @@ import (
 type chatUsecase struct {
 	chatRepo         domain.ChatRepository
 	userRepo         domain.UserRepository
+	friendshipRepo   domain.FriendshipRepository
 	notificationUsecase domain.NotificationUseCase
+	deviceUsecase    domain.DeviceUseCase
+	fileRepo         domain.FileRepository
+	jwtKeys          *domain.JWTKeySet
+	realtime         domain.RealtimePublisher
+	events           domain.DomainEventPublisher
+	followUseCase    domain.FollowUseCase
+	privacyUseCase   domain.PrivacyUseCase
+	storedFileRepo   domain.StoredFileRepository
 }
 
-func NewChatUsecase(chatRepo domain.ChatRepository, userRepo domain.UserRepository, notificationUsecase domain.NotificationUseCase) domain.ChatUsecase {
+func NewChatUsecase(
+	chatRepo domain.ChatRepository,
+	userRepo domain.UserRepository,
+	friendshipRepo domain.FriendshipRepository,
+	notificationUsecase domain.NotificationUseCase,
+	deviceUsecase domain.DeviceUseCase,
+	fileRepo domain.FileRepository,
+	jwtKeys *domain.JWTKeySet,
+	realtime domain.RealtimePublisher,
+	events domain.DomainEventPublisher,
+	followUseCase domain.FollowUseCase,
+	privacyUseCase domain.PrivacyUseCase,
+	storedFileRepo domain.StoredFileRepository,
+) domain.ChatUsecase {
 	return &chatUsecase{
 		chatRepo:         chatRepo,
 		userRepo:         userRepo,
+		friendshipRepo:   friendshipRepo,
 		notificationUsecase: notificationUsecase,
+		deviceUsecase:    deviceUsecase,
+		fileRepo:         fileRepo,
+		jwtKeys:          jwtKeys,
+		realtime:         realtime,
+		events:           events,
+		followUseCase:    followUseCase,
+		privacyUseCase:   privacyUseCase,
+		storedFileRepo:   storedFileRepo,
 	}
 }
 
@@ -61,6 +94,17 @@ func (u *chatUsecase) CreatePrivateChat(userID1 string, userID2 string) (*domain
 		}
 	}
 
+	// Existing rooms keep their history, but no new room is opened across a block
+	// or to someone whose privacy settings keep the user out
+	if err := u.checkNotBlocked(userID1, userID2); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if err := u.checkPrivacy(userID1, userID2); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	// Get user details for new room
 	user1, err := u.userRepo.GetUserByID(userID1)
 	if err != nil {
@@ -99,13 +143,26 @@ func (u *chatUsecase) CreatePrivateChat(userID1 string, userID2 string) (*domain
 	return room, nil
 }
 
-func (u *chatUsecase) CreateGroupChat(name string, memberIDs []string) (*domain.ChatRoom, error) {
+func (u *chatUsecase) CreateGroupChat(creatorID, name string, memberIDs []string) (*domain.ChatRoom, error) {
 	logger := utils.NewLogger("ChatUsecase.CreateGroupChat")
 	logger.LogInput(map[string]interface{}{
+		"creatorID": creatorID,
 		"name":      name,
 		"memberIDs": memberIDs,
 	})
 
+	// The creator is always a member and the first admin
+	isMember := false
+	for _, memberID := range memberIDs {
+		if memberID == creatorID {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		memberIDs = append([]string{creatorID}, memberIDs...)
+	}
+
 	room := &domain.ChatRoom{
 		BaseModel: domain.BaseModel{
 			ID:        primitive.NewObjectID(),
@@ -117,6 +174,7 @@ func (u *chatUsecase) CreateGroupChat(name string, memberIDs []string) (*domain.
 		Name:    name,
 		Type:    "group",
 		Members: memberIDs,
+		Admins:  []string{creatorID},
 	}
 
 	// Save room
@@ -161,11 +219,12 @@ func (u *chatUsecase) GetUserChats(userID string) ([]*domain.ChatRoom, error) {
 	return rooms, nil
 }
 
-func (u *chatUsecase) AddMemberToGroup(roomID string, userID string) error {
+func (u *chatUsecase) AddMemberToGroup(roomID, actorID, userID string) error {
 	logger := utils.NewLogger("ChatUsecase.AddMemberToGroup")
 	logger.LogInput(map[string]interface{}{
-		"roomID": roomID,
-		"userID": userID,
+		"roomID":  roomID,
+		"actorID": actorID,
+		"userID":  userID,
 	})
 
 	room, err := u.chatRepo.GetRoom(roomID)
@@ -185,15 +244,22 @@ func (u *chatUsecase) AddMemberToGroup(roomID string, userID string) error {
 		return err
 	}
 
+	u.postSystemMessage(roomID, domain.ChatSystemEvent{
+		Event:   domain.ChatSystemMemberAdded,
+		ActorID: actorID,
+		UserID:  userID,
+	})
+
 	logger.LogOutput(nil, nil)
 	return nil
 }
 
-func (u *chatUsecase) RemoveMemberFromGroup(roomID string, userID string) error {
+func (u *chatUsecase) RemoveMemberFromGroup(roomID, actorID, userID string) error {
 	logger := utils.NewLogger("ChatUsecase.RemoveMemberFromGroup")
 	logger.LogInput(map[string]interface{}{
-		"roomID": roomID,
-		"userID": userID,
+		"roomID":  roomID,
+		"actorID": actorID,
+		"userID":  userID,
 	})
 
 	room, err := u.chatRepo.GetRoom(roomID)
@@ -213,6 +279,16 @@ func (u *chatUsecase) RemoveMemberFromGroup(roomID string, userID string) error
 		return err
 	}
 
+	event := domain.ChatSystemEvent{
+		Event:   domain.ChatSystemMemberRemoved,
+		ActorID: actorID,
+		UserID:  userID,
+	}
+	if actorID == userID {
+		event.Event = domain.ChatSystemMemberLeft
+	}
+	u.postSystemMessage(roomID, event)
+
 	logger.LogOutput(nil, nil)
 	return nil
 }
@@ -286,13 +362,14 @@ func (u *chatUsecase) UpdateRoom(room *domain.ChatRoom) error {
 }
 
 // Message operations
-func (u *chatUsecase) SendMessage(roomID string, senderID string, messageType string, content string) (*domain.ChatMessage, error) {
+func (u *chatUsecase) SendMessage(roomID string, senderID string, messageType string, content string, replyToMessageID string) (*domain.ChatMessage, error) {
 	logger := utils.NewLogger("ChatUsecase.SendMessage")
 	logger.LogInput(map[string]interface{}{
-		"roomID":      roomID,
-		"senderID":    senderID,
-		"messageType": messageType,
-		"content":     content,
+		"roomID":           roomID,
+		"senderID":         senderID,
+		"messageType":      messageType,
+		"content":          content,
+		"replyToMessageID": replyToMessageID,
 	})
 
 	// Validate roomID
@@ -328,6 +405,30 @@ func (u *chatUsecase) SendMessage(roomID string, senderID string, messageType st
 		return nil, err
 	}
 
+	if err := u.checkCanMessage(room, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := checkCanPublish(u.userRepo, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var replyTo *domain.ChatMessage
+	if replyToMessageID != "" {
+		replyTo, err = u.getReplyTarget(roomID, replyToMessageID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	if err := u.checkMessageLimits(roomID, senderID, messageType, content); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	message := &domain.ChatMessage{
 		BaseModel: domain.BaseModel{
 			ID:        primitive.NewObjectID(),
@@ -342,54 +443,78 @@ func (u *chatUsecase) SendMessage(roomID string, senderID string, messageType st
 		Content:  content,
 		ReadBy:   []string{senderID},
 	}
+	if replyTo != nil {
+		message.ReplyToMessageID = replyToMessageID
+		message.ReplyTo = newReplyPreview(replyTo)
+	}
 
-	if err := u.chatRepo.SaveMessage(message); err != nil {
+	if err := u.deliverMessage(room, message, "New message received"); err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
-	// Create notifications for all other members
-	for _, memberID := range room.Members {
-		if memberID == senderID {
-			continue
-		}
+	u.sendAutoReply(room, message)
 
-		notification, err := u.CreateNotification(memberID, "new_message", roomID, message.ID.Hex())
-		if err != nil {
-			logger.LogOutput(nil, err)
-			return nil, err
-		}
+	logger.LogOutput(message, nil)
+	return message, nil
+}
 
-		notification.Message = "New message received"
+// deliverMessage saves a text message, then notifies and pushes it to the other
+// members who want it
+func (u *chatUsecase) deliverMessage(room *domain.ChatRoom, message *domain.ChatMessage, notice string) error {
+	if err := u.chatRepo.SaveMessage(message); err != nil {
+		return err
+	}
 
-		if err := u.chatRepo.SaveNotification(notification); err != nil {
-			logger.LogOutput(nil, err)
-			return nil, err
-		}
+	if err := u.fanOutMessage(message, "new_message", notice, u.notificationRecipients(room, message)); err != nil {
+		return err
 	}
 
-	logger.LogOutput(message, nil)
-	return message, nil
+	u.events.Publish(domain.DomainEventMessageSent, domain.MessageSentPayload{
+		MessageID: message.ID.Hex(),
+		RoomID:    message.RoomID,
+		SenderID:  message.SenderID,
+		Type:      message.Type,
+		SentAt:    message.CreatedAt,
+	})
+	return nil
 }
 
-func (u *chatUsecase) SendFileMessage(roomID string, senderID string, fileType string, fileSize int64, fileURL string) (*domain.ChatMessage, error) {
+func (u *chatUsecase) SendFileMessage(roomID string, senderID string, file domain.ChatFileMessage) (*domain.ChatMessage, error) {
 	logger := utils.NewLogger("ChatUsecase.SendFileMessage")
 	logger.LogInput(map[string]interface{}{
 		"roomID":   roomID,
 		"senderID": senderID,
-		"fileType": fileType,
-		"fileSize": fileSize,
-		"fileURL":  fileURL,
+		"file":     file,
 	})
 
-	if fileSize > 10*1024*1024 { // 10MB limit
-		err := fmt.Errorf("file size exceeds 10MB limit")
+	if err := validateChatFile(&file); err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
-	if fileType != "jpg" && fileType != "png" && fileType != "gif" {
-		err := fmt.Errorf("unsupported file type: %s", fileType)
+	room, err := u.getMemberRoom(roomID, senderID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if err := u.checkCanMessage(room, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := checkCanPublish(u.userRepo, senderID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.checkMessageLimits(roomID, senderID, "file", ""); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Private uploads become downloadable by the room's members
+	if err := u.shareFileWithRoom(senderID, roomID, file.FileURL, file.ThumbnailURL); err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
@@ -404,11 +529,13 @@ func (u *chatUsecase) SendFileMessage(roomID string, senderID string, fileType s
 		},
 		RoomID:   roomID,
 		SenderID: senderID,
-		Type:     "file",
-		FileURL:  fileURL,
-		FileType: fileType,
-		FileSize: fileSize,
-		ReadBy:   []string{senderID},
+		Type:         "file",
+		FileURL:      file.FileURL,
+		FileType:     file.FileType,
+		FileSize:     file.FileSize,
+		Duration:     file.Duration,
+		ThumbnailURL: file.ThumbnailURL,
+		ReadBy:       []string{senderID},
 	}
 
 	if err := u.chatRepo.SaveMessage(message); err != nil {
@@ -417,49 +544,51 @@ func (u *chatUsecase) SendFileMessage(roomID string, senderID string, fileType s
 	}
 
 	// Create notifications for other members (similar to text message)
-	room, err := u.chatRepo.GetRoom(roomID)
-	if err != nil {
+	if err := u.fanOutMessage(message, "new_message", "New file received", u.notificationRecipients(room, message)); err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
-	for _, memberID := range room.Members {
-		if memberID == senderID {
-			continue
-		}
-
-		notification, err := u.CreateNotification(memberID, "new_message", roomID, message.ID.Hex())
-		if err != nil {
-			logger.LogOutput(nil, err)
-			return nil, err
-		}
-
-		notification.Message = "New file received"
+	u.events.Publish(domain.DomainEventMessageSent, domain.MessageSentPayload{
+		MessageID: message.ID.Hex(),
+		RoomID:    message.RoomID,
+		SenderID:  message.SenderID,
+		Type:      message.Type,
+		SentAt:    message.CreatedAt,
+	})
 
-		if err := u.chatRepo.SaveNotification(notification); err != nil {
-			logger.LogOutput(nil, err)
-			return nil, err
-		}
-	}
+	u.sendAutoReply(room, message)
 
 	logger.LogOutput(message, nil)
 	return message, nil
 }
 
-func (u *chatUsecase) GetChatMessages(roomID string, limit int, offset int) ([]*domain.ChatMessage, error) {
+func (u *chatUsecase) GetChatMessages(roomID, viewerID string, afterSeq int64, limit int, offset int) ([]*domain.ChatMessage, error) {
 	logger := utils.NewLogger("ChatUsecase.GetChatMessages")
 	logger.LogInput(map[string]interface{}{
-		"roomID": roomID,
-		"limit":  limit,
-		"offset": offset,
+		"roomID":   roomID,
+		"viewerID": viewerID,
+		"afterSeq": afterSeq,
+		"limit":    limit,
+		"offset":   offset,
 	})
 
-	messages, err := u.chatRepo.GetRoomMessages(roomID, int64(limit), int64(offset))
+	messages, err := u.chatRepo.GetRoomMessages(roomID, viewerID, afterSeq, int64(limit), int64(offset))
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
+	if err := u.hideReadReceipts(messages, viewerID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.attachReplyPreviews(messages); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	logger.LogOutput(messages, nil)
 	return messages, nil
 }
@@ -476,13 +605,103 @@ func (u *chatUsecase) MarkMessageRead(messageID string, userID string) error {
 		return err
 	}
 
+	u.publishReadReceipt(messageID, userID)
+
 	logger.LogOutput(nil, nil)
 	return nil
 }
 
-func (u *chatUsecase) DeleteMessage(messageID string) error {
+// MarkRoomRead marks every message in the room sent up to upTo as read in one update
+// and returns the unread counts of all the user's rooms
+func (u *chatUsecase) MarkRoomRead(roomID, userID string, upTo time.Time) (*domain.ChatRoomReadResult, error) {
+	logger := utils.NewLogger("ChatUsecase.MarkRoomRead")
+	logger.LogInput(map[string]interface{}{
+		"roomID": roomID,
+		"userID": userID,
+		"upTo":   upTo,
+	})
+
+	if _, err := u.getMemberRoom(roomID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Messages can't be read before they are sent
+	if now := time.Now(); upTo.IsZero() || upTo.After(now) {
+		upTo = now
+	}
+
+	marked, err := u.chatRepo.MarkRoomAsRead(roomID, userID, upTo)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	unread, err := u.GetUnreadCounts(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if marked > 0 {
+		u.publishRoomRead(roomID, userID, upTo)
+	}
+
+	result := &domain.ChatRoomReadResult{
+		RoomID:       roomID,
+		MarkedCount:  marked,
+		UnreadCounts: unread,
+	}
+	logger.LogOutput(result, nil)
+	return result, nil
+}
+
+// GetUnreadCounts returns the number of unread messages in each of the user's rooms,
+// counted by one aggregation over all rooms
+func (u *chatUsecase) GetUnreadCounts(userID string) (map[string]int64, error) {
+	logger := utils.NewLogger("ChatUsecase.GetUnreadCounts")
+	logger.LogInput(userID)
+
+	rooms, err := u.chatRepo.GetRoomsByUser(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	roomIDs := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		roomIDs = append(roomIDs, room.ID.Hex())
+	}
+
+	unread, err := u.chatRepo.CountUnreadByRoom(userID, roomIDs)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	for _, id := range roomIDs {
+		if _, ok := unread[id]; !ok {
+			unread[id] = 0
+		}
+	}
+
+	logger.LogOutput(unread, nil)
+	return unread, nil
+}
+
+// DeleteMessage hides the message from the user, or with ChatDeleteForEveryone replaces it
+// with a placeholder for the whole room. Only the sender or an admin can delete for everyone.
+func (u *chatUsecase) DeleteMessage(messageID string, userID string, scope string) error {
 	logger := utils.NewLogger("ChatUsecase.DeleteMessage")
-	logger.LogInput(messageID)
+	logger.LogInput(map[string]interface{}{
+		"messageID": messageID,
+		"userID":    userID,
+		"scope":     scope,
+	})
+
+	if scope != domain.ChatDeleteForMe && scope != domain.ChatDeleteForEveryone {
+		err := fmt.Errorf("%w: scope must be me or all", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return err
+	}
 
 	// Check if message exists
 	message, err := u.chatRepo.GetMessage(messageID)
@@ -491,22 +710,119 @@ func (u *chatUsecase) DeleteMessage(messageID string) error {
 		return err
 	}
 	if message == nil {
-		err := fmt.Errorf("message not found")
+		err := domain.NewNotFoundError("message", messageID)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if scope == domain.ChatDeleteForMe {
+		if _, err := u.getMemberRoom(message.RoomID, userID); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+		if err := u.chatRepo.DeleteMessageForUser(messageID, userID); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	// System messages can only be deleted for oneself
+	if message.Type == domain.ChatMessageTypeSystem {
+		logger.LogOutput(nil, domain.ErrForbidden)
+		return domain.ErrForbidden
+	}
+
+	// Only the sender or an admin can delete a message for everyone
+	if err := authorizeOwnerOrAdmin(u.userRepo, userID, message.SenderID); err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
 
-	// Delete message
-	err = u.chatRepo.DeleteMessage(messageID)
+	message, err = u.chatRepo.DeleteMessageForEveryone(messageID)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
 
+	if u.realtime != nil {
+		if err := u.realtime.PublishToRoom(message.RoomID, domain.RealtimeEventMessageDeleted, message); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
 	logger.LogOutput(nil, nil)
 	return nil
 }
 
+// EditMessage lets the sender change the content of a text message.
+// The previous content is kept in the edit history and the room is told about the edit.
+func (u *chatUsecase) EditMessage(messageID, userID, content string) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("ChatUsecase.EditMessage")
+	logger.LogInput(map[string]interface{}{
+		"messageID": messageID,
+		"userID":    userID,
+		"content":   content,
+	})
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		err := fmt.Errorf("%w: content is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if !primitive.IsValidObjectID(messageID) {
+		logger.LogOutput(nil, domain.ErrInvalidID)
+		return nil, domain.ErrInvalidID
+	}
+
+	message, err := u.chatRepo.GetMessage(messageID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if message == nil || message.DeletedForEveryone {
+		err := domain.NewNotFoundError("message", messageID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Unlike deletes, admins can't put words in someone else's mouth
+	if message.SenderID != userID {
+		logger.LogOutput(nil, domain.ErrForbidden)
+		return nil, domain.ErrForbidden
+	}
+	if message.Type != "text" {
+		err := fmt.Errorf("%w: only text messages can be edited", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if message.Content == content {
+		logger.LogOutput(message, nil)
+		return message, nil
+	}
+
+	message, err = u.chatRepo.EditMessage(messageID, content, domain.ChatMessageEdit{
+		Content:  message.Content,
+		EditedAt: time.Now(),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if u.realtime != nil {
+		if err := u.realtime.PublishToRoom(message.RoomID, domain.RealtimeEventMessageEdited, message); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
+	logger.LogOutput(message, nil)
+	return message, nil
+}
+
 func (u *chatUsecase) GetUnreadMessages(roomID string, userID string) ([]*domain.ChatMessage, error) {
 	logger := utils.NewLogger("ChatUsecase.GetUnreadMessages")
 	logger.LogInput(map[string]interface{}{
@@ -554,6 +870,9 @@ func (u *chatUsecase) GetNotification(notificationID string) (*domain.ChatNotifi
 }
 
 // User status operations
+
+// UpdateUserOnlineStatus stores a status reported by the client. Clients connected to the
+// WebSocket don't need it, their presence is tracked from the connection, see TouchPresence.
 func (u *chatUsecase) UpdateUserOnlineStatus(userID string, isOnline bool) error {
 	logger := utils.NewLogger("ChatUsecase.UpdateUserOnlineStatus")
 	logger.LogInput(map[string]interface{}{
@@ -561,17 +880,18 @@ func (u *chatUsecase) UpdateUserOnlineStatus(userID string, isOnline bool) error
 		"isOnline": isOnline,
 	})
 
+	now := time.Now()
 	status := &domain.ChatUserStatus{
 		BaseModel: domain.BaseModel{
 			ID:        primitive.NewObjectID(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			CreatedAt: now,
+			UpdatedAt: now,
 			IsActive:  true,
 			Version:   1,
 		},
 		UserID:   userID,
 		IsOnline: isOnline,
-		LastSeen: time.Now(),
+		LastSeen: &now,
 	}
 
 	if err := u.chatRepo.UpdateUserStatus(status); err != nil {
@@ -583,23 +903,37 @@ func (u *chatUsecase) UpdateUserOnlineStatus(userID string, isOnline bool) error
 	return nil
 }
 
-func (u *chatUsecase) GetUserOnlineStatus(userID string) (*domain.ChatUserStatus, error) {
+func (u *chatUsecase) GetUserOnlineStatus(viewerID, userID string) (*domain.ChatUserStatus, error) {
 	logger := utils.NewLogger("ChatUsecase.GetUserOnlineStatus")
-	logger.LogInput(userID)
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID,
+		"userID":   userID,
+	})
 
 	status, err := u.chatRepo.GetUserStatus(userID)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
+	if status == nil {
+		err := domain.NewNotFoundError("status", userID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	u.applyPresence(status)
+	if err := u.applyLastSeenSettings(viewerID, status); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
 
 	logger.LogOutput(status, nil)
 	return status, nil
 }
 
-func (u *chatUsecase) GetOnlineUsers(userIDs []string) ([]*domain.ChatUserStatus, error) {
+func (u *chatUsecase) GetOnlineUsers(viewerID string, userIDs []string) ([]*domain.ChatUserStatus, error) {
 	logger := utils.NewLogger("ChatUsecase.GetOnlineUsers")
-	logger.LogInput(userIDs)
+	logger.LogInput(viewerID, userIDs)
 
 	statuses := make([]*domain.ChatUserStatus, 0)
 	for _, userID := range userIDs {
@@ -608,15 +942,98 @@ func (u *chatUsecase) GetOnlineUsers(userIDs []string) ([]*domain.ChatUserStatus
 			logger.LogOutput(nil, err)
 			continue
 		}
-		if status != nil {
-			statuses = append(statuses, status)
+		if status == nil {
+			continue
 		}
+		statuses = append(statuses, status)
 	}
 
+	u.applyPresence(statuses...)
+	visible := statuses[:0]
+	for _, status := range statuses {
+		if err := u.applyLastSeenSettings(viewerID, status); err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+		visible = append(visible, status)
+	}
+	statuses = visible
+
 	logger.LogOutput(statuses, nil)
 	return statuses, nil
 }
 
+// applyLastSeenSettings hides what the user doesn't share with the viewer.
+// Viewers outside the user's last seen visibility don't see them online either,
+// and get a coarse label just like viewers of users who chose coarse last seen.
+func (u *chatUsecase) applyLastSeenSettings(viewerID string, status *domain.ChatUserStatus) error {
+	if viewerID == status.UserID {
+		return nil
+	}
+
+	user, err := u.userRepo.FindByID(status.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain.NewNotFoundError("user", status.UserID)
+	}
+
+	visible := true
+	switch user.LastSeenVisibility {
+	case domain.LastSeenVisibilityNobody:
+		visible = false
+	case domain.LastSeenVisibilityFriends:
+		visible, err = u.areFriends(viewerID, user.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if visible && !user.CoarseLastSeen {
+		return nil
+	}
+	if !visible {
+		status.IsOnline = false
+	}
+	if status.LastSeen != nil {
+		status.LastSeenLabel = coarseLastSeen(*status.LastSeen, time.Now())
+	}
+	status.LastSeen = nil
+	return nil
+}
+
+func (u *chatUsecase) areFriends(viewerID string, userID primitive.ObjectID) (bool, error) {
+	viewerObjectID, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		return false, nil
+	}
+
+	friendship, err := u.friendshipRepo.FindByUsers(viewerObjectID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return friendship.Status == "accepted", nil
+}
+
+// coarseLastSeen buckets a last seen time into a label
+func coarseLastSeen(lastSeen, now time.Time) string {
+	elapsed := now.Sub(lastSeen)
+	switch {
+	case elapsed < time.Hour:
+		return domain.LastSeenRecently
+	case elapsed < 24*time.Hour:
+		return domain.LastSeenToday
+	case elapsed < 7*24*time.Hour:
+		return domain.LastSeenThisWeek
+	default:
+		return domain.LastSeenLongAgo
+	}
+}
+
 // Notification operations
 func (u *chatUsecase) CreateNotification(userID string, notificationType string, roomID string, messageID string) (*domain.ChatNotification, error) {
 	logger := utils.NewLogger("ChatUsecase.CreateNotification")
@@ -680,9 +1097,12 @@ func (u *chatUsecase) MarkNotificationRead(notificationID string) error {
 	return nil
 }
 
-func (u *chatUsecase) DeleteNotification(notificationID string) error {
+func (u *chatUsecase) DeleteNotification(notificationID string, userID string) error {
 	logger := utils.NewLogger("ChatUsecase.DeleteNotification")
-	logger.LogInput(notificationID)
+	logger.LogInput(map[string]interface{}{
+		"notificationID": notificationID,
+		"userID":         userID,
+	})
 
 	// Check if notification exists
 	notification, err := u.chatRepo.GetNotification(notificationID)
@@ -696,6 +1116,11 @@ func (u *chatUsecase) DeleteNotification(notificationID string) error {
 		return err
 	}
 
+	if err := authorizeOwnerOrAdmin(u.userRepo, userID, notification.UserID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
 	// Delete notification
 	err = u.chatRepo.DeleteNotification(notificationID)
 	if err != nil {
@@ -838,3 +1263,173 @@ func (u *chatUsecase) GetUserRooms(userID string) ([]*domain.ChatRoom, error) {
 func (u *chatUsecase) GetRoomsByUserID(userID string) ([]*domain.ChatRoom, error) {
     return u.chatRepo.GetRoomsByUser(userID)
 }
+
+func (u *chatUsecase) CountChatMessages(roomID string) (int64, error) {
+	logger := utils.NewLogger("ChatUsecase.CountChatMessages")
+	logger.LogInput(roomID)
+
+	count, err := u.chatRepo.CountRoomMessages(roomID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+// pushMessage sends a mobile push for a new chat message to the recipients. Failures are only logged.
+func (u *chatUsecase) pushMessage(recipientIDs []string, message *domain.ChatMessage) {
+	logger := utils.NewLogger("ChatUsecase.pushMessage")
+
+	if u.deviceUsecase == nil || len(recipientIDs) == 0 {
+		return
+	}
+
+	title := "New message"
+	sender, err := u.userRepo.FindByID(message.SenderID)
+	if err == nil && sender != nil {
+		title = sender.DisplayName
+		if title == "" {
+			title = sender.Username
+		}
+	}
+
+	body := message.Content
+	if message.Type == "file" {
+		switch domain.ChatFileTypes[message.FileType].Kind {
+		case domain.ChatFileAudio:
+			body = "Sent a voice message"
+		case domain.ChatFileVideo:
+			body = "Sent a video"
+		default:
+			body = "Sent a file"
+		}
+	}
+
+	data := map[string]string{
+		"type":      "new_message",
+		"roomId":    message.RoomID,
+		"messageId": message.ID.Hex(),
+	}
+	if message.ThreadID != "" {
+		data["type"] = "thread_reply"
+		data["threadId"] = message.ThreadID
+	}
+
+	push := &domain.PushMessage{
+		Title: title,
+		Body:  body,
+		Data:  data,
+	}
+	for _, recipientID := range recipientIDs {
+		recipientObjectID, err := primitive.ObjectIDFromHex(recipientID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+		if err := u.deviceUsecase.PushToUser(recipientObjectID, push); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+}
+
+// publishReadReceipt tells the room a member read a message, unless the member hides read receipts.
+// The read state is already saved, so failures are only logged.
+func (u *chatUsecase) publishReadReceipt(messageID, userID string) {
+	logger := utils.NewLogger("ChatUsecase.publishReadReceipt")
+
+	if u.realtime == nil {
+		return
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil || user.HideReadReceipts {
+		return
+	}
+
+	message, err := u.chatRepo.GetMessage(messageID)
+	if err != nil || message == nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	err = u.realtime.PublishToRoom(message.RoomID, domain.RealtimeEventReadReceipt, domain.ChatReadReceipt{
+		RoomID:    message.RoomID,
+		MessageID: messageID,
+		UserID:    userID,
+		ReadAt:    time.Now(),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
+// publishRoomRead tells the room a member caught up, unless they hide their read receipts
+func (u *chatUsecase) publishRoomRead(roomID, userID string, upTo time.Time) {
+	logger := utils.NewLogger("ChatUsecase.publishRoomRead")
+
+	if u.realtime == nil {
+		return
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user == nil || user.HideReadReceipts {
+		return
+	}
+
+	err = u.realtime.PublishToRoom(roomID, domain.RealtimeEventRoomRead, domain.ChatRoomRead{
+		RoomID:   roomID,
+		UserID:   userID,
+		ReadUpTo: upTo,
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
+// hideReadReceipts removes readBy entries of members who hide their read receipts.
+// Viewers always see their own entry.
+func (u *chatUsecase) hideReadReceipts(messages []*domain.ChatMessage, viewerID string) error {
+	seen := make(map[string]bool)
+	var readerIDs []primitive.ObjectID
+	for _, message := range messages {
+		for _, readerID := range message.ReadBy {
+			if readerID == viewerID || seen[readerID] {
+				continue
+			}
+			seen[readerID] = true
+			if id, err := primitive.ObjectIDFromHex(readerID); err == nil {
+				readerIDs = append(readerIDs, id)
+			}
+		}
+	}
+	if len(readerIDs) == 0 {
+		return nil
+	}
+
+	readers, err := u.userRepo.FindByIDs(readerIDs)
+	if err != nil {
+		return err
+	}
+	hidden := make(map[string]bool)
+	for _, reader := range readers {
+		if reader.HideReadReceipts {
+			hidden[reader.ID.Hex()] = true
+		}
+	}
+	if len(hidden) == 0 {
+		return nil
+	}
+
+	for _, message := range messages {
+		readBy := make([]string, 0, len(message.ReadBy))
+		for _, readerID := range message.ReadBy {
+			if !hidden[readerID] {
+				readBy = append(readBy, readerID)
+			}
+		}
+		message.ReadBy = readBy
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
@@ -8,11 +9,18 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Translations are cached until the comment is edited or this TTL passes
+const commentTranslationTTL = 24 * time.Hour
+
 type commentUseCase struct {
 	commentRepo        domain.CommentRepository
 	postRepo          domain.PostRepository
 	notificationUseCase domain.NotificationUseCase
 	userRepo           domain.UserRepository
+	translationRepo    domain.TranslationRepository
+	hotContent         domain.HotContentPolicy
+	followUseCase      domain.FollowUseCase
+	privacyUseCase     domain.PrivacyUseCase
 }
 
 func NewCommentUseCase(
@@ -20,12 +28,20 @@ func NewCommentUseCase(
 	postRepo domain.PostRepository,
 	notificationUseCase domain.NotificationUseCase,
 	userRepo domain.UserRepository,
+	translationRepo domain.TranslationRepository,
+	hotContent domain.HotContentPolicy,
+	followUseCase domain.FollowUseCase,
+	privacyUseCase domain.PrivacyUseCase,
 ) domain.CommentUseCase {
 	return &commentUseCase{
 		commentRepo:        commentRepo,
 		postRepo:          postRepo,
 		notificationUseCase: notificationUseCase,
 		userRepo:           userRepo,
+		translationRepo:    translationRepo,
+		hotContent:         hotContent,
+		followUseCase:      followUseCase,
+		privacyUseCase:     privacyUseCase,
 	}
 }
 
@@ -40,6 +56,11 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 	}
 	logger.LogInput(input)
 
+	if err := checkCanPublish(c.userRepo, userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	// Get post to increment comment count and get post owner
 	post, err := c.postRepo.FindByID(postID)
 	if err != nil {
@@ -47,6 +68,47 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 		return nil, err
 	}
 
+	// Replies stay in the thread of their parent's post
+	var parent *domain.Comment
+	if replyTo != nil {
+		parent, err = c.commentRepo.FindByID(*replyTo)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if parent.PostID != postID {
+			err := fmt.Errorf("%w: the replied comment belongs to another post", domain.ErrInvalidInput)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	// Blocked users can't comment on each other's posts or reply to each other
+	others := []primitive.ObjectID{post.UserID}
+	if parent != nil {
+		others = append(others, parent.UserID)
+	}
+	for _, otherID := range others {
+		if otherID == userID {
+			continue
+		}
+		blocked, err := isBlockedEitherWay(c.followUseCase, userID, otherID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if blocked {
+			logger.LogOutput(nil, domain.ErrBlocked)
+			return nil, domain.ErrBlocked
+		}
+	}
+
+	// The post author picks who can comment, replies included
+	if err := c.privacyUseCase.CheckCanComment(userID, post.UserID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	now := time.Now()
 	comment := &domain.Comment{
 		BaseModel: domain.BaseModel{
@@ -59,9 +121,11 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 		PostID:         postID,
 		UserID:         userID,
 		Content:        content,
+		Language:       utils.DetectLanguage(content),
 		Media:          media,
+		Mentions:       resolveMentions(c.userRepo, content),
 		ReactionCounts: make(map[string]int),
-		ReplyTo:        replyTo,
+		ReplyToCommentID: replyTo,
 	}
 
 	err = c.commentRepo.Create(comment)
@@ -70,50 +134,22 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 		return nil, err
 	}
 
-	// Check for mentions in content
-	mentions := utils.ExtractMentions(content)
-	for _, username := range mentions {
-		// Find user by username
-		mentionedUser, err := c.userRepo.FindByUsername(username)
-		if err != nil {
-			logger.LogOutput(nil, err)
-			continue // Skip if user not found
-		}
-
-		// Don't notify if user mentions themselves
-		if mentionedUser.ID == userID {
-			continue
-		}
+	notifyMentions(c.notificationUseCase, userID, comment.ID, "comment", "mentioned you in a comment", comment.Mentions, nil)
 
-		// Create mention notification
-		_, err = c.notificationUseCase.CreateNotification(
-			mentionedUser.ID,     // recipientID (mentioned user)
-			userID,               // senderID (user who mentioned)
-			comment.ID,           // refID (reference to the comment)
-			domain.NotificationTypeMention,
-			"comment",            // refType
-			"mentioned you in a comment", // message
-		)
-		if err != nil {
+	// If this is a reply to another comment, notify the original comment owner
+	if parent != nil {
+		if err := c.commentRepo.IncrementReplyCount(parent.ID, 1); err != nil {
 			logger.LogOutput(nil, err)
-			// Don't return error here as the comment was created successfully
 		}
-	}
 
-	// If this is a reply to another comment, notify the original comment owner
-	if replyTo != nil {
-		originalComment, err := c.commentRepo.FindByID(*replyTo)
-		if err != nil {
-			logger.LogOutput(nil, err)
-			// Don't return error, just skip notification
-		} else {
+		if parent.UserID != userID {
 			// Create notification for reply
 			_, err = c.notificationUseCase.CreateNotification(
-				originalComment.UserID, // recipientID (original comment owner)
-				userID,                 // senderID (user who replied)
-				comment.ID,             // refID (reference to the reply)
+				parent.UserID, // recipientID (original comment owner)
+				userID,        // senderID (user who replied)
+				comment.ID,    // refID (reference to the reply)
 				domain.NotificationTypeComment,
-				"comment",              // refType
+				"comment",                 // refType
 				"replied to your comment", // message
 			)
 			if err != nil {
@@ -125,14 +161,29 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 		// This is a comment on a post, notify the post owner
 		// Only notify if the commenter is not the post owner
 		if post.UserID != userID {
-			_, err = c.notificationUseCase.CreateNotification(
-				post.UserID,            // recipientID (post owner)
-				userID,                 // senderID (commenter)
-				comment.ID,             // refID (reference to the comment)
-				domain.NotificationTypeComment,
-				"post",                 // refType
-				"commented on your post", // message
-			)
+			// Comments on hot posts are aggregated per post instead
+			hot := c.hotContent.IsHotPost(post)
+			recordNotifyStrategy("comment", hot)
+			if hot {
+				_, err = c.notificationUseCase.CreateAggregatedNotification(
+					post.UserID,
+					userID,
+					post.ID,
+					domain.NotificationTypeComment,
+					"post",
+					"commented on your post",
+					c.hotContent.AggregationWindow,
+				)
+			} else {
+				_, err = c.notificationUseCase.CreateNotification(
+					post.UserID,            // recipientID (post owner)
+					userID,                 // senderID (commenter)
+					comment.ID,             // refID (reference to the comment)
+					domain.NotificationTypeComment,
+					"post",                 // refType
+					"commented on your post", // message
+				)
+			}
 			if err != nil {
 				logger.LogOutput(nil, err)
 				// Don't return error here as the comment was created successfully
@@ -141,8 +192,7 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 	}
 
 	// Increment comment count in post
-	post.CommentCount++
-	err = c.postRepo.Update(post)
+	err = c.postRepo.IncrementCounter(post.ID, domain.PostCounterComments, 1)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
@@ -152,9 +202,10 @@ func (c *commentUseCase) CreateComment(userID, postID primitive.ObjectID, conten
 	return comment, nil
 }
 
-func (c *commentUseCase) UpdateComment(commentID primitive.ObjectID, content string, media []domain.Media) (*domain.Comment, error) {
+func (c *commentUseCase) UpdateComment(userID, commentID primitive.ObjectID, content string, media []domain.Media) (*domain.Comment, error) {
 	logger := utils.NewLogger("CommentUseCase.UpdateComment")
 	input := map[string]interface{}{
+		"userID":    userID,
 		"commentID": commentID,
 		"content":   content,
 		"media":     media,
@@ -167,9 +218,27 @@ func (c *commentUseCase) UpdateComment(commentID primitive.ObjectID, content str
 		return nil, err
 	}
 
+	if err := authorizeOwnerOrAdmin(c.userRepo, userID.Hex(), comment.UserID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Keep the previous version before overwriting it
+	now := time.Now()
+	comment.EditHistory = append(comment.EditHistory, domain.CommentEditLog{
+		Content:  comment.Content,
+		Media:    comment.Media,
+		EditedAt: now,
+	})
+
+	previousMentions := comment.Mentions
 	comment.Content = content
+	comment.Language = utils.DetectLanguage(content)
 	comment.Media = media
-	comment.UpdatedAt = time.Now()
+	comment.Mentions = resolveMentions(c.userRepo, content)
+	comment.UpdatedAt = now
+	comment.IsEdited = true
+	comment.LastEditedAt = &now
 
 	err = c.commentRepo.Update(comment)
 	if err != nil {
@@ -177,6 +246,9 @@ func (c *commentUseCase) UpdateComment(commentID primitive.ObjectID, content str
 		return nil, err
 	}
 
+	// Only users mentioned by this edit are notified
+	notifyMentions(c.notificationUseCase, comment.UserID, comment.ID, "comment", "mentioned you in a comment", comment.Mentions, previousMentions)
+
 	logger.LogOutput(comment, nil)
 	return comment, nil
 }
@@ -205,10 +277,15 @@ func (c *commentUseCase) DeleteComment(commentID primitive.ObjectID) error {
 		return err
 	}
 
+	if comment.ReplyToCommentID != nil {
+		if err := c.commentRepo.IncrementReplyCount(*comment.ReplyToCommentID, -1); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
 	// Decrement comment count in post
 	if post.CommentCount > 0 {
-		post.CommentCount--
-		err = c.postRepo.Update(post)
+		err = c.postRepo.IncrementCounter(post.ID, domain.PostCounterComments, -1)
 		if err != nil {
 			logger.LogOutput(nil, err)
 			return err
@@ -233,16 +310,24 @@ func (c *commentUseCase) GetComment(commentID primitive.ObjectID) (*domain.Comme
 	return comment, nil
 }
 
-func (c *commentUseCase) ListComments(postID primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
+// ListComments leaves out comments of users the viewer blocked or who blocked the viewer
+func (c *commentUseCase) ListComments(viewerID, postID primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
 	logger := utils.NewLogger("CommentUseCase.ListComments")
 	input := map[string]interface{}{
-		"postID": postID,
-		"limit":  limit,
-		"offset": offset,
+		"viewerID": viewerID,
+		"postID":   postID,
+		"limit":    limit,
+		"offset":   offset,
 	}
 	logger.LogInput(input)
 
-	comments, err := c.commentRepo.FindByPostID(postID, limit, offset)
+	blocked, err := c.followUseCase.GetBlockedIDs(viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	comments, err := c.commentRepo.FindByPostID(postID, blocked, limit, offset)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
@@ -251,3 +336,138 @@ func (c *commentUseCase) ListComments(postID primitive.ObjectID, limit, offset i
 	logger.LogOutput(comments, nil)
 	return comments, nil
 }
+
+func (c *commentUseCase) CountComments(postID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("CommentUseCase.CountComments")
+	logger.LogInput(postID)
+
+	count, err := c.commentRepo.CountByPostID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+// ReplyToComment adds a reply to the comment, on the comment's post
+func (c *commentUseCase) ReplyToComment(userID, commentID primitive.ObjectID, content string, media []domain.Media) (*domain.Comment, error) {
+	logger := utils.NewLogger("CommentUseCase.ReplyToComment")
+	logger.LogInput(map[string]interface{}{
+		"userID":    userID,
+		"commentID": commentID,
+		"content":   content,
+		"media":     media,
+	})
+
+	parent, err := c.commentRepo.FindByID(commentID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	reply, err := c.CreateComment(userID, parent.PostID, content, media, &parent.ID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(reply, nil)
+	return reply, nil
+}
+
+// ListReplies leaves out replies of users the viewer blocked or who blocked the viewer
+func (c *commentUseCase) ListReplies(viewerID, commentID primitive.ObjectID, limit, offset int) ([]domain.Comment, error) {
+	logger := utils.NewLogger("CommentUseCase.ListReplies")
+	input := map[string]interface{}{
+		"viewerID":  viewerID,
+		"commentID": commentID,
+		"limit":     limit,
+		"offset":    offset,
+	}
+	logger.LogInput(input)
+
+	blocked, err := c.followUseCase.GetBlockedIDs(viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	replies, err := c.commentRepo.FindReplies(commentID, blocked, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(replies, nil)
+	return replies, nil
+}
+
+func (c *commentUseCase) CountReplies(commentID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("CommentUseCase.CountReplies")
+	logger.LogInput(commentID)
+
+	count, err := c.commentRepo.CountReplies(commentID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (c *commentUseCase) TranslateComment(commentID primitive.ObjectID, targetLanguage string) (*domain.Translation, error) {
+	logger := utils.NewLogger("CommentUseCase.TranslateComment")
+	input := map[string]interface{}{
+		"commentID":      commentID,
+		"targetLanguage": targetLanguage,
+	}
+	logger.LogInput(input)
+
+	if !utils.IsValidLanguageCode(targetLanguage) {
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return nil, domain.ErrInvalidInput
+	}
+
+	comment, err := c.commentRepo.FindByID(commentID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Nothing to translate when the comment is already in the requested language
+	if comment.Language == targetLanguage {
+		translation := &domain.Translation{
+			SourceLanguage: comment.Language,
+			TargetLanguage: targetLanguage,
+			Text:           comment.Content,
+		}
+		logger.LogOutput(translation, nil)
+		return translation, nil
+	}
+
+	translation, err := c.commentRepo.GetTranslation(commentID, targetLanguage)
+	if err != nil {
+		// Cache miss on Redis errors, the provider is still the source of truth
+		logger.LogOutput(nil, err)
+	}
+	if translation != nil {
+		logger.LogOutput(translation, nil)
+		return translation, nil
+	}
+
+	translation, err = c.translationRepo.Translate(comment.Content, comment.Language, targetLanguage)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := c.commentRepo.SetTranslation(commentID, translation, commentTranslationTTL); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	logger.LogOutput(translation, nil)
+	return translation, nil
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type contentModerationUseCase struct {
+	postRepo       domain.PostRepository
+	commentRepo    domain.CommentRepository
+	postUseCase    domain.PostUseCase
+	commentUseCase domain.CommentUseCase
+	auditLogRepo   domain.AuditLogRepository
+}
+
+func NewContentModerationUseCase(
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	postUseCase domain.PostUseCase,
+	commentUseCase domain.CommentUseCase,
+	auditLogRepo domain.AuditLogRepository,
+) domain.ContentModerationUseCase {
+	return &contentModerationUseCase{
+		postRepo:       postRepo,
+		commentRepo:    commentRepo,
+		postUseCase:    postUseCase,
+		commentUseCase: commentUseCase,
+		auditLogRepo:   auditLogRepo,
+	}
+}
+
+func (u *contentModerationUseCase) HideContent(adminID primitive.ObjectID, contentType, id string, req domain.ContentModerationRequest) (*domain.AuditLog, error) {
+	return u.setActive("ContentModerationUseCase.HideContent", adminID, contentType, id, false, req)
+}
+
+func (u *contentModerationUseCase) UnhideContent(adminID primitive.ObjectID, contentType, id string, req domain.ContentModerationRequest) (*domain.AuditLog, error) {
+	return u.setActive("ContentModerationUseCase.UnhideContent", adminID, contentType, id, true, req)
+}
+
+func (u *contentModerationUseCase) DeleteContent(adminID primitive.ObjectID, contentType, id string, req domain.ContentModerationRequest) (*domain.AuditLog, error) {
+	logger := utils.NewLogger("ContentModerationUseCase.DeleteContent")
+	logger.LogInput(adminID, contentType, id, req)
+
+	contentID, err := moderatedContentID(contentType, id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// The use cases clean up counters, tags and files like any other delete
+	if contentType == domain.ModeratedContentPost {
+		err = u.postUseCase.DeletePost(adminID, contentID)
+	} else {
+		err = u.commentUseCase.DeleteComment(contentID)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	auditLog, err := u.recordAudit(adminID, domain.AuditActionDeleteContent, contentType, id, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(auditLog, nil)
+	return auditLog, nil
+}
+
+// setActive hides or unhides a post or comment. Hiding hidden content, or
+// unhiding visible content, changes nothing but is still recorded.
+func (u *contentModerationUseCase) setActive(name string, adminID primitive.ObjectID, contentType, id string, active bool, req domain.ContentModerationRequest) (*domain.AuditLog, error) {
+	logger := utils.NewLogger(name)
+	logger.LogInput(adminID, contentType, id, req)
+
+	contentID, err := moderatedContentID(contentType, id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if contentType == domain.ModeratedContentPost {
+		post, findErr := u.postRepo.FindByID(contentID)
+		if findErr != nil {
+			logger.LogOutput(nil, findErr)
+			return nil, findErr
+		}
+		if post.IsActive != active {
+			post.IsActive = active
+			err = u.postRepo.Update(post)
+		}
+	} else {
+		comment, findErr := u.commentRepo.FindByID(contentID)
+		if findErr != nil {
+			logger.LogOutput(nil, findErr)
+			return nil, findErr
+		}
+		if comment.IsActive != active {
+			comment.IsActive = active
+			err = u.commentRepo.Update(comment)
+		}
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	action := domain.AuditActionHideContent
+	if active {
+		action = domain.AuditActionUnhideContent
+	}
+	auditLog, err := u.recordAudit(adminID, action, contentType, id, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(auditLog, nil)
+	return auditLog, nil
+}
+
+func (u *contentModerationUseCase) recordAudit(adminID primitive.ObjectID, action, contentType, id string, req domain.ContentModerationRequest) (*domain.AuditLog, error) {
+	auditLog := &domain.AuditLog{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: contentType,
+		TargetID:   id,
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		auditLog.Metadata = map[string]interface{}{"reason": reason}
+	}
+	if err := u.auditLogRepo.Create(auditLog); err != nil {
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+func moderatedContentID(contentType, id string) (primitive.ObjectID, error) {
+	if contentType != domain.ModeratedContentPost && contentType != domain.ModeratedContentComment {
+		return primitive.NilObjectID, fmt.Errorf("%w: unknown content type %q", domain.ErrInvalidInput, contentType)
+	}
+	contentID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, domain.ErrInvalidID
+	}
+	return contentID, nil
+}
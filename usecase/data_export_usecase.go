@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type dataExportUseCase struct {
+	exportRepo          domain.DataExportRepository
+	userRepo            domain.UserRepository
+	postRepo            domain.PostRepository
+	commentRepo         domain.CommentRepository
+	reactionRepo        domain.ReactionRepository
+	chatRepo            domain.ChatRepository
+	fileRepo            domain.FileRepository
+	notificationUseCase domain.NotificationUseCase
+}
+
+func NewDataExportUseCase(
+	exportRepo domain.DataExportRepository,
+	userRepo domain.UserRepository,
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	reactionRepo domain.ReactionRepository,
+	chatRepo domain.ChatRepository,
+	fileRepo domain.FileRepository,
+	notificationUseCase domain.NotificationUseCase,
+) domain.DataExportUseCase {
+	return &dataExportUseCase{
+		exportRepo:          exportRepo,
+		userRepo:            userRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		reactionRepo:        reactionRepo,
+		chatRepo:            chatRepo,
+		fileRepo:            fileRepo,
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+func (u *dataExportUseCase) RequestExport(userID primitive.ObjectID) (*domain.DataExport, error) {
+	logger := utils.NewLogger("DataExportUseCase.RequestExport")
+	logger.LogInput(userID)
+
+	latest, err := u.exportRepo.FindLatestByUser(userID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if latest != nil && (latest.Status == domain.DataExportPending || latest.Status == domain.DataExportRunning) {
+		err := fmt.Errorf("%w: an export is already in progress", domain.ErrDuplicate)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	export := &domain.DataExport{
+		UserID: userID,
+		Status: domain.DataExportPending,
+	}
+	if err := u.exportRepo.Create(export); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(export.ID, nil)
+	return export, nil
+}
+
+func (u *dataExportUseCase) GetLatestExport(userID primitive.ObjectID) (*domain.DataExport, error) {
+	logger := utils.NewLogger("DataExportUseCase.GetLatestExport")
+	logger.LogInput(userID)
+
+	export, err := u.exportRepo.FindLatestByUser(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(export.Status, nil)
+	return export, nil
+}
+
+// RunNextExport streams the archive into storage as it's built, so large
+// accounts are never held in memory. An export that can't be built is marked
+// failed and the user may request another.
+func (u *dataExportUseCase) RunNextExport() (bool, error) {
+	logger := utils.NewLogger("DataExportUseCase.RunNextExport")
+
+	export, err := u.exportRepo.ClaimNext(time.Now().Add(-domain.DataExportStaleAfter))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if export == nil {
+		return false, nil
+	}
+	logger.LogInput(export.ID, export.UserID)
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(u.writeArchive(writer, export.UserID))
+	}()
+	file, err := u.fileRepo.Upload(&domain.File{
+		FileName:    fmt.Sprintf("vongga-export-%s.zip", export.ID.Hex()),
+		ContentType: "application/zip",
+	}, reader)
+	// Unblocks the archive writer when the upload gave up early
+	reader.CloseWithError(err)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		if err := u.exportRepo.Fail(export.ID, "could not build the archive"); err != nil {
+			logger.LogOutput(nil, err)
+			return true, err
+		}
+		return true, nil
+	}
+
+	if err := u.exportRepo.Complete(export.ID, file.FileURL, file.Size); err != nil {
+		logger.LogOutput(nil, err)
+		return true, err
+	}
+
+	// The export is ready either way, the user can also poll for it
+	message := fmt.Sprintf("Your data export is ready to download: %s", file.FileURL)
+	_, err = u.notificationUseCase.CreateNotification(export.UserID, primitive.NilObjectID, export.ID, domain.NotificationTypeAccount, "data_export", message)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	logger.LogOutput(file.FileURL, nil)
+	return true, nil
+}
+
+// writeArchive writes the user's profile and one JSON array per kind of content to w as a zip
+func (u *dataExportUseCase) writeArchive(w io.Writer, userID primitive.ObjectID) error {
+	archive := zip.NewWriter(w)
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		return err
+	}
+	profile, err := archive.Create("profile.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(profile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(user); err != nil {
+		return err
+	}
+
+	err = writeExportSection(archive, "posts.json", func(limit, offset int) ([]domain.Post, error) {
+		return u.postRepo.FindByUserID(userID, nil, limit, offset, false, "")
+	})
+	if err != nil {
+		return err
+	}
+	err = writeExportSection(archive, "comments.json", func(limit, offset int) ([]domain.Comment, error) {
+		return u.commentRepo.FindByUserID(userID, limit, offset)
+	})
+	if err != nil {
+		return err
+	}
+	err = writeExportSection(archive, "reactions.json", func(limit, offset int) ([]domain.Reaction, error) {
+		return u.reactionRepo.FindByUserID(userID, limit, offset)
+	})
+	if err != nil {
+		return err
+	}
+	err = writeExportSection(archive, "messages.json", func(limit, offset int) ([]*domain.ChatMessage, error) {
+		return u.chatRepo.GetMessagesBySender(userID.Hex(), int64(limit), int64(offset))
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeExportSection writes every record page returns, a page at a time, as one JSON array file
+func writeExportSection[T any](archive *zip.Writer, name string, page func(limit, offset int) ([]T, error)) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(file, "["); err != nil {
+		return err
+	}
+
+	for offset := 0; ; {
+		records, err := page(domain.DataExportPageSize, offset)
+		if err != nil {
+			return err
+		}
+		for i, record := range records {
+			recordJSON, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			separator := ",\n"
+			if offset+i == 0 {
+				separator = "\n"
+			}
+			if _, err := io.WriteString(file, separator); err != nil {
+				return err
+			}
+			if _, err := file.Write(recordJSON); err != nil {
+				return err
+			}
+		}
+		offset += len(records)
+		if len(records) < domain.DataExportPageSize {
+			break
+		}
+	}
+
+	_, err = io.WriteString(file, "\n]\n")
+	return err
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// DataExportWorker builds the queued data exports in the background
+type DataExportWorker struct {
+	dataExportUseCase domain.DataExportUseCase
+	interval          time.Duration
+}
+
+func NewDataExportWorker(dataExportUseCase domain.DataExportUseCase, interval time.Duration) *DataExportWorker {
+	return &DataExportWorker{
+		dataExportUseCase: dataExportUseCase,
+		interval:          interval,
+	}
+}
+
+// Run checks for queued exports every interval until ctx is done
+func (w *DataExportWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runExports(ctx)
+		}
+	}
+}
+
+// runExports builds queued exports one after another until none is left
+func (w *DataExportWorker) runExports(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := w.dataExportUseCase.RunNextExport()
+		if err != nil {
+			utils.NewLogger("DataExportWorker.runExports").LogOutput(nil, err)
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type deviceUseCase struct {
+	deviceTokenRepo domain.DeviceTokenRepository
+	pushSender      domain.PushSender
+}
+
+// NewDeviceUseCase creates the device use case. A nil pushSender disables push delivery.
+func NewDeviceUseCase(deviceTokenRepo domain.DeviceTokenRepository, pushSender domain.PushSender) domain.DeviceUseCase {
+	return &deviceUseCase{
+		deviceTokenRepo: deviceTokenRepo,
+		pushSender:      pushSender,
+	}
+}
+
+func (u *deviceUseCase) RegisterDevice(userID primitive.ObjectID, token, platform string) (*domain.DeviceToken, error) {
+	logger := utils.NewLogger("DeviceUseCase.RegisterDevice")
+	logger.LogInput(map[string]interface{}{
+		"userID":   userID,
+		"platform": platform,
+	})
+
+	token = strings.TrimSpace(token)
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if token == "" {
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return nil, domain.ErrInvalidInput
+	}
+	switch platform {
+	case domain.DevicePlatformIOS, domain.DevicePlatformAndroid, domain.DevicePlatformWeb:
+	default:
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return nil, domain.ErrInvalidInput
+	}
+
+	device := &domain.DeviceToken{
+		UserID:   userID,
+		Token:    token,
+		Platform: platform,
+	}
+	err := u.deviceTokenRepo.Upsert(device)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(device.ID, nil)
+	return device, nil
+}
+
+func (u *deviceUseCase) UnregisterDevice(userID primitive.ObjectID, token string) error {
+	logger := utils.NewLogger("DeviceUseCase.UnregisterDevice")
+	logger.LogInput(userID)
+
+	err := u.deviceTokenRepo.Delete(userID, strings.TrimSpace(token))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Device unregistered successfully", nil)
+	return nil
+}
+
+// PushToUser sends the message to every registered device of the user and
+// drops tokens the provider no longer accepts
+func (u *deviceUseCase) PushToUser(userID primitive.ObjectID, message *domain.PushMessage) error {
+	logger := utils.NewLogger("DeviceUseCase.PushToUser")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"title":  message.Title,
+	})
+
+	if u.pushSender == nil {
+		return nil
+	}
+
+	devices, err := u.deviceTokenRepo.FindByUserID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if len(devices) == 0 {
+		logger.LogOutput("No registered devices", nil)
+		return nil
+	}
+
+	tokens := make([]string, 0, len(devices))
+	for _, device := range devices {
+		tokens = append(tokens, device.Token)
+	}
+
+	invalidTokens, err := u.pushSender.Send(tokens, message)
+	if len(invalidTokens) > 0 {
+		if err := u.deviceTokenRepo.DeleteByTokens(invalidTokens); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(map[string]interface{}{
+		"devices":       len(tokens),
+		"invalidTokens": len(invalidTokens),
+	}, nil)
+	return nil
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type digestUseCase struct {
+	preferencesRepo     domain.NotificationPreferencesRepository
+	userRepo            domain.UserRepository
+	followRepo          domain.FollowRepository
+	hashtagRepo         domain.HashtagRepository
+	postRepo            domain.PostRepository
+	notificationUseCase domain.NotificationUseCase
+}
+
+func NewDigestUseCase(
+	preferencesRepo domain.NotificationPreferencesRepository,
+	userRepo domain.UserRepository,
+	followRepo domain.FollowRepository,
+	hashtagRepo domain.HashtagRepository,
+	postRepo domain.PostRepository,
+	notificationUseCase domain.NotificationUseCase,
+) domain.DigestUseCase {
+	return &digestUseCase{
+		preferencesRepo:     preferencesRepo,
+		userRepo:            userRepo,
+		followRepo:          followRepo,
+		hashtagRepo:         hashtagRepo,
+		postRepo:            postRepo,
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+// GetPreferences returns the defaults for users who never saved preferences
+func (d *digestUseCase) GetPreferences(userID primitive.ObjectID) (*domain.NotificationPreferences, error) {
+	logger := utils.NewLogger("DigestUseCase.GetPreferences")
+	logger.LogInput(userID)
+
+	preferences, err := d.preferencesRepo.Find(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &domain.NotificationPreferences{UserID: userID}
+	}
+
+	logger.LogOutput(preferences, nil)
+	return preferences, nil
+}
+
+func (d *digestUseCase) UpdatePreferences(userID primitive.ObjectID, update domain.NotificationPreferencesUpdate) (*domain.NotificationPreferences, error) {
+	logger := utils.NewLogger("DigestUseCase.UpdatePreferences")
+	logger.LogInput(userID, update)
+
+	if update.QuietHours != nil && update.ClearQuietHours {
+		err := fmt.Errorf("%w: quietHours can't be set and cleared at once", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if quiet := update.QuietHours; quiet != nil {
+		if quiet.StartHour < 0 || quiet.StartHour > 23 || quiet.EndHour < 0 || quiet.EndHour > 23 {
+			err := fmt.Errorf("%w: quiet hours must be between 0 and 23", domain.ErrInvalidInput)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if quiet.StartHour == quiet.EndHour {
+			err := fmt.Errorf("%w: quiet hours must start and end at different hours", domain.ErrInvalidInput)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+	if update.TimeZone != nil && *update.TimeZone != "" {
+		if _, err := time.LoadLocation(*update.TimeZone); err != nil {
+			err = fmt.Errorf("%w: unknown time zone", domain.ErrInvalidInput)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	preferences, err := d.GetPreferences(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if update.TrendingDigest != nil {
+		preferences.TrendingDigest = *update.TrendingDigest
+	}
+	if update.QuietHours != nil {
+		preferences.QuietHours = update.QuietHours
+	}
+	if update.ClearQuietHours {
+		preferences.QuietHours = nil
+	}
+	if update.TimeZone != nil {
+		preferences.TimeZone = *update.TimeZone
+	}
+
+	if err := d.preferencesRepo.Upsert(preferences); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(preferences, nil)
+	return preferences, nil
+}
+
+// SendTrendingDigests runs hourly, so users in their quiet hours get their digest
+// at the first run after them. A failure for one user doesn't stop the others,
+// they're tried again at the next run.
+func (d *digestUseCase) SendTrendingDigests() (int, error) {
+	logger := utils.NewLogger("DigestUseCase.SendTrendingDigests")
+
+	now := time.Now()
+	trending, err := d.hashtagRepo.FindTrending(domain.DigestWindow, domain.DigestTrendingTags)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	sent := 0
+	after := primitive.NilObjectID
+	for {
+		due, err := d.preferencesRepo.FindDigestDue(now.Add(-domain.DigestMinGap), after, domain.DigestBatchSize)
+		if err != nil {
+			logger.LogOutput(sent, err)
+			return sent, err
+		}
+
+		for i := range due {
+			preferences := &due[i]
+			after = preferences.UserID
+			if preferences.InQuietHours(now) {
+				continue
+			}
+
+			notified, err := d.sendDigest(preferences.UserID, trending, now)
+			if err != nil {
+				logger.LogOutput(preferences.UserID, err)
+				continue
+			}
+			if notified {
+				sent++
+			}
+			if err := d.preferencesRepo.MarkDigestSent(preferences.UserID, now); err != nil {
+				logger.LogOutput(preferences.UserID, err)
+			}
+		}
+
+		if len(due) < domain.DigestBatchSize {
+			break
+		}
+	}
+
+	logger.LogOutput(sent, nil)
+	return sent, nil
+}
+
+// sendDigest notifies the user of the trending tags matching their interests and
+// the popular recent posts with those tags or by the accounts they follow. It
+// reports false when there was nothing worth sending.
+func (d *digestUseCase) sendDigest(userID primitive.ObjectID, trending []domain.TrendingTag, now time.Time) (bool, error) {
+	user, err := d.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+
+	interests := make(map[string]bool, len(user.Interests))
+	for _, interest := range user.Interests {
+		interests[strings.ToLower(interest)] = true
+	}
+	tags := make([]string, 0, domain.DigestMaxTags)
+	for _, tag := range trending {
+		if len(tags) == domain.DigestMaxTags {
+			break
+		}
+		if interests[strings.ToLower(tag.Tag)] {
+			tags = append(tags, tag.Tag)
+		}
+	}
+
+	following, err := d.followRepo.FindFollowing(userID, domain.DigestMaxFollowing, 0)
+	if err != nil {
+		return false, err
+	}
+	authorIDs := make([]primitive.ObjectID, 0, len(following))
+	for _, follow := range following {
+		authorIDs = append(authorIDs, follow.FollowingID)
+	}
+
+	blockedIDs, err := d.followRepo.FindBlockedIDs(userID)
+	if err != nil {
+		return false, err
+	}
+	exclude := append(blockedIDs, userID)
+
+	posts, err := d.postRepo.FindPopularSince(tags, authorIDs, exclude, now.Add(-domain.DigestWindow), domain.DigestMaxPosts)
+	if err != nil {
+		return false, err
+	}
+	if len(tags) == 0 && len(posts) == 0 {
+		return false, nil
+	}
+
+	refID, refType := userID, "user"
+	if len(posts) > 0 {
+		refID, refType = posts[0].ID, "post"
+	}
+	_, err = d.notificationUseCase.CreateNotification(userID, primitive.NilObjectID, refID, domain.NotificationTypeDigest, refType, digestMessage(tags, len(posts)))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// digestMessage reads like "What you missed: 3 popular posts and trending #travel, #food"
+func digestMessage(tags []string, posts int) string {
+	parts := make([]string, 0, 2)
+	switch {
+	case posts == 1:
+		parts = append(parts, "1 popular post")
+	case posts > 1:
+		parts = append(parts, fmt.Sprintf("%d popular posts", posts))
+	}
+	if len(tags) > 0 {
+		parts = append(parts, "trending #"+strings.Join(tags, ", #"))
+	}
+	return "What you missed: " + strings.Join(parts, " and ")
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type domainEventPublisher struct {
+	outboxRepo domain.DomainEventRepository
+}
+
+// NewDomainEventPublisher writes events to the outbox for the exporter.
+// A nil outboxRepo disables event export.
+func NewDomainEventPublisher(outboxRepo domain.DomainEventRepository) domain.DomainEventPublisher {
+	return &domainEventPublisher{
+		outboxRepo: outboxRepo,
+	}
+}
+
+func (p *domainEventPublisher) Publish(eventType string, payload interface{}) {
+	if p.outboxRepo == nil {
+		return
+	}
+
+	logger := utils.NewLogger("DomainEventPublisher.Publish")
+	logger.LogInput(eventType)
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	event := &domain.DomainEvent{
+		ID:            primitive.NewObjectID(),
+		Type:          eventType,
+		SchemaVersion: domain.DomainEventSchemaVersions[eventType],
+		OccurredAt:    time.Now(),
+		Payload:       string(payloadJSON),
+	}
+	if err := p.outboxRepo.Save(event); err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	logger.LogOutput(event.ID, nil)
+}
+
+// DomainEventExporter moves events from the outbox to the sink. Events are marked exported
+// only after the sink accepted them, so a failed or interrupted export is retried.
+type DomainEventExporter struct {
+	outboxRepo domain.DomainEventRepository
+	sink       domain.DomainEventSink
+	interval   time.Duration
+	batchSize  int
+}
+
+func NewDomainEventExporter(outboxRepo domain.DomainEventRepository, sink domain.DomainEventSink, interval time.Duration, batchSize int) *DomainEventExporter {
+	return &DomainEventExporter{
+		outboxRepo: outboxRepo,
+		sink:       sink,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+// Run exports pending events every interval until ctx is done
+func (e *DomainEventExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.exportPending()
+		}
+	}
+}
+
+// exportPending drains the outbox batch by batch, stopping at the first failure
+func (e *DomainEventExporter) exportPending() {
+	logger := utils.NewLogger("DomainEventExporter.exportPending")
+
+	exported := 0
+	for {
+		events, err := e.outboxRepo.FindPending(e.batchSize)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		if err := e.sink.Export(events); err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		if err := e.outboxRepo.MarkExported(ids); err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+
+		exported += len(events)
+		if len(events) < e.batchSize {
+			break
+		}
+	}
+
+	if exported > 0 {
+		logger.LogOutput(map[string]interface{}{"exported": exported}, nil)
+	}
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type feedLayoutUseCase struct {
+	layoutRepo domain.FeedLayoutRepository
+}
+
+func NewFeedLayoutUseCase(layoutRepo domain.FeedLayoutRepository) domain.FeedLayoutUseCase {
+	return &feedLayoutUseCase{
+		layoutRepo: layoutRepo,
+	}
+}
+
+// GetLayout takes the modules of the first experiment that puts the user in a
+// variant with modules, or the default modules, then drops the modules whose
+// flag is off for the user
+func (u *feedLayoutUseCase) GetLayout(userID primitive.ObjectID) (*domain.FeedLayout, error) {
+	logger := utils.NewLogger("FeedLayoutUseCase.GetLayout")
+	logger.LogInput(userID)
+
+	config, err := u.GetConfig()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	layout := &domain.FeedLayout{
+		Modules:     make([]domain.FeedModule, 0, len(config.Modules)),
+		Experiments: make(map[string]string),
+		Version:     "default",
+	}
+	if !config.UpdatedAt.IsZero() {
+		layout.Version = strconv.FormatInt(config.UpdatedAt.Unix(), 10)
+	}
+
+	modules := config.Modules
+	overridden := false
+	for _, experiment := range config.Experiments {
+		if !experiment.Enabled {
+			continue
+		}
+		variant := pickVariant(experiment, userID)
+		if variant == nil {
+			continue
+		}
+		layout.Experiments[experiment.Key] = variant.Name
+		if !overridden && len(variant.Modules) > 0 {
+			modules = variant.Modules
+			overridden = true
+		}
+	}
+
+	flags := make(map[string]domain.FeatureFlag, len(config.Flags))
+	for _, flag := range config.Flags {
+		flags[flag.Key] = flag
+	}
+	for _, module := range modules {
+		if module.Flag != "" && !flagOn(flags[module.Flag], userID) {
+			continue
+		}
+		layout.Modules = append(layout.Modules, module)
+	}
+
+	logger.LogOutput(layout, nil)
+	return layout, nil
+}
+
+// userHash spreads users evenly and stably for the key
+func userHash(key string, userID primitive.ObjectID) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(key + ":" + userID.Hex()))
+	return hash.Sum32()
+}
+
+// flagOn reports whether the flag is on for the user, unknown flags are off
+func flagOn(flag domain.FeatureFlag, userID primitive.ObjectID) bool {
+	return flag.Enabled && int(userHash("flag:"+flag.Key, userID)%100) < flag.RolloutPercent
+}
+
+// pickVariant returns the user's variant of the experiment by weight
+func pickVariant(experiment domain.LayoutExperiment, userID primitive.ObjectID) *domain.ExperimentVariant {
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	point := int(userHash("experiment:"+experiment.Key, userID) % uint32(total))
+	for i := range experiment.Variants {
+		point -= experiment.Variants[i].Weight
+		if point < 0 {
+			return &experiment.Variants[i]
+		}
+	}
+	return nil
+}
+
+func (u *feedLayoutUseCase) GetConfig() (*domain.FeedLayoutConfig, error) {
+	logger := utils.NewLogger("FeedLayoutUseCase.GetConfig")
+
+	config, err := u.layoutRepo.Find()
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if config == nil {
+		defaults := domain.DefaultFeedLayout
+		config = &defaults
+	}
+
+	logger.LogOutput(config, nil)
+	return config, nil
+}
+
+func (u *feedLayoutUseCase) UpdateConfig(config *domain.FeedLayoutConfig) (*domain.FeedLayoutConfig, error) {
+	logger := utils.NewLogger("FeedLayoutUseCase.UpdateConfig")
+	logger.LogInput(config)
+
+	if err := validateFeedLayout(config); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.layoutRepo.Save(config); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(config.UpdatedAt, nil)
+	return config, nil
+}
+
+// validateFeedLayout rejects modules the app can't render, flags modules refer
+// to that aren't defined, and experiments nobody can be placed in
+func validateFeedLayout(config *domain.FeedLayoutConfig) error {
+	if config.Flags == nil {
+		config.Flags = []domain.FeatureFlag{}
+	}
+	if config.Experiments == nil {
+		config.Experiments = []domain.LayoutExperiment{}
+	}
+
+	flags := make(map[string]bool, len(config.Flags))
+	for _, flag := range config.Flags {
+		if flag.Key == "" || flags[flag.Key] {
+			return fmt.Errorf("%w: flag keys must be set and unique", domain.ErrInvalidInput)
+		}
+		if flag.RolloutPercent < 0 || flag.RolloutPercent > 100 {
+			return fmt.Errorf("%w: rollout of flag %q must be between 0 and 100", domain.ErrInvalidInput, flag.Key)
+		}
+		flags[flag.Key] = true
+	}
+
+	validModules := func(modules []domain.FeedModule) error {
+		for _, module := range modules {
+			if !domain.IsFeedModule(module.Type) {
+				return fmt.Errorf("%w: unknown module %q", domain.ErrInvalidInput, module.Type)
+			}
+			if module.Flag != "" && !flags[module.Flag] {
+				return fmt.Errorf("%w: module %q uses undefined flag %q", domain.ErrInvalidInput, module.Type, module.Flag)
+			}
+		}
+		return nil
+	}
+
+	if len(config.Modules) == 0 {
+		return fmt.Errorf("%w: the layout needs at least one module", domain.ErrInvalidInput)
+	}
+	if err := validModules(config.Modules); err != nil {
+		return err
+	}
+
+	experiments := make(map[string]bool, len(config.Experiments))
+	for _, experiment := range config.Experiments {
+		if experiment.Key == "" || experiments[experiment.Key] {
+			return fmt.Errorf("%w: experiment keys must be set and unique", domain.ErrInvalidInput)
+		}
+		experiments[experiment.Key] = true
+
+		total := 0
+		for _, variant := range experiment.Variants {
+			if variant.Name == "" || variant.Weight < 0 {
+				return fmt.Errorf("%w: variants of experiment %q need a name and a weight of 0 or more", domain.ErrInvalidInput, experiment.Key)
+			}
+			if err := validModules(variant.Modules); err != nil {
+				return err
+			}
+			total += variant.Weight
+		}
+		if experiment.Enabled && total == 0 {
+			return fmt.Errorf("%w: experiment %q needs a variant with weight", domain.ErrInvalidInput, experiment.Key)
+		}
+	}
+	return nil
+}
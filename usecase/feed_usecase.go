@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// The feed reads from at most this many followed users and friends each
+	maxFeedAudience = 1000
+	// Follow and friendship changes show up in the feed once the cached audience expires
+	feedAudienceTTL = 2 * time.Minute
+)
+
+type feedUseCase struct {
+	postRepo       domain.PostRepository
+	userRepo       domain.UserRepository
+	followRepo     domain.FollowRepository
+	friendshipRepo domain.FriendshipRepository
+	feedRepo       domain.FeedRepository
+}
+
+func NewFeedUseCase(
+	postRepo domain.PostRepository,
+	userRepo domain.UserRepository,
+	followRepo domain.FollowRepository,
+	friendshipRepo domain.FriendshipRepository,
+	feedRepo domain.FeedRepository,
+) domain.FeedUseCase {
+	return &feedUseCase{
+		postRepo:       postRepo,
+		userRepo:       userRepo,
+		followRepo:     followRepo,
+		friendshipRepo: friendshipRepo,
+		feedRepo:       feedRepo,
+	}
+}
+
+// GetFeed aggregates posts from the user, their friends and the users they follow, newest first
+func (f *feedUseCase) GetFeed(userID primitive.ObjectID, limit, offset int) ([]domain.PostWithDetails, error) {
+	logger := utils.NewLogger("FeedUseCase.GetFeed")
+	input := map[string]interface{}{
+		"userID": userID,
+		"limit":  limit,
+		"offset": offset,
+	}
+	logger.LogInput(input)
+
+	audience, err := f.getAudience(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	posts, err := f.postRepo.FindFeed(userID, audience, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	result, err := withAuthors(f.userRepo, posts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(result)}, nil)
+	return result, nil
+}
+
+func (f *feedUseCase) CountFeed(userID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("FeedUseCase.CountFeed")
+	logger.LogInput(userID)
+
+	audience, err := f.getAudience(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	count, err := f.postRepo.CountFeed(userID, audience)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+// getAudience loads followed users and friends, served from Redis while the cached copy is fresh
+func (f *feedUseCase) getAudience(userID primitive.ObjectID) (*domain.FeedAudience, error) {
+	logger := utils.NewLogger("FeedUseCase.getAudience")
+	logger.LogInput(userID)
+
+	audience, err := f.feedRepo.GetAudience(userID)
+	if err != nil {
+		// Fall back to the database on cache errors
+		logger.LogOutput(nil, err)
+	}
+	if audience != nil {
+		logger.LogOutput(audience, nil)
+		return audience, nil
+	}
+
+	follows, err := f.followRepo.FindFollowing(userID, maxFeedAudience, 0)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	friendships, err := f.friendshipRepo.FindFriends(userID, maxFeedAudience, 0)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	audience = &domain.FeedAudience{
+		FollowingIDs: make([]primitive.ObjectID, 0, len(follows)),
+		FriendIDs:    make([]primitive.ObjectID, 0, len(friendships)),
+	}
+	for _, follow := range follows {
+		audience.FollowingIDs = append(audience.FollowingIDs, follow.FollowingID)
+	}
+	for _, friendship := range friendships {
+		friendID := friendship.UserID1
+		if friendID == userID {
+			friendID = friendship.UserID2
+		}
+		audience.FriendIDs = append(audience.FriendIDs, friendID)
+	}
+
+	if err := f.feedRepo.SetAudience(userID, audience, feedAudienceTTL); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	logger.LogOutput(audience, nil)
+	return audience, nil
+}
@@ -0,0 +1,451 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fileUseCase struct {
+	fileRepo        domain.FileRepository
+	storedFileRepo  domain.StoredFileRepository
+	mediaBlobRepo   domain.MediaBlobRepository
+	bannedMediaRepo domain.BannedMediaRepository
+	chatRepo        domain.ChatRepository
+	quota           int64
+	imageProcessing map[string]domain.ImageProcessing
+}
+
+// NewFileUseCase creates the file use case. A quota of 0 means storage is unlimited.
+// Upload contexts missing from imageProcessing use its UploadContextDefault entry.
+func NewFileUseCase(
+	fileRepo domain.FileRepository,
+	storedFileRepo domain.StoredFileRepository,
+	mediaBlobRepo domain.MediaBlobRepository,
+	bannedMediaRepo domain.BannedMediaRepository,
+	chatRepo domain.ChatRepository,
+	quota int64,
+	imageProcessing map[string]domain.ImageProcessing,
+) domain.FileUseCase {
+	return &fileUseCase{
+		fileRepo:        fileRepo,
+		storedFileRepo:  storedFileRepo,
+		mediaBlobRepo:   mediaBlobRepo,
+		bannedMediaRepo: bannedMediaRepo,
+		chatRepo:        chatRepo,
+		quota:           quota,
+		imageProcessing: imageProcessing,
+	}
+}
+
+func (u *fileUseCase) Upload(userID primitive.ObjectID, file *domain.File, fileData io.Reader) (*domain.File, error) {
+	logger := utils.NewLogger("FileUseCase.Upload")
+	logger.LogInput(map[string]interface{}{
+		"userID":      userID,
+		"fileName":    file.FileName,
+		"contentType": file.ContentType,
+		"size":        file.Size,
+		"context":     file.Context,
+		"private":     file.ACL != nil,
+	})
+
+	if file.Context != "" && !domain.IsValidUploadContext(file.Context) {
+		err := fmt.Errorf("%w: unknown upload context %q", domain.ErrInvalidInput, file.Context)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Chat uploads are private until sent to a room
+	if file.ACL != nil || file.Context == domain.UploadContextChat {
+		file.ACL = &domain.FileACL{OwnerID: userID}
+	}
+
+	if u.quota > 0 {
+		used, _, err := u.storedFileRepo.GetUsage(userID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if used+file.Size > u.quota {
+			err := fmt.Errorf("%w: %d of %d bytes used, the file needs %d more", domain.ErrStorageQuotaExceeded, used, u.quota, file.Size)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	data, err := io.ReadAll(fileData)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if strings.HasPrefix(file.ContentType, "image/") {
+		data, err = u.processImage(file, data)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+
+		// Formats the server can't decode go through without a hash
+		if hash, err := utils.PerceptualHash(data); err == nil {
+			file.PerceptualHash = hash
+		}
+		if err := u.checkBanned(file.PerceptualHash); err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	blob, err := u.storeBlob(file, data)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	err = u.storedFileRepo.Create(&domain.StoredFile{
+		UserID:      userID,
+		FileName:    blob.FileName,
+		FileURL:     blob.FileURL,
+		ContentType: blob.ContentType,
+		Size:        blob.Size,
+		BlobID:      blob.ID,
+		ACL:         file.ACL,
+	})
+	if err != nil {
+		// An unaccounted file would slip past the quota, so don't hand it out
+		u.releaseBlob(blob.ID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	uploaded := &domain.File{
+		FileName:       blob.FileName,
+		FileURL:        blob.FileURL,
+		ContentType:    blob.ContentType,
+		Size:           blob.Size,
+		Duration:       file.Duration,
+		Context:        file.Context,
+		PerceptualHash: blob.PerceptualHash,
+		ACL:            file.ACL,
+	}
+	logger.LogOutput(uploaded, nil)
+	return uploaded, nil
+}
+
+// processImage strips metadata and normalizes orientation as configured for the upload context
+func (u *fileUseCase) processImage(file *domain.File, data []byte) ([]byte, error) {
+	processing, ok := u.imageProcessing[file.Context]
+	if !ok {
+		processing = u.imageProcessing[domain.UploadContextDefault]
+	}
+	if !processing.StripMetadata && !processing.NormalizeOrientation {
+		return data, nil
+	}
+
+	processed, err := utils.ProcessImage(data, file.ContentType, processing)
+	if err != nil {
+		return nil, fmt.Errorf("%w: image could not be processed: %v", domain.ErrInvalidInput, err)
+	}
+	return processed, nil
+}
+
+// checkBanned rejects images close to a banned perceptual hash
+func (u *fileUseCase) checkBanned(hash string) error {
+	if hash == "" {
+		return nil
+	}
+
+	banned, err := u.bannedMediaRepo.ListHashes()
+	if err != nil {
+		return err
+	}
+	for _, bannedHash := range banned {
+		if d := utils.PerceptualHashDistance(hash, bannedHash); d >= 0 && d <= domain.BannedMediaMaxDistance {
+			return domain.ErrBannedMedia
+		}
+	}
+	return nil
+}
+
+// storeBlob returns the blob holding the content, uploading it only when no identical
+// content is stored yet. The returned blob has a reference taken for the caller.
+// Private files are stored apart, so they only share blobs with other private files.
+func (u *fileUseCase) storeBlob(file *domain.File, data []byte) (*domain.MediaBlob, error) {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if file.ACL != nil {
+		checksum = "private:" + checksum
+	}
+
+	blob, err := u.mediaBlobRepo.FindByChecksum(checksum)
+	if err != nil {
+		return nil, err
+	}
+	if blob != nil {
+		return u.mediaBlobRepo.AddReference(blob.ID, 1)
+	}
+
+	uploaded, err := u.fileRepo.Upload(file, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	blob = &domain.MediaBlob{
+		Checksum:       checksum,
+		FileName:       uploaded.FileName,
+		FileURL:        uploaded.FileURL,
+		ContentType:    uploaded.ContentType,
+		Size:           uploaded.Size,
+		PerceptualHash: file.PerceptualHash,
+		RefCount:       1,
+	}
+	// Private files are only fetched through download links, at full size
+	if file.ACL == nil {
+		blob.Width, blob.VariantWidths = u.storeVariants(uploaded, data)
+	}
+	err = u.mediaBlobRepo.Create(blob)
+	if err == nil {
+		return blob, nil
+	}
+
+	// Either way our copy isn't referenced
+	u.deleteObjects(blob)
+	if !errors.Is(err, domain.ErrDuplicate) {
+		return nil, err
+	}
+
+	// An identical upload finished first, share its blob
+	blob, err = u.mediaBlobRepo.FindByChecksum(checksum)
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, domain.ErrInternalError
+	}
+	return u.mediaBlobRepo.AddReference(blob.ID, 1)
+}
+
+// releaseBlob drops a reference and deletes the stored object with the last one
+func (u *fileUseCase) releaseBlob(blobID primitive.ObjectID) {
+	logger := utils.NewLogger("FileUseCase.releaseBlob")
+
+	blob, err := u.mediaBlobRepo.AddReference(blobID, -1)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+	if blob.RefCount > 0 {
+		return
+	}
+
+	if err := u.deleteObjects(blob); err != nil {
+		return
+	}
+	if err := u.mediaBlobRepo.Delete(blob.ID); err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
+// storeVariants stores the scaled down variants of a JPEG or PNG image and
+// returns its width and the variant widths. Images the variants can't be made
+// of are served at full size.
+func (u *fileUseCase) storeVariants(original *domain.File, data []byte) (int, []int) {
+	logger := utils.NewLogger("FileUseCase.storeVariants")
+
+	if original.ContentType != "image/jpeg" && original.ContentType != "image/png" {
+		return 0, nil
+	}
+	width, err := utils.ImageWidth(data)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, nil
+	}
+
+	var variantWidths []int
+	for _, variantWidth := range domain.MediaVariantWidths {
+		if variantWidth >= width {
+			break
+		}
+		resized, err := utils.ResizeImage(data, original.ContentType, variantWidth)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			break
+		}
+		if _, err := u.fileRepo.UploadVariant(original, variantWidth, bytes.NewReader(resized)); err != nil {
+			logger.LogOutput(nil, err)
+			break
+		}
+		variantWidths = append(variantWidths, variantWidth)
+	}
+
+	logger.LogOutput(variantWidths, nil)
+	return width, variantWidths
+}
+
+// deleteObjects deletes the blob's stored object and its variants, reporting the
+// object's deletion failure
+func (u *fileUseCase) deleteObjects(blob *domain.MediaBlob) error {
+	logger := utils.NewLogger("FileUseCase.deleteObjects")
+
+	for _, width := range blob.VariantWidths {
+		if err := u.fileRepo.Delete(domain.MediaVariantName(blob.FileName, width)); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+	if err := u.fileRepo.Delete(blob.FileName); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteFile removes one of the user's uploads and frees its storage.
+// The stored object is kept while other uploads share it.
+func (u *fileUseCase) DeleteFile(userID primitive.ObjectID, url string) error {
+	logger := utils.NewLogger("FileUseCase.DeleteFile")
+	logger.LogInput(userID, url)
+
+	stored, err := u.storedFileRepo.FindByURL(userID, url)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := u.storedFileRepo.Delete(stored.ID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if stored.BlobID.IsZero() {
+		if err := u.fileRepo.Delete(stored.FileName); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	} else {
+		u.releaseBlob(stored.BlobID)
+	}
+
+	logger.LogOutput("File deleted successfully", nil)
+	return nil
+}
+
+func (u *fileUseCase) GetStorageUsage(userID primitive.ObjectID) (*domain.StorageUsage, error) {
+	logger := utils.NewLogger("FileUseCase.GetStorageUsage")
+	logger.LogInput(userID)
+
+	used, count, err := u.storedFileRepo.GetUsage(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	usage := &domain.StorageUsage{
+		UsedBytes:  used,
+		QuotaBytes: u.quota,
+		FileCount:  count,
+	}
+	logger.LogOutput(usage, nil)
+	return usage, nil
+}
+
+func (u *fileUseCase) GetDownload(userID primitive.ObjectID, url string) (*domain.FileDownload, error) {
+	logger := utils.NewLogger("FileUseCase.GetDownload")
+	logger.LogInput(userID, url)
+
+	files, err := u.storedFileRepo.FindAllByURL(url)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len(files) == 0 {
+		err := domain.NewNotFoundError("file", url)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Identical uploads share the stored object, so any upload letting the user in does
+	allowed := false
+	for _, file := range files {
+		if file.ACL == nil {
+			logger.LogOutput("public", nil)
+			return &domain.FileDownload{URL: url}, nil
+		}
+		if !allowed {
+			allowed, err = u.canDownload(userID, file.ACL)
+			if err != nil {
+				logger.LogOutput(nil, err)
+				return nil, err
+			}
+		}
+	}
+	if !allowed {
+		// Not telling the file exists
+		err := domain.NewNotFoundError("file", url)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(domain.FileDownloadTTL)
+	signedURL, err := u.fileRepo.SignedURL(files[0].FileName, expiresAt)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(expiresAt, nil)
+	return &domain.FileDownload{URL: signedURL, ExpiresAt: &expiresAt}, nil
+}
+
+// canDownload reports whether the user owns the file, is listed by its ACL or is a member of one of its rooms
+func (u *fileUseCase) canDownload(userID primitive.ObjectID, acl *domain.FileACL) (bool, error) {
+	if acl.OwnerID == userID {
+		return true, nil
+	}
+	for _, id := range acl.UserIDs {
+		if id == userID {
+			return true, nil
+		}
+	}
+	for _, roomID := range acl.RoomIDs {
+		room, err := u.chatRepo.GetRoom(roomID)
+		if err != nil {
+			return false, err
+		}
+		if room != nil && utils.Contains(room.Members, userID.Hex()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (u *fileUseCase) ShareFile(userID primitive.ObjectID, url string, userIDs []string) error {
+	logger := utils.NewLogger("FileUseCase.ShareFile")
+	logger.LogInput(userID, url, userIDs)
+
+	ids := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, userIDHex := range userIDs {
+		id, err := primitive.ObjectIDFromHex(userIDHex)
+		if err != nil {
+			logger.LogOutput(nil, domain.ErrInvalidID)
+			return domain.ErrInvalidID
+		}
+		ids = append(ids, id)
+	}
+
+	if err := u.storedFileRepo.SetSharedUsers(userID, url, ids); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
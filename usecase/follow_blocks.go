@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cascadeBlock ends the blocker's follow of the blocked user and marks their
+// friendship blocked, so pending requests can't be accepted and the friends-only
+// content of either is hidden from the other. The blocked user's follow was
+// already turned into the block itself.
+func (f *followUseCase) cascadeBlock(userID, blockedID primitive.ObjectID) error {
+	follow, err := f.followRepo.FindByFollowerAndFollowing(userID, blockedID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	// A blocked status there is the other user's block of the blocker, which stays
+	if follow != nil && follow.Status == "active" {
+		if err := f.followRepo.Delete(userID, blockedID); err != nil {
+			return err
+		}
+	}
+
+	friendship, err := f.friendshipRepo.FindByUsers(userID, blockedID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if friendship == nil {
+		now := time.Now()
+		return f.friendshipRepo.Create(&domain.Friendship{
+			BaseModel:   domain.BaseModel{CreatedAt: now, UpdatedAt: now},
+			UserID1:     userID,
+			UserID2:     blockedID,
+			Status:      "blocked",
+			RequestedBy: userID,
+		})
+	}
+	if friendship.Status == "blocked" {
+		return nil
+	}
+	friendship.Status = "blocked"
+	return f.friendshipRepo.Update(friendship)
+}
+
+// liftBlockedFriendship removes the blocked friendship once neither user blocks
+// the other, so they can send friend requests again
+func (f *followUseCase) liftBlockedFriendship(userID, blockedID primitive.ObjectID) error {
+	stillBlocked, err := f.IsBlocked(blockedID, userID)
+	if err != nil || stillBlocked {
+		return err
+	}
+
+	friendship, err := f.friendshipRepo.FindByUsers(userID, blockedID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if friendship.Status != "blocked" {
+		return nil
+	}
+	return f.friendshipRepo.Delete(userID, blockedID)
+}
+
+func (f *followUseCase) GetBlockedIDs(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	logger := utils.NewLogger("FollowUseCase.GetBlockedIDs")
+	logger.LogInput(userID)
+
+	ids, err := f.followRepo.FindBlockedIDs(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(ids)}, nil)
+	return ids, nil
+}
+
+// ListBlocked lists the accounts the user blocked, latest first. Deleted accounts
+// are skipped but still counted.
+func (f *followUseCase) ListBlocked(userID primitive.ObjectID, limit, offset int) ([]domain.BlockedUser, int64, error) {
+	logger := utils.NewLogger("FollowUseCase.ListBlocked")
+	logger.LogInput(userID, limit, offset)
+
+	blocks, err := f.followRepo.FindBlocks(userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, 0, err
+	}
+
+	total, err := f.followRepo.CountBlocks(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, 0, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(blocks))
+	for _, block := range blocks {
+		ids = append(ids, block.FollowerID)
+	}
+	users, err := f.userRepo.FindByIDs(ids)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, 0, err
+	}
+	usersByID := make(map[primitive.ObjectID]*domain.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+
+	blocked := make([]domain.BlockedUser, 0, len(blocks))
+	for _, block := range blocks {
+		user, ok := usersByID[block.FollowerID]
+		if !ok {
+			continue
+		}
+		blocked = append(blocked, domain.BlockedUser{
+			User:      toSearchUser(user),
+			BlockedAt: block.UpdatedAt,
+		})
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(blocked), "total": total}, nil)
+	return blocked, total, nil
+}
+
+// blockedSet returns the accounts the user blocked or that blocked the user
+func blockedSet(followUseCase domain.FollowUseCase, userID primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	ids, err := followUseCase.GetBlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
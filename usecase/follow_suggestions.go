@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetSuggestions recommends accounts to follow: first accounts followed by people
+// the user follows, then popular accounts nearby, then accounts sharing interests.
+// The full list is cached per user and trimmed to limit on the way out.
+func (f *followUseCase) GetSuggestions(userID primitive.ObjectID, limit int) ([]domain.FollowSuggestion, error) {
+	logger := utils.NewLogger("FollowUseCase.GetSuggestions")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID.Hex(),
+		"limit":  limit,
+	})
+
+	if limit <= 0 || limit > domain.MaxFollowSuggestions {
+		limit = domain.MaxFollowSuggestions
+	}
+
+	suggestions, err := f.suggestionRepo.GetCached(userID)
+	if err != nil {
+		// A broken cache shouldn't hide suggestions
+		logger.LogOutput(nil, err)
+	}
+	if suggestions == nil {
+		suggestions, err = f.computeSuggestions(userID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if err := f.suggestionRepo.SetCached(userID, suggestions, domain.FollowSuggestionCacheTTL); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(suggestions)}, nil)
+	return suggestions, nil
+}
+
+// DismissSuggestion hides the account from the user's suggestions for a while
+func (f *followUseCase) DismissSuggestion(userID, suggestedID primitive.ObjectID) error {
+	logger := utils.NewLogger("FollowUseCase.DismissSuggestion")
+	logger.LogInput(map[string]interface{}{
+		"userID":      userID.Hex(),
+		"suggestedID": suggestedID.Hex(),
+	})
+
+	if userID == suggestedID {
+		err := fmt.Errorf("%w: cannot dismiss yourself", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := f.suggestionRepo.Dismiss(userID, suggestedID, domain.FollowSuggestionDismissTTL); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Follow suggestion dismissed", nil)
+	return nil
+}
+
+// clearSuggestions drops cached suggestions after the follow graph changed
+func (f *followUseCase) clearSuggestions(userIDs ...primitive.ObjectID) {
+	logger := utils.NewLogger("FollowUseCase.clearSuggestions")
+	for _, userID := range userIDs {
+		if err := f.suggestionRepo.ClearCached(userID); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+}
+
+func (f *followUseCase) computeSuggestions(userID primitive.ObjectID) ([]domain.FollowSuggestion, error) {
+	user, err := f.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the user, everyone they follow or have blocked, anyone who blocked them
+	// and accounts they dismissed
+	related, err := f.followRepo.FindRelatedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	dismissed, err := f.suggestionRepo.GetDismissed(userID)
+	if err != nil {
+		return nil, err
+	}
+	exclude := append([]primitive.ObjectID{userID}, related...)
+	exclude = append(exclude, dismissed...)
+
+	suggestions := make([]domain.FollowSuggestion, 0, domain.MaxFollowSuggestions)
+	add := func(candidate domain.User, reason, explanation string, mutualIDs []string) {
+		suggestions = append(suggestions, domain.FollowSuggestion{
+			User:           toSearchUser(&candidate),
+			FollowersCount: candidate.FollowersCount,
+			Reason:         reason,
+			Explanation:    explanation,
+			MutualIDs:      mutualIDs,
+		})
+		exclude = append(exclude, candidate.ID)
+	}
+
+	secondDegree, err := f.followRepo.FindSecondDegree(userID, exclude, domain.MaxFollowSuggestions)
+	if err != nil {
+		return nil, err
+	}
+	if len(secondDegree) > 0 {
+		// Load the suggested accounts and the followed accounts named in explanations at once
+		ids := make([]primitive.ObjectID, 0, len(secondDegree)*2)
+		for _, entry := range secondDegree {
+			ids = append(ids, entry.UserID)
+			ids = append(ids, entry.FollowedBy...)
+		}
+		users, err := f.userRepo.FindByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		usersByID := make(map[primitive.ObjectID]domain.User, len(users))
+		for _, u := range users {
+			usersByID[u.ID] = u
+		}
+
+		for _, entry := range secondDegree {
+			candidate, ok := usersByID[entry.UserID]
+			if !ok {
+				continue
+			}
+			names := make([]string, 0, len(entry.FollowedBy))
+			mutualIDs := make([]string, 0, len(entry.FollowedBy))
+			for _, id := range entry.FollowedBy {
+				if mutual, ok := usersByID[id]; ok {
+					names = append(names, suggestionName(&mutual))
+					mutualIDs = append(mutualIDs, id.Hex())
+				}
+			}
+			if len(names) == 0 {
+				continue
+			}
+			add(candidate, domain.SuggestionReasonFollowedBy, followedByExplanation(names, entry.Count), mutualIDs)
+		}
+	}
+
+	coordinates := user.Location.Coordinates
+	if len(suggestions) < domain.MaxFollowSuggestions && len(coordinates) == 2 && (coordinates[0] != 0 || coordinates[1] != 0) {
+		nearby, err := f.userRepo.FindPopularNearby(coordinates, domain.FollowSuggestionNearbyMeters, exclude, domain.MaxFollowSuggestions-len(suggestions))
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range nearby {
+			add(candidate, domain.SuggestionReasonPopularNearby, "Popular near you", nil)
+		}
+	}
+
+	if len(suggestions) < domain.MaxFollowSuggestions && len(user.Interests) > 0 {
+		similar, err := f.userRepo.FindByInterests(user.Interests, exclude, domain.MaxFollowSuggestions-len(suggestions))
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range similar {
+			shared := sharedInterests(user.Interests, candidate.Interests)
+			add(candidate, domain.SuggestionReasonSharedInterests, "Shares your interests: "+strings.Join(shared, ", "), nil)
+		}
+	}
+
+	return suggestions, nil
+}
+
+func toSearchUser(user *domain.User) domain.SearchUser {
+	return domain.SearchUser{
+		ID:           user.ID.Hex(),
+		Username:     user.Username,
+		DisplayName:  user.DisplayName,
+		PhotoProfile: user.PhotoProfile,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+	}
+}
+
+func suggestionName(user *domain.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
+
+// followedByExplanation reads "Followed by alice", "Followed by alice and bob"
+// or "Followed by alice, bob and 3 others"
+func followedByExplanation(names []string, total int) string {
+	if len(names) > 2 {
+		names = names[:2]
+	}
+	others := total - len(names)
+	switch {
+	case others == 1:
+		return fmt.Sprintf("Followed by %s and 1 other", strings.Join(names, ", "))
+	case others > 1:
+		return fmt.Sprintf("Followed by %s and %d others", strings.Join(names, ", "), others)
+	case len(names) == 2:
+		return fmt.Sprintf("Followed by %s and %s", names[0], names[1])
+	default:
+		return "Followed by " + names[0]
+	}
+}
+
+// sharedInterests returns up to three interests present in both lists
+func sharedInterests(mine, theirs []string) []string {
+	set := make(map[string]bool, len(theirs))
+	for _, interest := range theirs {
+		set[strings.ToLower(interest)] = true
+	}
+	shared := make([]string, 0, 3)
+	for _, interest := range mine {
+		if set[strings.ToLower(interest)] {
+			shared = append(shared, interest)
+			if len(shared) == 3 {
+				break
+			}
+		}
+	}
+	return shared
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"errors"
+	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
@@ -11,13 +12,21 @@ import (
 type followUseCase struct {
 	followRepo         domain.FollowRepository
 	notificationUseCase domain.NotificationUseCase
+	hotContent         domain.HotContentPolicy
+	userRepo           domain.UserRepository
+	suggestionRepo     domain.FollowSuggestionRepository
+	friendshipRepo     domain.FriendshipRepository
 }
 
 // NewFollowUseCase creates a new instance of FollowUseCase
-func NewFollowUseCase(fr domain.FollowRepository, nu domain.NotificationUseCase) domain.FollowUseCase {
+func NewFollowUseCase(fr domain.FollowRepository, nu domain.NotificationUseCase, hotContent domain.HotContentPolicy, ur domain.UserRepository, sr domain.FollowSuggestionRepository, fsr domain.FriendshipRepository) domain.FollowUseCase {
 	return &followUseCase{
 		followRepo:         fr,
 		notificationUseCase: nu,
+		hotContent:         hotContent,
+		userRepo:           ur,
+		suggestionRepo:     sr,
+		friendshipRepo:     fsr,
 	}
 }
 
@@ -36,6 +45,16 @@ func (f *followUseCase) Follow(followerID, followingID primitive.ObjectID) error
 		return err
 	}
 
+	blocked, err := isBlockedEitherWay(f, followerID, followingID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if blocked {
+		logger.LogOutput(nil, domain.ErrBlocked)
+		return domain.ErrBlocked
+	}
+
 	// Check if already following
 	existing, err := f.followRepo.FindByFollowerAndFollowing(followerID, followingID)
 	if err != nil && !errors.Is(err, domain.ErrNotFound) {
@@ -65,15 +84,37 @@ func (f *followUseCase) Follow(followerID, followingID primitive.ObjectID) error
 		return err
 	}
 
-	// Create notification for the user being followed
-	_, err = f.notificationUseCase.CreateNotification(
-		followingID,  // recipientID (user being followed)
-		followerID,   // senderID (user who followed)
-		followerID,   // refID (reference to the follower)
-		domain.NotificationTypeFollow,
-		"user",       // refType
-		"started following you", // message
-	)
+	// The new account must drop out of the follower's suggestions
+	f.clearSuggestions(followerID)
+
+	// Create notification for the user being followed. Users with many followers
+	// get one aggregated notification per window, referencing their own profile.
+	followers, err := f.followRepo.CountFollowers(followingID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+	hot := err == nil && f.hotContent.IsHotUser(followers)
+	recordNotifyStrategy("follow", hot)
+	if hot {
+		_, err = f.notificationUseCase.CreateAggregatedNotification(
+			followingID,
+			followerID,
+			followingID,
+			domain.NotificationTypeFollow,
+			"user",
+			"started following you",
+			f.hotContent.AggregationWindow,
+		)
+	} else {
+		_, err = f.notificationUseCase.CreateNotification(
+			followingID,  // recipientID (user being followed)
+			followerID,   // senderID (user who followed)
+			followerID,   // refID (reference to the follower)
+			domain.NotificationTypeFollow,
+			"user",       // refType
+			"started following you", // message
+		)
+	}
 	if err != nil {
 		logger.LogOutput(nil, err)
 		// Don't return error here as the follow action was successful
@@ -113,7 +154,8 @@ func (f *followUseCase) Unfollow(followerID, followingID primitive.ObjectID) err
 	return nil
 }
 
-// Block updates the follow status to blocked
+// Block updates the follow status to blocked and ends the follows and the
+// friendship between the two users
 func (f *followUseCase) Block(userID, blockedID primitive.ObjectID) error {
 	logger := utils.NewLogger("FollowUseCase.Block")
 	input := map[string]interface{}{
@@ -140,23 +182,29 @@ func (f *followUseCase) Block(userID, blockedID primitive.ObjectID) error {
 			logger.LogOutput(nil, err)
 			return err
 		}
-		logger.LogOutput(nil, nil)
-		return nil
-	}
+	} else {
+		// Create new blocked relationship
+		now := time.Now()
+		follow := &domain.Follow{
+			BaseModel:   domain.BaseModel{CreatedAt: now, UpdatedAt: now},
+			FollowerID:  blockedID,
+			FollowingID: userID,
+			Status:      "blocked",
+		}
 
-	// Create new blocked relationship
-	follow := &domain.Follow{
-		FollowerID:  blockedID,
-		FollowingID: userID,
-		Status:      "blocked",
+		if err := f.followRepo.Create(follow); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
 	}
 
-	if err := f.followRepo.Create(follow); err != nil {
+	if err := f.cascadeBlock(userID, blockedID); err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
+	f.clearSuggestions(userID, blockedID)
 
-	logger.LogOutput(follow, nil)
+	logger.LogOutput(nil, nil)
 	return nil
 }
 
@@ -191,6 +239,11 @@ func (f *followUseCase) Unblock(userID, blockedID primitive.ObjectID) error {
 		return err
 	}
 
+	if err := f.liftBlockedFriendship(userID, blockedID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
 	logger.LogOutput(nil, nil)
 	return nil
 }
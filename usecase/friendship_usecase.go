@@ -12,13 +12,17 @@ import (
 type friendshipUseCase struct {
 	friendshipRepo     domain.FriendshipRepository
 	notificationUseCase domain.NotificationUseCase
+	followUseCase      domain.FollowUseCase
+	privacyUseCase     domain.PrivacyUseCase
 }
 
 // NewFriendshipUseCase creates a new instance of FriendshipUseCase
-func NewFriendshipUseCase(fr domain.FriendshipRepository, nu domain.NotificationUseCase) domain.FriendshipUseCase {
+func NewFriendshipUseCase(fr domain.FriendshipRepository, nu domain.NotificationUseCase, fu domain.FollowUseCase, pu domain.PrivacyUseCase) domain.FriendshipUseCase {
 	return &friendshipUseCase{
 		friendshipRepo:     fr,
 		notificationUseCase: nu,
+		followUseCase:      fu,
+		privacyUseCase:     pu,
 	}
 }
 
@@ -37,6 +41,16 @@ func (f *friendshipUseCase) SendFriendRequest(fromID, toID primitive.ObjectID) e
 		return err
 	}
 
+	blocked, err := isBlockedEitherWay(f.followUseCase, fromID, toID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return domain.ErrInternalError
+	}
+	if blocked {
+		logger.LogOutput(nil, domain.ErrBlocked)
+		return domain.ErrBlocked
+	}
+
 	// Check if friendship already exists
 	existing, err := f.friendshipRepo.FindByUsers(fromID, toID)
 	if err != nil && !errors.Is(err, domain.ErrNotFound) {
@@ -263,7 +277,7 @@ func (f *friendshipUseCase) Unfriend(userID1, userID2 primitive.ObjectID) error
 	return nil
 }
 
-// BlockFriend blocks a user
+// BlockFriend blocks a user across the product, see FollowUseCase.Block
 func (f *friendshipUseCase) BlockFriend(userID, blockedID primitive.ObjectID) error {
 	logger := utils.NewLogger("FriendshipUseCase.BlockFriend")
 	input := map[string]interface{}{
@@ -278,39 +292,17 @@ func (f *friendshipUseCase) BlockFriend(userID, blockedID primitive.ObjectID) er
 		return err
 	}
 
-	friendship, err := f.friendshipRepo.FindByUsers(userID, blockedID)
-	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+	if err := f.followUseCase.Block(userID, blockedID); err != nil {
 		logger.LogOutput(nil, err)
 		return domain.ErrInternalError
 	}
 
-	if friendship != nil {
-		friendship.Status = "blocked"
-		friendship.UpdatedAt = time.Now()
-		if err := f.friendshipRepo.Update(friendship); err != nil {
-			logger.LogOutput(nil, err)
-			return domain.ErrInternalError
-		}
-	} else {
-		// Create new blocked relationship
-		friendship = &domain.Friendship{
-			UserID1:     userID,
-			UserID2:     blockedID,
-			Status:      "blocked",
-			RequestedBy: userID,
-		}
-
-		if err := f.friendshipRepo.Create(friendship); err != nil {
-			logger.LogOutput(nil, err)
-			return domain.ErrInternalError
-		}
-	}
-
-	logger.LogOutput(friendship, nil)
+	logger.LogOutput(nil, nil)
 	return nil
 }
 
-// UnblockFriend removes a block
+// UnblockFriend removes a block. Friendships blocked before blocks cascaded
+// have no block behind them and are removed directly.
 func (f *friendshipUseCase) UnblockFriend(userID, blockedID primitive.ObjectID) error {
 	logger := utils.NewLogger("FriendshipUseCase.UnblockFriend")
 	input := map[string]interface{}{
@@ -319,6 +311,20 @@ func (f *friendshipUseCase) UnblockFriend(userID, blockedID primitive.ObjectID)
 	}
 	logger.LogInput(input)
 
+	blocked, err := f.followUseCase.IsBlocked(userID, blockedID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return domain.ErrInternalError
+	}
+	if blocked {
+		if err := f.followUseCase.Unblock(userID, blockedID); err != nil {
+			logger.LogOutput(nil, err)
+			return domain.ErrInternalError
+		}
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
 	friendship, err := f.friendshipRepo.FindByUsers(userID, blockedID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
@@ -328,7 +334,8 @@ func (f *friendshipUseCase) UnblockFriend(userID, blockedID primitive.ObjectID)
 		return err
 	}
 
-	if friendship.Status != "blocked" {
+	// Blocked the other way round, that block is not the user's to lift
+	if friendship.Status != "blocked" || friendship.RequestedBy != userID {
 		err = domain.ErrInvalidInput
 		logger.LogOutput(nil, err)
 		return err
@@ -435,6 +442,37 @@ func (f *friendshipUseCase) ListFriends(userID primitive.ObjectID, limit, offset
 	return f.friendshipRepo.FindFriends(userID, limit, offset)
 }
 
+func (f *friendshipUseCase) ListUserFriends(viewerID, userID primitive.ObjectID, limit, offset int) ([]domain.Friendship, error) {
+	logger := utils.NewLogger("FriendshipUseCase.ListUserFriends")
+	logger.LogInput(viewerID, userID, limit, offset)
+
+	if viewerID != userID {
+		blocked, err := isBlockedEitherWay(f.followUseCase, viewerID, userID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if blocked {
+			logger.LogOutput(nil, domain.ErrBlocked)
+			return nil, domain.ErrBlocked
+		}
+	}
+
+	if err := f.privacyUseCase.CheckCanSeeFriends(viewerID, userID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	friends, err := f.friendshipRepo.FindFriends(userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(friends)}, nil)
+	return friends, nil
+}
+
 // ListFriendRequests returns a list of friend requests
 func (f *friendshipUseCase) ListFriendRequests(userID primitive.ObjectID, limit, offset int) ([]domain.Friendship, error) {
 	return f.friendshipRepo.FindPendingRequests(userID, limit, offset)
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type hashtagUseCase struct {
+	hashtagRepo domain.HashtagRepository
+	postRepo    domain.PostRepository
+	userRepo    domain.UserRepository
+}
+
+func NewHashtagUseCase(
+	hashtagRepo domain.HashtagRepository,
+	postRepo domain.PostRepository,
+	userRepo domain.UserRepository,
+) domain.HashtagUseCase {
+	return &hashtagUseCase{
+		hashtagRepo: hashtagRepo,
+		postRepo:    postRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (h *hashtagUseCase) GetTagPosts(viewerID primitive.ObjectID, tag string, limit, offset int) ([]domain.PostWithDetails, error) {
+	logger := utils.NewLogger("HashtagUseCase.GetTagPosts")
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID,
+		"tag":      tag,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	normalized := utils.NormalizeHashtag(tag)
+	if normalized == "" {
+		err := fmt.Errorf("%w: invalid tag", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	posts, err := h.postRepo.FindByTag(viewerID, normalized, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	result, err := withAuthors(h.userRepo, posts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(result)}, nil)
+	return result, nil
+}
+
+func (h *hashtagUseCase) CountTagPosts(viewerID primitive.ObjectID, tag string) (int64, error) {
+	logger := utils.NewLogger("HashtagUseCase.CountTagPosts")
+	logger.LogInput(viewerID, tag)
+
+	normalized := utils.NormalizeHashtag(tag)
+	if normalized == "" {
+		err := fmt.Errorf("%w: invalid tag", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	count, err := h.postRepo.CountByTag(viewerID, normalized)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (h *hashtagUseCase) GetTrending(window time.Duration, limit int) ([]domain.TrendingTag, error) {
+	logger := utils.NewLogger("HashtagUseCase.GetTrending")
+	logger.LogInput(window.String(), limit)
+
+	if window == 0 {
+		window = domain.DefaultTrendingWindow
+	}
+	if window < time.Hour || window > domain.MaxTrendingWindow || limit <= 0 {
+		err := fmt.Errorf("%w: window must be between 1h and %s", domain.ErrInvalidInput, domain.MaxTrendingWindow)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	trending, err := h.hashtagRepo.FindTrending(window, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(trending, nil)
+	return trending, nil
+}
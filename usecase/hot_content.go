@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// recordNotifyStrategy counts which notification strategy was taken for an event,
+// exposed under counters on the internal metrics endpoint
+func recordNotifyStrategy(event string, aggregated bool) {
+	strategy := "direct"
+	if aggregated {
+		strategy = "aggregated"
+	}
+	utils.IncrementMetric("notifications." + event + "." + strategy)
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var interestSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type interestUseCase struct {
+	interestRepo domain.InterestRepository
+}
+
+func NewInterestUseCase(interestRepo domain.InterestRepository) domain.InterestUseCase {
+	return &interestUseCase{
+		interestRepo: interestRepo,
+	}
+}
+
+func (u *interestUseCase) SearchInterests(query, category, lang string, limit int) ([]domain.InterestOption, error) {
+	logger := utils.NewLogger("InterestUseCase.SearchInterests")
+	logger.LogInput(map[string]interface{}{
+		"query":    query,
+		"category": category,
+		"lang":     lang,
+		"limit":    limit,
+	})
+
+	if limit <= 0 {
+		limit = domain.DefaultInterestSearchLimit
+	}
+	if limit > domain.MaxInterestSearchLimit {
+		limit = domain.MaxInterestSearchLimit
+	}
+
+	interests, err := u.interestRepo.Search(strings.ToLower(strings.TrimSpace(query)), category, limit)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	options := make([]domain.InterestOption, 0, len(interests))
+	for i := range interests {
+		options = append(options, domain.InterestOption{
+			Slug:     interests[i].Slug,
+			Category: interests[i].Category,
+			Label:    interests[i].Label(lang),
+		})
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(options)}, nil)
+	return options, nil
+}
+
+func (u *interestUseCase) ResolveInterests(values []string) ([]string, []string, error) {
+	logger := utils.NewLogger("InterestUseCase.ResolveInterests")
+	logger.LogInput(values)
+
+	terms := make([]string, 0, len(values))
+	for _, value := range values {
+		if term := strings.ToLower(strings.TrimSpace(value)); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		logger.LogOutput(nil, nil)
+		return []string{}, []string{}, nil
+	}
+
+	interests, err := u.interestRepo.FindByTerms(terms)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, nil, err
+	}
+	slugByTerm := make(map[string]string)
+	for _, interest := range interests {
+		for _, term := range interest.SearchTerms {
+			slugByTerm[term] = interest.Slug
+		}
+	}
+
+	slugs := make([]string, 0, len(terms))
+	legacy := make([]string, 0)
+	seen := make(map[string]bool, len(terms))
+	for i, value := range values {
+		term := strings.ToLower(strings.TrimSpace(value))
+		if term == "" {
+			continue
+		}
+		if slug, ok := slugByTerm[term]; ok {
+			if !seen[slug] {
+				seen[slug] = true
+				slugs = append(slugs, slug)
+			}
+			continue
+		}
+		if !seen["legacy:"+term] {
+			seen["legacy:"+term] = true
+			legacy = append(legacy, strings.TrimSpace(values[i]))
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{
+		"slugs":  slugs,
+		"legacy": legacy,
+	}, nil)
+	return slugs, legacy, nil
+}
+
+func (u *interestUseCase) ListInterests(category string) ([]domain.Interest, error) {
+	logger := utils.NewLogger("InterestUseCase.ListInterests")
+	logger.LogInput(category)
+
+	interests, err := u.interestRepo.List(category, false)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(interests)}, nil)
+	return interests, nil
+}
+
+func (u *interestUseCase) CreateInterest(interest *domain.Interest) error {
+	logger := utils.NewLogger("InterestUseCase.CreateInterest")
+	logger.LogInput(interest)
+
+	interest.Slug = strings.ToLower(strings.TrimSpace(interest.Slug))
+	if !interestSlugPattern.MatchString(interest.Slug) {
+		err := fmt.Errorf("%w: slug must be lowercase letters and digits separated by dashes", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if err := prepareInterest(interest); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	err := u.interestRepo.Create(interest)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(interest, nil)
+	return nil
+}
+
+func (u *interestUseCase) UpdateInterest(id primitive.ObjectID, update domain.InterestUpdate) (*domain.Interest, error) {
+	logger := utils.NewLogger("InterestUseCase.UpdateInterest")
+	logger.LogInput(map[string]interface{}{
+		"id":     id,
+		"update": update,
+	})
+
+	interest, err := u.interestRepo.FindByID(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if update.Category != nil {
+		interest.Category = *update.Category
+	}
+	if update.Labels != nil {
+		interest.Labels = update.Labels
+	}
+	if update.IsActive != nil {
+		interest.IsActive = *update.IsActive
+	}
+	if err := prepareInterest(interest); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	err = u.interestRepo.Update(interest)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(interest, nil)
+	return interest, nil
+}
+
+func (u *interestUseCase) DeleteInterest(id primitive.ObjectID) error {
+	logger := utils.NewLogger("InterestUseCase.DeleteInterest")
+	logger.LogInput(id)
+
+	err := u.interestRepo.Delete(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// prepareInterest validates the category and labels and rebuilds the search terms
+func prepareInterest(interest *domain.Interest) error {
+	interest.Category = strings.ToLower(strings.TrimSpace(interest.Category))
+	if interest.Category == "" {
+		return fmt.Errorf("%w: category is required", domain.ErrInvalidInput)
+	}
+
+	labels := make(map[string]string, len(interest.Labels))
+	for lang, label := range interest.Labels {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		label = strings.TrimSpace(label)
+		if lang != "" && label != "" {
+			labels[lang] = label
+		}
+	}
+	if labels[domain.DefaultInterestLanguage] == "" {
+		return fmt.Errorf("%w: a %q label is required", domain.ErrInvalidInput, domain.DefaultInterestLanguage)
+	}
+	interest.Labels = labels
+
+	terms := []string{interest.Slug}
+	seen := map[string]bool{interest.Slug: true}
+	for _, label := range labels {
+		term := strings.ToLower(label)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	interest.SearchTerms = terms
+	return nil
+}
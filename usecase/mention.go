@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resolveMentions returns the IDs of the users @mentioned in content, without duplicates.
+// Usernames that don't belong to anyone are skipped.
+func resolveMentions(userRepo domain.UserRepository, content string) []primitive.ObjectID {
+	logger := utils.NewLogger("resolveMentions")
+
+	mentions := make([]primitive.ObjectID, 0)
+	seen := make(map[primitive.ObjectID]bool)
+	for _, username := range utils.ExtractMentions(content) {
+		user, err := userRepo.FindByUsername(username)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			continue
+		}
+		if user == nil || seen[user.ID] {
+			continue
+		}
+		seen[user.ID] = true
+		mentions = append(mentions, user.ID)
+	}
+	return mentions
+}
+
+// notifyMentions sends a mention notification to every mentioned user, except the author
+// and users in alreadyMentioned, so edits only notify newly mentioned users.
+// Failures are logged, the content itself is already saved.
+func notifyMentions(
+	notificationUseCase domain.NotificationUseCase,
+	authorID, refID primitive.ObjectID,
+	refType, message string,
+	mentions, alreadyMentioned []primitive.ObjectID,
+) {
+	logger := utils.NewLogger("notifyMentions")
+
+	skip := make(map[primitive.ObjectID]bool, len(alreadyMentioned)+1)
+	skip[authorID] = true
+	for _, id := range alreadyMentioned {
+		skip[id] = true
+	}
+
+	for _, recipientID := range mentions {
+		if skip[recipientID] {
+			continue
+		}
+		_, err := notificationUseCase.CreateNotification(
+			recipientID,
+			authorID,
+			refID,
+			domain.NotificationTypeMention,
+			refType,
+			message,
+		)
+		if err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+}
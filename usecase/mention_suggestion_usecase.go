@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mentionPostCommenters is how many of a post's comments are scanned for participants
+const mentionPostCommenters = 100
+
+type mentionUseCase struct {
+	mentionRepo    domain.MentionRepository
+	userRepo       domain.UserRepository
+	friendshipRepo domain.FriendshipRepository
+	postRepo       domain.PostRepository
+	commentRepo    domain.CommentRepository
+	chatRepo       domain.ChatRepository
+}
+
+func NewMentionUseCase(
+	mentionRepo domain.MentionRepository,
+	userRepo domain.UserRepository,
+	friendshipRepo domain.FriendshipRepository,
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	chatRepo domain.ChatRepository,
+) domain.MentionUseCase {
+	return &mentionUseCase{
+		mentionRepo:    mentionRepo,
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+		postRepo:       postRepo,
+		commentRepo:    commentRepo,
+		chatRepo:       chatRepo,
+	}
+}
+
+// SuggestMentions matches the prefix against usernames, display names and first
+// and last names of the cached candidates. Other users are only looked up by
+// username, and only when the context and friends don't fill the limit.
+func (u *mentionUseCase) SuggestMentions(userID primitive.ObjectID, prefix, context string, limit int) ([]domain.MentionSuggestion, error) {
+	logger := utils.NewLogger("MentionUseCase.SuggestMentions")
+	logger.LogInput(userID, prefix, context, limit)
+
+	if limit < 1 || limit > domain.MaxMentionSuggestions {
+		err := fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, domain.MaxMentionSuggestions)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	prefix = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(prefix), "@"))
+
+	type group struct {
+		source     string
+		candidates []domain.SearchUser
+	}
+	groups := make([]group, 0, 3)
+
+	if context != "" {
+		members, err := u.contextCandidates(userID, context)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		groups = append(groups, group{source: domain.MentionSourceContext, candidates: members})
+	}
+
+	friends, err := u.friendCandidates(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	groups = append(groups, group{source: domain.MentionSourceFriend, candidates: friends})
+
+	// Only the matches are ranked, so the interaction lookup stays small
+	seen := map[string]bool{userID.Hex(): true}
+	matches := make([]domain.MentionSuggestion, 0)
+	for _, g := range groups {
+		for _, candidate := range g.candidates {
+			if seen[candidate.ID] || !mentionMatches(candidate, prefix) {
+				continue
+			}
+			seen[candidate.ID] = true
+			matches = append(matches, domain.MentionSuggestion{User: candidate, Source: g.source})
+		}
+	}
+
+	if len(matches) < limit && prefix != "" {
+		others, err := u.usernameCandidates(prefix)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		for _, candidate := range others {
+			if seen[candidate.ID] {
+				continue
+			}
+			seen[candidate.ID] = true
+			matches = append(matches, domain.MentionSuggestion{User: candidate, Source: domain.MentionSourceOther})
+		}
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.User.ID)
+	}
+	interactions, err := u.mentionRepo.GetInteractions(userID, ids)
+	if err != nil {
+		// Suggestions are still useful unranked
+		logger.LogOutput(nil, err)
+	}
+	for i := range matches {
+		if at, ok := interactions[matches[i].User.ID]; ok {
+			matches[i].LastInteractionAt = &at
+		}
+	}
+
+	// Keep the source order, then the latest interactions first. Candidates
+	// without one keep their cached order.
+	sourceRank := map[string]int{
+		domain.MentionSourceContext: 0,
+		domain.MentionSourceFriend:  1,
+		domain.MentionSourceOther:   2,
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Source != b.Source {
+			return sourceRank[a.Source] < sourceRank[b.Source]
+		}
+		if a.LastInteractionAt == nil || b.LastInteractionAt == nil {
+			return a.LastInteractionAt != nil && b.LastInteractionAt == nil
+		}
+		return a.LastInteractionAt.After(*b.LastInteractionAt)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(matches)}, nil)
+	return matches, nil
+}
+
+// contextCandidates returns the members of "room:<id>" or the participants of
+// "post:<id>": its author, the users it mentions and its recent commenters.
+// Rooms the user isn't in and posts they can't see are reported as not found.
+func (u *mentionUseCase) contextCandidates(userID primitive.ObjectID, context string) ([]domain.SearchUser, error) {
+	kind, id, _ := strings.Cut(context, ":")
+	switch kind {
+	case domain.MentionContextRoom:
+		room, err := u.chatRepo.GetRoom(id)
+		if err != nil {
+			return nil, err
+		}
+		if room == nil || !utils.Contains(room.Members, userID.Hex()) {
+			return nil, domain.NewNotFoundError("chat room", id)
+		}
+		return u.cachedCandidates("room:"+id, domain.MentionContextCacheTTL, func() ([]primitive.ObjectID, error) {
+			memberIDs := make([]primitive.ObjectID, 0, len(room.Members))
+			for _, member := range room.Members {
+				memberID, err := primitive.ObjectIDFromHex(member)
+				if err != nil {
+					continue
+				}
+				memberIDs = append(memberIDs, memberID)
+			}
+			return memberIDs, nil
+		})
+
+	case domain.MentionContextPost:
+		postID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, domain.ErrInvalidID
+		}
+		post, err := u.postRepo.FindByID(postID)
+		if err != nil {
+			return nil, err
+		}
+		canView, err := canViewPost(u.friendshipRepo, userID, post)
+		if err != nil {
+			return nil, err
+		}
+		if !canView {
+			return nil, domain.NewNotFoundError("post", id)
+		}
+		return u.cachedCandidates("post:"+id, domain.MentionContextCacheTTL, func() ([]primitive.ObjectID, error) {
+			participantIDs := append([]primitive.ObjectID{post.UserID}, post.Mentions...)
+			comments, err := u.commentRepo.FindByPostID(postID, nil, mentionPostCommenters, 0)
+			if err != nil {
+				return nil, err
+			}
+			for _, comment := range comments {
+				participantIDs = append(participantIDs, comment.UserID)
+			}
+			return participantIDs, nil
+		})
+	}
+	return nil, fmt.Errorf("%w: context must be post:<id> or room:<id>", domain.ErrInvalidInput)
+}
+
+// friendCandidates returns the user's friends, the latest friendships first
+func (u *mentionUseCase) friendCandidates(userID primitive.ObjectID) ([]domain.SearchUser, error) {
+	return u.cachedCandidates("friends:"+userID.Hex(), domain.MentionFriendsCacheTTL, func() ([]primitive.ObjectID, error) {
+		friendships, err := u.friendshipRepo.FindFriends(userID, domain.MaxMentionCandidates, 0)
+		if err != nil {
+			return nil, err
+		}
+		friendIDs := make([]primitive.ObjectID, 0, len(friendships))
+		for _, friendship := range friendships {
+			if friendship.UserID1 == userID {
+				friendIDs = append(friendIDs, friendship.UserID2)
+			} else {
+				friendIDs = append(friendIDs, friendship.UserID1)
+			}
+		}
+		return friendIDs, nil
+	})
+}
+
+// usernameCandidates returns the most followed users whose username starts with
+// the prefix. The lookup is shared by everyone typing the same prefix.
+func (u *mentionUseCase) usernameCandidates(prefix string) ([]domain.SearchUser, error) {
+	logger := utils.NewLogger("MentionUseCase.usernameCandidates")
+
+	scope := "prefix:" + prefix
+	candidates, err := u.mentionRepo.GetCandidates(scope)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+	if candidates != nil {
+		return candidates, nil
+	}
+
+	// One extra in case the user matches their own prefix
+	users, err := u.userRepo.FindByUsernamePrefix(prefix, domain.MaxMentionSuggestions+1)
+	if err != nil {
+		return nil, err
+	}
+	candidates = make([]domain.SearchUser, 0, len(users))
+	for i := range users {
+		candidates = append(candidates, toSearchUser(&users[i]))
+	}
+
+	if err := u.mentionRepo.SetCandidates(scope, candidates, domain.MentionContextCacheTTL); err != nil {
+		logger.LogOutput(nil, err)
+	}
+	return candidates, nil
+}
+
+// cachedCandidates returns the cached candidates of the scope, or loads the users
+// load returns and caches them in that order. Cache failures only cost speed.
+func (u *mentionUseCase) cachedCandidates(scope string, ttl time.Duration, load func() ([]primitive.ObjectID, error)) ([]domain.SearchUser, error) {
+	logger := utils.NewLogger("MentionUseCase.cachedCandidates")
+
+	candidates, err := u.mentionRepo.GetCandidates(scope)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+	if candidates != nil {
+		return candidates, nil
+	}
+
+	ids, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > domain.MaxMentionCandidates {
+		ids = ids[:domain.MaxMentionCandidates]
+	}
+
+	users, err := u.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*domain.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	candidates = make([]domain.SearchUser, 0, len(users))
+	added := make(map[primitive.ObjectID]bool, len(users))
+	for _, id := range ids {
+		user, ok := byID[id]
+		if !ok || added[id] {
+			continue
+		}
+		added[id] = true
+		candidates = append(candidates, toSearchUser(user))
+	}
+
+	if err := u.mentionRepo.SetCandidates(scope, candidates, ttl); err != nil {
+		logger.LogOutput(nil, err)
+	}
+	return candidates, nil
+}
+
+// mentionMatches reports whether the username, display name, first or last name
+// starts with the lowercase prefix. An empty prefix matches everyone.
+func mentionMatches(user domain.SearchUser, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	for _, name := range []string{user.Username, user.DisplayName, user.FirstName, user.LastName} {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type moderationUseCase struct {
+	jobRepo           domain.ModerationJobRepository
+	auditLogRepo      domain.AuditLogRepository
+	postUseCase       domain.PostUseCase
+	commentUseCase    domain.CommentUseCase
+	suspensionUseCase domain.SuspensionUseCase
+}
+
+func NewModerationUseCase(
+	jobRepo domain.ModerationJobRepository,
+	auditLogRepo domain.AuditLogRepository,
+	postUseCase domain.PostUseCase,
+	commentUseCase domain.CommentUseCase,
+	suspensionUseCase domain.SuspensionUseCase,
+) domain.ModerationUseCase {
+	return &moderationUseCase{
+		jobRepo:           jobRepo,
+		auditLogRepo:      auditLogRepo,
+		postUseCase:       postUseCase,
+		commentUseCase:    commentUseCase,
+		suspensionUseCase: suspensionUseCase,
+	}
+}
+
+// CreateJob validates the request and queues the job for the moderation worker
+func (u *moderationUseCase) CreateJob(adminID primitive.ObjectID, req domain.ModerationJobRequest) (*domain.ModerationJob, error) {
+	logger := utils.NewLogger("ModerationUseCase.CreateJob")
+	logger.LogInput(map[string]interface{}{
+		"adminID": adminID.Hex(),
+		"request": req,
+	})
+
+	job := &domain.ModerationJob{
+		CreatedBy: adminID,
+		Action:    req.Action,
+		Reason:    strings.TrimSpace(req.Reason),
+		Status:    domain.ModerationJobPending,
+		Items:     make([]domain.ModerationJobItem, 0, len(req.TargetIDs)),
+	}
+
+	switch req.Action {
+	case domain.ModerationActionDeleteComment, domain.ModerationActionDeletePost, domain.ModerationActionLiftSuspension:
+	case domain.ModerationActionBanUser, domain.ModerationActionMuteUser:
+		// Every target gets the same end, however long the job takes to run
+		now := time.Now()
+		until := now.Add(time.Duration(req.DurationHours) * time.Hour)
+		if req.Until != nil {
+			until = *req.Until
+		}
+		if err := validateSuspension(moderationSuspensionType(req.Action), now, until); err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		job.Until = &until
+	default:
+		err := fmt.Errorf("%w: unknown moderation action %q", domain.ErrInvalidInput, req.Action)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.TargetIDs))
+	for _, targetID := range req.TargetIDs {
+		targetID = strings.TrimSpace(targetID)
+		if seen[targetID] {
+			continue
+		}
+		if !primitive.IsValidObjectID(targetID) {
+			err := fmt.Errorf("%w: %q is not a valid ID", domain.ErrInvalidInput, targetID)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		seen[targetID] = true
+		job.Items = append(job.Items, domain.ModerationJobItem{
+			TargetID: targetID,
+			Status:   domain.ModerationItemPending,
+		})
+	}
+	if len(job.Items) == 0 {
+		err := fmt.Errorf("%w: targetIds is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if len(job.Items) > domain.MaxModerationJobItems {
+		err := fmt.Errorf("%w: a job takes at most %d targets", domain.ErrInvalidInput, domain.MaxModerationJobItems)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	job.Total = len(job.Items)
+
+	if err := u.jobRepo.Create(job); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	err := u.auditLogRepo.Create(&domain.AuditLog{
+		ActorID:    adminID,
+		Action:     domain.AuditActionBulkModeration,
+		TargetType: "moderation_job",
+		TargetID:   job.ID.Hex(),
+		Metadata: map[string]interface{}{
+			"action": job.Action,
+			"total":  job.Total,
+			"reason": job.Reason,
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	logger.LogOutput(job.ID, nil)
+	return job, nil
+}
+
+func (u *moderationUseCase) GetJob(id primitive.ObjectID) (*domain.ModerationJob, error) {
+	logger := utils.NewLogger("ModerationUseCase.GetJob")
+	logger.LogInput(id)
+
+	job, err := u.jobRepo.FindByID(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(job.Status, nil)
+	return job, nil
+}
+
+func (u *moderationUseCase) ListJobs(limit, offset int) ([]domain.ModerationJob, error) {
+	logger := utils.NewLogger("ModerationUseCase.ListJobs")
+	logger.LogInput(limit, offset)
+
+	jobs, err := u.jobRepo.FindAll(limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(jobs)}, nil)
+	return jobs, nil
+}
+
+// RunNextJob applies the action of the next job to its pending items, recording
+// each result as it goes so a job taken over after a crash resumes where it stopped
+func (u *moderationUseCase) RunNextJob() (bool, error) {
+	logger := utils.NewLogger("ModerationUseCase.RunNextJob")
+
+	job, err := u.jobRepo.ClaimNext(time.Now().Add(-domain.ModerationJobStaleAfter))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+	logger.LogInput(job.ID, job.Action)
+
+	for i, item := range job.Items {
+		if item.Status != domain.ModerationItemPending {
+			continue
+		}
+
+		now := time.Now()
+		result := domain.ModerationJobItem{
+			TargetID:    item.TargetID,
+			Status:      domain.ModerationItemSucceeded,
+			ProcessedAt: &now,
+		}
+		if err := u.apply(job, item.TargetID); err != nil {
+			result.Status = domain.ModerationItemFailed
+			result.Error = moderationItemError(err)
+		}
+
+		if err := u.jobRepo.SetItemResult(job.ID, i, result); err != nil {
+			logger.LogOutput(nil, err)
+			return true, err
+		}
+	}
+
+	if err := u.jobRepo.Complete(job.ID); err != nil {
+		logger.LogOutput(nil, err)
+		return true, err
+	}
+
+	logger.LogOutput(job.ID, nil)
+	return true, nil
+}
+
+// apply runs the job's action on one target as the admin who created the job
+func (u *moderationUseCase) apply(job *domain.ModerationJob, targetID string) error {
+	id, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	switch job.Action {
+	case domain.ModerationActionDeleteComment:
+		return u.commentUseCase.DeleteComment(id)
+	case domain.ModerationActionDeletePost:
+		return u.postUseCase.DeletePost(job.CreatedBy, id)
+	case domain.ModerationActionBanUser, domain.ModerationActionMuteUser:
+		_, err := u.suspensionUseCase.Suspend(job.CreatedBy, id, domain.SuspendRequest{
+			Type:   moderationSuspensionType(job.Action),
+			Reason: job.Reason,
+			Until:  job.Until,
+		})
+		return err
+	case domain.ModerationActionLiftSuspension:
+		return u.suspensionUseCase.Lift(job.CreatedBy, id)
+	}
+	return fmt.Errorf("%w: unknown moderation action %q", domain.ErrInvalidInput, job.Action)
+}
+
+func moderationSuspensionType(action string) string {
+	if action == domain.ModerationActionMuteUser {
+		return domain.SuspensionMute
+	}
+	return domain.SuspensionBan
+}
+
+// moderationItemError describes why an item failed
+func moderationItemError(err error) string {
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrNotFound.Error()
+	}
+	return err.Error()
+}
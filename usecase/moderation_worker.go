@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// ModerationWorker runs the queued bulk moderation jobs in the background
+type ModerationWorker struct {
+	moderationUseCase domain.ModerationUseCase
+	interval          time.Duration
+}
+
+func NewModerationWorker(moderationUseCase domain.ModerationUseCase, interval time.Duration) *ModerationWorker {
+	return &ModerationWorker{
+		moderationUseCase: moderationUseCase,
+		interval:          interval,
+	}
+}
+
+// Run checks for queued jobs every interval until ctx is done
+func (w *ModerationWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runJobs(ctx)
+		}
+	}
+}
+
+// runJobs runs queued jobs one after another until none is left
+func (w *ModerationWorker) runJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := w.moderationUseCase.RunNextJob()
+		if err != nil {
+			utils.NewLogger("ModerationWorker.runJobs").LogOutput(nil, err)
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type nearbyUseCase struct {
+	postRepo      domain.PostRepository
+	userRepo      domain.UserRepository
+	followUseCase domain.FollowUseCase
+}
+
+func NewNearbyUseCase(postRepo domain.PostRepository, userRepo domain.UserRepository, followUseCase domain.FollowUseCase) domain.NearbyUseCase {
+	return &nearbyUseCase{
+		postRepo:      postRepo,
+		userRepo:      userRepo,
+		followUseCase: followUseCase,
+	}
+}
+
+func (n *nearbyUseCase) GetNearbyPosts(viewerID primitive.ObjectID, lat, lng, radius float64, limit, offset int) ([]domain.PostWithDetails, error) {
+	logger := utils.NewLogger("NearbyUseCase.GetNearbyPosts")
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID,
+		"lat":      lat,
+		"lng":      lng,
+		"radius":   radius,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	radius, err := nearbyRadius(lat, lng, radius)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	found, err := n.postRepo.FindNearby(viewerID, []float64{lng, lat}, radius, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Posts of users blocked either way are left out of the page
+	posts := make([]domain.Post, 0, len(found))
+	distances := make([]float64, 0, len(found))
+	for i := range found {
+		blocked, err := isBlockedEitherWay(n.followUseCase, viewerID, found[i].UserID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if blocked {
+			continue
+		}
+		posts = append(posts, found[i].Post)
+		distances = append(distances, found[i].Distance)
+	}
+
+	result, err := withAuthors(n.userRepo, posts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	for i := range result {
+		result[i].Distance = &distances[i]
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(result)}, nil)
+	return result, nil
+}
+
+func (n *nearbyUseCase) GetNearbyUsers(viewerID primitive.ObjectID, lat, lng, radius float64, limit, offset int) ([]domain.NearbyUser, error) {
+	logger := utils.NewLogger("NearbyUseCase.GetNearbyUsers")
+	logger.LogInput(map[string]interface{}{
+		"viewerID": viewerID,
+		"lat":      lat,
+		"lng":      lng,
+		"radius":   radius,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	radius, err := nearbyRadius(lat, lng, radius)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	found, err := n.userRepo.FindNearby([]float64{lng, lat}, radius, []primitive.ObjectID{viewerID}, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	users := make([]domain.NearbyUser, 0, len(found))
+	for i := range found {
+		blocked, err := isBlockedEitherWay(n.followUseCase, viewerID, found[i].ID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if blocked {
+			continue
+		}
+		users = append(users, domain.NearbyUser{
+			User:       toSearchUser(&found[i].User),
+			DistanceKm: math.Max(1, math.Ceil(found[i].Distance/1000)),
+		})
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(users)}, nil)
+	return users, nil
+}
+
+// nearbyRadius validates the searched point and returns the radius to search,
+// the default one when it's 0
+func nearbyRadius(lat, lng, radius float64) (float64, error) {
+	if err := validateCoordinates(lat, lng); err != nil {
+		return 0, err
+	}
+	if radius == 0 {
+		return domain.DefaultNearbyRadiusMeters, nil
+	}
+	if radius < 0 || radius > domain.MaxNearbyRadiusMeters {
+		return 0, fmt.Errorf("%w: radius must be between 1 and %d meters", domain.ErrInvalidInput, domain.MaxNearbyRadiusMeters)
+	}
+	return radius, nil
+}
+
+func validateCoordinates(lat, lng float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return fmt.Errorf("%w: lat must be between -90 and 90 and lng between -180 and 180", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+// validatePostLocation checks the coordinates of a GeoJSON point location, which
+// the geo index would reject. Locations of other types only carry a place name.
+func validatePostLocation(location *domain.Location) error {
+	if location == nil || location.Type != domain.GeoPointType {
+		return nil
+	}
+	if len(location.Coordinates) != 2 {
+		return fmt.Errorf("%w: a point location needs [lng, lat] coordinates", domain.ErrInvalidInput)
+	}
+	return validateCoordinates(location.Coordinates[1], location.Coordinates[0])
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// markStaleRefs flags notifications whose post, comment or subpost has been deleted, and
+// unflags them again when a deleted post is restored. Notifications about comments on
+// posts reference the comment, so a comment only counts as existing while its post does.
+// Lookup errors leave the stored flags untouched.
+func (n *notificationUseCase) markStaleRefs(notifications []domain.Notification) {
+	logger := utils.NewLogger("NotificationUseCase.markStaleRefs")
+
+	var contentIDs, subPostIDs []primitive.ObjectID
+	for _, notification := range notifications {
+		switch notification.RefType {
+		case "post", "comment":
+			contentIDs = append(contentIDs, notification.RefID)
+		case "subpost":
+			subPostIDs = append(subPostIDs, notification.RefID)
+		}
+	}
+	if len(contentIDs) == 0 && len(subPostIDs) == 0 {
+		return
+	}
+
+	exists := make(map[primitive.ObjectID]bool)
+	if len(contentIDs) > 0 {
+		comments, err := n.commentRepo.FindByIDs(contentIDs)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+		postIDs := append([]primitive.ObjectID{}, contentIDs...)
+		for _, comment := range comments {
+			postIDs = append(postIDs, comment.PostID)
+		}
+		posts, err := n.postRepo.FindByIDs(postIDs)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+		for _, post := range posts {
+			exists[post.ID] = true
+		}
+		for _, comment := range comments {
+			if exists[comment.PostID] {
+				exists[comment.ID] = true
+			}
+		}
+	}
+	for _, id := range subPostIDs {
+		if _, err := n.subPostRepo.FindByID(id); err != nil {
+			if !domain.IsNotFoundError(err) {
+				logger.LogOutput(nil, err)
+				return
+			}
+			continue
+		}
+		exists[id] = true
+	}
+
+	var stale, restored []primitive.ObjectID
+	for i := range notifications {
+		switch notifications[i].RefType {
+		case "post", "comment", "subpost":
+		default:
+			continue
+		}
+		isStale := !exists[notifications[i].RefID]
+		if isStale == notifications[i].Stale {
+			continue
+		}
+		if isStale {
+			stale = append(stale, notifications[i].ID)
+		} else {
+			restored = append(restored, notifications[i].ID)
+		}
+		notifications[i].Stale = isStale
+	}
+
+	if len(stale) > 0 {
+		if err := n.notificationRepo.SetStale(stale, true); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+	if len(restored) > 0 {
+		if err := n.notificationRepo.SetStale(restored, false); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"time"
+
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,12 +11,24 @@ import (
 type notificationUseCase struct {
 	notificationRepo domain.NotificationRepository
 	userRepo        domain.UserRepository
+	postRepo        domain.PostRepository
+	commentRepo     domain.CommentRepository
+	subPostRepo     domain.SubPostRepository
+	realtime        domain.RealtimePublisher
+	deviceUseCase   domain.DeviceUseCase
+	mentionRepo     domain.MentionRepository
 }
 
-func NewNotificationUseCase(notificationRepo domain.NotificationRepository, userRepo domain.UserRepository) domain.NotificationUseCase {
+func NewNotificationUseCase(notificationRepo domain.NotificationRepository, userRepo domain.UserRepository, postRepo domain.PostRepository, commentRepo domain.CommentRepository, subPostRepo domain.SubPostRepository, realtime domain.RealtimePublisher, deviceUseCase domain.DeviceUseCase, mentionRepo domain.MentionRepository) domain.NotificationUseCase {
 	return &notificationUseCase{
 		notificationRepo: notificationRepo,
 		userRepo:        userRepo,
+		postRepo:        postRepo,
+		commentRepo:     commentRepo,
+		subPostRepo:     subPostRepo,
+		realtime:        realtime,
+		deviceUseCase:   deviceUseCase,
+		mentionRepo:     mentionRepo,
 	}
 }
 
@@ -46,6 +60,62 @@ func (n *notificationUseCase) CreateNotification(recipientID, senderID, refID pr
 		return nil, err
 	}
 
+	n.touchInteraction(recipientID, senderID)
+
+	// Delivery is best effort, the notification stays available through polling
+	sender, err := n.userRepo.FindByID(senderID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+	n.publishNotification(notification, sender)
+	n.sendPush(notification, sender)
+
+	logger.LogOutput(notification, nil)
+	return notification, nil
+}
+
+// CreateAggregatedNotification creates the notification like CreateNotification, unless an unread one
+// about the same ref was created within the window. The event is then folded into that one and only
+// pushed to connected clients, so a hot post doesn't buzz its owner's phone for every reaction.
+func (n *notificationUseCase) CreateAggregatedNotification(recipientID, senderID, refID primitive.ObjectID, nType domain.NotificationType, refType, message string, window time.Duration) (*domain.Notification, error) {
+	logger := utils.NewLogger("NotificationUseCase.CreateAggregatedNotification")
+	logger.LogInput(map[string]interface{}{
+		"recipientID": recipientID.Hex(),
+		"senderID":    senderID.Hex(),
+		"refID":       refID.Hex(),
+		"type":        nType,
+		"refType":     refType,
+		"window":      window.String(),
+	})
+
+	existing, err := n.notificationRepo.FindAggregate(recipientID, refID, nType, time.Now().Add(-window))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if existing == nil {
+		notification, err := n.CreateNotification(recipientID, senderID, refID, nType, refType, message)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		logger.LogOutput(notification, nil)
+		return notification, nil
+	}
+
+	notification, err := n.notificationRepo.AddToAggregate(existing.ID, senderID, message)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	n.touchInteraction(recipientID, senderID)
+
+	sender, err := n.userRepo.FindByID(senderID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+	n.publishNotification(notification, sender)
+
 	logger.LogOutput(notification, nil)
 	return notification, nil
 }
@@ -60,6 +130,10 @@ func (n *notificationUseCase) GetNotification(notificationID primitive.ObjectID)
 		return nil, err
 	}
 
+	single := []domain.Notification{*notification}
+	n.markStaleRefs(single)
+	notification = &single[0]
+
 	// Get sender information
 	sender, err := n.userRepo.FindByID(notification.SenderID.Hex())
 	if err != nil {
@@ -96,6 +170,9 @@ func (n *notificationUseCase) ListNotifications(recipientID primitive.ObjectID,
 		return nil, err
 	}
 
+	// Annotate notifications about deleted content instead of letting clients open broken screens
+	n.markStaleRefs(notifications)
+
 	// Create response with user information
 	response := make([]domain.NotificationResponse, len(notifications))
 	for i, notification := range notifications {
@@ -120,9 +197,10 @@ func (n *notificationUseCase) ListNotifications(recipientID primitive.ObjectID,
 	return response, nil
 }
 
-func (n *notificationUseCase) MarkAsRead(notificationID primitive.ObjectID) error {
+func (n *notificationUseCase) MarkAsRead(recipientID, notificationID primitive.ObjectID) error {
 	logger := utils.NewLogger("NotificationUseCase.MarkAsRead")
 	input := map[string]interface{}{
+		"recipientID":    recipientID.Hex(),
 		"notificationID": notificationID.Hex(),
 	}
 	logger.LogInput(input)
@@ -133,6 +211,10 @@ func (n *notificationUseCase) MarkAsRead(notificationID primitive.ObjectID) erro
 		return err
 	}
 
+	n.publishReadState(recipientID, &domain.NotificationReadEvent{
+		NotificationIDs: []string{notificationID.Hex()},
+	})
+
 	logger.LogOutput(map[string]interface{}{"success": true}, nil)
 	return nil
 }
@@ -150,18 +232,32 @@ func (n *notificationUseCase) MarkAllAsRead(recipientID primitive.ObjectID) erro
 		return err
 	}
 
+	n.publishReadState(recipientID, &domain.NotificationReadEvent{All: true})
+
 	logger.LogOutput(map[string]interface{}{"success": true}, nil)
 	return nil
 }
 
-func (n *notificationUseCase) DeleteNotification(notificationID primitive.ObjectID) error {
+func (n *notificationUseCase) DeleteNotification(userID, notificationID primitive.ObjectID) error {
 	logger := utils.NewLogger("NotificationUseCase.DeleteNotification")
 	input := map[string]interface{}{
+		"userID":         userID.Hex(),
 		"notificationID": notificationID.Hex(),
 	}
 	logger.LogInput(input)
 
-	err := n.notificationRepo.Delete(notificationID)
+	notification, err := n.notificationRepo.FindByID(notificationID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := authorizeOwnerOrAdmin(n.userRepo, userID.Hex(), notification.RecipientID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	err = n.notificationRepo.Delete(notificationID)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
@@ -187,3 +283,112 @@ func (n *notificationUseCase) GetUnreadCount(recipientID primitive.ObjectID) (in
 	logger.LogOutput(map[string]interface{}{"count": count}, nil)
 	return count, nil
 }
+
+func (n *notificationUseCase) CountNotifications(recipientID primitive.ObjectID) (int64, error) {
+	logger := utils.NewLogger("NotificationUseCase.CountNotifications")
+	logger.LogInput(recipientID)
+
+	count, err := n.notificationRepo.CountByRecipient(recipientID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+// publishReadState tells the recipient's other devices to update their badge counts.
+// The read state is already saved, so failures are only logged.
+func (n *notificationUseCase) publishReadState(recipientID primitive.ObjectID, event *domain.NotificationReadEvent) {
+	logger := utils.NewLogger("NotificationUseCase.publishReadState")
+
+	if n.realtime == nil {
+		return
+	}
+
+	count, err := n.notificationRepo.CountUnread(recipientID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+	event.UnreadCount = count
+
+	err = n.realtime.SendToUser(recipientID.Hex(), domain.RealtimeEventNotificationRead, event)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	logger.LogOutput(event, nil)
+}
+
+// publishNotification pushes the new notification to the recipient's connected clients.
+// sender may be nil when it couldn't be loaded.
+func (n *notificationUseCase) publishNotification(notification *domain.Notification, sender *domain.User) {
+	logger := utils.NewLogger("NotificationUseCase.publishNotification")
+
+	if n.realtime == nil {
+		return
+	}
+
+	response := &domain.NotificationResponse{
+		Notification: *notification,
+	}
+	if sender != nil {
+		response.Sender.UserID = sender.ID.Hex()
+		response.Sender.Username = sender.Username
+		response.Sender.DisplayName = sender.DisplayName
+		response.Sender.PhotoProfile = sender.PhotoProfile
+		response.Sender.FirstName = sender.FirstName
+		response.Sender.LastName = sender.LastName
+	}
+
+	err := n.realtime.SendToUser(notification.RecipientID.Hex(), domain.RealtimeEventNotification, response)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
+// sendPush delivers the notification to the recipient's mobile devices.
+// The notification is already saved, so failures are only logged.
+func (n *notificationUseCase) sendPush(notification *domain.Notification, sender *domain.User) {
+	logger := utils.NewLogger("NotificationUseCase.sendPush")
+
+	if n.deviceUseCase == nil {
+		return
+	}
+
+	title := "Vongga"
+	if sender != nil {
+		title = sender.DisplayName
+		if title == "" {
+			title = sender.Username
+		}
+	}
+
+	err := n.deviceUseCase.PushToUser(notification.RecipientID, &domain.PushMessage{
+		Title: title,
+		Body:  notification.Message,
+		Data: map[string]string{
+			"notificationId": notification.ID.Hex(),
+			"type":           string(notification.Type),
+			"refId":          notification.RefID.Hex(),
+			"refType":        notification.RefType,
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
+// touchInteraction records that the sender and recipient interacted, which ranks
+// them higher in each other's mention suggestions. Failures are only logged.
+func (n *notificationUseCase) touchInteraction(recipientID, senderID primitive.ObjectID) {
+	if senderID.IsZero() || senderID == recipientID {
+		return
+	}
+	if err := n.mentionRepo.TouchInteraction(senderID, recipientID, time.Now()); err != nil {
+		utils.NewLogger("NotificationUseCase.touchInteraction").LogOutput(nil, err)
+	}
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type onboardingUseCase struct {
+	stepRepo domain.OnboardingStepRepository
+	userRepo domain.UserRepository
+	postRepo domain.PostRepository
+}
+
+func NewOnboardingUseCase(stepRepo domain.OnboardingStepRepository, userRepo domain.UserRepository, postRepo domain.PostRepository) domain.OnboardingUseCase {
+	return &onboardingUseCase{
+		stepRepo: stepRepo,
+		userRepo: userRepo,
+		postRepo: postRepo,
+	}
+}
+
+// GetChecklist evaluates the active steps for the user, falling back to the
+// default steps until an admin configures the checklist
+func (u *onboardingUseCase) GetChecklist(userID primitive.ObjectID) (*domain.OnboardingChecklist, error) {
+	logger := utils.NewLogger("OnboardingUseCase.GetChecklist")
+	logger.LogInput(userID)
+
+	configured, err := u.stepRepo.List(false)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	steps := domain.DefaultOnboardingSteps
+	if len(configured) > 0 {
+		steps = make([]domain.OnboardingStep, 0, len(configured))
+		for _, step := range configured {
+			if step.IsActive {
+				steps = append(steps, step)
+			}
+		}
+	}
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	checklist := &domain.OnboardingChecklist{
+		Steps: make([]domain.OnboardingStepProgress, 0, len(steps)),
+		Total: len(steps),
+	}
+	var postCount *int
+	for _, step := range steps {
+		progress := domain.OnboardingStepProgress{
+			Key:         step.Key,
+			Title:       step.Title,
+			Description: step.Description,
+		}
+
+		switch step.Predicate {
+		case domain.OnboardingHasAvatar:
+			progress.Done = user.PhotoProfile != "" || user.Avatar != ""
+		case domain.OnboardingHasBio:
+			progress.Done = strings.TrimSpace(user.Bio) != ""
+		case domain.OnboardingHasCover:
+			progress.Done = user.PhotoCover != ""
+		case domain.OnboardingHasInterests:
+			progress.Done = len(user.Interests) > 0 || len(user.LegacyInterests) > 0
+		case domain.OnboardingEmailVerified:
+			progress.Done = user.EmailVerified
+		case domain.OnboardingFollowingCount:
+			progress.Progress = user.FollowingCount
+		case domain.OnboardingFriendsCount:
+			progress.Progress = user.FriendsCount
+		case domain.OnboardingPostCount:
+			// Posts are counted once however many steps use them
+			if postCount == nil {
+				count, err := u.postRepo.CountByUserID(userID, nil, false, "")
+				if err != nil {
+					logger.LogOutput(nil, err)
+					return nil, err
+				}
+				n := int(count)
+				postCount = &n
+			}
+			progress.Progress = *postCount
+		}
+		if domain.IsOnboardingCountPredicate(step.Predicate) {
+			progress.Target = onboardingTarget(step)
+			progress.Done = progress.Progress >= progress.Target
+		}
+
+		if progress.Done {
+			checklist.Completed++
+		}
+		checklist.Steps = append(checklist.Steps, progress)
+	}
+	checklist.Done = checklist.Completed == checklist.Total
+
+	logger.LogOutput(checklist, nil)
+	return checklist, nil
+}
+
+// onboardingTarget treats a missing target of a count predicate as one
+func onboardingTarget(step domain.OnboardingStep) int {
+	if step.Target < 1 {
+		return 1
+	}
+	return step.Target
+}
+
+func validateOnboardingStep(step *domain.OnboardingStep) error {
+	if strings.TrimSpace(step.Title) == "" {
+		return fmt.Errorf("%w: title is required", domain.ErrInvalidInput)
+	}
+	if !domain.IsValidOnboardingPredicate(step.Predicate) {
+		return fmt.Errorf("%w: unknown predicate %q", domain.ErrInvalidInput, step.Predicate)
+	}
+	if step.Target < 0 {
+		return fmt.Errorf("%w: target must not be negative", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+func (u *onboardingUseCase) ListSteps() ([]domain.OnboardingStep, error) {
+	logger := utils.NewLogger("OnboardingUseCase.ListSteps")
+
+	steps, err := u.stepRepo.List(false)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(steps)}, nil)
+	return steps, nil
+}
+
+func (u *onboardingUseCase) CreateStep(step *domain.OnboardingStep) error {
+	logger := utils.NewLogger("OnboardingUseCase.CreateStep")
+	logger.LogInput(step)
+
+	step.Key = strings.ToLower(strings.TrimSpace(step.Key))
+	if step.Key == "" {
+		err := fmt.Errorf("%w: key is required", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if err := validateOnboardingStep(step); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	err := u.stepRepo.Create(step)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(step, nil)
+	return nil
+}
+
+func (u *onboardingUseCase) UpdateStep(id primitive.ObjectID, update domain.OnboardingStepUpdate) (*domain.OnboardingStep, error) {
+	logger := utils.NewLogger("OnboardingUseCase.UpdateStep")
+	logger.LogInput(map[string]interface{}{
+		"id":     id,
+		"update": update,
+	})
+
+	step, err := u.stepRepo.FindByID(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if update.Title != nil {
+		step.Title = *update.Title
+	}
+	if update.Description != nil {
+		step.Description = *update.Description
+	}
+	if update.Predicate != nil {
+		step.Predicate = *update.Predicate
+	}
+	if update.Target != nil {
+		step.Target = *update.Target
+	}
+	if update.Order != nil {
+		step.Order = *update.Order
+	}
+	if update.IsActive != nil {
+		step.IsActive = *update.IsActive
+	}
+	if err := validateOnboardingStep(step); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	err = u.stepRepo.Update(step)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(step, nil)
+	return step, nil
+}
+
+func (u *onboardingUseCase) DeleteStep(id primitive.ObjectID) error {
+	logger := utils.NewLogger("OnboardingUseCase.DeleteStep")
+	logger.LogInput(id)
+
+	err := u.stepRepo.Delete(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
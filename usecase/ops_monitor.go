@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// OpsMonitor publishes a snapshot of the instance to the ops feed every interval,
+// plus alerts for server error spikes and a growing moderation queue, so the ops
+// dashboard doesn't poll. Every instance runs one and reports its own counters.
+type OpsMonitor struct {
+	feed              domain.OpsFeed
+	moderationJobRepo domain.ModerationJobRepository
+	interval          time.Duration
+
+	// counter values of the last tick, to report growth
+	lastCounters map[string]int64
+	// server errors of the last intervals, oldest first
+	recentErrors []int64
+	// set while the moderation queue is above the threshold so it alerts once
+	backlogAlerted bool
+}
+
+func NewOpsMonitor(feed domain.OpsFeed, moderationJobRepo domain.ModerationJobRepository, interval time.Duration) *OpsMonitor {
+	return &OpsMonitor{
+		feed:              feed,
+		moderationJobRepo: moderationJobRepo,
+		interval:          interval,
+		lastCounters:      utils.MetricsSnapshot(),
+	}
+}
+
+// Run reports every interval until ctx is done
+func (m *OpsMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.tick()
+		}
+	}
+}
+
+func (m *OpsMonitor) tick() {
+	logger := utils.NewLogger("OpsMonitor.tick")
+
+	counters := utils.MetricsSnapshot()
+	growth := make(map[string]int64)
+	for name, value := range counters {
+		if delta := value - m.lastCounters[name]; delta > 0 {
+			growth[name] = delta
+		}
+	}
+	m.lastCounters = counters
+
+	// A failed count leaves the snapshot at zero rather than holding it back
+	pending, err := m.moderationJobRepo.CountPending()
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	m.publish(domain.OpsEventSnapshot, domain.OpsSnapshot{
+		Connections:       m.feed.ConnectionStats(),
+		Goroutines:        runtime.NumGoroutine(),
+		Counters:          growth,
+		ModerationPending: pending,
+	})
+
+	m.checkErrors(growth[utils.MetricServerErrors])
+	if err == nil {
+		m.checkModerationBacklog(pending)
+	}
+}
+
+// checkErrors alerts when the interval's server errors are well above the recent average
+func (m *OpsMonitor) checkErrors(errors int64) {
+	var average float64
+	if len(m.recentErrors) > 0 {
+		var sum int64
+		for _, n := range m.recentErrors {
+			sum += n
+		}
+		average = float64(sum) / float64(len(m.recentErrors))
+	}
+
+	if errors >= domain.OpsErrorSpikeMin && float64(errors) >= average*domain.OpsErrorSpikeFactor {
+		m.publish(domain.OpsEventErrorSpike, domain.OpsErrorSpike{
+			Errors:          errors,
+			Average:         average,
+			IntervalSeconds: m.interval.Seconds(),
+		})
+	}
+
+	m.recentErrors = append(m.recentErrors, errors)
+	if len(m.recentErrors) > domain.OpsErrorSpikeWindow {
+		m.recentErrors = m.recentErrors[1:]
+	}
+}
+
+// checkModerationBacklog alerts once when the queue reaches the threshold
+func (m *OpsMonitor) checkModerationBacklog(pending int64) {
+	if pending < domain.OpsModerationBacklogThreshold {
+		m.backlogAlerted = false
+		return
+	}
+	if m.backlogAlerted {
+		return
+	}
+	m.backlogAlerted = true
+
+	m.publish(domain.OpsEventModerationBacklog, domain.OpsModerationBacklog{
+		Pending:   pending,
+		Threshold: domain.OpsModerationBacklogThreshold,
+	})
+}
+
+func (m *OpsMonitor) publish(eventType string, data interface{}) {
+	err := m.feed.PublishOps(domain.OpsEvent{
+		Type: eventType,
+		Data: data,
+		At:   time.Now(),
+	})
+	if err != nil {
+		utils.NewLogger("OpsMonitor.publish").LogOutput(nil, err)
+	}
+}
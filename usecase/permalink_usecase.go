@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type permalinkUseCase struct {
+	postRepo       domain.PostRepository
+	commentRepo    domain.CommentRepository
+	userRepo       domain.UserRepository
+	friendshipRepo domain.FriendshipRepository
+	followUseCase  domain.FollowUseCase
+	chatRepo       domain.ChatRepository
+	shortLinks     domain.ShortLinkUseCase
+	jwtKeys        *domain.JWTKeySet
+	hosts          map[string]bool
+	scheme         string
+}
+
+// NewPermalinkUseCase resolves https links on hosts and app links using scheme
+func NewPermalinkUseCase(
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	userRepo domain.UserRepository,
+	friendshipRepo domain.FriendshipRepository,
+	followUseCase domain.FollowUseCase,
+	chatRepo domain.ChatRepository,
+	shortLinks domain.ShortLinkUseCase,
+	jwtKeys *domain.JWTKeySet,
+	hosts []string,
+	scheme string,
+) domain.PermalinkUseCase {
+	hostSet := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		hostSet[strings.ToLower(host)] = true
+	}
+	return &permalinkUseCase{
+		postRepo:       postRepo,
+		commentRepo:    commentRepo,
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+		followUseCase:  followUseCase,
+		chatRepo:       chatRepo,
+		shortLinks:     shortLinks,
+		jwtKeys:        jwtKeys,
+		hosts:          hostSet,
+		scheme:         strings.ToLower(scheme),
+	}
+}
+
+func (u *permalinkUseCase) Resolve(viewerID primitive.ObjectID, rawURL string) (*domain.Permalink, error) {
+	logger := utils.NewLogger("PermalinkUseCase.Resolve")
+	logger.LogInput(viewerID, rawURL)
+
+	segments, err := u.pathSegments(rawURL)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	var link *domain.Permalink
+	switch {
+	case len(segments) == 2 && segments[0] == "s":
+		link, err = u.resolveShortLink(viewerID, segments[1])
+	case len(segments) == 2 && (segments[0] == "p" || segments[0] == "posts"):
+		link, err = u.resolvePost(viewerID, segments[1])
+	case len(segments) == 4 && segments[0] == "posts" && segments[2] == "comments":
+		link, err = u.resolveComment(viewerID, segments[3], segments[1])
+	case len(segments) == 2 && (segments[0] == "c" || segments[0] == "comments"):
+		link, err = u.resolveComment(viewerID, segments[1], "")
+	case len(segments) == 2 && (segments[0] == "u" || segments[0] == "users"):
+		link, err = u.resolveUser(viewerID, segments[1])
+	case len(segments) == 1 && strings.HasPrefix(segments[0], "@"):
+		link, err = u.resolveUser(viewerID, strings.TrimPrefix(segments[0], "@"))
+	case len(segments) == 3 && segments[0] == "chats" && segments[1] == "join",
+		len(segments) == 2 && segments[0] == "invite":
+		link, err = u.resolveInvite(viewerID, segments[len(segments)-1])
+	case len(segments) == 2 && segments[0] == "chats":
+		link, err = u.resolveRoom(viewerID, segments[1])
+	default:
+		err = fmt.Errorf("%w: the link doesn't point at anything", domain.ErrInvalidInput)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(link, nil)
+	return link, nil
+}
+
+// pathSegments returns the path of a link to one of our hosts, or of an app link
+// whose host is the first segment, like vongga://p/<id>
+func (u *permalinkUseCase) pathSegments(rawURL string) ([]string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Scheme == "" {
+		return nil, fmt.Errorf("%w: url is not a valid link", domain.ErrInvalidInput)
+	}
+
+	path := parsed.Path
+	switch scheme := strings.ToLower(parsed.Scheme); {
+	case (scheme == "https" || scheme == "http") && u.hosts[strings.ToLower(parsed.Hostname())]:
+	case u.scheme != "" && scheme == u.scheme:
+		path = parsed.Host + "/" + path
+	default:
+		return nil, fmt.Errorf("%w: url is not one of our links", domain.ErrInvalidInput)
+	}
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments, nil
+}
+
+func (u *permalinkUseCase) findVisiblePost(viewerID primitive.ObjectID, postID primitive.ObjectID) (*domain.Post, error) {
+	post, err := u.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, domain.NewNotFoundError("post", postID.Hex())
+	}
+
+	ok, err := canViewPost(u.friendshipRepo, viewerID, post)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrForbidden
+	}
+	return post, nil
+}
+
+func (u *permalinkUseCase) resolvePost(viewerID primitive.ObjectID, id string) (*domain.Permalink, error) {
+	postID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.NewNotFoundError("post", id)
+	}
+
+	if _, err := u.findVisiblePost(viewerID, postID); err != nil {
+		return nil, err
+	}
+	return &domain.Permalink{Type: domain.PermalinkPost, ID: postID.Hex()}, nil
+}
+
+// resolveComment checks the comment against the post it belongs to, the post in
+// the link only has to match it when it's given
+func (u *permalinkUseCase) resolveComment(viewerID primitive.ObjectID, id, linkPostID string) (*domain.Permalink, error) {
+	commentID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.NewNotFoundError("comment", id)
+	}
+
+	comment, err := u.commentRepo.FindByID(commentID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.NewNotFoundError("comment", id)
+		}
+		return nil, err
+	}
+	if linkPostID != "" && comment.PostID.Hex() != linkPostID {
+		return nil, domain.NewNotFoundError("comment", id)
+	}
+
+	if _, err := u.findVisiblePost(viewerID, comment.PostID); err != nil {
+		return nil, err
+	}
+	return &domain.Permalink{
+		Type:   domain.PermalinkComment,
+		ID:     comment.ID.Hex(),
+		PostID: comment.PostID.Hex(),
+	}, nil
+}
+
+// resolveShortLink opens a short link, which counts as a click, and resolves its target
+func (u *permalinkUseCase) resolveShortLink(viewerID primitive.ObjectID, code string) (*domain.Permalink, error) {
+	shortLink, err := u.shortLinks.Open(code)
+	if err != nil {
+		return nil, err
+	}
+
+	switch shortLink.TargetType {
+	case domain.ShortLinkPost:
+		return u.resolvePost(viewerID, shortLink.TargetID.Hex())
+	case domain.ShortLinkProfile:
+		user, err := u.userRepo.FindByID(shortLink.TargetID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, domain.NewNotFoundError("user", shortLink.TargetID.Hex())
+		}
+		return u.userLink(viewerID, user)
+	}
+	return nil, domain.NewNotFoundError("short link", code)
+}
+
+func (u *permalinkUseCase) resolveUser(viewerID primitive.ObjectID, username string) (*domain.Permalink, error) {
+	user, err := u.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.NewNotFoundError("user", username)
+	}
+	return u.userLink(viewerID, user)
+}
+
+// userLink hides profiles across a block in either direction
+func (u *permalinkUseCase) userLink(viewerID primitive.ObjectID, user *domain.User) (*domain.Permalink, error) {
+	if user.ID != viewerID {
+		blocked, err := isBlockedEitherWay(u.followUseCase, viewerID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, domain.NewNotFoundError("user", user.ID.Hex())
+		}
+	}
+
+	return &domain.Permalink{
+		Type:     domain.PermalinkUser,
+		ID:       user.ID.Hex(),
+		Username: user.Username,
+	}, nil
+}
+
+// resolveRoom only resolves rooms for their members
+func (u *permalinkUseCase) resolveRoom(viewerID primitive.ObjectID, roomID string) (*domain.Permalink, error) {
+	if !primitive.IsValidObjectID(roomID) {
+		return nil, domain.NewNotFoundError("chat room", roomID)
+	}
+
+	room, err := u.chatRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, domain.NewNotFoundError("chat room", roomID)
+	}
+	if !isRoomMember(room, viewerID.Hex()) {
+		return nil, domain.ErrForbidden
+	}
+
+	return &domain.Permalink{Type: domain.PermalinkRoom, ID: room.ID.Hex(), IsMember: true}, nil
+}
+
+// resolveInvite checks an invite link without joining, that's left to the app
+// so the invite isn't used up by previews
+func (u *permalinkUseCase) resolveInvite(viewerID primitive.ObjectID, token string) (*domain.Permalink, error) {
+	claims := &domain.ChatInviteClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, u.jwtKeys.Keyfunc)
+	if err != nil || !parsed.Valid || claims.Type != domain.TokenTypeChatInvite {
+		return nil, domain.ErrInvalidChatInvite
+	}
+
+	room, err := u.chatRepo.GetRoom(claims.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil || room.Type != "group" {
+		return nil, domain.ErrInvalidChatInvite
+	}
+
+	return &domain.Permalink{
+		Type:        domain.PermalinkRoom,
+		ID:          room.ID.Hex(),
+		InviteToken: token,
+		IsMember:    isRoomMember(room, viewerID.Hex()),
+	}, nil
+}
+
+func isRoomMember(room *domain.ChatRoom, userID string) bool {
+	for _, memberID := range room.Members {
+		if memberID == userID {
+			return true
+		}
+	}
+	return false
+}
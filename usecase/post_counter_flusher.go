@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// PostCounterFlusher periodically writes the reaction, comment and view counts
+// buffered in Redis to the post documents, so popular posts aren't rewritten on every change
+type PostCounterFlusher struct {
+	postRepo  domain.PostRepository
+	interval  time.Duration
+	batchSize int
+}
+
+func NewPostCounterFlusher(postRepo domain.PostRepository, interval time.Duration, batchSize int) *PostCounterFlusher {
+	return &PostCounterFlusher{
+		postRepo:  postRepo,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run flushes buffered counters every interval, and once more when ctx is done
+func (f *PostCounterFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Hand the buffered counters over before the process exits
+			f.flush()
+			return
+		case <-ticker.C:
+			f.flush()
+		}
+	}
+}
+
+// flush drains the buffered counters batch by batch, stopping at the first failure
+func (f *PostCounterFlusher) flush() {
+	logger := utils.NewLogger("PostCounterFlusher.flush")
+
+	total := 0
+	for {
+		flushed, err := f.postRepo.FlushCounters(f.batchSize)
+		total += flushed
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+		if flushed < f.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.LogOutput(map[string]interface{}{"posts": total}, nil)
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// withAuthors pairs each post with its author, loading all authors in one query
+func withAuthors(userRepo domain.UserRepository, posts []domain.Post) ([]domain.PostWithDetails, error) {
+	authorIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.UserID)
+	}
+	authors, err := userRepo.FindByIDs(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByID := make(map[primitive.ObjectID]*domain.PostUser, len(authors))
+	for _, user := range authors {
+		usersByID[user.ID] = &domain.PostUser{
+			ID:           user.ID,
+			Username:     user.Username,
+			DisplayName:  user.DisplayName,
+			PhotoProfile: user.PhotoProfile,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+		}
+	}
+
+	result := make([]domain.PostWithDetails, 0, len(posts))
+	for i := range posts {
+		result = append(result, domain.PostWithDetails{
+			Post: &posts[i],
+			User: usersByID[posts[i].UserID],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type postDraftUseCase struct {
+	draftRepo   domain.PostDraftRepository
+	postUseCase domain.PostUseCase
+}
+
+func NewPostDraftUseCase(draftRepo domain.PostDraftRepository, postUseCase domain.PostUseCase) domain.PostDraftUseCase {
+	return &postDraftUseCase{
+		draftRepo:   draftRepo,
+		postUseCase: postUseCase,
+	}
+}
+
+func (u *postDraftUseCase) CreateDraft(userID primitive.ObjectID, req domain.PostDraftRequest) (*domain.PostDraft, error) {
+	logger := utils.NewLogger("PostDraftUseCase.CreateDraft")
+	logger.LogInput(userID, req)
+
+	if err := validateDraftVisibility(req.Visibility); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	count, err := u.draftRepo.CountByUserID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if count >= domain.MaxPostDrafts {
+		err = fmt.Errorf("%w: you can keep at most %d drafts", domain.ErrInvalidInput, domain.MaxPostDrafts)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	draft := &domain.PostDraft{UserID: userID}
+	applyDraftRequest(draft, req)
+	if err := u.draftRepo.Create(draft); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return draft, nil
+}
+
+func (u *postDraftUseCase) ListDrafts(userID primitive.ObjectID, limit, offset int) ([]domain.PostDraft, int64, error) {
+	logger := utils.NewLogger("PostDraftUseCase.ListDrafts")
+	logger.LogInput(userID, limit, offset)
+
+	drafts, err := u.draftRepo.FindByUserID(userID, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, 0, err
+	}
+
+	total, err := u.draftRepo.CountByUserID(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, 0, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(drafts), "total": total}, nil)
+	return drafts, total, nil
+}
+
+func (u *postDraftUseCase) GetDraft(userID, draftID primitive.ObjectID) (*domain.PostDraft, error) {
+	logger := utils.NewLogger("PostDraftUseCase.GetDraft")
+	logger.LogInput(userID, draftID)
+
+	draft, err := u.findOwnDraft(userID, draftID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return draft, nil
+}
+
+func (u *postDraftUseCase) UpdateDraft(userID, draftID primitive.ObjectID, req domain.PostDraftRequest) (*domain.PostDraft, error) {
+	logger := utils.NewLogger("PostDraftUseCase.UpdateDraft")
+	logger.LogInput(userID, draftID, req)
+
+	if err := validateDraftVisibility(req.Visibility); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	draft, err := u.findOwnDraft(userID, draftID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	applyDraftRequest(draft, req)
+	if err := u.draftRepo.Update(draft); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(draft.ID, nil)
+	return draft, nil
+}
+
+// PublishDraft runs the validation drafts skip, then posts the draft. A draft left
+// behind by a failed delete is only logged, the post is already out.
+func (u *postDraftUseCase) PublishDraft(userID, draftID primitive.ObjectID) (*domain.Post, error) {
+	logger := utils.NewLogger("PostDraftUseCase.PublishDraft")
+	logger.LogInput(userID, draftID)
+
+	draft, err := u.findOwnDraft(userID, draftID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(draft.Content) == "" && len(draft.Media) == 0 && len(draft.SubPosts) == 0 {
+		err = fmt.Errorf("%w: add content or media before publishing", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	visibility := draft.Visibility
+	if visibility == "" {
+		visibility = domain.VisibilityPublic
+	}
+
+	post, err := u.postUseCase.CreatePost(userID, draft.Content, draft.Media, draft.Tags, draft.Location, visibility, draft.SubPosts)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := u.draftRepo.Delete(draft.ID); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	logger.LogOutput(post.ID, nil)
+	return post, nil
+}
+
+func (u *postDraftUseCase) DeleteDraft(userID, draftID primitive.ObjectID) error {
+	logger := utils.NewLogger("PostDraftUseCase.DeleteDraft")
+	logger.LogInput(userID, draftID)
+
+	draft, err := u.findOwnDraft(userID, draftID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := u.draftRepo.Delete(draft.ID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// findOwnDraft loads a draft of the user. Other users' drafts are reported as not found.
+func (u *postDraftUseCase) findOwnDraft(userID, draftID primitive.ObjectID) (*domain.PostDraft, error) {
+	draft, err := u.draftRepo.FindByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, domain.NewNotFoundError("post draft", draftID.Hex())
+	}
+	return draft, nil
+}
+
+func applyDraftRequest(draft *domain.PostDraft, req domain.PostDraftRequest) {
+	draft.Content = req.Content
+	draft.Media = req.Media
+	draft.Tags = req.Tags
+	draft.Location = req.Location
+	draft.Visibility = req.Visibility
+	draft.SubPosts = req.SubPosts
+}
+
+// validateDraftVisibility rejects unknown visibilities, an empty one is left for later
+func validateDraftVisibility(visibility string) error {
+	switch visibility {
+	case "", domain.VisibilityPublic, domain.VisibilityFriends, domain.VisibilityPrivate:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown visibility %q", domain.ErrInvalidInput, visibility)
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
@@ -8,24 +9,42 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Editors refresh the lock while the editor is open, an abandoned lock frees itself after this TTL
+const postEditLockTTL = 2 * time.Minute
+
 type postUseCase struct {
 	postRepo            domain.PostRepository
 	subPostRepo         domain.SubPostRepository
+	hashtagRepo         domain.HashtagRepository
+	storedFileRepo      domain.StoredFileRepository
 	userRepo            domain.UserRepository
+	friendshipRepo      domain.FriendshipRepository
+	followUseCase       domain.FollowUseCase
 	notificationUseCase domain.NotificationUseCase
+	events              domain.DomainEventPublisher
 }
 
 func NewPostUseCase(
 	postRepo domain.PostRepository,
 	subPostRepo domain.SubPostRepository,
+	hashtagRepo domain.HashtagRepository,
+	storedFileRepo domain.StoredFileRepository,
 	userRepo domain.UserRepository,
+	friendshipRepo domain.FriendshipRepository,
+	followUseCase domain.FollowUseCase,
 	notificationUseCase domain.NotificationUseCase,
+	events domain.DomainEventPublisher,
 ) domain.PostUseCase {
 	return &postUseCase{
 		postRepo:            postRepo,
 		subPostRepo:         subPostRepo,
+		hashtagRepo:         hashtagRepo,
+		storedFileRepo:      storedFileRepo,
 		userRepo:            userRepo,
+		friendshipRepo:      friendshipRepo,
+		followUseCase:       followUseCase,
 		notificationUseCase: notificationUseCase,
+		events:              events,
 	}
 }
 
@@ -42,6 +61,17 @@ func (p *postUseCase) CreatePost(userID primitive.ObjectID, content string, medi
 	}
 	logger.LogInput(input)
 
+	if err := checkCanPublish(p.userRepo, userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := validatePostLocation(location); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	tags = utils.NormalizeHashtags(tags)
 	now := time.Now()
 	post := &domain.Post{
 		BaseModel: domain.BaseModel{
@@ -55,6 +85,7 @@ func (p *postUseCase) CreatePost(userID primitive.ObjectID, content string, medi
 		Content:        content,
 		Media:          media,
 		Tags:           tags,
+		Mentions:       resolveMentions(p.userRepo, content),
 		Location:       location,
 		Visibility:     visibility,
 		ReactionCounts: make(map[string]int),
@@ -88,6 +119,7 @@ func (p *postUseCase) CreatePost(userID primitive.ObjectID, content string, medi
 				ReactionCounts: make(map[string]int),
 				CommentCount:   0,
 				Order:          subPostInput.Order,
+				Visibility:     post.Visibility,
 			}
 			err := p.subPostRepo.Create(subPost)
 			if err != nil {
@@ -97,43 +129,19 @@ func (p *postUseCase) CreatePost(userID primitive.ObjectID, content string, medi
 		}
 	}
 
-	// Check for mentions in content
-	mentions := utils.ExtractMentions(content)
-	for _, username := range mentions {
-		// Find user by username
-		mentionedUser, err := p.userRepo.FindByUsername(username)
-		if err != nil {
-			logger.LogOutput(nil, err)
-			continue // Skip if user not found
-		}
-
-		// Don't notify if user mentions themselves
-		if mentionedUser.ID == userID {
-			continue
-		}
+	p.indexTags(post.Tags, nil)
+	p.publishPostCreated(post)
 
-		// Create mention notification
-		_, err = p.notificationUseCase.CreateNotification(
-			mentionedUser.ID, // recipientID (mentioned user)
-			userID,           // senderID (user who mentioned)
-			post.ID,          // refID (reference to the post)
-			domain.NotificationTypeMention,
-			"post",                    // refType
-			"mentioned you in a post", // message
-		)
-		if err != nil {
-			logger.LogOutput(nil, err)
-			// Don't return error here as the post was created successfully
-		}
-	}
+	notifyMentions(p.notificationUseCase, userID, post.ID, "post", "mentioned you in a post", post.Mentions, nil)
 
 	logger.LogOutput(post, nil)
 	return post, nil
 }
 
-func (p *postUseCase) UpdatePost(postID primitive.ObjectID, content string, media []domain.Media, tags []string, location *domain.Location, visibility string) (*domain.Post, error) {
+func (p *postUseCase) UpdatePost(userID, postID primitive.ObjectID, lockToken string, content string, media []domain.Media, tags []string, location *domain.Location, visibility string) (*domain.Post, error) {
 	logger := utils.NewLogger("PostUseCase.UpdatePost")
 	input := map[string]interface{}{
+		"userID":     userID,
 		"postID":     postID,
 		"content":    content,
 		"media":      media,
@@ -143,12 +151,33 @@ func (p *postUseCase) UpdatePost(postID primitive.ObjectID, content string, medi
 	}
 	logger.LogInput(input)
 
+	if err := validatePostLocation(location); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	post, err := p.postRepo.FindByID(postID)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
 	}
 
+	if err := p.checkCanEdit(userID, post); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Reject the edit while another session holds the edit lock
+	lock, err := p.postRepo.GetEditLock(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if lock != nil && lock.Token != lockToken {
+		logger.LogOutput(nil, domain.ErrPostEditLocked)
+		return nil, domain.ErrPostEditLocked
+	}
+
 	// Create edit log
 	editLog := domain.EditLog{
 		Content:  post.Content,
@@ -159,10 +188,15 @@ func (p *postUseCase) UpdatePost(postID primitive.ObjectID, content string, medi
 	}
 	post.EditHistory = append(post.EditHistory, editLog)
 
+	visibilityChanged := post.Visibility != visibility
+	previousTags := post.Tags
+	previousMentions := post.Mentions
+
 	// Update post
 	post.Content = content
 	post.Media = media
-	post.Tags = tags
+	post.Tags = utils.NormalizeHashtags(tags)
+	post.Mentions = resolveMentions(p.userRepo, content)
 	post.Location = location
 	post.Visibility = visibility
 	post.UpdatedAt = time.Now()
@@ -174,72 +208,380 @@ func (p *postUseCase) UpdatePost(postID primitive.ObjectID, content string, medi
 		return nil, err
 	}
 
-	// Check for mentions in content
-	mentions := utils.ExtractMentions(content)
-	for _, username := range mentions {
-		// Find user by username
-		mentionedUser, err := p.userRepo.FindByUsername(username)
+	// Subposts always follow the parent's visibility
+	if visibilityChanged {
+		err = p.subPostRepo.UpdateVisibilityByParentID(postID, visibility)
 		if err != nil {
 			logger.LogOutput(nil, err)
-			continue // Skip if user not found
+			return nil, err
 		}
+	}
 
-		// Don't notify if user mentions themselves
-		if mentionedUser.ID == post.UserID {
-			continue
+	p.indexTags(post.Tags, previousTags)
+
+	// Only users mentioned by this edit are notified
+	notifyMentions(p.notificationUseCase, post.UserID, post.ID, "post", "mentioned you in a post", post.Mentions, previousMentions)
+
+	logger.LogOutput(post, nil)
+	return post, nil
+}
+
+// LockPostForEdit takes or refreshes the edit lock. An empty lockToken starts a new editing session.
+func (p *postUseCase) LockPostForEdit(userID, postID primitive.ObjectID, lockToken string) (*domain.PostEditLock, error) {
+	logger := utils.NewLogger("PostUseCase.LockPostForEdit")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	post, err := p.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := p.checkCanEdit(userID, post); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if lockToken == "" {
+		lockToken = utils.GenerateID()
+	}
+
+	lock := &domain.PostEditLock{
+		PostID:    postID,
+		UserID:    userID,
+		Token:     lockToken,
+		ExpiresAt: time.Now().Add(postEditLockTTL),
+	}
+	acquired, err := p.postRepo.AcquireEditLock(lock, postEditLockTTL)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if !acquired {
+		logger.LogOutput(nil, domain.ErrPostEditLocked)
+		return nil, domain.ErrPostEditLocked
+	}
+
+	logger.LogOutput(lock, nil)
+	return lock, nil
+}
+
+func (p *postUseCase) UnlockPost(userID, postID primitive.ObjectID, lockToken string) error {
+	logger := utils.NewLogger("PostUseCase.UnlockPost")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"postID": postID,
+	})
+
+	post, err := p.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := p.checkCanEdit(userID, post); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	err = p.postRepo.ReleaseEditLock(postID, lockToken)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput("Post unlocked successfully", nil)
+	return nil
+}
+
+// checkCanEdit allows the author and admins to change a post
+// indexTags moves tag post counts from the previous tags of a post to its current ones
+// and counts newly added tags towards trending. Failures are logged, the post itself is already saved.
+func (p *postUseCase) indexTags(current, previous []string) {
+	logger := utils.NewLogger("PostUseCase.indexTags")
+
+	added, removed := utils.DiffHashtags(previous, current)
+	if err := p.hashtagRepo.IncrementPostCounts(added, 1); err != nil {
+		logger.LogOutput(nil, err)
+	}
+	if err := p.hashtagRepo.IncrementPostCounts(removed, -1); err != nil {
+		logger.LogOutput(nil, err)
+	}
+	if err := p.hashtagRepo.RecordUsage(added, time.Now()); err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
+// mediaURLs lists the uploaded files behind the media, thumbnails included
+func mediaURLs(media []domain.Media) []string {
+	urls := make([]string, 0, len(media))
+	for _, m := range media {
+		urls = append(urls, m.URL)
+		if m.ThumbnailURL != "" {
+			urls = append(urls, m.ThumbnailURL)
 		}
+	}
+	return urls
+}
 
-		// Create mention notification
-		_, err = p.notificationUseCase.CreateNotification(
-			mentionedUser.ID, // recipientID (mentioned user)
-			post.UserID,      // senderID (user who mentioned)
-			post.ID,          // refID (reference to the post)
-			domain.NotificationTypeMention,
-			"post",                    // refType
-			"mentioned you in a post", // message
-		)
+func (p *postUseCase) publishPostCreated(post *domain.Post) {
+	p.events.Publish(domain.DomainEventPostCreated, domain.PostCreatedPayload{
+		PostID:     post.ID,
+		UserID:     post.UserID,
+		PostType:   post.PostType,
+		Visibility: post.Visibility,
+		Tags:       post.Tags,
+		MediaCount: len(post.Media),
+		CreatedAt:  post.CreatedAt,
+	})
+}
+
+func (p *postUseCase) RecordView(viewerID, postID primitive.ObjectID) (bool, error) {
+	logger := utils.NewLogger("PostUseCase.RecordView")
+	logger.LogInput(viewerID, postID)
+
+	post, err := p.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	visible, err := p.canView(viewerID, post)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if !visible {
+		err = domain.NewNotFoundError("post", postID.Hex())
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	if post.UserID == viewerID {
+		logger.LogOutput(false, nil)
+		return false, nil
+	}
+
+	first, err := p.postRepo.AddViewer(postID, viewerID, time.Now())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+	if !first {
+		logger.LogOutput(false, nil)
+		return false, nil
+	}
+
+	// The count is buffered with the other hot counters and flushed in the background
+	if err := p.postRepo.IncrementCounter(postID, domain.PostCounterViews, 1); err != nil {
+		logger.LogOutput(nil, err)
+		return false, err
+	}
+
+	logger.LogOutput(true, nil)
+	return true, nil
+}
+
+func (p *postUseCase) PinPost(userID, postID primitive.ObjectID) (*domain.Post, error) {
+	return p.setPinned("PostUseCase.PinPost", userID, postID, true)
+}
+
+func (p *postUseCase) UnpinPost(userID, postID primitive.ObjectID) (*domain.Post, error) {
+	return p.setPinned("PostUseCase.UnpinPost", userID, postID, false)
+}
+
+// setPinned pins or unpins a post. Pinning a pinned post again keeps its place.
+func (p *postUseCase) setPinned(name string, userID, postID primitive.ObjectID, pinned bool) (*domain.Post, error) {
+	logger := utils.NewLogger(name)
+	logger.LogInput(userID, postID)
+
+	post, err := p.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if err := p.checkCanEdit(userID, post); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if post.IsPinned == pinned {
+		logger.LogOutput(post, nil)
+		return post, nil
+	}
+
+	if pinned {
+		count, err := p.postRepo.CountPinnedByUserID(post.UserID)
 		if err != nil {
 			logger.LogOutput(nil, err)
-			// Don't return error here as the post was updated successfully
+			return nil, err
 		}
+		if count >= domain.MaxPinnedPosts {
+			err = fmt.Errorf("%w: unpin a post first, at most %d can be pinned", domain.ErrPinnedPostLimit, domain.MaxPinnedPosts)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		now := time.Now()
+		post.PinnedAt = &now
+	} else {
+		post.PinnedAt = nil
+	}
+	post.IsPinned = pinned
+	post.UpdatedAt = time.Now()
+
+	if err := p.postRepo.Update(post); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
 	}
 
 	logger.LogOutput(post, nil)
 	return post, nil
 }
 
-func (p *postUseCase) DeletePost(postID primitive.ObjectID) error {
+func (p *postUseCase) checkCanEdit(userID primitive.ObjectID, post *domain.Post) error {
+	return authorizeOwnerOrAdmin(p.userRepo, userID.Hex(), post.UserID.Hex())
+}
+
+func (p *postUseCase) DeletePost(userID, postID primitive.ObjectID) error {
 	logger := utils.NewLogger("PostUseCase.DeletePost")
-	logger.LogInput(postID)
+	logger.LogInput(userID, postID)
 
-	// Delete all subposts first
-	subPosts, err := p.subPostRepo.FindByParentID(postID, 0, 0) // Get all subposts
+	post, err := p.postRepo.FindByID(postID)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
-	for _, subPost := range subPosts {
-		err = p.subPostRepo.Delete(subPost.ID)
-		if err != nil {
-			logger.LogOutput(nil, err)
-			return err
-		}
+
+	if err := p.checkCanEdit(userID, post); err != nil {
+		logger.LogOutput(nil, err)
+		return err
 	}
 
-	// Delete the post
+	// Delete the post, then its subposts so they can be restored together
 	err = p.postRepo.Delete(postID)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
 
+	err = p.subPostRepo.SoftDeleteByParentID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	p.indexTags(nil, post.Tags)
+
+	// The media stops counting towards the author's storage while the post is deleted
+	if err := p.storedFileRepo.SetReleased(post.UserID, mediaURLs(post.Media), true); err != nil {
+		logger.LogOutput(nil, err)
+	}
+
 	logger.LogOutput("Post and all related subposts deleted successfully", nil)
 	return nil
 }
 
-func (p *postUseCase) GetPost(postID primitive.ObjectID, includeSubPosts bool) (*domain.PostWithDetails, error) {
+// SharePost creates a post of type share pointing at postID, with an optional quote.
+// Sharing a share points at the original post.
+func (p *postUseCase) SharePost(userID, postID primitive.ObjectID, quote, visibility string) (*domain.Post, error) {
+	logger := utils.NewLogger("PostUseCase.SharePost")
+	logger.LogInput(map[string]interface{}{
+		"userID":     userID,
+		"postID":     postID,
+		"quote":      quote,
+		"visibility": visibility,
+	})
+
+	if err := checkCanPublish(p.userRepo, userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	original, err := p.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if original.PostType == domain.PostTypeShare && original.SharedPostID != nil {
+		original, err = p.postRepo.FindByID(*original.SharedPostID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	// Only public posts can be shared, so a share never reveals a restricted post
+	if original.UserID != userID && original.Visibility != domain.VisibilityPublic && original.Visibility != "" {
+		logger.LogOutput(nil, domain.ErrForbidden)
+		return nil, domain.ErrForbidden
+	}
+
+	if visibility == "" {
+		visibility = domain.VisibilityPublic
+	}
+
+	now := time.Now()
+	share := &domain.Post{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			IsActive:  true,
+			Version:   1,
+		},
+		UserID:         userID,
+		Content:        quote,
+		Media:          make([]domain.Media, 0),
+		Mentions:       resolveMentions(p.userRepo, quote),
+		Visibility:     visibility,
+		ReactionCounts: make(map[string]int),
+		EditHistory:    make([]domain.EditLog, 0),
+		PostType:       domain.PostTypeShare,
+		SharedPostID:   &original.ID,
+	}
+
+	err = p.postRepo.Create(share)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	err = p.postRepo.IncrementShareCount(original.ID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if original.UserID != userID {
+		_, err = p.notificationUseCase.CreateNotification(
+			original.UserID, // recipientID (original author)
+			userID,          // senderID (user who shared)
+			share.ID,        // refID (reference to the share)
+			domain.NotificationTypeShare,
+			"post",              // refType
+			"shared your post", // message
+		)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			// Don't return error here as the post was shared successfully
+		}
+	}
+
+	notifyMentions(p.notificationUseCase, userID, share.ID, "post", "mentioned you in a post", share.Mentions, nil)
+	p.publishPostCreated(share)
+
+	logger.LogOutput(share, nil)
+	return share, nil
+}
+
+func (p *postUseCase) GetPost(viewerID, postID primitive.ObjectID, includeSubPosts bool) (*domain.PostWithDetails, error) {
 	logger := utils.NewLogger("PostUseCase.GetPost")
 	input := map[string]interface{}{
+		"viewerID":        viewerID,
 		"postID":          postID,
 		"includeSubPosts": includeSubPosts,
 	}
@@ -251,6 +593,18 @@ func (p *postUseCase) GetPost(postID primitive.ObjectID, includeSubPosts bool) (
 		return nil, err
 	}
 
+	// Hidden posts look missing, so their existence doesn't leak
+	visible, err := p.canView(viewerID, post)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if !visible {
+		err = domain.NewNotFoundError("post", postID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	// Get user data
 	user, err := p.userRepo.FindByID(post.UserID.Hex())
 	if err != nil {
@@ -286,10 +640,11 @@ func (p *postUseCase) GetPost(postID primitive.ObjectID, includeSubPosts bool) (
 	return result, nil
 }
 
-func (p *postUseCase) ListPosts(userID primitive.ObjectID, limit, offset int, includeSubPosts bool, hasMedia bool, mediaType string) ([]domain.PostWithDetails, error) {
+func (p *postUseCase) ListPosts(viewerID, userID primitive.ObjectID, limit, offset int, includeSubPosts bool, hasMedia bool, mediaType string) ([]domain.PostWithDetails, error) {
 	logger := utils.NewLogger("PostUseCase.ListPosts")
 
 	input := map[string]interface{}{
+		"viewerID":        viewerID,
 		"userID":          userID,
 		"limit":           limit,
 		"offset":          offset,
@@ -299,7 +654,17 @@ func (p *postUseCase) ListPosts(userID primitive.ObjectID, limit, offset int, in
 	}
 	logger.LogInput(input)
 
-	posts, err := p.postRepo.FindByUserID(userID, limit, offset, hasMedia, mediaType)
+	visibilities, hidden, err := p.visibilitiesFor(viewerID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if hidden {
+		logger.LogOutput(nil, nil)
+		return []domain.PostWithDetails{}, nil
+	}
+
+	posts, err := p.postRepo.FindByUserID(userID, visibilities, limit, offset, hasMedia, mediaType)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return nil, err
@@ -344,3 +709,124 @@ func (p *postUseCase) ListPosts(userID primitive.ObjectID, limit, offset int, in
 	logger.LogOutput(result, nil)
 	return result, nil
 }
+
+func (p *postUseCase) CountPosts(viewerID, userID primitive.ObjectID, hasMedia bool, mediaType string) (int64, error) {
+	logger := utils.NewLogger("PostUseCase.CountPosts")
+	logger.LogInput(viewerID, userID, hasMedia, mediaType)
+
+	visibilities, hidden, err := p.visibilitiesFor(viewerID, userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+	if hidden {
+		logger.LogOutput(0, nil)
+		return 0, nil
+	}
+
+	count, err := p.postRepo.CountByUserID(userID, visibilities, hasMedia, mediaType)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+// GetPostsByIDs fetches posts with their authors in one round trip, keeping the requested order
+func (p *postUseCase) GetPostsByIDs(viewerID primitive.ObjectID, ids []string) ([]domain.BatchItem, error) {
+	logger := utils.NewLogger("PostUseCase.GetPostsByIDs")
+	logger.LogInput(viewerID, ids)
+
+	objectIDs, valid := utils.ParseBatchIDs(ids)
+
+	posts, err := p.postRepo.FindByIDs(objectIDs)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// Load all authors at once
+	authorIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.UserID)
+	}
+	authors, err := p.userRepo.FindByIDs(authorIDs)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	usersByID := make(map[primitive.ObjectID]*domain.PostUser, len(authors))
+	for _, user := range authors {
+		usersByID[user.ID] = &domain.PostUser{
+			ID:           user.ID,
+			Username:     user.Username,
+			DisplayName:  user.DisplayName,
+			PhotoProfile: user.PhotoProfile,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+		}
+	}
+
+	postsByID := make(map[primitive.ObjectID]*domain.PostWithDetails, len(posts))
+	for i := range posts {
+		// Posts hidden from the viewer are left out and reported as not found
+		visible, err := p.canView(viewerID, &posts[i])
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if !visible {
+			continue
+		}
+		postsByID[posts[i].ID] = &domain.PostWithDetails{
+			Post: &posts[i],
+			User: usersByID[posts[i].UserID],
+		}
+	}
+
+	items := make([]domain.BatchItem, 0, len(ids))
+	for _, id := range ids {
+		item := domain.BatchItem{ID: id}
+		objectID, ok := valid[id]
+		switch {
+		case !ok:
+			item.Error = domain.ErrInvalidID.Error()
+		case postsByID[objectID] == nil:
+			item.Error = domain.ErrNotFound.Error()
+		default:
+			item.Data = postsByID[objectID]
+		}
+		items = append(items, item)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(items)}, nil)
+	return items, nil
+}
+
+// canView applies the visibility rules of the post, and hides it from users
+// the author blocked or who blocked the author
+func (p *postUseCase) canView(viewerID primitive.ObjectID, post *domain.Post) (bool, error) {
+	if viewerID != post.UserID {
+		blocked, err := isBlockedEitherWay(p.followUseCase, viewerID, post.UserID)
+		if err != nil || blocked {
+			return false, err
+		}
+	}
+	return canViewPost(p.friendshipRepo, viewerID, post)
+}
+
+// visibilitiesFor returns the visibilities of the author's posts the viewer may
+// see, or hidden when either blocked the other
+func (p *postUseCase) visibilitiesFor(viewerID, authorID primitive.ObjectID) (visibilities []string, hidden bool, err error) {
+	if viewerID != authorID {
+		blocked, err := isBlockedEitherWay(p.followUseCase, viewerID, authorID)
+		if err != nil || blocked {
+			return nil, blocked, err
+		}
+	}
+	visibilities, err = visiblePostVisibilities(p.friendshipRepo, viewerID, authorID)
+	return visibilities, false, err
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// PresenceSweeper periodically takes users offline whose connections stopped sending
+// heartbeats without closing, which a disconnect alone can't detect
+type PresenceSweeper struct {
+	chatUsecase domain.ChatUsecase
+	interval    time.Duration
+}
+
+func NewPresenceSweeper(chatUsecase domain.ChatUsecase, interval time.Duration) *PresenceSweeper {
+	return &PresenceSweeper{
+		chatUsecase: chatUsecase,
+		interval:    interval,
+	}
+}
+
+// Run sweeps expired presence every interval until ctx is done
+func (s *PresenceSweeper) Run(ctx context.Context) {
+	logger := utils.NewLogger("PresenceSweeper.Run")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.chatUsecase.ExpirePresence(); err != nil {
+				logger.LogOutput(nil, err)
+			}
+		}
+	}
+}
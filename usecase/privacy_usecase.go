@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type privacyUseCase struct {
+	userRepo       domain.UserRepository
+	friendshipRepo domain.FriendshipRepository
+}
+
+func NewPrivacyUseCase(userRepo domain.UserRepository, friendshipRepo domain.FriendshipRepository) domain.PrivacyUseCase {
+	return &privacyUseCase{
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+	}
+}
+
+func (p *privacyUseCase) GetPrivacy(userID primitive.ObjectID) (*domain.PrivacySettings, error) {
+	logger := utils.NewLogger("PrivacyUseCase.GetPrivacy")
+	logger.LogInput(userID)
+
+	privacy, err := p.getPrivacy(userID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(privacy, nil)
+	return privacy, nil
+}
+
+func (p *privacyUseCase) UpdatePrivacy(userID primitive.ObjectID, settings domain.PrivacySettings) (*domain.PrivacySettings, error) {
+	logger := utils.NewLogger("PrivacyUseCase.UpdatePrivacy")
+	logger.LogInput(userID, settings)
+
+	settings = settings.WithDefaults()
+	for field, audience := range map[string]string{
+		"whoCanMessage":    settings.WhoCanMessage,
+		"whoCanSeeFriends": settings.WhoCanSeeFriends,
+		"whoCanComment":    settings.WhoCanComment,
+	} {
+		if !domain.IsPrivacyAudience(audience) {
+			err := fmt.Errorf("%w: %s must be everyone, friends or nobody", domain.ErrInvalidInput, field)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	if err := p.userRepo.SetPrivacy(userID, settings); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(settings, nil)
+	return &settings, nil
+}
+
+func (p *privacyUseCase) CheckCanMessage(senderID, recipientID primitive.ObjectID) error {
+	return p.checkAudience(senderID, recipientID, func(privacy *domain.PrivacySettings) string {
+		return privacy.WhoCanMessage
+	})
+}
+
+func (p *privacyUseCase) CheckCanComment(userID, authorID primitive.ObjectID) error {
+	return p.checkAudience(userID, authorID, func(privacy *domain.PrivacySettings) string {
+		return privacy.WhoCanComment
+	})
+}
+
+func (p *privacyUseCase) CheckCanSeeFriends(viewerID, userID primitive.ObjectID) error {
+	return p.checkAudience(viewerID, userID, func(privacy *domain.PrivacySettings) string {
+		return privacy.WhoCanSeeFriends
+	})
+}
+
+// checkAudience lets the viewer through when the audience the owner picked includes them
+func (p *privacyUseCase) checkAudience(viewerID, ownerID primitive.ObjectID, audience func(*domain.PrivacySettings) string) error {
+	if viewerID == ownerID {
+		return nil
+	}
+
+	privacy, err := p.getPrivacy(ownerID)
+	if err != nil {
+		return err
+	}
+
+	switch audience(privacy) {
+	case domain.PrivacyEveryone:
+		return nil
+	case domain.PrivacyFriends:
+		friendship, err := p.friendshipRepo.FindByUsers(viewerID, ownerID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		if friendship != nil && friendship.Status == "accepted" {
+			return nil
+		}
+	}
+	return domain.ErrPrivacyRestricted
+}
+
+// getPrivacy reads the user from the database, the cached user leaves privacy out
+func (p *privacyUseCase) getPrivacy(userID primitive.ObjectID) (*domain.PrivacySettings, error) {
+	users, err := p.userRepo.FindByIDs([]primitive.ObjectID{userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, domain.NewNotFoundError("user", userID.Hex())
+	}
+	privacy := users[0].Privacy.WithDefaults()
+	return &privacy, nil
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// profileField reads and writes one tracked profile field
+type profileField struct {
+	name string
+	get  func(user *domain.User) interface{}
+	// set decodes a recorded value into the field
+	set func(user *domain.User, value interface{}) error
+}
+
+func trackedProfileField[T any](name string, ptr func(user *domain.User) *T) profileField {
+	return profileField{
+		name: name,
+		get: func(user *domain.User) interface{} {
+			return *ptr(user)
+		},
+		set: func(user *domain.User, value interface{}) error {
+			data, err := bson.Marshal(bson.M{"v": value})
+			if err != nil {
+				return err
+			}
+			var holder struct {
+				V T `bson:"v"`
+			}
+			if err := bson.Unmarshal(data, &holder); err != nil {
+				return err
+			}
+			*ptr(user) = holder.V
+			return nil
+		},
+	}
+}
+
+// profileFields are the user editable fields whose changes are recorded, named like their bson keys
+var profileFields = []profileField{
+	trackedProfileField("username", func(u *domain.User) *string { return &u.Username }),
+	trackedProfileField("displayName", func(u *domain.User) *string { return &u.DisplayName }),
+	trackedProfileField("firstName", func(u *domain.User) *string { return &u.FirstName }),
+	trackedProfileField("lastName", func(u *domain.User) *string { return &u.LastName }),
+	trackedProfileField("bio", func(u *domain.User) *string { return &u.Bio }),
+	trackedProfileField("avatar", func(u *domain.User) *string { return &u.Avatar }),
+	trackedProfileField("photoProfile", func(u *domain.User) *string { return &u.PhotoProfile }),
+	trackedProfileField("photoCover", func(u *domain.User) *string { return &u.PhotoCover }),
+	trackedProfileField("dateOfBirth", func(u *domain.User) *time.Time { return &u.DateOfBirth }),
+	trackedProfileField("gender", func(u *domain.User) *string { return &u.Gender }),
+	trackedProfileField("interestedIn", func(u *domain.User) *[]string { return &u.InterestedIn }),
+	trackedProfileField("location", func(u *domain.User) *domain.GeoLocation { return &u.Location }),
+	trackedProfileField("relationStatus", func(u *domain.User) *string { return &u.RelationStatus }),
+	trackedProfileField("height", func(u *domain.User) *float64 { return &u.Height }),
+	trackedProfileField("interests", func(u *domain.User) *[]string { return &u.Interests }),
+	trackedProfileField("legacyInterests", func(u *domain.User) *[]string { return &u.LegacyInterests }),
+	trackedProfileField("occupation", func(u *domain.User) *string { return &u.Occupation }),
+	trackedProfileField("education", func(u *domain.User) *string { return &u.Education }),
+	trackedProfileField("phoneNumber", func(u *domain.User) *string { return &u.PhoneNumber }),
+	trackedProfileField("datingPhotos", func(u *domain.User) *[]domain.DatingPhoto { return &u.DatingPhotos }),
+	trackedProfileField("isVerified", func(u *domain.User) *bool { return &u.IsVerified }),
+	trackedProfileField("isActive", func(u *domain.User) *bool { return &u.IsActive }),
+	trackedProfileField("live", func(u *domain.User) *domain.Live { return &u.Live }),
+	trackedProfileField("hideReadReceipts", func(u *domain.User) *bool { return &u.HideReadReceipts }),
+	trackedProfileField("lastSeenVisibility", func(u *domain.User) *string { return &u.LastSeenVisibility }),
+	trackedProfileField("coarseLastSeen", func(u *domain.User) *bool { return &u.CoarseLastSeen }),
+}
+
+func findProfileField(name string) (profileField, bool) {
+	for _, field := range profileFields {
+		if field.name == name {
+			return field, true
+		}
+	}
+	return profileField{}, false
+}
+
+// sameProfileValue compares values the way they are stored, so times in
+// different zones or with sub-millisecond precision don't count as changes
+func sameProfileValue(a, b interface{}) bool {
+	dataA, errA := bson.Marshal(bson.M{"v": a})
+	dataB, errB := bson.Marshal(bson.M{"v": b})
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// diffProfile returns a change for every tracked field that differs between before and after
+func diffProfile(before, after *domain.User) []domain.ProfileChange {
+	changes := make([]domain.ProfileChange, 0)
+	for _, field := range profileFields {
+		oldValue, newValue := field.get(before), field.get(after)
+		if sameProfileValue(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, domain.ProfileChange{
+			UserID:   after.ID,
+			Field:    field.name,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+	return changes
+}
+
+// recordProfileChanges records the changes of a profile update that has already
+// been saved, so a failure only gets logged
+func recordProfileChanges(changeRepo domain.ProfileChangeRepository, actorID primitive.ObjectID, before, after *domain.User, source string, rollbackOf *primitive.ObjectID) {
+	changes := diffProfile(before, after)
+	for i := range changes {
+		changes[i].ActorID = actorID
+		changes[i].Source = source
+		changes[i].RollbackOf = rollbackOf
+	}
+	if err := changeRepo.CreateMany(changes); err != nil {
+		utils.NewLogger("recordProfileChanges").LogOutput(nil, err)
+	}
+}
+
+type profileChangeUseCase struct {
+	changeRepo domain.ProfileChangeRepository
+	userRepo   domain.UserRepository
+}
+
+func NewProfileChangeUseCase(changeRepo domain.ProfileChangeRepository, userRepo domain.UserRepository) domain.ProfileChangeUseCase {
+	return &profileChangeUseCase{
+		changeRepo: changeRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (u *profileChangeUseCase) ListChanges(userID primitive.ObjectID, field string, limit, offset int) ([]domain.ProfileChange, error) {
+	logger := utils.NewLogger("ProfileChangeUseCase.ListChanges")
+	logger.LogInput(map[string]interface{}{
+		"userID": userID,
+		"field":  field,
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	if field != "" {
+		if _, ok := findProfileField(field); !ok {
+			err := fmt.Errorf("%w: unknown profile field %q", domain.ErrInvalidInput, field)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	changes, err := u.changeRepo.FindByUser(userID, field, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(changes)}, nil)
+	return changes, nil
+}
+
+func (u *profileChangeUseCase) RollbackChange(actorID, userID, changeID primitive.ObjectID) (*domain.User, error) {
+	logger := utils.NewLogger("ProfileChangeUseCase.RollbackChange")
+	logger.LogInput(map[string]interface{}{
+		"actorID":  actorID,
+		"userID":   userID,
+		"changeID": changeID,
+	})
+
+	if err := authorizeOwnerOrAdmin(u.userRepo, actorID.Hex(), userID.Hex()); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	change, err := u.changeRepo.FindByID(changeID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if change.UserID != userID {
+		err = domain.NewNotFoundError("profile change", changeID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	field, ok := findProfileField(change.Field)
+	if !ok {
+		err = fmt.Errorf("%w: %s can't be rolled back", domain.ErrInvalidInput, change.Field)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil {
+		err = domain.NewNotFoundError("user", userID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	before := *user
+	if err := field.set(user, change.OldValue); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if sameProfileValue(field.get(&before), field.get(user)) {
+		logger.LogOutput(user, nil)
+		return user, nil
+	}
+
+	// A username given up since may belong to someone else now
+	if field.name == "username" {
+		if user.Username == "" {
+			err = fmt.Errorf("%w: the user had no username", domain.ErrInvalidInput)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		existing, err := u.userRepo.FindByUsername(user.Username)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if existing != nil && existing.ID != user.ID {
+			logger.LogOutput(nil, domain.ErrUsernameTaken)
+			return nil, domain.ErrUsernameTaken
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+	user.Version++
+	if err := u.userRepo.Update(user); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	recordProfileChanges(u.changeRepo, actorID, &before, user, domain.ProfileChangeSourceRollback, &change.ID)
+
+	logger.LogOutput(user, nil)
+	return user, nil
+}
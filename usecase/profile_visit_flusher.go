@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// ProfileVisitFlusher periodically adds the profile visits buffered in Redis to
+// the daily visit stats, so a visit costs a Redis write instead of a MongoDB upsert
+type ProfileVisitFlusher struct {
+	visitRepo domain.ProfileVisitRepository
+	interval  time.Duration
+	batchSize int
+}
+
+func NewProfileVisitFlusher(visitRepo domain.ProfileVisitRepository, interval time.Duration, batchSize int) *ProfileVisitFlusher {
+	return &ProfileVisitFlusher{
+		visitRepo: visitRepo,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run flushes buffered visits every interval, and once more when ctx is done
+func (f *ProfileVisitFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Hand the buffered visits over before the process exits
+			f.flush()
+			return
+		case <-ticker.C:
+			f.flush()
+		}
+	}
+}
+
+// flush drains the buffered visits batch by batch, stopping at the first failure
+func (f *ProfileVisitFlusher) flush() {
+	logger := utils.NewLogger("ProfileVisitFlusher.flush")
+
+	total := 0
+	for {
+		flushed, err := f.visitRepo.Flush(f.batchSize)
+		total += flushed
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return
+		}
+		if flushed < f.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.LogOutput(map[string]interface{}{"profileDays": total}, nil)
+	}
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type profileVisitUseCase struct {
+	visitRepo domain.ProfileVisitRepository
+}
+
+func NewProfileVisitUseCase(visitRepo domain.ProfileVisitRepository) domain.ProfileVisitUseCase {
+	return &profileVisitUseCase{
+		visitRepo: visitRepo,
+	}
+}
+
+// RecordVisit counts the visit under its source. Users visiting their own profile aren't counted.
+func (u *profileVisitUseCase) RecordVisit(ownerID, visitorID primitive.ObjectID, ref string) error {
+	logger := utils.NewLogger("ProfileVisitUseCase.RecordVisit")
+	logger.LogInput(map[string]interface{}{
+		"ownerID":   ownerID.Hex(),
+		"visitorID": visitorID.Hex(),
+		"ref":       ref,
+	})
+
+	if ownerID == visitorID {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	counted, err := u.visitRepo.Record(ownerID, visitorID, domain.NormalizeProfileVisitSource(ref), time.Now())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(counted, nil)
+	return nil
+}
+
+// GetInsights returns the visits of the last days, with an entry for every day
+// so clients can chart them directly
+func (u *profileVisitUseCase) GetInsights(ownerID primitive.ObjectID, days int) (*domain.ProfileVisitInsights, error) {
+	logger := utils.NewLogger("ProfileVisitUseCase.GetInsights")
+	logger.LogInput(map[string]interface{}{
+		"ownerID": ownerID.Hex(),
+		"days":    days,
+	})
+
+	if days <= 0 {
+		days = domain.DefaultProfileVisitDays
+	}
+	if days > domain.MaxProfileVisitDays {
+		days = domain.MaxProfileVisitDays
+	}
+
+	from := time.Now().UTC().AddDate(0, 0, -(days - 1))
+	stored, err := u.visitRepo.FindDaily(ownerID, from.Format(domain.ProfileVisitDayFormat))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	byDay := make(map[string]domain.ProfileVisitDay, len(stored))
+	for _, day := range stored {
+		byDay[day.Day] = day
+	}
+
+	insights := &domain.ProfileVisitInsights{
+		Days:    days,
+		Sources: make(map[string]int64),
+		Daily:   make([]domain.ProfileVisitDay, 0, days),
+	}
+	for i := 0; i < days; i++ {
+		key := from.AddDate(0, 0, i).Format(domain.ProfileVisitDayFormat)
+		day, ok := byDay[key]
+		if !ok {
+			day = domain.ProfileVisitDay{Day: key}
+		}
+		if day.Sources == nil {
+			day.Sources = make(map[string]int64)
+		}
+		insights.Total += day.Total
+		for source, count := range day.Sources {
+			insights.Sources[source] += count
+		}
+		insights.Daily = append(insights.Daily, day)
+	}
+
+	logger.LogOutput(map[string]interface{}{"total": insights.Total}, nil)
+	return insights, nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetPostAnalytics caches the analytics of hot posts briefly, their authors tend
+// to refresh while the reactions pour in. Other posts are cheap to aggregate.
+func (r *reactionUseCase) GetPostAnalytics(userID, postID primitive.ObjectID) (*domain.PostReactionAnalytics, error) {
+	logger := utils.NewLogger("ReactionUseCase.GetPostAnalytics")
+	logger.LogInput(userID, postID)
+
+	post, err := r.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if post.UserID != userID {
+		logger.LogOutput(nil, domain.ErrForbidden)
+		return nil, domain.ErrForbidden
+	}
+
+	hot := r.hotContent.IsHotPost(post)
+	if hot {
+		cached, err := r.reactionRepo.GetPostAnalytics(postID)
+		if err != nil {
+			// The aggregation still answers
+			logger.LogOutput(nil, err)
+		}
+		if cached != nil {
+			logger.LogOutput(cached.GeneratedAt, nil)
+			return cached, nil
+		}
+	}
+
+	friendships, err := r.friendshipRepo.FindFriends(userID, domain.ReactionAnalyticsMaxFriends, 0)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	friendIDs := make([]primitive.ObjectID, 0, len(friendships))
+	for _, friendship := range friendships {
+		if friendship.UserID1 == userID {
+			friendIDs = append(friendIDs, friendship.UserID2)
+		} else {
+			friendIDs = append(friendIDs, friendship.UserID1)
+		}
+	}
+
+	now := time.Now().UTC()
+	until := now.Truncate(time.Hour)
+	since := until.Add(-(domain.ReactionTimelineHours - 1) * time.Hour)
+	if created := post.CreatedAt.UTC().Truncate(time.Hour); created.After(since) {
+		since = created
+	}
+
+	analytics, err := r.reactionRepo.AnalyzePost(postID, friendIDs, domain.ReactionAnalyticsFriends, since)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	for _, count := range analytics.Types {
+		analytics.Total += count.Count
+	}
+	analytics.Timeline = fillReactionTimeline(analytics.Timeline, since, until)
+	analytics.GeneratedAt = now
+
+	if hot {
+		if err := r.reactionRepo.SetPostAnalytics(analytics, domain.ReactionAnalyticsCacheTTL); err != nil {
+			logger.LogOutput(nil, err)
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"total": analytics.Total, "hot": hot}, nil)
+	return analytics, nil
+}
+
+// fillReactionTimeline returns one bucket per hour from since to until, both
+// included, taking the counts of the aggregated buckets
+func fillReactionTimeline(buckets []domain.ReactionTimelineBucket, since, until time.Time) []domain.ReactionTimelineBucket {
+	counts := make(map[time.Time]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Hour.UTC()] = bucket.Count
+	}
+
+	timeline := make([]domain.ReactionTimelineBucket, 0, int(until.Sub(since)/time.Hour)+1)
+	for hour := since; !hour.After(until); hour = hour.Add(time.Hour) {
+		timeline = append(timeline, domain.ReactionTimelineBucket{Hour: hour, Count: counts[hour]})
+	}
+	return timeline
+}
@@ -14,6 +14,8 @@ type reactionUseCase struct {
 	postRepo          domain.PostRepository
 	commentRepo       domain.CommentRepository
 	notificationUseCase domain.NotificationUseCase
+	hotContent        domain.HotContentPolicy
+	friendshipRepo    domain.FriendshipRepository
 }
 
 func NewReactionUseCase(
@@ -21,12 +23,16 @@ func NewReactionUseCase(
 	postRepo domain.PostRepository,
 	commentRepo domain.CommentRepository,
 	notificationUseCase domain.NotificationUseCase,
+	hotContent domain.HotContentPolicy,
+	friendshipRepo domain.FriendshipRepository,
 ) domain.ReactionUseCase {
 	return &reactionUseCase{
 		reactionRepo:       reactionRepo,
 		postRepo:          postRepo,
 		commentRepo:       commentRepo,
 		notificationUseCase: notificationUseCase,
+		hotContent:        hotContent,
+		friendshipRepo:    friendshipRepo,
 	}
 }
 
@@ -86,18 +92,7 @@ func (r *reactionUseCase) CreateReaction(userID, postID primitive.ObjectID, comm
 
 	// Update reaction counts
 	if commentID == nil {
-		post, err := r.postRepo.FindByID(postID)
-		if err != nil {
-			logger.LogOutput(nil, err)
-			return nil, err
-		}
-
-		if post.ReactionCounts == nil {
-			post.ReactionCounts = make(map[string]int)
-		}
-		post.ReactionCounts[reactionType]++
-
-		err = r.postRepo.Update(post)
+		err = r.postRepo.IncrementCounter(postID, domain.PostReactionCounter(reactionType), 1)
 		if err != nil {
 			logger.LogOutput(nil, err)
 			return nil, err
@@ -149,14 +144,29 @@ func (r *reactionUseCase) CreateReaction(userID, postID primitive.ObjectID, comm
 			logger.LogOutput(nil, err)
 			// Don't return error, just skip notification
 		} else if post.UserID != userID { // Don't notify if user reacts to their own post
-			_, err = r.notificationUseCase.CreateNotification(
-				post.UserID,           // recipientID (post owner)
-				userID,                // senderID (user who reacted)
-				reaction.ID,           // refID (reference to the reaction)
-				domain.NotificationTypeLike,
-				"post",                // refType
-				"reacted to your post", // message
-			)
+			// Reactions on hot posts are aggregated per post instead
+			hot := r.hotContent.IsHotPost(post)
+			recordNotifyStrategy("reaction", hot)
+			if hot {
+				_, err = r.notificationUseCase.CreateAggregatedNotification(
+					post.UserID,
+					userID,
+					post.ID,
+					domain.NotificationTypeLike,
+					"post",
+					"reacted to your post",
+					r.hotContent.AggregationWindow,
+				)
+			} else {
+				_, err = r.notificationUseCase.CreateNotification(
+					post.UserID,           // recipientID (post owner)
+					userID,                // senderID (user who reacted)
+					reaction.ID,           // refID (reference to the reaction)
+					domain.NotificationTypeLike,
+					"post",                // refType
+					"reacted to your post", // message
+				)
+			}
 			if err != nil {
 				logger.LogOutput(nil, err)
 				// Don't return error here as the reaction was created successfully
@@ -187,8 +197,7 @@ func (r *reactionUseCase) DeleteReaction(reactionID primitive.ObjectID) error {
 			return err
 		}
 		if count := post.ReactionCounts[reaction.Type]; count > 0 {
-			post.ReactionCounts[reaction.Type]--
-			err = r.postRepo.Update(post)
+			err = r.postRepo.IncrementCounter(post.ID, domain.PostReactionCounter(reaction.Type), -1)
 			if err != nil {
 				logger.LogOutput(nil, err)
 				return err
@@ -261,3 +270,64 @@ func (r *reactionUseCase) ListReactions(targetID primitive.ObjectID, isComment b
 	logger.LogOutput(reactions, nil)
 	return reactions, nil
 }
+
+func (r *reactionUseCase) CountReactions(targetID primitive.ObjectID, isComment bool) (int64, error) {
+	logger := utils.NewLogger("ReactionUseCase.CountReactions")
+	logger.LogInput(targetID, isComment)
+
+	count, err := r.reactionRepo.CountByTarget(targetID, isComment)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(count, nil)
+	return count, nil
+}
+
+func (r *reactionUseCase) GetReactionSummary(targetID primitive.ObjectID, isComment bool, usersPerType int) (*domain.ReactionSummary, error) {
+	logger := utils.NewLogger("ReactionUseCase.GetReactionSummary")
+	logger.LogInput(targetID, isComment, usersPerType)
+
+	if usersPerType <= 0 {
+		usersPerType = domain.DefaultReactionSummaryUsers
+	}
+	if usersPerType > domain.MaxReactionSummaryUsers {
+		usersPerType = domain.MaxReactionSummaryUsers
+	}
+
+	types, err := r.reactionRepo.SummarizeByTarget(targetID, isComment, usersPerType)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	summary := &domain.ReactionSummary{
+		TargetID: targetID,
+		Types:    types,
+	}
+	for i := range summary.Types {
+		summary.Total += summary.Types[i].Count
+		summary.Types[i].Users = orderReactionUsers(summary.Types[i].UserIDs, summary.Types[i].Users)
+	}
+
+	logger.LogOutput(summary, nil)
+	return summary, nil
+}
+
+// orderReactionUsers puts the looked up profiles back in reaction order, most recent first.
+// Users that no longer exist are left out.
+func orderReactionUsers(ids []primitive.ObjectID, users []domain.ReactionUser) []domain.ReactionUser {
+	byID := make(map[primitive.ObjectID]domain.ReactionUser, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	ordered := make([]domain.ReactionUser, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := byID[id]; ok {
+			ordered = append(ordered, user)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,301 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var reportTargetTypes = map[string]bool{
+	domain.ReportTargetPost:    true,
+	domain.ReportTargetComment: true,
+	domain.ReportTargetUser:    true,
+	domain.ReportTargetMessage: true,
+}
+
+var reportReasons = map[string]bool{
+	domain.ReportReasonSpam:           true,
+	domain.ReportReasonHarassment:     true,
+	domain.ReportReasonHateSpeech:     true,
+	domain.ReportReasonViolence:       true,
+	domain.ReportReasonNudity:         true,
+	domain.ReportReasonMisinformation: true,
+	domain.ReportReasonImpersonation:  true,
+	domain.ReportReasonOther:          true,
+}
+
+type reportUseCase struct {
+	reportRepo     domain.ReportRepository
+	postRepo       domain.PostRepository
+	commentRepo    domain.CommentRepository
+	userRepo       domain.UserRepository
+	chatRepo       domain.ChatRepository
+	friendshipRepo domain.FriendshipRepository
+	auditLogRepo   domain.AuditLogRepository
+}
+
+func NewReportUseCase(
+	reportRepo domain.ReportRepository,
+	postRepo domain.PostRepository,
+	commentRepo domain.CommentRepository,
+	userRepo domain.UserRepository,
+	chatRepo domain.ChatRepository,
+	friendshipRepo domain.FriendshipRepository,
+	auditLogRepo domain.AuditLogRepository,
+) domain.ReportUseCase {
+	return &reportUseCase{
+		reportRepo:     reportRepo,
+		postRepo:       postRepo,
+		commentRepo:    commentRepo,
+		userRepo:       userRepo,
+		chatRepo:       chatRepo,
+		friendshipRepo: friendshipRepo,
+		auditLogRepo:   auditLogRepo,
+	}
+}
+
+func (u *reportUseCase) CreateReport(reporterID primitive.ObjectID, req domain.ReportRequest) (*domain.Report, error) {
+	logger := utils.NewLogger("ReportUseCase.CreateReport")
+	logger.LogInput(reporterID, req)
+
+	details := strings.TrimSpace(req.Details)
+	var err error
+	switch {
+	case !reportTargetTypes[req.TargetType]:
+		err = fmt.Errorf("%w: unknown target type %q", domain.ErrInvalidInput, req.TargetType)
+	case !reportReasons[req.Reason]:
+		err = fmt.Errorf("%w: unknown reason %q", domain.ErrInvalidInput, req.Reason)
+	case req.Reason == domain.ReportReasonOther && details == "":
+		err = fmt.Errorf("%w: describe the problem when the reason is other", domain.ErrInvalidInput)
+	case utf8.RuneCountInString(details) > domain.MaxReportDetailsLength:
+		err = fmt.Errorf("%w: details can be at most %d characters", domain.ErrInvalidInput, domain.MaxReportDetailsLength)
+	}
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	ownerID, err := u.findTargetOwner(reporterID, req.TargetType, req.TargetID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if ownerID == reporterID {
+		err = fmt.Errorf("%w: you can't report yourself", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	report := &domain.Report{
+		ReporterID:    reporterID,
+		TargetType:    req.TargetType,
+		TargetID:      req.TargetID,
+		TargetOwnerID: ownerID,
+		Reason:        req.Reason,
+		Details:       details,
+		Status:        domain.ReportStatusOpen,
+	}
+	if err := u.reportRepo.Create(report); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return report, nil
+}
+
+func (u *reportUseCase) ListReports(filter domain.ReportFilter, limit, offset int) ([]domain.Report, error) {
+	logger := utils.NewLogger("ReportUseCase.ListReports")
+	logger.LogInput(filter, limit, offset)
+
+	if err := validateReportFilter(filter); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	reports, err := u.reportRepo.FindAll(filter, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(reports)}, nil)
+	return reports, nil
+}
+
+func (u *reportUseCase) GetReport(id primitive.ObjectID) (*domain.Report, error) {
+	logger := utils.NewLogger("ReportUseCase.GetReport")
+	logger.LogInput(id)
+
+	report, err := u.reportRepo.FindByID(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return report, nil
+}
+
+func (u *reportUseCase) ResolveReport(adminID, reportID primitive.ObjectID, req domain.ResolveReportRequest) (*domain.Report, error) {
+	logger := utils.NewLogger("ReportUseCase.ResolveReport")
+	logger.LogInput(adminID, reportID, req)
+
+	if req.Status != domain.ReportStatusResolved && req.Status != domain.ReportStatusDismissed {
+		err := fmt.Errorf("%w: status must be %s or %s", domain.ErrInvalidInput, domain.ReportStatusResolved, domain.ReportStatusDismissed)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	report, err := u.reportRepo.FindByID(reportID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if report.Status != domain.ReportStatusOpen {
+		err = fmt.Errorf("%w: the report is already %s", domain.ErrInvalidInput, report.Status)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	note := strings.TrimSpace(req.Note)
+	closed, err := u.reportRepo.ResolveOpen(report.TargetType, report.TargetID, req.Status, adminID, note)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// The reports are already closed, a failed audit record only gets logged
+	err = u.auditLogRepo.Create(&domain.AuditLog{
+		ActorID:    adminID,
+		Action:     domain.AuditActionResolveReport,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Metadata: map[string]interface{}{
+			"reportId": report.ID.Hex(),
+			"status":   req.Status,
+			"note":     note,
+			"closed":   closed,
+		},
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+
+	report, err = u.reportRepo.FindByID(reportID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(report.ID, nil)
+	return report, nil
+}
+
+func (u *reportUseCase) ListTargetCounts(filter domain.ReportFilter, limit, offset int) ([]domain.ReportCount, error) {
+	logger := utils.NewLogger("ReportUseCase.ListTargetCounts")
+	logger.LogInput(filter, limit, offset)
+
+	if err := validateReportFilter(filter); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	counts, err := u.reportRepo.CountByTarget(filter, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(counts)}, nil)
+	return counts, nil
+}
+
+// findTargetOwner returns who is responsible for the reported target. Targets the
+// reporter can't see are reported as not found.
+func (u *reportUseCase) findTargetOwner(reporterID primitive.ObjectID, targetType, targetID string) (primitive.ObjectID, error) {
+	switch targetType {
+	case domain.ReportTargetPost:
+		id, err := primitive.ObjectIDFromHex(targetID)
+		if err != nil {
+			return primitive.NilObjectID, domain.ErrInvalidID
+		}
+		post, err := u.postRepo.FindByID(id)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		canView, err := canViewPost(u.friendshipRepo, reporterID, post)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		if !canView {
+			return primitive.NilObjectID, domain.NewNotFoundError("post", targetID)
+		}
+		return post.UserID, nil
+
+	case domain.ReportTargetComment:
+		id, err := primitive.ObjectIDFromHex(targetID)
+		if err != nil {
+			return primitive.NilObjectID, domain.ErrInvalidID
+		}
+		comment, err := u.commentRepo.FindByID(id)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		return comment.UserID, nil
+
+	case domain.ReportTargetUser:
+		if _, err := primitive.ObjectIDFromHex(targetID); err != nil {
+			return primitive.NilObjectID, domain.ErrInvalidID
+		}
+		user, err := u.userRepo.FindByID(targetID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		if user == nil {
+			return primitive.NilObjectID, domain.NewNotFoundError("user", targetID)
+		}
+		return user.ID, nil
+
+	case domain.ReportTargetMessage:
+		if _, err := primitive.ObjectIDFromHex(targetID); err != nil {
+			return primitive.NilObjectID, domain.ErrInvalidID
+		}
+		message, err := u.chatRepo.GetMessage(targetID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		if message == nil || message.Type == domain.ChatMessageTypeSystem {
+			return primitive.NilObjectID, domain.NewNotFoundError("message", targetID)
+		}
+		room, err := u.chatRepo.GetRoom(message.RoomID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		if room == nil || !utils.Contains(room.Members, reporterID.Hex()) {
+			return primitive.NilObjectID, domain.NewNotFoundError("message", targetID)
+		}
+		senderID, err := primitive.ObjectIDFromHex(message.SenderID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		return senderID, nil
+	}
+	return primitive.NilObjectID, fmt.Errorf("%w: unknown target type %q", domain.ErrInvalidInput, targetType)
+}
+
+func validateReportFilter(filter domain.ReportFilter) error {
+	switch filter.Status {
+	case "", domain.ReportStatusOpen, domain.ReportStatusResolved, domain.ReportStatusDismissed:
+	default:
+		return fmt.Errorf("%w: unknown status %q", domain.ErrInvalidInput, filter.Status)
+	}
+	if filter.TargetType != "" && !reportTargetTypes[filter.TargetType] {
+		return fmt.Errorf("%w: unknown target type %q", domain.ErrInvalidInput, filter.TargetType)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// ScheduledJob is a maintenance job run on a fixed interval
+type ScheduledJob struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs periodic maintenance jobs in the background. Each job has its
+// own ticker and never overlaps with itself, a run that takes longer than the
+// interval just delays the next one.
+type Scheduler struct {
+	jobs []ScheduledJob
+	wg   sync.WaitGroup
+}
+
+func NewScheduler(jobs ...ScheduledJob) *Scheduler {
+	return &Scheduler{
+		jobs: jobs,
+	}
+}
+
+// Start runs every job once its interval passed and then on every tick until
+// ctx is done. Jobs without an interval are disabled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			utils.NewLogger("Scheduler.Start").LogOutput(job.Name+" is disabled", nil)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(job ScheduledJob) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until the running jobs returned after ctx is done, so shutdown
+// doesn't cut a job off halfway
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job ScheduledJob) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, job)
+		}
+	}
+}
+
+// run runs the job once, recovering from panics so one broken job doesn't take
+// the process down
+func (s *Scheduler) run(ctx context.Context, job ScheduledJob) {
+	logger := utils.NewLogger("Scheduler." + job.Name)
+	defer func() {
+		if r := recover(); r != nil {
+			utils.IncrementMetric("scheduler." + job.Name + ".failed")
+			logger.LogOutput(nil, fmt.Errorf("panic recovered in %s: %v", job.Name, r))
+		}
+	}()
+
+	started := time.Now()
+	if err := job.Run(ctx); err != nil {
+		utils.IncrementMetric("scheduler." + job.Name + ".failed")
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	utils.IncrementMetric("scheduler." + job.Name + ".succeeded")
+	logger.LogOutput(map[string]interface{}{"took": time.Since(started).String()}, nil)
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+// normalizeSearchQuery lowercases the query and collapses its whitespace, so the
+// same search typed differently is remembered once
+func normalizeSearchQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// RecordSearch remembers the query for the user. Only the user's first search of
+// a query counts towards its popularity, repeating a search doesn't promote it.
+func (s *searchUseCase) RecordSearch(userID, query string) error {
+	logger := utils.NewLogger("SearchUseCase.RecordSearch")
+	logger.LogInput(userID, query)
+
+	query = normalizeSearchQuery(query)
+	if query == "" || utf8.RuneCountInString(query) > domain.MaxSearchQueryLength {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+
+	isNew, err := s.historyRepo.AddHistory(userID, query, time.Now())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if isNew {
+		if err := s.historyRepo.IncrementPopular(query); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
+	logger.LogOutput(isNew, nil)
+	return nil
+}
+
+func (s *searchUseCase) GetHistory(userID string) ([]domain.SearchHistoryEntry, error) {
+	logger := utils.NewLogger("SearchUseCase.GetHistory")
+	logger.LogInput(userID)
+
+	entries, err := s.historyRepo.GetHistory(userID, domain.MaxSearchHistory)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(entries)}, nil)
+	return entries, nil
+}
+
+func (s *searchUseCase) DeleteHistoryEntry(userID, query string) error {
+	logger := utils.NewLogger("SearchUseCase.DeleteHistoryEntry")
+	logger.LogInput(userID, query)
+
+	removed, err := s.historyRepo.DeleteHistory(userID, normalizeSearchQuery(query))
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if !removed {
+		err = domain.NewNotFoundError("search history entry", query)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (s *searchUseCase) ClearHistory(userID string) error {
+	logger := utils.NewLogger("SearchUseCase.ClearHistory")
+	logger.LogInput(userID)
+
+	if err := s.historyRepo.ClearHistory(userID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (s *searchUseCase) Suggest(userID, prefix string, limit int) ([]domain.SearchSuggestion, error) {
+	logger := utils.NewLogger("SearchUseCase.Suggest")
+	logger.LogInput(userID, prefix, limit)
+
+	if limit < 1 || limit > domain.MaxSearchSuggestions {
+		err := fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, domain.MaxSearchSuggestions)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	// A trailing space ends a word, "new " shouldn't suggest "newsletter"
+	wordEnded := strings.HasSuffix(prefix, " ")
+	prefix = normalizeSearchQuery(prefix)
+	suggestions := make([]domain.SearchSuggestion, 0, limit)
+	if prefix == "" {
+		logger.LogOutput(suggestions, nil)
+		return suggestions, nil
+	}
+
+	if wordEnded {
+		prefix += " "
+	}
+
+	history, err := s.historyRepo.GetHistory(userID, domain.MaxSearchHistory)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, entry := range history {
+		if len(suggestions) == limit {
+			break
+		}
+		if strings.HasPrefix(entry.Query, prefix) {
+			seen[entry.Query] = true
+			suggestions = append(suggestions, domain.SearchSuggestion{Query: entry.Query, Source: domain.SearchSuggestionHistory})
+		}
+	}
+
+	if len(suggestions) < limit {
+		// Ask for enough to fill up after dropping the ones already suggested
+		popular, err := s.historyRepo.SuggestPopular(prefix, domain.MinSuggestedSearchUsers, limit+len(seen))
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		for _, query := range popular {
+			if len(suggestions) == limit {
+				break
+			}
+			if !seen[query] {
+				suggestions = append(suggestions, domain.SearchSuggestion{Query: query, Source: domain.SearchSuggestionPopular})
+			}
+		}
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(suggestions)}, nil)
+	return suggestions, nil
+}
+
+func (s *searchUseCase) TrimSuggestions() error {
+	logger := utils.NewLogger("SearchUseCase.TrimSuggestions")
+
+	removed, err := s.historyRepo.TrimPopular(domain.MaxPopularSearches)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(removed, nil)
+	return nil
+}
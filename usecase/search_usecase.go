@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// contactMatchScore ranks exact email and phone number matches above any text match
+const contactMatchScore = 1000
+
+// phoneNumberPattern matches phone numbers, spaces and dashes allowed
+var phoneNumberPattern = regexp.MustCompile(`^\+?[0-9][0-9 -]{5,18}[0-9]$`)
+
+type searchUseCase struct {
+	searchRepo  domain.SearchRepository
+	userRepo    domain.UserRepository
+	historyRepo domain.SearchHistoryRepository
+}
+
+func NewSearchUseCase(searchRepo domain.SearchRepository, userRepo domain.UserRepository, historyRepo domain.SearchHistoryRepository) domain.SearchUseCase {
+	return &searchUseCase{
+		searchRepo:  searchRepo,
+		userRepo:    userRepo,
+		historyRepo: historyRepo,
+	}
+}
+
+// Search returns posts and users ranked together by text score.
+// Each source is read up to offset+limit hits, merged, then the requested page is cut out.
+func (s *searchUseCase) Search(query string, searchType domain.SearchResultType, limit, offset int) ([]domain.SearchResult, error) {
+	logger := utils.NewLogger("SearchUseCase.Search")
+	input := map[string]interface{}{
+		"query":      query,
+		"searchType": searchType,
+		"limit":      limit,
+		"offset":     offset,
+	}
+	logger.LogInput(input)
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return nil, domain.ErrInvalidInput
+	}
+
+	results := make([]domain.SearchResult, 0)
+	window := offset + limit
+	if window > domain.MaxSearchWindow {
+		window = domain.MaxSearchWindow
+	}
+	if offset >= window {
+		logger.LogOutput(results, nil)
+		return results, nil
+	}
+
+	if searchType == domain.SearchTypeAll || searchType == domain.SearchTypePost {
+		postResults, err := s.searchPosts(query, window)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		results = append(results, postResults...)
+	}
+
+	if searchType == domain.SearchTypeAll || searchType == domain.SearchTypeUser {
+		contacts, err := s.findUsersByContact(query)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		found := make(map[primitive.ObjectID]bool, len(contacts))
+		for i := range contacts {
+			found[contacts[i].ID] = true
+			searchUser := toSearchUser(&contacts[i])
+			results = append(results, domain.SearchResult{
+				Type:  domain.SearchTypeUser,
+				ID:    searchUser.ID,
+				Score: contactMatchScore,
+				Data:  &searchUser,
+			})
+		}
+
+		hits, err := s.searchRepo.SearchUsers(query, window)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		for _, hit := range hits {
+			if found[hit.User.ID] {
+				continue
+			}
+			results = append(results, domain.SearchResult{
+				Type:  domain.SearchTypeUser,
+				ID:    hit.User.ID.Hex(),
+				Score: hit.Score,
+				Data: &domain.SearchUser{
+					ID:           hit.User.ID.Hex(),
+					Username:     hit.User.Username,
+					DisplayName:  hit.User.DisplayName,
+					PhotoProfile: hit.User.PhotoProfile,
+					FirstName:    hit.User.FirstName,
+					LastName:     hit.User.LastName,
+				},
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if offset >= len(results) {
+		results = results[:0]
+	} else {
+		end := offset + limit
+		if end > len(results) {
+			end = len(results)
+		}
+		results = results[offset:end]
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(results)}, nil)
+	return results, nil
+}
+
+// findUsersByContact looks the query up as an exact email or phone number when
+// it looks like one. Only users who opted in to being found that way match.
+func (s *searchUseCase) findUsersByContact(query string) ([]domain.User, error) {
+	var email, phone string
+	if at := strings.Index(query, "@"); at > 0 && strings.Contains(query[at:], ".") && !strings.ContainsAny(query, " \t") {
+		email = strings.ToLower(query)
+	} else if phoneNumberPattern.MatchString(query) {
+		phone = strings.NewReplacer(" ", "", "-", "").Replace(query)
+	}
+	if email == "" && phone == "" {
+		return nil, nil
+	}
+	return s.searchRepo.FindUsersByContact(email, phone, domain.MaxContactMatches)
+}
+
+// searchPosts loads matching posts and hydrates their authors in one query
+func (s *searchUseCase) searchPosts(query string, limit int) ([]domain.SearchResult, error) {
+	hits, err := s.searchRepo.SearchPosts(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	authorIDs := make([]primitive.ObjectID, 0, len(hits))
+	for _, hit := range hits {
+		authorIDs = append(authorIDs, hit.Post.UserID)
+	}
+	authors, err := s.userRepo.FindByIDs(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByID := make(map[primitive.ObjectID]*domain.PostUser, len(authors))
+	for _, user := range authors {
+		usersByID[user.ID] = &domain.PostUser{
+			ID:           user.ID,
+			Username:     user.Username,
+			DisplayName:  user.DisplayName,
+			PhotoProfile: user.PhotoProfile,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+		}
+	}
+
+	results := make([]domain.SearchResult, 0, len(hits))
+	for i := range hits {
+		post := &hits[i].Post
+		results = append(results, domain.SearchResult{
+			Type:  domain.SearchTypePost,
+			ID:    post.ID.Hex(),
+			Score: hits[i].Score,
+			Data: &domain.PostWithDetails{
+				Post: post,
+				User: usersByID[post.UserID],
+			},
+		})
+	}
+	return results, nil
+}
+
+func (s *searchUseCase) CountResults(query string, searchType domain.SearchResultType) (int64, error) {
+	logger := utils.NewLogger("SearchUseCase.CountResults")
+	logger.LogInput(query, searchType)
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return 0, domain.ErrInvalidInput
+	}
+
+	var total int64
+	if searchType == domain.SearchTypeAll || searchType == domain.SearchTypePost {
+		count, err := s.searchRepo.CountPosts(query)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+		total += count
+	}
+	if searchType == domain.SearchTypeAll || searchType == domain.SearchTypeUser {
+		count, err := s.searchRepo.CountUsers(query)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+		contacts, err := s.findUsersByContact(query)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+		total += count + int64(len(contacts))
+	}
+
+	// Results past the search window can't be paged to
+	if total > domain.MaxSearchWindow {
+		total = domain.MaxSearchWindow
+	}
+
+	logger.LogOutput(total, nil)
+	return total, nil
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+)
+
+type serviceAccountUseCase struct {
+	accounts    map[string]domain.ServiceAccount
+	jwtKeys     *domain.JWTKeySet
+	tokenPolicy domain.TokenPolicy
+	tokenExpiry time.Duration
+}
+
+func NewServiceAccountUseCase(
+	accounts []domain.ServiceAccount,
+	jwtKeys *domain.JWTKeySet,
+	tokenPolicy domain.TokenPolicy,
+	tokenExpiry time.Duration,
+) domain.ServiceAccountUseCase {
+	byClientID := make(map[string]domain.ServiceAccount, len(accounts))
+	for _, account := range accounts {
+		byClientID[account.ClientID] = account
+	}
+
+	return &serviceAccountUseCase{
+		accounts:    byClientID,
+		jwtKeys:     jwtKeys,
+		tokenPolicy: tokenPolicy,
+		tokenExpiry: tokenExpiry,
+	}
+}
+
+// IssueToken exchanges client credentials for a short-lived machine token.
+// When no scopes are requested the token carries every scope granted to the account.
+func (u *serviceAccountUseCase) IssueToken(ctx context.Context, clientID, clientSecret string, scopes []string) (*domain.ServiceToken, error) {
+	logger := utils.NewLogger("ServiceAccountUseCase.IssueToken")
+	logger.LogInput(map[string]interface{}{
+		"clientId": clientID,
+		"scopes":   scopes,
+	})
+
+	account, ok := u.accounts[clientID]
+	if !ok || !verifyClientSecret(account.SecretHash, clientSecret) {
+		err := errors.New("invalid client credentials")
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if len(scopes) == 0 {
+		scopes = account.Scopes
+	}
+	for _, scope := range scopes {
+		if !account.HasScope(scope) {
+			err := errors.New("scope not allowed: " + scope)
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	claims := domain.ServiceClaims{
+		ClientID:         account.ClientID,
+		Scopes:           scopes,
+		Type:             domain.TokenTypeService,
+		RegisteredClaims: u.tokenPolicy.RegisteredClaims(account.ClientID, u.tokenExpiry),
+	}
+
+	accessToken, err := u.jwtKeys.Sign(claims)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	token := &domain.ServiceToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(u.tokenExpiry.Seconds()),
+		Scopes:      scopes,
+	}
+
+	logger.LogOutput(map[string]interface{}{
+		"clientId":  account.ClientID,
+		"scopes":    scopes,
+		"expiresIn": token.ExpiresIn,
+	}, nil)
+	return token, nil
+}
+
+// VerifyToken parses a machine token and rejects user tokens
+func (u *serviceAccountUseCase) VerifyToken(tokenString string) (*domain.ServiceClaims, error) {
+	claims := &domain.ServiceClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, u.jwtKeys.Keyfunc, u.tokenPolicy.ParserOptions()...)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.Type != domain.TokenTypeService {
+		return nil, errors.New("not a service token")
+	}
+
+	return claims, nil
+}
+
+func verifyClientSecret(secretHash, clientSecret string) bool {
+	sum := sha256.Sum256([]byte(clientSecret))
+	actual := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(secretHash)) == 1
+}
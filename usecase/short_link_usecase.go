@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const shortLinkAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// shortLinkCodeAttempts is how many codes are tried before giving up on collisions
+const shortLinkCodeAttempts = 5
+
+type shortLinkUseCase struct {
+	linkRepo       domain.ShortLinkRepository
+	postRepo       domain.PostRepository
+	userRepo       domain.UserRepository
+	friendshipRepo domain.FriendshipRepository
+	followUseCase  domain.FollowUseCase
+	baseURL        string
+	permalinkBase  string
+	defaultTTL     time.Duration
+}
+
+// NewShortLinkUseCase serves links as baseURL/<code> and points them at
+// permalinkBase/p/<postId> and permalinkBase/u/<username>
+func NewShortLinkUseCase(
+	linkRepo domain.ShortLinkRepository,
+	postRepo domain.PostRepository,
+	userRepo domain.UserRepository,
+	friendshipRepo domain.FriendshipRepository,
+	followUseCase domain.FollowUseCase,
+	baseURL string,
+	permalinkBase string,
+	defaultTTL time.Duration,
+) domain.ShortLinkUseCase {
+	return &shortLinkUseCase{
+		linkRepo:       linkRepo,
+		postRepo:       postRepo,
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+		followUseCase:  followUseCase,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		permalinkBase:  strings.TrimSuffix(permalinkBase, "/"),
+		defaultTTL:     defaultTTL,
+	}
+}
+
+func (u *shortLinkUseCase) CreatePostLink(userID, postID primitive.ObjectID, req domain.ShortLinkRequest) (*domain.ShortLink, error) {
+	logger := utils.NewLogger("ShortLinkUseCase.CreatePostLink")
+	logger.LogInput(userID, postID, req)
+
+	post, err := u.postRepo.FindByID(postID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if post == nil {
+		err = domain.NewNotFoundError("post", postID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	ok, err := canViewPost(u.friendshipRepo, userID, post)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if !ok {
+		logger.LogOutput(nil, domain.ErrForbidden)
+		return nil, domain.ErrForbidden
+	}
+
+	link, err := u.createLink(userID, domain.ShortLinkPost, postID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(link, nil)
+	return link, nil
+}
+
+func (u *shortLinkUseCase) CreateProfileLink(userID, profileID primitive.ObjectID, req domain.ShortLinkRequest) (*domain.ShortLink, error) {
+	logger := utils.NewLogger("ShortLinkUseCase.CreateProfileLink")
+	logger.LogInput(userID, profileID, req)
+
+	profile, err := u.userRepo.FindByID(profileID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if profile == nil {
+		err = domain.NewNotFoundError("user", profileID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if userID != profileID {
+		blocked, err := isBlockedEitherWay(u.followUseCase, userID, profileID)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if blocked {
+			err = domain.NewNotFoundError("user", profileID.Hex())
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+	}
+
+	link, err := u.createLink(userID, domain.ShortLinkProfile, profileID, req)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(link, nil)
+	return link, nil
+}
+
+// createLink reuses the user's link to the target unless an expiry was asked for
+func (u *shortLinkUseCase) createLink(userID primitive.ObjectID, targetType string, targetID primitive.ObjectID, req domain.ShortLinkRequest) (*domain.ShortLink, error) {
+	ttl := u.defaultTTL
+	if req.ExpiresInHours != 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+		if ttl < 0 || ttl > domain.MaxShortLinkExpiry {
+			return nil, fmt.Errorf("%w: expiresInHours must be between 1 and %d", domain.ErrInvalidInput, int(domain.MaxShortLinkExpiry.Hours()))
+		}
+	} else {
+		existing, err := u.linkRepo.FindReusable(userID, targetType, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			existing.URL = u.baseURL + "/" + existing.Code
+			return existing, nil
+		}
+	}
+
+	link := &domain.ShortLink{
+		TargetType: targetType,
+		TargetID:   targetID,
+		CreatedBy:  userID,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		link.ExpiresAt = &expiresAt
+	}
+
+	for attempt := 0; ; attempt++ {
+		code, err := generateShortLinkCode()
+		if err != nil {
+			return nil, err
+		}
+		link.Code = code
+
+		err = u.linkRepo.Create(link)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, domain.ErrDuplicate) || attempt+1 == shortLinkCodeAttempts {
+			return nil, err
+		}
+	}
+
+	link.URL = u.baseURL + "/" + link.Code
+	return link, nil
+}
+
+func (u *shortLinkUseCase) Open(code string) (*domain.ShortLink, error) {
+	logger := utils.NewLogger("ShortLinkUseCase.Open")
+	logger.LogInput(code)
+
+	link, err := u.linkRepo.FindByCode(code)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if link.IsExpired(time.Now()) {
+		logger.LogOutput(nil, domain.ErrShortLinkExpired)
+		return nil, domain.ErrShortLinkExpired
+	}
+
+	switch link.TargetType {
+	case domain.ShortLinkPost:
+		link.TargetURL = u.permalinkBase + "/p/" + link.TargetID.Hex()
+	case domain.ShortLinkProfile:
+		// Profile links follow username changes
+		user, err := u.userRepo.FindByID(link.TargetID.Hex())
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if user == nil || user.Username == "" {
+			err = domain.NewNotFoundError("user", link.TargetID.Hex())
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		link.TargetURL = u.permalinkBase + "/u/" + user.Username
+	}
+
+	// A lost click isn't worth failing the redirect for
+	if err := u.linkRepo.IncrementClicks(code); err != nil {
+		logger.LogOutput(nil, err)
+	} else {
+		link.Clicks++
+	}
+	link.URL = u.baseURL + "/" + link.Code
+
+	logger.LogOutput(link, nil)
+	return link, nil
+}
+
+func generateShortLinkCode() (string, error) {
+	max := big.NewInt(int64(len(shortLinkAlphabet)))
+	code := make([]byte, domain.ShortLinkCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shortLinkAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type storyInsightsUseCase struct {
+	interactionRepo domain.StoryInteractionRepository
+	storyRepo       domain.StoryRepository
+}
+
+func NewStoryInsightsUseCase(interactionRepo domain.StoryInteractionRepository, storyRepo domain.StoryRepository) domain.StoryInsightsUseCase {
+	return &storyInsightsUseCase{
+		interactionRepo: interactionRepo,
+		storyRepo:       storyRepo,
+	}
+}
+
+// RecordInteractions skips events on stories that are gone, since apps send
+// batches late and a story may have expired in the meantime
+func (u *storyInsightsUseCase) RecordInteractions(viewerID string, events []domain.StoryInteractionEvent) (int, error) {
+	logger := utils.NewLogger("StoryInsightsUseCase.RecordInteractions")
+	logger.LogInput(viewerID, len(events))
+
+	if len(events) > domain.MaxStoryInteractionBatchSize {
+		err := fmt.Errorf("%w: at most %d events per batch", domain.ErrInvalidInput, domain.MaxStoryInteractionBatchSize)
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+	for i, event := range events {
+		if !domain.IsStoryInteraction(event.Type) {
+			err := fmt.Errorf("%w: event %d has an unknown type %q", domain.ErrInvalidInput, i, event.Type)
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+		if !primitive.IsValidObjectID(event.StoryID) {
+			err := fmt.Errorf("%w: event %d has an invalid story ID", domain.ErrInvalidInput, i)
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+		if event.Position < 0 {
+			err := fmt.Errorf("%w: event %d has a negative position", domain.ErrInvalidInput, i)
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+	}
+
+	stories := make(map[string]*domain.Story)
+	records := make([]domain.StoryInteractionRecord, 0, len(events))
+	for _, event := range events {
+		story, loaded := stories[event.StoryID]
+		if !loaded {
+			var err error
+			story, err = u.storyRepo.FindByID(event.StoryID)
+			if err != nil {
+				logger.LogOutput(nil, err)
+				return 0, err
+			}
+			stories[event.StoryID] = story
+		}
+		if story == nil || story.UserID == viewerID {
+			continue
+		}
+
+		unique, err := u.interactionRepo.MarkInteracted(event.StoryID, viewerID, event.Type)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return 0, err
+		}
+		second := int(event.Position)
+		if length := storyLength(story); second > length {
+			second = length
+		}
+		records = append(records, domain.StoryInteractionRecord{
+			StoryID: event.StoryID,
+			OwnerID: story.UserID,
+			Type:    event.Type,
+			Second:  second,
+			Unique:  unique,
+		})
+	}
+
+	if err := u.interactionRepo.Record(records); err != nil {
+		logger.LogOutput(nil, err)
+		return 0, err
+	}
+
+	logger.LogOutput(len(records), nil)
+	return len(records), nil
+}
+
+func (u *storyInsightsUseCase) GetInsights(storyID, userID string) (*domain.StoryInsights, error) {
+	logger := utils.NewLogger("StoryInsightsUseCase.GetInsights")
+	logger.LogInput(storyID, userID)
+
+	story, err := u.storyRepo.FindAnyByID(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if story.UserID != userID {
+		err := fmt.Errorf("%w: only the author can see a story's insights", domain.ErrForbidden)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	stats, err := u.interactionRepo.FindStats(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if stats == nil {
+		stats = &domain.StoryInteractionStats{StoryID: storyID}
+	}
+
+	viewers := int64(story.ViewersCount)
+	insights := &domain.StoryInsights{
+		StoryID:     storyID,
+		Viewers:     viewers,
+		Reactions:   int64(story.ReactionsCount),
+		TapsForward: stats.Counts[domain.StoryInteractionTapForward],
+		TapsBack:    stats.Counts[domain.StoryInteractionTapBack],
+		Exits:       stats.Counts[domain.StoryInteractionExit],
+		SwipeUps:    stats.Counts[domain.StoryInteractionSwipeUp],
+		Retention:   make([]domain.StoryRetentionPoint, 0),
+	}
+	if viewers == 0 {
+		logger.LogOutput(insights, nil)
+		return insights, nil
+	}
+
+	forwarded := stats.Viewers[domain.StoryInteractionTapForward]
+	exited := stats.Viewers[domain.StoryInteractionExit]
+	insights.ForwardRate = storyRate(forwarded, viewers)
+	insights.ExitRate = storyRate(exited, viewers)
+	insights.SwipeUpRate = storyRate(stats.Viewers[domain.StoryInteractionSwipeUp], viewers)
+	insights.CompletionRate = storyRate(viewers-forwarded-exited, viewers)
+
+	// Everyone watches second 0, then the viewers who left at a second are gone from the next
+	watching := viewers
+	for second := 0; second <= storyLength(story); second++ {
+		insights.Retention = append(insights.Retention, domain.StoryRetentionPoint{
+			Second:  second,
+			Viewers: watching,
+			Rate:    storyRate(watching, viewers),
+		})
+		watching -= stats.DropOffs[strconv.Itoa(second)]
+		if watching < 0 {
+			watching = 0
+		}
+	}
+
+	logger.LogOutput(insights, nil)
+	return insights, nil
+}
+
+// storyLength is how many seconds the story plays, capped for the retention curve
+func storyLength(story *domain.Story) int {
+	if story.Media.Type != domain.Video || story.Media.Duration <= 0 {
+		return domain.StoryImageSeconds
+	}
+	if story.Media.Duration > domain.MaxStoryRetentionSecond {
+		return domain.MaxStoryRetentionSecond
+	}
+	return story.Media.Duration
+}
+
+// storyRate is count as a fraction of the viewers, kept between 0 and 1 since
+// interactions and views are counted separately
+func storyRate(count, viewers int64) float64 {
+	rate := float64(count) / float64(viewers)
+	if rate < 0 {
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
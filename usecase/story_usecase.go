@@ -1,22 +1,42 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// A viewer counts as watching if a heartbeat arrived within this window
+	storyWatcherWindow = 30 * time.Second
+	// Live viewer counts are pushed to the owner at most once per interval
+	storyWatcherBroadcastInterval = 2 * time.Second
 )
 
 type storyUseCase struct {
-	storyRepo domain.StoryRepository
-	userRepo  domain.UserRepository
+	storyRepo           domain.StoryRepository
+	userRepo            domain.UserRepository
+	fileRepo            domain.FileRepository
+	realtime            domain.RealtimePublisher
+	chatUseCase         domain.ChatUsecase
+	notificationUseCase domain.NotificationUseCase
+	followUseCase       domain.FollowUseCase
 }
 
-func NewStoryUseCase(storyRepo domain.StoryRepository, userRepo domain.UserRepository) domain.StoryUseCase {
+func NewStoryUseCase(storyRepo domain.StoryRepository, userRepo domain.UserRepository, fileRepo domain.FileRepository, realtime domain.RealtimePublisher, chatUseCase domain.ChatUsecase, notificationUseCase domain.NotificationUseCase, followUseCase domain.FollowUseCase) domain.StoryUseCase {
 	return &storyUseCase{
-		storyRepo: storyRepo,
-		userRepo:  userRepo,
+		storyRepo:           storyRepo,
+		userRepo:            userRepo,
+		fileRepo:            fileRepo,
+		realtime:            realtime,
+		chatUseCase:         chatUseCase,
+		notificationUseCase: notificationUseCase,
+		followUseCase:       followUseCase,
 	}
 }
 
@@ -35,6 +55,11 @@ func (u *storyUseCase) CreateStory(story *domain.Story) error {
 		logger.LogOutput(nil, err)
 		return err
 	}
+	if user.Suspension.IsActive(time.Now()) {
+		err = &domain.SuspensionError{Suspension: user.Suspension}
+		logger.LogOutput(nil, err)
+		return err
+	}
 
 	// Validate media
 	if story.Media.URL == "" {
@@ -49,6 +74,14 @@ func (u *storyUseCase) CreateStory(story *domain.Story) error {
 		return err
 	}
 
+	// Validate audio track against the uploaded audio file
+	if story.Audio != nil {
+		if err = u.validateAudio(story); err != nil {
+			logger.LogOutput(nil, err)
+			return err
+		}
+	}
+
 	// Create story
 	err = u.storyRepo.Create(story)
 	if err != nil {
@@ -60,9 +93,43 @@ func (u *storyUseCase) CreateStory(story *domain.Story) error {
 	return nil
 }
 
-func (u *storyUseCase) GetStoryByID(id string) (*domain.StoryResponse, error) {
+// validateAudio checks the trim window of the story audio track against the stored audio file
+func (u *storyUseCase) validateAudio(story *domain.Story) error {
+	audio := story.Audio
+	if audio.URL == "" {
+		return fmt.Errorf("%w: audio URL is required", domain.ErrInvalidStoryAudio)
+	}
+	if audio.StartOffset < 0 {
+		return fmt.Errorf("%w: start offset must not be negative", domain.ErrInvalidStoryAudio)
+	}
+	if audio.Duration <= 0 || audio.Duration > domain.MaxStoryAudioDuration {
+		return fmt.Errorf("%w: duration must be between 0 and %d seconds", domain.ErrInvalidStoryAudio, domain.MaxStoryAudioDuration)
+	}
+	if story.Media.Type == domain.Video && story.Media.Duration > 0 && audio.Duration > float64(story.Media.Duration) {
+		return fmt.Errorf("%w: duration must not exceed the video duration", domain.ErrInvalidStoryAudio)
+	}
+
+	file, err := u.fileRepo.FindByURL(audio.URL)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: audio file not found", domain.ErrInvalidStoryAudio)
+		}
+		return err
+	}
+	if !strings.HasPrefix(file.ContentType, "audio/") {
+		return fmt.Errorf("%w: file is not an audio file", domain.ErrInvalidStoryAudio)
+	}
+	if file.Duration > 0 && audio.StartOffset+audio.Duration > file.Duration {
+		return fmt.Errorf("%w: trim range exceeds the audio length", domain.ErrInvalidStoryAudio)
+	}
+
+	return nil
+}
+
+// GetStoryByID hides the story when the viewer and its owner blocked one another
+func (u *storyUseCase) GetStoryByID(id string, viewerID string) (*domain.StoryResponse, error) {
 	logger := utils.NewLogger("StoryUseCase.GetStoryByID")
-	logger.LogInput(id)
+	logger.LogInput(id, viewerID)
 
 	story, err := u.storyRepo.FindByID(id)
 	if err != nil {
@@ -76,6 +143,17 @@ func (u *storyUseCase) GetStoryByID(id string) (*domain.StoryResponse, error) {
 		return nil, err
 	}
 
+	blocked, err := u.isBlocked(story.UserID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if blocked {
+		err = domain.NewNotFoundError("story", id)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	// Get user information
 	user, err := u.userRepo.FindByID(story.UserID)
 	if err != nil {
@@ -97,9 +175,10 @@ func (u *storyUseCase) GetStoryByID(id string) (*domain.StoryResponse, error) {
 	return response, nil
 }
 
-func (u *storyUseCase) GetUserStories(userID string) ([]*domain.StoryResponse, error) {
+// GetUserStories returns no stories when the viewer and the user blocked one another
+func (u *storyUseCase) GetUserStories(userID string, viewerID string) ([]*domain.StoryResponse, error) {
 	logger := utils.NewLogger("StoryUseCase.GetUserStories")
-	logger.LogInput(userID)
+	logger.LogInput(userID, viewerID)
 
 	// Validate user exists
 	user, err := u.userRepo.FindByID(userID)
@@ -113,6 +192,16 @@ func (u *storyUseCase) GetUserStories(userID string) ([]*domain.StoryResponse, e
 		return nil, err
 	}
 
+	blocked, err := u.isBlocked(userID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if blocked {
+		logger.LogOutput(nil, nil)
+		return []*domain.StoryResponse{}, nil
+	}
+
 	stories, err := u.storyRepo.FindByUserID(userID)
 	if err != nil {
 		logger.LogOutput(nil, err)
@@ -137,8 +226,21 @@ func (u *storyUseCase) GetUserStories(userID string) ([]*domain.StoryResponse, e
 	return responses, nil
 }
 
-func (u *storyUseCase) GetActiveStories() ([]*domain.StoryResponse, error) {
+// GetActiveStories leaves out the stories of users the viewer blocked or who blocked the viewer
+func (u *storyUseCase) GetActiveStories(viewerID string) ([]*domain.StoryResponse, error) {
 	logger := utils.NewLogger("StoryUseCase.GetActiveStories")
+	logger.LogInput(viewerID)
+
+	viewerObjectID, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		logger.LogOutput(nil, domain.ErrInvalidID)
+		return nil, domain.ErrInvalidID
+	}
+	blocked, err := blockedSet(u.followUseCase, viewerObjectID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
 
 	stories, err := u.storyRepo.FindActiveStories()
 	if err != nil {
@@ -148,6 +250,9 @@ func (u *storyUseCase) GetActiveStories() ([]*domain.StoryResponse, error) {
 
 	var responses []*domain.StoryResponse
 	for _, story := range stories {
+		if ownerID, err := primitive.ObjectIDFromHex(story.UserID); err == nil && blocked[ownerID] {
+			continue
+		}
 		user, err := u.userRepo.FindByID(story.UserID)
 		if err != nil {
 			logger.LogOutput(nil, err)
@@ -190,6 +295,17 @@ func (u *storyUseCase) ViewStory(storyID string, viewerID string) error {
 		return err
 	}
 
+	blocked, err := u.isBlocked(story.UserID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if blocked {
+		err = domain.NewNotFoundError("story", storyID)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
 	// Validate viewer exists
 	viewer, err := u.userRepo.FindByID(viewerID)
 	if err != nil {
@@ -234,6 +350,203 @@ func (u *storyUseCase) ViewStory(storyID string, viewerID string) error {
 	return nil
 }
 
+// ReplyToStory sends the reply as a chat message to the story owner, with a
+// snapshot of the story so the reply still makes sense once the story expires
+func (u *storyUseCase) ReplyToStory(storyID string, viewerID string, content string) (*domain.ChatMessage, error) {
+	logger := utils.NewLogger("StoryUseCase.ReplyToStory")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+		"content":  content,
+	})
+
+	if !primitive.IsValidObjectID(storyID) {
+		logger.LogOutput(nil, domain.ErrInvalidID)
+		return nil, domain.ErrInvalidID
+	}
+
+	// Expired stories are not found either
+	story, err := u.storyRepo.FindByID(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if story == nil {
+		err = domain.NewNotFoundError("story", storyID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if story.UserID == viewerID {
+		err = fmt.Errorf("%w: you can't reply to your own story", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	blocked, err := u.isBlocked(story.UserID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if blocked {
+		err = domain.NewNotFoundError("story", storyID)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	message, err := u.chatUseCase.SendStoryReply(viewerID, domain.ChatStoryRef{
+		StoryID:   story.ID.Hex(),
+		OwnerID:   story.UserID,
+		MediaURL:  story.Media.URL,
+		MediaType: story.Media.Type,
+		Thumbnail: story.Media.Thumbnail,
+		Caption:   story.Caption,
+		ExpiresAt: story.ExpiresAt,
+	}, content)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(message, nil)
+	return message, nil
+}
+
+// ReactToStory sets the viewer's quick reaction. The owner is notified of new
+// reactions only, not when a viewer changes their emoji.
+func (u *storyUseCase) ReactToStory(storyID string, viewerID string, emoji string) error {
+	logger := utils.NewLogger("StoryUseCase.ReactToStory")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+		"emoji":    emoji,
+	})
+
+	if !domain.StoryReactionEmojis[emoji] {
+		err := fmt.Errorf("%w: unsupported reaction", domain.ErrInvalidInput)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	story, err := u.getReactableStory(storyID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	added, err := u.storyRepo.SetReaction(storyID, domain.StoryReaction{
+		UserID:    viewerID,
+		Emoji:     emoji,
+		ReactedAt: time.Now(),
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if added {
+		u.notifyReaction(story, viewerID, emoji)
+	}
+
+	logger.LogOutput(added, nil)
+	return nil
+}
+
+func (u *storyUseCase) RemoveStoryReaction(storyID string, viewerID string) error {
+	logger := utils.NewLogger("StoryUseCase.RemoveStoryReaction")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+	})
+
+	if _, err := u.getReactableStory(storyID, viewerID); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	removed, err := u.storyRepo.RemoveReaction(storyID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if !removed {
+		err = domain.NewNotFoundError("story reaction", storyID)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+// getReactableStory loads a live story of another user who the viewer didn't
+// block and wasn't blocked by
+func (u *storyUseCase) getReactableStory(storyID, viewerID string) (*domain.Story, error) {
+	if !primitive.IsValidObjectID(storyID) {
+		return nil, domain.ErrInvalidID
+	}
+	story, err := u.storyRepo.FindByID(storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story == nil {
+		return nil, domain.NewNotFoundError("story", storyID)
+	}
+	if story.UserID == viewerID {
+		return nil, fmt.Errorf("%w: you can't react to your own story", domain.ErrInvalidInput)
+	}
+	blocked, err := u.isBlocked(story.UserID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, domain.NewNotFoundError("story", storyID)
+	}
+	return story, nil
+}
+
+// isBlocked reports whether the story owner and the viewer blocked one another
+func (u *storyUseCase) isBlocked(ownerID, viewerID string) (bool, error) {
+	if ownerID == viewerID {
+		return false, nil
+	}
+	owner, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return false, domain.ErrInvalidID
+	}
+	viewer, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		return false, domain.ErrInvalidID
+	}
+	return isBlockedEitherWay(u.followUseCase, owner, viewer)
+}
+
+// notifyReaction tells the story owner about a new reaction. Delivery is best effort.
+func (u *storyUseCase) notifyReaction(story *domain.Story, viewerID, emoji string) {
+	logger := utils.NewLogger("StoryUseCase.notifyReaction")
+
+	ownerID, err := primitive.ObjectIDFromHex(story.UserID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+	senderID, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return
+	}
+
+	_, err = u.notificationUseCase.CreateNotification(
+		ownerID,
+		senderID,
+		story.ID,
+		domain.NotificationTypeLike,
+		"story",
+		"reacted "+emoji+" to your story",
+	)
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
+
 func (u *storyUseCase) DeleteStory(storyID string, userID string) error {
 	logger := utils.NewLogger("StoryUseCase.DeleteStory")
 	input := map[string]interface{}{
@@ -254,8 +567,7 @@ func (u *storyUseCase) DeleteStory(storyID string, userID string) error {
 		return err
 	}
 
-	if story.UserID != userID {
-		err = fmt.Errorf("unauthorized to delete this story")
+	if err = authorizeOwnerOrAdmin(u.userRepo, userID, story.UserID); err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
@@ -282,3 +594,103 @@ func (u *storyUseCase) ArchiveExpiredStories() error {
 	logger.LogOutput(nil, nil)
 	return nil
 }
+
+// WatchStory records a viewer heartbeat and pushes the live viewer count to the owner
+func (u *storyUseCase) WatchStory(storyID string, viewerID string) error {
+	logger := utils.NewLogger("StoryUseCase.WatchStory")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+	})
+
+	story, err := u.storyRepo.FindByID(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if story == nil || !story.IsActive || time.Now().After(story.ExpiresAt) {
+		err = fmt.Errorf("story not found")
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	// Owners previewing their own story are not counted
+	if story.UserID == viewerID {
+		logger.LogOutput(nil, nil)
+		return nil
+	}
+	blocked, err := u.isBlocked(story.UserID, viewerID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if blocked {
+		err = domain.NewNotFoundError("story", storyID)
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	watching, err := u.storyRepo.AddWatcher(storyID, viewerID, storyWatcherWindow)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	u.publishLiveViewers(story.UserID, storyID, watching)
+
+	logger.LogOutput(watching, nil)
+	return nil
+}
+
+// LeaveStory removes the viewer from the live viewer count
+func (u *storyUseCase) LeaveStory(storyID string, viewerID string) error {
+	logger := utils.NewLogger("StoryUseCase.LeaveStory")
+	logger.LogInput(map[string]interface{}{
+		"storyID":  storyID,
+		"viewerID": viewerID,
+	})
+
+	story, err := u.storyRepo.FindByID(storyID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if story == nil {
+		err = fmt.Errorf("story not found")
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	watching, err := u.storyRepo.RemoveWatcher(storyID, viewerID, storyWatcherWindow)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	u.publishLiveViewers(story.UserID, storyID, watching)
+
+	logger.LogOutput(watching, nil)
+	return nil
+}
+
+func (u *storyUseCase) publishLiveViewers(ownerID, storyID string, watching int64) {
+	logger := utils.NewLogger("StoryUseCase.publishLiveViewers")
+
+	if u.realtime == nil {
+		return
+	}
+
+	acquired, err := u.storyRepo.TryLockWatcherBroadcast(storyID, storyWatcherBroadcastInterval)
+	if err != nil || !acquired {
+		// Throttled, the next heartbeat will carry the latest count
+		return
+	}
+
+	err = u.realtime.SendToUser(ownerID, domain.RealtimeEventStoryViewers, domain.StoryLiveViewers{
+		StoryID:  storyID,
+		Watching: watching,
+	})
+	if err != nil {
+		logger.LogOutput(nil, err)
+	}
+}
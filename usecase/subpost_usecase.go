@@ -9,14 +9,26 @@ import (
 )
 
 type subPostUseCase struct {
-	subPostRepo domain.SubPostRepository
-	postRepo    domain.PostRepository
+	subPostRepo         domain.SubPostRepository
+	postRepo            domain.PostRepository
+	friendshipRepo      domain.FriendshipRepository
+	userRepo            domain.UserRepository
+	notificationUseCase domain.NotificationUseCase
 }
 
-func NewSubPostUseCase(subPostRepo domain.SubPostRepository, postRepo domain.PostRepository) domain.SubPostUseCase {
+func NewSubPostUseCase(
+	subPostRepo domain.SubPostRepository,
+	postRepo domain.PostRepository,
+	friendshipRepo domain.FriendshipRepository,
+	userRepo domain.UserRepository,
+	notificationUseCase domain.NotificationUseCase,
+) domain.SubPostUseCase {
 	return &subPostUseCase{
-		subPostRepo: subPostRepo,
-		postRepo:    postRepo,
+		subPostRepo:         subPostRepo,
+		postRepo:            postRepo,
+		friendshipRepo:      friendshipRepo,
+		userRepo:            userRepo,
+		notificationUseCase: notificationUseCase,
 	}
 }
 
@@ -38,14 +50,24 @@ func (s *subPostUseCase) CreateSubPost(parentID, userID primitive.ObjectID, cont
 		return nil, err
 	}
 
+	now := time.Now()
 	subPost := &domain.SubPost{
+		BaseModel: domain.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			IsActive:  true,
+			Version:   1,
+		},
 		ParentID:       parentID,
 		UserID:         userID,
 		Content:        content,
 		Media:          media,
+		Mentions:       resolveMentions(s.userRepo, content),
 		ReactionCounts: make(map[string]int),
 		CommentCount:   0,
 		Order:          order,
+		Visibility:     post.Visibility,
 	}
 
 	err = s.subPostRepo.Create(subPost)
@@ -62,6 +84,8 @@ func (s *subPostUseCase) CreateSubPost(parentID, userID primitive.ObjectID, cont
 		return nil, err
 	}
 
+	notifyMentions(s.notificationUseCase, userID, subPost.ID, "subpost", "mentioned you in a post", subPost.Mentions, nil)
+
 	logger.LogOutput(subPost, nil)
 	return subPost, nil
 }
@@ -81,8 +105,10 @@ func (s *subPostUseCase) UpdateSubPost(subPostID primitive.ObjectID, content str
 		return nil, err
 	}
 
+	previousMentions := subPost.Mentions
 	subPost.Content = content
 	subPost.Media = media
+	subPost.Mentions = resolveMentions(s.userRepo, content)
 	subPost.UpdatedAt = time.Now()
 
 	err = s.subPostRepo.Update(subPost)
@@ -91,6 +117,9 @@ func (s *subPostUseCase) UpdateSubPost(subPostID primitive.ObjectID, content str
 		return nil, err
 	}
 
+	// Only users mentioned by this edit are notified
+	notifyMentions(s.notificationUseCase, subPost.UserID, subPost.ID, "subpost", "mentioned you in a post", subPost.Mentions, previousMentions)
+
 	logger.LogOutput(subPost, nil)
 	return subPost, nil
 }
@@ -133,9 +162,12 @@ func (s *subPostUseCase) DeleteSubPost(subPostID primitive.ObjectID) error {
 	return nil
 }
 
-func (s *subPostUseCase) GetSubPost(subPostID primitive.ObjectID) (*domain.SubPost, error) {
+func (s *subPostUseCase) GetSubPost(viewerID, subPostID primitive.ObjectID) (*domain.SubPost, error) {
 	logger := utils.NewLogger("SubPostUseCase.GetSubPost")
-	logger.LogInput(subPostID)
+	logger.LogInput(map[string]interface{}{
+		"viewerID":  viewerID,
+		"subPostID": subPostID,
+	})
 
 	subPost, err := s.subPostRepo.FindByID(subPostID)
 	if err != nil {
@@ -143,19 +175,34 @@ func (s *subPostUseCase) GetSubPost(subPostID primitive.ObjectID) (*domain.SubPo
 		return nil, err
 	}
 
+	// Subposts are only visible to those who can see the parent post
+	if _, err := s.findVisibleParent(viewerID, subPost.ParentID); err != nil {
+		if domain.IsNotFoundError(err) {
+			err = domain.NewNotFoundError("subpost", subPostID.Hex())
+		}
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	logger.LogOutput(subPost, nil)
 	return subPost, nil
 }
 
-func (s *subPostUseCase) ListSubPosts(parentID primitive.ObjectID, limit, offset int) ([]domain.SubPost, error) {
+func (s *subPostUseCase) ListSubPosts(viewerID, parentID primitive.ObjectID, limit, offset int) ([]domain.SubPost, error) {
 	logger := utils.NewLogger("SubPostUseCase.ListSubPosts")
 	input := map[string]interface{}{
+		"viewerID": viewerID,
 		"parentID": parentID,
 		"limit":    limit,
 		"offset":   offset,
 	}
 	logger.LogInput(input)
 
+	if _, err := s.findVisibleParent(viewerID, parentID); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
 	subPosts, err := s.subPostRepo.FindByParentID(parentID, limit, offset)
 	if err != nil {
 		logger.LogOutput(nil, err)
@@ -166,6 +213,23 @@ func (s *subPostUseCase) ListSubPosts(parentID primitive.ObjectID, limit, offset
 	return subPosts, nil
 }
 
+// findVisibleParent loads the parent post, reporting posts hidden from the viewer as not found
+func (s *subPostUseCase) findVisibleParent(viewerID, parentID primitive.ObjectID) (*domain.Post, error) {
+	post, err := s.postRepo.FindByID(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := canViewPost(s.friendshipRepo, viewerID, post)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, domain.NewNotFoundError("post", parentID.Hex())
+	}
+	return post, nil
+}
+
 func (s *subPostUseCase) ReorderSubPosts(parentID primitive.ObjectID, orders map[primitive.ObjectID]int) error {
 	logger := utils.NewLogger("SubPostUseCase.ReorderSubPosts")
 	input := map[string]interface{}{
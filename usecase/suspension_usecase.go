@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Users handled per suspension processing run, the rest wait for the next run
+const suspensionBatchSize = 500
+
+type suspensionUseCase struct {
+	userRepo            domain.UserRepository
+	auditLogRepo        domain.AuditLogRepository
+	notificationUseCase domain.NotificationUseCase
+}
+
+func NewSuspensionUseCase(userRepo domain.UserRepository, auditLogRepo domain.AuditLogRepository, notificationUseCase domain.NotificationUseCase) domain.SuspensionUseCase {
+	return &suspensionUseCase{
+		userRepo:            userRepo,
+		auditLogRepo:        auditLogRepo,
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+func (u *suspensionUseCase) Suspend(adminID, userID primitive.ObjectID, req domain.SuspendRequest) (*domain.Suspension, error) {
+	logger := utils.NewLogger("SuspensionUseCase.Suspend")
+	logger.LogInput(map[string]interface{}{
+		"adminID": adminID.Hex(),
+		"userID":  userID.Hex(),
+		"request": req,
+	})
+
+	now := time.Now()
+	until := now.Add(time.Duration(req.DurationHours) * time.Hour)
+	if req.Until != nil {
+		until = *req.Until
+	}
+	if err := validateSuspension(req.Type, now, until); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil {
+		err = domain.NewNotFoundError("user", userID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user.IsAdmin() {
+		err = fmt.Errorf("%w: admins can't be suspended", domain.ErrForbidden)
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	suspension := &domain.Suspension{
+		Type:      req.Type,
+		Reason:    strings.TrimSpace(req.Reason),
+		Until:     until.UTC().Truncate(time.Millisecond), // stored with millisecond precision
+		CreatedBy: adminID,
+		CreatedAt: now,
+	}
+	if err := u.userRepo.SetSuspension(userID, suspension); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	u.recordAudit(adminID, domain.AuditActionSuspend, userID, map[string]interface{}{
+		"type":   suspension.Type,
+		"reason": suspension.Reason,
+		"until":  suspension.Until,
+	})
+	u.notify(userID, suspensionStartedMessage(suspension))
+
+	logger.LogOutput(suspension, nil)
+	return suspension, nil
+}
+
+func (u *suspensionUseCase) Lift(adminID, userID primitive.ObjectID) error {
+	logger := utils.NewLogger("SuspensionUseCase.Lift")
+	logger.LogInput(adminID.Hex(), userID.Hex())
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if user == nil || user.Suspension == nil {
+		err = domain.NewNotFoundError("suspension", userID.Hex())
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	if err := u.userRepo.SetSuspension(userID, nil); err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	u.recordAudit(adminID, domain.AuditActionLift, userID, map[string]interface{}{
+		"type":  user.Suspension.Type,
+		"until": user.Suspension.Until,
+	})
+	u.notify(userID, suspensionLiftedMessage(user.Suspension))
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (u *suspensionUseCase) GetActiveSuspension(userID string) (*domain.Suspension, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.Suspension.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return user.Suspension, nil
+}
+
+// ProcessSuspensions runs from the scheduled job. Users are warned once when their
+// suspension ends within SuspensionEndingNotice, and told again once it is lifted.
+func (u *suspensionUseCase) ProcessSuspensions() (*domain.SuspensionRunResult, error) {
+	logger := utils.NewLogger("SuspensionUseCase.ProcessSuspensions")
+
+	now := time.Now()
+	result := &domain.SuspensionRunResult{}
+
+	ending, err := u.userRepo.FindSuspendedUntil(now.Add(domain.SuspensionEndingNotice), true, suspensionBatchSize)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	for _, user := range ending {
+		// Suspensions that already ended only get the lifted notification
+		if !user.Suspension.IsActive(now) {
+			continue
+		}
+		if err := u.userRepo.MarkSuspensionEndingNotified(user.ID, user.Suspension.Until); err != nil {
+			logger.LogOutput(nil, err)
+			return result, err
+		}
+		u.notify(user.ID, suspensionEndingMessage(user.Suspension))
+		result.Notified++
+	}
+
+	expired, err := u.userRepo.FindSuspendedUntil(now, false, suspensionBatchSize)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return result, err
+	}
+	for _, user := range expired {
+		lifted, err := u.userRepo.LiftExpiredSuspension(user.ID, user.Suspension.Until)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return result, err
+		}
+		if !lifted {
+			continue
+		}
+		u.notify(user.ID, suspensionLiftedMessage(user.Suspension))
+		result.Lifted++
+	}
+
+	logger.LogOutput(result, nil)
+	return result, nil
+}
+
+// notify sends a system notification about the user's own account. Delivery is best effort.
+func (u *suspensionUseCase) notify(userID primitive.ObjectID, message string) {
+	_, err := u.notificationUseCase.CreateNotification(userID, primitive.NilObjectID, userID, domain.NotificationTypeAccount, "user", message)
+	if err != nil {
+		utils.NewLogger("SuspensionUseCase.notify").LogOutput(nil, err)
+	}
+}
+
+// recordAudit logs an admin action that has already happened, so a failure only gets logged
+func (u *suspensionUseCase) recordAudit(adminID primitive.ObjectID, action string, userID primitive.ObjectID, metadata map[string]interface{}) {
+	err := u.auditLogRepo.Create(&domain.AuditLog{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: string(domain.DeletedContentUser),
+		TargetID:   userID.Hex(),
+		Metadata:   metadata,
+	})
+	if err != nil {
+		utils.NewLogger("SuspensionUseCase.recordAudit").LogOutput(nil, err)
+	}
+}
+
+func validateSuspension(suspensionType string, now, until time.Time) error {
+	if suspensionType != domain.SuspensionBan && suspensionType != domain.SuspensionMute {
+		return fmt.Errorf("%w: type must be ban or mute", domain.ErrInvalidInput)
+	}
+	if !until.After(now) {
+		return fmt.Errorf("%w: the suspension must end in the future", domain.ErrInvalidInput)
+	}
+	if until.Sub(now) > domain.MaxSuspensionDuration {
+		return fmt.Errorf("%w: suspensions last at most %d days", domain.ErrInvalidInput, int(domain.MaxSuspensionDuration.Hours()/24))
+	}
+	return nil
+}
+
+func suspensionNoun(suspension *domain.Suspension) string {
+	if suspension.Type == domain.SuspensionMute {
+		return "mute"
+	}
+	return "suspension"
+}
+
+func suspensionStartedMessage(suspension *domain.Suspension) string {
+	message := fmt.Sprintf("Your account is suspended until %s", suspension.Until.Format(time.RFC1123))
+	if suspension.Type == domain.SuspensionMute {
+		message = fmt.Sprintf("You can't post, comment or send messages until %s", suspension.Until.Format(time.RFC1123))
+	}
+	if suspension.Reason != "" {
+		message += ". Reason: " + suspension.Reason
+	}
+	return message
+}
+
+func suspensionEndingMessage(suspension *domain.Suspension) string {
+	return fmt.Sprintf("Your account %s ends on %s", suspensionNoun(suspension), suspension.Until.Format(time.RFC1123))
+}
+
+func suspensionLiftedMessage(suspension *domain.Suspension) string {
+	return fmt.Sprintf("Your account %s has been lifted", suspensionNoun(suspension))
+}
@@ -1,22 +1,30 @@
 package usecase
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"firebase.google.com/go/v4/auth"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type userUseCase struct {
-	userRepo domain.UserRepository
+	userRepo       domain.UserRepository
+	changeRepo     domain.ProfileChangeRepository
+	followRepo     domain.FollowRepository
+	friendshipRepo domain.FriendshipRepository
+	events         domain.DomainEventPublisher
 }
 
-func NewUserUseCase(userRepo domain.UserRepository) domain.UserUseCase {
+func NewUserUseCase(userRepo domain.UserRepository, changeRepo domain.ProfileChangeRepository, followRepo domain.FollowRepository, friendshipRepo domain.FriendshipRepository, events domain.DomainEventPublisher) domain.UserUseCase {
 	return &userUseCase{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		changeRepo:     changeRepo,
+		followRepo:     followRepo,
+		friendshipRepo: friendshipRepo,
+		events:         events,
 	}
 }
 
@@ -54,6 +62,12 @@ func (u *userUseCase) CreateOrUpdateUser(firebaseUID, email, firstName, lastName
 			return nil, err
 		}
 
+		u.events.Publish(domain.DomainEventUserRegistered, domain.UserRegisteredPayload{
+			UserID:       user.ID,
+			Provider:     user.Provider,
+			RegisteredAt: user.CreatedAt,
+		})
+
 		logger.LogOutput(user, nil)
 		return user, nil
 	}
@@ -132,52 +146,91 @@ func (u *userUseCase) GetUserByUsername(username string) (*domain.User, error) {
 	return user, nil
 }
 
-func (u *userUseCase) UpdateUser(user *domain.User) error {
-	logger := utils.NewLogger("UserUseCase.UpdateUser")
-	logger.LogInput(user)
+func (u *userUseCase) GetProfileByUsername(viewerID primitive.ObjectID, username string) (*domain.ProfileView, error) {
+	logger := utils.NewLogger("UserUseCase.GetProfileByUsername")
+	logger.LogInput(viewerID, username)
 
-	err := u.userRepo.Update(user)
+	user, err := u.userRepo.FindByUsername(username)
 	if err != nil {
 		logger.LogOutput(nil, err)
-		return err
+		return nil, err
+	}
+	if user == nil {
+		err := domain.NewNotFoundError("user", username)
+		logger.LogOutput(nil, err)
+		return nil, err
 	}
 
-	logger.LogOutput(user, nil)
-	return nil
+	relationship, err := u.profileRelationship(viewerID, user.ID)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	view := domain.NewProfileView(user, relationship)
+	logger.LogOutput(map[string]interface{}{"userID": view.ID, "relationship": relationship}, nil)
+	return view, nil
 }
 
-func (u *userUseCase) DeleteAccount(userID string, authClient interface{}) error {
-	logger := utils.NewLogger("UserUseCase.DeleteAccount")
-	logger.LogInput(userID)
+// profileRelationship works out how the viewer relates to the owner. A block
+// either way outweighs a friendship or a follow.
+func (u *userUseCase) profileRelationship(viewerID, ownerID primitive.ObjectID) (string, error) {
+	if viewerID == ownerID {
+		return domain.ProfileRelationSelf, nil
+	}
 
-	// Get user to get Firebase UID
-	user, err := u.userRepo.FindByID(userID)
-	if err != nil {
-		logger.LogOutput(nil, err)
-		return err
+	// A block is kept as the blocked user's follow of the blocker, marked blocked
+	viewerFollow, err := u.followRepo.FindByFollowerAndFollowing(viewerID, ownerID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return "", err
 	}
-	if user == nil {
-		err = fmt.Errorf("user not found")
-		logger.LogOutput(nil, err)
-		return err
+	ownerFollow, err := u.followRepo.FindByFollowerAndFollowing(ownerID, viewerID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return "", err
+	}
+	if (viewerFollow != nil && viewerFollow.Status == "blocked") || (ownerFollow != nil && ownerFollow.Status == "blocked") {
+		return domain.ProfileRelationBlocked, nil
 	}
 
-	// Delete user from Firebase
-	firebaseAuth := authClient.(*auth.Client)
-	err = firebaseAuth.DeleteUser(context.Background(), user.FirebaseUID)
+	friendship, err := u.friendshipRepo.FindByUsers(viewerID, ownerID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return "", err
+	}
+	if friendship != nil {
+		switch friendship.Status {
+		case "blocked":
+			return domain.ProfileRelationBlocked, nil
+		case "accepted":
+			return domain.ProfileRelationFriend, nil
+		}
+	}
+
+	if viewerFollow != nil && viewerFollow.Status == "active" {
+		return domain.ProfileRelationFollower, nil
+	}
+	return domain.ProfileRelationStranger, nil
+}
+
+func (u *userUseCase) UpdateUser(actorID primitive.ObjectID, user *domain.User) error {
+	logger := utils.NewLogger("UserUseCase.UpdateUser")
+	logger.LogInput(actorID, user)
+
+	before, err := u.userRepo.FindByID(user.ID.Hex())
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
 
-	// Soft delete user in our database
-	err = u.userRepo.SoftDelete(userID)
+	err = u.userRepo.Update(user)
 	if err != nil {
 		logger.LogOutput(nil, err)
 		return err
 	}
+	if before != nil {
+		recordProfileChanges(u.changeRepo, actorID, before, user, domain.ProfileChangeSourceProfile, nil)
+	}
 
-	logger.LogOutput("success", nil)
+	logger.LogOutput(user, nil)
 	return nil
 }
 
@@ -246,3 +299,40 @@ func (u *userUseCase) GetUserList(req *domain.UserListRequest) (*domain.UserList
 	logger.LogOutput(response, nil)
 	return response, nil
 }
+
+// GetUsersByIDs fetches users in one round trip, keeping the requested order
+func (u *userUseCase) GetUsersByIDs(ids []string) ([]domain.BatchItem, error) {
+	logger := utils.NewLogger("UserUseCase.GetUsersByIDs")
+	logger.LogInput(ids)
+
+	objectIDs, valid := utils.ParseBatchIDs(ids)
+
+	users, err := u.userRepo.FindByIDs(objectIDs)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	usersByID := make(map[primitive.ObjectID]*domain.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+
+	items := make([]domain.BatchItem, 0, len(ids))
+	for _, id := range ids {
+		item := domain.BatchItem{ID: id}
+		objectID, ok := valid[id]
+		switch {
+		case !ok:
+			item.Error = domain.ErrInvalidID.Error()
+		case usersByID[objectID] == nil:
+			item.Error = domain.ErrNotFound.Error()
+		default:
+			item.Data = usersByID[objectID]
+		}
+		items = append(items, item)
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(items)}, nil)
+	return items, nil
+}
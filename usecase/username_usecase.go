@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type usernameUseCase struct {
+	userRepo     domain.UserRepository
+	reservedRepo domain.ReservedUsernameRepository
+	changeRepo   domain.ProfileChangeRepository
+}
+
+func NewUsernameUseCase(userRepo domain.UserRepository, reservedRepo domain.ReservedUsernameRepository, changeRepo domain.ProfileChangeRepository) domain.UsernameUseCase {
+	return &usernameUseCase{
+		userRepo:     userRepo,
+		reservedRepo: reservedRepo,
+		changeRepo:   changeRepo,
+	}
+}
+
+// SuggestUsernames returns available, profanity-filtered usernames derived from base
+func (u *usernameUseCase) SuggestUsernames(base string, limit int) ([]string, error) {
+	logger := utils.NewLogger("UsernameUseCase.SuggestUsernames")
+	logger.LogInput(map[string]interface{}{
+		"base":  base,
+		"limit": limit,
+	})
+
+	// Over-generate since some candidates will be taken or filtered out
+	candidates := utils.GenerateUsernameCandidates(base, limit*3)
+
+	suggestions := make([]string, 0, limit)
+	for _, candidate := range candidates {
+		if len(suggestions) >= limit {
+			break
+		}
+		if !utils.IsAllowedUsername(candidate) {
+			continue
+		}
+
+		reserved, err := u.reservedRepo.FindByUsername(candidate)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if reserved != nil {
+			continue
+		}
+
+		existingUser, err := u.userRepo.FindByUsername(candidate)
+		if err != nil {
+			logger.LogOutput(nil, err)
+			return nil, err
+		}
+		if existingUser == nil {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	logger.LogOutput(suggestions, nil)
+	return suggestions, nil
+}
+
+// ValidateUsername checks the username against reserved words, profanity and existing users
+func (u *usernameUseCase) ValidateUsername(username string) error {
+	logger := utils.NewLogger("UsernameUseCase.ValidateUsername")
+	logger.LogInput(username)
+
+	if !utils.IsAllowedUsername(username) {
+		logger.LogOutput(nil, domain.ErrUsernameNotAllowed)
+		return domain.ErrUsernameNotAllowed
+	}
+
+	reserved, err := u.reservedRepo.FindByUsername(username)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if reserved != nil {
+		logger.LogOutput(nil, domain.ErrUsernameNotAllowed)
+		return domain.ErrUsernameNotAllowed
+	}
+
+	existingUser, err := u.userRepo.FindByUsername(username)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+	if existingUser != nil {
+		logger.LogOutput(nil, domain.ErrUsernameTaken)
+		return domain.ErrUsernameTaken
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (u *usernameUseCase) CreateReservedUsername(reserved *domain.ReservedUsername) error {
+	logger := utils.NewLogger("UsernameUseCase.CreateReservedUsername")
+	logger.LogInput(reserved)
+
+	if reserved.Username == "" {
+		logger.LogOutput(nil, domain.ErrInvalidInput)
+		return domain.ErrInvalidInput
+	}
+	if reserved.Category == "" {
+		reserved.Category = domain.ReservedCategorySystem
+	}
+
+	err := u.reservedRepo.Create(reserved)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(reserved, nil)
+	return nil
+}
+
+func (u *usernameUseCase) UpdateReservedUsername(id primitive.ObjectID, category domain.ReservedUsernameCategory, reason string, isPremium bool) (*domain.ReservedUsername, error) {
+	logger := utils.NewLogger("UsernameUseCase.UpdateReservedUsername")
+	logger.LogInput(map[string]interface{}{
+		"id":        id,
+		"category":  category,
+		"reason":    reason,
+		"isPremium": isPremium,
+	})
+
+	reserved, err := u.reservedRepo.FindByID(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	if category != "" {
+		reserved.Category = category
+	}
+	reserved.Reason = reason
+	reserved.IsPremium = isPremium
+
+	err = u.reservedRepo.Update(reserved)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(reserved, nil)
+	return reserved, nil
+}
+
+func (u *usernameUseCase) DeleteReservedUsername(id primitive.ObjectID) error {
+	logger := utils.NewLogger("UsernameUseCase.DeleteReservedUsername")
+	logger.LogInput(id)
+
+	err := u.reservedRepo.Delete(id)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return err
+	}
+
+	logger.LogOutput(nil, nil)
+	return nil
+}
+
+func (u *usernameUseCase) ListReservedUsernames(category string, limit, offset int) ([]domain.ReservedUsername, error) {
+	logger := utils.NewLogger("UsernameUseCase.ListReservedUsernames")
+	logger.LogInput(map[string]interface{}{
+		"category": category,
+		"limit":    limit,
+		"offset":   offset,
+	})
+
+	reserved, err := u.reservedRepo.List(category, limit, offset)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(map[string]interface{}{"count": len(reserved)}, nil)
+	return reserved, nil
+}
+
+// ClaimPremiumUsername lets a verified account take over a premium reserved username
+func (u *usernameUseCase) ClaimPremiumUsername(userID primitive.ObjectID, username string) (*domain.User, error) {
+	logger := utils.NewLogger("UsernameUseCase.ClaimPremiumUsername")
+	logger.LogInput(map[string]interface{}{
+		"userId":   userID,
+		"username": username,
+	})
+
+	user, err := u.userRepo.FindByID(userID.Hex())
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if user == nil {
+		err = domain.NewNotFoundError("user", userID.Hex())
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if !user.IsVerified {
+		logger.LogOutput(nil, domain.ErrUsernameNotClaimable)
+		return nil, domain.ErrUsernameNotClaimable
+	}
+
+	reserved, err := u.reservedRepo.FindByUsername(username)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if reserved == nil || !reserved.IsClaimable() {
+		logger.LogOutput(nil, domain.ErrUsernameNotClaimable)
+		return nil, domain.ErrUsernameNotClaimable
+	}
+
+	existingUser, err := u.userRepo.FindByUsername(reserved.Username)
+	if err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	if existingUser != nil {
+		logger.LogOutput(nil, domain.ErrUsernameTaken)
+		return nil, domain.ErrUsernameTaken
+	}
+
+	before := *user
+	user.Username = reserved.Username
+	user.UpdatedAt = time.Now()
+	user.Version++
+	if err = u.userRepo.Update(user); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+	recordProfileChanges(u.changeRepo, user.ID, &before, user, domain.ProfileChangeSourceUsernameClaim, nil)
+
+	now := time.Now()
+	reserved.ClaimedBy = &user.ID
+	reserved.ClaimedAt = &now
+	if err = u.reservedRepo.Update(reserved); err != nil {
+		logger.LogOutput(nil, err)
+		return nil, err
+	}
+
+	logger.LogOutput(user, nil)
+	return user, nil
+}
@@ -1,9 +1,10 @@
 package utils
 
 import (
-	"errors"
+	"fmt"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -11,7 +12,7 @@ import (
 func GetUserIDFromContext(c *fiber.Ctx) (primitive.ObjectID, error) {
 	userIDValue := c.Locals("userId")
 	if userIDValue == nil {
-		return primitive.NilObjectID, errors.New("userId not found in context")
+		return primitive.NilObjectID, fmt.Errorf("%w: userId not found in context", domain.ErrUnauthorized)
 	}
 
 	// Try to convert to ObjectID directly
@@ -23,10 +24,10 @@ func GetUserIDFromContext(c *fiber.Ctx) (primitive.ObjectID, error) {
 	if userIDStr, ok := userIDValue.(string); ok {
 		userID, err := primitive.ObjectIDFromHex(userIDStr)
 		if err != nil {
-			return primitive.NilObjectID, errors.New("invalid userId format")
+			return primitive.NilObjectID, fmt.Errorf("%w: invalid userId format", domain.ErrUnauthorized)
 		}
 		return userID, nil
 	}
 
-	return primitive.NilObjectID, errors.New("userId in context is not a valid format")
+	return primitive.NilObjectID, fmt.Errorf("%w: userId in context is not a valid format", domain.ErrUnauthorized)
 }
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ParseBatchIDs converts the requested IDs to ObjectIDs, skipping invalid and duplicate ones.
+// The returned map tells which requested IDs were valid.
+func ParseBatchIDs(ids []string) ([]primitive.ObjectID, map[string]primitive.ObjectID) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	valid := make(map[string]primitive.ObjectID, len(ids))
+	for _, id := range ids {
+		if _, seen := valid[id]; seen {
+			continue
+		}
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		valid[id] = objectID
+		objectIDs = append(objectIDs, objectID)
+	}
+	return objectIDs, valid
+}
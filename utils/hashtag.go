@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var hashtagPattern = regexp.MustCompile(`^[\p{L}\p{N}_]{1,64}$`)
+
+// NormalizeHashtag lowercases the tag and strips the leading # and surrounding spaces.
+// It returns an empty string when the tag is not a valid hashtag.
+func NormalizeHashtag(tag string) string {
+	tag = strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+	if !hashtagPattern.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
+// NormalizeHashtags normalizes the tags, dropping invalid ones and duplicates
+func NormalizeHashtags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeHashtag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// DiffHashtags returns the tags present in next but not in prev, and those present in prev but not in next
+func DiffHashtags(prev, next []string) (added, removed []string) {
+	prevSet := make(map[string]bool, len(prev))
+	for _, tag := range prev {
+		prevSet[tag] = true
+	}
+	nextSet := make(map[string]bool, len(next))
+	for _, tag := range next {
+		nextSet[tag] = true
+		if !prevSet[tag] {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range prev {
+		if !nextSet[tag] {
+			removed = append(removed, tag)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,420 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/bits"
+	"strconv"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// Quality used when a JPEG has to be re-encoded to normalize its orientation
+const jpegReencodeQuality = 92
+
+// Quality of the resized JPEG variants, which are meant to save data
+const jpegVariantQuality = 80
+
+var errMalformedImage = errors.New("malformed image")
+
+// ProcessImage strips metadata such as EXIF and GPS from an uploaded image and
+// rotates JPEG pixels to match their EXIF orientation. Metadata is removed without
+// re-encoding where possible. Content types other than JPEG, PNG and WebP are returned as is.
+func ProcessImage(data []byte, contentType string, opts domain.ImageProcessing) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return processJPEG(data, opts)
+	case "image/png":
+		if !opts.StripMetadata {
+			return data, nil
+		}
+		return stripPNGMetadata(data)
+	case "image/webp":
+		if !opts.StripMetadata {
+			return data, nil
+		}
+		return stripWebPMetadata(data)
+	default:
+		return data, nil
+	}
+}
+
+func processJPEG(data []byte, opts domain.ImageProcessing) ([]byte, error) {
+	if opts.NormalizeOrientation {
+		if orientation := jpegOrientation(data); orientation > 1 && orientation <= 8 {
+			src, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+
+			// The encoder writes no metadata, so this strips it too
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, orientImage(src, orientation), &jpeg.Options{Quality: jpegReencodeQuality}); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+	}
+	if !opts.StripMetadata {
+		return data, nil
+	}
+	return stripJPEGMetadata(data)
+}
+
+// jpegSegments calls fn for every marker segment before the image data with the
+// marker and the full segment bytes. It returns the offset where the scan data starts.
+func jpegSegments(data []byte, fn func(marker byte, segment []byte)) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, errMalformedImage
+	}
+
+	pos := 2
+	for pos < len(data) {
+		if data[pos] != 0xFF {
+			return 0, errMalformedImage
+		}
+		// Markers may be preceded by any number of fill bytes
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+		if pos >= len(data) {
+			return 0, errMalformedImage
+		}
+		marker := data[pos]
+		start := pos - 1
+		pos++
+
+		// Standalone markers carry no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			fn(marker, data[start:pos])
+			continue
+		}
+		if marker == 0xD9 || pos+2 > len(data) {
+			return start, nil
+		}
+
+		end := pos + int(binary.BigEndian.Uint16(data[pos:pos+2]))
+		if end > len(data) {
+			return 0, errMalformedImage
+		}
+		// Start of scan, everything after the header is entropy coded data
+		if marker == 0xDA {
+			return start, nil
+		}
+		fn(marker, data[start:end])
+		pos = end
+	}
+	return 0, errMalformedImage
+}
+
+// stripJPEGMetadata drops the EXIF, XMP, IPTC and comment segments.
+// JFIF, the ICC color profile and the Adobe color transform are kept since they affect rendering.
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	scan, err := jpegSegments(data, func(marker byte, segment []byte) {
+		isApp := marker >= 0xE0 && marker <= 0xEF
+		keep := marker == 0xE0 || marker == 0xE2 || marker == 0xEE
+		if (isApp && !keep) || marker == 0xFE {
+			return
+		}
+		out = append(out, segment...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, data[scan:]...), nil
+}
+
+// jpegOrientation reads the EXIF orientation tag, 1 when there is none
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	_, _ = jpegSegments(data, func(marker byte, segment []byte) {
+		if marker != 0xE1 || len(segment) < 10 || string(segment[4:10]) != "Exif\x00\x00" {
+			return
+		}
+		if o := exifOrientation(segment[10:]); o > 0 {
+			orientation = o
+		}
+	})
+	return orientation
+}
+
+// exifOrientation finds the orientation tag in the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+	return 0
+}
+
+// orientImage applies the EXIF orientation so the image displays upright without it
+func orientImage(src image.Image, orientation int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	// Work on a plain RGBA copy, reading pixels through image.Image is slow
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // mirrored and rotated 270
+				sx, sy = y, x
+			case 6: // rotated 90
+				sx, sy = y, h-1-x
+			case 7: // mirrored and rotated 90
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 270
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			si := rgba.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], rgba.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// stripPNGMetadata drops the EXIF, text and timestamp chunks
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if len(data) < len(signature) || string(data[:len(signature)]) != signature {
+		return nil, errMalformedImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+	pos := len(signature)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if end > len(data) {
+			return nil, errMalformedImage
+		}
+		chunk := data[pos:end]
+		switch string(chunk[4:8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, chunk...)
+		}
+		pos = end
+		if string(chunk[4:8]) == "IEND" {
+			return out, nil
+		}
+	}
+	return nil, errMalformedImage
+}
+
+// stripWebPMetadata drops the EXIF and XMP chunks of an extended WebP and clears their flags
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformedImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size + size%2 // chunks are padded to an even size
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[pos:end]
+		switch string(chunk[:4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk = append([]byte(nil), chunk...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present flags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, chunk...)
+		}
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+// ImageWidth returns the width of a JPEG, PNG or GIF image without decoding its pixels
+func ImageWidth(data []byte) (int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	return config.Width, nil
+}
+
+// ResizeImage scales a JPEG or PNG image down to the width, keeping its aspect
+// ratio and format. Each pixel is the average of the source pixels it covers.
+func ResizeImage(data []byte, contentType string, width int) ([]byte, error) {
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, fmt.Errorf("cannot resize %s images", contentType)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if width <= 0 || width >= w {
+		return nil, fmt.Errorf("cannot resize a %d pixel wide image to %d pixels", w, width)
+	}
+	height := max(1, h*width/w)
+
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*h/height, max((y+1)*h/height, y*h/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*w/width, max((x+1)*w/width, x*w/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					si := rgba.PixOffset(sx, sy)
+					for c := 0; c < 4; c++ {
+						sum[c] += int(rgba.Pix[si+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			di := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[di+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if contentType == "image/png" {
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegVariantQuality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PerceptualHash computes a 64 bit difference hash of the image, formatted as 16 hex digits.
+// Resized, recompressed or lightly edited copies of an image hash to nearby values,
+// so hashes are compared with PerceptualHashDistance rather than for equality.
+func PerceptualHash(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	// Shrink to 9x8 grayscale by averaging blocks, then compare each pixel with its right neighbour
+	b := img.Bounds()
+	if b.Dx() < 9 || b.Dy() < 8 {
+		return "", errMalformedImage
+	}
+	var gray [8][9]float64
+	for gy := 0; gy < 8; gy++ {
+		y0, y1 := b.Min.Y+gy*b.Dy()/8, b.Min.Y+(gy+1)*b.Dy()/8
+		for gx := 0; gx < 9; gx++ {
+			x0, x1 := b.Min.X+gx*b.Dx()/9, b.Min.X+(gx+1)*b.Dx()/9
+			var sum float64
+			var count int
+			// Sampling a bounded grid keeps large images cheap
+			stepY, stepX := (y1-y0)/16+1, (x1-x0)/16+1
+			for y := y0; y < y1; y += stepY {
+				for x := x0; x < x1; x += stepX {
+					r, g, bl, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+					count++
+				}
+			}
+			gray[gy][gx] = sum / float64(count)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if gray[y][x] > gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// IsPerceptualHash reports whether the value has the format produced by PerceptualHash
+func IsPerceptualHash(value string) bool {
+	if len(value) != 16 {
+		return false
+	}
+	_, err := strconv.ParseUint(value, 16, 64)
+	return err == nil
+}
+
+// PerceptualHashDistance returns the number of differing bits between two hashes,
+// or -1 when either is not a hash produced by PerceptualHash
+func PerceptualHashDistance(a, b string) int {
+	if !IsPerceptualHash(a) || !IsPerceptualHash(b) {
+		return -1
+	}
+	x, _ := strconv.ParseUint(a, 16, 64)
+	y, _ := strconv.ParseUint(b, 16, 64)
+	return bits.OnesCount64(x ^ y)
+}
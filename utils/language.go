@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"regexp"
+	"unicode"
+)
+
+// LanguageUnknown is stored when no script dominates the text, e.g. emoji-only comments
+const LanguageUnknown = "und"
+
+var languageCodeRegex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// scriptLanguages maps Unicode scripts to the language they most likely belong to
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Thai, "th"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Lao, "lo"},
+	{unicode.Khmer, "km"},
+	{unicode.Myanmar, "my"},
+	{unicode.Latin, "en"},
+}
+
+// DetectLanguage guesses the ISO 639-1 language of text from the Unicode scripts it uses.
+// Kana wins over Han so Japanese text with kanji is not reported as Chinese,
+// and Latin script is assumed to be English.
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	for _, r := range text {
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				break
+			}
+		}
+	}
+
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+
+	language, best := LanguageUnknown, 0
+	for _, s := range scriptLanguages {
+		if counts[s.language] > best {
+			language, best = s.language, counts[s.language]
+		}
+	}
+	return language
+}
+
+// IsValidLanguageCode reports whether code looks like a BCP-47 language tag such as "th" or "zh-TW"
+func IsValidLanguageCode(code string) bool {
+	return languageCodeRegex.MatchString(code)
+}
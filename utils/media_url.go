@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// storageURLPattern matches Firebase Storage download URLs inside JSON strings,
+// where encoding/json writes & as \u0026
+var storageURLPattern = regexp.MustCompile(`https://firebasestorage\.googleapis\.com/v0/b/([^/"\\?]+)/o/([^"?\\]+)(?:\?(?:[^"\\]|\\u0026)*)?`)
+
+// MediaURLRewriter turns storage URLs into CDN URLs and back
+type MediaURLRewriter struct {
+	cdn        domain.MediaCDN
+	buckets    map[string]string // CDN base URL to bucket
+	cdnPattern *regexp.Regexp
+}
+
+// NewMediaURLRewriter returns nil when no CDN host is configured
+func NewMediaURLRewriter(cdn domain.MediaCDN) *MediaURLRewriter {
+	if len(cdn.Hosts) == 0 {
+		return nil
+	}
+
+	hosts := make(map[string]string, len(cdn.Hosts))
+	buckets := make(map[string]string, len(cdn.Hosts))
+	bases := make([]string, 0, len(cdn.Hosts))
+	for bucket, baseURL := range cdn.Hosts {
+		baseURL = strings.TrimRight(baseURL, "/")
+		hosts[bucket] = baseURL
+		buckets[baseURL] = bucket
+		bases = append(bases, regexp.QuoteMeta(baseURL))
+	}
+	cdn.Hosts = hosts
+	rewriter := &MediaURLRewriter{
+		cdn:     cdn,
+		buckets: buckets,
+	}
+	// Longest first, so a base URL isn't cut short by another one it starts with
+	sort.Slice(bases, func(i, j int) bool { return len(bases[i]) > len(bases[j]) })
+	rewriter.cdnPattern = regexp.MustCompile(`(` + strings.Join(bases, "|") + `)/([^"?\\]+)(?:\?(?:[^"\\]|\\u0026)*)?`)
+
+	return rewriter
+}
+
+// RewriteJSON replaces the storage URLs in a JSON document with CDN URLs
+func (r *MediaURLRewriter) RewriteJSON(body []byte) []byte {
+	return storageURLPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		parts := storageURLPattern.FindSubmatch(match)
+		return []byte(r.cdnURL(string(parts[1]), string(parts[2]), string(match)))
+	})
+}
+
+// RestoreJSON replaces the CDN URLs in a JSON document with the storage URLs
+// they came from, dropping their signatures
+func (r *MediaURLRewriter) RestoreJSON(body []byte) []byte {
+	return r.cdnPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		parts := r.cdnPattern.FindSubmatch(match)
+		name, err := url.PathUnescape(string(parts[2]))
+		if err != nil {
+			return match
+		}
+		bucket := r.buckets[string(parts[1])]
+		return []byte(fmt.Sprintf("https://firebasestorage.googleapis.com/v0/b/%s/o/%s?alt=media", bucket, name))
+	})
+}
+
+// cdnURL builds the CDN URL of the object, or returns fallback when the bucket has no CDN
+func (r *MediaURLRewriter) cdnURL(bucket, escapedName, fallback string) string {
+	baseURL, ok := r.cdn.Hosts[bucket]
+	if !ok {
+		return fallback
+	}
+	name, err := url.PathUnescape(escapedName)
+	// Private files are only fetched through their download links
+	if err != nil || strings.HasPrefix(name, domain.PrivateFilePrefix) {
+		return fallback
+	}
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	cdnURL := baseURL + "/" + strings.Join(segments, "/")
+	if len(r.cdn.SigningKey) == 0 {
+		return cdnURL
+	}
+	return r.sign(cdnURL, time.Now())
+}
+
+// sign signs the URL the way Cloud CDN checks signed URLs. The expiry is rounded
+// to the TTL so the same URL is handed out for a while and stays cacheable.
+func (r *MediaURLRewriter) sign(cdnURL string, now time.Time) string {
+	expires := now.Truncate(r.cdn.SignedURLTTL).Add(2 * r.cdn.SignedURLTTL).Unix()
+	cdnURL = fmt.Sprintf("%s?Expires=%d&KeyName=%s", cdnURL, expires, url.QueryEscape(r.cdn.SigningKeyName))
+
+	mac := hmac.New(sha1.New, r.cdn.SigningKey)
+	mac.Write([]byte(cdnURL))
+	return cdnURL + "&Signature=" + base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
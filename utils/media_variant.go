@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// mediaVariantPattern matches the variant folders of storage URLs, with the
+// slashes of the object name escaped or not
+var mediaVariantPattern = regexp.MustCompile(`(https://firebasestorage\.googleapis\.com/v0/b/[^/"\\?]+/o/)variants(?:/|%2F)w\d+(?:/|%2F)`)
+
+// MediaVariantURL returns the URL of the stored object's variant at the width
+func MediaVariantURL(fileURL, fileName string, width int) string {
+	return strings.Replace(fileURL, "/o/"+fileName, "/o/"+domain.MediaVariantName(fileName, width), 1)
+}
+
+// StorageURLs returns the distinct storage URLs in a JSON document
+func StorageURLs(body []byte) []string {
+	seen := make(map[string]bool)
+	urls := make([]string, 0)
+	for _, match := range storageURLPattern.FindAll(body, -1) {
+		if url := string(match); !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// ReplaceStorageURLsJSON replaces the storage URLs in a JSON document that have a replacement
+func ReplaceStorageURLsJSON(body []byte, replacements map[string]string) []byte {
+	return storageURLPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		if replacement, ok := replacements[string(match)]; ok {
+			return []byte(replacement)
+		}
+		return match
+	})
+}
+
+// RestoreMediaVariantsJSON replaces the variant URLs in a JSON document with
+// the URLs of the objects they were made of
+func RestoreMediaVariantsJSON(body []byte) []byte {
+	return mediaVariantPattern.ReplaceAll(body, []byte("$1"))
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MetricServerErrors counts the HTTP responses with a 5xx status
+const MetricServerErrors = "http.responses.5xx"
+
+var metricCounters sync.Map // name -> *int64
+
+// IncrementMetric adds one to the named process counter
+func IncrementMetric(name string) {
+	counter, ok := metricCounters.Load(name)
+	if !ok {
+		counter, _ = metricCounters.LoadOrStore(name, new(int64))
+	}
+	atomic.AddInt64(counter.(*int64), 1)
+}
+
+// MetricsSnapshot returns the current value of every process counter
+func MetricsSnapshot() map[string]int64 {
+	snapshot := make(map[string]int64)
+	metricCounters.Range(func(name, counter interface{}) bool {
+		snapshot[name.(string)] = atomic.LoadInt64(counter.(*int64))
+		return true
+	})
+	return snapshot
+}
+
+// ResponseMetrics returns a middleware counting server errors
+func ResponseMetrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if ResponseStatus(c, err) >= fiber.StatusInternalServerError {
+			IncrementMetric(MetricServerErrors)
+		}
+		return err
+	}
+}
+
+// ResponseStatus returns the status a request will be answered with. Errors handlers
+// return are answered later by the error handler, so their status is worked out here.
+func ResponseStatus(c *fiber.Ctx, err error) int {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError
+	}
+	return c.Response().StatusCode()
+}
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"encoding/base64"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 )
 
 const (
@@ -29,3 +33,59 @@ func GetPaginationParams(c *fiber.Ctx) (limit, offset int) {
 
 	return limit, offset
 }
+
+// GetCursorParams extracts limit and offset, preferring the opaque cursor over offset when present
+func GetCursorParams(c *fiber.Ctx, defaultLimit int) (limit, offset int) {
+	limit, offset = GetPaginationParams(c)
+	if c.Query("limit") == "" || limit == 0 {
+		limit = defaultLimit
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		if cursorOffset, err := DecodeCursor(cursor); err == nil {
+			offset = cursorOffset
+		}
+	}
+
+	return limit, offset
+}
+
+// EncodeCursor turns an offset into an opaque cursor
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+// DecodeCursor reads the offset back from a cursor created by EncodeCursor
+func DecodeCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	value := strings.TrimPrefix(string(decoded), "o:")
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, nil
+}
+
+// NewPage builds the paginated envelope for a list of count items fetched at offset
+func NewPage(items interface{}, count, limit, offset int, totalApprox int64) *domain.Page {
+	next := offset + count
+	hasMore := count > 0 && count >= limit && int64(next) < totalApprox
+	// A capped total means there may be more than we counted
+	if totalApprox >= domain.MaxApproxCount && count >= limit {
+		hasMore = true
+	}
+
+	page := &domain.Page{
+		Items:       items,
+		HasMore:     hasMore,
+		TotalApprox: totalApprox,
+	}
+	if hasMore {
+		page.NextCursor = EncodeCursor(next)
+	}
+	return page
+}
@@ -1,13 +1,18 @@
 package utils
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
 )
 
-// ErrorResponse represents the structure of error responses
+// ErrorResponse represents the structure of error responses. Code classifies
+// the error and Fields holds the problem with each invalid input field.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error  string            `json:"error"`
+	Code   domain.ErrorCode  `json:"code,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 // SuccessResponse represents the structure of success responses
@@ -15,9 +20,10 @@ type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
-// SendError sends an error response with the given status code and message
+// SendError sends an error response with the given status code and message,
+// coded by the status
 func SendError(c *fiber.Ctx, status int, message string) error {
-	return c.Status(status).JSON(ErrorResponse{Error: message})
+	return c.Status(status).JSON(ErrorResponse{Error: message, Code: statusCodes[status]})
 }
 
 // SendSuccess sends a success response with the given message
@@ -25,28 +31,143 @@ func SendSuccess(c *fiber.Ctx, message string) error {
 	return c.Status(fiber.StatusOK).JSON(SuccessResponse{Message: message})
 }
 
+// codeStatuses maps the error codes to HTTP status codes
+var codeStatuses = map[domain.ErrorCode]int{
+	domain.CodeValidation:    fiber.StatusBadRequest,
+	domain.CodeUnauthorized:  fiber.StatusUnauthorized,
+	domain.CodeForbidden:     fiber.StatusForbidden,
+	domain.CodeNotFound:      fiber.StatusNotFound,
+	domain.CodeConflict:      fiber.StatusConflict,
+	domain.CodeGone:          fiber.StatusGone,
+	domain.CodeTooLarge:      fiber.StatusRequestEntityTooLarge,
+	domain.CodeUnprocessable: fiber.StatusUnprocessableEntity,
+	domain.CodeRateLimited:   fiber.StatusTooManyRequests,
+	domain.CodeInternal:      fiber.StatusInternalServerError,
+	domain.CodeUnavailable:   fiber.StatusServiceUnavailable,
+}
+
+// statusCodes maps HTTP status codes back to the error codes
+var statusCodes = func() map[int]domain.ErrorCode {
+	codes := make(map[int]domain.ErrorCode, len(codeStatuses))
+	for code, status := range codeStatuses {
+		codes[status] = code
+	}
+	return codes
+}()
+
+// errorCodes maps the domain error catalog to error codes
+var errorCodes = []struct {
+	err  error
+	code domain.ErrorCode
+}{
+	{domain.ErrNotFound, domain.CodeNotFound},
+	{domain.ErrFriendRequestNotFound, domain.CodeNotFound},
+	{domain.ErrFriendshipNotFound, domain.CodeNotFound},
+	{domain.ErrInvalidID, domain.CodeValidation},
+	{domain.ErrInvalidInput, domain.CodeValidation},
+	{domain.ErrNotFriends, domain.CodeValidation},
+	{domain.ErrUsernameNotAllowed, domain.CodeValidation},
+	{domain.ErrInvalidStoryAudio, domain.CodeValidation},
+	{domain.ErrUnauthorized, domain.CodeUnauthorized},
+	{domain.ErrForbidden, domain.CodeForbidden},
+	{domain.ErrBlocked, domain.CodeForbidden},
+	{domain.ErrPrivacyRestricted, domain.CodeForbidden},
+	{domain.ErrUsernameNotClaimable, domain.CodeForbidden},
+	{domain.ErrAccountBanned, domain.CodeForbidden},
+	{domain.ErrAccountMuted, domain.CodeForbidden},
+	{domain.ErrDuplicate, domain.CodeConflict},
+	{domain.ErrFriendRequestAlreadySent, domain.CodeConflict},
+	{domain.ErrAlreadyFriends, domain.CodeConflict},
+	{domain.ErrUsernameTaken, domain.CodeConflict},
+	{domain.ErrPostEditLocked, domain.CodeConflict},
+	{domain.ErrPinnedPostLimit, domain.CodeConflict},
+	{domain.ErrInternalError, domain.CodeInternal},
+	{domain.ErrInvalidChatInvite, domain.CodeGone},
+	{domain.ErrShortLinkExpired, domain.CodeGone},
+	{domain.ErrChatMessageTooLong, domain.CodeValidation},
+	{domain.ErrChatTooManyLinks, domain.CodeValidation},
+	{domain.ErrChatRateLimited, domain.CodeRateLimited},
+	{domain.ErrChatMuted, domain.CodeRateLimited},
+	{domain.ErrRateLimited, domain.CodeRateLimited},
+	{domain.ErrStorageQuotaExceeded, domain.CodeTooLarge},
+	{domain.ErrBannedMedia, domain.CodeUnprocessable},
+	{domain.ErrTranslationUnavailable, domain.CodeUnavailable},
+}
+
+// ErrorCode returns the code of a domain error: the code of a *domain.Error,
+// or the code of the catalog error it wraps. The second return value is false
+// for errors outside the catalog.
+func ErrorCode(err error) (domain.ErrorCode, bool) {
+	if domainErr := domain.AsError(err); domainErr != nil {
+		return domainErr.Code, true
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code, true
+		}
+	}
+	if domain.IsNotFoundError(err) {
+		return domain.CodeNotFound, true
+	}
+	return domain.CodeInternal, false
+}
+
+// ErrorStatus returns the HTTP status for a domain error, matching wrapped errors too.
+// The second return value is false for errors outside the catalog.
+func ErrorStatus(err error) (int, bool) {
+	code, ok := ErrorCode(err)
+	status, known := codeStatuses[code]
+	if !ok || !known {
+		return fiber.StatusInternalServerError, false
+	}
+	return status, true
+}
+
 // HandleError handles different types of errors and sends appropriate responses
 func HandleError(c *fiber.Ctx, err error) error {
-	var status int
-	var message string
-
-	switch {
-	case err == domain.ErrNotFound:
-		status = fiber.StatusNotFound
-		message = err.Error()
-	case err == domain.ErrUnauthorized:
-		status = fiber.StatusUnauthorized
-		message = err.Error()
-	case err == domain.ErrInvalidInput:
-		status = fiber.StatusBadRequest
-		message = err.Error()
-	case err == domain.ErrInternalError:
-		status = fiber.StatusInternalServerError
-		message = err.Error()
-	default:
-		status = fiber.StatusInternalServerError
-		message = "Internal server error"
-	}
-
-	return SendError(c, status, message)
+	if suspension := domain.AsSuspensionError(err); suspension != nil {
+		return SendSuspensionError(c, suspension)
+	}
+
+	status, ok := ErrorStatus(err)
+	if !ok {
+		// Don't leak unexpected errors to clients
+		return SendError(c, status, "Internal server error")
+	}
+
+	response := ErrorResponse{Error: err.Error(), Code: statusCodes[status]}
+	if domainErr := domain.AsError(err); domainErr != nil {
+		response.Fields = domainErr.Fields
+	}
+	return c.Status(status).JSON(response)
+}
+
+// ErrorHandler is the app's error handler, for errors handlers and middleware
+// return rather than answer. Domain errors are answered like HandleError does,
+// and Fiber's own errors, like unknown routes or bodies over the limit, keep
+// their status.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return SendError(c, fiberErr.Code, fiberErr.Message)
+	}
+	return HandleError(c, err)
+}
+
+// SendSuspensionError tells a suspended user what the suspension is and when it ends
+func SendSuspensionError(c *fiber.Ctx, suspension *domain.Suspension) error {
+	err := error(&domain.SuspensionError{Suspension: suspension})
+	code := "account_banned"
+	if suspension.Type == domain.SuspensionMute {
+		code = "account_muted"
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": err.Error(),
+		"code":  code,
+		"suspension": fiber.Map{
+			"type":   suspension.Type,
+			"reason": suspension.Reason,
+			"until":  suspension.Until,
+		},
+	})
 }
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"strings"
+)
+
+// reservedUsernames can't be claimed because they collide with routes, brand or staff accounts
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "root": true, "system": true, "support": true,
+	"help": true, "moderator": true, "mod": true, "staff": true, "official": true,
+	"vongga": true, "api": true, "me": true, "list": true, "null": true, "undefined": true,
+	"security": true, "billing": true, "settings": true, "login": true, "logout": true,
+	"signup": true, "register": true, "checkusername": true, "usernamesuggestions": true,
+}
+
+// profaneWords are matched as substrings after leetspeak normalization
+var profaneWords = []string{
+	"fuck", "shit", "bitch", "cunt", "dick", "pussy", "cock", "whore", "slut",
+	"bastard", "asshole", "nigger", "nigga", "faggot", "retard", "porn", "rape",
+	"nazi", "hitler", "penis", "vagina", "dildo", "wank", "twat",
+}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "@", "a", "$", "s",
+)
+
+// IsReservedUsername reports whether the username is reserved for the platform
+func IsReservedUsername(username string) bool {
+	return reservedUsernames[strings.ToLower(username)]
+}
+
+// ContainsProfanity reports whether the username contains a profane word
+func ContainsProfanity(username string) bool {
+	normalized := leetReplacer.Replace(strings.ToLower(username))
+	for _, word := range profaneWords {
+		if strings.Contains(normalized, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllowedUsername checks the username against reserved words and the profanity filter
+func IsAllowedUsername(username string) bool {
+	return !IsReservedUsername(username) && !ContainsProfanity(username)
+}
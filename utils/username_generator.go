@@ -22,21 +22,58 @@ func GenerateUsername(displayName string, email string) string {
 		baseName = strings.Split(email, "@")[0]
 	}
 
+	baseName = CleanUsernameBase(baseName, 15)
+
+	// Add random numbers
+	randomNum := rand.Intn(9999)
+	return fmt.Sprintf("%s%04d", baseName, randomNum)
+}
+
+// CleanUsernameBase lowercases and strips the base name, falling back to "user"
+// when it is too short, reserved or profane
+func CleanUsernameBase(baseName string, maxLength int) string {
 	// Clean the base name
 	baseName = strings.ToLower(baseName)
 	baseName = nonAlphanumeric.ReplaceAllString(baseName, "")
 
-	// If base name is too short, use a default
-	if len(baseName) < 3 {
+	// If base name is too short or not allowed, use a default
+	if len(baseName) < 3 || ContainsProfanity(baseName) {
 		baseName = "user"
 	}
 
 	// Truncate if too long
-	if len(baseName) > 15 {
-		baseName = baseName[:15]
+	if len(baseName) > maxLength {
+		baseName = baseName[:maxLength]
 	}
 
-	// Add random numbers
-	randomNum := rand.Intn(9999)
-	return fmt.Sprintf("%s%04d", baseName, randomNum)
+	return baseName
+}
+
+// GenerateUsernameCandidates returns username variants of the base name, shortest first
+func GenerateUsernameCandidates(baseName string, count int) []string {
+	rand.Seed(time.Now().UnixNano())
+
+	// Keep room for a numeric suffix within the 15 character limit
+	baseName = CleanUsernameBase(baseName, 11)
+
+	candidates := make([]string, 0, count)
+	seen := make(map[string]bool)
+	add := func(candidate string) {
+		if len(candidates) < count && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	add(baseName)
+	add(fmt.Sprintf("%s%d", baseName, time.Now().Year()%100))
+	for attempt := 0; len(candidates) < count && attempt < count*10; attempt++ {
+		if attempt%2 == 0 {
+			add(fmt.Sprintf("%s%02d", baseName, rand.Intn(100)))
+		} else {
+			add(fmt.Sprintf("%s%04d", baseName, rand.Intn(10000)))
+		}
+	}
+
+	return candidates
 }
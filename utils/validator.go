@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prohmpiriya_phonumnuaisuk/vongga-platform/vongga-backend/domain"
+)
+
+// validate checks the `validate` struct tags of request bodies. Fields are
+// reported by their JSON names, the way clients sent them.
+var validate = func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v
+}()
+
+// ParseBody parses the request body into out and validates it by its struct
+// tags. Both an unreadable body and invalid fields are validation errors, the
+// latter with the problem of each field.
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return domain.NewValidationError("invalid request body", nil)
+	}
+	return ValidateStruct(out)
+}
+
+// ValidateStruct validates s by its struct tags. Anything that isn't a struct,
+// like a list of IDs, passes as is.
+func ValidateStruct(s interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(s))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := validate.Struct(s)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	fields := make(map[string]string, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		// The namespace starts with the struct's own name
+		_, name, _ := strings.Cut(fieldErr.Namespace(), ".")
+		fields[name] = validationMessage(value.Type(), fieldErr)
+	}
+	return domain.NewValidationError("invalid request", fields)
+}
+
+// validationMessage describes why the field failed its tag
+func validationMessage(structType reflect.Type, fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required without %s", otherFieldName(structType, param))
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(param, " ", ", "))
+	case "min", "max", "len":
+		unit := "characters"
+		switch fieldErr.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			unit = "items"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			unit = ""
+		}
+		if fieldErr.Tag() == "min" && param == "1" && unit != "" {
+			return "must not be empty"
+		}
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fieldErr.Tag()]
+		return strings.TrimSpace(fmt.Sprintf("must be %s %s %s", bound, param, unit))
+	case "gte":
+		return fmt.Sprintf("must be at least %s", param)
+	case "lte":
+		return fmt.Sprintf("must be at most %s", param)
+	case "email":
+		return "must be an email address"
+	case "url":
+		return "must be a URL"
+	case "mongodb":
+		return "must be a valid ID"
+	}
+	return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+}
+
+// otherFieldName returns the JSON name of a top level field tags like
+// required_without refer to by its Go name
+func otherFieldName(structType reflect.Type, goName string) string {
+	if field, ok := structType.FieldByName(goName); ok {
+		return jsonFieldName(field)
+	}
+	return goName
+}
+
+// jsonFieldName returns the name the field has in JSON bodies
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}